@@ -0,0 +1,588 @@
+/**
+ * Account Actions - Data Export, Password Change and Account Deletion
+ *
+ * This file provides account-level endpoints that act on all of a user's
+ * data at once. They require the current password in the request body in
+ * addition to the JWT, so a stolen token alone is not enough. Accounts
+ * without a password (Apple and magic-link sign-ins) instead sign in
+ * again: their session must have started within reauthWindow.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-21
+ */
+package actions
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"time"
+
+	"backend/models"
+	"backend/storage"
+	"backend/validators"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/nulls"
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gofrs/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// exportBatchSize is the number of entries loaded per query while exporting.
+const exportBatchSize = 500
+
+// reauthWindow is how recently an account without a password must have
+// signed in to re-authenticate.
+const reauthWindow = 10 * time.Minute
+
+// reauthLimit bounds the password attempts of one user.
+var reauthLimit = newRateLimiter(10, 15*time.Minute)
+
+/**
+ * recentSignIn reports whether the session of the request's access token
+ * started, by a sign-in, within reauthWindow; the session starts with the
+ * first refresh token of its family. API keys never count as recent.
+ */
+func recentSignIn(c buffalo.Context, now time.Time) (bool, error) {
+	claims, ok := currentTokenClaims(c)
+	if !ok {
+		return false, nil
+	}
+	family, err := uuid.FromString(claims.Family)
+	if err != nil {
+		return false, nil
+	}
+	var started struct {
+		At nulls.Time `db:"started_at"`
+	}
+	if err := mustTx(c).RawQuery(`SELECT MIN(created_at) AS started_at FROM refresh_tokens WHERE family_id = ?`, family).First(&started); err != nil {
+		return false, err
+	}
+	return started.At.Valid && now.Sub(started.At.Time) <= reauthWindow, nil
+}
+
+/**
+ * reauthenticate checks the `password` field of the request body against
+ * the authenticated user's password. A missing or wrong password gets
+ * 403, so clients do not mistake it for an expired session, and more
+ * than 10 attempts in 15 minutes get 429. An account without a password
+ * passes when it signed in within reauthWindow (recentSignIn) and gets
+ * 401 "sign in again to continue" otherwise.
+ *
+ * @param c - Buffalo context with authenticated user
+ * @return models.User - The authenticated user
+ * @return int - HTTP status for the error response, 0 on success
+ * @return string - Error message for the error response
+ */
+func reauthenticate(c buffalo.Context) (models.User, int, string) {
+	var p struct {
+		Password string `json:"password"`
+	}
+	if c.Request().ContentLength != 0 {
+		if err := c.Bind(&p); err != nil {
+			return models.User{}, http.StatusBadRequest, "bad payload"
+		}
+	}
+	u, ok := CurrentUser(c)
+	if !ok {
+		return u, http.StatusUnauthorized, "unauthorized"
+	}
+	if u.PasswordHash == "" {
+		recent, err := recentSignIn(c, time.Now())
+		if err != nil {
+			return u, http.StatusInternalServerError, "db error"
+		}
+		if !recent {
+			return u, http.StatusUnauthorized, "sign in again to continue"
+		}
+		return u, 0, ""
+	}
+	if p.Password == "" {
+		return u, http.StatusForbidden, "password required"
+	}
+	if !reauthLimit.allow(u.ID.String(), time.Now()) {
+		return u, http.StatusTooManyRequests, "too many attempts"
+	}
+	if bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(p.Password)) != nil {
+		return u, http.StatusForbidden, "invalid password"
+	}
+	return u, 0, ""
+}
+
+/**
+ * exportEntry is a time entry in the export archive; the photo is a
+ * separate file instead of inline base64
+ */
+type exportEntry struct {
+	models.TimeTrac
+	PhotoFile string `json:"photo_file,omitempty"`
+}
+
+/**
+ * exportPhoto locates one photo to copy into the archive
+ */
+type exportPhoto struct {
+	file    string
+	trackID uuid.UUID
+	key     string // storage key, "" for legacy photo_data
+}
+
+/**
+ * MeExport streams all personal data of the user as a zip archive
+ *
+ * GET /api/me/export (also POST, for clients that cannot send a GET body)
+ *
+ * Payload: {"password": "<current password>"}
+ *
+ * Archive contents:
+ * - user.json: Account record
+ * - preferences.json: User preferences
+ * - templates.json: Quick-start templates
+ * - teams.json: Team memberships with team name and role
+ * - entries.json: All time entries including trash, oldest first; photos
+ *   are referenced by `photo_file`
+ * - photos/<entry id>.<ext>: Photo attachments
+ *
+ * Entries are read in batches and photos are copied from the storage
+ * backend one at a time, so memory use does not grow with the account.
+ * Once streaming has started errors can no longer change the status, so
+ * they are logged and the archive is cut short.
+ *
+ * @param c - Buffalo context with authenticated user
+ * @return zip archive or JSON error response
+ */
+func MeExport(c buffalo.Context) error {
+	u, status, msg := reauthenticate(c)
+	if status != 0 {
+		return c.Render(status, r.JSON(map[string]string{"error": msg}))
+	}
+	tx := mustTx(c)
+
+	prefs, _, err := loadPreferences(tx, u.ID)
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+	templates := []models.TrackTemplate{}
+	if err := tx.Where("user_id = ?", u.ID).Order("position ASC").All(&templates); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+	type membership struct {
+		TeamID   uuid.UUID  `db:"team_id" json:"team_id"`
+		TeamName string     `db:"team_name" json:"team_name"`
+		Role     string     `db:"role" json:"role"`
+		Status   string     `db:"status" json:"status"`
+		JoinedAt *time.Time `db:"joined_at" json:"joined_at"`
+	}
+	teams := []membership{}
+	if err := tx.RawQuery(`
+		SELECT tm.team_id, t.name AS team_name, tm.role, tm.status, tm.joined_at
+		FROM team_members tm JOIN teams t ON t.id = tm.team_id
+		WHERE tm.user_id = ?
+		ORDER BY t.name
+	`, u.ID).All(&teams); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+
+	res := c.Response()
+	res.Header().Set("Content-Type", "application/zip")
+	res.Header().Set("Content-Disposition",
+		fmt.Sprintf(`attachment; filename="timetrac-export-%s.zip"`, time.Now().UTC().Format("20060102")))
+	res.WriteHeader(http.StatusOK)
+
+	zw := zip.NewWriter(res)
+	if err := writeExport(c, tx, zw, u, prefs, templates, teams); err != nil {
+		c.Logger().Errorf("export for user %s aborted: %v", u.ID, err)
+		return nil
+	}
+	if err := zw.Close(); err != nil {
+		c.Logger().Errorf("export for user %s: %v", u.ID, err)
+	}
+	return nil
+}
+
+/**
+ * writeExport writes the archive files of MeExport
+ */
+func writeExport(c buffalo.Context, tx *pop.Connection, zw *zip.Writer, u models.User, prefs models.UserPreferences, templates, teams any) error {
+	for _, f := range []struct {
+		name string
+		v    any
+	}{
+		{"user.json", u},
+		{"preferences.json", prefs},
+		{"templates.json", templates},
+		{"teams.json", teams},
+	} {
+		w, err := zw.Create(f.name)
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(f.v); err != nil {
+			return err
+		}
+	}
+
+	photos, err := writeExportEntries(tx, zw, u.ID)
+	if err != nil {
+		return err
+	}
+	for _, p := range photos {
+		err := writeExportPhoto(c, tx, zw, p)
+		if errors.Is(err, storage.ErrNotFound) {
+			c.Logger().Warnf("export: photo %s of entry %s is missing", p.key, p.trackID)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("photo of %s: %w", p.trackID, err)
+		}
+	}
+	return nil
+}
+
+/**
+ * writeExportEntries streams entries.json in batches using (start_at, id)
+ * keyset pagination and returns the photos to copy afterwards
+ */
+func writeExportEntries(tx *pop.Connection, zw *zip.Writer, uid uuid.UUID) ([]exportPhoto, error) {
+	w, err := zw.Create("entries.json")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(w, "[\n"); err != nil {
+		return nil, err
+	}
+
+	photos := []exportPhoto{}
+	first := true
+	var last *models.TimeTrac
+	for {
+		q := tx.Where("user_id = ?", uid)
+		if last != nil {
+			q = q.Where("(start_at, id) > (?, ?)", last.StartAt, last.ID)
+		}
+		batch := []models.TimeTrac{}
+		if err := q.Order("start_at ASC, id ASC").Limit(exportBatchSize).All(&batch); err != nil {
+			return nil, err
+		}
+
+		for _, item := range batch {
+			entry := exportEntry{TimeTrac: item}
+			switch {
+			case item.PhotoKey.Valid && item.PhotoKey.String != "":
+				entry.PhotoFile = "photos/" + item.ID.String() + path.Ext(item.PhotoKey.String)
+				photos = append(photos, exportPhoto{file: entry.PhotoFile, trackID: item.ID, key: item.PhotoKey.String})
+			case item.PhotoData.Valid && item.PhotoData.String != "":
+				data, err := decodePhotoData(item.PhotoData.String)
+				if err != nil {
+					break
+				}
+				mime, _ := sniffPhotoType(data)
+				ext := photoExtensions[mime]
+				if ext == "" {
+					ext = "bin"
+				}
+				entry.PhotoFile = "photos/" + item.ID.String() + "." + ext
+				photos = append(photos, exportPhoto{file: entry.PhotoFile, trackID: item.ID})
+			}
+			entry.PhotoData.Valid, entry.PhotoData.String = false, ""
+
+			b, err := json.Marshal(entry)
+			if err != nil {
+				return nil, err
+			}
+			if !first {
+				if _, err := io.WriteString(w, ",\n"); err != nil {
+					return nil, err
+				}
+			}
+			first = false
+			if _, err := w.Write(b); err != nil {
+				return nil, err
+			}
+		}
+
+		if len(batch) < exportBatchSize {
+			break
+		}
+		last = &batch[len(batch)-1]
+	}
+
+	_, err = io.WriteString(w, "\n]\n")
+	return photos, err
+}
+
+/**
+ * writeExportPhoto copies one photo into the archive, from the storage
+ * backend or from the legacy photo_data column
+ */
+func writeExportPhoto(c buffalo.Context, tx *pop.Connection, zw *zip.Writer, p exportPhoto) error {
+	var src io.Reader
+	if p.key != "" {
+		st, err := photoStore()
+		if err != nil {
+			return err
+		}
+		rc, err := st.Get(c, p.key)
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+		src = rc
+	} else {
+		var item models.TimeTrac
+		if err := tx.Select("id", "photo_data").Where("id = ?", p.trackID).First(&item); err != nil {
+			return err
+		}
+		data, err := decodePhotoData(item.PhotoData.String)
+		if err != nil {
+			return err
+		}
+		src = bytes.NewReader(data)
+	}
+
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: p.file, Method: zip.Store, Modified: time.Now()})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, src)
+	return err
+}
+
+/**
+ * MeDelete deletes the user's account and all personal data
+ *
+ * DELETE /api/me
+ *
+ * Payload: {"password": "<current password>"}
+ *
+ * Within the request transaction this revokes every auth token, deletes
+ * all time entries (with their pauses, revisions and geofence
+ * notifications), templates, preferences, weekly goals, geofences and
+ * team memberships, and deletes teams the user owns alone. Photos are
+ * removed from storage after the commit.
+ *
+ * Teams the user owns that still have other members block the deletion:
+ * ownership must be transferred first.
+ *
+ * Responses:
+ * - 204 on success; the old token gets 401 afterwards
+ * - 401 for an account without a password that did not sign in within
+ *   reauthWindow
+ * - 403 if the password is missing or wrong
+ * - 409 {"error", "teams": [{id, name, members}]} for owned teams with
+ *   other members
+ *
+ * @param c - Buffalo context with authenticated user
+ * @return empty response or JSON error response
+ */
+func MeDelete(c buffalo.Context) error {
+	u, status, msg := reauthenticate(c)
+	if status != 0 {
+		return c.Render(status, r.JSON(map[string]string{"error": msg}))
+	}
+
+	blocking, err := deleteAccount(c, mustTx(c), u.ID)
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot delete account"}))
+	}
+	if len(blocking) > 0 {
+		return c.Render(http.StatusConflict, r.JSON(map[string]any{
+			"error": "transfer ownership of these teams before deleting the account",
+			"teams": blocking,
+		}))
+	}
+
+	c.Response().WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+/**
+ * ownedTeam is a team that blocks an account deletion
+ */
+type ownedTeam struct {
+	ID      uuid.UUID `db:"id" json:"id"`
+	Name    string    `db:"name" json:"name"`
+	Members int       `db:"members" json:"members"`
+}
+
+/**
+ * deleteAccount deletes a user and all their data, as described for
+ * MeDelete. Nothing is deleted while the user owns teams with other
+ * members; those teams are returned instead.
+ *
+ * @param c - Buffalo context; photos are removed after its commit
+ * @param tx - Request transaction
+ * @param uid - User to delete
+ * @return []ownedTeam - Teams blocking the deletion, empty on success
+ */
+func deleteAccount(c buffalo.Context, tx *pop.Connection, uid uuid.UUID) ([]ownedTeam, error) {
+	owned := []ownedTeam{}
+	if err := tx.RawQuery(`
+		SELECT t.id, t.name,
+		       (SELECT COUNT(*) FROM team_members tm WHERE tm.team_id = t.id AND tm.user_id <> ?) AS members
+		FROM teams t
+		WHERE t.owner_id = ?
+		ORDER BY t.name
+	`, uid, uid).All(&owned); err != nil {
+		return nil, err
+	}
+	blocking := []ownedTeam{}
+	for _, t := range owned {
+		if t.Members > 0 {
+			blocking = append(blocking, t)
+		}
+	}
+	if len(blocking) > 0 {
+		return blocking, nil
+	}
+
+	var photos []struct {
+		Key string `db:"photo_key"`
+	}
+	if err := tx.RawQuery(
+		"SELECT photo_key FROM timetrac WHERE user_id = ? AND photo_key IS NOT NULL AND photo_key <> ''", uid,
+	).All(&photos); err != nil {
+		return nil, err
+	}
+
+	// timetrac.user_id has no foreign key; the other tables cascade from users
+	entries, err := tx.RawQuery("DELETE FROM timetrac WHERE user_id = ?", uid).ExecWithCount()
+	if err != nil {
+		return nil, err
+	}
+	for _, stmt := range []string{
+		"UPDATE auth_tokens SET revoked_at = now() WHERE user_id = ? AND revoked_at IS NULL",
+		"DELETE FROM teams WHERE owner_id = ?",
+		"DELETE FROM users WHERE id = ?",
+	} {
+		if err := tx.RawQuery(stmt, uid).Exec(); err != nil {
+			return nil, err
+		}
+	}
+
+	keys := make([]string, 0, len(photos))
+	for _, p := range photos {
+		keys = append(keys, p.Key)
+	}
+	afterCommit(c, func() { deletePhotos(c, keys) })
+	forgetTokens(c, func(tc *tokenCache) { tc.forgetUser(uid) })
+
+	c.Logger().Infof("audit: account deleted user_id=%s entries=%d photos=%d teams_deleted=%d",
+		uid, entries, len(keys), len(owned))
+	return nil, nil
+}
+
+/**
+ * deletePhotos removes objects from photo storage, logging failures;
+ * the database rows referencing them are already gone
+ */
+func deletePhotos(c buffalo.Context, keys []string) {
+	if len(keys) == 0 {
+		return
+	}
+	st, err := photoStore()
+	if err != nil {
+		c.Logger().Errorf("photo storage unavailable, %d photos not deleted: %v", len(keys), err)
+		return
+	}
+	for _, key := range keys {
+		if err := st.Delete(c, key); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			c.Logger().Errorf("cannot delete photo %s: %v", key, err)
+		}
+	}
+}
+
+/**
+ * MePassword changes the user's password
+ *
+ * POST /api/me/password
+ *
+ * Payload:
+ * - current_password: The password in use; an account without one sets
+ *   its first password instead, and must have signed in within
+ *   reauthWindow (401 otherwise)
+ * - new_password: Replacement, following the registration rules
+ *
+ * All other sessions are signed out: their access tokens and refresh
+ * tokens are revoked, while the token used for this request stays valid.
+ *
+ * Responses:
+ * - 200 {"status": "password changed"}
+ * - 422 {"error": "validation failed", "errors": {...}} for a wrong
+ *   current_password or a new_password breaking the rules of
+ *   validators.PasswordProblems; a wrong password is not a 401 so
+ *   clients keep the session
+ * - 429 after 10 password attempts in 15 minutes, counted together with
+ *   reauthenticate
+ *
+ * @param c - Buffalo context with authenticated user
+ * @return JSON status or error response
+ */
+func MePassword(c buffalo.Context) error {
+	var p struct {
+		CurrentPassword string `json:"current_password"`
+		NewPassword     string `json:"new_password"`
+	}
+	if err := c.Bind(&p); err != nil {
+		return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "bad payload"}))
+	}
+	u, ok := CurrentUser(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+	claims, ok := currentTokenClaims(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "invalid token"}))
+	}
+
+	if u.PasswordHash == "" {
+		recent, err := recentSignIn(c, time.Now())
+		if err != nil {
+			return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+		}
+		if !recent {
+			return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "sign in again to continue"}))
+		}
+	} else if !reauthLimit.allow(u.ID.String(), time.Now()) {
+		return c.Render(http.StatusTooManyRequests, r.JSON(map[string]string{"error": "too many attempts"}))
+	} else if bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(p.CurrentPassword)) != nil {
+		return renderValidationErrors(c, map[string][]string{"current_password": {"is incorrect"}})
+	}
+	problems := validators.PasswordProblems(p.NewPassword)
+	if p.NewPassword == p.CurrentPassword {
+		problems = append(problems, "must differ from the current password")
+	}
+	if len(problems) > 0 {
+		return renderValidationErrors(c, map[string][]string{"new_password": problems})
+	}
+
+	hash, err := hasher.Hash(p.NewPassword)
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot change password"}))
+	}
+
+	tx := mustTx(c)
+	now := time.Now().UTC()
+	u.PasswordHash = hash
+	u.UpdatedAt = now
+	if err := tx.UpdateColumns(&u, "password_hash", "updated_at"); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot change password"}))
+	}
+
+	if err := revokeOtherSessions(tx, u.ID, claims, now); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot change password"}))
+	}
+	// Cached tokens carry the user, including the old password hash
+	forgetTokens(c, func(tc *tokenCache) { tc.forgetUser(u.ID) })
+
+	return c.Render(http.StatusOK, r.JSON(map[string]string{"status": "password changed"}))
+}
@@ -0,0 +1,243 @@
+package actions
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"backend/models"
+)
+
+func (as *ActionSuite) Test_MeExport() {
+	token := as.registerToken("export@example.com")
+
+	start := time.Now().Add(-2 * time.Hour).UTC().Truncate(time.Second)
+	res := as.authJSON(token, "/api/tracks/").Post(map[string]any{
+		"project":  "Archive",
+		"start_at": start,
+		"end_at":   start.Add(time.Hour),
+	})
+	as.Equal(http.StatusCreated, res.Code)
+
+	res = as.authJSON(token, "/api/me/export").Post(map[string]string{"password": "wrong"})
+	as.Equal(http.StatusForbidden, res.Code)
+
+	res = as.authJSON(token, "/api/me/export").Post(map[string]string{"password": "secret123"})
+	as.Equal(http.StatusOK, res.Code)
+	as.Equal("application/zip", res.Header().Get("Content-Type"))
+
+	zr, err := zip.NewReader(bytes.NewReader(res.Body.Bytes()), int64(res.Body.Len()))
+	as.NoError(err)
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+	for _, name := range []string{"user.json", "preferences.json", "templates.json", "teams.json", "entries.json"} {
+		as.Contains(files, name)
+	}
+
+	rc, err := files["entries.json"].Open()
+	as.NoError(err)
+	defer rc.Close()
+	var entries []exportEntry
+	as.NoError(json.NewDecoder(rc).Decode(&entries))
+	as.Len(entries, 1)
+	as.Equal("Archive", entries[0].Project)
+}
+
+func (as *ActionSuite) Test_MeDelete() {
+	token := as.registerToken("delete-me@example.com")
+
+	start := time.Now().Add(-2 * time.Hour).UTC().Truncate(time.Second)
+	res := as.authJSON(token, "/api/tracks/").Post(map[string]any{
+		"start_at": start,
+		"end_at":   start.Add(time.Hour),
+	})
+	as.Equal(http.StatusCreated, res.Code)
+
+	res, err := as.authJSON(token, "/api/me").Do(http.MethodDelete, map[string]string{"password": "wrong"})
+	as.NoError(err)
+	as.Equal(http.StatusForbidden, res.Code)
+
+	res, err = as.authJSON(token, "/api/me").Do(http.MethodDelete, map[string]string{"password": "secret123"})
+	as.NoError(err)
+	as.Equal(http.StatusNoContent, res.Code)
+
+	claims, err := ParseJWT(token)
+	as.NoError(err)
+	count, err := as.DB.Where("user_id = ?", claims.UserID).Count(&models.TimeTrac{})
+	as.NoError(err)
+	as.Zero(count)
+
+	res = as.authJSON(token, "/api/me").Get()
+	as.Equal(http.StatusUnauthorized, res.Code)
+}
+
+func (as *ActionSuite) Test_Reauthenticate_RateLimit() {
+	token := as.registerToken("reauth-limit@example.com")
+	for i := 0; i < 10; i++ {
+		res := as.authJSON(token, "/api/me/export").Post(map[string]string{"password": "wrong"})
+		as.Equal(http.StatusForbidden, res.Code)
+	}
+	// Even the right password waits out the window
+	res := as.authJSON(token, "/api/me/export").Post(map[string]string{"password": "secret123"})
+	as.Equal(http.StatusTooManyRequests, res.Code)
+}
+
+func (as *ActionSuite) Test_Reauthenticate_Passwordless() {
+	token := as.registerToken("passwordless@example.com")
+	claims, err := ParseJWT(token)
+	as.NoError(err)
+	// An Apple or magic-link account has no password
+	as.NoError(as.DB.RawQuery("UPDATE users SET password_hash = '' WHERE id = ?", claims.UserID).Exec())
+
+	// A fresh sign-in is enough, no password is asked for
+	res := as.authJSON(token, "/api/me/export").Post(map[string]string{})
+	as.Equal(http.StatusOK, res.Code)
+	res = as.authJSON(token, "/api/me/password").Post(map[string]string{"new_password": "Another-secret-42"})
+	as.Equal(http.StatusOK, res.Code)
+	as.NoError(as.DB.RawQuery("UPDATE users SET password_hash = '' WHERE id = ?", claims.UserID).Exec())
+
+	// An older session must sign in again
+	as.NoError(as.DB.RawQuery("UPDATE refresh_tokens SET created_at = ? WHERE family_id = ?",
+		time.Now().Add(-reauthWindow-time.Minute), claims.Family).Exec())
+	res = as.authJSON(token, "/api/me/export").Post(map[string]string{})
+	as.Equal(http.StatusUnauthorized, res.Code)
+	res, err = as.authJSON(token, "/api/me").Do(http.MethodDelete, map[string]string{})
+	as.NoError(err)
+	as.Equal(http.StatusUnauthorized, res.Code)
+}
+
+func (as *ActionSuite) Test_MePassword() {
+	token := as.registerToken("password@example.com")
+	res := as.JSON("/api/auth/login").Post(map[string]string{"email": "password@example.com", "password": "secret123"})
+	as.Equal(http.StatusOK, res.Code)
+	var other tokenPair
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &other))
+
+	res = as.authJSON(token, "/api/me/password").Post(map[string]string{"current_password": "wrong", "new_password": "newsecret1"})
+	as.Equal(http.StatusUnprocessableEntity, res.Code)
+	as.Contains(res.Body.String(), "current_password")
+
+	res = as.authJSON(token, "/api/me/password").Post(map[string]string{"current_password": "secret123", "new_password": "abc"})
+	as.Equal(http.StatusUnprocessableEntity, res.Code)
+	as.Contains(res.Body.String(), "new_password")
+
+	res = as.authJSON(token, "/api/me/password").Post(map[string]string{"current_password": "secret123", "new_password": "newsecret1"})
+	as.Equal(http.StatusOK, res.Code)
+
+	// The current session survives, the other one is signed out
+	as.Equal(http.StatusOK, as.authJSON(token, "/api/me").Get().Code)
+	as.Equal(http.StatusUnauthorized, as.authJSON(other.Token, "/api/me").Get().Code)
+	res = as.JSON("/api/auth/refresh").Post(map[string]string{"refresh_token": other.RefreshToken})
+	as.Equal(http.StatusUnauthorized, res.Code)
+
+	res = as.JSON("/api/auth/login").Post(map[string]string{"email": "password@example.com", "password": "newsecret1"})
+	as.Equal(http.StatusOK, res.Code)
+}
+
+func (as *ActionSuite) Test_MePassword_RateLimit() {
+	token := as.registerToken("password-limit@example.com")
+	for i := 0; i < 10; i++ {
+		res := as.authJSON(token, "/api/me/password").Post(map[string]string{"current_password": "wrong", "new_password": "newsecret1"})
+		as.Equal(http.StatusUnprocessableEntity, res.Code)
+	}
+	// The right password has to wait too, so guessing gains nothing
+	res := as.authJSON(token, "/api/me/password").Post(map[string]string{"current_password": "secret123", "new_password": "newsecret1"})
+	as.Equal(http.StatusTooManyRequests, res.Code)
+	as.Equal(http.StatusOK, as.JSON("/api/auth/login").Post(map[string]string{"email": "password-limit@example.com", "password": "secret123"}).Code)
+}
+
+func (as *ActionSuite) Test_MeSessions() {
+	token := as.registerToken("sessions@example.com")
+	res := as.JSON("/api/auth/login").Post(map[string]string{"email": "sessions@example.com", "password": "secret123"})
+	as.Equal(http.StatusOK, res.Code)
+	var other tokenPair
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &other))
+
+	res = as.authJSON(token, "/api/me/sessions").Get()
+	as.Equal(http.StatusOK, res.Code)
+	var sessions []sessionInfo
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &sessions))
+	as.Len(sessions, 2)
+
+	var otherJTI string
+	for _, s := range sessions {
+		if !s.Current {
+			otherJTI = s.JTI
+		}
+	}
+	as.NotEmpty(otherJTI)
+
+	res, err := as.authJSON(token, "/api/me/sessions/%s", otherJTI).Do(http.MethodDelete, nil)
+	as.NoError(err)
+	as.Equal(http.StatusOK, res.Code)
+	as.Equal(http.StatusUnauthorized, as.authJSON(other.Token, "/api/me").Get().Code)
+	as.Equal(http.StatusOK, as.authJSON(token, "/api/me").Get().Code)
+}
+
+func (as *ActionSuite) Test_UpdatePreferences() {
+	token := as.registerToken("prefs@example.com")
+
+	res, err := as.authJSON(token, "/api/me/preferences").Do(http.MethodPatch, map[string]any{
+		"timezone":          "Mars/Olympus",
+		"default_color":     "blue",
+		"rounding_minutes":  7,
+		"max_running_hours": 8,
+		"locale":            "fr-FR",
+	})
+	as.NoError(err)
+	as.Equal(http.StatusUnprocessableEntity, res.Code)
+	var body struct {
+		Errors map[string][]string `json:"errors"`
+	}
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &body))
+	as.Len(body.Errors, 4)
+	as.Contains(body.Errors, "timezone")
+	as.Contains(body.Errors, "default_color")
+	as.Contains(body.Errors, "rounding_minutes")
+	as.Contains(body.Errors, "locale")
+
+	// Nothing from the rejected request was saved
+	res = as.authJSON(token, "/api/me/preferences").Get()
+	var prefs models.UserPreferences
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &prefs))
+	as.Equal(models.DefaultMaxRunningHours, prefs.MaxRunningHours)
+	as.Equal(models.DefaultColor, prefs.DefaultColor)
+	as.Equal(models.DefaultLocale, prefs.Locale)
+
+	// Warm the cache, then change the default color: new entries use it
+	as.Equal(http.StatusCreated, as.authJSON(token, "/api/tracks/start").Post(map[string]string{"project": "A"}).Code)
+	as.Equal(http.StatusOK, as.authJSON(token, "/api/tracks/stop").Post(nil).Code)
+	res, err = as.authJSON(token, "/api/me/preferences").Do(http.MethodPatch, map[string]any{"default_color": "#ABC", "rounding_minutes": 15})
+	as.NoError(err)
+	as.Equal(http.StatusOK, res.Code)
+
+	res = as.authJSON(token, "/api/tracks/start").Post(map[string]string{"project": "B"})
+	as.Equal(http.StatusCreated, res.Code)
+	var item models.TimeTrac
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &item))
+	as.Equal("#aabbcc", item.Color)
+
+	// Reports follow the locale preference unless their config names one
+	res, err = as.authJSON(token, "/api/me/preferences").Do(http.MethodPatch, map[string]any{"locale": "de-DE"})
+	as.NoError(err)
+	as.Equal(http.StatusOK, res.Code)
+	var report struct {
+		Data struct {
+			Title  string `json:"title"`
+			Locale string `json:"locale"`
+		} `json:"data"`
+	}
+	res = as.authJSON(token, "/api/reports/generate").Post(map[string]any{"template": "summary"})
+	as.Equal(http.StatusOK, res.Code)
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &report))
+	as.Equal("Zusammenfassung", report.Data.Title)
+	as.Equal("de-DE", report.Data.Locale)
+	res = as.authJSON(token, "/api/reports/generate").Post(map[string]any{"template": "summary", "config": map[string]any{"locale": "en-US"}})
+	as.Equal(http.StatusOK, res.Code)
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &report))
+	as.Equal("Summary Report", report.Data.Title)
+}
@@ -1,9 +1,12 @@
 package actions
 
 import (
+	"encoding/json"
+	"net/http"
 	"os"
 	"testing"
 
+	"github.com/gobuffalo/httptest"
 	"github.com/gobuffalo/suite/v4"
 )
 
@@ -25,3 +28,25 @@ func Test_ActionSuite(t *testing.T) {
 	}
 	suite.Run(t, as)
 }
+
+// registerToken creates a user through the public API and returns its bearer token.
+func (as *ActionSuite) registerToken(email string) string {
+	res := as.JSON("/api/auth/register").Post(map[string]string{
+		"email":    email,
+		"password": "secret123",
+	})
+	as.Equal(http.StatusCreated, res.Code)
+
+	var body struct {
+		Token string `json:"token"`
+	}
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &body))
+	return body.Token
+}
+
+// authJSON builds a JSON request carrying the given bearer token.
+func (as *ActionSuite) authJSON(token, u string, args ...interface{}) *httptest.JSON {
+	req := as.JSON(u, args...)
+	req.Headers["Authorization"] = "Bearer " + token
+	return req
+}
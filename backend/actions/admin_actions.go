@@ -0,0 +1,325 @@
+/**
+ * Admin Actions - User Management for Administrators
+ *
+ * Users with is_admin set manage accounts under /api/admin:
+ * - GET /api/admin/users: list and search users with their entry counts
+ * - POST /api/admin/users/{id}/disable, /enable: disabled users get 403
+ *   from AuthRequired until enabled again
+ * - POST /api/admin/users/{id}/deactivate, /reactivate: see
+ *   deactivation_actions.go
+ * - POST /api/admin/users/{id}/revoke-sessions: sign the user out
+ *   everywhere
+ * - DELETE /api/admin/users/{id}: delete the account as MeDelete does
+ * - GET /api/admin/audit: the audit log
+ *
+ * Every action is written to admin_audit_events in the same transaction.
+ * Admins cannot disable or delete themselves, and API keys cannot reach
+ * these endpoints. The first admin is granted with
+ * `buffalo task users:grant-admin <email>`.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-21
+ */
+package actions
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/models"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/nulls"
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gofrs/uuid"
+)
+
+// adminPageMax bounds per_page on admin listings.
+const adminPageMax = 100
+
+// likeEscaper escapes LIKE wildcards in search terms.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+/**
+ * AdminRequired rejects requests from users who are not admins, and
+ * requests made with API keys. It runs after AuthRequired.
+ */
+func AdminRequired(next buffalo.Handler) buffalo.Handler {
+	return func(c buffalo.Context) error {
+		u, ok := CurrentUser(c)
+		if !ok {
+			return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+		}
+		if _, ok := currentAPIKey(c); ok || !u.IsAdmin {
+			return c.Render(http.StatusForbidden, r.JSON(map[string]string{"error": "admin only"}))
+		}
+		return next(c)
+	}
+}
+
+/**
+ * recordAdminAction writes an audit event
+ *
+ * @param tx - Transaction of the action
+ * @param actor - Admin acting, uuid.Nil for command line tasks
+ * @param target - Account acted on
+ * @param action - models.AdminAction...
+ */
+func recordAdminAction(tx *pop.Connection, actor uuid.UUID, target models.User, action string) error {
+	ev := models.AdminAuditEvent{
+		TargetID:    nulls.NewUUID(target.ID),
+		TargetEmail: nulls.NewString(target.Email),
+		Action:      action,
+	}
+	if actor != uuid.Nil {
+		ev.ActorID = nulls.NewUUID(actor)
+	}
+	return tx.Create(&ev)
+}
+
+/**
+ * RecordAdminAction writes an audit event for a command line task
+ */
+func RecordAdminAction(tx *pop.Connection, target models.User, action string) error {
+	return recordAdminAction(tx, uuid.Nil, target, action)
+}
+
+/**
+ * adminTarget loads the user named by the id parameter; when it fails
+ * the returned status and message describe the error response
+ */
+func adminTarget(c buffalo.Context) (models.User, int, string) {
+	id, err := uuid.FromString(c.Param("id"))
+	if err != nil {
+		return models.User{}, http.StatusBadRequest, "bad id"
+	}
+	var u models.User
+	if err := mustTx(c).Find(&u, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.User{}, http.StatusNotFound, "not found"
+		}
+		return models.User{}, http.StatusInternalServerError, "db error"
+	}
+	return u, 0, ""
+}
+
+/**
+ * adminPage reads page (default 1) and per_page (default 50, up to 100)
+ */
+func adminPage(c buffalo.Context) (page, perPage int, msg string) {
+	page, perPage = 1, 50
+	var err error
+	if v := c.Param("page"); v != "" {
+		if page, err = strconv.Atoi(v); err != nil || page < 1 {
+			return 0, 0, "bad page"
+		}
+	}
+	if v := c.Param("per_page"); v != "" {
+		if perPage, err = strconv.Atoi(v); err != nil || perPage < 1 || perPage > adminPageMax {
+			return 0, 0, "bad per_page"
+		}
+	}
+	return page, perPage, ""
+}
+
+/**
+ * adminUser is a user as listed to admins
+ */
+type adminUser struct {
+	models.User
+	EntryCount int `db:"entry_count" json:"entry_count"`
+}
+
+/**
+ * AdminUsersIndex lists users, newest first
+ *
+ * GET /api/admin/users?q=&page=&per_page=
+ *
+ * Query Parameters:
+ * - q: Case-insensitive substring of the email address
+ * - page, per_page: Pagination (default 1 and 50, per_page up to 100)
+ *
+ * @param c - Buffalo context with an admin
+ * @return JSON array of users with entry_count (entries not in trash)
+ */
+func AdminUsersIndex(c buffalo.Context) error {
+	page, perPage, msg := adminPage(c)
+	if msg != "" {
+		return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": msg}))
+	}
+	pattern := "%" + likeEscaper.Replace(strings.TrimSpace(c.Param("q"))) + "%"
+
+	list := []adminUser{}
+	err := mustTx(c).RawQuery(`
+		SELECT u.*,
+		       (SELECT COUNT(*) FROM timetrac t WHERE t.user_id = u.id AND t.deleted_at IS NULL) AS entry_count
+		FROM users u
+		WHERE u.email ILIKE ?
+		ORDER BY u.created_at DESC, u.id
+		LIMIT ? OFFSET ?
+	`, pattern, perPage, (page-1)*perPage).All(&list)
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+	return c.Render(http.StatusOK, r.JSON(list))
+}
+
+/**
+ * AdminUsersDisable disables an account
+ *
+ * POST /api/admin/users/{id}/disable
+ *
+ * The user's tokens and API keys get 403 until the account is enabled
+ * again; sessions are kept. Disabling a disabled account keeps its
+ * original disabled_at.
+ *
+ * @param c - Buffalo context with an admin and user ID
+ * @return JSON user or error response
+ */
+func AdminUsersDisable(c buffalo.Context) error {
+	return setUserDisabled(c, true)
+}
+
+/**
+ * AdminUsersEnable enables a disabled account
+ *
+ * POST /api/admin/users/{id}/enable
+ *
+ * @param c - Buffalo context with an admin and user ID
+ * @return JSON user or error response
+ */
+func AdminUsersEnable(c buffalo.Context) error {
+	return setUserDisabled(c, false)
+}
+
+/**
+ * setUserDisabled implements AdminUsersDisable and AdminUsersEnable
+ */
+func setUserDisabled(c buffalo.Context, disable bool) error {
+	actor, _ := currentUserID(c)
+	u, status, msg := adminTarget(c)
+	if status != 0 {
+		return c.Render(status, r.JSON(map[string]string{"error": msg}))
+	}
+	if disable && u.ID == actor {
+		return c.Render(http.StatusConflict, r.JSON(map[string]string{"error": "cannot disable yourself"}))
+	}
+
+	tx := mustTx(c)
+	action := models.AdminActionEnable
+	if disable {
+		action = models.AdminActionDisable
+		if !u.DisabledAt.Valid {
+			u.DisabledAt = nulls.NewTime(time.Now().UTC())
+		}
+	} else {
+		u.DisabledAt = nulls.Time{}
+	}
+	if err := tx.RawQuery("UPDATE users SET disabled_at = ? WHERE id = ?", u.DisabledAt, u.ID).Exec(); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+	if err := recordAdminAction(tx, actor, u, action); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+	// Cached tokens carry the user, including disabled_at
+	forgetTokens(c, func(tc *tokenCache) { tc.forgetUser(u.ID) })
+	return c.Render(http.StatusOK, r.JSON(u))
+}
+
+/**
+ * AdminUsersRevokeSessions revokes all access and refresh tokens of a user
+ *
+ * POST /api/admin/users/{id}/revoke-sessions
+ *
+ * @param c - Buffalo context with an admin and user ID
+ * @return JSON status or error response
+ */
+func AdminUsersRevokeSessions(c buffalo.Context) error {
+	actor, _ := currentUserID(c)
+	u, status, msg := adminTarget(c)
+	if status != 0 {
+		return c.Render(status, r.JSON(map[string]string{"error": msg}))
+	}
+
+	tx := mustTx(c)
+	if err := revokeAllSessions(tx, u.ID, time.Now()); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot revoke"}))
+	}
+	if err := recordAdminAction(tx, actor, u, models.AdminActionRevokeSessions); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+	forgetTokens(c, func(tc *tokenCache) { tc.forgetUser(u.ID) })
+	return c.Render(http.StatusOK, r.JSON(map[string]string{"status": "revoked"}))
+}
+
+/**
+ * AdminUsersDelete deletes a user and all their data
+ *
+ * DELETE /api/admin/users/{id}
+ *
+ * Same cascade as MeDelete, including the 409 for owned teams with
+ * other members.
+ *
+ * @param c - Buffalo context with an admin and user ID
+ * @return JSON status or error response
+ */
+func AdminUsersDelete(c buffalo.Context) error {
+	actor, _ := currentUserID(c)
+	u, status, msg := adminTarget(c)
+	if status != 0 {
+		return c.Render(status, r.JSON(map[string]string{"error": msg}))
+	}
+	if u.ID == actor {
+		return c.Render(http.StatusConflict, r.JSON(map[string]string{"error": "cannot delete yourself"}))
+	}
+
+	tx := mustTx(c)
+	blocking, err := deleteAccount(c, tx, u.ID)
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot delete account"}))
+	}
+	if len(blocking) > 0 {
+		return c.Render(http.StatusConflict, r.JSON(map[string]any{
+			"error": "the user owns teams with other members",
+			"teams": blocking,
+		}))
+	}
+	if err := recordAdminAction(tx, actor, u, models.AdminActionDelete); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+	return c.Render(http.StatusOK, r.JSON(map[string]string{"status": "deleted"}))
+}
+
+/**
+ * AdminAuditIndex lists audit events, newest first
+ *
+ * GET /api/admin/audit?target_id=&page=&per_page=
+ *
+ * @param c - Buffalo context with an admin
+ * @return JSON array of AdminAuditEvent or error response
+ */
+func AdminAuditIndex(c buffalo.Context) error {
+	page, perPage, msg := adminPage(c)
+	if msg != "" {
+		return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": msg}))
+	}
+	q := mustTx(c).Order("created_at DESC, id").Paginate(page, perPage)
+	if v := c.Param("target_id"); v != "" {
+		id, err := uuid.FromString(v)
+		if err != nil {
+			return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "bad target_id"}))
+		}
+		q = q.Where("target_id = ?", id)
+	}
+
+	list := []models.AdminAuditEvent{}
+	if err := q.All(&list); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+	return c.Render(http.StatusOK, r.JSON(list))
+}
@@ -0,0 +1,130 @@
+package actions
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"backend/models"
+
+	"github.com/gofrs/uuid"
+)
+
+// adminToken registers a user and makes them an admin.
+func (as *ActionSuite) adminToken(email string) (string, uuid.UUID) {
+	token := as.registerToken(email)
+	claims, err := ParseJWT(token)
+	as.NoError(err)
+	uid := uuid.FromStringOrNil(claims.UserID)
+	as.NoError(as.DB.RawQuery("UPDATE users SET is_admin = true WHERE id = ?", uid).Exec())
+	currentTokenCache().forgetUser(uid)
+	return token, uid
+}
+
+func (as *ActionSuite) Test_Admin_RequiresAdmin() {
+	token := as.registerToken("plain@example.com")
+	res := as.authJSON(token, "/api/admin/users").Get()
+	as.Equal(http.StatusForbidden, res.Code)
+}
+
+func (as *ActionSuite) Test_Admin_Users() {
+	adminToken, adminID := as.adminToken("admin@example.com")
+	userToken := as.registerToken("someone@example.com")
+	claims, err := ParseJWT(userToken)
+	as.NoError(err)
+	uid := claims.UserID
+
+	start := time.Now().Add(-2 * time.Hour).UTC().Truncate(time.Second)
+	res := as.authJSON(userToken, "/api/tracks/").Post(map[string]any{"start_at": start, "end_at": start.Add(time.Hour)})
+	as.Equal(http.StatusCreated, res.Code)
+
+	// Search with entry counts; wildcards are literal
+	res = as.authJSON(adminToken, "/api/admin/users?q=SOMEONE").Get()
+	as.Equal(http.StatusOK, res.Code)
+	var list []adminUser
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &list))
+	as.Len(list, 1)
+	as.Equal(1, list[0].EntryCount)
+	res = as.authJSON(adminToken, "/api/admin/users?q=%%25").Get()
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &list))
+	as.Empty(list)
+
+	// Disabled users get 403 until enabled
+	as.Equal(http.StatusOK, as.authJSON(adminToken, "/api/admin/users/%s/disable", uid).Post(nil).Code)
+	res = as.authJSON(userToken, "/api/me").Get()
+	as.Equal(http.StatusForbidden, res.Code)
+	as.Contains(res.Body.String(), "account disabled")
+	as.Equal(http.StatusForbidden, as.HTML("/api/tracks/events?token=%s", userToken).Get().Code)
+	as.Equal(http.StatusOK, as.authJSON(adminToken, "/api/admin/users/%s/enable", uid).Post(nil).Code)
+	as.Equal(http.StatusOK, as.authJSON(userToken, "/api/me").Get().Code)
+
+	as.Equal(http.StatusConflict, as.authJSON(adminToken, "/api/admin/users/%s/disable", adminID).Post(nil).Code)
+
+	// Revoking sessions signs the user out
+	as.Equal(http.StatusOK, as.authJSON(adminToken, "/api/admin/users/%s/revoke-sessions", uid).Post(nil).Code)
+	as.Equal(http.StatusUnauthorized, as.authJSON(userToken, "/api/me").Get().Code)
+
+	// Deletion cascades like self-deletion
+	res, err = as.authJSON(adminToken, "/api/admin/users/%s", uid).Do(http.MethodDelete, nil)
+	as.NoError(err)
+	as.Equal(http.StatusOK, res.Code)
+	count, err := as.DB.Where("user_id = ?", uid).Count(&models.TimeTrac{})
+	as.NoError(err)
+	as.Zero(count)
+
+	// Every action is audited, and the log outlives the target
+	res = as.authJSON(adminToken, "/api/admin/audit?target_id=%s", uid).Get()
+	as.Equal(http.StatusOK, res.Code)
+	var events []models.AdminAuditEvent
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &events))
+	actions := []string{}
+	for _, ev := range events {
+		as.Equal(adminID, ev.ActorID.UUID)
+		as.Equal("someone@example.com", ev.TargetEmail.String)
+		actions = append(actions, ev.Action)
+	}
+	as.ElementsMatch([]string{
+		models.AdminActionDisable, models.AdminActionEnable,
+		models.AdminActionRevokeSessions, models.AdminActionDelete,
+	}, actions)
+}
+
+func (as *ActionSuite) Test_MeDeactivate_ReactivatesOnLogin() {
+	token := as.registerToken("leaving@example.com")
+	res := as.authJSON(token, "/api/me/deactivate").Post(map[string]string{"password": "secret123"})
+	as.Equal(http.StatusNoContent, res.Code)
+	as.Equal(http.StatusUnauthorized, as.authJSON(token, "/api/me").Get().Code)
+
+	res = as.JSON("/api/auth/login").Post(map[string]string{"email": "leaving@example.com", "password": "secret123"})
+	as.Equal(http.StatusOK, res.Code)
+	var u models.User
+	as.NoError(as.DB.Where("email = ?", "leaving@example.com").First(&u))
+	as.False(u.DeactivatedAt.Valid)
+}
+
+func (as *ActionSuite) Test_Admin_Deactivate() {
+	adminToken, _ := as.adminToken("hr@example.com")
+	userToken := as.registerToken("contractor@example.com")
+	claims, err := ParseJWT(userToken)
+	as.NoError(err)
+	uid := claims.UserID
+
+	start := time.Now().Add(-2 * time.Hour).UTC().Truncate(time.Second)
+	res := as.authJSON(userToken, "/api/tracks/").Post(map[string]any{"start_at": start, "end_at": start.Add(time.Hour)})
+	as.Equal(http.StatusCreated, res.Code)
+
+	as.Equal(http.StatusOK, as.authJSON(adminToken, "/api/admin/users/%s/deactivate", uid).Post(nil).Code)
+	as.Equal(http.StatusUnauthorized, as.authJSON(userToken, "/api/me").Get().Code)
+
+	// Signing in is refused with the deactivation code; the data is kept
+	res = as.JSON("/api/auth/login").Post(map[string]string{"email": "contractor@example.com", "password": "secret123"})
+	as.Equal(http.StatusForbidden, res.Code)
+	as.Contains(res.Body.String(), codeAccountDeactivated)
+	count, err := as.DB.Where("user_id = ?", uid).Count(&models.TimeTrac{})
+	as.NoError(err)
+	as.Equal(1, count)
+
+	as.Equal(http.StatusOK, as.authJSON(adminToken, "/api/admin/users/%s/reactivate", uid).Post(nil).Code)
+	res = as.JSON("/api/auth/login").Post(map[string]string{"email": "contractor@example.com", "password": "secret123"})
+	as.Equal(http.StatusOK, res.Code)
+}
@@ -0,0 +1,60 @@
+/**
+ * After-Commit Hooks - Side Effects Outside the Database
+ *
+ * Handlers that must touch external systems (e.g. delete objects from
+ * photo storage, publish live timer events) register the work with
+ * afterCommit. It runs only when the request transaction was committed,
+ * so a rollback never leaves the database pointing at data that is
+ * already gone, and no client hears of a change that did not happen.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-21
+ */
+package actions
+
+import (
+	"net/http"
+
+	"github.com/gobuffalo/buffalo"
+)
+
+// afterCommitKey is the context key for hooks queued by a handler.
+const afterCommitKey = "after_commit_hooks"
+
+/**
+ * afterCommit queues fn to run after the request transaction commits
+ *
+ * Outside runAfterCommit (e.g. in tests calling handlers directly) the
+ * hook is dropped.
+ */
+func afterCommit(c buffalo.Context, fn func()) {
+	if hooks, ok := c.Value(afterCommitKey).(*[]func()); ok {
+		*hooks = append(*hooks, fn)
+	}
+}
+
+/**
+ * runAfterCommit runs the hooks queued during a request after the request
+ * succeeded. It must be registered before the transaction middleware so
+ * it runs after the commit.
+ */
+func runAfterCommit(next buffalo.Handler) buffalo.Handler {
+	return func(c buffalo.Context) error {
+		hooks := []func(){}
+		c.Set(afterCommitKey, &hooks)
+
+		err := next(c)
+		if err != nil {
+			return err
+		}
+		// The transaction middleware rolls back responses >= 400
+		if res, ok := c.Response().(*buffalo.Response); ok && res.Status >= http.StatusBadRequest {
+			return nil
+		}
+		for _, fn := range hooks {
+			fn()
+		}
+		return nil
+	}
+}
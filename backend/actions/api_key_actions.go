@@ -0,0 +1,313 @@
+/**
+ * API Key Actions - Personal Access Tokens
+ *
+ * Scripts and integrations authenticate with `Authorization: Bearer
+ * ttk_...` instead of a JWT; AuthRequired accepts both. Keys do not
+ * expire unless the user sets an expiry, and are revoked by deleting
+ * them.
+ *
+ * Scopes:
+ * - read: GET requests only
+ * - write: every request
+ * - introspect: token introspection only (see introspect_actions.go);
+ *   only administrators may create such keys
+ *
+ * Keys cannot manage API keys themselves, so a leaked key cannot mint
+ * new ones. Nor can they change the account, its sessions, devices or
+ * webhooks (see refuseAPIKeys).
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-21
+ */
+package actions
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"backend/models"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/nulls"
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gofrs/uuid"
+)
+
+// currentAPIKeyKey is the context key of the API key a request uses.
+const currentAPIKeyKey = "current_api_key"
+
+// maxAPIKeyName matches the api_keys.name column.
+const maxAPIKeyName = 100
+
+/**
+ * newAPIKey returns a random key and its prefix and secret parts
+ */
+func newAPIKey() (key, prefix, secret string, err error) {
+	buf := make([]byte, 5+32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", "", err
+	}
+	prefix = hex.EncodeToString(buf[:5])
+	secret = base64.RawURLEncoding.EncodeToString(buf[5:])
+	return models.APIKeyPrefix + prefix + "_" + secret, prefix, secret, nil
+}
+
+/**
+ * isAPIKey reports whether a bearer credential is an API key
+ */
+func isAPIKey(raw string) bool {
+	return strings.HasPrefix(raw, models.APIKeyPrefix)
+}
+
+/**
+ * userFromAPIKey resolves an API key to its user
+ *
+ * @param tx - Database connection
+ * @param raw - Key from the Authorization header
+ * @param now - Reference time for expiry
+ * @return models.User, models.APIKey - Owner and key when msg is ""
+ * @return string - Error message for a 401 response
+ */
+func userFromAPIKey(tx *pop.Connection, raw string, now time.Time) (models.User, models.APIKey, string) {
+	prefix, secret, ok := strings.Cut(strings.TrimPrefix(raw, models.APIKeyPrefix), "_")
+	if !ok || prefix == "" || secret == "" {
+		return models.User{}, models.APIKey{}, "invalid api key"
+	}
+
+	var key models.APIKey
+	if err := tx.Where("prefix = ?", prefix).First(&key); err != nil {
+		return models.User{}, models.APIKey{}, "invalid api key"
+	}
+	if subtle.ConstantTimeCompare([]byte(models.HashAPIKeySecret(secret)), []byte(key.SecretHash)) != 1 {
+		return models.User{}, models.APIKey{}, "invalid api key"
+	}
+	if key.ExpiresAt.Valid && !now.Before(key.ExpiresAt.Time) {
+		return models.User{}, models.APIKey{}, "api key expired"
+	}
+
+	var u models.User
+	if err := tx.Find(&u, key.UserID); err != nil {
+		return models.User{}, models.APIKey{}, "user not found"
+	}
+
+	// Like session tokens, record use at most every few minutes
+	if !key.LastUsedAt.Valid || now.Sub(key.LastUsedAt.Time) >= models.AuthTokenTouchInterval {
+		_ = tx.RawQuery("UPDATE api_keys SET last_used_at = ? WHERE id = ?", now.UTC(), key.ID).Exec()
+	}
+	return u, key, ""
+}
+
+/**
+ * currentAPIKey returns the API key the request authenticated with, if any
+ */
+func currentAPIKey(c buffalo.Context) (models.APIKey, bool) {
+	key, ok := c.Value(currentAPIKeyKey).(models.APIKey)
+	return key, ok
+}
+
+/**
+ * requireKeyScope rejects requests made with an API key lacking the
+ * scope the method needs: read for GET and HEAD, write otherwise.
+ * GET routes with side effects are wrapped in requireWriteScope.
+ * Requests authenticated with a JWT pass unchanged.
+ */
+func requireKeyScope(next buffalo.Handler) buffalo.Handler {
+	return func(c buffalo.Context) error {
+		key, ok := currentAPIKey(c)
+		if !ok {
+			return next(c)
+		}
+		scope := models.APIKeyScopeWrite
+		if m := c.Request().Method; m == http.MethodGet || m == http.MethodHead {
+			scope = models.APIKeyScopeRead
+		}
+		if !key.Allows(scope) {
+			return c.Render(http.StatusForbidden, r.JSON(map[string]string{"error": "api key lacks " + scope + " scope"}))
+		}
+		return next(c)
+	}
+}
+
+/**
+ * requireWriteScope wraps a GET handler that changes state, such as one
+ * issuing a code, so an API key needs the write scope to call it
+ */
+func requireWriteScope(next buffalo.Handler) buffalo.Handler {
+	return func(c buffalo.Context) error {
+		if key, ok := currentAPIKey(c); ok && !key.Allows(models.APIKeyScopeWrite) {
+			return c.Render(http.StatusForbidden, r.JSON(map[string]string{"error": "api key lacks " + models.APIKeyScopeWrite + " scope"}))
+		}
+		return next(c)
+	}
+}
+
+/**
+ * refuseAPIKeys rejects requests made with an API key, whatever its
+ * scopes. It guards account, session, device and webhook routes, so a
+ * leaked key cannot take over the account or redirect its data.
+ */
+func refuseAPIKeys(next buffalo.Handler) buffalo.Handler {
+	return func(c buffalo.Context) error {
+		if _, ok := currentAPIKey(c); ok {
+			return c.Render(http.StatusForbidden, r.JSON(map[string]string{"error": "api keys cannot manage the account"}))
+		}
+		return next(c)
+	}
+}
+
+/**
+ * APIKeysIndex lists the user's API keys
+ *
+ * GET /api/me/api-keys
+ *
+ * Secrets are never included; keys are told apart by name and prefix.
+ *
+ * @param c - Buffalo context with authenticated user
+ * @return JSON array of APIKey or error response
+ */
+func APIKeysIndex(c buffalo.Context) error {
+	uid, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+	if _, ok := currentAPIKey(c); ok {
+		return c.Render(http.StatusForbidden, r.JSON(map[string]string{"error": "api keys cannot manage api keys"}))
+	}
+
+	list := []models.APIKey{}
+	if err := mustTx(c).Where("user_id = ?", uid).Order("created_at DESC").All(&list); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+	return c.Render(http.StatusOK, r.JSON(list))
+}
+
+/**
+ * APIKeysCreate creates an API key
+ *
+ * POST /api/me/api-keys
+ *
+ * Payload:
+ * - name: Label (required, up to 100 characters)
+ * - scopes: Subset of read, write (default: read); introspect is
+ *   reserved for administrators
+ * - expires_at: Optional RFC 3339 expiry in the future
+ *
+ * The response is the only one that includes the full key. A user may
+ * keep up to 20 keys; creating more returns 409.
+ *
+ * @param c - Buffalo context with authenticated user
+ * @return JSON APIKey with key or error response
+ */
+func APIKeysCreate(c buffalo.Context) error {
+	var p struct {
+		Name      string     `json:"name"`
+		Scopes    []string   `json:"scopes"`
+		ExpiresAt *time.Time `json:"expires_at"`
+	}
+	if err := c.Bind(&p); err != nil {
+		return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "bad payload"}))
+	}
+
+	uid, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+	if _, ok := currentAPIKey(c); ok {
+		return c.Render(http.StatusForbidden, r.JSON(map[string]string{"error": "api keys cannot manage api keys"}))
+	}
+
+	name := strings.TrimSpace(p.Name)
+	if name == "" {
+		return renderFieldError(c, "name", errors.New("required"))
+	}
+	if len(name) > maxAPIKeyName {
+		return renderFieldError(c, "name", errors.New("too long"))
+	}
+	scopes := []string{}
+	for _, s := range p.Scopes {
+		s = strings.TrimSpace(s)
+		if !slices.Contains(models.APIKeyScopes, s) {
+			return renderFieldError(c, "scopes", errors.New("unknown scope "+s))
+		}
+		if s == models.APIKeyScopeIntrospect {
+			if u, ok := CurrentUser(c); !ok || !u.IsAdmin {
+				return c.Render(http.StatusForbidden, r.JSON(map[string]string{"error": "introspect scope is reserved for administrators"}))
+			}
+		}
+		if !slices.Contains(scopes, s) {
+			scopes = append(scopes, s)
+		}
+	}
+	if len(scopes) == 0 {
+		scopes = []string{models.APIKeyScopeRead}
+	}
+	key := models.APIKey{UserID: uid, Name: name, Scopes: scopes}
+	if p.ExpiresAt != nil {
+		if !p.ExpiresAt.After(time.Now()) {
+			return renderFieldError(c, "expires_at", errors.New("must be in the future"))
+		}
+		key.ExpiresAt = nulls.NewTime(p.ExpiresAt.UTC())
+	}
+
+	tx := mustTx(c)
+	count, err := tx.Where("user_id = ?", uid).Count(&models.APIKey{})
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+	if count >= models.MaxAPIKeys {
+		return c.Render(http.StatusConflict, r.JSON(map[string]string{"error": "api key limit reached"}))
+	}
+
+	raw, prefix, secret, err := newAPIKey()
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot create"}))
+	}
+	key.Prefix = prefix
+	key.SecretHash = models.HashAPIKeySecret(secret)
+	if err := tx.Create(&key); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot create"}))
+	}
+	return c.Render(http.StatusCreated, r.JSON(struct {
+		models.APIKey
+		Key string `json:"key"`
+	}{key, raw}))
+}
+
+/**
+ * APIKeysDelete revokes an API key
+ *
+ * DELETE /api/me/api-keys/{id}
+ *
+ * @param c - Buffalo context with authenticated user and key ID
+ * @return JSON status or error response
+ */
+func APIKeysDelete(c buffalo.Context) error {
+	uid, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+	if _, ok := currentAPIKey(c); ok {
+		return c.Render(http.StatusForbidden, r.JSON(map[string]string{"error": "api keys cannot manage api keys"}))
+	}
+	id, err := uuid.FromString(c.Param("id"))
+	if err != nil {
+		return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "bad id"}))
+	}
+
+	n, err := mustTx(c).RawQuery("DELETE FROM api_keys WHERE id = ? AND user_id = ?", id, uid).ExecWithCount()
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot delete"}))
+	}
+	if n == 0 {
+		return c.Render(http.StatusNotFound, r.JSON(map[string]string{"error": "not found"}))
+	}
+	return c.Render(http.StatusOK, r.JSON(map[string]string{"status": "deleted"}))
+}
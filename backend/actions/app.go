@@ -4,9 +4,12 @@
 package actions
 
 import (
+	"os"
+	"strings"
 	"sync"
 
 	"backend/locales"
+	"backend/logging"
 	"backend/models"
 
 	"github.com/gobuffalo/buffalo"
@@ -15,7 +18,6 @@ import (
 	"github.com/gobuffalo/middleware/contenttype"
 	"github.com/gobuffalo/middleware/forcessl"
 	"github.com/gobuffalo/middleware/i18n"
-	"github.com/gobuffalo/middleware/paramlogger"
 	"github.com/gobuffalo/x/sessions"
 	"github.com/rs/cors"
 	"github.com/unrolled/secure"
@@ -46,78 +48,296 @@ func App() *buffalo.App {
 			AllowedHeaders: []string{
 				"Authorization", "Content-Type", "Accept", "Origin", "X-Requested-With",
 				"Access-Control-Request-Method", "Access-Control-Request-Headers",
+				"Idempotency-Key", requestIDHeader,
 			},
-			ExposedHeaders:      []string{"Content-Type"},
+			ExposedHeaders:      []string{"Content-Type", "Deprecation", "Warning", "Idempotent-Replayed", "Content-Disposition", refreshedTokenHeader, requestIDHeader},
 			AllowCredentials:    true,
 			AllowPrivateNetwork: true,
 		})
 
+		logger, logErr := logging.FromEnv(os.Getenv)
+		logging.SetDefault(logger)
+
 		app = buffalo.New(buffalo.Options{
 			Env:          ENV,
+			Logger:       buffaloLogger{logger},
 			SessionStore: sessions.Null{},
 			PreWares: []buffalo.PreWare{
 				c.Handler, // ✅ handle preflight before Buffalo routes/middleware
+				captureSlackBody,
 			},
 			SessionName: "_backend_session",
 		})
+		if logErr != nil {
+			app.Logger.Errorf("logging, using the defaults: %v", logErr)
+		}
+
+		// Request IDs and one structured log line per request, in place of
+		// Buffalo's request logger: outside its error handling, so error
+		// pages carry the ID and are logged with their status
+		app.Middleware.Replace(buffalo.RequestLogger, func(next buffalo.Handler) buffalo.Handler {
+			return requestLogger(requestID(next))
+		})
 
 		// HTTPS in production
 		app.Use(forceSSL())
 
 		// JSON API
-		app.Use(contenttype.Set("application/json"))
-		app.Use(paramlogger.ParameterLogger)
+		app.Use(jsonContentType())
 
 		// i18n (optional)
 		app.Use(translations())
 
+		// Live timer events, published after the request transaction commits
+		trackEvents = newEventHub()
+
+		// Side effects (live events, photo storage) that must follow the commit
+		app.Use(runAfterCommit)
+
 		// DB transaction per request
-		app.Use(popmw.Transaction(models.DB))
+		txm := popmw.Transaction(models.DB)
+		app.Use(txm)
 
 		app.GET("/", HomeHandler)
 
+		// Public keys verifying access tokens
+		app.GET("/.well-known/jwks.json", JWKS)
+		app.Middleware.Skip(txm, JWKS)
+
+		// Process counters for monitoring (disabled without METRICS_TOKEN)
+		app.GET("/metrics", Metrics)
+		app.Middleware.Skip(txm, Metrics)
+
 		// Public auth
 		auth := app.Group("/api/auth")
 		auth.POST("/register", Register)
 		auth.POST("/login", Login)
+		auth.POST("/apple", AppleSignIn)
+		auth.POST("/magic-link", MagicLinkRequest)
+		auth.GET("/magic-link/consume", MagicLinkConsume)
+		auth.POST("/magic-link/consume", MagicLinkConsume)
+		auth.POST("/refresh", RefreshToken)
+		auth.POST("/introspect", Introspect)
+		// Refresh commits a family revocation along with its 401 response
+		auth.Middleware.Skip(txm, RefreshToken)
+
+		// Team invitation preview for the sign-up page
+		app.GET("/api/invitations/{token}", InvitationShow)
+
+		// Scheduled report download links, authenticated by their signature
+		app.GET("/api/reports/runs/{id}/download", DownloadReportRun)
+
+		// Slack slash command, authenticated by Slack's request signature
+		app.POST(slackCommandPath, SlackCommand)
+
+		// Event stream authenticates itself and must not hold a transaction open
+		app.GET("/api/tracks/events", TracksEvents)
+		app.Middleware.Skip(txm, TracksEvents)
 
 		// Protected
 		api := app.Group("/api")
 		api.Use(AuthRequired)
+		// API keys: read scope for GET only
+		api.Use(requireKeyScope)
 		api.GET("/me", Me)
+		api.DELETE("/me", refuseAPIKeys(MeDelete))
+		api.POST("/me/deactivate", refuseAPIKeys(MeDeactivate))
+		api.GET("/me/export", MeExport)
+		api.POST("/me/export", MeExport)
+		api.POST("/me/password", refuseAPIKeys(MePassword))
+		api.GET("/me/sessions", refuseAPIKeys(MeSessions))
+		api.GET("/me/security/logins", refuseAPIKeys(MeSecurityLogins))
+		api.DELETE("/me/sessions", refuseAPIKeys(MeSessionsRevokeOthers))
+		api.DELETE("/me/sessions/{jti}", refuseAPIKeys(MeSessionRevoke))
+		api.GET("/me/api-keys", APIKeysIndex)
+		api.POST("/me/api-keys", APIKeysCreate)
+		api.DELETE("/me/api-keys/{id}", APIKeysDelete)
+		api.GET("/me/devices", refuseAPIKeys(DevicesIndex))
+		api.POST("/me/devices", refuseAPIKeys(DevicesRegister))
+		api.DELETE("/me/devices/{id}", refuseAPIKeys(DevicesDelete))
 		api.POST("/logout", Logout)
+		api.GET("/me/preferences", GetPreferences)
+		api.PATCH("/me/preferences", UpdatePreferences)
+
+		// Administration (admins only)
+		admin := api.Group("/admin")
+		admin.Use(AdminRequired)
+		admin.GET("/users", AdminUsersIndex)
+		admin.POST("/users/{id}/disable", AdminUsersDisable)
+		admin.POST("/users/{id}/enable", AdminUsersEnable)
+		admin.POST("/users/{id}/deactivate", AdminUsersDeactivate)
+		admin.POST("/users/{id}/reactivate", AdminUsersReactivate)
+		admin.POST("/users/{id}/revoke-sessions", AdminUsersRevokeSessions)
+		admin.DELETE("/users/{id}", AdminUsersDelete)
+		admin.GET("/audit", AdminAuditIndex)
 
 		// Time tracking (protected)
 		tracks := api.Group("/tracks")
 		tracks.GET("/", TracksIndex)
-		tracks.POST("/start", TracksStart)
-		tracks.POST("/stop", TracksStop)
+		tracks.GET("/summary", TracksSummary)
+		tracks.GET("/stats/compare", TracksStatsCompare)
+		tracks.GET("/stats/distribution", TracksStatsDistribution)
+		tracks.GET("/tags/summary", TracksTagsSummary)
+		tracks.GET("/refs/summary", TracksRefsSummary)
+		tracks.GET("/trash", TracksTrash)
+		tracks.GET("/short", TracksShortIndex)
+		tracks.DELETE("/short", TracksShortDelete)
+		tracks.POST("/", idempotent(TracksCreate))
+		tracks.POST("/start", idempotent(TracksStart))
+		tracks.POST("/stop", idempotent(TracksStop))
+		tracks.POST("/merge", TracksMerge)
+		tracks.POST("/sync", TracksSync)
+		tracks.GET("/templates", TemplatesIndex)
+		tracks.POST("/templates", TemplatesCreate)
+		tracks.PUT("/templates/positions", TemplatesReorder)
+		tracks.PATCH("/templates/{id}", TemplatesUpdate)
+		tracks.DELETE("/templates/{id}", TemplatesDelete)
+		tracks.POST("/templates/{id}/start", idempotent(TemplatesStart))
 		tracks.PATCH("/{id}", TracksUpdate)
 		tracks.DELETE("/{id}", TracksDelete)
+		tracks.POST("/{id}/split", TracksSplit)
+		tracks.POST("/{id}/restart", TracksRestart)
+		tracks.POST("/{id}/pause", TracksPause)
+		tracks.POST("/{id}/resume", TracksResume)
+		tracks.POST("/{id}/restore", TracksRestore)
+		tracks.GET("/{id}/history", TracksHistory)
+		tracks.GET("/{id}/photo", TracksGetPhoto)
+		tracks.POST("/{id}/photo", TracksUploadPhoto)
+		tracks.DELETE("/{id}/photo", TracksDeletePhoto)
+
+		// Integrations (protected)
+		api.GET("/integrations/slack/link", requireWriteScope(SlackLinkCode))
+
+		// Webhooks (protected)
+		webhooks := api.Group("/webhooks")
+		webhooks.Use(refuseAPIKeys)
+		webhooks.GET("/", WebhooksIndex)
+		webhooks.POST("/", WebhooksCreate)
+		webhooks.PATCH("/{id}", WebhooksUpdate)
+		webhooks.DELETE("/{id}", WebhooksDelete)
+		webhooks.GET("/{id}/deliveries", WebhooksDeliveries)
+
+		// Weekly goal (protected)
+		api.GET("/goals/history", GoalsHistory)
+
+		// Projects (protected)
+		projects := api.Group("/projects")
+		projects.GET("/", ProjectsIndex)
+		projects.GET("/{id}/burndown", GetProjectBurndown)
+		projects.POST("/{name}/archive", ProjectsArchive)
+		projects.DELETE("/{name}/archive", ProjectsUnarchive)
+
+		// Geofences and location pings (protected)
+		geofences := api.Group("/geofences")
+		geofences.GET("/", GeofencesIndex)
+		geofences.POST("/", GeofencesCreate)
+		geofences.GET("/notifications", GeofenceNotificationsIndex)
+		geofences.POST("/notifications/{id}/dismiss", GeofenceNotificationsDismiss)
+		geofences.PATCH("/{id}", GeofencesUpdate)
+		geofences.DELETE("/{id}", GeofencesDelete)
+		api.POST("/location/ping", LocationPing)
 
 		// Team management (protected)
 		teams := api.Group("/teams")
 		teams.POST("/", CreateTeam)
 		teams.GET("/", GetTeams)
+		teams.POST("/join", JoinTeamWithCode)
 		teams.GET("/{id}", GetTeam)
+		teams.PATCH("/{id}", UpdateTeam)
+		teams.DELETE("/{id}", DeleteTeam)
+		teams.POST("/{id}/archive", ArchiveTeam)
+		teams.POST("/{id}/unarchive", UnarchiveTeam)
+		teams.GET("/{id}/avatar", GetTeamAvatar)
+		teams.POST("/{id}/avatar", UploadTeamAvatar)
+		teams.DELETE("/{id}/avatar", DeleteTeamAvatar)
+		teams.GET("/{id}/tracks", GetTeamTracks)
+		teams.GET("/{id}/summary", GetTeamSummary)
+		teams.GET("/{id}/projects", GetTeamProjects)
+		teams.POST("/{id}/projects", CreateTeamProject)
+		teams.PATCH("/{id}/projects/{project_id}", UpdateTeamProject)
+		teams.DELETE("/{id}/projects/{project_id}", DeleteTeamProject)
+		teams.GET("/{id}/projects/{project_id}/members", GetProjectMembers)
+		teams.POST("/{id}/projects/{project_id}/members", AddProjectMember)
+		teams.DELETE("/{id}/projects/{project_id}/members/{user_id}", RemoveProjectMember)
+		teams.GET("/{id}/roles", GetTeamRoles)
+		teams.POST("/{id}/roles", CreateTeamRole)
+		teams.PATCH("/{id}/roles/{role_id}", UpdateTeamRole)
+		teams.DELETE("/{id}/roles/{role_id}", DeleteTeamRole)
+		teams.GET("/{id}/timesheets", GetTeamTimesheets)
+		teams.POST("/{id}/timesheets/{week}/submit", SubmitTimesheet)
+		teams.POST("/{id}/timesheets/{timesheet_id}/approve", ApproveTimesheet)
+		teams.POST("/{id}/timesheets/{timesheet_id}/reject", RejectTimesheet)
+		teams.POST("/{id}/timesheets/{timesheet_id}/revoke", RevokeTimesheetApproval)
 		teams.POST("/{id}/invite", InviteMember)
+		teams.POST("/{id}/invitations/batch", InviteMembersBatch)
+		teams.GET("/{id}/join-codes", GetTeamJoinCodes)
+		teams.POST("/{id}/join-codes", CreateTeamJoinCode)
+		teams.DELETE("/{id}/join-codes/{code_id}", DeleteTeamJoinCode)
+		teams.GET("/{id}/members", GetTeamMembers)
 		teams.PUT("/{id}/members/{member_id}", UpdateMemberRole)
 		teams.DELETE("/{id}/members/{member_id}", RemoveMember)
+		teams.GET("/{id}/members/{member_id}/rate", GetMemberRate)
+		teams.PUT("/{id}/members/{member_id}/rate", SetMemberRate)
+		teams.POST("/{id}/members/{member_id}/suspend", SuspendMember)
+		teams.POST("/{id}/members/{member_id}/reactivate", ReactivateMember)
 
 		// Team invitations (protected)
 		invitations := api.Group("/teams/invitations")
 		invitations.POST("/{id}/accept", AcceptInvitation)
 		invitations.POST("/{id}/decline", DeclineInvitation)
+		api.POST("/invitations/{token}/accept", InvitationAccept)
 
 		// Reports endpoints (protected)
+		api.POST("/reports/generate", GenerateReport)
+		api.POST("/reports/preview", PreviewReport)
+		api.GET("/reports/charts", GetReportCharts)
+		api.POST("/reports/jobs", CreateReportJob)
+		api.GET("/reports/jobs/{id}", GetReportJob)
+		api.GET("/reports/templates", GetReportTemplates)
+		api.GET("/reports/history", GetReportHistory)
+		api.GET("/reports/download/{id}", DownloadReportArtifact)
+		scheduled := api.Group("/reports/scheduled")
+		scheduled.GET("/", GetScheduledReports)
+		scheduled.POST("/", CreateScheduledReport)
+		scheduled.GET("/{id}", GetScheduledReport)
+		scheduled.PATCH("/{id}", UpdateScheduledReport)
+		scheduled.DELETE("/{id}", DeleteScheduledReport)
+		scheduled.GET("/{id}/runs", GetScheduledReportRuns)
 		api.GET("/scheduled", GetScheduledReports)
 		api.POST("/scheduled", CreateScheduledReport)
-		api.GET("/templates", GetReportTemplates)
+		api.GET("/templates", RedirectReportTemplates)
 		api.POST("/preview", PreviewReport)
 
 		// Team invitations pending (protected)
 		api.GET("/pending", GetPendingInvitations)
 
+		if _, err := currentJWTKeys(); err != nil {
+			app.Logger.Fatalf("jwt keys: %v", err)
+		}
+		if err := checkTokenConfig(); err != nil {
+			app.Logger.Warnf("token lifetimes: %v; using defaults", err)
+		}
+		currentTokenCache()
+		if err := configureHasher(); err != nil {
+			app.Logger.Warnf("password hashing: %v; using default cost", err)
+		}
+
+		configureAppleSignIn()
+		if err := configureMailer(); err != nil {
+			app.Logger.Fatalf("mailer: %v", err)
+		}
+		if err := configurePush(); err != nil {
+			app.Logger.Fatalf("push: %v", err)
+		}
+
+		// Background jobs
+		startTrackJobs(app)
+		startGeocoder(app)
+		startWebhookWorker(app)
+		startScheduledReportRunner(app)
+		startReportJobWorkers(app)
+		startReportCleanup(app)
+
 		// (Optional) DEV helper: catch-all OPTIONS, if you still see preflight issues
 		// app.Options("/{ignored:.+}", func(c buffalo.Context) error {
 		// 	return c.Render(204, r.JSON(nil))
@@ -141,3 +361,18 @@ func forceSSL() buffalo.MiddlewareFunc {
 		SSLProxyHeaders: map[string]string{"X-Forwarded-Proto": "https"},
 	})
 }
+
+// jsonContentType treats request bodies as JSON, except multipart uploads
+// (entry photos, team avatars) which need their original Content-Type and boundary.
+func jsonContentType() buffalo.MiddlewareFunc {
+	json := contenttype.Set("application/json")
+	return func(next buffalo.Handler) buffalo.Handler {
+		jsonNext := json(next)
+		return func(c buffalo.Context) error {
+			if strings.HasPrefix(c.Request().Header.Get("Content-Type"), "multipart/form-data") {
+				return next(c)
+			}
+			return jsonNext(c)
+		}
+	}
+}
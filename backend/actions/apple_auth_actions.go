@@ -0,0 +1,184 @@
+/**
+ * Apple Auth Actions - Sign in with Apple
+ *
+ * The iOS app obtains an identity token from Apple through the Capacitor
+ * plugin and posts it to POST /api/auth/apple. The token is verified
+ * against Apple's keys (see package appleid), then the user is found or
+ * created and our tokens are issued as for a password login.
+ *
+ * Apple sends the email address only on the first authorization, and it
+ * may be a private relay address (…@privaterelay.appleid.com) that never
+ * matches an existing account. The token's subject is therefore stored
+ * in the identities table and used to recognize later sign-ins.
+ *
+ * Configuration:
+ * - APPLE_CLIENT_IDS: comma-separated accepted audiences (bundle ID,
+ *   service ID); Apple sign-in is disabled when empty
+ * - APPLE_KEYS_URL: Apple's JWKS endpoint (default appleid.DefaultKeysURL)
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-21
+ */
+package actions
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"backend/appleid"
+	"backend/models"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/envy"
+	"github.com/gobuffalo/nulls"
+	"github.com/gofrs/uuid"
+)
+
+// appleVerifier is nil while Apple sign-in is not configured.
+var appleVerifier *appleid.Verifier
+
+/**
+ * configureAppleSignIn creates the identity token verifier from the
+ * environment
+ */
+func configureAppleSignIn() {
+	var audiences []string
+	for _, id := range strings.Split(envy.Get("APPLE_CLIENT_IDS", ""), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			audiences = append(audiences, id)
+		}
+	}
+	if len(audiences) == 0 {
+		return
+	}
+	appleVerifier = appleid.NewVerifier(envy.Get("APPLE_KEYS_URL", appleid.DefaultKeysURL), audiences)
+}
+
+/**
+ * AppleSignIn signs a user in with an Apple identity token
+ *
+ * POST /api/auth/apple
+ *
+ * Payload:
+ * - identity_token: JWT from Sign in with Apple
+ *
+ * The user is resolved in this order:
+ * 1. An identity with the token's subject
+ * 2. An existing user with the token's verified email, which is linked
+ * 3. A new user with the token's email (which may be a relay address)
+ *
+ * Users created here have no password; they sign in with Apple only.
+ *
+ * Responses:
+ * - 200 (existing user) or 201 (new user) with user and tokens as Login
+ * - 401 for an invalid or expired token
+ * - 422 when the token has no email and no identity is stored; the user
+ *   must remove the app under "Sign in with Apple" in their Apple ID
+ *   settings so Apple sends the email again
+ * - 503 when Apple sign-in is not configured
+ *
+ * @param c - Buffalo context with the identity token
+ * @return JSON user data with tokens or error response
+ */
+func AppleSignIn(c buffalo.Context) error {
+	if appleVerifier == nil {
+		return c.Render(http.StatusServiceUnavailable, r.JSON(map[string]string{"error": "apple sign-in not configured"}))
+	}
+	var p struct {
+		IdentityToken string `json:"identity_token"`
+	}
+	if err := c.Bind(&p); err != nil || p.IdentityToken == "" {
+		return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "identity_token required"}))
+	}
+
+	claims, err := appleVerifier.Verify(c.Request().Context(), p.IdentityToken, time.Now())
+	if err != nil {
+		c.Logger().Warnf("apple sign-in: %v", err)
+		recordLoginFailure(c, models.LoginMethodApple, models.LoginInvalidToken, uuid.Nil, "")
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "invalid identity token"}))
+	}
+	email := strings.TrimSpace(strings.ToLower(claims.Email))
+
+	tx := mustTx(c)
+	var (
+		u       models.User
+		created bool
+	)
+	var ident models.Identity
+	err = tx.Where("provider = ? AND subject = ?", models.IdentityProviderApple, claims.Subject).First(&ident)
+	switch {
+	case err == nil:
+		if err := tx.Find(&u, ident.UserID); err != nil {
+			return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+		}
+		// Keep the latest address, e.g. after the user changed relay settings
+		if email != "" && ident.Email.String != email {
+			ident.Email = nulls.NewString(email)
+			ident.UpdatedAt = time.Now()
+			if err := tx.UpdateColumns(&ident, "email", "updated_at"); err != nil {
+				return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+			}
+		}
+
+	case email == "":
+		return c.Render(http.StatusUnprocessableEntity, r.JSON(map[string]string{
+			"error": "email missing; remove the app from your Apple ID's Sign in with Apple settings and try again",
+		}))
+
+	default:
+		found := bool(claims.EmailVerified) && tx.Where("email = ?", email).First(&u) == nil
+		if !found {
+			if exists, err := tx.Where("email = ?", email).Exists(&models.User{}); err != nil || exists {
+				// An unverified address must not take over an existing account
+				return c.Render(http.StatusConflict, r.JSON(map[string]string{"error": "email already in use"}))
+			}
+			uid, err := uuid.NewV4()
+			if err != nil {
+				return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot create user"}))
+			}
+			u = models.User{ID: uid, Email: email}
+			if err := tx.Create(&u); err != nil {
+				return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot create user"}))
+			}
+			// Only an address Apple verified may claim invitations sent to it
+			if bool(claims.EmailVerified) {
+				if err := surfaceEmailInvitations(tx, u, time.Now()); err != nil {
+					return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot create user"}))
+				}
+			}
+			created = true
+		}
+
+		ident = models.Identity{
+			UserID:   u.ID,
+			Provider: models.IdentityProviderApple,
+			Subject:  claims.Subject,
+			Email:    nulls.NewString(email),
+		}
+		if err := tx.Create(&ident); err != nil {
+			return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot link identity"}))
+		}
+	}
+
+	if ok, err := admitSignIn(tx, &u); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	} else if !ok {
+		return refuseDeactivatedSignIn(c, models.LoginMethodApple, u)
+	}
+	pair, err := newLoginTokens(c, tx, u.ID)
+	if err != nil {
+		c.Logger().Errorf("apple sign-in: issue tokens: %v", err)
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot persist token"}))
+	}
+	if err := recordLoginSuccess(c, tx, &u, models.LoginMethodApple); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	return c.Render(status, r.JSON(authResponse(u, pair)))
+}
@@ -4,12 +4,13 @@
  * This package handles all user authentication related API endpoints including:
  * - User registration with email/password validation
  * - User login with credential verification
- * - JWT token generation and management
+ * - JWT access token and refresh token generation
  * - User profile retrieval
  * - Secure logout with token revocation
  *
  * Security Features:
- * - Password hashing with bcrypt
+ * - Password hashing with bcrypt at BCRYPT_COST (10–16, default 10);
+ *   hashes below the configured cost are upgraded on login
  * - JWT token generation with expiration
  * - Token blacklisting on logout
  * - Input validation and sanitization
@@ -21,11 +22,16 @@
 package actions
 
 import (
+	"fmt"
 	"net/http"
+	"os"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"backend/models"
+	"backend/validators"
 
 	"github.com/gobuffalo/buffalo"
 	"github.com/gobuffalo/pop/v6"
@@ -33,6 +39,126 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+/**
+ * passwordHasher hashes new passwords; tests swap it for a failing one
+ */
+type passwordHasher interface {
+	Hash(password string) (string, error)
+	// NeedsRehash reports whether a stored hash is weaker than new ones.
+	NeedsRehash(hash string) bool
+}
+
+// bcryptHasher hashes with bcrypt at the given cost.
+type bcryptHasher struct{ cost int }
+
+func (h bcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	return string(hash), err
+}
+
+func (h bcryptHasher) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err == nil && cost < h.cost
+}
+
+var hasher passwordHasher = bcryptHasher{cost: bcrypt.DefaultCost}
+
+// bcrypt costs accepted from BCRYPT_COST; each step doubles login time.
+const (
+	minBcryptCost = bcrypt.DefaultCost
+	maxBcryptCost = 16
+)
+
+// bcryptCost reads BCRYPT_COST (10–16, default 10).
+func bcryptCost() (int, error) {
+	raw := os.Getenv("BCRYPT_COST")
+	if raw == "" {
+		return bcrypt.DefaultCost, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil {
+		return bcrypt.DefaultCost, fmt.Errorf("BCRYPT_COST: %q is not a whole number", raw)
+	}
+	if n < minBcryptCost || n > maxBcryptCost {
+		return bcrypt.DefaultCost, fmt.Errorf("BCRYPT_COST: %d is outside %d–%d", n, minBcryptCost, maxBcryptCost)
+	}
+	return n, nil
+}
+
+// configureHasher sets the cost of new password hashes; an invalid
+// BCRYPT_COST is reported and the default used.
+func configureHasher() error {
+	cost, err := bcryptCost()
+	hasher = bcryptHasher{cost: cost}
+	return err
+}
+
+// fallbackDummyHash is a bcrypt hash at the default cost, used when the
+// hasher cannot produce one.
+const fallbackDummyHash = "$2a$10$bKshsZNpEZsuvJ1eUl3TGOs4J/K4/spRe.dpyA/FKOdx4O78v9D9u"
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     []byte
+)
+
+/**
+ * dummyPasswordHash returns a hash at the configured cost that no
+ * password matches, for comparisons against unknown accounts
+ */
+func dummyPasswordHash() []byte {
+	dummyHashOnce.Do(func() {
+		dummyHash = []byte(fallbackDummyHash)
+		if h, ok := hasher.(bcryptHasher); ok {
+			if hash, err := h.Hash(uuid.Must(uuid.NewV4()).String()); err == nil {
+				dummyHash = []byte(hash)
+			}
+		}
+	})
+	return dummyHash
+}
+
+/**
+ * passwordMatches reports whether password matches hash. Accounts
+ * without a password (Apple or magic-link sign-in only) are compared
+ * against dummyPasswordHash, so rejecting them takes as long as a wrong
+ * password.
+ */
+func passwordMatches(hash, password string) bool {
+	if hash == "" {
+		_ = bcrypt.CompareHashAndPassword(dummyPasswordHash(), []byte(password))
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+/**
+ * upgradePasswordHash re-hashes a verified password when the stored hash
+ * uses a lower cost than configured. Failures are logged; the login
+ * goes on with the old hash.
+ *
+ * @param c - Buffalo context of the login
+ * @param tx - Request transaction
+ * @param u - User that signed in, updated in place
+ * @param password - Password just verified against u.PasswordHash
+ */
+func upgradePasswordHash(c buffalo.Context, tx *pop.Connection, u *models.User, password string) {
+	if !hasher.NeedsRehash(u.PasswordHash) {
+		return
+	}
+	hash, err := hasher.Hash(password)
+	if err != nil {
+		c.Logger().Errorf("login: rehash: %v", err)
+		return
+	}
+	// The old hash in the condition keeps a concurrent password change
+	if err := tx.RawQuery("UPDATE users SET password_hash = ? WHERE id = ? AND password_hash = ?", hash, u.ID, u.PasswordHash).Exec(); err != nil {
+		c.Logger().Errorf("login: rehash: %v", err)
+		return
+	}
+	u.PasswordHash = hash
+}
+
 /**
  * Register creates a new user account with email and password
  *
@@ -44,15 +170,18 @@ import (
  *
  * Payload:
  * - email: User's email address (will be normalized to lowercase)
- * - password: User's password (minimum 6 characters)
+ * - password: User's password (see validators.PasswordProblems)
  *
  * Validation:
- * - Email must be valid and not empty
- * - Password must be at least 6 characters
+ * - Email must be a valid address (validators.NormalizeEmail)
+ * - Password must be at least 8 characters and not a common password
  * - Email must be unique (not already registered)
+ * - Violations return 422 {"error": "validation failed", "errors":
+ *   {"email": [...], "password": [...]}}
  *
  * Response:
- * - Returns user object, JWT token, and expiration time
+ * - Returns user object, access token and refresh token with their
+ *   expiration times (see refresh_token_actions.go)
  * - Token is automatically stored in auth_tokens table
  *
  * @param c - Buffalo context with registration payload
@@ -68,47 +197,63 @@ func Register(c buffalo.Context) error {
 		return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "bad payload"}))
 	}
 
-	// Normalize and validate email
-	p.Email = strings.TrimSpace(strings.ToLower(p.Email))
-	if p.Email == "" || len(p.Password) < 6 {
-		return c.Render(http.StatusUnprocessableEntity, r.JSON(map[string]string{"error": "email or password invalid"}))
+	// Normalize and validate email and password, reporting every problem
+	errs := map[string][]string{}
+	email, err := validators.NormalizeEmail(p.Email)
+	if err != nil {
+		errs["email"] = []string{err.Error()}
+	}
+	if problems := validators.PasswordProblems(p.Password); len(problems) > 0 {
+		errs["password"] = problems
 	}
+	if len(errs) > 0 {
+		return renderValidationErrors(c, errs)
+	}
+	p.Email = email
 
 	tx := c.Value("tx").(*pop.Connection)
 
+	// Hash password with bcrypt; no user is created without a hash. Hashing
+	// before the duplicate check keeps both outcomes equally slow.
+	hash, err := hasher.Hash(p.Password)
+	if err != nil {
+		c.Logger().Errorf("register: hash password: %v", err)
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot create user"}))
+	}
+
 	// Check for existing user with same email
 	var exists models.User
 	if err := tx.Where("email = ?", p.Email).First(&exists); err == nil {
 		return c.Render(http.StatusConflict, r.JSON(map[string]string{"error": "email already in use"}))
 	}
 
-	// Hash password with bcrypt
-	hash, _ := bcrypt.GenerateFromPassword([]byte(p.Password), bcrypt.DefaultCost)
-
 	// Create new user
-	uid, _ := uuid.NewV4()
+	uid, err := uuid.NewV4()
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot create user"}))
+	}
 	u := models.User{
 		ID:           uid,
 		Email:        p.Email,
-		PasswordHash: string(hash),
+		PasswordHash: hash,
 	}
 
 	if err := tx.Create(&u); err != nil {
 		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot create user"}))
 	}
+	// Nothing proves the address belongs to the caller yet, so invitations
+	// sent to it are not surfaced: they are accepted with their emailed
+	// token, or surface once a magic link proves the mailbox
+	// (MagicLinkConsume)
 
-	// Generate JWT token for immediate login
-	token, jti, exp, _ := GenerateJWT(u.ID.String())
-	_ = tx.RawQuery(`
-		INSERT INTO auth_tokens (jti, user_id, expires_at, created_at)
-		VALUES (?, ?, ?, now())
-	`, jti, u.ID.String(), exp).Exec()
+	// Issue tokens for immediate login
+	pair, err := newLoginTokens(c, tx, u.ID)
+	if err != nil {
+		c.Logger().Errorf("register: issue tokens: %v", err)
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot persist token"}))
+	}
 
-	return c.Render(http.StatusCreated, r.JSON(map[string]any{
-		"user":       u,
-		"token":      token,
-		"expires_at": exp,
-	}))
+	return c.Render(http.StatusCreated, r.JSON(authResponse(u, pair)))
 }
 
 /**
@@ -128,13 +273,14 @@ func Register(c buffalo.Context) error {
  * - Normalizes email to lowercase
  * - Looks up user by email
  * - Verifies password using bcrypt
- * - Generates new JWT token
- * - Stores token in database
+ * - Generates new access and refresh tokens
+ * - Stores tokens in database
  *
  * Security:
  * - Uses bcrypt for password verification
  * - Returns generic "invalid credentials" for both wrong email and password
  * - Generates new token on each login (token rotation)
+ * - Records the attempt in login_events and sets last_login_at on success
  *
  * @param c - Buffalo context with login payload
  * @return JSON user data with JWT token or error response
@@ -154,31 +300,38 @@ func Login(c buffalo.Context) error {
 
 	tx := c.Value("tx").(*pop.Connection)
 
-	// Find user by email
+	// Find user by email; unknown addresses still pay for a bcrypt
+	// comparison so response times do not reveal which emails exist
 	var u models.User
 	if err := tx.Where("email = ?", p.Email).First(&u); err != nil {
+		_ = bcrypt.CompareHashAndPassword(dummyPasswordHash(), []byte(p.Password))
+		recordLoginFailure(c, models.LoginMethodPassword, models.LoginUnknownEmail, uuid.Nil, p.Email)
 		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "invalid credentials"}))
 	}
 
 	// Verify password using bcrypt
-	if bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(p.Password)) != nil {
+	if !passwordMatches(u.PasswordHash, p.Password) {
+		recordLoginFailure(c, models.LoginMethodPassword, models.LoginInvalidPassword, u.ID, p.Email)
 		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "invalid credentials"}))
 	}
+	if ok, err := admitSignIn(tx, &u); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	} else if !ok {
+		return refuseDeactivatedSignIn(c, models.LoginMethodPassword, u)
+	}
+	upgradePasswordHash(c, tx, &u, p.Password)
 
-	// Generate new JWT token for this session
-	token, jti, exp, _ := GenerateJWT(u.ID.String())
-	if err := tx.RawQuery(`
-	INSERT INTO auth_tokens (jti, user_id, expires_at, created_at, updated_at)
-	VALUES (?, ?, ?, now(), now())
-	`, jti, u.ID, exp).Exec(); err != nil {
+	// Issue a new token pair for this session
+	pair, err := newLoginTokens(c, tx, u.ID)
+	if err != nil {
+		c.Logger().Errorf("login: issue tokens: %v", err)
 		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot persist token"}))
 	}
+	if err := recordLoginSuccess(c, tx, &u, models.LoginMethodPassword); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
 
-	return c.Render(http.StatusOK, r.JSON(map[string]any{
-		"user":       u,
-		"token":      token,
-		"expires_at": exp,
-	}))
+	return c.Render(http.StatusOK, r.JSON(authResponse(u, pair)))
 }
 
 /**
@@ -226,6 +379,8 @@ func Me(c buffalo.Context) error {
  * - Parses and validates the token
  * - Marks token as revoked in database
  * - Uses UPSERT to handle existing token records
+ * - Revokes the session's refresh token family, taken from the token or
+ *   from an optional `refresh_token` in the body
  *
  * Security:
  * - Token revocation prevents reuse even if stolen
@@ -272,5 +427,31 @@ func Logout(c buffalo.Context) error {
 		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "logout failed"}))
 	}
 
+	// Revoke the refresh token family so the session cannot be renewed
+	family, err := uuid.FromString(claims.Family)
+	if err != nil {
+		var p struct {
+			RefreshToken string `json:"refresh_token"`
+		}
+		_ = c.Bind(&p)
+		var rt models.RefreshToken
+		if p.RefreshToken == "" || tx.Where("token_hash = ? AND user_id = ?", models.HashRefreshToken(p.RefreshToken), claims.UserID).First(&rt) != nil {
+			family = uuid.Nil
+		} else {
+			family = rt.FamilyID
+		}
+	}
+	if family != uuid.Nil {
+		if err := revokeTokenFamily(tx, family, time.Now()); err != nil {
+			return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "logout failed"}))
+		}
+	}
+
+	forgetTokens(c, func(tc *tokenCache) {
+		tc.forgetJTI(claims.ID)
+		if family != uuid.Nil {
+			tc.forgetFamily(family.String())
+		}
+	})
 	return c.Render(http.StatusOK, r.JSON(map[string]string{"status": "logged out"}))
 }
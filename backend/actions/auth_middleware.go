@@ -1,11 +1,15 @@
 package actions
 
 import (
+	"database/sql"
+	"errors"
 	"net/http"
 	"strings"
+	"time"
 
 	"backend/models"
 	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/nulls"
 	"github.com/gobuffalo/pop/v6"
 	"github.com/gofrs/uuid"
 )
@@ -19,31 +23,147 @@ func AuthRequired(next buffalo.Handler) buffalo.Handler {
 		if authz == "" || !strings.HasPrefix(authz, "Bearer ") {
 			return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "missing bearer token"}))
 		}
-		claims, err := ParseJWT(strings.TrimPrefix(authz, "Bearer "))
-		if err != nil {
-			return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "invalid token"}))
-		}
 
 		tx := c.Value("tx").(*pop.Connection)
+		raw := strings.TrimPrefix(authz, "Bearer ")
 
-		// إذا التوكن مُلغى
-		var at models.AuthToken
-		if err := tx.Where("jti = ? AND revoked_at IS NOT NULL", claims.ID).First(&at); err == nil {
-			return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "token revoked"}))
+		// مفاتيح API (ttk_...) للسكربتات والتكاملات
+		if isAPIKey(raw) {
+			u, key, msg := userFromAPIKey(tx, raw, time.Now())
+			if msg != "" {
+				return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": msg}))
+			}
+			if u.DisabledAt.Valid {
+				return c.Render(http.StatusForbidden, r.JSON(map[string]string{"error": "account disabled"}))
+			}
+			if u.DeactivatedAt.Valid {
+				return renderDeactivated(c)
+			}
+			c.Set(currentUserKey, u)
+			c.Set(currentAPIKeyKey, key)
+			return next(c)
 		}
 
-		// تحميل المستخدم
-		var u models.User
-		uid, err := uuid.FromString(claims.UserID)
-		if err != nil || tx.Find(&u, uid) != nil {
-			return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "user not found"}))
+		auth, msg := authenticateToken(tx, raw)
+		if msg != "" {
+			return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": msg}))
+		}
+		// حساب معطّل من قبل المشرف (انظر admin_actions.go)
+		if auth.user.DisabledAt.Valid {
+			return c.Render(http.StatusForbidden, r.JSON(map[string]string{"error": "account disabled"}))
 		}
+		// حساب معطّل نهائياً (انظر deactivation_actions.go)
+		if auth.user.DeactivatedAt.Valid {
+			return renderDeactivated(c)
+		}
+
+		// تجديد التوكن القريب من الانتهاء (انظر sliding_session.go)،
+		// قبل تحديث last_used_at حتى لا تنتظر معاملة التجديد قفل هذا الطلب
+		now := time.Now()
+		slideSession(c, auth, now)
+		auth.touch(tx, now)
 
-		c.Set(currentUserKey, u)
+		c.Set(currentUserKey, auth.user)
 		return next(c)
 	}
 }
 
+// نتيجة التحقق من توكن صالح
+type tokenAuth struct {
+	user      models.User
+	claims    *JWTClaims
+	expiresAt time.Time  // auth_tokens.expires_at
+	lastUsed  nulls.Time // auth_tokens.last_used_at
+}
+
+// يتحقق من التوكن ويرجع المستخدم، أو رسالة الخطأ لاستجابة 401
+func userFromToken(tx *pop.Connection, raw string) (models.User, string) {
+	auth, msg := authenticateToken(tx, raw)
+	if msg == "" {
+		auth.touch(tx, time.Now())
+	}
+	return auth.user, msg
+}
+
+// يسجّل استخدام التوكن (انظر touchAuthToken)
+func (a tokenAuth) touch(tx *pop.Connection, now time.Time) {
+	if touchAuthToken(tx, a.claims.ID, a.lastUsed, now) {
+		currentTokenCache().touched(a.claims.ID, now)
+	}
+}
+
+// التوقيع وحده لا يكفي: يجب أن يكون للتوكن سجل في auth_tokens غير مُلغى
+// وغير منتهي، ويُحمَّل السجل مع المستخدم في استعلام واحد
+func authenticateToken(tx *pop.Connection, raw string) (tokenAuth, string) {
+	claims, err := ParseJWT(raw)
+	if err != nil {
+		return tokenAuth{}, "invalid token"
+	}
+	uid, err := uuid.FromString(claims.UserID)
+	if err != nil {
+		return tokenAuth{}, "invalid token"
+	}
+
+	// كاش التوكنات الصالحة (انظر token_cache.go)
+	now := time.Now()
+	cache := currentTokenCache()
+	if e, ok := cache.get(claims.ID, now); ok && e.user.ID == uid {
+		return tokenAuth{user: e.user, claims: claims, expiresAt: e.expiresAt, lastUsed: e.lastUsed}, ""
+	}
+
+	var row struct {
+		models.User
+		TokenRevokedAt  nulls.Time `db:"token_revoked_at"`
+		TokenExpiresAt  time.Time  `db:"token_expires_at"`
+		TokenLastUsedAt nulls.Time `db:"token_last_used_at"`
+	}
+	err = tx.RawQuery(`
+		SELECT u.*, a.revoked_at AS token_revoked_at, a.expires_at AS token_expires_at,
+			a.last_used_at AS token_last_used_at
+		FROM auth_tokens a
+		JOIN users u ON u.id = a.user_id
+		WHERE a.jti = ? AND a.user_id = ?
+	`, claims.ID, uid).First(&row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return tokenAuth{}, "unknown token"
+	}
+	if err != nil {
+		return tokenAuth{}, "invalid token"
+	}
+
+	// إذا التوكن مُلغى أو انتهى سجله
+	if row.TokenRevokedAt.Valid {
+		return tokenAuth{}, "token revoked"
+	}
+	if !now.Before(row.TokenExpiresAt) {
+		return tokenAuth{}, "token expired"
+	}
+
+	cache.put(claims.ID, cachedToken{
+		user:      row.User,
+		family:    claims.Family,
+		expiresAt: row.TokenExpiresAt,
+		lastUsed:  row.TokenLastUsedAt,
+		loadedAt:  now,
+	})
+	return tokenAuth{user: row.User, claims: claims, expiresAt: row.TokenExpiresAt, lastUsed: row.TokenLastUsedAt}, ""
+}
+
+// يحدّث last_used_at مرة كل AuthTokenTouchInterval على الأكثر لتقليل الكتابة
+// ويرجع true إذا تم التحديث
+func touchAuthToken(tx *pop.Connection, jti string, lastUsed nulls.Time, now time.Time) bool {
+	if lastUsed.Valid && now.Sub(lastUsed.Time) < models.AuthTokenTouchInterval {
+		return false
+	}
+	return tx.RawQuery("UPDATE auth_tokens SET last_used_at = ? WHERE jti = ?", now.UTC(), jti).Exec() == nil
+}
+
+// يرجع claims التوكن الحالي من ترويسة Authorization
+func currentTokenClaims(c buffalo.Context) (*JWTClaims, bool) {
+	claims, err := ParseJWT(strings.TrimPrefix(c.Request().Header.Get("Authorization"), "Bearer "))
+	return claims, err == nil
+}
+
 // Helper يرجع المستخدم الحالي من الـ Context
 func CurrentUser(c buffalo.Context) (models.User, bool) {
 	if v := c.Value(currentUserKey); v != nil {
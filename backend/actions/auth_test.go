@@ -0,0 +1,952 @@
+package actions
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"backend/appleid"
+	"backend/mailer"
+	"backend/models"
+	"backend/validators"
+
+	"github.com/gobuffalo/envy"
+	"github.com/gofrs/uuid"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func Test_ParseTTL(t *testing.T) {
+	cases := []struct {
+		raw     string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", defaultAccessTTL, false},
+		{"15", 15 * time.Minute, false},
+		{"60", time.Hour, false},
+		{"5", defaultAccessTTL, true},
+		{"90", defaultAccessTTL, true},
+		{"1h", defaultAccessTTL, true},
+	}
+	for _, tc := range cases {
+		got, err := parseTTL(tc.raw, time.Minute, defaultAccessTTL, 15*time.Minute, time.Hour)
+		if got != tc.want || (err != nil) != tc.wantErr {
+			t.Errorf("%q: got %v, %v", tc.raw, got, err)
+		}
+	}
+}
+
+func Test_SlidingWindow_Env(t *testing.T) {
+	if d, err := slidingWindow(); err != nil || d != 0 {
+		t.Errorf("unset: got %v, %v", d, err)
+	}
+	t.Setenv("SESSION_SLIDING_WINDOW_MINUTES", "10")
+	if d, err := slidingWindow(); err != nil || d != 10*time.Minute {
+		t.Errorf("got %v, %v", d, err)
+	}
+	t.Setenv("SESSION_SLIDING_WINDOW_MINUTES", "30")
+	if d, err := slidingWindow(); err == nil || d != 0 {
+		t.Errorf("got %v, %v", d, err)
+	}
+}
+
+func Test_RefreshTokenTTL_Env(t *testing.T) {
+	t.Setenv("REFRESH_TOKEN_TTL_DAYS", "7")
+	if d, err := refreshTokenTTL(); err != nil || d != 7*24*time.Hour {
+		t.Errorf("got %v, %v", d, err)
+	}
+	t.Setenv("REFRESH_TOKEN_TTL_DAYS", "0")
+	if d, err := refreshTokenTTL(); err == nil || d != defaultRefreshTTL {
+		t.Errorf("got %v, %v", d, err)
+	}
+	if checkTokenConfig() == nil {
+		t.Error("invalid config not reported")
+	}
+}
+
+func (as *ActionSuite) Test_RefreshToken_Rotation() {
+	res := as.JSON("/api/auth/register").Post(map[string]string{
+		"email":    "refresh@example.com",
+		"password": "secret123",
+	})
+	as.Equal(http.StatusCreated, res.Code)
+	var first tokenPair
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &first))
+	as.NotEmpty(first.RefreshToken)
+
+	res = as.JSON("/api/auth/refresh").Post(map[string]string{"refresh_token": first.RefreshToken})
+	as.Equal(http.StatusOK, res.Code)
+	var second tokenPair
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &second))
+	as.NotEqual(first.RefreshToken, second.RefreshToken)
+	as.Equal(http.StatusOK, as.authJSON(second.Token, "/api/me").Get().Code)
+
+	// Replaying the rotated token revokes the whole family
+	res = as.JSON("/api/auth/refresh").Post(map[string]string{"refresh_token": first.RefreshToken})
+	as.Equal(http.StatusUnauthorized, res.Code)
+	res = as.JSON("/api/auth/refresh").Post(map[string]string{"refresh_token": second.RefreshToken})
+	as.Equal(http.StatusUnauthorized, res.Code)
+	as.Equal(http.StatusUnauthorized, as.authJSON(second.Token, "/api/me").Get().Code)
+}
+
+func (as *ActionSuite) Test_Logout_RevokesRefreshToken() {
+	res := as.JSON("/api/auth/register").Post(map[string]string{
+		"email":    "logout-refresh@example.com",
+		"password": "secret123",
+	})
+	as.Equal(http.StatusCreated, res.Code)
+	var pair tokenPair
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &pair))
+
+	as.Equal(http.StatusOK, as.authJSON(pair.Token, "/api/logout").Post(nil).Code)
+	as.Equal(http.StatusUnauthorized, as.authJSON(pair.Token, "/api/me").Get().Code)
+	res = as.JSON("/api/auth/refresh").Post(map[string]string{"refresh_token": pair.RefreshToken})
+	as.Equal(http.StatusUnauthorized, res.Code)
+}
+
+func testRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func testClaims() JWTClaims {
+	now := time.Now()
+	return JWTClaims{
+		UserID: "u1",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        "j1",
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
+}
+
+func Test_JWT_HS256(t *testing.T) {
+	ks := &jwtKeySet{verify: map[string]*rsa.PublicKey{}}
+	token, err := signJWT(ks, testClaims())
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := parseJWTWith(ks, token, time.Now())
+	if err != nil || claims.UserID != "u1" {
+		t.Fatalf("got %v, %v", claims, err)
+	}
+}
+
+func Test_JWT_RS256(t *testing.T) {
+	key := testRSAKey(t)
+	pemKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	ks, err := loadJWTKeys(func(name string) string {
+		if name == "JWT_PRIVATE_KEY" {
+			return string(pemKey)
+		}
+		return ""
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := signJWT(ks, testClaims())
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, &JWTClaims{})
+	if err != nil || parsed.Header["kid"] != ks.signingKID || parsed.Method != jwt.SigningMethodRS256 {
+		t.Fatalf("unexpected header %v (%v)", parsed.Header, err)
+	}
+	if claims, err := parseJWTWith(ks, token, time.Now()); err != nil || claims.ID != "j1" {
+		t.Fatalf("got %v, %v", claims, err)
+	}
+
+	// HS256 tokens only within the migration window
+	hsToken, _ := signJWT(&jwtKeySet{}, testClaims())
+	if _, err := parseJWTWith(ks, hsToken, time.Now()); err == nil {
+		t.Error("HS256 token accepted without migration window")
+	}
+	ks.hsUntil = time.Now().Add(time.Hour)
+	if _, err := parseJWTWith(ks, hsToken, time.Now()); err != nil {
+		t.Errorf("HS256 token rejected within migration window: %v", err)
+	}
+}
+
+func Test_JWT_RS256_UnknownKid(t *testing.T) {
+	known, unknown := testRSAKey(t), testRSAKey(t)
+	ks := &jwtKeySet{signing: known, signingKID: jwkThumbprint(&known.PublicKey), verify: map[string]*rsa.PublicKey{}}
+	ks.verify[ks.signingKID] = &known.PublicKey
+
+	rogue := &jwtKeySet{signing: unknown, signingKID: jwkThumbprint(&unknown.PublicKey)}
+	token, err := signJWT(rogue, testClaims())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parseJWTWith(ks, token, time.Now()); err == nil {
+		t.Error("token with unknown kid accepted")
+	}
+
+	// A known kid does not help a token signed by another key
+	rogue.signingKID = ks.signingKID
+	token, _ = signJWT(rogue, testClaims())
+	if _, err := parseJWTWith(ks, token, time.Now()); err == nil {
+		t.Error("token with forged kid accepted")
+	}
+
+	// Rotated-out keys keep verifying when listed as verify keys
+	pub, _ := x509.MarshalPKIXPublicKey(&unknown.PublicKey)
+	rotated, err := loadJWTKeys(func(name string) string {
+		if name == "JWT_VERIFY_KEYS" {
+			return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pub}))
+		}
+		return ""
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rogue.signingKID = jwkThumbprint(&unknown.PublicKey)
+	token, _ = signJWT(rogue, testClaims())
+	if _, err := parseJWTWith(rotated, token, time.Now()); err != nil {
+		t.Errorf("token signed by a verify key rejected: %v", err)
+	}
+}
+
+func Test_JWT_IssuerAudience(t *testing.T) {
+	prod := &jwtKeySet{issuer: "https://api.example.com", audience: "timetrac"}
+	token, err := signJWT(prod, testClaims())
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := parseJWTWith(prod, token, time.Now())
+	if err != nil || claims.Issuer != prod.issuer || len(claims.Audience) != 1 || claims.Audience[0] != prod.audience {
+		t.Fatalf("got %v, %v", claims, err)
+	}
+
+	// Tokens of another deployment sharing the secret
+	staging := &jwtKeySet{issuer: "https://staging.example.com", audience: "timetrac"}
+	stagingToken, _ := signJWT(staging, testClaims())
+	if _, err := parseJWTWith(prod, stagingToken, time.Now()); !errors.Is(err, jwt.ErrTokenInvalidIssuer) {
+		t.Errorf("foreign issuer: got %v", err)
+	}
+	otherAud := &jwtKeySet{issuer: prod.issuer, audience: "admin"}
+	otherAudToken, _ := signJWT(otherAud, testClaims())
+	if _, err := parseJWTWith(prod, otherAudToken, time.Now()); !errors.Is(err, jwt.ErrTokenInvalidAudience) {
+		t.Errorf("foreign audience: got %v", err)
+	}
+	legacy, _ := signJWT(&jwtKeySet{}, testClaims())
+	if _, err := parseJWTWith(prod, legacy, time.Now()); err == nil {
+		t.Error("token without iss/aud accepted")
+	}
+}
+
+func Test_JWT_IssuerAudience_Lenient(t *testing.T) {
+	ks, err := loadJWTKeys(func(name string) string {
+		switch name {
+		case "JWT_ISSUER":
+			return "https://api.example.com"
+		case "JWT_AUDIENCE":
+			return "timetrac"
+		case "JWT_ISS_AUD_LENIENT_UNTIL":
+			return time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+		}
+		return ""
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var logged []string
+	ks.logf = func(format string, args ...any) { logged = append(logged, format) }
+
+	legacy, _ := signJWT(&jwtKeySet{}, testClaims())
+	if _, err := parseJWTWith(ks, legacy, time.Now()); err != nil {
+		t.Errorf("token without iss/aud rejected in migration window: %v", err)
+	}
+	if len(logged) != 1 {
+		t.Errorf("logged %d times", len(logged))
+	}
+
+	// The window does not excuse other failures, nor outlast its end
+	tampered := legacy[:len(legacy)-2] + "xx"
+	if _, err := parseJWTWith(ks, tampered, time.Now()); err == nil {
+		t.Error("tampered token accepted")
+	}
+	if _, err := parseJWTWith(ks, legacy, time.Now().Add(2*time.Hour)); err == nil {
+		t.Error("token without iss/aud accepted after migration window")
+	}
+	if _, err := loadJWTKeys(func(name string) string {
+		if name == "JWT_ISS_AUD_LENIENT_UNTIL" {
+			return "tomorrow"
+		}
+		return ""
+	}); err == nil {
+		t.Error("invalid JWT_ISS_AUD_LENIENT_UNTIL accepted")
+	}
+}
+
+func Test_JWKThumbprint(t *testing.T) {
+	// Example key from RFC 7638, section 3.1
+	n, _ := base64.RawURLEncoding.DecodeString("0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw")
+	pub := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: 65537}
+	if got := jwkThumbprint(pub); got != "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs" {
+		t.Errorf("got %s", got)
+	}
+}
+
+func Test_GenerateJWT_UniqueJTI(t *testing.T) {
+	const workers, perWorker = 16, 200
+	jtis := make(chan string, workers*perWorker)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				_, jti, _, err := GenerateJWT("u1", "")
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				jtis <- jti
+			}
+		}()
+	}
+	wg.Wait()
+	close(jtis)
+
+	seen := map[string]bool{}
+	for jti := range jtis {
+		if _, err := uuid.FromString(jti); err != nil {
+			t.Fatalf("jti %q is not a UUID", jti)
+		}
+		if seen[jti] {
+			t.Fatalf("duplicate jti %s", jti)
+		}
+		seen[jti] = true
+	}
+	if len(seen) != workers*perWorker {
+		t.Errorf("got %d tokens", len(seen))
+	}
+}
+
+type failingHasher struct{}
+
+func (failingHasher) Hash(string) (string, error) { return "", errors.New("hasher down") }
+func (failingHasher) NeedsRehash(string) bool     { return true }
+
+type failingSigner struct{}
+
+func (failingSigner) Sign(JWTClaims) (string, error) { return "", errors.New("signer down") }
+
+func Test_GenerateJWT_SignerError(t *testing.T) {
+	defer func(s tokenSigner) { signer = s }(signer)
+	signer = failingSigner{}
+	if token, _, _, err := GenerateJWT("u1", ""); err == nil || token != "" {
+		t.Errorf("got %q, %v", token, err)
+	}
+}
+
+func (as *ActionSuite) Test_Register_HasherError() {
+	defer func(h passwordHasher) { hasher = h }(hasher)
+	hasher = failingHasher{}
+
+	res := as.JSON("/api/auth/register").Post(map[string]string{"email": "nohash@example.com", "password": "secret123"})
+	as.Equal(http.StatusInternalServerError, res.Code)
+	exists, err := as.DB.Where("email = ?", "nohash@example.com").Exists(&models.User{})
+	as.NoError(err)
+	as.False(exists)
+}
+
+func (as *ActionSuite) Test_Register_SignerError() {
+	defer func(s tokenSigner) { signer = s }(signer)
+	signer = failingSigner{}
+
+	res := as.JSON("/api/auth/register").Post(map[string]string{"email": "nosign@example.com", "password": "secret123"})
+	as.Equal(http.StatusInternalServerError, res.Code)
+	as.NotContains(res.Body.String(), `"token"`)
+	exists, err := as.DB.Where("email = ?", "nosign@example.com").Exists(&models.User{})
+	as.NoError(err)
+	as.False(exists, "user row must be rolled back")
+}
+
+func (as *ActionSuite) Test_Login_SignerError() {
+	as.registerToken("login-nosign@example.com")
+	defer func(s tokenSigner) { signer = s }(signer)
+	signer = failingSigner{}
+
+	res := as.JSON("/api/auth/login").Post(map[string]string{"email": "login-nosign@example.com", "password": "secret123"})
+	as.Equal(http.StatusInternalServerError, res.Code)
+}
+
+func (as *ActionSuite) Test_AppleSignIn() {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	as.NoError(err)
+	keys := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"keys": []jwk{rsaJWK(&key.PublicKey, "apple-1")}})
+	}))
+	defer keys.Close()
+	defer func(v *appleid.Verifier) { appleVerifier = v }(appleVerifier)
+	appleVerifier = appleid.NewVerifier(keys.URL, []string{"com.example.timetrac"})
+
+	identityToken := func(sub, email string) string {
+		claims := jwt.MapClaims{
+			"iss": appleid.Issuer,
+			"aud": "com.example.timetrac",
+			"sub": sub,
+			"exp": time.Now().Add(5 * time.Minute).Unix(),
+		}
+		if email != "" {
+			claims["email"] = email
+			claims["email_verified"] = "true"
+			claims["is_private_email"] = "true"
+		}
+		t := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		t.Header["kid"] = "apple-1"
+		s, err := t.SignedString(key)
+		as.NoError(err)
+		return s
+	}
+
+	// First authorization carries the (relay) email and creates the user
+	res := as.JSON("/api/auth/apple").Post(map[string]string{"identity_token": identityToken("001.abc", "x7@privaterelay.appleid.com")})
+	as.Equal(http.StatusCreated, res.Code)
+	var first struct {
+		User  models.User `json:"user"`
+		Token string      `json:"token"`
+	}
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &first))
+	as.NotEmpty(first.Token)
+
+	// Later sign-ins have no email and are matched by subject
+	res = as.JSON("/api/auth/apple").Post(map[string]string{"identity_token": identityToken("001.abc", "")})
+	as.Equal(http.StatusOK, res.Code)
+	var second struct {
+		User models.User `json:"user"`
+	}
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &second))
+	as.Equal(first.User.ID, second.User.ID)
+
+	res = as.JSON("/api/auth/apple").Post(map[string]string{"identity_token": identityToken("002.unknown", "")})
+	as.Equal(http.StatusUnprocessableEntity, res.Code)
+
+	res = as.JSON("/api/auth/apple").Post(map[string]string{"identity_token": "not-a-token"})
+	as.Equal(http.StatusUnauthorized, res.Code)
+}
+
+func Test_RateLimiter(t *testing.T) {
+	l := newRateLimiter(2, time.Minute)
+	now := time.Now()
+	if !l.allow("a", now) || !l.allow("a", now.Add(time.Second)) {
+		t.Fatal("events within the limit rejected")
+	}
+	if l.allow("a", now.Add(2*time.Second)) {
+		t.Error("third event within the window allowed")
+	}
+	if !l.allow("b", now.Add(2*time.Second)) {
+		t.Error("limit shared between keys")
+	}
+	if !l.allow("a", now.Add(time.Minute+time.Second)) {
+		t.Error("event after the window rejected")
+	}
+}
+
+func Test_ClientIP(t *testing.T) {
+	var trusted []netip.Prefix
+	envy.Temp(func() {
+		envy.Set("TRUSTED_PROXIES", "10.0.0.0/8, 192.0.2.1, bogus")
+		trusted = trustedProxies()
+	})
+	if len(trusted) != 2 {
+		t.Fatalf("got %v", trusted)
+	}
+	cases := []struct {
+		remote, xff, want string
+	}{
+		{"198.51.100.9:4000", "", "198.51.100.9"},
+		{"198.51.100.9:4000", "203.0.113.7", "198.51.100.9"}, // Untrusted peer
+		{"192.0.2.1:4000", "203.0.113.7", "203.0.113.7"},
+		{"192.0.2.1:4000", "1.2.3.4, 203.0.113.7, 10.0.0.5", "203.0.113.7"}, // Spoofed leftmost entry
+		{"192.0.2.1:4000", "", "192.0.2.1"},
+		{"192.0.2.1:4000", "not-an-ip", "192.0.2.1"},
+		{"", "203.0.113.7", ""},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = tc.remote
+		if tc.xff != "" {
+			req.Header.Set("X-Forwarded-For", tc.xff)
+		}
+		ip, ok := clientIP(req, trusted)
+		if got := ip.String(); (ok && got != tc.want) || (!ok && tc.want != "") {
+			t.Errorf("%s via %q: got %s, want %s", tc.remote, tc.xff, got, tc.want)
+		}
+	}
+}
+
+// captureSender records sent mail for tests.
+type captureSender chan mailer.Message
+
+func (s captureSender) Send(msg mailer.Message) error {
+	s <- msg
+	return nil
+}
+
+func (as *ActionSuite) Test_MagicLink() {
+	sent := make(captureSender, 4)
+	prev := mailSender
+	mailSender = sent
+	defer func() { mailSender = prev }()
+
+	as.registerToken("magic@example.com")
+	tokenFromMail := func() string {
+		select {
+		case msg := <-sent:
+			as.Equal("magic@example.com", msg.To)
+			i := strings.Index(msg.Text, "token=")
+			as.True(i >= 0)
+			return strings.Fields(msg.Text[i+len("token="):])[0]
+		case <-time.After(2 * time.Second):
+			as.Fail("no email sent")
+			return ""
+		}
+	}
+
+	// Unknown addresses get the same answer and no email
+	res := as.JSON("/api/auth/magic-link").Post(map[string]string{"email": "nobody@example.com"})
+	as.Equal(http.StatusAccepted, res.Code)
+	unknownBody := res.Body.String()
+
+	res = as.JSON("/api/auth/magic-link").Post(map[string]string{"email": "Magic@Example.com"})
+	as.Equal(http.StatusAccepted, res.Code)
+	as.Equal(unknownBody, res.Body.String())
+	first := tokenFromMail()
+
+	// A new link invalidates the first one
+	as.Equal(http.StatusAccepted, as.JSON("/api/auth/magic-link").Post(map[string]string{"email": "magic@example.com"}).Code)
+	second := tokenFromMail()
+	as.Equal(http.StatusUnauthorized, as.JSON("/api/auth/magic-link/consume").Post(map[string]string{"token": first}).Code)
+
+	res = as.JSON("/api/auth/magic-link/consume?token=%s", second).Get()
+	as.Equal(http.StatusOK, res.Code)
+	var pair tokenPair
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &pair))
+	as.Equal(http.StatusOK, as.authJSON(pair.Token, "/api/me").Get().Code)
+
+	// Single use
+	as.Equal(http.StatusUnauthorized, as.JSON("/api/auth/magic-link/consume").Post(map[string]string{"token": second}).Code)
+}
+
+func (as *ActionSuite) Test_APIKeys() {
+	token := as.registerToken("apikeys@example.com")
+
+	create := func(scopes ...string) string {
+		res := as.authJSON(token, "/api/me/api-keys").Post(map[string]any{"name": "cron", "scopes": scopes})
+		as.Equal(http.StatusCreated, res.Code)
+		var body struct {
+			ID  uuid.UUID `json:"id"`
+			Key string    `json:"key"`
+		}
+		as.NoError(json.Unmarshal(res.Body.Bytes(), &body))
+		as.True(strings.HasPrefix(body.Key, models.APIKeyPrefix))
+		return body.Key
+	}
+	readKey := create("read")
+	writeKey := create("write")
+
+	as.Equal(http.StatusOK, as.authJSON(readKey, "/api/tracks/").Get().Code)
+	as.Equal(http.StatusForbidden, as.authJSON(readKey, "/api/tracks/start").Post(map[string]string{"project": "Web"}).Code)
+	as.Equal(http.StatusCreated, as.authJSON(writeKey, "/api/tracks/start").Post(map[string]string{"project": "Web"}).Code)
+
+	// Issuing a Slack link code is a GET that writes
+	as.Equal(http.StatusForbidden, as.authJSON(readKey, "/api/integrations/slack/link").Get().Code)
+	as.Equal(http.StatusOK, as.authJSON(writeKey, "/api/integrations/slack/link").Get().Code)
+
+	// Nor touch the account, its sessions, devices or webhooks
+	as.Equal(http.StatusForbidden, as.authJSON(writeKey, "/api/me/password").Post(map[string]string{"current_password": "secret123", "new_password": "secret456"}).Code)
+	as.Equal(http.StatusForbidden, as.authJSON(readKey, "/api/me/sessions").Get().Code)
+	as.Equal(http.StatusForbidden, as.authJSON(writeKey, "/api/me/sessions").Delete().Code)
+	as.Equal(http.StatusForbidden, as.authJSON(writeKey, "/api/me/devices").Post(map[string]string{"token": "device", "platform": "ios"}).Code)
+	as.Equal(http.StatusForbidden, as.authJSON(readKey, "/api/webhooks/").Get().Code)
+	as.Equal(http.StatusForbidden, as.authJSON(writeKey, "/api/webhooks/").Post(map[string]any{"url": "https://example.com/hook", "events": []string{"track.started"}}).Code)
+	as.Equal(http.StatusForbidden, as.authJSON(writeKey, "/api/me/deactivate").Post(map[string]string{"password": "secret123"}).Code)
+	as.Equal(http.StatusOK, as.authJSON(token, "/api/me/sessions").Get().Code)
+
+	// Keys cannot mint keys, and the list never shows secrets
+	as.Equal(http.StatusForbidden, as.authJSON(writeKey, "/api/me/api-keys").Post(map[string]any{"name": "x"}).Code)
+	res := as.authJSON(token, "/api/me/api-keys").Get()
+	as.Equal(http.StatusOK, res.Code)
+	as.NotContains(res.Body.String(), readKey[len(models.APIKeyPrefix)+11:])
+
+	var keys []models.APIKey
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &keys))
+	as.Len(keys, 2)
+	for _, k := range keys {
+		as.Equal(http.StatusOK, as.authJSON(token, "/api/me/api-keys/%s", k.ID).Delete().Code)
+	}
+	as.Equal(http.StatusUnauthorized, as.authJSON(readKey, "/api/tracks/").Get().Code)
+	as.Equal(http.StatusUnauthorized, as.authJSON("ttk_0000000000_wrong", "/api/tracks/").Get().Code)
+}
+
+func (as *ActionSuite) Test_Register_ValidationErrors() {
+	res := as.JSON("/api/auth/register").Post(map[string]string{"email": "Ann <ann@example.com>", "password": "password1"})
+	as.Equal(http.StatusUnprocessableEntity, res.Code)
+	var body struct {
+		Errors map[string][]string `json:"errors"`
+	}
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &body))
+	as.Equal([]string{validators.ErrInvalidEmail.Error()}, body.Errors["email"])
+	as.Equal([]string{validators.PasswordTooCommon}, body.Errors["password"])
+
+	res = as.JSON("/api/auth/register").Post(map[string]string{"email": "ann@example.com", "password": "short"})
+	as.Equal(http.StatusUnprocessableEntity, res.Code)
+	as.Contains(res.Body.String(), validators.PasswordTooShort)
+}
+
+func (as *ActionSuite) Test_MeSecurityLogins() {
+	token := as.registerToken("history@example.com")
+
+	as.Equal(http.StatusUnauthorized, as.JSON("/api/auth/login").Post(map[string]string{"email": "history@example.com", "password": "wrong-password"}).Code)
+	as.Equal(http.StatusUnauthorized, as.JSON("/api/auth/login").Post(map[string]string{"email": "ghost@example.com", "password": "wrong-password"}).Code)
+	req := as.JSON("/api/auth/login")
+	req.Headers["X-Forwarded-For"] = "203.0.113.7"
+	res := req.Post(map[string]string{"email": "history@example.com", "password": "secret123"})
+	as.Equal(http.StatusOK, res.Code)
+	var login struct {
+		User models.User `json:"user"`
+	}
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &login))
+	as.True(login.User.LastLoginAt.Valid)
+
+	res = as.authJSON(token, "/api/me/security/logins").Get()
+	as.Equal(http.StatusOK, res.Code)
+	var events []models.LoginEvent
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &events))
+	as.Len(events, 2)
+	as.Equal(models.LoginSucceeded, events[0].Outcome)
+	as.False(events[0].IP.Valid, "X-Forwarded-For of an untrusted peer is ignored")
+	as.Equal(models.LoginInvalidPassword, events[1].Outcome)
+
+	// Unknown addresses are kept without a user for throttling
+	n, err := as.DB.Where("email = ? AND user_id IS NULL", "ghost@example.com").Count(&models.LoginEvent{})
+	as.NoError(err)
+	as.Equal(1, n)
+}
+
+func (as *ActionSuite) Test_AuthRequired_TokenRecord() {
+	token := as.registerToken("record@example.com")
+	as.Equal(http.StatusOK, as.authJSON(token, "/api/me").Get().Code)
+	claims, err := ParseJWT(token)
+	as.NoError(err)
+
+	// Validly signed but never persisted
+	forged, _, _, err := GenerateJWT(claims.UserID, claims.Family)
+	as.NoError(err)
+	res := as.authJSON(forged, "/api/me").Get()
+	as.Equal(http.StatusUnauthorized, res.Code)
+	as.Contains(res.Body.String(), "unknown token")
+
+	// The row expired before the JWT did; direct writes bypass the cache
+	currentTokenCache().forgetJTI(claims.ID)
+	as.NoError(as.DB.RawQuery("UPDATE auth_tokens SET expires_at = ? WHERE jti = ?", time.Now().Add(-time.Minute).UTC(), claims.ID).Exec())
+	res = as.authJSON(token, "/api/me").Get()
+	as.Equal(http.StatusUnauthorized, res.Code)
+	as.Contains(res.Body.String(), "token expired")
+
+	// Revoked
+	as.NoError(as.DB.RawQuery("UPDATE auth_tokens SET expires_at = ?, revoked_at = ? WHERE jti = ?", time.Now().Add(time.Hour).UTC(), time.Now().UTC(), claims.ID).Exec())
+	res = as.authJSON(token, "/api/me").Get()
+	as.Equal(http.StatusUnauthorized, res.Code)
+	as.Contains(res.Body.String(), "token revoked")
+}
+
+func Test_TokenCache(t *testing.T) {
+	now := time.Now()
+	u1 := models.User{ID: uuid.Must(uuid.NewV4())}
+	u2 := models.User{ID: uuid.Must(uuid.NewV4())}
+	tc := newTokenCache(time.Minute)
+	tc.put("a", cachedToken{user: u1, family: "f1", expiresAt: now.Add(time.Hour), loadedAt: now})
+	tc.put("b", cachedToken{user: u1, family: "f2", expiresAt: now.Add(time.Hour), loadedAt: now})
+	tc.put("c", cachedToken{user: u2, family: "f3", expiresAt: now.Add(time.Second), loadedAt: now})
+
+	hits, misses := tokenCacheHits.Value(), tokenCacheMisses.Value()
+	if _, ok := tc.get("a", now.Add(30*time.Second)); !ok {
+		t.Fatal("fresh entry missed")
+	}
+	if _, ok := tc.get("a", now.Add(time.Minute)); ok {
+		t.Fatal("entry older than the TTL hit")
+	}
+	if _, ok := tc.get("c", now.Add(2*time.Second)); ok {
+		t.Fatal("expired token hit")
+	}
+	if got := tokenCacheHits.Value() - hits; got != 1 {
+		t.Fatalf("hits = %d, want 1", got)
+	}
+	if got := tokenCacheMisses.Value() - misses; got != 2 {
+		t.Fatalf("misses = %d, want 2", got)
+	}
+
+	tc.forgetFamily("")
+	if _, ok := tc.get("b", now); !ok {
+		t.Fatal("empty family dropped entries")
+	}
+	tc.forgetFamily("f2")
+	if _, ok := tc.get("b", now); ok {
+		t.Fatal("forgotten family hit")
+	}
+	tc.forgetUser(u1.ID)
+	if _, ok := tc.get("a", now); ok {
+		t.Fatal("forgotten user hit")
+	}
+	if _, ok := tc.get("c", now); !ok {
+		t.Fatal("other user dropped")
+	}
+
+	off := newTokenCache(0)
+	off.put("a", cachedToken{user: u1, expiresAt: now.Add(time.Hour), loadedAt: now})
+	if _, ok := off.get("a", now); ok {
+		t.Fatal("disabled cache hit")
+	}
+}
+
+func (as *ActionSuite) Test_TokenCache_Logout() {
+	token := as.registerToken("cache@example.com")
+	as.Equal(http.StatusOK, as.authJSON(token, "/api/me").Get().Code)
+	as.Equal(http.StatusOK, as.authJSON(token, "/api/me").Get().Code)
+
+	as.Equal(http.StatusOK, as.authJSON(token, "/api/logout").Post(nil).Code)
+	res := as.authJSON(token, "/api/me").Get()
+	as.Equal(http.StatusUnauthorized, res.Code)
+	as.Contains(res.Body.String(), "token revoked")
+
+	// Metrics are off until a scrape token is configured
+	as.Equal(http.StatusForbidden, as.JSON("/metrics").Get().Code)
+	envy.Temp(func() {
+		envy.Set("METRICS_TOKEN", "scrape-secret")
+		as.Equal(http.StatusUnauthorized, as.authJSON("wrong", "/metrics").Get().Code)
+		res = as.authJSON("scrape-secret", "/metrics").Get()
+		as.Equal(http.StatusOK, res.Code)
+		as.Contains(res.Body.String(), "token_cache_hits_total")
+	})
+}
+
+func (as *ActionSuite) Test_SlidingSession() {
+	as.T().Setenv("SESSION_SLIDING_WINDOW_MINUTES", "5")
+	token := as.registerToken("sliding@example.com")
+	claims, err := ParseJWT(token)
+	as.NoError(err)
+
+	// Far from expiry: no renewal
+	res := as.authJSON(token, "/api/me").Get()
+	as.Equal(http.StatusOK, res.Code)
+	as.Empty(res.Header().Get(refreshedTokenHeader))
+
+	// Within the window: a fresh token of the same session
+	currentTokenCache().forgetJTI(claims.ID)
+	as.NoError(as.DB.RawQuery("UPDATE auth_tokens SET expires_at = ? WHERE jti = ?", time.Now().Add(2*time.Minute).UTC(), claims.ID).Exec())
+	res = as.authJSON(token, "/api/me").Get()
+	as.Equal(http.StatusOK, res.Code)
+	fresh := res.Header().Get(refreshedTokenHeader)
+	as.NotEmpty(fresh)
+	freshClaims, err := ParseJWT(fresh)
+	as.NoError(err)
+	as.Equal(claims.Family, freshClaims.Family)
+	as.NotEqual(claims.ID, freshClaims.ID)
+
+	// The old token keeps working briefly without renewing again
+	res = as.authJSON(token, "/api/me").Get()
+	as.Equal(http.StatusOK, res.Code)
+	as.Empty(res.Header().Get(refreshedTokenHeader))
+	var old models.AuthToken
+	as.NoError(as.DB.Where("jti = ?", claims.ID).First(&old))
+	as.True(old.ExpiresAt.Before(time.Now().Add(slidingGracePeriod + time.Second)))
+
+	// Once the grace period is over it stops working; the new one works
+	currentTokenCache().forgetJTI(claims.ID)
+	as.NoError(as.DB.RawQuery("UPDATE auth_tokens SET expires_at = ? WHERE jti = ?", time.Now().Add(-time.Second).UTC(), claims.ID).Exec())
+	res = as.authJSON(token, "/api/me").Get()
+	as.Equal(http.StatusUnauthorized, res.Code)
+	as.Contains(res.Body.String(), "token expired")
+	as.Equal(http.StatusOK, as.authJSON(fresh, "/api/me").Get().Code)
+}
+
+func Test_BcryptCost_Env(t *testing.T) {
+	cases := []struct {
+		raw     string
+		want    int
+		wantErr bool
+	}{
+		{"", bcrypt.DefaultCost, false},
+		{"12", 12, false},
+		{"4", bcrypt.DefaultCost, true},
+		{"20", bcrypt.DefaultCost, true},
+		{"high", bcrypt.DefaultCost, true},
+	}
+	for _, tc := range cases {
+		t.Setenv("BCRYPT_COST", tc.raw)
+		got, err := bcryptCost()
+		if got != tc.want || (err != nil) != tc.wantErr {
+			t.Errorf("%q: got %v, %v", tc.raw, got, err)
+		}
+	}
+}
+
+func Test_BcryptHasher_NeedsRehash(t *testing.T) {
+	low, err := bcrypt.GenerateFromPassword([]byte("secret123"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := bcryptHasher{cost: bcrypt.DefaultCost}
+	if !h.NeedsRehash(string(low)) {
+		t.Error("low-cost hash not flagged")
+	}
+	if (bcryptHasher{cost: bcrypt.MinCost}).NeedsRehash(string(low)) {
+		t.Error("hash at target cost flagged")
+	}
+	if h.NeedsRehash("not a hash") {
+		t.Error("malformed hash flagged")
+	}
+}
+
+func (as *ActionSuite) Test_Login_UpgradesHashCost() {
+	low, err := bcrypt.GenerateFromPassword([]byte("secret123"), bcrypt.MinCost)
+	as.NoError(err)
+	u := models.User{Email: "lowcost@example.com", PasswordHash: string(low)}
+	as.NoError(as.DB.Create(&u))
+
+	login := func() int {
+		return as.JSON("/api/auth/login").Post(map[string]string{"email": u.Email, "password": "secret123"}).Code
+	}
+	as.Equal(http.StatusOK, login())
+	as.NoError(as.DB.Reload(&u))
+	cost, err := bcrypt.Cost([]byte(u.PasswordHash))
+	as.NoError(err)
+	as.Equal(bcrypt.DefaultCost, cost)
+
+	// The upgraded hash still verifies the same password
+	as.Equal(http.StatusOK, login())
+}
+
+func (as *ActionSuite) Test_Introspect() {
+	// Only administrators may mint introspection keys
+	plain := as.registerToken("introspect-plain@example.com")
+	as.Equal(http.StatusForbidden, as.authJSON(plain, "/api/me/api-keys").Post(map[string]any{"name": "reporting", "scopes": []string{"introspect"}}).Code)
+
+	token, uid := as.adminToken("introspect@example.com")
+	claims, err := ParseJWT(token)
+	as.NoError(err)
+
+	res := as.authJSON(token, "/api/me/api-keys").Post(map[string]any{"name": "reporting", "scopes": []string{"introspect"}})
+	as.Equal(http.StatusCreated, res.Code)
+	var key struct {
+		Key string `json:"key"`
+	}
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &key))
+
+	// The key only introspects
+	as.Equal(http.StatusForbidden, as.authJSON(key.Key, "/api/tracks/").Get().Code)
+	as.Equal(http.StatusUnauthorized, as.JSON("/api/auth/introspect").Post(map[string]string{"token": token}).Code)
+	as.Equal(http.StatusUnauthorized, as.authJSON(token, "/api/auth/introspect").Post(map[string]string{"token": token}).Code)
+
+	introspect := func(caller, t string) map[string]any {
+		res := as.authJSON(caller, "/api/auth/introspect").Post(map[string]string{"token": t})
+		as.Equal(http.StatusOK, res.Code)
+		var body map[string]any
+		as.NoError(json.Unmarshal(res.Body.Bytes(), &body))
+		return body
+	}
+	body := introspect(key.Key, token)
+	as.Equal(true, body["active"])
+
+	// RFC 7662 clients send a form body
+	form := as.HTML("/api/auth/introspect")
+	form.Headers["Authorization"] = "Bearer " + key.Key
+	formRes := form.Post(url.Values{"token": {token}})
+	as.Equal(http.StatusOK, formRes.Code)
+	as.Contains(formRes.Body.String(), `"active":true`)
+	as.Equal(claims.UserID, body["sub"])
+	as.Equal(claims.ID, body["jti"])
+
+	// A static credential works too
+	envy.Temp(func() {
+		envy.Set("INTROSPECTION_TOKEN", "service-secret")
+		as.Equal(true, introspect("service-secret", token)["active"])
+	})
+
+	// Revoked and garbage tokens are inactive, without details
+	as.Equal(http.StatusOK, as.authJSON(token, "/api/logout").Post(nil).Code)
+	as.Equal(map[string]any{"active": false}, introspect(key.Key, token))
+	as.Equal(map[string]any{"active": false}, introspect(key.Key, "not-a-jwt"))
+
+	// Keys of a demoted administrator stop working
+	as.NoError(as.DB.RawQuery("UPDATE users SET is_admin = false WHERE id = ?", uid).Exec())
+	as.Equal(http.StatusUnauthorized, as.authJSON(key.Key, "/api/auth/introspect").Post(map[string]string{"token": "not-a-jwt"}).Code)
+}
+
+func Test_DummyPasswordHash(t *testing.T) {
+	// A malformed hash would fail fast and defeat the purpose
+	for _, h := range [][]byte{[]byte(fallbackDummyHash), dummyPasswordHash()} {
+		if err := bcrypt.CompareHashAndPassword(h, []byte("secret123")); !errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			t.Errorf("%s: got %v", h, err)
+		}
+		if cost, err := bcrypt.Cost(h); err != nil || cost < bcrypt.DefaultCost {
+			t.Errorf("%s: cost %d, %v", h, cost, err)
+		}
+	}
+}
+
+func Test_PasswordMatches(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret123"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !passwordMatches(string(hash), "secret123") || passwordMatches(string(hash), "wrong") {
+		t.Error("password hash not compared")
+	}
+	// Passwordless accounts match nothing, not even an empty password
+	for _, pw := range []string{"", "secret123"} {
+		if passwordMatches("", pw) {
+			t.Errorf("passwordless account matched %q", pw)
+		}
+	}
+}
+
+func (as *ActionSuite) Test_Login_UniformFailures() {
+	as.registerToken("known@example.com")
+	token := as.registerToken("passwordless@example.com")
+	claims, err := ParseJWT(token)
+	as.NoError(err)
+	as.NoError(as.DB.RawQuery("UPDATE users SET password_hash = '' WHERE id = ?", claims.UserID).Exec())
+
+	unknown := as.JSON("/api/auth/login").Post(map[string]string{"email": "unknown@example.com", "password": "secret123"})
+	wrong := as.JSON("/api/auth/login").Post(map[string]string{"email": "known@example.com", "password": "wrong-password"})
+	passwordless := as.JSON("/api/auth/login").Post(map[string]string{"email": "passwordless@example.com", "password": ""})
+	as.Equal(http.StatusUnauthorized, unknown.Code)
+	as.Equal(unknown.Code, wrong.Code)
+	as.Equal(unknown.Body.String(), wrong.Body.String())
+	as.Equal(unknown.Code, passwordless.Code)
+	as.Equal(unknown.Body.String(), passwordless.Body.String())
+}
+
+func (as *ActionSuite) Test_Register_ExistingEmail() {
+	as.registerToken("taken@example.com")
+	// Registration still tells a taken address apart; it is not hidden
+	for _, email := range []string{"taken@example.com", " Taken@Example.com "} {
+		res := as.JSON("/api/auth/register").Post(map[string]string{"email": email, "password": "another-secret-9"})
+		as.Equal(http.StatusConflict, res.Code)
+		as.Contains(res.Body.String(), "email already in use")
+	}
+}
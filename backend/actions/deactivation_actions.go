@@ -0,0 +1,193 @@
+/**
+ * Deactivation Actions - Freezing Accounts Without Deleting Them
+ *
+ * A deactivated account keeps all its data, so entries stay in team
+ * reports and invoices, but cannot be used:
+ * - Its sessions are revoked when it is deactivated
+ * - AuthRequired answers 403 {"error", "code": "account_deactivated"}
+ *   for its API keys and any token issued later
+ *
+ * Users deactivate themselves with POST /api/me/deactivate and are
+ * reactivated by signing in again. Accounts deactivated by an admin
+ * (POST /api/admin/users/{id}/deactivate) cannot sign in until an admin
+ * reactivates them.
+ *
+ * Unlike disabling (see admin_actions.go), which pauses an account and
+ * keeps its sessions, deactivation is meant for people who have left.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-21
+ */
+package actions
+
+import (
+	"net/http"
+	"time"
+
+	"backend/models"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/nulls"
+	"github.com/gobuffalo/pop/v6"
+)
+
+// codeAccountDeactivated identifies deactivation in 403 responses.
+const codeAccountDeactivated = "account_deactivated"
+
+/**
+ * renderDeactivated renders the 403 for a deactivated account
+ */
+func renderDeactivated(c buffalo.Context) error {
+	return c.Render(http.StatusForbidden, r.JSON(map[string]string{
+		"error": "account deactivated",
+		"code":  codeAccountDeactivated,
+	}))
+}
+
+/**
+ * admitSignIn decides whether a user who proved their identity may sign
+ * in. Self-deactivated accounts are reactivated; accounts deactivated by
+ * an admin are refused.
+ *
+ * @param tx - Request transaction
+ * @param u - User signing in, updated in place
+ * @return bool - Whether tokens may be issued
+ */
+func admitSignIn(tx *pop.Connection, u *models.User) (bool, error) {
+	if !u.DeactivatedAt.Valid {
+		return true, nil
+	}
+	if !u.SelfDeactivated {
+		return false, nil
+	}
+	if err := setDeactivated(tx, u, false, false); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+/**
+ * setDeactivated deactivates or reactivates an account; deactivation
+ * revokes all its sessions
+ *
+ * @param tx - Request transaction
+ * @param u - Account, updated in place
+ * @param deactivate - Deactivate (true) or reactivate (false)
+ * @param self - The user deactivates their own account
+ */
+func setDeactivated(tx *pop.Connection, u *models.User, deactivate, self bool) error {
+	now := time.Now().UTC()
+	if deactivate {
+		if !u.DeactivatedAt.Valid {
+			u.DeactivatedAt = nulls.NewTime(now)
+		}
+		u.SelfDeactivated = self
+	} else {
+		u.DeactivatedAt = nulls.Time{}
+		u.SelfDeactivated = false
+	}
+	if err := tx.RawQuery(
+		"UPDATE users SET deactivated_at = ?, self_deactivated = ?, updated_at = ? WHERE id = ?",
+		u.DeactivatedAt, u.SelfDeactivated, now, u.ID,
+	).Exec(); err != nil {
+		return err
+	}
+	if deactivate {
+		return revokeAllSessions(tx, u.ID, now)
+	}
+	return nil
+}
+
+/**
+ * MeDeactivate deactivates the user's own account
+ *
+ * POST /api/me/deactivate
+ *
+ * Payload: {"password": "<current password>"}
+ *
+ * All sessions, including the current one, are revoked. Signing in again
+ * reactivates the account.
+ *
+ * @param c - Buffalo context with authenticated user
+ * @return empty 204 response or JSON error response
+ */
+func MeDeactivate(c buffalo.Context) error {
+	u, status, msg := reauthenticate(c)
+	if status != 0 {
+		return c.Render(status, r.JSON(map[string]string{"error": msg}))
+	}
+
+	if err := setDeactivated(mustTx(c), &u, true, true); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot deactivate"}))
+	}
+	forgetTokens(c, func(tc *tokenCache) { tc.forgetUser(u.ID) })
+	c.Response().WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+/**
+ * AdminUsersDeactivate deactivates an account
+ *
+ * POST /api/admin/users/{id}/deactivate
+ *
+ * Revokes the user's sessions; the user cannot sign in until an admin
+ * reactivates the account. A self-deactivated account becomes admin
+ * deactivated.
+ *
+ * @param c - Buffalo context with an admin and user ID
+ * @return JSON user or error response
+ */
+func AdminUsersDeactivate(c buffalo.Context) error {
+	return adminSetDeactivated(c, true)
+}
+
+/**
+ * AdminUsersReactivate reactivates an account
+ *
+ * POST /api/admin/users/{id}/reactivate
+ *
+ * @param c - Buffalo context with an admin and user ID
+ * @return JSON user or error response
+ */
+func AdminUsersReactivate(c buffalo.Context) error {
+	return adminSetDeactivated(c, false)
+}
+
+/**
+ * adminSetDeactivated implements AdminUsersDeactivate and
+ * AdminUsersReactivate
+ */
+func adminSetDeactivated(c buffalo.Context, deactivate bool) error {
+	actor, _ := currentUserID(c)
+	u, status, msg := adminTarget(c)
+	if status != 0 {
+		return c.Render(status, r.JSON(map[string]string{"error": msg}))
+	}
+	if deactivate && u.ID == actor {
+		return c.Render(http.StatusConflict, r.JSON(map[string]string{"error": "cannot deactivate yourself"}))
+	}
+
+	tx := mustTx(c)
+	if err := setDeactivated(tx, &u, deactivate, false); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+	action := models.AdminActionReactivate
+	if deactivate {
+		action = models.AdminActionDeactivate
+	}
+	if err := recordAdminAction(tx, actor, u, action); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+	forgetTokens(c, func(tc *tokenCache) { tc.forgetUser(u.ID) })
+	return c.Render(http.StatusOK, r.JSON(u))
+}
+
+/**
+ * refuseDeactivatedSignIn records and renders a sign-in refused by
+ * admitSignIn
+ */
+func refuseDeactivatedSignIn(c buffalo.Context, method string, u models.User) error {
+	recordLoginFailure(c, method, models.LoginDeactivated, u.ID, u.Email)
+	return renderDeactivated(c)
+}
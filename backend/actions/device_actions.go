@@ -0,0 +1,172 @@
+/**
+ * Device Actions - Push Notification Registration
+ *
+ * Clients register their push token after sign-in and on every app
+ * start:
+ * - POST /api/me/devices upserts by push token, so repeated
+ *   registrations update the existing device (and last_seen_at) instead
+ *   of adding rows. A token registered by another user moves to the
+ *   caller, since the device changed hands.
+ * - GET /api/me/devices lists the user's devices
+ * - DELETE /api/me/devices/{id} removes one, e.g. on sign-out
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-21
+ */
+package actions
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"backend/models"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/nulls"
+	"github.com/gofrs/uuid"
+)
+
+const (
+	// maxPushTokenLength matches the devices.push_token column.
+	maxPushTokenLength = 512
+	// maxDeviceName matches the devices.name column.
+	maxDeviceName = 100
+)
+
+/**
+ * DevicesIndex lists the user's devices, most recently seen first
+ *
+ * GET /api/me/devices
+ *
+ * @param c - Buffalo context with authenticated user
+ * @return JSON array of Device or error response
+ */
+func DevicesIndex(c buffalo.Context) error {
+	uid, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+
+	list := []models.Device{}
+	if err := mustTx(c).Where("user_id = ?", uid).Order("last_seen_at DESC").All(&list); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+	return c.Render(http.StatusOK, r.JSON(list))
+}
+
+/**
+ * DevicesRegister registers a push token
+ *
+ * POST /api/me/devices
+ *
+ * Payload:
+ * - platform: ios, android or web (required)
+ * - push_token: Provider token (required, up to 512 characters)
+ * - name: Optional label, up to 100 characters
+ *
+ * Responses:
+ * - 201 with the device when the token is new
+ * - 200 with the device when it was registered before
+ * - 409 when the user already has 20 other devices
+ *
+ * @param c - Buffalo context with authenticated user
+ * @return JSON Device or error response
+ */
+func DevicesRegister(c buffalo.Context) error {
+	var p struct {
+		Platform  string `json:"platform"`
+		PushToken string `json:"push_token"`
+		Name      string `json:"name"`
+	}
+	if err := c.Bind(&p); err != nil {
+		return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "bad payload"}))
+	}
+	uid, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+
+	p.Platform = strings.ToLower(strings.TrimSpace(p.Platform))
+	if !slices.Contains(models.DevicePlatforms, p.Platform) {
+		return renderFieldError(c, "platform", errors.New("must be one of ios, android, web"))
+	}
+	p.PushToken = strings.TrimSpace(p.PushToken)
+	if p.PushToken == "" {
+		return renderFieldError(c, "push_token", errors.New("required"))
+	}
+	if len(p.PushToken) > maxPushTokenLength {
+		return renderFieldError(c, "push_token", errors.New("too long"))
+	}
+	var name nulls.String
+	if n := strings.TrimSpace(p.Name); n != "" {
+		if len(n) > maxDeviceName {
+			return renderFieldError(c, "name", errors.New("too long"))
+		}
+		name = nulls.NewString(n)
+	}
+
+	tx := mustTx(c)
+	count, err := tx.Where("user_id = ? AND push_token <> ?", uid, p.PushToken).Count(&models.Device{})
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+	if count >= models.MaxDevices {
+		return c.Render(http.StatusConflict, r.JSON(map[string]string{"error": "device limit reached"}))
+	}
+
+	// xmax is 0 for rows this statement inserted
+	var row struct {
+		models.Device
+		Inserted bool `db:"inserted"`
+	}
+	now := time.Now().UTC()
+	err = tx.RawQuery(`
+		INSERT INTO devices (user_id, platform, push_token, name, last_seen_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (push_token) DO UPDATE
+		SET user_id = EXCLUDED.user_id, platform = EXCLUDED.platform,
+		    name = COALESCE(EXCLUDED.name, devices.name),
+		    last_seen_at = EXCLUDED.last_seen_at, updated_at = EXCLUDED.updated_at
+		RETURNING *, (xmax = 0) AS inserted
+	`, uid, p.Platform, p.PushToken, name, now, now, now).First(&row)
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot register device"}))
+	}
+	status := http.StatusOK
+	if row.Inserted {
+		status = http.StatusCreated
+	}
+	return c.Render(status, r.JSON(row.Device))
+}
+
+/**
+ * DevicesDelete removes a device
+ *
+ * DELETE /api/me/devices/{id}
+ *
+ * @param c - Buffalo context with authenticated user and device ID
+ * @return JSON status or error response
+ */
+func DevicesDelete(c buffalo.Context) error {
+	uid, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+	id, err := uuid.FromString(c.Param("id"))
+	if err != nil {
+		return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "bad id"}))
+	}
+
+	n, err := mustTx(c).RawQuery("DELETE FROM devices WHERE id = ? AND user_id = ?", id, uid).ExecWithCount()
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot delete"}))
+	}
+	if n == 0 {
+		return c.Render(http.StatusNotFound, r.JSON(map[string]string{"error": "not found"}))
+	}
+	return c.Render(http.StatusOK, r.JSON(map[string]string{"status": "deleted"}))
+}
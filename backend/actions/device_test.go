@@ -0,0 +1,83 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"backend/models"
+	"backend/push"
+)
+
+// chanNotifier hands sent notifications to the test.
+type chanNotifier chan push.Notification
+
+func (n chanNotifier) Send(_ context.Context, msg push.Notification) error {
+	n <- msg
+	return nil
+}
+
+func (as *ActionSuite) Test_Devices_Upsert() {
+	token := as.registerToken("devices@example.com")
+	body := map[string]string{"platform": "android", "push_token": "fcm-token-1", "name": "Pixel"}
+
+	res := as.authJSON(token, "/api/me/devices").Post(body)
+	as.Equal(http.StatusCreated, res.Code)
+	var first models.Device
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &first))
+
+	// Registering the same token again updates the device
+	body["name"] = ""
+	res = as.authJSON(token, "/api/me/devices").Post(body)
+	as.Equal(http.StatusOK, res.Code)
+	var again models.Device
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &again))
+	as.Equal(first.ID, again.ID)
+	as.Equal("Pixel", again.Name.String)
+	count, err := as.DB.Where("push_token = ?", "fcm-token-1").Count(&models.Device{})
+	as.NoError(err)
+	as.Equal(1, count)
+
+	// A device changing hands moves to the new user
+	other := as.registerToken("devices2@example.com")
+	as.Equal(http.StatusOK, as.authJSON(other, "/api/me/devices").Post(body).Code)
+	res = as.authJSON(token, "/api/me/devices").Get()
+	var mine []models.Device
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &mine))
+	as.Empty(mine)
+
+	res = as.authJSON(other, "/api/me/devices").Post(map[string]string{"platform": "fax", "push_token": "x"})
+	as.Equal(http.StatusUnprocessableEntity, res.Code)
+
+	res, err = as.authJSON(other, "/api/me/devices/%s", first.ID).Do(http.MethodDelete, nil)
+	as.NoError(err)
+	as.Equal(http.StatusOK, res.Code)
+}
+
+func (as *ActionSuite) Test_AutoStop_NotifiesDevices() {
+	defer func(n push.Notifier) { pushNotifier = n }(pushNotifier)
+	sent := make(chanNotifier, 1)
+	pushNotifier = sent
+
+	token := as.registerToken("autostop-push@example.com")
+	as.Equal(http.StatusCreated, as.authJSON(token, "/api/me/devices").Post(map[string]string{"platform": "ios", "push_token": "apns-via-fcm"}).Code)
+	res := as.authJSON(token, "/api/tracks/start").Post(map[string]any{})
+	as.Equal(http.StatusCreated, res.Code)
+	var entry models.TimeTrac
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &entry))
+	as.NoError(as.DB.RawQuery("UPDATE timetrac SET start_at = ? WHERE id = ?",
+		time.Now().Add(-time.Duration(models.DefaultMaxRunningHours+1)*time.Hour).UTC(), entry.ID).Exec())
+
+	n, err := AutoStopForgottenTracks(as.DB, time.Now())
+	as.NoError(err)
+	as.Equal(1, n)
+	select {
+	case msg := <-sent:
+		as.Equal("apns-via-fcm", msg.Token)
+		as.Equal(autoStopTitle, msg.Title)
+		as.Equal(entry.ID.String(), msg.Data["track_id"])
+	case <-time.After(5 * time.Second):
+		as.Fail("no notification sent")
+	}
+}
@@ -0,0 +1,152 @@
+/**
+ * Geocode Jobs - Background Address Lookup for Entries
+ *
+ * Entries started with coordinates but without an address are queued for
+ * reverse geocoding once the request commits, when a provider is
+ * configured: coordinates are never sent to a third party the operator
+ * did not choose. A single worker resolves them one at a time (keeping
+ * within the provider's rate limit) and fills location_addr. A failed
+ * lookup is queued again with backoff up to geocodeMaxAttempts times;
+ * failures are logged and never affect the entry itself.
+ *
+ * Configuration:
+ * - GEOCODER_URL: Nominatim-compatible reverse endpoint; unset (or "off")
+ *   disables geocoding
+ * - GEOCODER_MIN_INTERVAL_MS: minimum time between provider requests (default 1000)
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-20
+ */
+package actions
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"backend/geocode"
+	"backend/models"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/envy"
+	"github.com/gofrs/uuid"
+)
+
+/**
+ * geocodeJob is a pending address lookup for one entry
+ */
+type geocodeJob struct {
+	TrackID uuid.UUID
+	Lat     float64
+	Lng     float64
+	Attempt int
+}
+
+// geocodeQueue is nil until startGeocoder runs; enqueueGeocode is then a no-op.
+var geocodeQueue chan geocodeJob
+
+// geocodeMaxAttempts is how often a lookup is tried before it is dropped.
+const geocodeMaxAttempts = 3
+
+// geocodeRetryDelay is the wait before the first retry; it doubles after that.
+var geocodeRetryDelay = 5 * time.Second
+
+/**
+ * queueGeocode schedules an address lookup for a started entry without an
+ * address. The job is queued after the request transaction commits, so
+ * the worker never looks for a row that is not visible yet.
+ */
+func queueGeocode(c buffalo.Context, item models.TimeTrac) {
+	if item.LocationAddr.Valid && item.LocationAddr.String != "" {
+		return
+	}
+	afterCommit(c, func() { enqueueGeocode(item) })
+}
+
+/**
+ * enqueueGeocode schedules an address lookup for an entry. It never
+ * blocks: when the queue is full or geocoding is disabled the job is
+ * dropped.
+ */
+func enqueueGeocode(item models.TimeTrac) {
+	if !item.LocationLat.Valid || !item.LocationLng.Valid {
+		return
+	}
+	sendGeocodeJob(geocodeJob{TrackID: item.ID, Lat: item.LocationLat.Float64, Lng: item.LocationLng.Float64})
+}
+
+/**
+ * sendGeocodeJob hands a job to the worker, dropping it when the queue is
+ * full or geocoding is disabled
+ */
+func sendGeocodeJob(job geocodeJob) {
+	if geocodeQueue == nil {
+		return
+	}
+	select {
+	case geocodeQueue <- job:
+	default:
+	}
+}
+
+/**
+ * retryGeocode queues a failed job again after a backoff delay, without
+ * holding up the worker in the meantime
+ *
+ * @return bool - false once the job has used up its attempts
+ */
+func retryGeocode(job geocodeJob) bool {
+	job.Attempt++
+	if job.Attempt >= geocodeMaxAttempts {
+		return false
+	}
+	time.AfterFunc(geocodeRetryDelay<<(job.Attempt-1), func() { sendGeocodeJob(job) })
+	return true
+}
+
+/**
+ * startGeocoder starts the reverse geocoding worker
+ */
+func startGeocoder(app *buffalo.App) {
+	providerURL := envy.Get("GEOCODER_URL", "")
+	if providerURL == "" || providerURL == "off" {
+		return
+	}
+	ms, err := strconv.Atoi(envy.Get("GEOCODER_MIN_INTERVAL_MS", "1000"))
+	if err != nil || ms < 0 {
+		ms = 1000
+	}
+	client := geocode.NewClient(providerURL, time.Duration(ms)*time.Millisecond)
+
+	geocodeQueue = make(chan geocodeJob, 256)
+	go func() {
+		for job := range geocodeQueue {
+			if err := resolveTrackAddress(client, job); err != nil {
+				if retryGeocode(job) {
+					app.Logger.Warnf("geocode %s: %v; retrying", job.TrackID, err)
+				} else {
+					app.Logger.Warnf("geocode %s: %v", job.TrackID, err)
+				}
+			}
+		}
+	}()
+}
+
+/**
+ * resolveTrackAddress looks up the address for a queued entry and stores
+ * it unless the entry got an address in the meantime (or is gone)
+ */
+func resolveTrackAddress(client *geocode.Client, job geocodeJob) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	addr, err := client.Reverse(ctx, job.Lat, job.Lng)
+	if err != nil {
+		return err
+	}
+	return models.DB.RawQuery(`
+		UPDATE timetrac SET location_addr = ?
+		WHERE id = ? AND (location_addr IS NULL OR location_addr = '')
+	`, addr, job.TrackID).Exec()
+}
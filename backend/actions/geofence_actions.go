@@ -0,0 +1,383 @@
+/**
+ * Geofence Actions - Job Site Fences and Location Pings
+ *
+ * This file provides the endpoints for location-based tracking:
+ * - CRUD for the user's geofences
+ * - Location pings from the mobile app, which stop the running entry or
+ *   record a reminder when the user leaves the fence it was started in
+ * - Listing and dismissing pending geofence reminders
+ *
+ * A fence is tied to the running entry when the entry's start location
+ * lies inside it. Exits are detected with a margin beyond the radius (see
+ * models.Geofence.Exited) so GPS jitter at the boundary does not flap.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-20
+ */
+package actions
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"backend/models"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/nulls"
+	"github.com/gofrs/uuid"
+)
+
+// Geofence radius bounds in meters.
+const (
+	geofenceMinRadius = 25
+	geofenceMaxRadius = 50000
+)
+
+/**
+ * geofencePayload is the request body for creating and updating fences;
+ * nil fields are left unchanged on update
+ */
+type geofencePayload struct {
+	Name    *string  `json:"name"`
+	Lat     *float64 `json:"lat"`
+	Lng     *float64 `json:"lng"`
+	RadiusM *int     `json:"radius_m"`
+	Action  *string  `json:"action"`
+}
+
+/**
+ * apply copies the provided fields onto g and validates the result
+ *
+ * @return string - Validation error message, "" if valid
+ */
+func (p geofencePayload) apply(g *models.Geofence) string {
+	if p.Name != nil {
+		g.Name = strings.TrimSpace(*p.Name)
+	}
+	if p.Lat != nil {
+		g.Lat = *p.Lat
+	}
+	if p.Lng != nil {
+		g.Lng = *p.Lng
+	}
+	if p.RadiusM != nil {
+		g.RadiusM = *p.RadiusM
+	}
+	if p.Action != nil {
+		g.Action = strings.TrimSpace(*p.Action)
+	}
+
+	switch {
+	case g.Name == "":
+		return "name required"
+	case g.Lat < -90 || g.Lat > 90 || g.Lng < -180 || g.Lng > 180:
+		return "lat must be within ±90 and lng within ±180"
+	case g.RadiusM < geofenceMinRadius || g.RadiusM > geofenceMaxRadius:
+		return "radius_m must be between 25 and 50000"
+	case g.Action != models.GeofenceActionStop && g.Action != models.GeofenceActionNotify:
+		return "action must be stop or notify"
+	}
+	return ""
+}
+
+/**
+ * GeofencesIndex lists the authenticated user's geofences
+ *
+ * GET /api/geofences
+ *
+ * @param c - Buffalo context with authenticated user
+ * @return JSON array of Geofence or error response
+ */
+func GeofencesIndex(c buffalo.Context) error {
+	tx := mustTx(c)
+	uid, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+
+	list := []models.Geofence{}
+	if err := tx.Where("user_id = ?", uid).Order("name ASC").All(&list); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+	return c.Render(http.StatusOK, r.JSON(list))
+}
+
+/**
+ * GeofencesCreate creates a geofence
+ *
+ * POST /api/geofences
+ *
+ * Payload:
+ * - name: Display name (required)
+ * - lat, lng: Center coordinates (required)
+ * - radius_m: Radius in meters, 25–50000 (required)
+ * - action: "stop" (default) or "notify"
+ *
+ * @param c - Buffalo context with authenticated user
+ * @return JSON Geofence or error response
+ */
+func GeofencesCreate(c buffalo.Context) error {
+	var p geofencePayload
+	if err := c.Bind(&p); err != nil {
+		return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "bad payload"}))
+	}
+
+	tx := mustTx(c)
+	uid, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+
+	if p.Lat == nil || p.Lng == nil || p.RadiusM == nil {
+		return c.Render(http.StatusUnprocessableEntity, r.JSON(map[string]string{"error": "lat, lng and radius_m required"}))
+	}
+	g := models.Geofence{UserID: uid, Action: models.GeofenceActionStop}
+	if msg := p.apply(&g); msg != "" {
+		return c.Render(http.StatusUnprocessableEntity, r.JSON(map[string]string{"error": msg}))
+	}
+
+	if err := tx.Create(&g); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot create"}))
+	}
+	return c.Render(http.StatusCreated, r.JSON(g))
+}
+
+/**
+ * GeofencesUpdate modifies a geofence
+ *
+ * PATCH /api/geofences/{id}
+ *
+ * Accepts the same fields as GeofencesCreate, all optional.
+ *
+ * @param c - Buffalo context with authenticated user and fence ID
+ * @return JSON Geofence or error response
+ */
+func GeofencesUpdate(c buffalo.Context) error {
+	id, err := uuid.FromString(c.Param("id"))
+	if err != nil {
+		return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "bad id"}))
+	}
+	var p geofencePayload
+	if err := c.Bind(&p); err != nil {
+		return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "bad payload"}))
+	}
+
+	tx := mustTx(c)
+	uid, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+
+	var g models.Geofence
+	if err := tx.Where("id = ? AND user_id = ?", id, uid).First(&g); err != nil {
+		return c.Render(http.StatusNotFound, r.JSON(map[string]string{"error": "not found"}))
+	}
+	if msg := p.apply(&g); msg != "" {
+		return c.Render(http.StatusUnprocessableEntity, r.JSON(map[string]string{"error": msg}))
+	}
+
+	g.UpdatedAt = time.Now()
+	if err := tx.Update(&g); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot update"}))
+	}
+	return c.Render(http.StatusOK, r.JSON(g))
+}
+
+/**
+ * GeofencesDelete removes a geofence and its reminders
+ *
+ * DELETE /api/geofences/{id}
+ *
+ * @param c - Buffalo context with authenticated user and fence ID
+ * @return JSON status or error response
+ */
+func GeofencesDelete(c buffalo.Context) error {
+	id, err := uuid.FromString(c.Param("id"))
+	if err != nil {
+		return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "bad id"}))
+	}
+
+	tx := mustTx(c)
+	uid, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+
+	var g models.Geofence
+	if err := tx.Where("id = ? AND user_id = ?", id, uid).First(&g); err != nil {
+		return c.Render(http.StatusNotFound, r.JSON(map[string]string{"error": "not found"}))
+	}
+	if err := tx.Destroy(&g); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot delete"}))
+	}
+	return c.Render(http.StatusOK, r.JSON(map[string]string{"status": "deleted"}))
+}
+
+/**
+ * LocationPing processes the user's current position
+ *
+ * POST /api/location/ping
+ *
+ * Called periodically by the mobile app. If an entry is running and was
+ * started inside one of the user's fences, and the position is clearly
+ * outside that fence:
+ * - action "stop": the entry is stopped now with stopped_reason
+ *   "geofence_exit"
+ * - action "notify": a pending reminder is recorded (once per fence and
+ *   entry)
+ *
+ * Payload:
+ * - lat, lng: Current coordinates (required)
+ * - accuracy_m: Reported GPS accuracy in meters (optional, widens the
+ *   exit margin)
+ *
+ * Response:
+ * - entry: The running entry, or the entry that was just stopped (null
+ *   if nothing is running)
+ * - stopped: True if the entry was stopped by this ping
+ * - notifications: Reminders created by this ping
+ *
+ * @param c - Buffalo context with authenticated user
+ * @return JSON ping result or error response
+ */
+func LocationPing(c buffalo.Context) error {
+	type payload struct {
+		Lat       *float64 `json:"lat"`
+		Lng       *float64 `json:"lng"`
+		AccuracyM float64  `json:"accuracy_m"`
+	}
+	var p payload
+	if err := c.Bind(&p); err != nil {
+		return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "bad payload"}))
+	}
+	if p.Lat == nil || p.Lng == nil || *p.Lat < -90 || *p.Lat > 90 || *p.Lng < -180 || *p.Lng > 180 {
+		return c.Render(http.StatusUnprocessableEntity, r.JSON(map[string]string{"error": "valid lat and lng required"}))
+	}
+
+	tx := mustTx(c)
+	uid, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+
+	type result struct {
+		Entry         *models.TimeTrac              `json:"entry"`
+		Stopped       bool                          `json:"stopped"`
+		Notifications []models.GeofenceNotification `json:"notifications"`
+	}
+	res := result{Notifications: []models.GeofenceNotification{}}
+
+	var item models.TimeTrac
+	if err := tx.Where("user_id = ? AND end_at IS NULL AND deleted_at IS NULL", uid).Order("start_at DESC").First(&item); err != nil {
+		return c.Render(http.StatusOK, r.JSON(res))
+	}
+	res.Entry = &item
+	if !item.LocationLat.Valid || !item.LocationLng.Valid {
+		return c.Render(http.StatusOK, r.JSON(res))
+	}
+
+	var fences []models.Geofence
+	if err := tx.Where("user_id = ?", uid).All(&fences); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+
+	now := time.Now()
+	for _, g := range fences {
+		if !g.Contains(item.LocationLat.Float64, item.LocationLng.Float64) || !g.Exited(*p.Lat, *p.Lng, p.AccuracyM) {
+			continue
+		}
+
+		if g.Action == models.GeofenceActionStop {
+			if err := recordRevision(tx, item, uid); err != nil {
+				return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot stop"}))
+			}
+			item.StopReason = nulls.NewString(models.StopReasonGeofenceExit)
+			if err := stopTrack(tx, &item, now); err != nil {
+				return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot stop"}))
+			}
+			res.Stopped = true
+			break
+		}
+
+		// One reminder per fence and entry, however often (or concurrently)
+		// the app pings: the unique index decides
+		created := []models.GeofenceNotification{}
+		if err := tx.RawQuery(`
+			INSERT INTO geofence_notifications (id, user_id, geofence_id, track_id, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?)
+			ON CONFLICT (geofence_id, track_id) DO NOTHING
+			RETURNING *
+		`, uuid.Must(uuid.NewV4()), uid, g.ID, item.ID, now, now).All(&created); err != nil {
+			return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot record notification"}))
+		}
+		res.Notifications = append(res.Notifications, created...)
+	}
+
+	items := []models.TimeTrac{item}
+	if err := attachPauses(tx, items, now); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+	res.Entry = &items[0]
+	if res.Stopped {
+		queueTrackEvent(c, eventTrackStopped, items[0])
+	}
+	return c.Render(http.StatusOK, r.JSON(res))
+}
+
+/**
+ * GeofenceNotificationsIndex lists pending geofence reminders
+ *
+ * GET /api/geofences/notifications
+ *
+ * @param c - Buffalo context with authenticated user
+ * @return JSON array of GeofenceNotification or error response
+ */
+func GeofenceNotificationsIndex(c buffalo.Context) error {
+	tx := mustTx(c)
+	uid, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+
+	list := []models.GeofenceNotification{}
+	if err := tx.Where("user_id = ? AND dismissed_at IS NULL", uid).Order("created_at DESC").All(&list); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+	return c.Render(http.StatusOK, r.JSON(list))
+}
+
+/**
+ * GeofenceNotificationsDismiss marks a geofence reminder as handled
+ *
+ * POST /api/geofences/notifications/{id}/dismiss
+ *
+ * @param c - Buffalo context with authenticated user and notification ID
+ * @return JSON GeofenceNotification or error response
+ */
+func GeofenceNotificationsDismiss(c buffalo.Context) error {
+	id, err := uuid.FromString(c.Param("id"))
+	if err != nil {
+		return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "bad id"}))
+	}
+
+	tx := mustTx(c)
+	uid, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+
+	var n models.GeofenceNotification
+	if err := tx.Where("id = ? AND user_id = ?", id, uid).First(&n); err != nil {
+		return c.Render(http.StatusNotFound, r.JSON(map[string]string{"error": "not found"}))
+	}
+	if !n.DismissedAt.Valid {
+		n.DismissedAt = nulls.NewTime(time.Now())
+		n.UpdatedAt = n.DismissedAt.Time
+		if err := tx.Update(&n); err != nil {
+			return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot dismiss"}))
+		}
+	}
+	return c.Render(http.StatusOK, r.JSON(n))
+}
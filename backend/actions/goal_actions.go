@@ -0,0 +1,182 @@
+/**
+ * Goal Actions - Weekly Time Goal Progress
+ *
+ * The weekly goal is the weekly_goal_minutes preference. Changes are
+ * snapshotted per week into weekly_goals so history shows the goal that
+ * was active in each week:
+ * - Progress for the current week (embedded in summary and compare)
+ * - Goal vs. actual for the last 12 weeks
+ *
+ * Weeks are ISO weeks in the user's timezone.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-20
+ */
+package actions
+
+import (
+	"math"
+	"net/http"
+	"time"
+
+	"backend/models"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gofrs/uuid"
+)
+
+// goalHistoryWeeks is the number of weeks returned by GoalsHistory.
+const goalHistoryWeeks = 12
+
+/**
+ * goalProgress is the current week's progress towards the goal; the
+ * derived fields are null when no goal is set
+ */
+type goalProgress struct {
+	GoalMinutes      int      `json:"goal_minutes"`
+	ProgressPercent  *float64 `json:"progress_percent"`
+	RemainingMinutes *int     `json:"remaining_minutes"`
+}
+
+/**
+ * newGoalProgress computes progress for tracked seconds against a goal
+ */
+func newGoalProgress(goalMinutes int, trackedSeconds float64) goalProgress {
+	g := goalProgress{GoalMinutes: goalMinutes}
+	if goalMinutes <= 0 {
+		return g
+	}
+	pct := math.Round(trackedSeconds/60/float64(goalMinutes)*1000) / 10
+	remaining := int(math.Max(0, math.Ceil(float64(goalMinutes)-trackedSeconds/60)))
+	g.ProgressPercent = &pct
+	g.RemainingMinutes = &remaining
+	return g
+}
+
+/**
+ * snapshotWeeklyGoal records the preference's goal for the current week
+ * in the user's timezone
+ */
+func snapshotWeeklyGoal(tx *pop.Connection, prefs models.UserPreferences, now time.Time) error {
+	loc, err := time.LoadLocation(prefs.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	weekStart, _, _ := periodBounds(now, loc, "week")
+	return tx.RawQuery(`
+		INSERT INTO weekly_goals (user_id, week_start, goal_minutes, created_at, updated_at)
+		VALUES (?, ?::date, ?, ?, ?)
+		ON CONFLICT (user_id, week_start) DO UPDATE
+		SET goal_minutes = EXCLUDED.goal_minutes, updated_at = EXCLUDED.updated_at
+	`, prefs.ID, weekStart.Format("2006-01-02"), prefs.WeeklyGoalMinutes, now, now).Exec()
+}
+
+/**
+ * currentWeekGoal returns the current week's goal progress
+ *
+ * @param tx - Database transaction
+ * @param uid - User ID
+ * @param loc - Timezone for the week boundaries
+ * @param now - Reference time
+ */
+func currentWeekGoal(tx *pop.Connection, uid uuid.UUID, loc *time.Location, now time.Time) (goalProgress, error) {
+	prefs, err := userPreferences(tx, uid)
+	if err != nil {
+		return goalProgress{}, err
+	}
+	if prefs.WeeklyGoalMinutes <= 0 {
+		return newGoalProgress(0, 0), nil
+	}
+
+	weekStart, weekEnd, _ := periodBounds(now, loc, "week")
+	var row struct {
+		Seconds float64 `db:"seconds"`
+	}
+	if err := tx.RawQuery(`
+		SELECT COALESCE(SUM(`+trackNetSecondsSQL+`), 0) AS seconds
+		FROM timetrac t
+		WHERE t.user_id = ? AND t.deleted_at IS NULL AND t.start_at >= ? AND t.start_at < ?
+	`, uid, weekStart.UTC(), weekEnd.UTC()).First(&row); err != nil {
+		return goalProgress{}, err
+	}
+	return newGoalProgress(prefs.WeeklyGoalMinutes, row.Seconds), nil
+}
+
+/**
+ * GoalsHistory returns goal vs. actual for the last 12 weeks
+ *
+ * GET /api/goals/history?tz=<IANA zone>
+ *
+ * Weeks are returned oldest first, the current week last. Each week uses
+ * the goal that was active then.
+ *
+ * Response: {"timezone", "weeks": [{week_start, goal_minutes,
+ * tracked_minutes, progress_percent, remaining_minutes}]}
+ *
+ * @param c - Buffalo context with authenticated user
+ * @return JSON goal history or error response
+ */
+func GoalsHistory(c buffalo.Context) error {
+	tx := mustTx(c)
+	uid, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+
+	loc, badTz, err := requestLocation(c, tx, uid)
+	if err != nil {
+		if badTz != "" {
+			return renderBadTimezone(c, badTz)
+		}
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+
+	curStart, curEnd, _ := periodBounds(time.Now(), loc, "week")
+	first := curStart.AddDate(0, 0, -7*(goalHistoryWeeks-1))
+
+	var actuals []struct {
+		Week    string  `db:"week"`
+		Seconds float64 `db:"seconds"`
+	}
+	if err := tx.RawQuery(`
+		SELECT to_char(date_trunc('week', (t.start_at AT TIME ZONE 'UTC') AT TIME ZONE ?), 'YYYY-MM-DD') AS week,
+		       COALESCE(SUM(`+trackNetSecondsSQL+`), 0) AS seconds
+		FROM timetrac t
+		WHERE t.user_id = ? AND t.deleted_at IS NULL AND t.start_at >= ? AND t.start_at < ?
+		GROUP BY 1
+	`, loc.String(), uid, first.UTC(), curEnd.UTC()).All(&actuals); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+	tracked := map[string]float64{}
+	for _, a := range actuals {
+		tracked[a.Week] = a.Seconds
+	}
+
+	var goals models.WeeklyGoals
+	if err := tx.Where("user_id = ? AND week_start <= ?", uid, curStart.Format("2006-01-02")).Order("week_start ASC").All(&goals); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+
+	type week struct {
+		WeekStart      string  `json:"week_start"`
+		TrackedMinutes float64 `json:"tracked_minutes"`
+		goalProgress
+	}
+	weeks := make([]week, 0, goalHistoryWeeks)
+	for ws := first; ws.Before(curEnd); ws = ws.AddDate(0, 0, 7) {
+		day := ws.Format("2006-01-02")
+		seconds := tracked[day]
+		weeks = append(weeks, week{
+			WeekStart:      day,
+			TrackedMinutes: math.Round(seconds/60*10) / 10,
+			goalProgress:   newGoalProgress(goals.For(ws), seconds),
+		})
+	}
+
+	return c.Render(http.StatusOK, r.JSON(map[string]any{
+		"timezone": loc.String(),
+		"weeks":    weeks,
+	}))
+}
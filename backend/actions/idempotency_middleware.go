@@ -0,0 +1,157 @@
+/**
+ * Idempotency Middleware - Safe Retries for Mutating Endpoints
+ *
+ * Mobile clients on flaky networks retry requests whose response got
+ * lost. Wrapping a handler with idempotent makes such retries harmless:
+ * the first request carrying an `Idempotency-Key` header executes and its
+ * response is stored; later requests with the same key (per user, within
+ * models.IdempotencyKeyTTL) get the stored response replayed.
+ *
+ * Concurrent duplicates are serialized by the unique (user_id, key) index:
+ * the second INSERT waits for the first request's transaction and then
+ * replays its committed response. Failed requests (status >= 400) are
+ * rolled back with their transaction, so their keys can be retried.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-20
+ */
+package actions
+
+import (
+	"bytes"
+	"net/http"
+	"time"
+
+	"backend/models"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/pop/v6"
+)
+
+// idempotencyKeyMaxLen bounds the accepted header length.
+const idempotencyKeyMaxLen = 255
+
+/**
+ * captureWriter copies everything written to the response into buf
+ */
+type captureWriter struct {
+	http.ResponseWriter
+	buf bytes.Buffer
+}
+
+func (w *captureWriter) Write(b []byte) (int, error) {
+	w.buf.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+/**
+ * idempotent wraps a handler with Idempotency-Key support
+ *
+ * Requests without the header are passed through unchanged. Replayed
+ * responses carry `Idempotent-Replayed: true`. Reusing a key for a
+ * different endpoint yields 422.
+ *
+ * @param next - Handler to protect; must run inside the request transaction
+ * @return buffalo.Handler - Wrapped handler
+ */
+func idempotent(next buffalo.Handler) buffalo.Handler {
+	return func(c buffalo.Context) error {
+		key := c.Request().Header.Get("Idempotency-Key")
+		if key == "" {
+			return next(c)
+		}
+		if len(key) > idempotencyKeyMaxLen {
+			return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "Idempotency-Key too long"}))
+		}
+		uid, ok := currentUserID(c)
+		if !ok {
+			return next(c)
+		}
+
+		tx := mustTx(c)
+		now := time.Now()
+		path := c.Request().Method + " " + c.Request().URL.Path
+
+		// An expired key behaves like a new one
+		if err := tx.RawQuery(
+			"DELETE FROM idempotency_keys WHERE user_id = ? AND key = ? AND created_at < ?",
+			uid, key, now.Add(-models.IdempotencyKeyTTL),
+		).Exec(); err != nil {
+			return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+		}
+
+		// Blocks while another transaction holds the same key
+		claimed, err := tx.RawQuery(`
+			INSERT INTO idempotency_keys (user_id, key, request_path, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT (user_id, key) DO NOTHING
+		`, uid, key, path, now, now).ExecWithCount()
+		if err != nil {
+			return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+		}
+		if claimed == 0 {
+			return replayIdempotent(c, tx, uid.String(), key, path)
+		}
+
+		res, ok := c.Response().(*buffalo.Response)
+		if !ok {
+			return next(c)
+		}
+		cw := &captureWriter{ResponseWriter: res.ResponseWriter}
+		res.ResponseWriter = cw
+		err = next(c)
+		res.ResponseWriter = cw.ResponseWriter
+		if err != nil || res.Status >= http.StatusBadRequest {
+			return err
+		}
+
+		if err := tx.RawQuery(`
+			UPDATE idempotency_keys
+			SET status = ?, content_type = ?, response_body = ?, updated_at = ?
+			WHERE user_id = ? AND key = ?
+		`, res.Status, res.Header().Get("Content-Type"), cw.buf.String(), time.Now(), uid, key).Exec(); err != nil {
+			c.Logger().Errorf("idempotency: cannot store response: %v", err)
+		}
+		return nil
+	}
+}
+
+/**
+ * replayIdempotent writes the stored response for an already used key
+ */
+func replayIdempotent(c buffalo.Context, tx *pop.Connection, uid, key, path string) error {
+	var stored models.IdempotencyKey
+	if err := tx.Where("user_id = ? AND key = ?", uid, key).First(&stored); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+	if stored.RequestPath != path {
+		return c.Render(http.StatusUnprocessableEntity, r.JSON(map[string]string{"error": "Idempotency-Key was used for a different request"}))
+	}
+	if stored.Status == 0 {
+		// Only reachable if the first request is still in flight on this connection
+		return c.Render(http.StatusConflict, r.JSON(map[string]string{"error": "request with this Idempotency-Key is in progress"}))
+	}
+
+	h := c.Response().Header()
+	if stored.ContentType != "" {
+		h.Set("Content-Type", stored.ContentType)
+	}
+	h.Set("Idempotent-Replayed", "true")
+	c.Response().WriteHeader(stored.Status)
+	_, err := c.Response().Write([]byte(stored.ResponseBody))
+	return err
+}
+
+/**
+ * PurgeExpiredIdempotencyKeys deletes stored responses older than
+ * models.IdempotencyKeyTTL
+ *
+ * @param db - Database connection
+ * @param now - Reference time
+ * @return int - Number of deleted keys
+ */
+func PurgeExpiredIdempotencyKeys(db *pop.Connection, now time.Time) (int, error) {
+	n, err := db.RawQuery("DELETE FROM idempotency_keys WHERE created_at < ?", now.Add(-models.IdempotencyKeyTTL)).ExecWithCount()
+	return n, err
+}
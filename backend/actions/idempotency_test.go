@@ -0,0 +1,39 @@
+package actions
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"backend/models"
+)
+
+func (as *ActionSuite) Test_Idempotency_StartReplays() {
+	token := as.registerToken("idempotent-start@example.com")
+
+	start := func() (int, models.TimeTrac, string) {
+		req := as.authJSON(token, "/api/tracks/start")
+		req.Headers["Idempotency-Key"] = "start-1"
+		res := req.Post(map[string]string{"project": "Web"})
+		var item models.TimeTrac
+		as.NoError(json.Unmarshal(res.Body.Bytes(), &item))
+		return res.Code, item, res.Header().Get("Idempotent-Replayed")
+	}
+
+	code1, first, replayed1 := start()
+	code2, second, replayed2 := start()
+	as.Equal(http.StatusCreated, code1)
+	as.Equal(http.StatusCreated, code2)
+	as.Equal("", replayed1)
+	as.Equal("true", replayed2)
+	as.Equal(first.ID, second.ID)
+
+	count, err := as.DB.Where("start_at IS NOT NULL").Count(&models.TimeTrac{})
+	as.NoError(err)
+	as.Equal(1, count, "the retry must not create a second entry")
+
+	// The same key on another endpoint is rejected
+	req := as.authJSON(token, "/api/tracks/stop")
+	req.Headers["Idempotency-Key"] = "start-1"
+	res := req.Post(map[string]string{})
+	as.Equal(http.StatusUnprocessableEntity, res.Code)
+}
@@ -0,0 +1,248 @@
+/**
+ * Image Uploads - Shared Handling of Uploaded Pictures
+ *
+ * Entry photos and team avatars are uploaded the same way: a multipart
+ * file field, capped at PHOTO_MAX_BYTES and accepted only if its content
+ * sniffs as an allowed image type. Avatars are also decoded and
+ * re-encoded at fixed sizes; re-encoding drops EXIF data, so the JPEG
+ * orientation tag is applied to the pixels first.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-22
+ */
+package actions
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+
+	"github.com/gobuffalo/buffalo"
+)
+
+// maxImagePixels bounds the decoded size of uploaded images, so a small
+// file cannot expand into an enormous bitmap.
+const maxImagePixels = 40_000_000
+
+/**
+ * readImageUpload reads the multipart file field of the request, enforcing
+ * the PHOTO_MAX_BYTES limit and sniffing the content against allowed
+ *
+ * @param c - Buffalo context of the upload
+ * @param field - Form field holding the file (also names it in messages)
+ * @param allowed - Accepted MIME types
+ * @return []byte - File content
+ * @return string - Detected MIME type
+ * @return int - Error status (413, 415 or 400), 0 when accepted
+ * @return string - Error message
+ */
+func readImageUpload(c buffalo.Context, field string, allowed map[string]bool) ([]byte, string, int, string) {
+	// Cap the whole body; leave room for multipart headers around the file
+	limit := photoMaxBytes()
+	req := c.Request()
+	req.Body = http.MaxBytesReader(c.Response(), req.Body, limit+64<<10)
+
+	f, err := c.File(field)
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return nil, "", http.StatusRequestEntityTooLarge, field + " too large"
+		}
+		return nil, "", http.StatusBadRequest, field + " file required"
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, limit+1))
+	if err != nil {
+		return nil, "", http.StatusBadRequest, "cannot read " + field
+	}
+	if int64(len(data)) > limit {
+		return nil, "", http.StatusRequestEntityTooLarge, field + " too large"
+	}
+
+	mime := http.DetectContentType(data)
+	if !allowed[mime] {
+		return nil, mime, http.StatusUnsupportedMediaType, "unsupported image type"
+	}
+	return data, mime, 0, ""
+}
+
+/**
+ * decodeUprightImage decodes a JPEG or PNG upload and turns it upright
+ * according to its EXIF orientation
+ */
+func decodeUprightImage(data []byte) (image.Image, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Width*cfg.Height > maxImagePixels {
+		return nil, errors.New("image dimensions too large")
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return orientImage(img, jpegOrientation(data)), nil
+}
+
+/**
+ * jpegOrientation reads the EXIF orientation tag (1-8) of a JPEG; 1 (as
+ * stored) when there is none or the data is not a JPEG
+ */
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	for i := 2; i+4 <= len(data) && data[i] == 0xFF; {
+		marker := data[i+1]
+		size := int(binary.BigEndian.Uint16(data[i+2:]))
+		if marker == 0xDA || size < 2 || i+2+size > len(data) {
+			break // Image data starts; metadata comes before it
+		}
+		seg := data[i+4 : i+2+size]
+		if marker == 0xE1 && len(seg) > 14 && string(seg[:6]) == "Exif\x00\x00" {
+			return exifOrientation(seg[6:])
+		}
+		i += 2 + size
+	}
+	return 1
+}
+
+/**
+ * exifOrientation finds the orientation tag in IFD0 of a TIFF block
+ */
+func exifOrientation(tiff []byte) int {
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	n := int(order.Uint16(tiff[ifd:]))
+	for e := ifd + 2; n > 0 && e+12 <= len(tiff); e, n = e+12, n-1 {
+		if order.Uint16(tiff[e:]) == 0x0112 {
+			if o := int(order.Uint16(tiff[e+8:])); o >= 1 && o <= 8 {
+				return o
+			}
+			return 1
+		}
+	}
+	return 1
+}
+
+/**
+ * orientImage applies an EXIF orientation (1-8) to img
+ */
+func orientImage(img image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // Mirrored
+				sx, sy = w-1-x, y
+			case 3: // Rotated 180°
+				sx, sy = w-1-x, h-1-y
+			case 4: // Flipped
+				sx, sy = x, h-1-y
+			case 5: // Transposed
+				sx, sy = y, x
+			case 6: // Needs 90° clockwise
+				sx, sy = y, h-1-x
+			case 7: // Transversed
+				sx, sy = w-1-y, h-1-x
+			case 8: // Needs 90° counter-clockwise
+				sx, sy = w-1-y, x
+			}
+			dst.Set(x, y, img.At(b.Min.X+sx, b.Min.Y+sy))
+		}
+	}
+	return dst
+}
+
+/**
+ * fitImage scales img down, keeping its aspect ratio, so that neither
+ * side exceeds size; smaller images are returned unchanged. Each target
+ * pixel averages the source pixels it covers.
+ */
+func fitImage(img image.Image, size int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= size && h <= size {
+		return img
+	}
+	dw, dh := size, h*size/w
+	if h > w {
+		dw, dh = w*size/h, size
+	}
+	if dw < 1 {
+		dw = 1
+	}
+	if dh < 1 {
+		dh = 1
+	}
+
+	src := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(src, src.Bounds(), img, b.Min, draw.Src)
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		y0, y1 := y*h/dh, (y+1)*h/dh
+		for x := 0; x < dw; x++ {
+			x0, x1 := x*w/dw, (x+1)*w/dw
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride+x0*4 : sy*src.Stride+x1*4]
+				for i := 0; i < len(row); i += 4 {
+					sum[0] += int(row[i])
+					sum[1] += int(row[i+1])
+					sum[2] += int(row[i+2])
+					sum[3] += int(row[i+3])
+				}
+			}
+			n := (x1 - x0) * (y1 - y0)
+			o := y*dst.Stride + x*4
+			for i := range sum {
+				dst.Pix[o+i] = uint8(sum[i] / n)
+			}
+		}
+	}
+	return dst
+}
+
+/**
+ * encodeImage encodes img as mime (image/jpeg or image/png)
+ */
+func encodeImage(img image.Image, mime string) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	if mime == "image/png" {
+		err = png.Encode(&buf, img)
+	} else {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85})
+	}
+	return buf.Bytes(), err
+}
@@ -0,0 +1,87 @@
+package actions
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+)
+
+// withOrientation inserts an EXIF block carrying orientation right after
+// the SOI marker of a JPEG.
+func withOrientation(data []byte, orientation byte) []byte {
+	tiff := []byte{
+		'M', 'M', 0, 42, 0, 0, 0, 8, // Big-endian header, IFD0 at 8
+		0, 1, // One entry
+		0x01, 0x12, 0, 3, 0, 0, 0, 1, 0, orientation, 0, 0, // Orientation, SHORT
+		0, 0, 0, 0, // No next IFD
+	}
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	size := len(payload) + 2
+	app1 := append([]byte{0xFF, 0xE1, byte(size >> 8), byte(size)}, payload...)
+	return append(append(append([]byte{}, data[:2]...), app1...), data[2:]...)
+}
+
+func Test_DecodeUprightImage(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	for x := 0; x < 20; x++ {
+		for y := 0; y < 20; y++ {
+			src.Set(x, y, color.White) // Left half white, right half black
+		}
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, src, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := decodeUprightImage(buf.Bytes())
+	if err != nil || img.Bounds().Dx() != 40 || img.Bounds().Dy() != 20 {
+		t.Fatalf("plain: %v, %v", img.Bounds(), err)
+	}
+
+	// Orientation 6 is displayed turned clockwise: the white left half ends up on top
+	img, err = decodeUprightImage(withOrientation(buf.Bytes(), 6))
+	if err != nil || img.Bounds().Dx() != 20 || img.Bounds().Dy() != 40 {
+		t.Fatalf("rotated: %v, %v", img.Bounds(), err)
+	}
+	top, _, _, _ := img.At(10, 5).RGBA()
+	bottom, _, _, _ := img.At(10, 35).RGBA()
+	if top < 0xc000 || bottom > 0x4000 {
+		t.Errorf("rotated the wrong way: top %x, bottom %x", top, bottom)
+	}
+
+	if _, err := decodeUprightImage([]byte("\xff\xd8\xff\xe0 truncated")); err == nil {
+		t.Error("broken JPEG decoded")
+	}
+}
+
+func Test_FitImage(t *testing.T) {
+	for _, tc := range []struct {
+		w, h, size, wantW, wantH int
+	}{
+		{1024, 768, 512, 512, 384},
+		{300, 1200, 512, 128, 512},
+		{200, 100, 512, 200, 100},
+	} {
+		img := fitImage(image.NewRGBA(image.Rect(0, 0, tc.w, tc.h)), tc.size)
+		if b := img.Bounds(); b.Dx() != tc.wantW || b.Dy() != tc.wantH {
+			t.Errorf("%dx%d in %d: got %v", tc.w, tc.h, tc.size, b)
+		}
+	}
+
+	gray := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for i := range gray.Pix {
+		gray.Pix[i] = 0xff
+		if i/4%2 == 0 {
+			gray.Pix[i] = 0 // Alternate black and white pixels
+		}
+		if i%4 == 3 {
+			gray.Pix[i] = 0xff
+		}
+	}
+	r, _, _, _ := fitImage(gray, 1).At(0, 0).RGBA()
+	if r>>8 < 0x70 || r>>8 > 0x90 {
+		t.Errorf("average = %x", r>>8)
+	}
+}
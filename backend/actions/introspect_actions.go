@@ -0,0 +1,140 @@
+/**
+ * Introspect Actions - Token Validation for Other Services
+ *
+ * POST /api/auth/introspect lets sibling services (e.g. reporting)
+ * validate access tokens without holding the signing keys, following
+ * RFC 7662. Callers authenticate with either:
+ * - INTROSPECTION_TOKEN as a bearer token, when configured
+ * - an API key with the introspect scope, owned by an administrator
+ *
+ * A token is active when its signature, iss/aud and expiry are valid,
+ * its auth_tokens row is neither revoked nor expired, and its account is
+ * usable. Inactive tokens yield {"active": false} and nothing else.
+ *
+ * Each caller may make 600 requests per minute; every call is logged
+ * with the caller and the outcome.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-21
+ */
+package actions
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"time"
+
+	"backend/models"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/envy"
+)
+
+// introspectLimit throttles each introspection caller.
+var introspectLimit = newRateLimiter(600, time.Minute)
+
+/**
+ * introspectionCaller authenticates the calling service
+ *
+ * @return string - Caller name for logs and rate limits, "" if rejected
+ */
+func introspectionCaller(c buffalo.Context) string {
+	raw, ok := strings.CutPrefix(c.Request().Header.Get("Authorization"), "Bearer ")
+	if !ok || raw == "" {
+		return ""
+	}
+	if isAPIKey(raw) {
+		u, key, msg := userFromAPIKey(mustTx(c), raw, time.Now())
+		if msg != "" || !key.Allows(models.APIKeyScopeIntrospect) || !u.IsAdmin {
+			return ""
+		}
+		return "api_key:" + key.ID.String()
+	}
+	if want := envy.Get("INTROSPECTION_TOKEN", ""); want != "" &&
+		subtle.ConstantTimeCompare([]byte(raw), []byte(want)) == 1 {
+		return "static"
+	}
+	return ""
+}
+
+/**
+ * Introspect reports whether an access token is active
+ *
+ * POST /api/auth/introspect
+ *
+ * Payload (form or JSON): token
+ *
+ * Response for active tokens:
+ * {"active": true, "token_type": "access_token", "sub", "jti", "exp",
+ *  "iat", "iss", "aud"}; iss and aud only when configured.
+ *
+ * Responses:
+ * - 200 with the introspection result, also for inactive tokens
+ * - 401 if the caller is not authenticated
+ * - 429 if the caller exceeds its rate limit
+ *
+ * @param c - Buffalo context
+ * @return JSON introspection result or error response
+ */
+func Introspect(c buffalo.Context) error {
+	caller := introspectionCaller(c)
+	if caller == "" {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+	if !introspectLimit.allow(caller, time.Now()) {
+		c.Logger().Warnf("introspect: caller=%s rate limited", caller)
+		return c.Render(http.StatusTooManyRequests, r.JSON(map[string]string{"error": "too many requests"}))
+	}
+
+	// Buffalo parsed a form body before jsonContentType relabeled the
+	// request as JSON, so form callers (RFC 7662) are read from PostForm
+	token := c.Request().PostForm.Get("token")
+	if token == "" {
+		var p struct {
+			Token string `json:"token"`
+		}
+		if err := c.Bind(&p); err != nil {
+			return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "bad payload"}))
+		}
+		token = p.Token
+	}
+
+	inactive := map[string]bool{"active": false}
+	raw := strings.TrimSpace(token)
+	if raw == "" || isAPIKey(raw) {
+		c.Logger().Infof("introspect: caller=%s active=false", caller)
+		return c.Render(http.StatusOK, r.JSON(inactive))
+	}
+	auth, msg := authenticateToken(mustTx(c), raw)
+	if msg == "" && (auth.user.DisabledAt.Valid || auth.user.DeactivatedAt.Valid) {
+		msg = "account not usable"
+	}
+	if msg != "" {
+		c.Logger().Infof("introspect: caller=%s active=false reason=%q", caller, msg)
+		return c.Render(http.StatusOK, r.JSON(inactive))
+	}
+
+	claims := auth.claims
+	res := map[string]any{
+		"active":     true,
+		"token_type": "access_token",
+		"sub":        auth.user.ID,
+		"jti":        claims.ID,
+	}
+	if claims.ExpiresAt != nil {
+		res["exp"] = claims.ExpiresAt.Unix()
+	}
+	if claims.IssuedAt != nil {
+		res["iat"] = claims.IssuedAt.Unix()
+	}
+	if claims.Issuer != "" {
+		res["iss"] = claims.Issuer
+	}
+	if len(claims.Audience) > 0 {
+		res["aud"] = claims.Audience
+	}
+	c.Logger().Infof("introspect: caller=%s active=true jti=%s", caller, claims.ID)
+	return c.Render(http.StatusOK, r.JSON(res))
+}
@@ -1,17 +1,29 @@
 package actions
 
 import (
+	"errors"
+	"fmt"
 	"os"
+	"strconv"
 	"time"
 
+	"github.com/gofrs/uuid"
 	"github.com/golang-jwt/jwt/v5"
 )
 
 type JWTClaims struct {
 	UserID string `json:"uid"`
+	// Family is the refresh token family the access token was issued with
+	Family string `json:"fid,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// Token lifetimes: access tokens are short-lived, refresh tokens renew them.
+const (
+	defaultAccessTTL  = 30 * time.Minute
+	defaultRefreshTTL = 30 * 24 * time.Hour
+)
+
 func jwtSecret() []byte {
 	sec := os.Getenv("JWT_SECRET")
 	if sec == "" {
@@ -20,36 +32,175 @@ func jwtSecret() []byte {
 	return []byte(sec)
 }
 
-func jwtExpiry() time.Duration {
-	if h := os.Getenv("JWT_EXPIRES_HOURS"); h != "" {
-		if d, err := time.ParseDuration(h + "h"); err == nil {
-			return d
-		}
+// parseTTL reads a whole number of units from raw and checks it lies within
+// [min, max]; an empty value yields def.
+func parseTTL(raw string, unit, def, min, max time.Duration) (time.Duration, error) {
+	if raw == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil {
+		return def, fmt.Errorf("%q is not a whole number", raw)
+	}
+	d := time.Duration(n) * unit
+	if d < min || d > max {
+		return def, fmt.Errorf("%s is outside %s–%s", d, min, max)
+	}
+	return d, nil
+}
+
+// accessTokenTTL reads JWT_ACCESS_TTL_MINUTES (15–60, default 30).
+func accessTokenTTL() (time.Duration, error) {
+	d, err := parseTTL(os.Getenv("JWT_ACCESS_TTL_MINUTES"), time.Minute, defaultAccessTTL, 15*time.Minute, time.Hour)
+	if err != nil {
+		err = fmt.Errorf("JWT_ACCESS_TTL_MINUTES: %w", err)
+	}
+	return d, err
+}
+
+// refreshTokenTTL reads REFRESH_TOKEN_TTL_DAYS (1–365, default 30).
+func refreshTokenTTL() (time.Duration, error) {
+	d, err := parseTTL(os.Getenv("REFRESH_TOKEN_TTL_DAYS"), 24*time.Hour, defaultRefreshTTL, 24*time.Hour, 365*24*time.Hour)
+	if err != nil {
+		err = fmt.Errorf("REFRESH_TOKEN_TTL_DAYS: %w", err)
 	}
-	return 24 * time.Hour
+	return d, err
 }
 
-func GenerateJWT(userID string) (token string, jti string, exp time.Time, err error) {
-	jti = time.Now().UTC().Format("20060102150405.000000000") // JTI بسيط وفريد زمنياً
+// slidingWindow reads SESSION_SLIDING_WINDOW_MINUTES (0–15, default 0,
+// which disables sliding sessions; see sliding_session.go).
+func slidingWindow() (time.Duration, error) {
+	d, err := parseTTL(os.Getenv("SESSION_SLIDING_WINDOW_MINUTES"), time.Minute, 0, 0, 15*time.Minute)
+	if err != nil {
+		err = fmt.Errorf("SESSION_SLIDING_WINDOW_MINUTES: %w", err)
+	}
+	return d, err
+}
+
+// checkTokenConfig reports invalid token lifetime settings, which fall
+// back to their defaults.
+func checkTokenConfig() error {
+	_, accessErr := accessTokenTTL()
+	_, refreshErr := refreshTokenTTL()
+	_, slidingErr := slidingWindow()
+	return errors.Join(accessErr, refreshErr, slidingErr)
+}
+
+func jwtExpiry() time.Duration {
+	d, _ := accessTokenTTL()
+	return d
+}
+
+func GenerateJWT(userID, family string) (token string, jti string, exp time.Time, err error) {
+	id, err := uuid.NewV4() // JTI عشوائي لا يكشف وقت الإصدار
+	if err != nil {
+		return
+	}
+	jti = id.String()
 	exp = time.Now().Add(jwtExpiry())
 
 	claims := JWTClaims{
 		UserID: userID,
+		Family: family,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ID:        jti,
 			ExpiresAt: jwt.NewNumericDate(exp),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
-	t := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	token, err = t.SignedString(jwtSecret())
+	token, err = signer.Sign(claims)
 	return
 }
 
+// tokenSigner turns claims into a signed token; tests swap it for a failing one.
+type tokenSigner interface {
+	Sign(claims JWTClaims) (string, error)
+}
+
+// keySetSigner signs with the configured key set.
+type keySetSigner struct{}
+
+func (keySetSigner) Sign(claims JWTClaims) (string, error) {
+	ks, err := currentJWTKeys()
+	if err != nil {
+		return "", err
+	}
+	return signJWT(ks, claims)
+}
+
+var signer tokenSigner = keySetSigner{}
+
+// signJWT signs with the RS256 key when configured, else with HS256, and
+// sets the configured issuer and audience.
+func signJWT(ks *jwtKeySet, claims JWTClaims) (string, error) {
+	if ks.issuer != "" {
+		claims.Issuer = ks.issuer
+	}
+	if ks.audience != "" {
+		claims.Audience = jwt.ClaimStrings{ks.audience}
+	}
+	if ks.signing == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(jwtSecret())
+	}
+	t := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	t.Header["kid"] = ks.signingKID
+	return t.SignedString(ks.signing)
+}
+
 func ParseJWT(tokenStr string) (*JWTClaims, error) {
+	ks, err := currentJWTKeys()
+	if err != nil {
+		return nil, err
+	}
+	return parseJWTWith(ks, tokenStr, time.Now())
+}
+
+// parseJWTWith verifies RS256 tokens with the key named by their kid and
+// HS256 tokens with JWT_SECRET while the key set accepts them, and checks
+// the configured issuer and audience.
+func parseJWTWith(ks *jwtKeySet, tokenStr string, now time.Time) (*JWTClaims, error) {
+	var opts []jwt.ParserOption
+	if ks.issuer != "" {
+		opts = append(opts, jwt.WithIssuer(ks.issuer))
+	}
+	if ks.audience != "" {
+		opts = append(opts, jwt.WithAudience(ks.audience))
+	}
+	claims, err := parseJWTOpts(ks, tokenStr, now, opts...)
+	issAudErr := errors.Is(err, jwt.ErrTokenInvalidIssuer) || errors.Is(err, jwt.ErrTokenInvalidAudience) ||
+		errors.Is(err, jwt.ErrTokenRequiredClaimMissing)
+	if issAudErr && now.Before(ks.lenientUntil) {
+		// Migration window: accept tokens valid apart from iss/aud
+		if claims, lenientErr := parseJWTOpts(ks, tokenStr, now); lenientErr == nil {
+			if ks.logf != nil {
+				ks.logf("jwt: accepting token %s despite %v", claims.ID, err)
+			}
+			return claims, nil
+		}
+	}
+	return claims, err
+}
+
+// parseJWTOpts verifies the signature and the time claims, plus whatever
+// opts require.
+func parseJWTOpts(ks *jwtKeySet, tokenStr string, now time.Time, opts ...jwt.ParserOption) (*JWTClaims, error) {
+	opts = append(opts, jwt.WithValidMethods([]string{"RS256", "HS256"}), jwt.WithTimeFunc(func() time.Time { return now }))
 	token, err := jwt.ParseWithClaims(tokenStr, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return jwtSecret(), nil
-	})
+		switch token.Method {
+		case jwt.SigningMethodRS256:
+			kid, _ := token.Header["kid"].(string)
+			if key, ok := ks.verify[kid]; ok {
+				return key, nil
+			}
+			return nil, fmt.Errorf("unknown kid %q", kid)
+		case jwt.SigningMethodHS256:
+			if ks.acceptsHS256(now) {
+				return jwtSecret(), nil
+			}
+			return nil, errors.New("HS256 tokens are no longer accepted")
+		}
+		return nil, jwt.ErrTokenSignatureInvalid
+	}, opts...)
 	if err != nil {
 		return nil, err
 	}
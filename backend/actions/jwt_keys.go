@@ -0,0 +1,255 @@
+/**
+ * JWT Keys - RS256 Signing Keys and the JWKS Endpoint
+ *
+ * When an RSA private key is configured, access tokens are signed with
+ * RS256 and carry a `kid` header naming the key. Other services verify
+ * them with the public keys published at /.well-known/jwks.json and
+ * cannot mint tokens themselves.
+ *
+ * Key rotation: configure the new private key and keep the old public key
+ * in JWT_VERIFY_KEYS until tokens signed with it have expired.
+ *
+ * Configuration:
+ * - JWT_PRIVATE_KEY_PATH or JWT_PRIVATE_KEY: PEM RSA private key (PKCS#1
+ *   or PKCS#8); without one tokens are signed with HS256 and JWT_SECRET
+ * - JWT_VERIFY_KEYS_PATH or JWT_VERIFY_KEYS: PEM public keys that are
+ *   still accepted, e.g. the previous signing key
+ * - JWT_HS256_ACCEPT_UNTIL: RFC 3339 time until which HS256 tokens are
+ *   still accepted once RS256 is enabled (default: not accepted)
+ * - JWT_ISSUER, JWT_AUDIENCE: iss and aud set on new tokens and required
+ *   of presented ones, so tokens of another deployment are rejected even
+ *   if it shares the keys (default: neither set nor checked)
+ * - JWT_ISS_AUD_LENIENT_UNTIL: RFC 3339 time until which tokens with a
+ *   missing or wrong iss or aud are logged and accepted, so sessions
+ *   issued before JWT_ISSUER/JWT_AUDIENCE were set survive the deploy
+ *
+ * A key's kid is its RFC 7638 JWK thumbprint.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-21
+ */
+package actions
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gobuffalo/buffalo"
+)
+
+/**
+ * jwtKeySet holds the keys used to sign and verify access tokens
+ */
+type jwtKeySet struct {
+	signing    *rsa.PrivateKey           // nil: sign with HS256
+	signingKID string                    // kid of the signing key
+	verify     map[string]*rsa.PublicKey // accepted RS256 keys by kid
+	hsUntil    time.Time                 // HS256 accepted before this time when RS256 is enabled
+
+	issuer       string               // iss of new tokens, required when set
+	audience     string               // aud of new tokens, required when set
+	lenientUntil time.Time            // iss/aud mismatches only logged before this time
+	logf         func(string, ...any) // reports lenient acceptances; nil: silent
+}
+
+/**
+ * acceptsHS256 reports whether HS256 tokens are valid at now
+ */
+func (ks *jwtKeySet) acceptsHS256(now time.Time) bool {
+	return ks.signing == nil || now.Before(ks.hsUntil)
+}
+
+var (
+	jwtKeysOnce sync.Once
+	jwtKeys     *jwtKeySet
+	jwtKeysErr  error
+)
+
+/**
+ * currentJWTKeys returns the configured key set, loading it on first use
+ */
+func currentJWTKeys() (*jwtKeySet, error) {
+	jwtKeysOnce.Do(func() {
+		jwtKeys, jwtKeysErr = loadJWTKeys(os.Getenv)
+		if jwtKeysErr == nil && app != nil {
+			jwtKeys.logf = app.Logger.Warnf
+		}
+	})
+	return jwtKeys, jwtKeysErr
+}
+
+/**
+ * envPEM reads PEM data from the file named by <name>_PATH or inline
+ * from <name>
+ */
+func envPEM(getenv func(string) string, name string) ([]byte, error) {
+	if path := getenv(name + "_PATH"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s_PATH: %w", name, err)
+		}
+		return data, nil
+	}
+	return []byte(getenv(name)), nil
+}
+
+/**
+ * loadJWTKeys builds the key set from the environment
+ *
+ * @param getenv - Environment lookup (os.Getenv outside tests)
+ * @return *jwtKeySet - Keys; HS256 only when no private key is configured
+ */
+func loadJWTKeys(getenv func(string) string) (*jwtKeySet, error) {
+	ks := &jwtKeySet{verify: map[string]*rsa.PublicKey{}}
+
+	data, err := envPEM(getenv, "JWT_PRIVATE_KEY")
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > 0 {
+		if ks.signing, err = parseRSAPrivateKey(data); err != nil {
+			return nil, fmt.Errorf("JWT_PRIVATE_KEY: %w", err)
+		}
+		ks.signingKID = jwkThumbprint(&ks.signing.PublicKey)
+		ks.verify[ks.signingKID] = &ks.signing.PublicKey
+	}
+
+	data, err = envPEM(getenv, "JWT_VERIFY_KEYS")
+	if err != nil {
+		return nil, err
+	}
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		pub, err := parseRSAPublicKey(block)
+		if err != nil {
+			return nil, fmt.Errorf("JWT_VERIFY_KEYS: %w", err)
+		}
+		ks.verify[jwkThumbprint(pub)] = pub
+	}
+
+	if raw := getenv("JWT_HS256_ACCEPT_UNTIL"); raw != "" {
+		if ks.hsUntil, err = time.Parse(time.RFC3339, raw); err != nil {
+			return nil, fmt.Errorf("JWT_HS256_ACCEPT_UNTIL: %w", err)
+		}
+	}
+
+	ks.issuer = getenv("JWT_ISSUER")
+	ks.audience = getenv("JWT_AUDIENCE")
+	if raw := getenv("JWT_ISS_AUD_LENIENT_UNTIL"); raw != "" {
+		if ks.lenientUntil, err = time.Parse(time.RFC3339, raw); err != nil {
+			return nil, fmt.Errorf("JWT_ISS_AUD_LENIENT_UNTIL: %w", err)
+		}
+	}
+	return ks, nil
+}
+
+/**
+ * parseRSAPrivateKey decodes a PKCS#1 or PKCS#8 PEM RSA private key
+ */
+func parseRSAPrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("not an RSA key")
+	}
+	return rsaKey, nil
+}
+
+/**
+ * parseRSAPublicKey decodes a PKIX or PKCS#1 public key block
+ */
+func parseRSAPublicKey(block *pem.Block) (*rsa.PublicKey, error) {
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("not an RSA key")
+	}
+	return rsaKey, nil
+}
+
+/**
+ * jwkThumbprint returns the RFC 7638 thumbprint of an RSA public key
+ */
+func jwkThumbprint(pub *rsa.PublicKey) string {
+	jwk := rsaJWK(pub, "")
+	canonical := fmt.Sprintf(`{"e":"%s","kty":"RSA","n":"%s"}`, jwk.E, jwk.N)
+	sum := sha256.Sum256([]byte(canonical))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+/**
+ * jwk is the JSON Web Key form of an RSA public key
+ */
+type jwk struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+func rsaJWK(pub *rsa.PublicKey, kid string) jwk {
+	return jwk{
+		Kty: "RSA",
+		Use: "sig",
+		Alg: "RS256",
+		Kid: kid,
+		N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+	}
+}
+
+/**
+ * JWKS publishes the public keys that verify access tokens
+ *
+ * GET /.well-known/jwks.json
+ *
+ * Lists the signing key and the keys kept for rotation. The set is empty
+ * while tokens are signed with HS256.
+ *
+ * @param c - Buffalo context
+ * @return JSON Web Key Set
+ */
+func JWKS(c buffalo.Context) error {
+	ks, err := currentJWTKeys()
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "keys unavailable"}))
+	}
+
+	keys := make([]jwk, 0, len(ks.verify))
+	for kid, pub := range ks.verify {
+		keys = append(keys, rsaJWK(pub, kid))
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Kid < keys[j].Kid })
+
+	c.Response().Header().Set("Cache-Control", "public, max-age=300")
+	return c.Render(http.StatusOK, r.JSON(map[string]any{"keys": keys}))
+}
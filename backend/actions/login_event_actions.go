@@ -0,0 +1,130 @@
+/**
+ * Login Event Actions - Sign-In History
+ *
+ * Password, Apple and magic-link sign-ins record every attempt in
+ * login_events, and successful ones update users.last_login_at. Users see
+ * their recent attempts at GET /api/me/security/logins.
+ *
+ * Failures end in a 401, which rolls back the request transaction, so
+ * they are written through models.DB directly. Successes are written in
+ * the request transaction together with the issued tokens.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-21
+ */
+package actions
+
+import (
+	"net/http"
+	"time"
+
+	"backend/models"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/nulls"
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gofrs/uuid"
+)
+
+// loginEventsShown is the number of attempts listed to the user.
+const loginEventsShown = 50
+
+/**
+ * newLoginEvent returns an event for the requesting client
+ */
+func newLoginEvent(c buffalo.Context, method, outcome string) models.LoginEvent {
+	client := sessionClientFrom(c)
+	return models.LoginEvent{
+		Method:    method,
+		Outcome:   outcome,
+		IP:        client.IP,
+		UserAgent: client.UserAgent,
+	}
+}
+
+/**
+ * recordLoginSuccess stores a successful sign-in and updates the user's
+ * last_login_at
+ *
+ * @param c - Buffalo context of the sign-in request
+ * @param tx - Request transaction
+ * @param u - User signed in, updated in place
+ * @param method - Sign-in method (models.LoginMethod...)
+ */
+func recordLoginSuccess(c buffalo.Context, tx *pop.Connection, u *models.User, method string) error {
+	now := time.Now().UTC()
+	if err := tx.RawQuery("UPDATE users SET last_login_at = ? WHERE id = ?", now, u.ID).Exec(); err != nil {
+		return err
+	}
+	u.LastLoginAt = nulls.NewTime(now)
+
+	ev := newLoginEvent(c, method, models.LoginSucceeded)
+	ev.UserID = nulls.NewUUID(u.ID)
+	ev.Email = nulls.NewString(u.Email)
+	return tx.Create(&ev)
+}
+
+/**
+ * recordLoginFailure stores a failed sign-in outside the request
+ * transaction. Errors are logged, not returned, so the client still gets
+ * its 401.
+ *
+ * @param c - Buffalo context of the sign-in request
+ * @param method - Sign-in method (models.LoginMethod...)
+ * @param outcome - Failure reason (models.Login...)
+ * @param uid - Account the attempt targeted, uuid.Nil if none
+ * @param email - Address given by the client, "" if none
+ */
+func recordLoginFailure(c buffalo.Context, method, outcome string, uid uuid.UUID, email string) {
+	ev := newLoginEvent(c, method, outcome)
+	if uid != uuid.Nil {
+		ev.UserID = nulls.NewUUID(uid)
+	}
+	if email != "" {
+		ev.Email = nulls.NewString(email)
+	}
+	if err := models.DB.Create(&ev); err != nil {
+		c.Logger().Errorf("login event: %v", err)
+	}
+}
+
+/**
+ * MeSecurityLogins lists the user's recent sign-in attempts
+ *
+ * GET /api/me/security/logins
+ *
+ * Returns the latest 50 attempts, newest first, with method, outcome,
+ * IP and User-Agent. Attempts are kept for 90 days.
+ *
+ * @param c - Buffalo context with authenticated user
+ * @return JSON array of LoginEvent or error response
+ */
+func MeSecurityLogins(c buffalo.Context) error {
+	uid, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+
+	list := []models.LoginEvent{}
+	err := mustTx(c).Where("user_id = ?", uid).
+		Order("created_at DESC").
+		Limit(loginEventsShown).
+		All(&list)
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+	return c.Render(http.StatusOK, r.JSON(list))
+}
+
+/**
+ * PurgeLoginEvents deletes sign-in attempts older than
+ * models.LoginEventRetention
+ *
+ * @param db - Database connection
+ * @param now - Reference time
+ * @return int - Number of deleted events
+ */
+func PurgeLoginEvents(db *pop.Connection, now time.Time) (int, error) {
+	return db.RawQuery("DELETE FROM login_events WHERE created_at < ?", now.Add(-models.LoginEventRetention).UTC()).ExecWithCount()
+}
@@ -0,0 +1,232 @@
+/**
+ * Magic Link Actions - Passwordless Sign-In by Email
+ *
+ * POST /api/auth/magic-link emails the user a sign-in link carrying a
+ * single-use token valid for models.MagicLinkTTL. The link opens the app,
+ * which exchanges the token at /api/auth/magic-link/consume for the same
+ * tokens a password login returns.
+ *
+ * The request endpoint answers identically whether or not the address
+ * belongs to an account, and it is rate limited per address and per
+ * client IP. Requesting a new link invalidates the unused earlier ones.
+ *
+ * Configuration:
+ * - MAGIC_LINK_URL: page the emailed link points to; the token is added
+ *   as the `token` query parameter (default
+ *   http://localhost:8100/auth/magic-link)
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-21
+ */
+package actions
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"backend/mailer"
+	"backend/models"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/envy"
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gofrs/uuid"
+)
+
+var (
+	// magicLinkEmailLimit bounds the links sent to one address.
+	magicLinkEmailLimit = newRateLimiter(3, 15*time.Minute)
+	// magicLinkIPLimit bounds the link requests from one client.
+	magicLinkIPLimit = newRateLimiter(10, time.Hour)
+)
+
+// magicLinkSent is the response to every accepted link request.
+var magicLinkSent = map[string]string{"status": "if the address belongs to an account, a sign-in link has been sent"}
+
+/**
+ * magicLinkURL returns the link emailed for a token
+ */
+func magicLinkURL(token string) string {
+	u, err := url.Parse(envy.Get("MAGIC_LINK_URL", "http://localhost:8100/auth/magic-link"))
+	if err != nil {
+		u = &url.URL{Path: "/auth/magic-link"}
+	}
+	q := u.Query()
+	q.Set("token", token)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+/**
+ * MagicLinkRequest emails a sign-in link
+ *
+ * POST /api/auth/magic-link
+ *
+ * Payload:
+ * - email: Account address
+ *
+ * Responses:
+ * - 202 whether or not an account exists; over the per-address limit the
+ *   response is the same but no email is sent
+ * - 422 for a missing email
+ * - 429 when the client IP exceeded its limit
+ *
+ * @param c - Buffalo context with the email
+ * @return JSON status or error response
+ */
+func MagicLinkRequest(c buffalo.Context) error {
+	var p struct {
+		Email string `json:"email"`
+	}
+	if err := c.Bind(&p); err != nil {
+		return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "bad payload"}))
+	}
+	email := strings.TrimSpace(strings.ToLower(p.Email))
+	if email == "" {
+		return c.Render(http.StatusUnprocessableEntity, r.JSON(map[string]string{"error": "email required"}))
+	}
+
+	now := time.Now()
+	client := sessionClientFrom(c)
+	if !magicLinkIPLimit.allow(client.IP.String, now) {
+		return c.Render(http.StatusTooManyRequests, r.JSON(map[string]string{"error": "too many requests"}))
+	}
+	if !magicLinkEmailLimit.allow(email, now) {
+		return c.Render(http.StatusAccepted, r.JSON(magicLinkSent))
+	}
+
+	tx := mustTx(c)
+	var u models.User
+	if err := tx.Where("email = ?", email).First(&u); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			c.Logger().Errorf("magic link: find user: %v", err)
+		}
+		return c.Render(http.StatusAccepted, r.JSON(magicLinkSent))
+	}
+
+	raw, err := newRefreshTokenValue()
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot create link"}))
+	}
+	// Only the newest link works
+	if err := tx.RawQuery(`DELETE FROM magic_link_tokens WHERE user_id = ? AND used_at IS NULL`, u.ID).Exec(); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot create link"}))
+	}
+	ml := models.MagicLinkToken{
+		UserID:    u.ID,
+		TokenHash: models.HashRefreshToken(raw),
+		ExpiresAt: now.Add(models.MagicLinkTTL).UTC(),
+	}
+	if err := tx.Create(&ml); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot create link"}))
+	}
+
+	link := magicLinkURL(raw)
+	afterCommit(c, func() {
+		sendMail(mailer.Message{
+			To:      u.Email,
+			Subject: "Your TimeTrac sign-in link",
+			Text: fmt.Sprintf("Open this link to sign in to TimeTrac:\n\n%s\n\n"+
+				"The link works once and expires in %d minutes. If you did not request it, you can ignore this email.\n",
+				link, int(models.MagicLinkTTL.Minutes())),
+		})
+	})
+	return c.Render(http.StatusAccepted, r.JSON(magicLinkSent))
+}
+
+/**
+ * MagicLinkConsume signs in with an emailed link token
+ *
+ * GET /api/auth/magic-link/consume?token=...
+ * POST /api/auth/magic-link/consume
+ *
+ * Payload (POST):
+ * - token: Token from the link
+ *
+ * The token is marked used before the session is issued, so a link signs
+ * in at most once even when opened twice concurrently.
+ * Opening the link proves access to the mailbox, so team invitations
+ * sent to the address surface as pending memberships (see
+ * surfaceEmailInvitations).
+ *
+ * Responses:
+ * - 200 with user and tokens as Login
+ * - 401 for an unknown, used or expired token
+ *
+ * @param c - Buffalo context with the token
+ * @return JSON user data with tokens or error response
+ */
+func MagicLinkConsume(c buffalo.Context) error {
+	raw := c.Param("token")
+	if c.Request().Method == http.MethodPost {
+		var p struct {
+			Token string `json:"token"`
+		}
+		if err := c.Bind(&p); err != nil {
+			return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "bad payload"}))
+		}
+		raw = p.Token
+	}
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return c.Render(http.StatusUnprocessableEntity, r.JSON(map[string]string{"error": "token required"}))
+	}
+
+	tx := mustTx(c)
+	now := time.Now().UTC()
+	var claimed struct {
+		UserID uuid.UUID `db:"user_id"`
+	}
+	err := tx.RawQuery(`
+		UPDATE magic_link_tokens SET used_at = ?, updated_at = ?
+		WHERE token_hash = ? AND used_at IS NULL AND expires_at > ?
+		RETURNING user_id
+	`, now, now, models.HashRefreshToken(raw), now).First(&claimed)
+	if errors.Is(err, sql.ErrNoRows) {
+		recordLoginFailure(c, models.LoginMethodMagicLink, models.LoginInvalidToken, uuid.Nil, "")
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "invalid or expired link"}))
+	}
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+
+	var u models.User
+	if err := tx.Find(&u, claimed.UserID); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+	if ok, err := admitSignIn(tx, &u); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	} else if !ok {
+		return refuseDeactivatedSignIn(c, models.LoginMethodMagicLink, u)
+	}
+	// The link proves the mailbox, so invitations sent to it surface
+	if err := surfaceEmailInvitations(tx, u, now); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+	pair, err := newLoginTokens(c, tx, u.ID)
+	if err != nil {
+		c.Logger().Errorf("magic link: issue tokens: %v", err)
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot persist token"}))
+	}
+	if err := recordLoginSuccess(c, tx, &u, models.LoginMethodMagicLink); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+	return c.Render(http.StatusOK, r.JSON(authResponse(u, pair)))
+}
+
+/**
+ * PurgeMagicLinkTokens deletes sign-in links past their expiry
+ *
+ * @param db - Database connection
+ * @param now - Reference time
+ * @return int - Number of deleted tokens
+ */
+func PurgeMagicLinkTokens(db *pop.Connection, now time.Time) (int, error) {
+	return db.RawQuery("DELETE FROM magic_link_tokens WHERE expires_at < ?", now.UTC()).ExecWithCount()
+}
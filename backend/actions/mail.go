@@ -0,0 +1,67 @@
+/**
+ * Mail - Outgoing Email from Handlers
+ *
+ * Handlers queue email with sendMail, normally from an afterCommit hook
+ * so nothing is sent for a rolled-back request. Delivery runs in the
+ * background: the response does not wait for the SMTP server, and its
+ * timing does not reveal whether a message was sent. A failed delivery is
+ * logged and tried once more after mailRetryDelay.
+ *
+ * Without SMTP_HOST messages are written to the log (see package mailer).
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-21
+ */
+package actions
+
+import (
+	"os"
+	"time"
+
+	"backend/mailer"
+)
+
+// mailSender delivers email; replaced by tests.
+var mailSender mailer.Sender
+
+// mailRetryDelay is the wait before the one retry of a failed delivery.
+var mailRetryDelay = 30 * time.Second
+
+/**
+ * configureMailer creates the sender from the environment
+ */
+func configureMailer() error {
+	s, err := mailer.FromEnv(os.Getenv)
+	if err != nil {
+		return err
+	}
+	if s == nil {
+		mailSender = mailer.Log{Logf: app.Logger.Infof}
+		return nil
+	}
+	mailSender = s
+	return nil
+}
+
+/**
+ * sendMail delivers msg in the background, logging failures and retrying
+ * once
+ */
+func sendMail(msg mailer.Message) {
+	sender := mailSender
+	if sender == nil {
+		return
+	}
+	go func() {
+		err := sender.Send(msg)
+		if err == nil {
+			return
+		}
+		app.Logger.Warnf("mail to %s: %v; retrying in %s", msg.To, err, mailRetryDelay)
+		time.Sleep(mailRetryDelay)
+		if err := sender.Send(msg); err != nil {
+			app.Logger.Errorf("mail to %s: %v", msg.To, err)
+		}
+	}()
+}
@@ -0,0 +1,38 @@
+package actions
+
+import (
+	"errors"
+	"time"
+
+	"backend/mailer"
+)
+
+// flakySender fails its first delivery and records the rest.
+type flakySender struct {
+	failed bool
+	sent   chan mailer.Message
+}
+
+func (s *flakySender) Send(msg mailer.Message) error {
+	if !s.failed {
+		s.failed = true
+		return errors.New("connection refused")
+	}
+	s.sent <- msg
+	return nil
+}
+
+func (as *ActionSuite) Test_SendMail_Retry() {
+	sender := &flakySender{sent: make(chan mailer.Message, 1)}
+	prev, prevDelay := mailSender, mailRetryDelay
+	mailSender, mailRetryDelay = sender, 10*time.Millisecond
+	defer func() { mailSender, mailRetryDelay = prev, prevDelay }()
+
+	sendMail(mailer.Message{To: "retry@example.com", Subject: "Hello", Text: "Hi"})
+	select {
+	case msg := <-sender.sent:
+		as.Equal("retry@example.com", msg.To)
+	case <-time.After(2 * time.Second):
+		as.Fail("not retried")
+	}
+}
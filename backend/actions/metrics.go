@@ -0,0 +1,97 @@
+/**
+ * Metrics - Process Counters for Monitoring
+ *
+ * GET /metrics serves the registered counters in the Prometheus text
+ * format. Scrapers must send METRICS_TOKEN as a bearer token; while it
+ * is unset the endpoint is disabled.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-21
+ */
+package actions
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/envy"
+)
+
+/**
+ * counter is a monotonically increasing metric
+ */
+type counter struct {
+	name string
+	help string
+	n    atomic.Int64
+}
+
+// Inc adds one to the counter.
+func (m *counter) Inc() { m.n.Add(1) }
+
+// Value returns the current count.
+func (m *counter) Value() int64 { return m.n.Load() }
+
+var (
+	countersMu sync.Mutex
+	counters   = map[string]*counter{}
+)
+
+/**
+ * newCounter registers a counter under a unique Prometheus metric name
+ */
+func newCounter(name, help string) *counter {
+	countersMu.Lock()
+	defer countersMu.Unlock()
+	if _, dup := counters[name]; dup {
+		panic("metrics: duplicate counter " + name)
+	}
+	m := &counter{name: name, help: help}
+	counters[name] = m
+	return m
+}
+
+/**
+ * Metrics renders all counters
+ *
+ * GET /metrics
+ *
+ * @param c - Buffalo context
+ * @return Prometheus text exposition, 401 for a wrong token or 403
+ *   while METRICS_TOKEN is unset
+ */
+func Metrics(c buffalo.Context) error {
+	want := envy.Get("METRICS_TOKEN", "")
+	if want == "" {
+		return c.Render(http.StatusForbidden, r.JSON(map[string]string{"error": "metrics disabled"}))
+	}
+	got := strings.TrimPrefix(c.Request().Header.Get("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(got), []byte(want)) != 1 {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+
+	countersMu.Lock()
+	list := make([]*counter, 0, len(counters))
+	for _, m := range counters {
+		list = append(list, m)
+	}
+	countersMu.Unlock()
+	sort.Slice(list, func(i, j int) bool { return list[i].name < list[j].name })
+
+	var b strings.Builder
+	for _, m := range list {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s counter\n%s %d\n", m.name, m.help, m.name, m.name, m.Value())
+	}
+	res := c.Response()
+	res.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	res.WriteHeader(http.StatusOK)
+	_, err := res.Write([]byte(b.String()))
+	return err
+}
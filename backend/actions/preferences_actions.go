@@ -0,0 +1,349 @@
+/**
+ * Preferences Actions - Per-User Settings API Endpoints
+ *
+ * This file exposes the authenticated user's preferences:
+ * - Reading preferences (stored values or defaults)
+ * - Partially updating preferences with validation
+ * - A short-lived in-process cache used by other handlers
+ * - Applying owners' location privacy to entries shown to other users
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-20
+ */
+package actions
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"backend/models"
+	"backend/validators"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gofrs/uuid"
+)
+
+/**
+ * loadPreferences returns the user's stored preferences, or the defaults
+ * if none have been saved yet.
+ *
+ * @param tx - Database transaction
+ * @param uid - User ID
+ * @return models.UserPreferences - Effective preferences
+ * @return bool - True if a row exists in user_preferences
+ */
+func loadPreferences(tx *pop.Connection, uid uuid.UUID) (models.UserPreferences, bool, error) {
+	prefs := models.DefaultUserPreferences(uid)
+	err := tx.Where("user_id = ?", uid).First(&prefs)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.DefaultUserPreferences(uid), false, nil
+	}
+	if err != nil {
+		return models.DefaultUserPreferences(uid), false, err
+	}
+	return prefs, true, nil
+}
+
+const (
+	// preferencesCacheTTL bounds how long a change made through another
+	// instance can go unnoticed.
+	preferencesCacheTTL = time.Minute
+	// preferencesCacheSize bounds the number of cached users.
+	preferencesCacheSize = 10000
+)
+
+/**
+ * cachedPreferences is one entry of prefsCache
+ */
+type cachedPreferences struct {
+	prefs    models.UserPreferences
+	loadedAt time.Time
+}
+
+var prefsCache = struct {
+	sync.Mutex
+	entries map[uuid.UUID]cachedPreferences
+}{entries: map[uuid.UUID]cachedPreferences{}}
+
+/**
+ * userPreferences returns the user's effective preferences, served from
+ * an in-process cache for up to preferencesCacheTTL. Handlers that only
+ * read preferences use it instead of loadPreferences.
+ *
+ * @param tx - Database connection used on a cache miss
+ * @param uid - User ID
+ * @return models.UserPreferences - Effective preferences
+ */
+func userPreferences(tx *pop.Connection, uid uuid.UUID) (models.UserPreferences, error) {
+	now := time.Now()
+	prefsCache.Lock()
+	e, ok := prefsCache.entries[uid]
+	prefsCache.Unlock()
+	if ok && now.Sub(e.loadedAt) < preferencesCacheTTL {
+		return e.prefs, nil
+	}
+
+	prefs, _, err := loadPreferences(tx, uid)
+	if err != nil {
+		return prefs, err
+	}
+	prefsCache.Lock()
+	if len(prefsCache.entries) >= preferencesCacheSize {
+		clear(prefsCache.entries)
+	}
+	prefsCache.entries[uid] = cachedPreferences{prefs: prefs, loadedAt: now}
+	prefsCache.Unlock()
+	return prefs, nil
+}
+
+/**
+ * forgetPreferences drops the user's cached preferences
+ */
+func forgetPreferences(uid uuid.UUID) {
+	prefsCache.Lock()
+	delete(prefsCache.entries, uid)
+	prefsCache.Unlock()
+}
+
+/**
+ * defaultEntryColor returns the color for a new entry of the current user
+ * that does not name one
+ */
+func defaultEntryColor(c buffalo.Context) string {
+	uid, ok := currentUserID(c)
+	if !ok {
+		return models.DefaultColor
+	}
+	prefs, err := userPreferences(mustTx(c), uid)
+	if err != nil {
+		return models.DefaultColor
+	}
+	return prefs.DefaultColor
+}
+
+/**
+ * GetPreferences returns the current user's preferences
+ *
+ * GET /api/me/preferences
+ *
+ * @param c - Buffalo context with authenticated user
+ * @return JSON preferences or error response
+ */
+func GetPreferences(c buffalo.Context) error {
+	uid, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+
+	prefs, _, err := loadPreferences(mustTx(c), uid)
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+	return c.Render(http.StatusOK, r.JSON(prefs))
+}
+
+/**
+ * UpdatePreferences partially updates the current user's preferences
+ *
+ * PATCH /api/me/preferences
+ *
+ * Payload (all fields optional):
+ * - max_running_hours: Auto-stop limit for running entries (1–168)
+ * - timezone: IANA zone name, validated with time.LoadLocation
+ * - location_visibility: exact, approximate or hidden; how entry
+ *   locations appear to other users (the owner always sees exact data)
+ * - discard_under_seconds: Stopping an entry shorter than this deletes it
+ *   (0–3600, 0 disables)
+ * - weekly_goal_minutes: Weekly time target (0–10080, 0 = none); applies
+ *   to the current week, earlier weeks keep their goal
+ * - default_color: Hex color of new entries that do not name one
+ * - rounding_minutes: Report rounding increment, one of 0, 1, 5, 6, 10,
+ *   15, 30 or 60 (0 = exact)
+ * - team_notification_emails: false stops email about team invitations,
+ *   role changes and removals
+ * - locale: Language and number format of reports and their emails,
+ *   en-US or de-DE; a report's config can override it
+ * - report_retention_days: Days stored reports are kept (1–365, 0 = the
+ *   server's retention); applies to reports already stored, counted from
+ *   when each was written
+ *
+ * Every invalid field is reported, and nothing is saved:
+ * 422 {"error": "validation failed", "errors": {"<field>": ["<message>"]}}
+ *
+ * @param c - Buffalo context with authenticated user
+ * @return JSON updated preferences or error response
+ */
+func UpdatePreferences(c buffalo.Context) error {
+	type payload struct {
+		MaxRunningHours        *int    `json:"max_running_hours"`
+		Timezone               *string `json:"timezone"`
+		LocationVisibility     *string `json:"location_visibility"`
+		DiscardUnderSeconds    *int    `json:"discard_under_seconds"`
+		WeeklyGoalMinutes      *int    `json:"weekly_goal_minutes"`
+		DefaultColor           *string `json:"default_color"`
+		RoundingMinutes        *int    `json:"rounding_minutes"`
+		TeamNotificationEmails *bool   `json:"team_notification_emails"`
+		Locale                 *string `json:"locale"`
+		ReportRetentionDays    *int    `json:"report_retention_days"`
+	}
+	var p payload
+	if err := c.Bind(&p); err != nil {
+		return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "bad payload"}))
+	}
+
+	tx := mustTx(c)
+	uid, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+
+	prefs, exists, err := loadPreferences(tx, uid)
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+
+	errs := map[string][]string{}
+	if p.MaxRunningHours != nil {
+		if *p.MaxRunningHours < 1 || *p.MaxRunningHours > 168 {
+			errs["max_running_hours"] = []string{"must be between 1 and 168"}
+		}
+		prefs.MaxRunningHours = *p.MaxRunningHours
+	}
+	if p.Timezone != nil {
+		tz := strings.TrimSpace(*p.Timezone)
+		if _, err := time.LoadLocation(tz); err != nil || tz == "" {
+			errs["timezone"] = []string{"must be an IANA time zone name"}
+		}
+		prefs.Timezone = tz
+	}
+	if p.LocationVisibility != nil {
+		if !models.ValidLocationVisibility(*p.LocationVisibility) {
+			errs["location_visibility"] = []string{"must be exact, approximate or hidden"}
+		}
+		prefs.LocationVisibility = *p.LocationVisibility
+	}
+	if p.DiscardUnderSeconds != nil {
+		if *p.DiscardUnderSeconds < 0 || *p.DiscardUnderSeconds > 3600 {
+			errs["discard_under_seconds"] = []string{"must be between 0 and 3600"}
+		}
+		prefs.DiscardUnderSeconds = *p.DiscardUnderSeconds
+	}
+	if p.WeeklyGoalMinutes != nil {
+		if *p.WeeklyGoalMinutes < 0 || *p.WeeklyGoalMinutes > 7*24*60 {
+			errs["weekly_goal_minutes"] = []string{"must be between 0 and 10080"}
+		}
+		prefs.WeeklyGoalMinutes = *p.WeeklyGoalMinutes
+	}
+	if p.DefaultColor != nil {
+		color, err := validators.NormalizeColor(*p.DefaultColor)
+		if err != nil {
+			errs["default_color"] = []string{err.Error()}
+		}
+		prefs.DefaultColor = color
+	}
+	if p.RoundingMinutes != nil {
+		if !models.ValidRoundingMinutes(*p.RoundingMinutes) {
+			errs["rounding_minutes"] = []string{"must be one of 0, 1, 5, 6, 10, 15, 30, 60"}
+		}
+		prefs.RoundingMinutes = *p.RoundingMinutes
+	}
+	if p.TeamNotificationEmails != nil {
+		prefs.TeamNotificationEmails = *p.TeamNotificationEmails
+	}
+	if p.Locale != nil {
+		if !models.ValidLocale(*p.Locale) {
+			errs["locale"] = []string{"must be one of " + strings.Join(models.Locales, ", ")}
+		}
+		prefs.Locale = *p.Locale
+	}
+	if p.ReportRetentionDays != nil {
+		if *p.ReportRetentionDays < 0 || *p.ReportRetentionDays > models.MaxReportRetentionDays {
+			errs["report_retention_days"] = []string{"must be between 0 and 365"}
+		}
+		prefs.ReportRetentionDays = *p.ReportRetentionDays
+	}
+	if len(errs) > 0 {
+		return renderValidationErrors(c, errs)
+	}
+
+	prefs.UpdatedAt = time.Now()
+	if exists {
+		err = tx.Update(&prefs)
+	} else {
+		err = tx.Create(&prefs)
+	}
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot save preferences"}))
+	}
+	if p.WeeklyGoalMinutes != nil {
+		if err := snapshotWeeklyGoal(tx, prefs, time.Now()); err != nil {
+			return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot save preferences"}))
+		}
+	}
+
+	// Drop the cached copy now for this instance's next reads, and again
+	// after the commit in case a concurrent request cached the old values
+	forgetPreferences(uid)
+	if p.ReportRetentionDays != nil {
+		if err := applyArtifactRetention(tx, uid); err != nil {
+			return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot save preferences"}))
+		}
+	}
+	afterCommit(c, func() { forgetPreferences(uid) })
+	return c.Render(http.StatusOK, r.JSON(prefs))
+}
+
+/**
+ * applyLocationPrivacy prepares entries of possibly several owners for a
+ * viewer, applying each owner's location_visibility via
+ * TimeTrac.ForViewer. Owners' preferences are loaded in one query.
+ *
+ * @param tx - Database transaction
+ * @param viewerID - User the response is rendered for
+ * @param items - Entries to render, updated in place
+ */
+func applyLocationPrivacy(tx *pop.Connection, viewerID uuid.UUID, items []models.TimeTrac) error {
+	return applyCappedLocationPrivacy(tx, viewerID, items, models.LocationExact)
+}
+
+/**
+ * applyCappedLocationPrivacy is applyLocationPrivacy where no owner's
+ * entries are shown more precisely than limit, e.g. a team's
+ * member_location_visibility setting
+ */
+func applyCappedLocationPrivacy(tx *pop.Connection, viewerID uuid.UUID, items []models.TimeTrac, limit string) error {
+	owners := []interface{}{}
+	seen := map[uuid.UUID]bool{}
+	for _, it := range items {
+		if it.UserID != viewerID && !seen[it.UserID] {
+			seen[it.UserID] = true
+			owners = append(owners, it.UserID)
+		}
+	}
+	if len(owners) == 0 {
+		return nil
+	}
+
+	var prefs []models.UserPreferences
+	if err := tx.Where("user_id in (?)", owners...).All(&prefs); err != nil {
+		return err
+	}
+	visibility := map[uuid.UUID]string{}
+	for _, p := range prefs {
+		visibility[p.ID] = p.LocationVisibility
+	}
+	for i := range items {
+		vis, ok := visibility[items[i].UserID]
+		if !ok {
+			vis = models.DefaultUserPreferences(items[i].UserID).LocationVisibility
+		}
+		items[i] = items[i].ForViewer(viewerID, models.StricterLocationVisibility(vis, limit))
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+/**
+ * Project Actions - Project Listing and Archiving
+ *
+ * Projects are the distinct project names of a user's entries, plus the
+ * shared projects of the user's teams (see team_project_actions.go). This
+ * file provides:
+ * - The project list used by pickers/autocomplete
+ * - Archiving and un-archiving projects
+ *
+ * Archived projects are hidden from the list by default; their entries
+ * are untouched and keep appearing in reports and summaries.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-20
+ */
+package actions
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"backend/models"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/nulls"
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gofrs/uuid"
+)
+
+/**
+ * projectArchived reports whether the user has archived the project
+ */
+func projectArchived(tx *pop.Connection, uid uuid.UUID, name string) (bool, error) {
+	return tx.Where("user_id = ? AND name = ?", uid, name).Exists(&models.ArchivedProject{})
+}
+
+/**
+ * unarchiveProject removes the project from the user's archive
+ */
+func unarchiveProject(tx *pop.Connection, uid uuid.UUID, name string) error {
+	return tx.RawQuery("DELETE FROM archived_projects WHERE user_id = ? AND name = ?", uid, name).Exec()
+}
+
+/**
+ * ProjectsIndex lists the user's projects for pickers
+ *
+ * GET /api/projects?archived=true
+ *
+ * Returns each distinct project name of the user's personal entries with
+ * its entry count and last use, followed by the projects of the teams the
+ * user is an active member of and can see (team_id and team_name set;
+ * entry counts cover the whole team). Personal projects come most recently used
+ * first, team projects by team and name. Archived projects are only
+ * included with `archived=true`.
+ *
+ * @param c - Buffalo context with authenticated user
+ * @return JSON array of projects or error response
+ */
+func ProjectsIndex(c buffalo.Context) error {
+	tx := mustTx(c)
+	uid, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+
+	type project struct {
+		Name     string       `db:"name" json:"name"`
+		Entries  int          `db:"entries" json:"entries"`
+		LastUsed nulls.Time   `db:"last_used" json:"last_used"`
+		Archived bool         `db:"archived" json:"archived"`
+		TeamID   nulls.UUID   `db:"team_id" json:"team_id"`
+		TeamName nulls.String `db:"team_name" json:"team_name"`
+	}
+	list := []project{}
+	archived := c.Param("archived") == "true"
+	if err := tx.RawQuery(`
+		SELECT name, entries, last_used, archived, team_id, team_name FROM (
+			SELECT t.project AS name, COUNT(*) AS entries, MAX(t.start_at) AS last_used,
+			       (a.name IS NOT NULL) AS archived, NULL::uuid AS team_id, NULL::text AS team_name,
+			       0 AS team_rank
+			FROM timetrac t
+			LEFT JOIN archived_projects a ON a.user_id = t.user_id AND a.name = t.project
+			WHERE t.user_id = ? AND t.team_id IS NULL AND t.deleted_at IS NULL AND t.project <> ''
+			  AND (? OR a.name IS NULL)
+			GROUP BY t.project, a.name
+			UNION ALL
+			SELECT p.name, COUNT(t.id) AS entries, MAX(t.start_at) AS last_used,
+			       (p.archived_at IS NOT NULL) AS archived, p.team_id, tm.name AS team_name,
+			       1 AS team_rank
+			FROM projects p
+			JOIN team_members m ON m.team_id = p.team_id AND m.user_id = ? AND m.status = 'active'
+			JOIN teams tm ON tm.id = p.team_id
+			LEFT JOIN timetrac t ON t.team_id = p.team_id AND t.project = p.name AND t.deleted_at IS NULL
+			WHERE (? OR p.archived_at IS NULL) AND `+projectVisibleSQL+`
+			GROUP BY p.id, tm.name
+		) AS projects
+		ORDER BY team_rank, CASE WHEN team_rank = 0 THEN last_used END DESC, team_name, name
+	`, uid, archived, uid, archived, uid, uid, uid).All(&list); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+	return c.Render(http.StatusOK, r.JSON(list))
+}
+
+/**
+ * ProjectsArchive archives a project
+ *
+ * POST /api/projects/{name}/archive
+ *
+ * Archiving is idempotent. Existing entries are not changed.
+ *
+ * @param c - Buffalo context with authenticated user and project name
+ * @return JSON archived project or error response
+ */
+func ProjectsArchive(c buffalo.Context) error {
+	name := strings.TrimSpace(c.Param("name"))
+	if name == "" {
+		return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "bad name"}))
+	}
+
+	tx := mustTx(c)
+	uid, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+
+	now := time.Now()
+	if err := tx.RawQuery(`
+		INSERT INTO archived_projects (user_id, name, created_at, updated_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (user_id, name) DO NOTHING
+	`, uid, name, now, now).Exec(); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot archive"}))
+	}
+
+	var archived models.ArchivedProject
+	if err := tx.Where("user_id = ? AND name = ?", uid, name).First(&archived); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+	return c.Render(http.StatusOK, r.JSON(archived))
+}
+
+/**
+ * ProjectsUnarchive restores an archived project to the pickers
+ *
+ * DELETE /api/projects/{name}/archive
+ *
+ * @param c - Buffalo context with authenticated user and project name
+ * @return JSON status or error response
+ */
+func ProjectsUnarchive(c buffalo.Context) error {
+	name := strings.TrimSpace(c.Param("name"))
+	if name == "" {
+		return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "bad name"}))
+	}
+
+	tx := mustTx(c)
+	uid, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+
+	if err := unarchiveProject(tx, uid, name); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot unarchive"}))
+	}
+	return c.Render(http.StatusOK, r.JSON(map[string]string{"status": "unarchived"}))
+}
@@ -0,0 +1,532 @@
+/**
+ * Project Budget Actions - Budget Tracking of Team Projects
+ *
+ * A team project can have a budget in hours, in money, or both (see
+ * models.Project). Consumption is the time of the project's stopped
+ * entries; its money value prices each entry at the rate its member had
+ * when it started (member_rate_history), counting only rates in the
+ * team's default currency. This file provides:
+ * - The burndown of a project: cumulative consumption per day against
+ *   the budget, GET /api/projects/{id}/burndown
+ * - Alerts when a stopped entry takes a project past 80% and 100% of a
+ *   budget, emailed to the members who manage the budget and sent to
+ *   their project.budget webhooks
+ * - The budgets of project reports (see reports.Budget)
+ *
+ * Hours budgets are visible to members with view_analytics; money budgets
+ * and values, like rates, only to members with manage_rates.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-22
+ */
+package actions
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"backend/mailer"
+	"backend/models"
+	"backend/reports"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/nulls"
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gofrs/uuid"
+	"github.com/lib/pq"
+)
+
+// Budget kinds, as named in alerts.
+const (
+	budgetHours  = "hours"
+	budgetAmount = "amount"
+)
+
+/**
+ * projectView returns the project as the member may see it: without its
+ * money budget unless they have manage_rates
+ */
+func projectView(p models.Project, member models.TeamMember) models.Project {
+	if !member.HasPermission("manage_rates") {
+		p.BudgetAmountCents = nulls.Int64{}
+	}
+	return p
+}
+
+/**
+ * budgetUsage is the consumption of a project
+ */
+type budgetUsage struct {
+	Project string  `db:"project"`
+	Seconds float64 `db:"seconds"`
+	Cents   float64 `db:"cents"` // Unrounded value in the currency asked for
+}
+
+/**
+ * projectBudgetUsage returns the consumption of the team's projects by
+ * their stopped entries started before a time
+ *
+ * @param currency - Currency whose rates are counted in Cents
+ * @return map[string]budgetUsage - By project name; projects without
+ *   entries are missing
+ */
+func projectBudgetUsage(tx *pop.Connection, teamID uuid.UUID, names []string, currency string, before time.Time) (map[string]budgetUsage, error) {
+	rows := []budgetUsage{}
+	if err := tx.RawQuery(`
+		SELECT t.project, COALESCE(SUM(`+trackNetSecondsSQL+`), 0) AS seconds,
+		       COALESCE(SUM(CASE WHEN rate.currency = ? THEN `+trackNetSecondsSQL+` * rate.hourly_rate_cents END), 0) / 3600 AS cents
+		FROM timetrac t
+		`+entryRateJoinSQL+`
+		WHERE t.team_id = ? AND t.project = ANY(?) AND t.deleted_at IS NULL AND t.end_at IS NOT NULL AND t.start_at < ?
+		GROUP BY t.project
+	`, currency, teamID, pq.Array(names), before.UTC()).All(&rows); err != nil {
+		return nil, err
+	}
+	usage := make(map[string]budgetUsage, len(rows))
+	for _, row := range rows {
+		usage[row.Project] = row
+	}
+	return usage, nil
+}
+
+/**
+ * reportBudgets returns the budgets for a project report on a team: those
+ * of the team's projects the user can see, or of the config's projects,
+ * with their consumption up to the end of the period. Members without
+ * view_analytics get none, and money budgets are left out without
+ * manage_rates.
+ */
+func reportBudgets(db *pop.Connection, uid uuid.UUID, teamID nulls.UUID, req reports.Request) ([]reports.Budget, error) {
+	if req.Template != "project" || !teamID.Valid {
+		return nil, nil
+	}
+	var member models.TeamMember
+	err := db.Where("team_id = ? AND user_id = ? AND status = ?", teamID.UUID, uid, "active").First(&member)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil || !member.HasPermission("view_analytics") {
+		return nil, err
+	}
+
+	projects := []models.Project{}
+	filter := req.Config.Projects
+	if err := db.RawQuery(`
+		SELECT p.* FROM projects p
+		WHERE p.team_id = ? AND (p.budget_hours IS NOT NULL OR p.budget_amount_cents IS NOT NULL)
+		  AND (? OR p.name = ANY(?)) AND `+projectVisibleSQL+`
+		ORDER BY p.name
+	`, teamID.UUID, len(filter) == 0, pq.Array(filter), uid, uid, uid).All(&projects); err != nil {
+		return nil, err
+	}
+	if len(projects) == 0 {
+		return nil, nil
+	}
+	settings, err := teamSettingsFor(db, teamID.UUID)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(projects))
+	for i, p := range projects {
+		names[i] = p.Name
+	}
+	usage, err := projectBudgetUsage(db, teamID.UUID, names, settings.DefaultCurrency, req.To)
+	if err != nil {
+		return nil, err
+	}
+
+	budgets := []reports.Budget{}
+	for _, p := range projects {
+		p = projectView(p, member)
+		if !p.BudgetHours.Valid && !p.BudgetAmountCents.Valid {
+			continue
+		}
+		u := usage[p.Name]
+		budgets = append(budgets, reports.Budget{
+			Project:     p.Name,
+			Hours:       p.BudgetHours.Float64,
+			AmountCents: p.BudgetAmountCents.Int64,
+			Currency:    settings.DefaultCurrency,
+			UsedSeconds: u.Seconds,
+			UsedCents:   int64(math.Round(u.Cents)),
+		})
+	}
+	return budgets, nil
+}
+
+/**
+ * burndownDay is a day of a project's burndown
+ */
+type burndownDay struct {
+	Date                  string  `json:"date"`
+	Hours                 float64 `json:"hours"`                             // Tracked on the day
+	CumulativeHours       float64 `json:"cumulative_hours"`                  // Tracked up to the end of the day
+	AmountCents           *int64  `json:"amount_cents,omitempty"`            // Value of the day, with manage_rates
+	CumulativeAmountCents *int64  `json:"cumulative_amount_cents,omitempty"` // Value up to the end of the day
+}
+
+/**
+ * projectBurndown is a project's consumption per day against its budget
+ */
+type projectBurndown struct {
+	ProjectID         uuid.UUID     `json:"project_id"`
+	Project           string        `json:"project"`
+	From              time.Time     `json:"from"`
+	To                time.Time     `json:"to"`
+	Timezone          string        `json:"timezone"`
+	BudgetHours       nulls.Float64 `json:"budget_hours"`
+	BudgetAmountCents nulls.Int64   `json:"budget_amount_cents"`
+	Currency          string        `json:"currency,omitempty"`          // Of the amounts, with manage_rates
+	UsedHours         float64       `json:"used_hours"`                  // Up to the end of the period
+	UsedAmountCents   *int64        `json:"used_amount_cents,omitempty"` // Up to the end of the period
+	Days              []burndownDay `json:"days"`
+}
+
+/**
+ * GetProjectBurndown returns a team project's cumulative consumption per
+ * day against its budget
+ * GET /api/projects/{id}/burndown?from=&to=&tz=
+ *
+ * Parameters: from and to as dates or RFC 3339 times (default from the
+ * day the project was created, at most 366 days back, to today); tz
+ * (default the caller's preference). Cumulative values include the
+ * entries before from, so the line starts where the budget stood then.
+ * Only stopped entries count.
+ *
+ * The caller must be able to see the project and have view_analytics in
+ * its team; money budgets and values are only included with
+ * manage_rates.
+ *
+ * Responses:
+ * - 200 with the burndown
+ * - 400 for an invalid project ID or timezone
+ * - 403 without view_analytics
+ * - 404 for a project that is not a visible team project
+ * - 422 for invalid from or to
+ */
+func GetProjectBurndown(c buffalo.Context) error {
+	uid, ok := currentUserID(c)
+	if !ok {
+		return renderTeamError(c, http.StatusUnauthorized, "Unauthorized")
+	}
+	id, err := uuid.FromString(c.Param("id"))
+	if err != nil {
+		return renderTeamError(c, http.StatusBadRequest, "Invalid project ID")
+	}
+
+	tx := mustTx(c)
+	var p models.Project
+	if err := tx.RawQuery(`
+		SELECT p.* FROM projects p
+		JOIN team_members m ON m.team_id = p.team_id AND m.user_id = ? AND m.status = 'active'
+		WHERE p.id = ? AND `+projectVisibleSQL,
+		uid, id, uid, uid, uid).First(&p); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return renderTeamError(c, http.StatusNotFound, "Project not found")
+		}
+		return renderTeamError(c, http.StatusInternalServerError, "Failed to load project")
+	}
+	var member models.TeamMember
+	if err := tx.Where("team_id = ? AND user_id = ? AND status = ?", p.TeamID, uid, "active").First(&member); err != nil {
+		return renderTeamError(c, http.StatusInternalServerError, "Failed to load membership")
+	}
+	if !member.HasPermission("view_analytics") {
+		return renderTeamError(c, http.StatusForbidden, "Insufficient permissions")
+	}
+	p = projectView(p, member)
+
+	loc, badTz, err := requestLocation(c, tx, uid)
+	if err != nil {
+		if badTz != "" {
+			return renderTeamError(c, http.StatusBadRequest, "Invalid timezone")
+		}
+		return renderTeamError(c, http.StatusInternalServerError, "Failed to load preferences")
+	}
+	to := startOfDay(time.Now(), loc).AddDate(0, 0, 1)
+	if v := c.Param("to"); v != "" {
+		if to, err = parseReportTime(v, loc, true); err != nil {
+			return renderFieldError(c, "to", errors.New("must be a date or an RFC 3339 time"))
+		}
+	}
+	from := startOfDay(p.CreatedAt, loc)
+	if earliest := to.AddDate(0, 0, -reportMaxDays); from.Before(earliest) {
+		from = earliest
+	}
+	if v := c.Param("from"); v != "" {
+		if from, err = parseReportTime(v, loc, false); err != nil {
+			return renderFieldError(c, "from", errors.New("must be a date or an RFC 3339 time"))
+		}
+	}
+	if !to.After(from) {
+		return renderFieldError(c, "to", errors.New("must be after from"))
+	}
+	if to.After(from.AddDate(0, 0, reportMaxDays)) {
+		return renderFieldError(c, "to", fmt.Errorf("must be within %d days of from", reportMaxDays))
+	}
+
+	amounts := member.HasPermission("manage_rates")
+	settings, err := teamSettingsFor(tx, p.TeamID.UUID)
+	if err != nil {
+		return renderTeamError(c, http.StatusInternalServerError, "Failed to load team settings")
+	}
+	type dayRow struct {
+		Day     string  `db:"day"`
+		Seconds float64 `db:"seconds"`
+		Cents   float64 `db:"cents"`
+	}
+	rows := []dayRow{}
+	if err := tx.RawQuery(`
+		SELECT to_char(date_trunc('day', (t.start_at AT TIME ZONE 'UTC') AT TIME ZONE ?), 'YYYY-MM-DD') AS day,
+		       COALESCE(SUM(`+trackNetSecondsSQL+`), 0) AS seconds,
+		       COALESCE(SUM(CASE WHEN rate.currency = ? THEN `+trackNetSecondsSQL+` * rate.hourly_rate_cents END), 0) / 3600 AS cents
+		FROM timetrac t
+		`+entryRateJoinSQL+`
+		WHERE t.team_id = ? AND t.project = ? AND t.deleted_at IS NULL AND t.end_at IS NOT NULL AND t.start_at < ?
+		GROUP BY 1
+		ORDER BY 1
+	`, loc.String(), settings.DefaultCurrency, p.TeamID.UUID, p.Name, to.UTC()).All(&rows); err != nil {
+		return renderTeamError(c, http.StatusInternalServerError, "Failed to load entries")
+	}
+
+	byDay := make(map[string]dayRow, len(rows))
+	var seconds, cents float64
+	first := from.In(loc).Format("2006-01-02")
+	for _, row := range rows {
+		if row.Day < first {
+			seconds, cents = seconds+row.Seconds, cents+row.Cents
+		} else {
+			byDay[row.Day] = row
+		}
+	}
+	burndown := projectBurndown{
+		ProjectID:         p.ID,
+		Project:           p.Name,
+		From:              from,
+		To:                to,
+		Timezone:          loc.String(),
+		BudgetHours:       p.BudgetHours,
+		BudgetAmountCents: p.BudgetAmountCents,
+		Days:              []burndownDay{},
+	}
+	for d := startOfDay(from, loc); d.Before(to); d = d.AddDate(0, 0, 1) {
+		key := d.Format("2006-01-02")
+		row := byDay[key]
+		seconds, cents = seconds+row.Seconds, cents+row.Cents
+		day := burndownDay{Date: key, Hours: row.Seconds / 3600, CumulativeHours: seconds / 3600}
+		if amounts {
+			dayCents, total := int64(math.Round(row.Cents)), int64(math.Round(cents))
+			day.AmountCents, day.CumulativeAmountCents = &dayCents, &total
+		}
+		burndown.Days = append(burndown.Days, day)
+	}
+	burndown.UsedHours = seconds / 3600
+	if amounts {
+		total := int64(math.Round(cents))
+		burndown.Currency, burndown.UsedAmountCents = settings.DefaultCurrency, &total
+	}
+
+	return c.Render(http.StatusOK, r.JSON(map[string]interface{}{
+		"success": true,
+		"data":    burndown,
+		"message": "Project burndown retrieved successfully",
+	}))
+}
+
+/**
+ * budgetWebhookBody is the JSON body POSTed to webhooks for budget alerts
+ */
+type budgetWebhookBody struct {
+	Event      string         `json:"event"`
+	OccurredAt time.Time      `json:"occurred_at"`
+	Project    models.Project `json:"project"`
+	Budget     string         `json:"budget"`             // hours or amount
+	Threshold  int            `json:"threshold"`          // Percent of the budget reached
+	Used       float64        `json:"used"`               // Hours, or cents for amount
+	Limit      float64        `json:"limit"`              // Budget in the same unit
+	Currency   string         `json:"currency,omitempty"` // For amount
+}
+
+/**
+ * checkProjectBudget alerts the managers of a stopped team entry's
+ * project when its consumption crossed a threshold of a budget; failures
+ * are logged and never fail the request. The alert runs in a savepoint,
+ * so a failed statement does not abort the request's transaction; the
+ * emails it queued are dropped with it.
+ */
+func checkProjectBudget(c buffalo.Context, tx *pop.Connection, item models.TimeTrac) {
+	if !item.TeamID.Valid || item.Project == "" {
+		return
+	}
+	hooks, _ := c.Value(afterCommitKey).(*[]func())
+	queued := 0
+	if hooks != nil {
+		queued = len(*hooks)
+	}
+	err := tx.RawQuery("SAVEPOINT budget_alert").Exec()
+	if err == nil {
+		err = alertProjectBudget(c, tx, item.TeamID.UUID, item.Project, time.Now())
+		if err != nil {
+			if hooks != nil {
+				*hooks = (*hooks)[:queued]
+			}
+			if rbErr := tx.RawQuery("ROLLBACK TO SAVEPOINT budget_alert").Exec(); rbErr != nil {
+				c.Logger().Errorf("budget alert rollback: %v", rbErr)
+			}
+		} else {
+			err = tx.RawQuery("RELEASE SAVEPOINT budget_alert").Exec()
+		}
+	}
+	if err != nil {
+		c.Logger().Errorf("budget alert for project %q of team %s: %v", item.Project, item.TeamID.UUID, err)
+	}
+}
+
+/**
+ * alertProjectBudget compares a project's consumption with its budgets
+ * and raises an alert for each threshold newly crossed
+ *
+ * A threshold is recorded on the project when it is alerted, so every
+ * threshold alerts once per budget even when entries stop concurrently.
+ */
+func alertProjectBudget(c buffalo.Context, tx *pop.Connection, teamID uuid.UUID, name string, now time.Time) error {
+	var p models.Project
+	err := tx.Where("team_id = ? AND name = ?", teamID, name).First(&p)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil || (!p.BudgetHours.Valid && !p.BudgetAmountCents.Valid) {
+		return err
+	}
+	settings, err := teamSettingsFor(tx, teamID)
+	if err != nil {
+		return err
+	}
+	usage, err := projectBudgetUsage(tx, teamID, []string{name}, settings.DefaultCurrency, now)
+	if err != nil {
+		return err
+	}
+	u := usage[name]
+
+	alerts := []budgetWebhookBody{}
+	if t := models.BudgetThresholdCrossed(p.BudgetAlertedHours, u.Seconds/3600, p.BudgetHours.Float64); t > 0 {
+		alerts = append(alerts, budgetWebhookBody{Budget: budgetHours, Threshold: t, Used: u.Seconds / 3600, Limit: p.BudgetHours.Float64})
+	}
+	if t := models.BudgetThresholdCrossed(p.BudgetAlertedAmount, u.Cents, float64(p.BudgetAmountCents.Int64)); t > 0 {
+		alerts = append(alerts, budgetWebhookBody{Budget: budgetAmount, Threshold: t, Used: math.Round(u.Cents),
+			Limit: float64(p.BudgetAmountCents.Int64), Currency: settings.DefaultCurrency})
+	}
+	for _, a := range alerts {
+		column := "budget_alerted_hours"
+		if a.Budget == budgetAmount {
+			column = "budget_alerted_amount"
+		}
+		n, err := tx.RawQuery("UPDATE projects SET "+column+" = ? WHERE id = ? AND "+column+" < ?",
+			a.Threshold, p.ID, a.Threshold).ExecWithCount()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			continue // Alerted by a concurrent request
+		}
+		a.Event, a.OccurredAt, a.Project = "project.budget", now.UTC(), p
+		if err := sendBudgetAlert(c, tx, a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/**
+ * sendBudgetAlert delivers a budget alert to the team's active members
+ * who can see the project and manage the budget: manage_projects for
+ * hours, manage_rates for money. Each gets a project.budget webhook
+ * delivery and, unless they turned off team emails, an email after
+ * commit.
+ */
+func sendBudgetAlert(c buffalo.Context, tx *pop.Connection, a budgetWebhookBody) error {
+	permission := "manage_projects"
+	if a.Budget == budgetAmount {
+		permission = "manage_rates"
+	}
+	members := models.TeamMembers{}
+	if err := tx.Where("team_id = ? AND status = ?", a.Project.TeamID, "active").All(&members); err != nil {
+		return err
+	}
+	var team models.Team
+	if err := tx.Find(&team, a.Project.TeamID.UUID); err != nil {
+		return err
+	}
+
+	for _, m := range members {
+		if !m.HasPermission(permission) {
+			continue
+		}
+		visible, err := tx.RawQuery(`SELECT 1 FROM projects p WHERE p.id = ? AND `+projectVisibleSQL,
+			a.Project.ID, m.UserID, m.UserID, m.UserID).Exists(&models.Project{})
+		if err != nil {
+			return err
+		}
+		if !visible {
+			continue
+		}
+
+		body := a
+		body.Project = projectView(a.Project, m)
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		if err := tx.RawQuery(`
+			INSERT INTO webhook_deliveries (webhook_id, event, payload, status, attempts, next_attempt_at, request_id, created_at, updated_at)
+			SELECT id, ?, ?, ?, 0, ?, ?, ?, ?
+			FROM webhooks
+			WHERE user_id = ? AND active AND ? = ANY(events)
+		`, a.Event, string(payload), models.WebhookDeliveryPending, a.OccurredAt, requestIDOf(c), a.OccurredAt, a.OccurredAt, m.UserID, a.Event).Exec(); err != nil {
+			return err
+		}
+
+		var user models.User
+		if err := tx.Find(&user, m.UserID); err != nil {
+			return err
+		}
+		prefs, err := userPreferences(tx, m.UserID)
+		if err != nil {
+			return err
+		}
+		if !prefs.TeamNotificationEmails {
+			continue
+		}
+		msg := budgetAlertMail(user.Email, team.Name, a)
+		afterCommit(c, func() { sendMail(msg) })
+	}
+	return nil
+}
+
+/**
+ * budgetAlertMail composes the email of a budget alert
+ */
+func budgetAlertMail(to, team string, a budgetWebhookBody) mailer.Message {
+	used, limit := fmt.Sprintf("%.1f hours", a.Used), fmt.Sprintf("%.1f hours", a.Limit)
+	kind := "hours"
+	if a.Budget == budgetAmount {
+		used = reports.FormatCents(int64(a.Used)) + " " + a.Currency
+		limit = reports.FormatCents(int64(a.Limit)) + " " + a.Currency
+		kind = "money"
+	}
+	subject := fmt.Sprintf("%s reached %d%% of its %s budget", a.Project.Name, a.Threshold, kind)
+	if a.Threshold >= 100 {
+		subject = fmt.Sprintf("%s used up its %s budget", a.Project.Name, kind)
+	}
+	return mailer.Message{
+		To:      to,
+		Subject: subject,
+		Text: fmt.Sprintf("The project %q of the team %q on TimeTrac has used %s of its budget of %s (%d%%).\n",
+			a.Project.Name, team, used, limit, int(a.Used*100/a.Limit)),
+	}
+}
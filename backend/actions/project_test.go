@@ -0,0 +1,26 @@
+package actions
+
+import (
+	"net/http"
+
+	"backend/models"
+)
+
+func (as *ActionSuite) Test_ProjectsArchive() {
+	token := as.registerToken("archive-projects@example.com")
+
+	as.Equal(http.StatusOK, as.authJSON(token, "/api/projects/Web/archive").Post(nil).Code)
+	as.Equal(http.StatusOK, as.authJSON(token, "/api/projects/Web/archive").Post(nil).Code)
+	n, err := as.DB.Where("name = ?", "Web").Count(&models.ArchivedProject{})
+	as.NoError(err)
+	as.Equal(1, n)
+
+	// A blank name is rejected both ways
+	as.Equal(http.StatusBadRequest, as.authJSON(token, "/api/projects/%%20/archive").Post(nil).Code)
+	as.Equal(http.StatusBadRequest, as.authJSON(token, "/api/projects/%%20/archive").Delete().Code)
+
+	as.Equal(http.StatusOK, as.authJSON(token, "/api/projects/Web/archive").Delete().Code)
+	n, err = as.DB.Where("name = ?", "Web").Count(&models.ArchivedProject{})
+	as.NoError(err)
+	as.Equal(0, n)
+}
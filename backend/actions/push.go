@@ -0,0 +1,88 @@
+/**
+ * Push - Notifications to Registered Devices
+ *
+ * Jobs and handlers notify a user with notifyUser, which sends to every
+ * device the user registered (see device_actions.go). Delivery runs in
+ * the background; devices whose token the provider reports as
+ * unregistered are removed.
+ *
+ * Without FCM_CREDENTIALS_FILE notifications are written to the log (see
+ * package push).
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-21
+ */
+package actions
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+
+	"backend/models"
+	"backend/push"
+
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gofrs/uuid"
+)
+
+// pushTimeout bounds the delivery to all devices of one notification.
+const pushTimeout = 30 * time.Second
+
+// pushNotifier delivers notifications; replaced by tests.
+var pushNotifier push.Notifier
+
+/**
+ * configurePush creates the notifier from the environment
+ */
+func configurePush() error {
+	f, err := push.FromEnv(os.Getenv)
+	if err != nil {
+		return err
+	}
+	if f == nil {
+		pushNotifier = push.Log{Logf: app.Logger.Infof}
+		return nil
+	}
+	pushNotifier = f
+	return nil
+}
+
+/**
+ * notifyUser sends a notification to all devices of a user
+ *
+ * @param db - Database connection; must outlive the call, as
+ *             unregistered devices are deleted in the background
+ * @param uid - Recipient
+ * @param title, body - Text shown by the OS
+ * @param data - Passed to the app
+ */
+func notifyUser(db *pop.Connection, uid uuid.UUID, title, body string, data map[string]string) {
+	notifier := pushNotifier
+	if notifier == nil {
+		return
+	}
+	devices := []models.Device{}
+	if err := db.Where("user_id = ?", uid).All(&devices); err != nil {
+		app.Logger.Errorf("push to user %s: %v", uid, err)
+		return
+	}
+	if len(devices) == 0 {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), pushTimeout)
+		defer cancel()
+		for _, d := range devices {
+			err := notifier.Send(ctx, push.Notification{Token: d.PushToken, Title: title, Body: body, Data: data})
+			if errors.Is(err, push.ErrUnregistered) {
+				err = db.RawQuery("DELETE FROM devices WHERE id = ? AND push_token = ?", d.ID, d.PushToken).Exec()
+			}
+			if err != nil {
+				app.Logger.Errorf("push to device %s: %v", d.ID, err)
+			}
+		}
+	}()
+}
@@ -0,0 +1,69 @@
+/**
+ * Rate Limit - In-Process Request Throttling
+ *
+ * Unauthenticated endpoints that send email (sign-in links) are limited
+ * per recipient and per client IP, so they cannot be used to flood an
+ * inbox or to probe many addresses. Counters live in memory; with several
+ * instances each enforces its own limit.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-21
+ */
+package actions
+
+import (
+	"sync"
+	"time"
+)
+
+/**
+ * rateLimiter allows at most limit events per key within a sliding window
+ */
+type rateLimiter struct {
+	limit  int
+	window time.Duration
+
+	mu    sync.Mutex
+	hits  map[string][]time.Time
+	swept time.Time
+}
+
+/**
+ * newRateLimiter returns a limiter allowing limit events per window
+ */
+func newRateLimiter(limit int, window time.Duration) *rateLimiter {
+	return &rateLimiter{limit: limit, window: window, hits: map[string][]time.Time{}}
+}
+
+/**
+ * allow records an event for key and reports whether it is within the
+ * limit. Rejected events are not recorded.
+ */
+func (l *rateLimiter) allow(key string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Drop idle keys now and then so the map does not grow without bound
+	if now.Sub(l.swept) > l.window {
+		for k, times := range l.hits {
+			if now.Sub(times[len(times)-1]) >= l.window {
+				delete(l.hits, k)
+			}
+		}
+		l.swept = now
+	}
+
+	recent := l.hits[key][:0]
+	for _, t := range l.hits[key] {
+		if now.Sub(t) < l.window {
+			recent = append(recent, t)
+		}
+	}
+	if len(recent) >= l.limit {
+		l.hits[key] = recent
+		return false
+	}
+	l.hits[key] = append(recent, now)
+	return true
+}
@@ -0,0 +1,272 @@
+/**
+ * Refresh Token Actions - Session Renewal
+ *
+ * Login and Register issue a short-lived access token (JWT) together with
+ * an opaque refresh token. POST /api/auth/refresh exchanges a refresh
+ * token for a new pair:
+ * - Every refresh token can be used once; using it rotates it
+ * - Tokens rotated from one login share a family. Presenting an already
+ *   rotated token again revokes the whole family, including the access
+ *   tokens issued with it, since one of the two holders is an attacker
+ * - Logout revokes the family of the current session
+ *
+ * Configuration:
+ * - JWT_ACCESS_TTL_MINUTES: access token lifetime, 15–60 (default 30)
+ * - REFRESH_TOKEN_TTL_DAYS: refresh token lifetime, 1–365 (default 30)
+ * - SESSION_SLIDING_WINDOW_MINUTES: renew access tokens this close to
+ *   expiry, 0–15 (default 0, off; see sliding_session.go)
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-21
+ */
+package actions
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"backend/models"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/nulls"
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gofrs/uuid"
+)
+
+/**
+ * tokenPair is the credentials returned by Login, Register and
+ * RefreshToken
+ */
+type tokenPair struct {
+	Token            string    `json:"token"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+/**
+ * authResponse is the body of a successful login or registration
+ */
+func authResponse(u models.User, pair tokenPair) map[string]any {
+	return map[string]any{
+		"user":               u,
+		"token":              pair.Token,
+		"expires_at":         pair.ExpiresAt,
+		"refresh_token":      pair.RefreshToken,
+		"refresh_expires_at": pair.RefreshExpiresAt,
+	}
+}
+
+/**
+ * newRefreshTokenValue returns a random opaque refresh token
+ */
+func newRefreshTokenValue() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+/**
+ * issueTokenPair creates an access token and a refresh token in a family
+ *
+ * @param tx - Database transaction
+ * @param uid - User the tokens belong to
+ * @param family - Refresh token family; pass a new ID for a new login
+ * @param client - Device the tokens are issued to, shown in session lists
+ * @param now - Issue time
+ * @return tokenPair - The new credentials
+ */
+func issueTokenPair(tx *pop.Connection, uid, family uuid.UUID, client sessionClient, now time.Time) (tokenPair, error) {
+	token, exp, err := issueAccessToken(tx, uid, family, client, now)
+	if err != nil {
+		return tokenPair{}, err
+	}
+
+	raw, err := newRefreshTokenValue()
+	if err != nil {
+		return tokenPair{}, err
+	}
+	ttl, _ := refreshTokenTTL()
+	rt := models.RefreshToken{
+		UserID:    uid,
+		FamilyID:  family,
+		TokenHash: models.HashRefreshToken(raw),
+		ExpiresAt: now.Add(ttl).UTC(),
+	}
+	if err := tx.Create(&rt); err != nil {
+		return tokenPair{}, err
+	}
+	return tokenPair{Token: token, ExpiresAt: exp, RefreshToken: raw, RefreshExpiresAt: rt.ExpiresAt}, nil
+}
+
+/**
+ * issueAccessToken creates an access token and its auth_tokens row
+ *
+ * @param tx - Database transaction
+ * @param uid - User the token belongs to
+ * @param family - Refresh token family of the session
+ * @param client - Device the token is issued to
+ * @param now - Issue time
+ * @return string, time.Time - Signed token and its expiry
+ */
+func issueAccessToken(tx *pop.Connection, uid, family uuid.UUID, client sessionClient, now time.Time) (string, time.Time, error) {
+	// A JTI collision leaves the transaction usable thanks to ON CONFLICT;
+	// the token is then regenerated with a fresh JTI
+	for attempt := 0; ; attempt++ {
+		token, jti, exp, err := GenerateJWT(uid.String(), family.String())
+		if err != nil {
+			return "", time.Time{}, err
+		}
+		n, err := tx.RawQuery(`
+			INSERT INTO auth_tokens (jti, user_id, family_id, expires_at, user_agent, ip, last_used_at, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (jti) DO NOTHING
+		`, jti, uid, family, exp.UTC(), client.UserAgent, client.IP, now.UTC(), now.UTC(), now.UTC()).ExecWithCount()
+		if err != nil {
+			return "", time.Time{}, err
+		}
+		if n == 1 {
+			return token, exp, nil
+		}
+		if attempt == 2 {
+			return "", time.Time{}, errors.New("cannot allocate a unique token ID")
+		}
+	}
+}
+
+/**
+ * newLoginTokens issues the token pair for a fresh login
+ */
+func newLoginTokens(c buffalo.Context, tx *pop.Connection, uid uuid.UUID) (tokenPair, error) {
+	family, err := uuid.NewV4()
+	if err != nil {
+		return tokenPair{}, err
+	}
+	return issueTokenPair(tx, uid, family, sessionClientFrom(c), time.Now())
+}
+
+/**
+ * revokeTokenFamily revokes all refresh and access tokens of a family
+ */
+func revokeTokenFamily(tx *pop.Connection, family uuid.UUID, now time.Time) error {
+	currentTokenCache().forgetFamily(family.String())
+	if err := tx.RawQuery(`
+		UPDATE refresh_tokens SET revoked_at = ?, updated_at = ?
+		WHERE family_id = ? AND revoked_at IS NULL
+	`, now.UTC(), now.UTC(), family).Exec(); err != nil {
+		return err
+	}
+	return tx.RawQuery(`
+		UPDATE auth_tokens SET revoked_at = ?, updated_at = ?
+		WHERE family_id = ? AND revoked_at IS NULL
+	`, now.UTC(), now.UTC(), family).Exec()
+}
+
+/**
+ * rotateRefreshToken redeems a refresh token for a new pair in its family
+ *
+ * A rejected token is reported through the returned message rather than
+ * an error, so the caller still commits a family revocation.
+ *
+ * @param tx - Database transaction
+ * @param raw - Refresh token sent by the client
+ * @param client - Device refreshing
+ * @param now - Reference time
+ * @return tokenPair - New credentials when msg is ""
+ * @return string - Rejection message for a 401 response
+ */
+func rotateRefreshToken(tx *pop.Connection, raw string, client sessionClient, now time.Time) (tokenPair, string, error) {
+	var rt models.RefreshToken
+	err := tx.RawQuery(`SELECT * FROM refresh_tokens WHERE token_hash = ? FOR UPDATE`, models.HashRefreshToken(raw)).First(&rt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return tokenPair{}, "invalid refresh token", nil
+	}
+	if err != nil {
+		return tokenPair{}, "", err
+	}
+
+	switch {
+	case rt.RevokedAt.Valid:
+		return tokenPair{}, "refresh token revoked", nil
+	case rt.UsedAt.Valid:
+		if err := revokeTokenFamily(tx, rt.FamilyID, now); err != nil {
+			return tokenPair{}, "", err
+		}
+		app.Logger.Warnf("refresh token reuse for user %s; family %s revoked", rt.UserID, rt.FamilyID)
+		return tokenPair{}, "refresh token reused", nil
+	case !now.Before(rt.ExpiresAt):
+		return tokenPair{}, "refresh token expired", nil
+	}
+
+	rt.UsedAt = nulls.NewTime(now.UTC())
+	rt.UpdatedAt = now.UTC()
+	if err := tx.Update(&rt); err != nil {
+		return tokenPair{}, "", err
+	}
+	pair, err := issueTokenPair(tx, rt.UserID, rt.FamilyID, client, now)
+	return pair, "", err
+}
+
+/**
+ * RefreshToken exchanges a refresh token for a new token pair
+ *
+ * POST /api/auth/refresh
+ *
+ * Payload:
+ * - refresh_token: Token from Login, Register or a previous refresh
+ *
+ * The presented token is rotated: it cannot be used again, and using it
+ * again revokes every token of the session. The route manages its own
+ * transaction so that revocation is committed with the 401 response.
+ *
+ * @param c - Buffalo context with refresh payload
+ * @return JSON token pair or error response
+ */
+func RefreshToken(c buffalo.Context) error {
+	var p struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := c.Bind(&p); err != nil {
+		return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "bad payload"}))
+	}
+	raw := strings.TrimSpace(p.RefreshToken)
+	if raw == "" {
+		return c.Render(http.StatusUnprocessableEntity, r.JSON(map[string]string{"error": "refresh_token required"}))
+	}
+
+	var (
+		pair tokenPair
+		msg  string
+	)
+	err := models.DB.Transaction(func(tx *pop.Connection) error {
+		var err error
+		pair, msg, err = rotateRefreshToken(tx, raw, sessionClientFrom(c), time.Now())
+		return err
+	})
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot refresh"}))
+	}
+	if msg != "" {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": msg}))
+	}
+	return c.Render(http.StatusOK, r.JSON(pair))
+}
+
+/**
+ * PurgeExpiredRefreshTokens deletes refresh tokens past their expiry
+ *
+ * @param db - Database connection
+ * @param now - Reference time
+ * @return int - Number of deleted tokens
+ */
+func PurgeExpiredRefreshTokens(db *pop.Connection, now time.Time) (int, error) {
+	return db.RawQuery("DELETE FROM refresh_tokens WHERE expires_at < ?", now.UTC()).ExecWithCount()
+}
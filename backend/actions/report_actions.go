@@ -2,7 +2,9 @@
  * Report Actions - Report Management API Endpoints
  *
  * This package provides HTTP handlers for report management operations
- * including scheduled reports and report templates.
+ * including report templates, previews and generation; scheduled reports
+ * live in scheduled_report_actions.go. Reports are built by package
+ * reports from the entries reportEntrySource loads.
  *
  * @author Abud Developer
  * @version 1.0.0
@@ -11,23 +13,145 @@
 package actions
 
 import (
+	"bytes"
+	"database/sql"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"strings"
 	"time"
+	"unicode/utf8"
+
+	"backend/models"
+	"backend/reports"
 
 	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/nulls"
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gofrs/uuid"
+	"github.com/lib/pq"
+)
+
+const (
+	// reportPreviewRows is the default number of rows per previewed table.
+	reportPreviewRows = 20
+	// reportPreviewMaxRows bounds the rows a preview can ask for.
+	reportPreviewMaxRows = 100
+	// reportSyncMaxEntries bounds the entries of a report generated
+	// within the request.
+	reportSyncMaxEntries = 5000
+	// reportMaxDays bounds the period of a generated report.
+	reportMaxDays = 366
 )
 
+// errReportTooLarge is returned by a limited reportEntrySource.
+var errReportTooLarge = errors.New("report has too many entries")
+
 /**
- * ScheduledReport represents a scheduled report configuration
+ * reportEntryRow is an entry loaded for a report
  */
-type ScheduledReport struct {
-	ID       string                 `json:"id"`
-	Name     string                 `json:"name"`
-	Schedule string                 `json:"schedule"`
-	Config   map[string]interface{} `json:"config"`
-	IsActive bool                   `json:"is_active"`
-	NextRun  *string                `json:"next_run,omitempty"`
+type reportEntryRow struct {
+	StartAt   time.Time      `db:"start_at"`
+	EndAt     nulls.Time     `db:"end_at"`
+	Email     string         `db:"email"`
+	Project   string         `db:"project"`
+	Tags      pq.StringArray `db:"tags"`
+	Note      string         `db:"note"`
+	Billable  bool           `db:"billable"`
+	Seconds   float64        `db:"seconds"`
+	RateCents nulls.Int      `db:"rate_cents"`
+	Currency  nulls.String   `db:"currency"`
+}
+
+/**
+ * entryRateJoinSQL joins "rate", the rate (hourly_rate_cents, currency)
+ * of the member of team entry "t" when it started; NULL without one
+ */
+const entryRateJoinSQL = `LEFT JOIN LATERAL (
+		SELECT h.hourly_rate_cents, h.currency
+		FROM member_rate_history h
+		WHERE h.team_id = t.team_id AND h.user_id = t.user_id AND h.effective_from <= t.start_at
+		ORDER BY h.effective_from DESC
+		LIMIT 1
+	) rate ON true`
+
+/**
+ * reportEntrySource returns a reports.Source over the user's own entries
+ * or, with teamID, the team's entries visible to the user: all of them
+ * when viewAll (view_analytics), their own otherwise. Team entries carry
+ * their member's rate when they started (see member_rate_history).
+ *
+ * @param limit - Fail with errReportTooLarge beyond this many entries, 0
+ *   for no limit
+ */
+func reportEntrySource(tx *pop.Connection, userID uuid.UUID, teamID nulls.UUID, viewAll bool, limit int) reports.Source {
+	return func(from, to time.Time) ([]reports.Entry, error) {
+		scope, args := "t.user_id = ?", []interface{}{userID}
+		if teamID.Valid {
+			scope = "t.team_id = ? AND " + entryVisibleSQL("t")
+			args = []interface{}{teamID.UUID, userID, userID, userID, userID}
+			if !viewAll {
+				scope += " AND t.user_id = ?"
+				args = append(args, userID)
+			}
+		}
+		args = append(args, from.UTC(), to.UTC())
+		q := `
+			SELECT t.start_at, t.end_at, u.email,
+			       COALESCE(t.project, '') AS project,
+			       COALESCE(t.tags, '{}') AS tags,
+			       COALESCE(t.note, '') AS note,
+			       t.billable,
+			       ` + trackNetSecondsSQL + ` AS seconds,
+			       rate.hourly_rate_cents AS rate_cents,
+			       rate.currency
+			FROM timetrac t
+			JOIN users u ON u.id = t.user_id
+			` + entryRateJoinSQL + `
+			WHERE ` + scope + ` AND t.deleted_at IS NULL AND t.start_at >= ? AND t.start_at < ?
+			ORDER BY t.start_at, t.id`
+		if limit > 0 {
+			q += " LIMIT ?"
+			args = append(args, limit+1)
+		}
+		rows := []reportEntryRow{}
+		if err := tx.RawQuery(q, args...).All(&rows); err != nil {
+			return nil, err
+		}
+		if limit > 0 && len(rows) > limit {
+			return nil, errReportTooLarge
+		}
+		entries := make([]reports.Entry, len(rows))
+		for i, row := range rows {
+			entries[i] = reports.Entry{
+				StartAt: row.StartAt, Member: row.Email, Project: row.Project, Tags: row.Tags,
+				Note: row.Note, Billable: row.Billable, Seconds: row.Seconds,
+			}
+			if row.EndAt.Valid {
+				end := row.EndAt.Time
+				entries[i].EndAt = &end
+			}
+			if row.RateCents.Valid && row.Currency.Valid {
+				entries[i].RateCents, entries[i].Currency = row.RateCents.Int, row.Currency.String
+			}
+		}
+		return entries, nil
+	}
+}
+
+/**
+ * parseReportTime parses a report bound: a date in loc, where the end
+ * bound includes the whole day, or an RFC 3339 time
+ */
+func parseReportTime(v string, loc *time.Location, end bool) (time.Time, error) {
+	if d, err := time.ParseInLocation("2006-01-02", v, loc); err == nil {
+		if end {
+			d = d.AddDate(0, 0, 1)
+		}
+		return d, nil
+	}
+	return time.Parse(time.RFC3339, v)
 }
 
 /**
@@ -43,48 +167,17 @@ type ReportTemplate struct {
 }
 
 /**
- * GetScheduledReports retrieves all scheduled reports for the current user
- * GET /api/scheduled
+ * RedirectReportTemplates sends clients of the old report templates path
+ * on to GetReportTemplates
+ * GET /api/templates
  */
-func GetScheduledReports(c buffalo.Context) error {
-	// For now, return empty array since we don't have scheduled reports implemented yet
-	// In a real implementation, this would query the database for user's scheduled reports
-
-	scheduledReports := []ScheduledReport{}
-
-	return c.Render(http.StatusOK, r.JSON(map[string]interface{}{
-		"success": true,
-		"data":    scheduledReports,
-		"message": "Scheduled reports retrieved successfully",
-	}))
-}
-
-/**
- * CreateScheduledReport creates a new scheduled report
- * POST /api/scheduled
- */
-func CreateScheduledReport(c buffalo.Context) error {
-	// For now, return a simple success response
-	// In a real implementation, this would save the scheduled report to the database
-
-	scheduledReport := map[string]interface{}{
-		"id":         "scheduled_" + fmt.Sprintf("%d", time.Now().Unix()),
-		"name":       "New Scheduled Report",
-		"schedule":   "daily",
-		"is_active":  true,
-		"created_at": time.Now().Format(time.RFC3339),
-	}
-
-	return c.Render(http.StatusCreated, r.JSON(map[string]interface{}{
-		"success": true,
-		"data":    scheduledReport,
-		"message": "Scheduled report created successfully",
-	}))
+func RedirectReportTemplates(c buffalo.Context) error {
+	return c.Redirect(http.StatusPermanentRedirect, "/api/reports/templates")
 }
 
 /**
  * GetReportTemplates retrieves all available report templates
- * GET /api/templates
+ * GET /api/reports/templates
  */
 func GetReportTemplates(c buffalo.Context) error {
 	// Define some default report templates
@@ -125,6 +218,18 @@ func GetReportTemplates(c buffalo.Context) error {
 				"group_by":        "project",
 			},
 		},
+		{
+			ID:          "tag-breakdown-template",
+			Title:       "Tag Breakdown",
+			Description: "Time tracking data grouped by tag; entries with several tags count toward each",
+			Type:        "summary",
+			Format:      "pdf",
+			Config: map[string]interface{}{
+				"include_charts":  true,
+				"include_details": false,
+				"group_by":        "tag",
+			},
+		},
 		{
 			ID:          "csv-export-template",
 			Title:       "CSV Export",
@@ -147,23 +252,307 @@ func GetReportTemplates(c buffalo.Context) error {
 }
 
 /**
- * PreviewReport generates a preview of a report
- * POST /api/preview
+ * GenerateReportRequest is the payload of GenerateReport
  */
-func PreviewReport(c buffalo.Context) error {
-	// For now, return a simple preview response
-	// In a real implementation, this would generate an actual report preview
+type GenerateReportRequest struct {
+	Template string              `json:"template"` // summary, project or detailed
+	Format   string              `json:"format"`   // json (default) or csv
+	Title    string              `json:"title"`
+	TeamID   *uuid.UUID          `json:"team_id"`
+	From     string              `json:"from"` // YYYY-MM-DD or RFC 3339
+	To       string              `json:"to"`   // YYYY-MM-DD (inclusive) or RFC 3339
+	Timezone string              `json:"timezone"`
+	Config   models.ReportConfig `json:"config"`
+}
+
+/**
+ * reportRenderer returns the renderer and Content-Type of a report format
+ */
+func reportRenderer(format string) (func(io.Writer, *reports.Document) error, string) {
+	switch format {
+	case "csv":
+		return reports.RenderCSV, "text/csv; charset=utf-8"
+	case "pdf":
+		return reports.RenderPDF, "application/pdf"
+	default:
+		return reports.RenderJSON, "application/json"
+	}
+}
+
+/**
+ * reportParams is a validated GenerateReportRequest
+ */
+type reportParams struct {
+	Request reports.Request
+	Format  string
+	TeamID  nulls.UUID
+	ViewAll bool              // Whether the team report covers every visible entry
+	Member  models.TeamMember // Caller's membership in the team, for team reports
+}
+
+// badTimezoneError is a timezone in a report request that does not load.
+type badTimezoneError string
+
+func (e badTimezoneError) Error() string { return "invalid timezone " + string(e) }
+
+// errReportAccess is returned for a team the caller is not an active member of.
+var errReportAccess = errors.New("not an active member of the team")
+
+/**
+ * resolveReportRequest validates a report request and resolves its
+ * defaults; see GenerateReport for the payload
+ *
+ * @return error - *reports.FieldError, badTimezoneError, errReportAccess
+ *   or a database error, for renderReportRequestError
+ */
+func resolveReportRequest(c buffalo.Context, tx *pop.Connection, uid uuid.UUID, req GenerateReportRequest) (reportParams, error) {
+	p := reportParams{Format: req.Format, ViewAll: true}
+	if req.Template == "" {
+		req.Template = "summary"
+	}
+	if !models.ValidTemplate(req.Template) {
+		return p, &reports.FieldError{Field: "template", Err: errors.New("must be summary, project or detailed")}
+	}
+	if err := req.Config.ValidateFor(req.TeamID != nil); err != nil {
+		return p, &reports.FieldError{Field: "config", Err: err}
+	}
+	switch p.Format {
+	case "":
+		p.Format = "json"
+	case "json", "csv", "pdf":
+	default:
+		return p, &reports.FieldError{Field: "format", Err: errors.New("must be json, csv or pdf")}
+	}
+	if utf8.RuneCountInString(req.Title) > 100 {
+		return p, &reports.FieldError{Field: "title", Err: errors.New("must be at most 100 characters")}
+	}
 
-	previewData := map[string]interface{}{
-		"preview_id": "preview_" + fmt.Sprintf("%d", time.Now().Unix()),
-		"status":     "generated",
-		"url":        "/api/reports/preview/preview_" + fmt.Sprintf("%d", time.Now().Unix()),
-		"expires_at": time.Now().Add(1 * time.Hour).Format(time.RFC3339),
+	var loc *time.Location
+	if tz := strings.TrimSpace(req.Timezone); tz != "" {
+		var err error
+		if loc, err = time.LoadLocation(tz); err != nil {
+			return p, badTimezoneError(tz)
+		}
+	} else {
+		var badTz string
+		var err error
+		if loc, badTz, err = requestLocation(c, tx, uid); err != nil {
+			if badTz != "" {
+				return p, badTimezoneError(badTz)
+			}
+			return p, err
+		}
+	}
+	now := time.Now().In(loc)
+	from, to := startOfDay(now, loc).AddDate(0, 0, -6), now
+	var err error
+	if req.Config.Period != "" {
+		if req.From != "" || req.To != "" {
+			return p, &reports.FieldError{Field: "config", Err: errors.New("period cannot be combined with from and to")}
+		}
+		if from, to, err = models.ResolvePeriod(req.Config.Period, req.Config.WeekStartDay(), now, loc); err != nil {
+			return p, &reports.FieldError{Field: "config", Err: err}
+		}
+	}
+	if req.From != "" {
+		if from, err = parseReportTime(req.From, loc, false); err != nil {
+			return p, &reports.FieldError{Field: "from", Err: errors.New("must be a date or an RFC 3339 time")}
+		}
+	}
+	if req.To != "" {
+		if to, err = parseReportTime(req.To, loc, true); err != nil {
+			return p, &reports.FieldError{Field: "to", Err: errors.New("must be a date or an RFC 3339 time")}
+		}
+	}
+	if !to.After(from) {
+		return p, &reports.FieldError{Field: "to", Err: errors.New("must be after from")}
+	}
+	if to.After(from.AddDate(0, 0, reportMaxDays)) {
+		return p, &reports.FieldError{Field: "to", Err: fmt.Errorf("must be within %d days of from", reportMaxDays)}
 	}
 
+	if req.TeamID != nil {
+		var member models.TeamMember
+		err := tx.Where("team_id = ? AND user_id = ? AND status = ?", *req.TeamID, uid, "active").First(&member)
+		if errors.Is(err, sql.ErrNoRows) {
+			return p, errReportAccess
+		}
+		if err != nil {
+			return p, err
+		}
+		p.TeamID, p.ViewAll, p.Member = nulls.NewUUID(*req.TeamID), member.HasPermission("view_analytics"), member
+	}
+	prefs, err := userPreferences(tx, uid)
+	if err != nil {
+		return p, err
+	}
+	p.Request = reports.Request{
+		Template: req.Template,
+		Title:    req.Title,
+		Config:   req.Config,
+		From:     from,
+		To:       to,
+		Location: loc,
+		Team:     p.TeamID.Valid,
+		Locale:   prefs.Locale,
+	}
+	return p, nil
+}
+
+/**
+ * renderReportRequestError answers a request resolveReportRequest
+ * rejected
+ */
+func renderReportRequestError(c buffalo.Context, err error) error {
+	var fe *reports.FieldError
+	var badTz badTimezoneError
+	switch {
+	case errors.As(err, &fe):
+		return renderFieldError(c, fe.Field, fe.Err)
+	case errors.As(err, &badTz):
+		return renderBadTimezone(c, string(badTz))
+	case errors.Is(err, errReportAccess):
+		return renderTeamError(c, http.StatusForbidden, "Access denied")
+	default:
+		return renderTeamError(c, http.StatusInternalServerError, "Failed to generate report")
+	}
+}
+
+/**
+ * GenerateReport generates a report and returns it
+ * POST /api/reports/generate
+ *
+ * Payload: template (default summary); format json (the document in the
+ * envelope), csv or pdf (a download); title; from and to (default the last 7
+ * days including today), at most 366 days apart; timezone (default the
+ * caller's preference, or ?tz=); team_id for a team report, covering
+ * every visible entry with view_analytics and the caller's own otherwise;
+ * config { group_by, include_details, include_charts, projects,
+ * billable_only, period, week_start, locale }. A config period such as
+ * last_week replaces from and to and is resolved now in the timezone (see
+ * models.ResolvePeriod). The report is written in the config's locale
+ * (en-US or de-DE), else the caller's preference.
+ *
+ * Reports over more than 5000 entries answer 413; POST /api/reports/jobs
+ * generates those in the background.
+ */
+func GenerateReport(c buffalo.Context) error {
+	uid, ok := currentUserID(c)
+	if !ok {
+		return renderTeamError(c, http.StatusUnauthorized, "Unauthorized")
+	}
+	var req GenerateReportRequest
+	if err := c.Bind(&req); err != nil {
+		return renderTeamError(c, http.StatusBadRequest, "Invalid request data")
+	}
+	tx := mustTx(c)
+	p, err := resolveReportRequest(c, tx, uid, req)
+	if err != nil {
+		return renderReportRequestError(c, err)
+	}
+	if p.Request.Budgets, err = reportBudgets(tx, uid, p.TeamID, p.Request); err != nil {
+		return renderTeamError(c, http.StatusInternalServerError, "Failed to load budgets")
+	}
+
+	doc, err := reports.GenerateReport(reportEntrySource(tx, uid, p.TeamID, p.ViewAll, reportSyncMaxEntries), p.Request)
+	var fe *reports.FieldError
+	switch {
+	case errors.As(err, &fe):
+		return renderFieldError(c, fe.Field, fe.Err)
+	case errors.Is(err, errReportTooLarge):
+		return renderTeamError(c, http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("Report has more than %d entries; narrow the period or use /api/reports/jobs", reportSyncMaxEntries))
+	case err != nil:
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to generate report",
+			"error":   err.Error(),
+		}))
+	}
+
+	if p.Format != "json" {
+		var buf bytes.Buffer
+		render, contentType := reportRenderer(p.Format)
+		if err := render(&buf, doc); err != nil {
+			return renderTeamError(c, http.StatusInternalServerError, "Failed to render report")
+		}
+		res := c.Response()
+		res.Header().Set("Content-Type", contentType)
+		res.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, reports.Filename(doc, p.Format)))
+		res.WriteHeader(http.StatusOK)
+		_, err := res.Write(buf.Bytes())
+		return err
+	}
+	return c.Render(http.StatusOK, r.JSON(map[string]interface{}{
+		"success": true,
+		"data":    doc,
+		"message": "Report generated successfully",
+	}))
+}
+
+/**
+ * PreviewReportRequest is the payload of PreviewReport
+ */
+type PreviewReportRequest struct {
+	GenerateReportRequest
+	Rows int `json:"rows"` // Rows per table, 20 by default and at most 100
+}
+
+/**
+ * PreviewReport generates a report and returns the start of it
+ * POST /api/reports/preview (also POST /api/preview)
+ *
+ * Payload: as for POST /api/reports/generate, without format, plus rows.
+ * The report is built over the whole period, so its totals are complete,
+ * but tables keep their first rows rows and at most rows details sections
+ * are included; what was cut is marked truncated (see reports.Preview).
+ *
+ * Responses: 200 with the document, 422 with field errors, 403 for a team
+ * the caller is not an active member of, 413 past 5000 entries.
+ */
+func PreviewReport(c buffalo.Context) error {
+	uid, ok := currentUserID(c)
+	if !ok {
+		return renderTeamError(c, http.StatusUnauthorized, "Unauthorized")
+	}
+	var req PreviewReportRequest
+	if err := c.Bind(&req); err != nil {
+		return renderTeamError(c, http.StatusBadRequest, "Invalid request data")
+	}
+	if req.Rows == 0 {
+		req.Rows = reportPreviewRows
+	}
+	if req.Rows < 1 || req.Rows > reportPreviewMaxRows {
+		return renderFieldError(c, "rows", fmt.Errorf("must be from 1 to %d", reportPreviewMaxRows))
+	}
+	req.Format = ""
+	tx := mustTx(c)
+	p, err := resolveReportRequest(c, tx, uid, req.GenerateReportRequest)
+	if err != nil {
+		return renderReportRequestError(c, err)
+	}
+	if p.Request.Budgets, err = reportBudgets(tx, uid, p.TeamID, p.Request); err != nil {
+		return renderTeamError(c, http.StatusInternalServerError, "Failed to load budgets")
+	}
+
+	doc, err := reports.GenerateReport(reportEntrySource(tx, uid, p.TeamID, p.ViewAll, reportSyncMaxEntries), p.Request)
+	var fe *reports.FieldError
+	switch {
+	case errors.As(err, &fe):
+		return renderFieldError(c, fe.Field, fe.Err)
+	case errors.Is(err, errReportTooLarge):
+		return renderTeamError(c, http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("Report has more than %d entries; narrow the period", reportSyncMaxEntries))
+	case err != nil:
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to preview report",
+			"error":   err.Error(),
+		}))
+	}
 	return c.Render(http.StatusOK, r.JSON(map[string]interface{}{
 		"success": true,
-		"data":    previewData,
+		"data":    reports.Preview(doc, req.Rows),
 		"message": "Report preview generated successfully",
 	}))
 }
@@ -0,0 +1,252 @@
+/**
+ * Report Artifact Actions - Stored Report Files and History
+ *
+ * Report jobs and scheduled report runs keep the files they generate as
+ * report artifacts: the file in the photo store under
+ * reports/{artifact id}/ and a report_artifacts row describing it. An
+ * artifact is only visible to its owner, who lists them with GET
+ * /api/reports/history and downloads them with GET
+ * /api/reports/download/{id} until they expire, which is after the
+ * owner's retention (see report_retention.go). Recipients of scheduled
+ * reports without an account get signed links instead (see
+ * scheduled_report_delivery.go).
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-22
+ */
+package actions
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"backend/models"
+	"backend/storage"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gofrs/uuid"
+)
+
+// reportArtifactCleanupBatch is the number of artifacts removed per pass.
+const reportArtifactCleanupBatch = 100
+
+/**
+ * reportArtifactView sets the fields of an artifact the API computes
+ */
+func reportArtifactView(a models.ReportArtifact) models.ReportArtifact {
+	a.DownloadURL = reportArtifactURL(a.ID)
+	return a
+}
+
+/**
+ * reportArtifactURL returns the API path downloading an artifact
+ */
+func reportArtifactURL(id uuid.UUID) string {
+	return "/api/reports/download/" + id.String()
+}
+
+/**
+ * storeReportArtifact puts a report file in the photo store and records
+ * it; ID, FileKey, Size, ExpiresAt (after the owner's retention) and the
+ * timestamps of a are set here
+ *
+ * @param db - Database connection
+ * @param a - Artifact to create, describing the report
+ * @param data - File content
+ */
+func storeReportArtifact(db *pop.Connection, a *models.ReportArtifact, data []byte) error {
+	st, err := photoStore()
+	if err != nil {
+		return err
+	}
+	retention, err := userArtifactRetention(db, a.UserID)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	a.ID = uuid.Must(uuid.NewV4())
+	a.FileKey = fmt.Sprintf("reports/%s/%s", a.ID, a.Filename)
+	a.Size = int64(len(data))
+	a.ExpiresAt = now.Add(retention)
+	a.CreatedAt, a.UpdatedAt = now, now
+	_, contentType := reportRenderer(a.Format)
+	if err := st.Put(context.Background(), a.FileKey, bytes.NewReader(data), a.Size, contentType); err != nil {
+		return err
+	}
+	if err := db.Create(a); err != nil {
+		st.Delete(context.Background(), a.FileKey)
+		return err
+	}
+	return nil
+}
+
+/**
+ * deleteReportArtifact removes an artifact's file and row; the runs
+ * linking to it lose it, which invalidates their download links
+ */
+func deleteReportArtifact(db *pop.Connection, st storage.Store, a models.ReportArtifact) error {
+	if err := st.Delete(context.Background(), a.FileKey); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return err
+	}
+	return db.Destroy(&a)
+}
+
+/**
+ * serveReportArtifact answers with an artifact's file: a redirect to a
+ * short-lived signed URL when the store supports it (S3), otherwise the
+ * file streamed through the API (disk)
+ */
+func serveReportArtifact(c buffalo.Context, a models.ReportArtifact) error {
+	st, err := photoStore()
+	if err != nil {
+		return renderTeamError(c, http.StatusInternalServerError, "Report storage unavailable")
+	}
+	if u, err := st.SignedURL(c, a.FileKey, photoURLExpiry); err != nil {
+		return renderTeamError(c, http.StatusInternalServerError, "Failed to sign report URL")
+	} else if u != "" {
+		return c.Redirect(http.StatusFound, u)
+	}
+
+	body, err := st.Get(c, a.FileKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return renderTeamError(c, http.StatusGone, "Report file has expired")
+	}
+	if err != nil {
+		return renderTeamError(c, http.StatusInternalServerError, "Failed to read report file")
+	}
+	defer body.Close()
+
+	_, contentType := reportRenderer(a.Format)
+	res := c.Response()
+	res.Header().Set("Content-Type", contentType)
+	res.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename}))
+	res.Header().Set("Content-Length", strconv.FormatInt(a.Size, 10))
+	res.WriteHeader(http.StatusOK)
+	_, err = io.Copy(res, body)
+	return err
+}
+
+/**
+ * GetReportHistory lists the caller's stored reports, newest first
+ * GET /api/reports/history
+ *
+ * Query parameters: source (job or scheduled), page (default 1),
+ * per_page (default 50, up to 100). The data holds items, page, per_page
+ * and total; expired reports are left out.
+ */
+func GetReportHistory(c buffalo.Context) error {
+	uid, ok := currentUserID(c)
+	if !ok {
+		return renderTeamError(c, http.StatusUnauthorized, "Unauthorized")
+	}
+	var err error
+	page, perPage := 1, 50
+	if v := c.Param("page"); v != "" {
+		if page, err = strconv.Atoi(v); err != nil || page < 1 {
+			return renderTeamError(c, http.StatusBadRequest, "Invalid page")
+		}
+	}
+	if v := c.Param("per_page"); v != "" {
+		if perPage, err = strconv.Atoi(v); err != nil || perPage < 1 || perPage > scheduledReportsPageMax {
+			return renderTeamError(c, http.StatusBadRequest, "Invalid per_page")
+		}
+	}
+
+	q := mustTx(c).Where("user_id = ? AND expires_at > ?", uid, time.Now().UTC())
+	switch source := c.Param("source"); source {
+	case "":
+	case models.ReportArtifactJob, models.ReportArtifactScheduled:
+		q = q.Where("source = ?", source)
+	default:
+		return renderTeamError(c, http.StatusBadRequest, "Invalid source")
+	}
+	artifacts := []models.ReportArtifact{}
+	q = q.Order("created_at DESC, id").Paginate(page, perPage)
+	if err := q.All(&artifacts); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to retrieve report history",
+			"error":   err.Error(),
+		}))
+	}
+	for i := range artifacts {
+		artifacts[i] = reportArtifactView(artifacts[i])
+	}
+	return c.Render(http.StatusOK, r.JSON(map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"items":    artifacts,
+			"page":     page,
+			"per_page": perPage,
+			"total":    q.Paginator.TotalEntriesSize,
+		},
+		"message": "Report history retrieved successfully",
+	}))
+}
+
+/**
+ * DownloadReportArtifact serves one of the caller's stored reports
+ * GET /api/reports/download/{id}
+ *
+ * Responses:
+ * - 200 with the file and its Content-Type and Content-Disposition, or a
+ *   redirect to a signed storage URL (S3)
+ * - 404 for another user's report
+ * - 410 once the report expired
+ */
+func DownloadReportArtifact(c buffalo.Context) error {
+	uid, ok := currentUserID(c)
+	if !ok {
+		return renderTeamError(c, http.StatusUnauthorized, "Unauthorized")
+	}
+	id, err := uuid.FromString(c.Param("id"))
+	if err != nil {
+		return renderTeamError(c, http.StatusBadRequest, "Invalid report ID")
+	}
+	var a models.ReportArtifact
+	if err := mustTx(c).Where("id = ? AND user_id = ?", id, uid).First(&a); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return renderTeamError(c, http.StatusNotFound, "Report not found")
+		}
+		return renderTeamError(c, http.StatusInternalServerError, "Failed to load report")
+	}
+	if !a.ExpiresAt.After(time.Now()) {
+		return renderTeamError(c, http.StatusGone, "Report file has expired")
+	}
+	return serveReportArtifact(c, a)
+}
+
+/**
+ * CleanupReportArtifacts removes expired artifacts and their files
+ *
+ * @param db - Database connection
+ * @param now - Reference time
+ * @return int - Number of artifacts removed
+ */
+func CleanupReportArtifacts(db *pop.Connection, now time.Time) (int, error) {
+	expired := []models.ReportArtifact{}
+	err := db.Where("expires_at <= ?", now.UTC()).Order("expires_at").Limit(reportArtifactCleanupBatch).All(&expired)
+	if err != nil || len(expired) == 0 {
+		return 0, err
+	}
+	st, err := photoStore()
+	if err != nil {
+		return 0, err
+	}
+	for i, a := range expired {
+		if err := deleteReportArtifact(db, st, a); err != nil {
+			return i, err
+		}
+	}
+	return len(expired), nil
+}
@@ -0,0 +1,136 @@
+/**
+ * Report Chart Actions - Chart Data for the Reports Screen
+ *
+ * GET /api/reports/charts serves the series the reports screen plots,
+ * aggregated by the report engine (reports.GenerateChart) over the same
+ * entries and groups as generated reports, so a chart always matches the
+ * PDF of the same period.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-22
+ */
+package actions
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"backend/models"
+	"backend/reports"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gofrs/uuid"
+)
+
+const (
+	// reportChartMaxBuckets bounds the groups of a chart.
+	reportChartMaxBuckets = 200
+	// reportChartMaxEntries bounds the entries a chart aggregates.
+	reportChartMaxEntries = 50000
+)
+
+/**
+ * GetReportCharts returns a metric per group, ready to plot
+ * GET /api/reports/charts?metric=hours|entries|amount&group=day|week|month|project|tag|member&from=&to=&team_id=&tz=
+ *
+ * Parameters: metric (default hours); group (default day); from and to
+ * as for POST /api/reports/generate (default the last 7 days including
+ * today), or period as a preset such as last_week; week_start; tz
+ * (default the caller's preference); team_id for a team chart, with the
+ * visibility rules of team reports; locale for the labels (default the
+ * caller's preference).
+ *
+ * The data holds labels and keys, one per group, and series of values
+ * in the same order: one series named after the metric, or for amount
+ * one per currency in cents. Day, week and month buckets cover the whole
+ * period, holding zero without entries; other groups are ordered by
+ * descending time. Tag groups overlap and carry a note.
+ *
+ * Responses:
+ * - 200 with the chart
+ * - 403 for a team the caller is not in, or amount without manage_rates
+ * - 413 over 50000 entries
+ * - 422 for invalid parameters, member without a team, amount without a
+ *   team, or more than 200 groups
+ */
+func GetReportCharts(c buffalo.Context) error {
+	uid, ok := currentUserID(c)
+	if !ok {
+		return renderTeamError(c, http.StatusUnauthorized, "Unauthorized")
+	}
+	metric := c.Param("metric")
+	if metric == "" {
+		metric = reports.MetricHours
+	}
+	group := c.Param("group")
+	if group == "" {
+		group = "day"
+	}
+	req := GenerateReportRequest{
+		From: c.Param("from"),
+		To:   c.Param("to"),
+		Config: models.ReportConfig{
+			GroupBy:   group,
+			Period:    c.Param("period"),
+			WeekStart: c.Param("week_start"),
+			Locale:    c.Param("locale"),
+		},
+	}
+	if v := c.Param("team_id"); v != "" {
+		teamID, err := uuid.FromString(v)
+		if err != nil {
+			return renderFieldError(c, "team_id", errors.New("must be a team ID"))
+		}
+		req.TeamID = &teamID
+	}
+
+	// Check the parameters here so errors name them rather than config
+	switch {
+	case metric != reports.MetricHours && metric != reports.MetricEntries && metric != reports.MetricAmount:
+		return renderFieldError(c, "metric", errors.New("must be hours, entries or amount"))
+	case metric == reports.MetricAmount && req.TeamID == nil:
+		return renderFieldError(c, "metric", errors.New("amount is only available for team charts"))
+	case !slices.Contains(reports.ChartGroupings, group):
+		return renderFieldError(c, "group", errors.New("must be day, week, month, project, tag or member"))
+	case group == "member" && req.TeamID == nil:
+		return renderFieldError(c, "group", errors.New("member is only available for team charts"))
+	}
+	if _, err := models.ParseWeekStart(req.Config.WeekStart); err != nil {
+		return renderFieldError(c, "week_start", err)
+	}
+	if req.Config.Locale != "" && !models.ValidLocale(req.Config.Locale) {
+		return renderFieldError(c, "locale", errors.New("must be one of "+strings.Join(models.Locales, ", ")))
+	}
+	if req.Config.Period != "" {
+		if _, _, err := models.ResolvePeriod(req.Config.Period, time.Monday, time.Now(), time.UTC); err != nil {
+			return renderFieldError(c, "period", err)
+		}
+	}
+
+	tx := mustTx(c)
+	p, err := resolveReportRequest(c, tx, uid, req)
+	if err != nil {
+		return renderReportRequestError(c, err)
+	}
+	if metric == reports.MetricAmount && !p.Member.HasPermission("manage_rates") {
+		return renderTeamError(c, http.StatusForbidden, "Insufficient permissions")
+	}
+
+	src := reportEntrySource(tx, uid, p.TeamID, p.ViewAll, reportChartMaxEntries)
+	chart, err := reports.GenerateChart(src, p.Request, metric, reportChartMaxBuckets)
+	if errors.Is(err, errReportTooLarge) {
+		return renderTeamError(c, http.StatusRequestEntityTooLarge, "Too many entries to chart; narrow the period")
+	}
+	if err != nil {
+		return renderReportRequestError(c, err)
+	}
+	return c.Render(http.StatusOK, r.JSON(map[string]interface{}{
+		"success": true,
+		"data":    chart,
+		"message": "Report chart retrieved successfully",
+	}))
+}
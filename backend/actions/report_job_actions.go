@@ -0,0 +1,156 @@
+/**
+ * Report Job Actions - Reports Generated in the Background
+ *
+ * Large reports, such as a year of entries as a detailed PDF, take
+ * longer than a request may. POST /api/reports/jobs queues a report with
+ * the payload of POST /api/reports/generate and answers at once with the
+ * job; the report workers (report_job_worker.go) generate it. Clients
+ * poll GET /api/reports/jobs/{id} for the status and progress and fetch
+ * the file from its download_url once the job completed; the file is a
+ * report artifact (report_artifact_actions.go), so it also shows up in
+ * the report history. Jobs are only visible to the user who queued them.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-22
+ */
+package actions
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"backend/models"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gofrs/uuid"
+)
+
+// reportJobsActiveMax bounds the queued and running jobs of a user.
+const reportJobsActiveMax = 5
+
+/**
+ * reportJobView sets the fields of a job the API computes
+ */
+func reportJobView(j models.ReportJob) models.ReportJob {
+	if j.Status == models.ReportJobCompleted && j.ArtifactID.Valid {
+		j.DownloadURL = reportArtifactURL(j.ArtifactID.UUID)
+	}
+	return j
+}
+
+/**
+ * findReportJob loads one of the caller's report jobs by the id
+ * parameter; when it fails the returned status and message describe the
+ * error response
+ */
+func findReportJob(c buffalo.Context, tx *pop.Connection) (models.ReportJob, int, string) {
+	var j models.ReportJob
+	id, err := uuid.FromString(c.Param("id"))
+	if err != nil {
+		return j, http.StatusBadRequest, "Invalid job ID"
+	}
+	uid, ok := currentUserID(c)
+	if !ok {
+		return j, http.StatusUnauthorized, "Unauthorized"
+	}
+	if err := tx.Where("id = ? AND user_id = ?", id, uid).First(&j); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return j, http.StatusNotFound, "Report job not found"
+		}
+		return j, http.StatusInternalServerError, "Failed to load report job"
+	}
+	return j, 0, ""
+}
+
+/**
+ * CreateReportJob queues a report for the report workers
+ * POST /api/reports/jobs
+ *
+ * Payload: as for POST /api/reports/generate, without its entry limit.
+ *
+ * Responses:
+ * - 202 with the queued job
+ * - 422 for an invalid payload, 403 for a team the caller is not in
+ * - 429 when the caller already has 5 jobs queued or running
+ */
+func CreateReportJob(c buffalo.Context) error {
+	uid, ok := currentUserID(c)
+	if !ok {
+		return renderTeamError(c, http.StatusUnauthorized, "Unauthorized")
+	}
+	var req GenerateReportRequest
+	if err := c.Bind(&req); err != nil {
+		return renderTeamError(c, http.StatusBadRequest, "Invalid request data")
+	}
+	tx := mustTx(c)
+	p, err := resolveReportRequest(c, tx, uid, req)
+	if err != nil {
+		return renderReportRequestError(c, err)
+	}
+
+	active, err := tx.Where("user_id = ? AND status IN (?, ?)", uid, models.ReportJobQueued, models.ReportJobRunning).
+		Count(&models.ReportJob{})
+	if err != nil {
+		return renderTeamError(c, http.StatusInternalServerError, "Failed to queue report")
+	}
+	if active >= reportJobsActiveMax {
+		return renderTeamError(c, http.StatusTooManyRequests,
+			fmt.Sprintf("You already have %d reports in progress; wait for one to finish", active))
+	}
+
+	now := time.Now().UTC()
+	job := models.ReportJob{
+		ID:         uuid.Must(uuid.NewV4()),
+		UserID:     uid,
+		TeamID:     p.TeamID,
+		Status:     models.ReportJobQueued,
+		Template:   p.Request.Template,
+		Title:      p.Request.Title,
+		Format:     p.Format,
+		Config:     p.Request.Config,
+		PeriodFrom: p.Request.From.UTC(),
+		PeriodTo:   p.Request.To.UTC(),
+		Timezone:   p.Request.Location.String(),
+		RequestID:  requestIDOf(c),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := tx.Create(&job); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to queue report",
+			"error":   err.Error(),
+		}))
+	}
+	afterCommit(c, wakeReportWorkers)
+	return c.Render(http.StatusAccepted, r.JSON(map[string]interface{}{
+		"success": true,
+		"data":    reportJobView(job),
+		"message": "Report queued successfully",
+	}))
+}
+
+/**
+ * GetReportJob returns one of the caller's report jobs
+ * GET /api/reports/jobs/{id}
+ *
+ * The status is queued, running, completed or failed; progress is the
+ * percent done. Completed jobs carry a download_url while their file is
+ * kept.
+ */
+func GetReportJob(c buffalo.Context) error {
+	j, status, msg := findReportJob(c, mustTx(c))
+	if status != 0 {
+		return renderTeamError(c, status, msg)
+	}
+	return c.Render(http.StatusOK, r.JSON(map[string]interface{}{
+		"success": true,
+		"data":    reportJobView(j),
+		"message": "Report job retrieved successfully",
+	}))
+}
@@ -0,0 +1,325 @@
+/**
+ * Report Job Worker - Generating Queued Reports
+ *
+ * A pool of workers takes queued report jobs (see report_job_actions.go)
+ * one at a time, oldest first, generates the report, renders it in the
+ * job's format and stores the file as a report artifact of the job's
+ * owner. Workers poll the queue and are woken when a job is queued, so a
+ * job usually starts at once. Rows are claimed with FOR
+ * UPDATE SKIP LOCKED, so several app instances can run workers.
+ *
+ * The progress of a running job moves through fixed stages: entries
+ * loaded, report built, file rendered, file stored. A job that takes
+ * longer than REPORT_JOB_TIMEOUT_SECONDS fails, and its worker takes no
+ * other job until the generation has stopped; one left running by a
+ * stopped instance is failed by the cleanup, which also removes jobs
+ * reportJobRetention after they finished. Errors of a job name the
+ * request that queued it.
+ *
+ * Configuration:
+ * - REPORT_JOB_WORKERS: workers per instance (default 2), 0 disables
+ * - REPORT_JOB_POLL_SECONDS: poll interval (default 5)
+ * - REPORT_JOB_TIMEOUT_SECONDS: time limit of a job (default 600)
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-22
+ */
+package actions
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"backend/models"
+	"backend/reports"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/envy"
+	"github.com/gobuffalo/pop/v6"
+)
+
+const (
+	// reportAsyncMaxEntries bounds the entries of a report job.
+	reportAsyncMaxEntries = 200000
+	// reportJobRetention is how long finished jobs are kept.
+	reportJobRetention = 7 * 24 * time.Hour
+	// defaultReportJobTimeout is the time limit of a job by default.
+	defaultReportJobTimeout = 10 * time.Minute
+)
+
+// Progress of a running job after each stage.
+const (
+	reportJobLoaded   = 50
+	reportJobBuilt    = 70
+	reportJobRendered = 90
+)
+
+// reportJobWake wakes an idle worker when a job is queued.
+var reportJobWake = make(chan struct{}, 1)
+
+/**
+ * wakeReportWorkers tells an idle worker that a job was queued
+ */
+func wakeReportWorkers() {
+	select {
+	case reportJobWake <- struct{}{}:
+	default:
+	}
+}
+
+/**
+ * reportJobTimeout reads REPORT_JOB_TIMEOUT_SECONDS
+ */
+func reportJobTimeout() time.Duration {
+	n, err := strconv.Atoi(envy.Get("REPORT_JOB_TIMEOUT_SECONDS", ""))
+	if err != nil || n <= 0 {
+		return defaultReportJobTimeout
+	}
+	return time.Duration(n) * time.Second
+}
+
+/**
+ * startReportJobWorkers starts the report workers
+ */
+func startReportJobWorkers(app *buffalo.App) {
+	workers, err := strconv.Atoi(envy.Get("REPORT_JOB_WORKERS", "2"))
+	if err != nil || workers <= 0 {
+		return
+	}
+	seconds, err := strconv.Atoi(envy.Get("REPORT_JOB_POLL_SECONDS", "5"))
+	if err != nil || seconds <= 0 {
+		seconds = 5
+	}
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			ticker := time.NewTicker(time.Duration(seconds) * time.Second)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+				case <-reportJobWake:
+				}
+				// Work until the queue is empty
+				for {
+					ran, err := RunReportJob(models.DB, time.Now())
+					if err != nil {
+						app.Logger.Errorf("report jobs: %v", err)
+					}
+					if !ran {
+						break
+					}
+				}
+			}
+		}()
+	}
+}
+
+/**
+ * RunReportJob claims the oldest queued job and generates it
+ *
+ * @param db - Database connection
+ * @param now - Reference time
+ * @return bool - Whether a job was claimed
+ * @return error - Why the outcome of the job could not be recorded
+ */
+func RunReportJob(db *pop.Connection, now time.Time) (bool, error) {
+	claimed := []models.ReportJob{}
+	err := db.RawQuery(`
+		UPDATE report_jobs SET status = ?, progress = 0, started_at = ?, updated_at = ?
+		WHERE id = (
+			SELECT id FROM report_jobs
+			WHERE status = ?
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *
+	`, models.ReportJobRunning, now.UTC(), now.UTC(), models.ReportJobQueued).All(&claimed)
+	if err != nil || len(claimed) == 0 {
+		return false, err
+	}
+	job := claimed[0]
+
+	timeout := reportJobTimeout()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- generateReportJob(ctx, db, job) }()
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("timed out after %s", timeout)
+		// Hold the worker until the generation notices and stops, so
+		// timed-out jobs cannot pile up beyond REPORT_JOB_WORKERS
+		defer func() { <-done }()
+	}
+	if err != nil {
+		return true, withRequestID(failReportJob(db, job, err, time.Now()), job.RequestID)
+	}
+	return true, nil
+}
+
+/**
+ * generateReportJob builds a job's report, stores the file and marks the
+ * job completed
+ */
+func generateReportJob(ctx context.Context, db *pop.Connection, job models.ReportJob) error {
+	conn := db.WithContext(ctx)
+	progress := func(percent int) {
+		conn.RawQuery("UPDATE report_jobs SET progress = ?, updated_at = ? WHERE id = ? AND status = ?",
+			percent, time.Now().UTC(), job.ID, models.ReportJobRunning).Exec()
+	}
+
+	var owner models.User
+	if err := conn.Find(&owner, job.UserID); err != nil {
+		return err
+	}
+	if err := reportOwnerActive(owner); err != nil {
+		return err
+	}
+	viewAll := true
+	if job.TeamID.Valid {
+		var err error
+		if viewAll, err = teamReportAccess(conn, job.TeamID.UUID, job.UserID); err != nil {
+			return err
+		}
+	}
+	loc, err := time.LoadLocation(job.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	prefs, _, err := loadPreferences(conn, job.UserID)
+	if err != nil {
+		return err
+	}
+	req := reports.Request{
+		Template: job.Template,
+		Title:    job.Title,
+		Config:   job.Config,
+		From:     job.PeriodFrom.In(loc),
+		To:       job.PeriodTo.In(loc),
+		Location: loc,
+		Team:     job.TeamID.Valid,
+		Locale:   prefs.Locale,
+	}
+	if req.Budgets, err = reportBudgets(conn, job.UserID, job.TeamID, req); err != nil {
+		return err
+	}
+	src := reportEntrySource(conn, job.UserID, job.TeamID, viewAll, reportAsyncMaxEntries)
+	doc, err := reports.GenerateReport(func(from, to time.Time) ([]reports.Entry, error) {
+		entries, err := src(from, to)
+		if err == nil {
+			progress(reportJobLoaded)
+		}
+		return entries, err
+	}, req)
+	if errors.Is(err, errReportTooLarge) {
+		return fmt.Errorf("report has more than %d entries; narrow the period", reportAsyncMaxEntries)
+	}
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	progress(reportJobBuilt)
+
+	var buf bytes.Buffer
+	render, _ := reportRenderer(job.Format)
+	if err := render(ctxWriter{ctx, &buf}, doc); err != nil {
+		return err
+	}
+	progress(reportJobRendered)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	artifact := models.ReportArtifact{
+		UserID:     job.UserID,
+		TeamID:     job.TeamID,
+		Source:     models.ReportArtifactJob,
+		Template:   job.Template,
+		Title:      doc.Title,
+		Format:     job.Format,
+		Filename:   reports.Filename(doc, job.Format),
+		PeriodFrom: job.PeriodFrom,
+		PeriodTo:   job.PeriodTo,
+		Timezone:   loc.String(),
+	}
+	if err := storeReportArtifact(conn, &artifact, buf.Bytes()); err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	n, err := conn.RawQuery(`
+		UPDATE report_jobs
+		SET status = ?, progress = 100, artifact_id = ?, error = NULL, finished_at = ?, expires_at = ?, updated_at = ?
+		WHERE id = ? AND status = ?
+	`, models.ReportJobCompleted, artifact.ID, now, now.Add(reportJobRetention), now, job.ID, models.ReportJobRunning).ExecWithCount()
+	if err != nil || n == 0 {
+		// Timed out meanwhile: the job is failed and keeps no file
+		if st, serr := photoStore(); serr == nil {
+			deleteReportArtifact(db, st, artifact)
+		}
+	}
+	return err
+}
+
+/**
+ * ctxWriter fails every write once ctx is done, so a renderer stops
+ * early when its job times out
+ */
+type ctxWriter struct {
+	ctx context.Context
+	w   io.Writer
+}
+
+func (w ctxWriter) Write(p []byte) (int, error) {
+	if err := w.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return w.w.Write(p)
+}
+
+/**
+ * failReportJob records why a running job failed
+ */
+func failReportJob(db *pop.Connection, job models.ReportJob, jobErr error, now time.Time) error {
+	return db.RawQuery(`
+		UPDATE report_jobs
+		SET status = ?, error = ?, finished_at = ?, expires_at = ?, updated_at = ?
+		WHERE id = ? AND status = ?
+	`, models.ReportJobFailed, runErrorText(jobErr), now.UTC(), now.Add(reportJobRetention).UTC(), now.UTC(),
+		job.ID, models.ReportJobRunning).Exec()
+}
+
+/**
+ * CleanupReportJobs fails jobs left running past their time limit, by
+ * an instance that stopped, and removes jobs past expires_at; their files
+ * are artifacts and expire on their own (CleanupReportArtifacts)
+ *
+ * @param db - Database connection
+ * @param now - Reference time
+ * @return int - Number of jobs removed
+ */
+func CleanupReportJobs(db *pop.Connection, now time.Time) (int, error) {
+	// A minute of grace lets the worker holding the job record the timeout
+	stale := now.Add(-reportJobTimeout() - time.Minute)
+	err := db.RawQuery(`
+		UPDATE report_jobs
+		SET status = ?, error = ?, finished_at = ?, expires_at = ?, updated_at = ?
+		WHERE status = ? AND started_at < ?
+	`, models.ReportJobFailed, "the worker running the report stopped", now.UTC(), now.Add(reportJobRetention).UTC(), now.UTC(),
+		models.ReportJobRunning, stale.UTC()).Exec()
+	if err != nil {
+		return 0, err
+	}
+
+	return db.RawQuery("DELETE FROM report_jobs WHERE expires_at <= ?", now.UTC()).ExecWithCount()
+}
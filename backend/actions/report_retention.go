@@ -0,0 +1,220 @@
+/**
+ * Report Retention - Removing Old Report Files, Jobs and Runs
+ *
+ * Stored reports and their delivery records are kept for a limited time:
+ * - Report artifacts (stored files) for REPORT_ARTIFACT_RETENTION_DAYS
+ *   after they were written, or the owner's report_retention_days
+ *   preference when set
+ * - Report runs (delivery records of scheduled reports) for
+ *   REPORT_RUN_RETENTION_DAYS after their run
+ * - Finished report jobs for reportJobRetention
+ *
+ * A cleanup running every minute removes what expired, a batch of each
+ * per pass, deleting artifacts' files with their rows. Removing an
+ * artifact invalidates the emailed links to it: their runs lose the file
+ * (the foreign key sets it to NULL) and the link answers 410. Previews
+ * are rendered per request and never stored, so they need no cleanup.
+ *
+ * The reports:cleanup grift runs the cleanup to completion, or with
+ * --dry-run lists what it would remove (PlanReportCleanup).
+ *
+ * Configuration:
+ * - REPORT_ARTIFACT_RETENTION_DAYS: 1–3650, default 90
+ * - REPORT_RUN_RETENTION_DAYS: 1–3650, default 365
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-22
+ */
+package actions
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"backend/models"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gofrs/uuid"
+)
+
+const (
+	// defaultReportArtifactRetention is how long report files are kept.
+	defaultReportArtifactRetention = 90 * 24 * time.Hour
+	// defaultReportRunRetention is how long delivery records are kept.
+	defaultReportRunRetention = 365 * 24 * time.Hour
+	// maxReportRetention bounds both retention settings.
+	maxReportRetention = 3650 * 24 * time.Hour
+	// reportRunCleanupBatch is the number of runs removed per pass.
+	reportRunCleanupBatch = 1000
+)
+
+// reportArtifactRetention reads REPORT_ARTIFACT_RETENTION_DAYS (1–3650,
+// default 90).
+func reportArtifactRetention() (time.Duration, error) {
+	d, err := parseTTL(os.Getenv("REPORT_ARTIFACT_RETENTION_DAYS"), 24*time.Hour, defaultReportArtifactRetention, 24*time.Hour, maxReportRetention)
+	if err != nil {
+		err = fmt.Errorf("REPORT_ARTIFACT_RETENTION_DAYS: %w", err)
+	}
+	return d, err
+}
+
+// reportRunRetention reads REPORT_RUN_RETENTION_DAYS (1–3650, default
+// 365).
+func reportRunRetention() (time.Duration, error) {
+	d, err := parseTTL(os.Getenv("REPORT_RUN_RETENTION_DAYS"), 24*time.Hour, defaultReportRunRetention, 24*time.Hour, maxReportRetention)
+	if err != nil {
+		err = fmt.Errorf("REPORT_RUN_RETENTION_DAYS: %w", err)
+	}
+	return d, err
+}
+
+/**
+ * userArtifactRetention returns how long a user's report files are kept:
+ * their report_retention_days preference, else the server's retention
+ */
+func userArtifactRetention(db *pop.Connection, uid uuid.UUID) (time.Duration, error) {
+	prefs, err := userPreferences(db, uid)
+	if err != nil {
+		return 0, err
+	}
+	if prefs.ReportRetentionDays > 0 {
+		return time.Duration(prefs.ReportRetentionDays) * 24 * time.Hour, nil
+	}
+	d, _ := reportArtifactRetention()
+	return d, nil
+}
+
+/**
+ * applyArtifactRetention moves the expiry of a user's stored reports to
+ * their current retention, counted from when each was written; the
+ * cleanup removes those now expired
+ */
+func applyArtifactRetention(db *pop.Connection, uid uuid.UUID) error {
+	d, err := userArtifactRetention(db, uid)
+	if err != nil {
+		return err
+	}
+	return db.RawQuery(`
+		UPDATE report_artifacts
+		SET expires_at = created_at + make_interval(secs => ?), updated_at = ?
+		WHERE user_id = ?
+	`, d.Seconds(), time.Now().UTC(), uid).Exec()
+}
+
+/**
+ * startReportCleanup starts removing expired report artifacts, jobs and
+ * runs
+ */
+func startReportCleanup(app *buffalo.App) {
+	_, artifactErr := reportArtifactRetention()
+	_, runErr := reportRunRetention()
+	if err := errors.Join(artifactErr, runErr); err != nil {
+		app.Logger.Errorf("report retention, using the defaults: %v", err)
+	}
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for range ticker.C {
+			now := time.Now()
+			if _, err := CleanupReportJobs(models.DB, now); err != nil {
+				app.Logger.Errorf("report jobs cleanup: %v", err)
+			}
+			if _, err := CleanupReportArtifacts(models.DB, now); err != nil {
+				app.Logger.Errorf("report artifacts cleanup: %v", err)
+			}
+			if _, err := CleanupReportRuns(models.DB, now); err != nil {
+				app.Logger.Errorf("report runs cleanup: %v", err)
+			}
+		}
+	}()
+}
+
+/**
+ * CleanupReportRuns removes a batch of report runs older than the run
+ * retention
+ *
+ * @param db - Database connection
+ * @param now - Reference time
+ * @return int - Number of runs removed
+ */
+func CleanupReportRuns(db *pop.Connection, now time.Time) (int, error) {
+	d, _ := reportRunRetention()
+	return db.RawQuery(`
+		DELETE FROM report_runs
+		WHERE id IN (SELECT id FROM report_runs WHERE run_at <= ? ORDER BY run_at LIMIT ?)
+	`, now.Add(-d).UTC(), reportRunCleanupBatch).ExecWithCount()
+}
+
+/**
+ * ReportCleanup is what a report cleanup removes
+ */
+type ReportCleanup struct {
+	Artifacts []models.ReportArtifact // Expired stored reports, removed with their files
+	Jobs      int                     // Finished report jobs past their expiry
+	Runs      int                     // Report runs older than the run retention
+}
+
+/**
+ * PlanReportCleanup lists what a cleanup at now would remove, without
+ * removing anything
+ *
+ * @param db - Database connection
+ * @param now - Reference time
+ * @return ReportCleanup - Artifacts, and the number of jobs and runs
+ */
+func PlanReportCleanup(db *pop.Connection, now time.Time) (ReportCleanup, error) {
+	var plan ReportCleanup
+	plan.Artifacts = []models.ReportArtifact{}
+	if err := db.Where("expires_at <= ?", now.UTC()).Order("expires_at").All(&plan.Artifacts); err != nil {
+		return plan, err
+	}
+	var err error
+	if plan.Jobs, err = db.Where("expires_at <= ?", now.UTC()).Count(&models.ReportJob{}); err != nil {
+		return plan, err
+	}
+	d, _ := reportRunRetention()
+	plan.Runs, err = db.Where("run_at <= ?", now.Add(-d).UTC()).Count(&models.ReportRun{})
+	return plan, err
+}
+
+/**
+ * CleanupReports runs the report cleanup until nothing is left to remove
+ *
+ * @param db - Database connection
+ * @param now - Reference time
+ * @return int - Number of artifacts removed
+ * @return int - Number of jobs removed
+ * @return int - Number of runs removed
+ */
+func CleanupReports(db *pop.Connection, now time.Time) (int, int, int, error) {
+	jobs, err := CleanupReportJobs(db, now)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	artifacts, runs := 0, 0
+	for {
+		n, err := CleanupReportArtifacts(db, now)
+		artifacts += n
+		if err != nil {
+			return artifacts, jobs, runs, err
+		}
+		if n < reportArtifactCleanupBatch {
+			break
+		}
+	}
+	for {
+		n, err := CleanupReportRuns(db, now)
+		runs += n
+		if err != nil {
+			return artifacts, jobs, runs, err
+		}
+		if n < reportRunCleanupBatch {
+			break
+		}
+	}
+	return artifacts, jobs, runs, nil
+}
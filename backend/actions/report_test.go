@@ -0,0 +1,443 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/models"
+	"backend/reports"
+	"backend/storage"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/gofrs/uuid"
+)
+
+func (as *ActionSuite) Test_GenerateReport() {
+	token := as.registerToken("report-gen@example.com")
+	day := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -2).Add(9 * time.Hour)
+	for i, project := range []string{"alpha", "beta", "alpha"} {
+		at := day.Add(time.Duration(i) * 2 * time.Hour)
+		res := as.authJSON(token, "/api/tracks/").Post(map[string]any{"project": project, "start_at": at, "end_at": at.Add(time.Hour)})
+		as.Equal(http.StatusCreated, res.Code)
+	}
+	payload := map[string]any{
+		"template": "project", "timezone": "UTC",
+		"from": day.Format("2006-01-02"), "to": day.Format("2006-01-02"),
+		"config": map[string]any{"include_details": true},
+	}
+
+	res := as.authJSON(token, "/api/reports/generate").Post(payload)
+	as.Equal(http.StatusOK, res.Code)
+	var body struct {
+		Data reports.Document `json:"data"`
+	}
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &body))
+	as.Equal(3, body.Data.Totals.EntryCount)
+	as.InDelta(3*3600, body.Data.Totals.TotalSeconds, 1)
+	as.Equal("By project", body.Data.Sections[0].Title)
+	as.Equal("alpha", body.Data.Sections[0].Table.Rows[0][0])
+	as.Len(body.Data.Sections, 3)
+
+	payload["format"] = "csv"
+	res = as.authJSON(token, "/api/reports/generate").Post(payload)
+	as.Equal(http.StatusOK, res.Code)
+	as.True(strings.HasPrefix(res.Header().Get("Content-Type"), "text/csv"))
+	as.Contains(res.Header().Get("Content-Disposition"), "project-report-")
+	as.Contains(res.Body.String(), "alpha")
+
+	payload["format"] = "pdf"
+	res = as.authJSON(token, "/api/reports/generate").Post(payload)
+	as.Equal(http.StatusOK, res.Code)
+	as.Equal("application/pdf", res.Header().Get("Content-Type"))
+	as.Contains(res.Header().Get("Content-Disposition"), ".pdf")
+	as.True(strings.HasPrefix(res.Body.String(), "%PDF-"))
+
+	payload["format"], payload["config"] = "json", map[string]any{"group_by": "planet"}
+	res = as.authJSON(token, "/api/reports/generate").Post(payload)
+	as.Equal(http.StatusUnprocessableEntity, res.Code)
+	as.Contains(res.Body.String(), "group_by")
+	payload["config"] = map[string]any{"group_by": "member"}
+	res = as.authJSON(token, "/api/reports/generate").Post(payload)
+	as.Equal(http.StatusUnprocessableEntity, res.Code)
+	as.Contains(res.Body.String(), "only available for team reports")
+
+	payload["config"] = map[string]any{"group_by": "tag"}
+	res = as.authJSON(token, "/api/reports/generate").Post(payload)
+	as.Equal(http.StatusOK, res.Code)
+	as.Contains(res.Body.String(), "count toward each of their tags")
+
+	// A period preset replaces from and to
+	res = as.authJSON(token, "/api/reports/generate").Post(map[string]any{
+		"timezone": "UTC", "config": map[string]any{"period": "last_n_days:3"},
+	})
+	as.Equal(http.StatusOK, res.Code)
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &body))
+	as.Equal(3, body.Data.Totals.EntryCount)
+	for _, config := range []map[string]any{{"period": "last_n_days:3"}, {"period": "next_week"}, {"week_start": "someday"}} {
+		res = as.authJSON(token, "/api/reports/generate").Post(map[string]any{
+			"from": day.Format("2006-01-02"), "config": config,
+		})
+		as.Equal(http.StatusUnprocessableEntity, res.Code)
+		as.Contains(res.Body.String(), `"config"`)
+	}
+
+	// Team reports need a membership
+	owner := as.userID(as.registerToken("report-gen-owner@example.com"))
+	team := as.teamFixture("Report Team", owner)
+	res = as.authJSON(token, "/api/reports/generate").Post(map[string]any{"team_id": team.ID})
+	as.Equal(http.StatusForbidden, res.Code)
+}
+
+func (as *ActionSuite) Test_ReportJobs() {
+	token := as.registerToken("report-jobs@example.com")
+	otherToken := as.registerToken("report-jobs-other@example.com")
+	day := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, -6, 0).Add(9 * time.Hour)
+	res := as.authJSON(token, "/api/tracks/").Post(map[string]any{"project": "alpha", "start_at": day, "end_at": day.Add(time.Hour)})
+	as.Equal(http.StatusCreated, res.Code)
+
+	// Payloads are checked before the job is queued
+	res = as.authJSON(token, "/api/reports/jobs").Post(map[string]any{"format": "docx"})
+	as.Equal(http.StatusUnprocessableEntity, res.Code)
+
+	var job struct {
+		Data models.ReportJob `json:"data"`
+	}
+	res = as.authJSON(token, "/api/reports/jobs").Post(map[string]any{
+		"template": "detailed", "format": "csv", "timezone": "UTC",
+		"from": day.AddDate(0, -1, 0).Format("2006-01-02"), "to": day.AddDate(0, 1, 0).Format("2006-01-02"),
+	})
+	as.Equal(http.StatusAccepted, res.Code)
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &job))
+	id := job.Data.ID
+	as.Equal(models.ReportJobQueued, job.Data.Status)
+	as.Empty(job.Data.DownloadURL)
+	as.Equal(http.StatusNotFound, as.authJSON(otherToken, "/api/reports/jobs/%s", id).Get().Code)
+
+	// The app's workers may take the job first; either way it completes
+	for i := 0; i < 50 && job.Data.Status != models.ReportJobCompleted; i++ {
+		_, err := RunReportJob(as.DB, time.Now())
+		as.NoError(err)
+		res = as.authJSON(token, "/api/reports/jobs/%s", id).Get()
+		as.Equal(http.StatusOK, res.Code)
+		as.NoError(json.Unmarshal(res.Body.Bytes(), &job))
+		as.NotEqual(models.ReportJobFailed, job.Data.Status, job.Data.Error.String)
+		time.Sleep(50 * time.Millisecond)
+	}
+	as.Equal(models.ReportJobCompleted, job.Data.Status)
+	as.Equal(100, job.Data.Progress)
+	as.True(job.Data.ArtifactID.Valid)
+	as.Equal("/api/reports/download/"+job.Data.ArtifactID.UUID.String(), job.Data.DownloadURL)
+
+	dl := as.HTML("%s", job.Data.DownloadURL)
+	dl.Headers["Authorization"] = "Bearer " + token
+	file := dl.Get()
+	as.Equal(http.StatusOK, file.Code)
+	as.Contains(file.Header().Get("Content-Type"), "text/csv")
+	as.Contains(file.Body.String(), "alpha")
+
+	// Expired jobs are removed; their file stays in the report history
+	n, err := CleanupReportJobs(as.DB, job.Data.ExpiresAt.Time.Add(time.Second))
+	as.NoError(err)
+	as.Equal(1, n)
+	as.Equal(http.StatusNotFound, as.authJSON(token, "/api/reports/jobs/%s", id).Get().Code)
+	as.Equal(http.StatusOK, dl.Get().Code)
+}
+
+func (as *ActionSuite) Test_ReportJobs_DisabledOwner() {
+	token := as.registerToken("report-jobs-disabled@example.com")
+	var job struct {
+		Data models.ReportJob `json:"data"`
+	}
+	res := as.authJSON(token, "/api/reports/jobs").Post(map[string]any{"template": "detailed", "format": "csv", "timezone": "UTC"})
+	as.Equal(http.StatusAccepted, res.Code)
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &job))
+	as.NoError(as.DB.RawQuery("UPDATE users SET disabled_at = ? WHERE email = ?",
+		time.Now().UTC(), "report-jobs-disabled@example.com").Exec())
+
+	// A job still queued when its owner is disabled is not generated
+	var stored models.ReportJob
+	for i := 0; i < 50; i++ {
+		_, err := RunReportJob(as.DB, time.Now())
+		as.NoError(err)
+		as.NoError(as.DB.Find(&stored, job.Data.ID))
+		if stored.Status != models.ReportJobQueued && stored.Status != models.ReportJobRunning {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	as.Equal(models.ReportJobFailed, stored.Status)
+	as.Contains(stored.Error.String, "owner account is disabled")
+	as.False(stored.ArtifactID.Valid)
+}
+
+func (as *ActionSuite) Test_ReportHistory() {
+	token := as.registerToken("report-history@example.com")
+	otherToken := as.registerToken("report-history-other@example.com")
+	owner := as.userID(token)
+	from := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	artifact := models.ReportArtifact{
+		UserID:     owner,
+		Source:     models.ReportArtifactJob,
+		Template:   "summary",
+		Title:      "Summary",
+		Format:     "csv",
+		Filename:   "summary-2025-09.csv",
+		PeriodFrom: from,
+		PeriodTo:   from.AddDate(0, 1, 0),
+		Timezone:   "UTC",
+	}
+	as.NoError(storeReportArtifact(as.DB, &artifact, []byte("project,hours\nalpha,1\n")))
+
+	var history struct {
+		Data struct {
+			Items []models.ReportArtifact `json:"items"`
+			Total int                     `json:"total"`
+		} `json:"data"`
+	}
+	res := as.authJSON(token, "/api/reports/history").Get()
+	as.Equal(http.StatusOK, res.Code)
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &history))
+	as.Equal(1, history.Data.Total)
+	as.Equal(artifact.ID, history.Data.Items[0].ID)
+	as.Equal(int64(22), history.Data.Items[0].Size)
+	as.Equal("/api/reports/download/"+artifact.ID.String(), history.Data.Items[0].DownloadURL)
+
+	res = as.authJSON(token, "/api/reports/history?source=scheduled").Get()
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &history))
+	as.Equal(0, history.Data.Total)
+	as.Equal(http.StatusBadRequest, as.authJSON(token, "/api/reports/history?source=other").Get().Code)
+
+	res = as.authJSON(otherToken, "/api/reports/history").Get()
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &history))
+	as.Equal(0, history.Data.Total)
+
+	// Only the owner downloads a report
+	dl := as.HTML("/api/reports/download/%s", artifact.ID)
+	dl.Headers["Authorization"] = "Bearer " + token
+	file := dl.Get()
+	as.Equal(http.StatusOK, file.Code)
+	as.Contains(file.Header().Get("Content-Type"), "text/csv")
+	as.Contains(file.Header().Get("Content-Disposition"), "summary-2025-09.csv")
+	as.Equal("project,hours\nalpha,1\n", file.Body.String())
+	other := as.HTML("/api/reports/download/%s", artifact.ID)
+	other.Headers["Authorization"] = "Bearer " + otherToken
+	as.Equal(http.StatusNotFound, other.Get().Code)
+
+	// Expired reports are removed with their files
+	n, err := CleanupReportArtifacts(as.DB, artifact.ExpiresAt.Add(-time.Second))
+	as.NoError(err)
+	as.Equal(0, n)
+	n, err = CleanupReportArtifacts(as.DB, artifact.ExpiresAt.Add(time.Second))
+	as.NoError(err)
+	as.Equal(1, n)
+	as.Equal(http.StatusNotFound, dl.Get().Code)
+	st, err := photoStore()
+	as.NoError(err)
+	_, err = st.Get(context.Background(), artifact.FileKey)
+	as.ErrorIs(err, storage.ErrNotFound)
+}
+
+func Test_ReportRetention_Env(t *testing.T) {
+	if d, err := reportArtifactRetention(); err != nil || d != 90*24*time.Hour {
+		t.Errorf("unset: got %v, %v", d, err)
+	}
+	t.Setenv("REPORT_ARTIFACT_RETENTION_DAYS", "7")
+	if d, err := reportArtifactRetention(); err != nil || d != 7*24*time.Hour {
+		t.Errorf("got %v, %v", d, err)
+	}
+	t.Setenv("REPORT_RUN_RETENTION_DAYS", "0")
+	if d, err := reportRunRetention(); err == nil || d != 365*24*time.Hour {
+		t.Errorf("got %v, %v", d, err)
+	}
+}
+
+func Test_CtxWriter(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var buf strings.Builder
+	w := ctxWriter{ctx, &buf}
+	if _, err := w.Write([]byte("a")); err != nil || buf.String() != "a" {
+		t.Fatalf("live context: %q, %v", buf.String(), err)
+	}
+	cancel()
+	if _, err := w.Write([]byte("b")); err != context.Canceled || buf.String() != "a" {
+		t.Errorf("done context: %q, %v", buf.String(), err)
+	}
+}
+
+func (as *ActionSuite) Test_ReportRetention() {
+	token := as.registerToken("report-retention@example.com")
+	owner := as.userID(token)
+	from := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	artifact := models.ReportArtifact{
+		UserID:     owner,
+		Source:     models.ReportArtifactJob,
+		Template:   "summary",
+		Title:      "Summary",
+		Format:     "csv",
+		Filename:   "summary-2025-09.csv",
+		PeriodFrom: from,
+		PeriodTo:   from.AddDate(0, 1, 0),
+		Timezone:   "UTC",
+	}
+	as.NoError(storeReportArtifact(as.DB, &artifact, []byte("project,hours\nalpha,1\n")))
+	as.WithinDuration(artifact.CreatedAt.Add(90*24*time.Hour), artifact.ExpiresAt, time.Second)
+
+	// The owner's preference shortens the retention of stored reports
+	res, err := as.authJSON(token, "/api/me/preferences").Do(http.MethodPatch, map[string]any{"report_retention_days": 400})
+	as.NoError(err)
+	as.Equal(http.StatusUnprocessableEntity, res.Code)
+	res, err = as.authJSON(token, "/api/me/preferences").Do(http.MethodPatch, map[string]any{"report_retention_days": 1})
+	as.NoError(err)
+	as.Equal(http.StatusOK, res.Code)
+	as.NoError(as.DB.Reload(&artifact))
+	as.WithinDuration(artifact.CreatedAt.Add(24*time.Hour), artifact.ExpiresAt, time.Second)
+
+	// A dry run lists what the cleanup then removes, runs after a year
+	var scheduled struct {
+		Data models.ScheduledReport `json:"data"`
+	}
+	res = as.authJSON(token, "/api/reports/scheduled").Post(map[string]any{"name": "Daily", "frequency": "daily", "timezone": "UTC"})
+	as.Equal(http.StatusCreated, res.Code)
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &scheduled))
+	at := artifact.ExpiresAt.Add(time.Second)
+	for _, runAt := range []time.Time{at.AddDate(-1, 0, -1), at.AddDate(0, -11, 0)} {
+		as.NoError(as.DB.Create(&models.ReportRun{
+			ID: uuid.Must(uuid.NewV4()), ScheduledReportID: scheduled.Data.ID, RunAt: runAt.UTC(), PeriodFrom: from,
+			PeriodTo: from.AddDate(0, 0, 1), Recipient: "report-retention@example.com", Status: models.ReportDeliverySent,
+		}))
+	}
+	plan, err := PlanReportCleanup(as.DB, at)
+	as.NoError(err)
+	as.Len(plan.Artifacts, 1)
+	as.Equal(artifact.ID, plan.Artifacts[0].ID)
+	as.Equal(1, plan.Runs)
+
+	artifacts, _, runs, err := CleanupReports(as.DB, at)
+	as.NoError(err)
+	as.Equal(1, artifacts)
+	as.Equal(1, runs)
+	plan, err = PlanReportCleanup(as.DB, at)
+	as.NoError(err)
+	as.Empty(plan.Artifacts)
+	as.Zero(plan.Runs)
+}
+
+func (as *ActionSuite) Test_PreviewReport() {
+	token := as.registerToken("report-preview@example.com")
+	outsider := as.registerToken("report-preview-outsider@example.com")
+	day := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -1).Add(9 * time.Hour)
+	for i, project := range []string{"alpha", "beta", "gamma"} {
+		at := day.Add(time.Duration(i) * time.Hour)
+		res := as.authJSON(token, "/api/tracks/").Post(map[string]any{"project": project, "start_at": at, "end_at": at.Add(30 * time.Minute)})
+		as.Equal(http.StatusCreated, res.Code)
+	}
+
+	res := as.authJSON(token, "/api/reports/preview").Post(map[string]any{"template": "project", "timezone": "UTC", "rows": 1})
+	as.Equal(http.StatusOK, res.Code)
+	var body struct {
+		Data reports.Document `json:"data"`
+	}
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &body))
+	as.True(body.Data.Truncated)
+	as.Equal(3, body.Data.Totals.EntryCount)
+	as.Len(body.Data.Sections[0].Table.Rows, 1)
+	as.True(body.Data.Sections[0].Table.Truncated)
+
+	// Bad configs are field errors
+	for field, payload := range map[string]map[string]any{
+		"config": {"config": map[string]any{"group_by": "planet"}},
+		"to":     {"from": "2025-03-10", "to": "2025-03-01"},
+		"rows":   {"rows": 1000},
+	} {
+		res = as.authJSON(token, "/api/preview").Post(payload)
+		as.Equal(http.StatusUnprocessableEntity, res.Code, field)
+		as.Contains(res.Body.String(), `"`+field+`"`)
+	}
+
+	// Team previews need an active membership
+	team := as.teamFixture("Preview Team", as.userID(token))
+	payload := map[string]any{"team_id": team.ID}
+	as.Equal(http.StatusOK, as.authJSON(token, "/api/reports/preview").Post(payload).Code)
+	as.Equal(http.StatusForbidden, as.authJSON(outsider, "/api/reports/preview").Post(payload).Code)
+}
+
+func (as *ActionSuite) Test_ReportCharts() {
+	ownerToken := as.registerToken("report-charts-owner@example.com")
+	owner := as.userID(ownerToken)
+	workerToken := as.registerToken("report-charts-worker@example.com")
+	worker := as.userID(workerToken)
+	outsiderToken := as.registerToken("report-charts-outsider@example.com")
+	team := as.teamFixture("Chart Team", owner)
+	as.projectFixture(team, "alpha")
+	m := as.inviteFixture(team, worker, owner, time.Now())
+	as.NoError(as.DB.RawQuery("UPDATE team_members SET status = 'active' WHERE id = ?", m.ID).Exec())
+	as.NoError(as.DB.Create(&models.MemberRate{
+		ID: uuid.Must(uuid.NewV4()), TeamID: team.ID, UserID: worker,
+		HourlyRateCents: nulls.NewInt(6000), Currency: nulls.NewString("EUR"),
+		EffectiveFrom: time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC),
+	}))
+	for _, start := range []string{"2025-09-02T08:00:00Z", "2025-09-04T08:00:00Z"} {
+		at, _ := time.Parse(time.RFC3339, start)
+		res := as.authJSON(workerToken, "/api/tracks").Post(map[string]any{
+			"project": "alpha", "team_id": team.ID, "start_at": at, "end_at": at.Add(90 * time.Minute),
+		})
+		as.Equal(http.StatusCreated, res.Code)
+	}
+
+	const period = "from=2025-09-01&to=2025-09-07&tz=UTC"
+	var chart struct {
+		Data reports.ChartData `json:"data"`
+	}
+	res := as.authJSON(ownerToken, "/api/reports/charts?team_id=%s&%s", team.ID, period).Get()
+	as.Equal(http.StatusOK, res.Code)
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &chart))
+	as.Equal("2025-09-01", chart.Data.Keys[0])
+	as.Equal([]float64{0, 1.5, 0, 1.5, 0, 0, 0}, chart.Data.Series[0].Values)
+
+	res = as.authJSON(ownerToken, "/api/reports/charts?metric=amount&group=member&team_id=%s&%s", team.ID, period).Get()
+	as.Equal(http.StatusOK, res.Code)
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &chart))
+	as.Equal([]string{"report-charts-worker@example.com"}, chart.Data.Labels)
+	as.Equal("EUR", chart.Data.Series[0].Name)
+	as.Equal([]float64{18000}, chart.Data.Series[0].Values)
+
+	// Amounts need manage_rates, team charts a membership
+	res = as.authJSON(workerToken, "/api/reports/charts?metric=amount&team_id=%s&%s", team.ID, period).Get()
+	as.Equal(http.StatusForbidden, res.Code)
+	res = as.authJSON(outsiderToken, "/api/reports/charts?team_id=%s&%s", team.ID, period).Get()
+	as.Equal(http.StatusForbidden, res.Code)
+
+	for field, query := range map[string]string{
+		"metric":     "metric=money",
+		"group":      "group=member",
+		"week_start": "group=week&week_start=someday",
+		"period":     "period=next_year",
+	} {
+		res = as.authJSON(ownerToken, "/api/reports/charts?%s", query).Get()
+		as.Equal(http.StatusUnprocessableEntity, res.Code, field)
+		as.Contains(res.Body.String(), `"`+field+`"`)
+	}
+
+	// More buckets than allowed is an error, not a cut-off chart
+	res = as.authJSON(ownerToken, "/api/reports/charts?from=2025-01-01&to=2025-12-31&tz=UTC").Get()
+	as.Equal(http.StatusUnprocessableEntity, res.Code)
+	as.Contains(res.Body.String(), "more than 200 groups")
+	res = as.authJSON(ownerToken, "/api/reports/charts?group=week&from=2025-01-01&to=2025-12-31&tz=UTC").Get()
+	as.Equal(http.StatusOK, res.Code)
+}
+
+func (as *ActionSuite) Test_ReportTemplates() {
+	token := as.registerToken("report-templates@example.com")
+	res := as.authJSON(token, "/api/reports/templates").Get()
+	as.Equal(http.StatusOK, res.Code)
+	as.Contains(res.Body.String(), "summary-template")
+
+	// The old path sends existing clients on
+	res = as.authJSON(token, "/api/templates").Get()
+	as.Equal(http.StatusPermanentRedirect, res.Code)
+	as.Equal("/api/reports/templates", res.Header().Get("Location"))
+}
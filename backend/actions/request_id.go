@@ -0,0 +1,203 @@
+/**
+ * Request IDs - Correlating Logs, Responses and Background Work
+ *
+ * Every request gets an ID: the client's X-Request-ID header when it sends
+ * one, else a new UUID. The ID
+ * - is stored on the context as "request_id" (requestIDOf)
+ * - is a field of every log line written through c.Logger() during the
+ *   request, and of its request log line (request_log.go)
+ * - is returned in the X-Request-ID response header
+ * - is added to JSON error bodies (status >= 400) as "request_id"
+ * - is stored with the webhook deliveries and report jobs the request
+ *   queues, so the workers' log lines name it and webhook POSTs carry it
+ *   in their own X-Request-ID header
+ *
+ * A client's ID is sanitized: only letters, digits, '-', '_' and '.' are
+ * kept, and at most requestIDMaxLen of them. An ID left empty is replaced
+ * by a new one.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-22
+ */
+package actions
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gofrs/uuid"
+)
+
+const (
+	// requestIDHeader carries the request ID in requests and responses.
+	requestIDHeader = "X-Request-ID"
+	// requestIDMaxLen bounds the length of a client's request ID.
+	requestIDMaxLen = 64
+)
+
+/**
+ * sanitizeRequestID keeps the letters, digits, '-', '_' and '.' of a
+ * client's request ID, at most requestIDMaxLen of them
+ */
+func sanitizeRequestID(raw string) string {
+	var b strings.Builder
+	for _, r := range raw {
+		if b.Len() == requestIDMaxLen {
+			break
+		}
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+/**
+ * requestIDOf returns the ID of the request, "" outside of one
+ */
+func requestIDOf(c buffalo.Context) string {
+	id, _ := c.Value("request_id").(string)
+	return id
+}
+
+/**
+ * withRequestID names a request in an error of background work queued by
+ * it
+ */
+func withRequestID(err error, requestID string) error {
+	if err == nil || requestID == "" {
+		return err
+	}
+	return fmt.Errorf("%w (request %s)", err, requestID)
+}
+
+/**
+ * requestIDWriter holds back JSON error bodies to add the request ID to
+ * them. The status of an error is held back until the body is written,
+ * as error handlers may set the Content-Type after it.
+ */
+type requestIDWriter struct {
+	http.ResponseWriter
+	id     string
+	status int           // Error status not written yet, 0 for none
+	buf    *bytes.Buffer // Held back error body, nil for other responses
+}
+
+func (w *requestIDWriter) WriteHeader(code int) {
+	if code >= http.StatusBadRequest {
+		w.status = code
+		return
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+/**
+ * hold decides, once the body of an error is written, whether to hold it
+ * back: only JSON bodies are
+ */
+func (w *requestIDWriter) hold() {
+	if w.status == 0 || w.buf != nil {
+		return
+	}
+	if strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+		w.buf = &bytes.Buffer{}
+		return
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	w.status = 0
+}
+
+func (w *requestIDWriter) Write(b []byte) (int, error) {
+	w.hold()
+	if w.buf != nil {
+		return w.buf.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush keeps streamed responses (the track events stream) working.
+func (w *requestIDWriter) Flush() {
+	w.hold()
+	if f, ok := w.ResponseWriter.(http.Flusher); ok && w.buf == nil {
+		f.Flush()
+	}
+}
+
+/**
+ * finish writes a held back error status, and body with the request ID
+ *
+ * @return int - Bytes added to the body
+ */
+func (w *requestIDWriter) finish() int {
+	if w.status == 0 {
+		return 0
+	}
+	status := w.status
+	w.status = 0
+	if w.buf == nil {
+		w.ResponseWriter.WriteHeader(status)
+		return 0
+	}
+	held := w.buf.Len()
+	body := addRequestID(w.buf.Bytes(), w.id)
+	w.buf = nil
+	w.Header().Del("Content-Length")
+	w.ResponseWriter.WriteHeader(status)
+	w.ResponseWriter.Write(body)
+	return len(body) - held
+}
+
+/**
+ * addRequestID adds "request_id" as the first member of a JSON object;
+ * other bodies, and objects that have one, are returned unchanged
+ */
+func addRequestID(body []byte, id string) []byte {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil || fields == nil {
+		return body
+	}
+	if _, ok := fields["request_id"]; ok {
+		return body
+	}
+	member, _ := json.Marshal(id)
+	rest := bytes.TrimSpace(body)[1:]
+	out := append([]byte(`{"request_id":`), member...)
+	if len(fields) > 0 {
+		out = append(out, ',')
+	}
+	return append(out, rest...)
+}
+
+/**
+ * requestID assigns the request its ID (see the file comment). It wraps
+ * the whole middleware stack, Buffalo's error handling included (see
+ * App), so the ID is set for every other middleware.
+ */
+func requestID(next buffalo.Handler) buffalo.Handler {
+	return func(c buffalo.Context) error {
+		id := sanitizeRequestID(c.Request().Header.Get(requestIDHeader))
+		if id == "" {
+			id = uuid.Must(uuid.NewV4()).String()
+		}
+		c.Set("request_id", id)
+		c.LogField("request_id", id)
+		c.Response().Header().Set(requestIDHeader, id)
+
+		res, ok := c.Response().(*buffalo.Response)
+		if !ok {
+			return next(c)
+		}
+		w := &requestIDWriter{ResponseWriter: res.ResponseWriter, id: id}
+		res.ResponseWriter = w
+		defer func() { res.ResponseWriter = w.ResponseWriter }()
+		err := next(c)
+		res.Size += w.finish()
+		return err
+	}
+}
@@ -0,0 +1,98 @@
+package actions
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/models"
+
+	"github.com/gofrs/uuid"
+)
+
+func Test_SanitizeRequestID(t *testing.T) {
+	cases := map[string]string{
+		"abc-123_X.y":             "abc-123_X.y",
+		"  evil\r\nX-Injected: 1": "evilX-Injected1",
+		"<script>":                "script",
+		"ünïcode":                 "ncode",
+		"":                        "",
+		strings.Repeat("a", 100):  strings.Repeat("a", requestIDMaxLen),
+	}
+	for in, want := range cases {
+		if got := sanitizeRequestID(in); got != want {
+			t.Errorf("sanitizeRequestID(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func Test_AddRequestID(t *testing.T) {
+	cases := map[string]string{
+		`{"success":false,"message":"Nope"}`: `{"request_id":"r1","success":false,"message":"Nope"}`,
+		` {} `:                               `{"request_id":"r1"}`,
+		`{"request_id":"r0"}`:                `{"request_id":"r0"}`,
+		`["a"]`:                              `["a"]`,
+		`not json`:                           `not json`,
+	}
+	for in, want := range cases {
+		if got := string(addRequestID([]byte(in), "r1")); got != want {
+			t.Errorf("addRequestID(%s) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func (as *ActionSuite) Test_RequestID() {
+	// A client's ID round-trips
+	req := as.JSON("/")
+	req.Headers["X-Request-ID"] = "client-id.42"
+	res := req.Get()
+	as.Equal(http.StatusOK, res.Code)
+	as.Equal("client-id.42", res.Header().Get("X-Request-ID"))
+
+	// Unsafe characters are dropped and long IDs truncated
+	req = as.JSON("/")
+	req.Headers["X-Request-ID"] = "<id>" + strings.Repeat("x", 100)
+	res = req.Get()
+	as.Equal("id"+strings.Repeat("x", requestIDMaxLen-2), res.Header().Get("X-Request-ID"))
+
+	// Without one a UUID is generated
+	res = as.JSON("/").Get()
+	_, err := uuid.FromString(res.Header().Get("X-Request-ID"))
+	as.NoError(err)
+
+	// Error bodies carry the ID
+	req = as.JSON("/api/tracks/")
+	req.Headers["X-Request-ID"] = "failing-request"
+	res = req.Get()
+	as.Equal(http.StatusUnauthorized, res.Code)
+	var body map[string]any
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &body))
+	as.Equal("failing-request", body["request_id"])
+	as.Equal("failing-request", res.Header().Get("X-Request-ID"))
+}
+
+func (as *ActionSuite) Test_RequestID_QueuedWork() {
+	token := as.registerToken("request-id@example.com")
+	hook := as.authJSON(token, "/api/webhooks/").Post(map[string]any{"url": "https://example.com/hook", "events": []string{"track.started"}})
+	as.Equal(http.StatusCreated, hook.Code)
+
+	req := as.authJSON(token, "/api/tracks/start")
+	req.Headers["X-Request-ID"] = "start-request"
+	as.Equal(http.StatusCreated, req.Post(map[string]string{"project": "Web"}).Code)
+	var d models.WebhookDelivery
+	as.NoError(as.DB.Where("event = ?", "track.started").First(&d))
+	as.Equal("start-request", d.RequestID)
+
+	day := time.Now().UTC().Truncate(24 * time.Hour)
+	req = as.authJSON(token, "/api/reports/jobs")
+	req.Headers["X-Request-ID"] = "job-request"
+	res := req.Post(map[string]any{
+		"template": "summary", "format": "csv", "timezone": "UTC",
+		"from": day.AddDate(0, 0, -7).Format("2006-01-02"), "to": day.Format("2006-01-02"),
+	})
+	as.Equal(http.StatusAccepted, res.Code)
+	var job models.ReportJob
+	as.NoError(as.DB.Where("request_id = ?", "job-request").First(&job))
+}
@@ -0,0 +1,165 @@
+/**
+ * Request Log - One Structured Line per Request
+ *
+ * requestLogger replaces Buffalo's request logger and paramlogger: when a
+ * request is done it writes one line through logging.Default() holding
+ * - method, path (without the query), status, duration_ms and bytes
+ * - request_id (see request_id.go) and user_id when authenticated
+ * - params: query, route and form parameters, with sensitive ones
+ *   (logging.RedactedFields) redacted; files are named, not logged
+ *
+ * Requests answered 5xx are logged as errors, the others as info.
+ *
+ * buffaloLogger makes logging.Logger the app's Logger, so c.Logger() and
+ * app.Logger lines are structured too and carry the request's fields.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-22
+ */
+package actions
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"backend/logging"
+
+	"github.com/gobuffalo/buffalo"
+)
+
+/**
+ * requestLogger logs every request once it is done (see the file comment)
+ */
+func requestLogger(next buffalo.Handler) buffalo.Handler {
+	return func(c buffalo.Context) error {
+		start := time.Now()
+		err := next(c)
+
+		status, size := http.StatusOK, 0
+		if res, ok := c.Response().(*buffalo.Response); ok {
+			size = res.Size
+			if res.Status != 0 {
+				status = res.Status
+			}
+		}
+		if err != nil {
+			// An error Buffalo's error handlers failed to answer
+			status = http.StatusInternalServerError
+			var herr buffalo.HTTPError
+			if errors.As(err, &herr) {
+				status = herr.Status
+			}
+		}
+
+		req := c.Request()
+		fields := logging.Fields{
+			"method":      req.Method,
+			"path":        req.URL.Path,
+			"status":      status,
+			"duration_ms": float64(time.Since(start).Microseconds()) / 1000,
+			"bytes":       size,
+			"request_id":  requestIDOf(c),
+		}
+		if uid, ok := currentUserID(c); ok {
+			fields["user_id"] = uid.String()
+		}
+		if params := requestParams(req, c.Params()); len(params) > 0 {
+			fields["params"] = params
+		}
+		if err != nil {
+			fields["error"] = err.Error()
+		}
+		level := logging.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = logging.LevelError
+		}
+		logging.Default().Log(level, "request", fields)
+		return err
+	}
+}
+
+/**
+ * requestParams adds the fields of a multipart form, parsed by the
+ * handler, to the route, query and form parameters of a request; uploaded
+ * files are listed by name
+ */
+func requestParams(req *http.Request, params buffalo.ParamValues) url.Values {
+	out := url.Values{}
+	if p, ok := params.(url.Values); ok {
+		for k, vs := range p {
+			out[k] = append(out[k], vs...)
+		}
+	}
+	if mp := req.MultipartForm; mp != nil {
+		for k, vs := range mp.Value {
+			out[k] = append(out[k], vs...)
+		}
+		for k, files := range mp.File {
+			for _, f := range files {
+				out.Add(k, f.Filename)
+			}
+		}
+	}
+	return out
+}
+
+/**
+ * buffaloLogger adapts a logging.Logger to Buffalo's Logger
+ */
+type buffaloLogger struct {
+	l *logging.Logger
+}
+
+func (b buffaloLogger) WithField(key string, value any) buffalo.Logger {
+	return buffaloLogger{b.l.With(logging.Fields{key: value})}
+}
+
+func (b buffaloLogger) WithFields(fields map[string]any) buffalo.Logger {
+	return buffaloLogger{b.l.With(fields)}
+}
+
+func (b buffaloLogger) Debugf(format string, args ...any) {
+	b.l.Debug(fmt.Sprintf(format, args...))
+}
+
+func (b buffaloLogger) Infof(format string, args ...any) {
+	b.l.Info(fmt.Sprintf(format, args...))
+}
+
+func (b buffaloLogger) Printf(format string, args ...any) {
+	b.l.Info(fmt.Sprintf(format, args...))
+}
+
+func (b buffaloLogger) Warnf(format string, args ...any) {
+	b.l.Warn(fmt.Sprintf(format, args...))
+}
+
+func (b buffaloLogger) Errorf(format string, args ...any) {
+	b.l.Error(fmt.Sprintf(format, args...))
+}
+
+func (b buffaloLogger) Fatalf(format string, args ...any) {
+	b.l.Error(fmt.Sprintf(format, args...))
+	os.Exit(1)
+}
+
+func (b buffaloLogger) Debug(args ...any) { b.l.Debug(fmt.Sprint(args...)) }
+func (b buffaloLogger) Info(args ...any)  { b.l.Info(fmt.Sprint(args...)) }
+func (b buffaloLogger) Warn(args ...any)  { b.l.Warn(fmt.Sprint(args...)) }
+func (b buffaloLogger) Error(args ...any) { b.l.Error(fmt.Sprint(args...)) }
+
+func (b buffaloLogger) Fatal(args ...any) {
+	b.l.Error(fmt.Sprint(args...))
+	os.Exit(1)
+}
+
+func (b buffaloLogger) Panic(args ...any) {
+	msg := fmt.Sprint(args...)
+	b.l.Error(msg)
+	panic(msg)
+}
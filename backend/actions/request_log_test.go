@@ -0,0 +1,67 @@
+package actions
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"backend/logging"
+	"backend/models"
+)
+
+// requestLines returns the request log lines written to buf.
+func requestLines(buf *bytes.Buffer) []map[string]any {
+	var lines []map[string]any
+	for _, s := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var line map[string]any
+		if json.Unmarshal([]byte(s), &line) == nil && line["msg"] == "request" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func (as *ActionSuite) Test_RequestLog() {
+	token := as.registerToken("request-log@example.com")
+	var user models.User
+	as.NoError(as.DB.Where("email = ?", "request-log@example.com").First(&user))
+
+	var buf bytes.Buffer
+	prev := logging.Default()
+	logging.SetDefault(logging.New(&buf, logging.LevelInfo, logging.FormatJSON))
+	defer logging.SetDefault(prev)
+
+	req := as.authJSON(token, "/api/tracks/?project=Web&access_token=secret-1&code=secret-2&signature=secret-3&client_secret=secret-4")
+	req.Headers["X-Request-ID"] = "logged-request"
+	res := req.Get()
+	as.Equal(http.StatusOK, res.Code)
+
+	lines := requestLines(&buf)
+	as.Len(lines, 1, buf.String())
+	line := lines[0]
+	as.Equal("GET", line["method"])
+	as.Equal("/api/tracks/", line["path"])
+	as.Equal(float64(http.StatusOK), line["status"])
+	as.Equal(float64(res.Body.Len()), line["bytes"])
+	as.Equal("logged-request", line["request_id"])
+	as.Equal(user.ID.String(), line["user_id"])
+	as.Contains(line, "duration_ms")
+	as.Equal(map[string]any{
+		"project":       []any{"Web"},
+		"access_token":  []any{logging.Redacted},
+		"code":          []any{logging.Redacted},
+		"signature":     []any{logging.Redacted},
+		"client_secret": []any{logging.Redacted},
+	}, line["params"])
+	as.NotContains(buf.String(), "secret-")
+
+	// Anonymous requests have no user; error bodies are counted with the ID
+	buf.Reset()
+	res = as.JSON("/api/tracks/").Get()
+	as.Equal(http.StatusUnauthorized, res.Code)
+	lines = requestLines(&buf)
+	as.Len(lines, 1, buf.String())
+	as.NotContains(lines[0], "user_id")
+	as.Equal(float64(res.Body.Len()), lines[0]["bytes"])
+}
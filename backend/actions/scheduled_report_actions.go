@@ -0,0 +1,435 @@
+/**
+ * Scheduled Report Actions - Reports Produced on a Schedule
+ *
+ * This file provides CRUD for a user's scheduled reports under
+ * /api/reports/scheduled (GET and POST /api/scheduled remain for older
+ * clients). A report covers the user's own entries or, with team_id, a
+ * team they are an active member of, and runs daily, weekly or monthly
+ * at a local time (see models.ScheduledReport). next_run_at is computed
+ * whenever the schedule changes or the report is resumed, and cleared
+ * while it is paused. The deliveries of past runs are listed under
+ * /api/reports/scheduled/{id}/runs.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-22
+ */
+package actions
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"backend/models"
+	"backend/validators"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/nulls"
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gofrs/uuid"
+)
+
+// scheduledReportsPageMax bounds per_page when listing scheduled reports.
+const scheduledReportsPageMax = 100
+
+/**
+ * ScheduledReportRequest is the payload for creating or changing a
+ * scheduled report; omitted fields are left unchanged on PATCH
+ */
+type ScheduledReportRequest struct {
+	Name       *string              `json:"name"`
+	TeamID     *uuid.UUID           `json:"team_id"` // POST only
+	Template   *string              `json:"template"`
+	Config     *models.ReportConfig `json:"config"`
+	Frequency  *string              `json:"frequency"` // Clears weekday and day_of_month unless given too
+	Weekday    *int                 `json:"weekday"`
+	DayOfMonth *int                 `json:"day_of_month"`
+	TimeOfDay  *string              `json:"time_of_day"`
+	Timezone   *string              `json:"timezone"`
+	Recipients *[]string            `json:"recipients"`
+	IsActive   *bool                `json:"is_active"`
+}
+
+/**
+ * apply validates the request and copies it onto s
+ *
+ * @return string - Payload field at fault, "" when valid
+ * @return error - Validation error
+ */
+func (req ScheduledReportRequest) apply(s *models.ScheduledReport) (string, error) {
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" || utf8.RuneCountInString(name) > 100 {
+			return "name", errors.New("must be 1 to 100 characters")
+		}
+		s.Name = name
+	}
+	if req.Template != nil {
+		if !models.ValidTemplate(*req.Template) {
+			return "template", errors.New("must be summary, project or detailed")
+		}
+		s.Template = *req.Template
+	}
+	if req.Config != nil {
+		if err := req.Config.ValidateFor(s.TeamID.Valid); err != nil {
+			return "config", err
+		}
+		s.Config = *req.Config
+	}
+	if req.Frequency != nil {
+		s.Frequency = strings.TrimSpace(*req.Frequency)
+		s.Weekday, s.DayOfMonth = nulls.Int{}, nulls.Int{}
+	}
+	if req.Weekday != nil {
+		s.Weekday = nulls.NewInt(*req.Weekday)
+	}
+	if req.DayOfMonth != nil {
+		s.DayOfMonth = nulls.NewInt(*req.DayOfMonth)
+	}
+	if req.TimeOfDay != nil {
+		s.TimeOfDay = strings.TrimSpace(*req.TimeOfDay)
+	}
+	if req.Timezone != nil {
+		s.Timezone = strings.TrimSpace(*req.Timezone)
+	}
+	if field, err := s.ValidateSchedule(); err != nil {
+		return field, err
+	}
+	if req.Recipients != nil {
+		list := []string{}
+		for _, addr := range *req.Recipients {
+			email, err := validators.NormalizeEmail(addr)
+			if err != nil {
+				return "recipients", fmt.Errorf("%q is not a valid email address", addr)
+			}
+			if !slices.Contains(list, email) {
+				list = append(list, email)
+			}
+		}
+		if len(list) > models.MaxReportRecipients {
+			return "recipients", fmt.Errorf("must list at most %d addresses", models.MaxReportRecipients)
+		}
+		s.Recipients = list
+	}
+	if req.IsActive != nil {
+		s.IsActive = *req.IsActive
+	}
+	return "", nil
+}
+
+/**
+ * scheduleReport sets next_run_at from now when the schedule or the
+ * active flag differ from before, or no run is planned yet
+ */
+func scheduleReport(s *models.ScheduledReport, before models.ScheduledReport, now time.Time) {
+	if !s.IsActive {
+		s.NextRunAt = nulls.Time{}
+		return
+	}
+	if s.NextRunAt.Valid && before.IsActive && s.Frequency == before.Frequency && s.Weekday == before.Weekday &&
+		s.DayOfMonth == before.DayOfMonth && s.TimeOfDay == before.TimeOfDay && s.Timezone == before.Timezone {
+		return
+	}
+	s.NextRunAt = nulls.NewTime(s.NextRun(now))
+}
+
+/**
+ * findScheduledReport loads one of the caller's scheduled reports by the
+ * id parameter; when it fails the returned status and message describe
+ * the error response
+ */
+func findScheduledReport(c buffalo.Context, tx *pop.Connection) (models.ScheduledReport, int, string) {
+	var s models.ScheduledReport
+	id, err := uuid.FromString(c.Param("id"))
+	if err != nil {
+		return s, http.StatusBadRequest, "Invalid report ID"
+	}
+	uid, ok := currentUserID(c)
+	if !ok {
+		return s, http.StatusUnauthorized, "Unauthorized"
+	}
+	if err := tx.Where("id = ? AND user_id = ?", id, uid).First(&s); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return s, http.StatusNotFound, "Scheduled report not found"
+		}
+		return s, http.StatusInternalServerError, "Failed to load scheduled report"
+	}
+	return s, 0, ""
+}
+
+/**
+ * GetScheduledReports lists the caller's scheduled reports, newest first
+ * GET /api/reports/scheduled
+ *
+ * Query parameters: page (default 1), per_page (default 50, up to 100).
+ * The data holds items, page, per_page and total.
+ */
+func GetScheduledReports(c buffalo.Context) error {
+	uid, ok := currentUserID(c)
+	if !ok {
+		return renderTeamError(c, http.StatusUnauthorized, "Unauthorized")
+	}
+	var err error
+	page, perPage := 1, 50
+	if v := c.Param("page"); v != "" {
+		if page, err = strconv.Atoi(v); err != nil || page < 1 {
+			return renderTeamError(c, http.StatusBadRequest, "Invalid page")
+		}
+	}
+	if v := c.Param("per_page"); v != "" {
+		if perPage, err = strconv.Atoi(v); err != nil || perPage < 1 || perPage > scheduledReportsPageMax {
+			return renderTeamError(c, http.StatusBadRequest, "Invalid per_page")
+		}
+	}
+
+	reports := []models.ScheduledReport{}
+	q := mustTx(c).Where("user_id = ?", uid).Order("created_at DESC, id").Paginate(page, perPage)
+	if err := q.All(&reports); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to retrieve scheduled reports",
+			"error":   err.Error(),
+		}))
+	}
+	return c.Render(http.StatusOK, r.JSON(map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"items":    reports,
+			"page":     page,
+			"per_page": perPage,
+			"total":    q.Paginator.TotalEntriesSize,
+		},
+		"message": "Scheduled reports retrieved successfully",
+	}))
+}
+
+/**
+ * GetScheduledReport returns one of the caller's scheduled reports
+ * GET /api/reports/scheduled/{id}
+ */
+func GetScheduledReport(c buffalo.Context) error {
+	s, status, msg := findScheduledReport(c, mustTx(c))
+	if status != 0 {
+		return renderTeamError(c, status, msg)
+	}
+	return c.Render(http.StatusOK, r.JSON(map[string]interface{}{
+		"success": true,
+		"data":    s,
+		"message": "Scheduled report retrieved successfully",
+	}))
+}
+
+/**
+ * CreateScheduledReport creates a scheduled report
+ * POST /api/reports/scheduled
+ *
+ * Payload: name and frequency (required); weekday (0 = Sunday to 6) for
+ * weekly and day_of_month (1 to 31, the last day in shorter months) for
+ * monthly reports; template (summary, project or detailed; default
+ * summary); config { projects, billable_only }; time_of_day (HH:MM,
+ * default 08:00); timezone (default the caller's timezone preference);
+ * recipients (up to 20 addresses, including the caller's own for
+ * personal reports and only active members for team reports; default
+ * the caller); is_active (default true); team_id (a team the caller is
+ * an active member of; 409 when it is archived).
+ */
+func CreateScheduledReport(c buffalo.Context) error {
+	uid, ok := currentUserID(c)
+	if !ok {
+		return renderTeamError(c, http.StatusUnauthorized, "Unauthorized")
+	}
+	var req ScheduledReportRequest
+	if err := c.Bind(&req); err != nil {
+		return renderTeamError(c, http.StatusBadRequest, "Invalid request data")
+	}
+	if req.Name == nil {
+		return renderFieldError(c, "name", errors.New("is required"))
+	}
+
+	tx := mustTx(c)
+	prefs, err := userPreferences(tx, uid)
+	if err != nil {
+		return renderTeamError(c, http.StatusInternalServerError, "Failed to load preferences")
+	}
+	now := time.Now().UTC()
+	s := models.ScheduledReport{
+		ID:         uuid.Must(uuid.NewV4()),
+		UserID:     uid,
+		Template:   "summary",
+		TimeOfDay:  "08:00",
+		Timezone:   prefs.Timezone,
+		Recipients: []string{},
+		IsActive:   true,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if req.TeamID != nil {
+		active, err := tx.Where("team_id = ? AND user_id = ? AND status = ?", *req.TeamID, uid, "active").
+			Exists(&models.TeamMember{})
+		var archived bool
+		if err == nil && active {
+			archived, err = teamArchived(tx, *req.TeamID)
+		}
+		if err != nil {
+			return renderTeamError(c, http.StatusInternalServerError, "Failed to create scheduled report")
+		}
+		if !active {
+			return renderFieldError(c, "team_id", errors.New("not an active member of this team"))
+		}
+		if archived {
+			return renderTeamError(c, http.StatusConflict, "Team is archived")
+		}
+		s.TeamID = nulls.NewUUID(*req.TeamID)
+	}
+	if field, err := req.apply(&s); err != nil {
+		return renderFieldError(c, field, err)
+	}
+	if problem, err := checkReportRecipients(tx, s); err != nil {
+		return renderTeamError(c, http.StatusInternalServerError, "Failed to create scheduled report")
+	} else if problem != "" {
+		return renderFieldError(c, "recipients", errors.New(problem))
+	}
+	scheduleReport(&s, models.ScheduledReport{}, now)
+
+	if err := tx.Create(&s); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to create scheduled report",
+			"error":   err.Error(),
+		}))
+	}
+	return c.Render(http.StatusCreated, r.JSON(map[string]interface{}{
+		"success": true,
+		"data":    s,
+		"message": "Scheduled report created successfully",
+	}))
+}
+
+/**
+ * UpdateScheduledReport changes one of the caller's scheduled reports
+ * PATCH /api/reports/scheduled/{id}
+ *
+ * Accepts the fields of CreateScheduledReport except team_id. Changing
+ * frequency drops weekday and day_of_month unless they are given too;
+ * is_active false pauses the report.
+ */
+func UpdateScheduledReport(c buffalo.Context) error {
+	tx := mustTx(c)
+	s, status, msg := findScheduledReport(c, tx)
+	if status != 0 {
+		return renderTeamError(c, status, msg)
+	}
+	var req ScheduledReportRequest
+	if err := c.Bind(&req); err != nil {
+		return renderTeamError(c, http.StatusBadRequest, "Invalid request data")
+	}
+	if req.TeamID != nil && (!s.TeamID.Valid || *req.TeamID != s.TeamID.UUID) {
+		return renderFieldError(c, "team_id", errors.New("cannot be changed"))
+	}
+
+	before := s
+	if field, err := req.apply(&s); err != nil {
+		return renderFieldError(c, field, err)
+	}
+	if req.Recipients != nil {
+		if problem, err := checkReportRecipients(tx, s); err != nil {
+			return renderTeamError(c, http.StatusInternalServerError, "Failed to update scheduled report")
+		} else if problem != "" {
+			return renderFieldError(c, "recipients", errors.New(problem))
+		}
+	}
+	now := time.Now().UTC()
+	scheduleReport(&s, before, now)
+	s.UpdatedAt = now
+	// The run state belongs to the runner, which may hold the report right now
+	if err := tx.Update(&s, "locked_at", "last_run_at", "last_status", "last_error", "failure_count"); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to update scheduled report",
+			"error":   err.Error(),
+		}))
+	}
+	return c.Render(http.StatusOK, r.JSON(map[string]interface{}{
+		"success": true,
+		"data":    s,
+		"message": "Scheduled report updated successfully",
+	}))
+}
+
+/**
+ * GetScheduledReportRuns lists the deliveries of a scheduled report, one
+ * per run and recipient, newest first
+ * GET /api/reports/scheduled/{id}/runs
+ *
+ * Query parameters: page (default 1), per_page (default 50, up to 100).
+ * The data holds items, page, per_page and total.
+ */
+func GetScheduledReportRuns(c buffalo.Context) error {
+	tx := mustTx(c)
+	s, status, msg := findScheduledReport(c, tx)
+	if status != 0 {
+		return renderTeamError(c, status, msg)
+	}
+	var err error
+	page, perPage := 1, 50
+	if v := c.Param("page"); v != "" {
+		if page, err = strconv.Atoi(v); err != nil || page < 1 {
+			return renderTeamError(c, http.StatusBadRequest, "Invalid page")
+		}
+	}
+	if v := c.Param("per_page"); v != "" {
+		if perPage, err = strconv.Atoi(v); err != nil || perPage < 1 || perPage > scheduledReportsPageMax {
+			return renderTeamError(c, http.StatusBadRequest, "Invalid per_page")
+		}
+	}
+
+	runs := []models.ReportRun{}
+	q := tx.Where("scheduled_report_id = ?", s.ID).Order("run_at DESC, recipient").Paginate(page, perPage)
+	if err := q.All(&runs); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to retrieve report runs",
+			"error":   err.Error(),
+		}))
+	}
+	return c.Render(http.StatusOK, r.JSON(map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"items":    runs,
+			"page":     page,
+			"per_page": perPage,
+			"total":    q.Paginator.TotalEntriesSize,
+		},
+		"message": "Report runs retrieved successfully",
+	}))
+}
+
+/**
+ * DeleteScheduledReport deletes one of the caller's scheduled reports
+ * DELETE /api/reports/scheduled/{id}
+ */
+func DeleteScheduledReport(c buffalo.Context) error {
+	tx := mustTx(c)
+	s, status, msg := findScheduledReport(c, tx)
+	if status != 0 {
+		return renderTeamError(c, status, msg)
+	}
+	if err := tx.Destroy(&s); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to delete scheduled report",
+			"error":   err.Error(),
+		}))
+	}
+	return c.Render(http.StatusOK, r.JSON(map[string]interface{}{
+		"success": true,
+		"message": "Scheduled report deleted successfully",
+	}))
+}
@@ -0,0 +1,243 @@
+/**
+ * Scheduled Report Delivery - Emailing Report Runs
+ *
+ * A run of a scheduled report is emailed to each recipient separately:
+ * the summary of the period inline (reports.RenderSummary) and the PDF
+ * attached, in the report's locale (the report.mail.body translation).
+ * When the PDF is larger than REPORT_ATTACHMENT_MAX_BYTES the email
+ * carries a signed link to the run's report artifact instead, valid for
+ * reportLinkExpiry or until the file expires if that is sooner. Every
+ * delivery is recorded in report_runs, which GET
+ * /api/reports/scheduled/{id}/runs lists.
+ *
+ * Recipients are limited so a report does not leak time data: a personal
+ * report's list must include its owner, and a team report only goes to
+ * active members of the team. Members who left since are skipped.
+ *
+ * Configuration:
+ * - REPORT_ATTACHMENT_MAX_BYTES: largest PDF attached (default 5 MiB)
+ * - REPORT_DOWNLOAD_URL: base of download links (default
+ *   http://localhost:3000/api/reports/runs)
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-22
+ */
+package actions
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/mailer"
+	"backend/models"
+	"backend/reports"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/envy"
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gofrs/uuid"
+)
+
+const (
+	// defaultReportAttachmentMax is the largest PDF attached by default.
+	defaultReportAttachmentMax = 5 << 20
+	// reportLinkExpiry is how long a report download link stays valid.
+	reportLinkExpiry = 7 * 24 * time.Hour
+)
+
+/**
+ * reportMail holds the values of the report.mail.body translation
+ */
+type reportMail struct {
+	Name     string
+	Team     string
+	Summary  string
+	Filename string
+	Link     string
+	Expires  string
+	Owner    string
+}
+
+/**
+ * emailRow is an address selected by a query
+ */
+type emailRow struct {
+	Email string `db:"email"`
+}
+
+/**
+ * reportAttachmentMax reads REPORT_ATTACHMENT_MAX_BYTES
+ */
+func reportAttachmentMax() int {
+	n, err := strconv.Atoi(envy.Get("REPORT_ATTACHMENT_MAX_BYTES", ""))
+	if err != nil || n <= 0 {
+		return defaultReportAttachmentMax
+	}
+	return n
+}
+
+/**
+ * reportLinkSignature signs a report run's download link
+ *
+ * @param id - Report run the link serves
+ * @param expires - Unix time the link expires at
+ * @return string - Hex HMAC-SHA256 keyed with the JWT secret
+ */
+func reportLinkSignature(id uuid.UUID, expires int64) string {
+	mac := hmac.New(sha256.New, jwtSecret())
+	fmt.Fprintf(mac, "report-run:%s:%d", id, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+/**
+ * reportDownloadURL returns the signed link to a report run's file
+ */
+func reportDownloadURL(id uuid.UUID, expires time.Time) string {
+	u, err := url.Parse(envy.Get("REPORT_DOWNLOAD_URL", "http://localhost:3000/api/reports/runs"))
+	if err != nil {
+		u = &url.URL{Path: "/api/reports/runs"}
+	}
+	u = u.JoinPath(id.String(), "download")
+	q := u.Query()
+	q.Set("expires", strconv.FormatInt(expires.Unix(), 10))
+	q.Set("signature", reportLinkSignature(id, expires.Unix()))
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+/**
+ * activeTeamEmails returns the addresses of a team's active members
+ */
+func activeTeamEmails(db *pop.Connection, teamID uuid.UUID) ([]string, error) {
+	rows := []emailRow{}
+	err := db.RawQuery(`
+		SELECT u.email
+		FROM team_members tm
+		JOIN users u ON u.id = tm.user_id
+		WHERE tm.team_id = ? AND tm.status = ?
+	`, teamID, "active").All(&rows)
+	if err != nil {
+		return nil, err
+	}
+	emails := make([]string, len(rows))
+	for i, row := range rows {
+		emails[i] = strings.ToLower(row.Email)
+	}
+	return emails, nil
+}
+
+/**
+ * checkReportRecipients applies the recipient rules to a report about to
+ * be saved: a personal report's list must include the owner, a team
+ * report's must only hold active members. An empty list goes to the
+ * owner.
+ *
+ * @return string - Why the list is not allowed, "" when it is
+ * @return error - Database error
+ */
+func checkReportRecipients(tx *pop.Connection, s models.ScheduledReport) (string, error) {
+	if len(s.Recipients) == 0 {
+		return "", nil
+	}
+	if s.TeamID.Valid {
+		members, err := activeTeamEmails(tx, s.TeamID.UUID)
+		if err != nil {
+			return "", err
+		}
+		for _, addr := range s.Recipients {
+			if !slices.Contains(members, addr) {
+				return fmt.Sprintf("%s is not an active member of the team", addr), nil
+			}
+		}
+		return "", nil
+	}
+	var owner models.User
+	if err := tx.Find(&owner, s.UserID); err != nil {
+		return "", err
+	}
+	if !slices.Contains(s.Recipients, strings.ToLower(owner.Email)) {
+		return "must include your own address " + owner.Email, nil
+	}
+	return "", nil
+}
+
+/**
+ * composeReportMail writes the email of a run to one recipient; a
+ * non-empty link replaces the attachment
+ */
+func composeReportMail(s models.ScheduledReport, f scheduledReportFile, link string, expires time.Time) (mailer.Message, error) {
+	var summary strings.Builder
+	if err := reports.RenderSummary(&summary, f.doc); err != nil {
+		return mailer.Message{}, err
+	}
+	data := reportMail{
+		Name:     s.Name,
+		Team:     f.team,
+		Summary:  summary.String(),
+		Filename: f.filename,
+		Link:     link,
+		Expires:  expires.In(f.doc.Location()).Format(reports.Translate(f.doc.Locale, "report.format.date_time", nil) + " MST"),
+		Owner:    f.owner,
+	}
+	msg := mailer.Message{
+		Subject: fmt.Sprintf("%s: %s", s.Name, f.doc.Period()),
+		Text:    reports.Translate(f.doc.Locale, "report.mail.body", data),
+	}
+	if link == "" {
+		msg.Attachments = []mailer.Attachment{{Filename: f.filename, ContentType: "application/pdf", Data: f.pdf}}
+	}
+	return msg, nil
+}
+
+/**
+ * DownloadReportRun serves the file behind a report email's link
+ * GET /api/reports/runs/{id}/download?expires=...&signature=...
+ *
+ * The link is its own authentication: recipients need no account.
+ *
+ * Responses:
+ * - 200 with the PDF, or a redirect to a signed storage URL (S3)
+ * - 403 when the signature is wrong or the link expired
+ * - 404 when the run has no file
+ * - 410 when the file expired or was removed (see report_retention.go)
+ */
+func DownloadReportRun(c buffalo.Context) error {
+	id, err := uuid.FromString(c.Param("id"))
+	if err != nil {
+		return renderTeamError(c, http.StatusBadRequest, "Invalid report run ID")
+	}
+	expires, err := strconv.ParseInt(c.Param("expires"), 10, 64)
+	if err != nil || time.Now().Unix() > expires ||
+		!hmac.Equal([]byte(c.Param("signature")), []byte(reportLinkSignature(id, expires))) {
+		return renderTeamError(c, http.StatusForbidden, "This download link is invalid or has expired")
+	}
+
+	var run models.ReportRun
+	if err := mustTx(c).Find(&run, id); err != nil {
+		return renderTeamError(c, http.StatusNotFound, "Report file not found")
+	}
+	if !run.ArtifactID.Valid {
+		// A link was sent for a file that has been removed since
+		if run.Delivery.String == models.ReportByLink {
+			return renderTeamError(c, http.StatusGone, "Report file has expired")
+		}
+		return renderTeamError(c, http.StatusNotFound, "Report file not found")
+	}
+	var a models.ReportArtifact
+	if err := mustTx(c).Find(&a, run.ArtifactID.UUID); err != nil {
+		return renderTeamError(c, http.StatusNotFound, "Report file not found")
+	}
+	if !a.ExpiresAt.After(time.Now()) {
+		return renderTeamError(c, http.StatusGone, "Report file has expired")
+	}
+	return serveReportArtifact(c, a)
+}
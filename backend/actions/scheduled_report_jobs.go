@@ -0,0 +1,413 @@
+/**
+ * Scheduled Report Jobs - Running Due Scheduled Reports
+ *
+ * A runner polls scheduled_reports every minute for active reports whose
+ * next_run_at has passed, generates each report over its last complete
+ * period (see models.ScheduledReport.Period) with package reports and
+ * emails it to its recipients, or to its owner when it has none (see
+ * scheduled_report_delivery.go). The PDF of each run is kept as a report
+ * artifact of the owner, listed in their report history.
+ *
+ * Team reports cover what the owner may see in the team: every visible
+ * entry with view_analytics, their own otherwise. A report whose team is
+ * archived, whose owner left the team, or whose owner's account is
+ * disabled or deactivated, is skipped until its next run.
+ *
+ * Rows are claimed by setting locked_at, so several app instances can run
+ * the worker; a claim older than scheduledReportLease is taken over. A
+ * failed run is recorded on the row (last_status, last_error,
+ * failure_count) and retried with models.ReportRetryBackoff while that
+ * comes before the next regular run and fewer than
+ * models.MaxReportAttempts runs in a row have failed. A retry only mails
+ * the recipients earlier attempts at the same period did not reach.
+ *
+ * Configuration:
+ * - SCHEDULED_REPORTS_POLL_SECONDS: poll interval (default 60), 0 disables
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-22
+ */
+package actions
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/models"
+	"backend/reports"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/envy"
+	"github.com/gobuffalo/nulls"
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gofrs/uuid"
+)
+
+const (
+	// scheduledReportBatchSize is the number of reports claimed per poll.
+	scheduledReportBatchSize = 10
+	// scheduledReportLease is how long a claim keeps other runners away.
+	scheduledReportLease = 10 * time.Minute
+)
+
+// reportSkippedError marks a report that cannot run in its current state.
+type reportSkippedError string
+
+func (e reportSkippedError) Error() string { return string(e) }
+
+/**
+ * reportOwnerActive refuses, as a reportSkippedError, to run reports for
+ * an account disabled by an admin or deactivated
+ */
+func reportOwnerActive(u models.User) error {
+	switch {
+	case u.DisabledAt.Valid:
+		return reportSkippedError("owner account is disabled")
+	case u.DeactivatedAt.Valid:
+		return reportSkippedError("owner account is deactivated")
+	}
+	return nil
+}
+
+/**
+ * startScheduledReportRunner starts polling for due scheduled reports
+ */
+func startScheduledReportRunner(app *buffalo.App) {
+	seconds, err := strconv.Atoi(envy.Get("SCHEDULED_REPORTS_POLL_SECONDS", "60"))
+	if err != nil || seconds <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(seconds) * time.Second)
+		defer ticker.Stop()
+		for range ticker.C {
+			n, err := RunScheduledReports(models.DB, time.Now())
+			if err != nil {
+				app.Logger.Errorf("scheduled reports: %v", err)
+			}
+			if n > 0 {
+				app.Logger.Infof("scheduled reports: ran %d reports", n)
+			}
+		}
+	}()
+}
+
+/**
+ * RunScheduledReports claims due reports and runs them one after another
+ *
+ * @param db - Database connection
+ * @param now - Reference time
+ * @return int - Number of reports run, including failed and skipped ones
+ */
+func RunScheduledReports(db *pop.Connection, now time.Time) (int, error) {
+	due := []models.ScheduledReport{}
+	err := db.RawQuery(`
+		UPDATE scheduled_reports SET locked_at = ?
+		WHERE id IN (
+			SELECT id FROM scheduled_reports
+			WHERE is_active AND next_run_at <= ? AND (locked_at IS NULL OR locked_at < ?)
+			ORDER BY next_run_at
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *
+	`, now.UTC(), now.UTC(), now.Add(-scheduledReportLease).UTC(), scheduledReportBatchSize).All(&due)
+	if err != nil {
+		return 0, err
+	}
+
+	var errs []error
+	for _, s := range due {
+		runErr := runScheduledReport(db, s, now)
+		if err := recordReportRun(db, s, runErr, time.Now()); err != nil {
+			errs = append(errs, fmt.Errorf("report %s: %w", s.ID, err))
+		}
+	}
+	return len(due), errors.Join(errs...)
+}
+
+/**
+ * runScheduledReport builds one report and mails it to each recipient
+ * that has not received this period's report yet, recording every
+ * delivery in report_runs
+ *
+ * @return error - reportSkippedError, or why the report was not delivered
+ */
+func runScheduledReport(db *pop.Connection, s models.ScheduledReport, now time.Time) error {
+	from, to := s.Period(now)
+	var owner models.User
+	if err := db.Find(&owner, s.UserID); err != nil {
+		return err
+	}
+	if err := reportOwnerActive(owner); err != nil {
+		return err
+	}
+	recipients := slices.Clone([]string(s.Recipients))
+	if len(recipients) == 0 {
+		recipients = []string{strings.ToLower(owner.Email)}
+	}
+	// A retry only goes to those an earlier attempt did not reach
+	done := []emailRow{}
+	err := db.RawQuery(`
+		SELECT recipient AS email FROM report_runs
+		WHERE scheduled_report_id = ? AND period_from = ? AND status = ?
+	`, s.ID, from.UTC(), models.ReportDeliverySent).All(&done)
+	if err != nil {
+		return err
+	}
+	recipients = slices.DeleteFunc(recipients, func(addr string) bool {
+		return slices.ContainsFunc(done, func(row emailRow) bool { return row.Email == addr })
+	})
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	record := func(run models.ReportRun) error {
+		run.ScheduledReportID = s.ID
+		run.RunAt, run.PeriodFrom, run.PeriodTo = now.UTC(), from.UTC(), to.UTC()
+		return db.Create(&run)
+	}
+	fail := func(runErr error) error {
+		status := models.ReportDeliveryFailed
+		var skipped reportSkippedError
+		if errors.As(runErr, &skipped) {
+			status = models.ReportDeliverySkipped
+		}
+		for _, addr := range recipients {
+			run := models.ReportRun{Recipient: addr, Status: status, Error: nulls.NewString(runErrorText(runErr))}
+			if err := record(run); err != nil {
+				return errors.Join(runErr, err)
+			}
+		}
+		return runErr
+	}
+
+	sender := mailSender
+	if sender == nil {
+		return fail(errors.New("mail is not configured"))
+	}
+	f, err := buildScheduledReport(db, s, now)
+	if err != nil {
+		return fail(err)
+	}
+	f.owner = owner.Email
+
+	// Members who left the team since the list was saved get nothing
+	var members []string
+	if s.TeamID.Valid {
+		if members, err = activeTeamEmails(db, s.TeamID.UUID); err != nil {
+			return fail(err)
+		}
+	}
+	artifact := models.ReportArtifact{
+		UserID:            s.UserID,
+		TeamID:            s.TeamID,
+		ScheduledReportID: nulls.NewUUID(s.ID),
+		Source:            models.ReportArtifactScheduled,
+		Template:          s.Template,
+		Title:             s.Name,
+		Format:            "pdf",
+		Filename:          f.filename,
+		PeriodFrom:        from.UTC(),
+		PeriodTo:          to.UTC(),
+		Timezone:          f.doc.Location().String(),
+	}
+	if err := storeReportArtifact(db, &artifact, f.pdf); err != nil {
+		return fail(fmt.Errorf("storing report: %w", err))
+	}
+	byLink := len(f.pdf) > reportAttachmentMax()
+
+	var failed []string
+	sent := 0
+	for _, addr := range recipients {
+		run := models.ReportRun{ID: uuid.Must(uuid.NewV4()), Recipient: addr, ArtifactID: nulls.NewUUID(artifact.ID)}
+		if s.TeamID.Valid && !slices.Contains(members, addr) {
+			run.Status = models.ReportDeliverySkipped
+			run.Error = nulls.NewString("not an active member of the team")
+			if err := record(run); err != nil {
+				return err
+			}
+			continue
+		}
+
+		link, delivery := "", models.ReportByAttachment
+		expires := now.Add(reportLinkExpiry)
+		if artifact.ExpiresAt.Before(expires) {
+			expires = artifact.ExpiresAt
+		}
+		if byLink {
+			link, delivery = reportDownloadURL(run.ID, expires), models.ReportByLink
+		}
+		msg, err := composeReportMail(s, f, link, expires)
+		if err == nil {
+			msg.To = addr
+			err = sender.Send(msg)
+		}
+		run.Delivery = nulls.NewString(delivery)
+		if err != nil {
+			run.Status, run.Error = models.ReportDeliveryFailed, nulls.NewString(runErrorText(err))
+			failed = append(failed, fmt.Sprintf("%s: %v", addr, err))
+		} else {
+			run.Status, run.SentAt = models.ReportDeliverySent, nulls.NewTime(time.Now().UTC())
+			sent++
+		}
+		if err := record(run); err != nil {
+			return err
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("not delivered to %s", strings.Join(failed, "; "))
+	}
+	if sent == 0 {
+		return reportSkippedError("no recipient is an active member of the team")
+	}
+	return nil
+}
+
+/**
+ * runErrorText returns an error message that fits the error columns
+ */
+func runErrorText(err error) string {
+	msg := strings.ToValidUTF8(err.Error(), "")
+	if len(msg) > 500 {
+		msg = strings.ToValidUTF8(msg[:500], "")
+	}
+	return msg
+}
+
+/**
+ * recordReportRun stores the outcome of a run, schedules the next run or
+ * retry and releases the claim
+ */
+func recordReportRun(db *pop.Connection, s models.ScheduledReport, runErr error, now time.Time) error {
+	next := s.NextRun(now)
+	var skipped reportSkippedError
+	switch {
+	case runErr == nil:
+		return db.RawQuery(`
+			UPDATE scheduled_reports
+			SET last_status = ?, last_error = NULL, failure_count = 0, last_run_at = ?, next_run_at = ?, locked_at = NULL, updated_at = ?
+			WHERE id = ?
+		`, models.ReportRunSucceeded, now.UTC(), next, now.UTC(), s.ID).Exec()
+	case errors.As(runErr, &skipped):
+		return db.RawQuery(`
+			UPDATE scheduled_reports
+			SET last_status = ?, last_error = ?, failure_count = 0, next_run_at = ?, locked_at = NULL, updated_at = ?
+			WHERE id = ?
+		`, models.ReportRunSkipped, string(skipped), next, now.UTC(), s.ID).Exec()
+	}
+
+	failures := s.Failures + 1
+	if retry := now.Add(models.ReportRetryBackoff(failures)); failures < models.MaxReportAttempts && retry.Before(next) {
+		next = retry
+	}
+	return db.RawQuery(`
+		UPDATE scheduled_reports
+		SET last_status = ?, last_error = ?, failure_count = ?, last_run_at = ?, next_run_at = ?, locked_at = NULL, updated_at = ?
+		WHERE id = ?
+	`, models.ReportRunFailed, runErrorText(runErr), failures, now.UTC(), next.UTC(), now.UTC(), s.ID).Exec()
+}
+
+/**
+ * scheduledReportAccess checks that a team report can run and returns
+ * whether its owner sees every visible entry of the team (view_analytics)
+ *
+ * @return error - reportSkippedError when the team cannot be reported on
+ */
+func scheduledReportAccess(db *pop.Connection, s models.ScheduledReport) (bool, error) {
+	if !s.TeamID.Valid {
+		return true, nil
+	}
+	archived, err := teamArchived(db, s.TeamID.UUID)
+	if err != nil {
+		return false, err
+	}
+	if archived {
+		return false, reportSkippedError("team is archived")
+	}
+	return teamReportAccess(db, s.TeamID.UUID, s.UserID)
+}
+
+/**
+ * teamReportAccess returns whether a user's report on a team covers every
+ * visible entry of the team (view_analytics) or only their own
+ *
+ * @return error - reportSkippedError when the user is not an active member
+ */
+func teamReportAccess(db *pop.Connection, teamID, userID uuid.UUID) (bool, error) {
+	var member models.TeamMember
+	err := db.Where("team_id = ? AND user_id = ? AND status = ?", teamID, userID, "active").First(&member)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, reportSkippedError("owner is no longer an active member of the team")
+	}
+	if err != nil {
+		return false, err
+	}
+	return member.HasPermission("view_analytics"), nil
+}
+
+/**
+ * scheduledReportFile is a generated run of a scheduled report
+ */
+type scheduledReportFile struct {
+	doc      *reports.Document
+	team     string // Team name, "" for personal reports
+	owner    string // Owner's address, named in the email
+	filename string
+	pdf      []byte
+}
+
+/**
+ * buildScheduledReport generates a report over its last complete period
+ * and renders it as PDF
+ */
+func buildScheduledReport(db *pop.Connection, s models.ScheduledReport, now time.Time) (scheduledReportFile, error) {
+	var f scheduledReportFile
+	viewAll, err := scheduledReportAccess(db, s)
+	if err != nil {
+		return f, err
+	}
+	prefs, _, err := loadPreferences(db, s.UserID)
+	if err != nil {
+		return f, err
+	}
+	from, to := s.Period(now)
+	req := reports.Request{
+		Template: s.Template,
+		Title:    s.Name,
+		Config:   s.Config,
+		From:     from,
+		To:       to,
+		Location: from.Location(),
+		Team:     s.TeamID.Valid,
+		Locale:   prefs.Locale,
+	}
+	if req.Budgets, err = reportBudgets(db, s.UserID, s.TeamID, req); err != nil {
+		return f, err
+	}
+	f.doc, err = reports.GenerateReport(reportEntrySource(db, s.UserID, s.TeamID, viewAll, 0), req)
+	if err != nil {
+		return f, err
+	}
+	if s.TeamID.Valid {
+		var team models.Team
+		if err := db.Find(&team, s.TeamID.UUID); err != nil {
+			return f, err
+		}
+		f.team = team.Name
+	}
+	var pdf bytes.Buffer
+	if err := reports.RenderPDF(&pdf, f.doc); err != nil {
+		return f, err
+	}
+	f.filename, f.pdf = reports.Filename(f.doc, "pdf"), pdf.Bytes()
+	return f, nil
+}
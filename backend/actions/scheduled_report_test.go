@@ -0,0 +1,293 @@
+package actions
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/mailer"
+	"backend/models"
+
+	"github.com/gobuffalo/envy"
+)
+
+func (as *ActionSuite) Test_ScheduledReports() {
+	token := as.registerToken("reports-owner@example.com")
+	otherToken := as.registerToken("reports-other@example.com")
+
+	var created struct {
+		Data models.ScheduledReport `json:"data"`
+	}
+	payload := map[string]any{
+		"name": "Weekly hours", "frequency": "weekly", "weekday": 1, "time_of_day": "09:30",
+		"timezone": "Europe/Berlin", "recipients": []string{"Boss@Example.com", "boss@example.com"},
+	}
+	// A personal report goes to its owner among others
+	res := as.authJSON(token, "/api/reports/scheduled").Post(payload)
+	as.Equal(http.StatusUnprocessableEntity, res.Code)
+	as.Contains(res.Body.String(), "reports-owner@example.com")
+	payload["recipients"] = []string{"Boss@Example.com", "boss@example.com", "reports-owner@example.com"}
+	res = as.authJSON(token, "/api/reports/scheduled").Post(payload)
+	as.Equal(http.StatusCreated, res.Code)
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &created))
+	report := created.Data
+	as.Equal("summary", report.Template)
+	as.Equal([]string{"boss@example.com", "reports-owner@example.com"}, []string(report.Recipients))
+	as.True(report.NextRunAt.Valid)
+
+	// Schedules are validated
+	res = as.authJSON(token, "/api/reports/scheduled").Post(map[string]any{"name": "Bad", "frequency": "weekly"})
+	as.Equal(http.StatusUnprocessableEntity, res.Code)
+	as.Contains(res.Body.String(), "weekday")
+
+	// The list holds the caller's reports only
+	var list struct {
+		Data struct {
+			Items []models.ScheduledReport `json:"items"`
+			Total int                      `json:"total"`
+		} `json:"data"`
+	}
+	res = as.authJSON(token, "/api/reports/scheduled?per_page=10").Get()
+	as.Equal(http.StatusOK, res.Code)
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &list))
+	as.Equal(1, list.Data.Total)
+	as.Equal(report.ID, list.Data.Items[0].ID)
+	res = as.authJSON(otherToken, "/api/reports/scheduled").Get()
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &list))
+	as.Equal(0, list.Data.Total)
+	as.Equal(http.StatusNotFound, as.authJSON(otherToken, "/api/reports/scheduled/%s", report.ID).Get().Code)
+
+	// Switching to monthly drops the weekday; pausing clears the next run
+	var updated struct {
+		Data models.ScheduledReport `json:"data"`
+	}
+	res = as.authJSON(token, "/api/reports/scheduled/%s", report.ID).Patch(map[string]any{"frequency": "monthly", "day_of_month": 31})
+	as.Equal(http.StatusOK, res.Code)
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &updated))
+	as.False(updated.Data.Weekday.Valid)
+	as.Equal(31, updated.Data.DayOfMonth.Int)
+	as.True(updated.Data.NextRunAt.Valid)
+	res = as.authJSON(token, "/api/reports/scheduled/%s", report.ID).Patch(map[string]any{"is_active": false})
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &updated))
+	as.False(updated.Data.NextRunAt.Valid)
+
+	as.Equal(http.StatusNotFound, as.authJSON(otherToken, "/api/reports/scheduled/%s", report.ID).Delete().Code)
+	as.Equal(http.StatusOK, as.authJSON(token, "/api/reports/scheduled/%s", report.ID).Delete().Code)
+	as.Equal(http.StatusNotFound, as.authJSON(token, "/api/reports/scheduled/%s", report.ID).Get().Code)
+}
+
+func (as *ActionSuite) Test_ScheduledReports_TeamRecipients() {
+	token := as.registerToken("reports-lead@example.com")
+	as.registerToken("reports-outsider@example.com")
+	team := as.teamFixture("Report Team", as.userID(token))
+
+	// Team reports only go to active members
+	payload := map[string]any{
+		"name": "Team hours", "team_id": team.ID, "frequency": "daily",
+		"recipients": []string{"reports-outsider@example.com"},
+	}
+	res := as.authJSON(token, "/api/reports/scheduled").Post(payload)
+	as.Equal(http.StatusUnprocessableEntity, res.Code)
+	as.Contains(res.Body.String(), "not an active member")
+	payload["recipients"] = []string{"reports-lead@example.com"}
+	as.Equal(http.StatusCreated, as.authJSON(token, "/api/reports/scheduled").Post(payload).Code)
+}
+
+func (as *ActionSuite) Test_RunScheduledReports() {
+	token := as.registerToken("reports-runner@example.com")
+	yesterday := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -1).Add(10 * time.Hour)
+	res := as.authJSON(token, "/api/tracks/").Post(map[string]any{
+		"project": "alpha", "start_at": yesterday, "end_at": yesterday.Add(90 * time.Minute),
+	})
+	as.Equal(http.StatusCreated, res.Code)
+
+	var created struct {
+		Data models.ScheduledReport `json:"data"`
+	}
+	res = as.authJSON(token, "/api/reports/scheduled").Post(map[string]any{
+		"name": "Daily projects", "template": "project", "frequency": "daily", "timezone": "UTC",
+		"recipients": []string{"boss@example.com", "reports-runner@example.com"},
+	})
+	as.Equal(http.StatusCreated, res.Code)
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &created))
+	id := created.Data.ID
+	due := func() {
+		as.NoError(as.DB.RawQuery("UPDATE scheduled_reports SET next_run_at = ? WHERE id = ?", time.Now().Add(-time.Minute).UTC(), id).Exec())
+	}
+	runs := func() []models.ReportRun {
+		var list struct {
+			Data struct {
+				Items []models.ReportRun `json:"items"`
+			} `json:"data"`
+		}
+		res := as.authJSON(token, "/api/reports/scheduled/%s/runs", id).Get()
+		as.Equal(http.StatusOK, res.Code)
+		as.NoError(json.Unmarshal(res.Body.Bytes(), &list))
+		return list.Data.Items
+	}
+
+	sent := make(captureSender, 4)
+	prev := mailSender
+	mailSender = sent
+	defer func() { mailSender = prev }()
+
+	due()
+	now := time.Now()
+	n, err := RunScheduledReports(as.DB, now)
+	as.NoError(err)
+	as.Equal(1, n)
+	msg := <-sent
+	as.Equal("boss@example.com", msg.To)
+	as.Contains(msg.Text, "alpha")
+	as.Contains(msg.Text, "1h 30m")
+	as.Contains(msg.Text, "reports-runner@example.com scheduled this report")
+	as.Contains(msg.Text, "The full report is attached as daily-projects-")
+	as.Len(msg.Attachments, 1)
+	as.Equal("application/pdf", msg.Attachments[0].ContentType)
+	as.Equal("reports-runner@example.com", (<-sent).To)
+	list := runs()
+	as.Len(list, 2)
+	as.Equal(models.ReportDeliverySent, list[0].Status)
+	as.Equal(models.ReportByAttachment, list[0].Delivery.String)
+	as.True(list[0].ArtifactID.Valid)
+	as.Equal(list[0].ArtifactID, list[1].ArtifactID)
+	res = as.authJSON(token, "/api/reports/history?source=scheduled").Get()
+	as.Contains(res.Body.String(), list[0].ArtifactID.UUID.String())
+
+	var report models.ScheduledReport
+	as.NoError(as.DB.Find(&report, id))
+	as.Equal(models.ReportRunSucceeded, report.LastStatus.String)
+	as.False(report.LockedAt.Valid)
+	as.True(report.NextRunAt.Time.After(now))
+
+	// Nothing is due any more, and a period is only delivered once
+	n, err = RunScheduledReports(as.DB, now)
+	as.NoError(err)
+	as.Zero(n)
+	due()
+	_, err = RunScheduledReports(as.DB, time.Now())
+	as.NoError(err)
+	as.Len(sent, 0)
+	as.NoError(as.DB.RawQuery("DELETE FROM report_runs WHERE scheduled_report_id = ?", id).Exec())
+
+	// A failed delivery is recorded and retried after a backoff, for the
+	// recipient it failed for only
+	flaky := &flakySender{sent: make(chan mailer.Message, 2)}
+	mailSender = flaky
+	due()
+	now = time.Now()
+	_, err = RunScheduledReports(as.DB, now)
+	as.NoError(err)
+	as.NoError(as.DB.Find(&report, id))
+	as.Equal(models.ReportRunFailed, report.LastStatus.String)
+	as.Equal(1, report.Failures)
+	as.WithinDuration(now.Add(models.ReportRetryBackoff(1)), report.NextRunAt.Time, 5*time.Second)
+	as.Equal("reports-runner@example.com", (<-flaky.sent).To)
+
+	res = as.authJSON(token, "/api/reports/scheduled/%s", id).Get()
+	as.Contains(res.Body.String(), "connection refused")
+	list = runs()
+	as.Len(list, 2)
+	as.Equal("boss@example.com", list[0].Recipient)
+	as.Equal(models.ReportDeliveryFailed, list[0].Status)
+	as.Contains(list[0].Error.String, "connection refused")
+
+	due()
+	_, err = RunScheduledReports(as.DB, time.Now())
+	as.NoError(err)
+	as.Equal("boss@example.com", (<-flaky.sent).To)
+	as.Len(runs(), 3)
+}
+
+func (as *ActionSuite) Test_RunScheduledReports_InactiveOwner() {
+	token := as.registerToken("reports-disabled@example.com")
+	var created struct {
+		Data models.ScheduledReport `json:"data"`
+	}
+	res := as.authJSON(token, "/api/reports/scheduled").Post(map[string]any{
+		"name": "Weekly", "frequency": "daily", "timezone": "UTC",
+		"recipients": []string{"boss@example.com"},
+	})
+	as.Equal(http.StatusCreated, res.Code)
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &created))
+	id := created.Data.ID
+
+	sent := make(captureSender, 1)
+	prev := mailSender
+	mailSender = sent
+	defer func() { mailSender = prev }()
+
+	// Reports of a disabled or deactivated account are skipped, not sent
+	for _, column := range []string{"disabled_at", "deactivated_at"} {
+		as.NoError(as.DB.RawQuery("UPDATE users SET disabled_at = NULL, deactivated_at = NULL, "+column+" = ? WHERE email = ?",
+			time.Now().UTC(), "reports-disabled@example.com").Exec())
+		as.NoError(as.DB.RawQuery("UPDATE scheduled_reports SET next_run_at = ? WHERE id = ?",
+			time.Now().Add(-time.Minute).UTC(), id).Exec())
+		now := time.Now()
+		_, err := RunScheduledReports(as.DB, now)
+		as.NoError(err)
+		as.Len(sent, 0)
+		var report models.ScheduledReport
+		as.NoError(as.DB.Find(&report, id))
+		as.Equal(models.ReportRunSkipped, report.LastStatus.String)
+		as.Zero(report.Failures)
+		as.True(report.NextRunAt.Time.After(now))
+	}
+}
+
+func (as *ActionSuite) Test_RunScheduledReports_DownloadLink() {
+	token := as.registerToken("reports-link@example.com")
+	var created struct {
+		Data models.ScheduledReport `json:"data"`
+	}
+	res := as.authJSON(token, "/api/reports/scheduled").Post(map[string]any{
+		"name": "Big report", "frequency": "daily", "timezone": "UTC",
+	})
+	as.Equal(http.StatusCreated, res.Code)
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &created))
+	as.NoError(as.DB.RawQuery("UPDATE scheduled_reports SET next_run_at = ? WHERE id = ?",
+		time.Now().Add(-time.Minute).UTC(), created.Data.ID).Exec())
+
+	sent := make(captureSender, 1)
+	prev := mailSender
+	mailSender = sent
+	defer func() { mailSender = prev }()
+
+	// Files over the cap are linked instead of attached
+	envy.Temp(func() {
+		envy.Set("REPORT_ATTACHMENT_MAX_BYTES", "1")
+		_, err := RunScheduledReports(as.DB, time.Now())
+		as.NoError(err)
+	})
+	msg := <-sent
+	as.Equal("reports-link@example.com", msg.To)
+	as.Empty(msg.Attachments)
+	i := strings.Index(msg.Text, "http://")
+	as.NotEqual(-1, i)
+	link, err := url.Parse(strings.Fields(msg.Text[i:])[0])
+	as.NoError(err)
+
+	dl := as.HTML("%s", link.RequestURI()).Get()
+	as.Equal(http.StatusOK, dl.Code)
+	as.Equal("application/pdf", dl.Header().Get("Content-Type"))
+	as.True(strings.HasPrefix(dl.Body.String(), "%PDF-"))
+
+	q := link.Query()
+	q.Set("expires", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+	link.RawQuery = q.Encode()
+	as.Equal(http.StatusForbidden, as.HTML("%s", link.RequestURI()).Get().Code)
+
+	// Removing the file invalidates the link
+	link, _ = url.Parse(strings.Fields(msg.Text[i:])[0])
+	var run models.ReportRun
+	as.NoError(as.DB.Where("recipient = ?", "reports-link@example.com").First(&run))
+	var artifact models.ReportArtifact
+	as.NoError(as.DB.Find(&artifact, run.ArtifactID.UUID))
+	n, err := CleanupReportArtifacts(as.DB, artifact.ExpiresAt.Add(time.Second))
+	as.NoError(err)
+	as.Equal(1, n)
+	as.Equal(http.StatusGone, as.HTML("%s", link.RequestURI()).Get().Code)
+}
@@ -0,0 +1,301 @@
+/**
+ * Session Actions - Listing and Revoking Signed-In Devices
+ *
+ * A session is one login: the access tokens of a refresh token family
+ * (see refresh_token_actions.go). Tokens record the User-Agent and IP of
+ * the client that obtained them, and AuthRequired updates last_used_at
+ * at most every models.AuthTokenTouchInterval.
+ *
+ * Revoking a session revokes its access and refresh tokens and drops them
+ * from the token cache (see token_cache.go), so a revoked session is
+ * rejected on its next request.
+ *
+ * Configuration:
+ * - TRUSTED_PROXIES: comma-separated IPs or CIDR ranges of the reverse
+ *   proxies whose X-Forwarded-For is believed; unset, the client IP is
+ *   the connection's remote address
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-21
+ */
+package actions
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"time"
+
+	"backend/models"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/envy"
+	"github.com/gobuffalo/nulls"
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gofrs/uuid"
+)
+
+// maxUserAgentLength matches the auth_tokens.user_agent column.
+const maxUserAgentLength = 512
+
+/**
+ * sessionClient describes the device a token is issued to
+ */
+type sessionClient struct {
+	UserAgent nulls.String
+	IP        nulls.String
+}
+
+/**
+ * sessionClientFrom reads the User-Agent and client IP (see clientIP) of
+ * a request
+ */
+func sessionClientFrom(c buffalo.Context) sessionClient {
+	req := c.Request()
+	var client sessionClient
+
+	if ua := strings.TrimSpace(req.UserAgent()); ua != "" {
+		if len(ua) > maxUserAgentLength {
+			ua = strings.ToValidUTF8(ua[:maxUserAgentLength], "")
+		}
+		client.UserAgent = nulls.NewString(ua)
+	}
+
+	if ip, ok := clientIP(req, trustedProxies()); ok {
+		client.IP = nulls.NewString(ip.String())
+	}
+	return client
+}
+
+/**
+ * trustedProxies parses TRUSTED_PROXIES; entries that are neither an IP
+ * nor a CIDR range are ignored
+ */
+func trustedProxies() []netip.Prefix {
+	var out []netip.Prefix
+	for _, s := range strings.Split(envy.Get("TRUSTED_PROXIES", ""), ",") {
+		s = strings.TrimSpace(s)
+		if p, err := netip.ParsePrefix(s); err == nil {
+			out = append(out, p.Masked())
+		} else if a, err := netip.ParseAddr(s); err == nil {
+			out = append(out, netip.PrefixFrom(a.Unmap(), a.Unmap().BitLen()))
+		}
+	}
+	return out
+}
+
+/**
+ * clientIP returns the IP of the client that sent a request. It is the
+ * connection's remote address unless that is a trusted proxy; then
+ * X-Forwarded-For is read from the right, past the trusted proxies, so
+ * a client cannot pick its own address by sending the header.
+ */
+func clientIP(req *http.Request, trusted []netip.Prefix) (netip.Addr, bool) {
+	isTrusted := func(a netip.Addr) bool {
+		for _, p := range trusted {
+			if p.Contains(a) {
+				return true
+			}
+		}
+		return false
+	}
+	host := req.RemoteAddr
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	ip = ip.Unmap()
+
+	hops := strings.Split(strings.Join(req.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0 && isTrusted(ip); i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		ip = hop.Unmap()
+	}
+	return ip, true
+}
+
+/**
+ * revokeOtherSessions revokes every token of the user except those of the
+ * session the claims belong to
+ *
+ * @param tx - Database transaction
+ * @param uid - User whose sessions are revoked
+ * @param claims - Claims of the token to keep
+ * @param now - Revocation time
+ */
+func revokeOtherSessions(tx *pop.Connection, uid uuid.UUID, claims *JWTClaims, now time.Time) error {
+	currentTokenCache().forgetUser(uid)
+	if err := tx.RawQuery(`
+		UPDATE auth_tokens SET revoked_at = ?, updated_at = ?
+		WHERE user_id = ? AND jti <> ? AND (family_id IS NULL OR family_id::text <> ?) AND revoked_at IS NULL
+	`, now.UTC(), now.UTC(), uid, claims.ID, claims.Family).Exec(); err != nil {
+		return err
+	}
+	return tx.RawQuery(`
+		UPDATE refresh_tokens SET revoked_at = ?, updated_at = ?
+		WHERE user_id = ? AND family_id::text <> ? AND revoked_at IS NULL
+	`, now.UTC(), now.UTC(), uid, claims.Family).Exec()
+}
+
+/**
+ * revokeAllSessions revokes every access and refresh token of the user
+ *
+ * @param tx - Database transaction
+ * @param uid - User whose sessions are revoked
+ * @param now - Revocation time
+ */
+func revokeAllSessions(tx *pop.Connection, uid uuid.UUID, now time.Time) error {
+	currentTokenCache().forgetUser(uid)
+	for _, table := range []string{"auth_tokens", "refresh_tokens"} {
+		if err := tx.RawQuery(
+			"UPDATE "+table+" SET revoked_at = ?, updated_at = ? WHERE user_id = ? AND revoked_at IS NULL",
+			now.UTC(), now.UTC(), uid,
+		).Exec(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/**
+ * sessionInfo is one entry of the session list
+ */
+type sessionInfo struct {
+	JTI        string       `db:"jti" json:"jti"`
+	FamilyID   nulls.UUID   `db:"family_id" json:"-"`
+	UserAgent  nulls.String `db:"user_agent" json:"user_agent"`
+	IP         nulls.String `db:"ip" json:"ip"`
+	LastUsedAt nulls.Time   `db:"last_used_at" json:"last_used_at"`
+	SignedInAt time.Time    `db:"signed_in_at" json:"signed_in_at"`
+	ExpiresAt  time.Time    `db:"expires_at" json:"expires_at"`
+	Current    bool         `db:"-" json:"current"`
+}
+
+/**
+ * MeSessions lists the user's active sessions
+ *
+ * GET /api/me/sessions
+ *
+ * One entry per login, represented by its newest access token, most
+ * recently used first. A session is active while that token is valid or
+ * its refresh token can still renew it; `expires_at` is the access token
+ * expiry. The session of the requesting token has `current: true`.
+ *
+ * @param c - Buffalo context with authenticated user
+ * @return JSON array of sessions or error response
+ */
+func MeSessions(c buffalo.Context) error {
+	u, ok := CurrentUser(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+	claims, ok := currentTokenClaims(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "invalid token"}))
+	}
+
+	now := time.Now().UTC()
+	list := []sessionInfo{}
+	err := mustTx(c).RawQuery(`
+		SELECT * FROM (
+			SELECT DISTINCT ON (COALESCE(a.family_id::text, a.jti))
+				a.jti, a.family_id, a.user_agent, a.ip, a.last_used_at, a.expires_at,
+				MIN(a.created_at) OVER (PARTITION BY COALESCE(a.family_id::text, a.jti)) AS signed_in_at
+			FROM auth_tokens a
+			WHERE a.user_id = ? AND a.revoked_at IS NULL
+			  AND (a.expires_at > ? OR EXISTS (
+				SELECT 1 FROM refresh_tokens rt
+				WHERE rt.family_id = a.family_id AND rt.revoked_at IS NULL
+				  AND rt.used_at IS NULL AND rt.expires_at > ?
+			  ))
+			ORDER BY COALESCE(a.family_id::text, a.jti), a.created_at DESC
+		) s
+		ORDER BY s.last_used_at DESC NULLS LAST
+	`, u.ID, now, now).All(&list)
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+
+	for i := range list {
+		s := &list[i]
+		s.Current = s.JTI == claims.ID || (s.FamilyID.Valid && s.FamilyID.UUID.String() == claims.Family)
+	}
+	return c.Render(http.StatusOK, r.JSON(list))
+}
+
+/**
+ * MeSessionRevoke signs out one session
+ *
+ * DELETE /api/me/sessions/{jti}
+ *
+ * Any access token of the session identifies it. Revoking the current
+ * session is the same as logging out.
+ *
+ * @param c - Buffalo context with authenticated user and token ID
+ * @return JSON status or error response
+ */
+func MeSessionRevoke(c buffalo.Context) error {
+	u, ok := CurrentUser(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+
+	tx := mustTx(c)
+	var at models.AuthToken
+	if err := tx.Where("jti = ? AND user_id = ?", c.Param("jti"), u.ID).First(&at); err != nil {
+		return c.Render(http.StatusNotFound, r.JSON(map[string]string{"error": "not found"}))
+	}
+
+	now := time.Now()
+	forgetTokens(c, func(tc *tokenCache) {
+		tc.forgetJTI(at.JTI)
+		if at.FamilyID.Valid {
+			tc.forgetFamily(at.FamilyID.UUID.String())
+		}
+	})
+	var err error
+	if at.FamilyID.Valid {
+		err = revokeTokenFamily(tx, at.FamilyID.UUID, now)
+	} else {
+		err = tx.RawQuery(`
+			UPDATE auth_tokens SET revoked_at = ?, updated_at = ? WHERE jti = ? AND revoked_at IS NULL
+		`, now.UTC(), now.UTC(), at.JTI).Exec()
+	}
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot revoke"}))
+	}
+	return c.Render(http.StatusOK, r.JSON(map[string]string{"status": "revoked"}))
+}
+
+/**
+ * MeSessionsRevokeOthers signs out every session except the current one
+ *
+ * DELETE /api/me/sessions
+ *
+ * @param c - Buffalo context with authenticated user
+ * @return JSON status or error response
+ */
+func MeSessionsRevokeOthers(c buffalo.Context) error {
+	u, ok := CurrentUser(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+	claims, ok := currentTokenClaims(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "invalid token"}))
+	}
+
+	if err := revokeOtherSessions(mustTx(c), u.ID, claims, time.Now()); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot revoke"}))
+	}
+	forgetTokens(c, func(tc *tokenCache) { tc.forgetUser(u.ID) })
+	return c.Render(http.StatusOK, r.JSON(map[string]string{"status": "revoked"}))
+}
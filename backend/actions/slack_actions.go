@@ -0,0 +1,344 @@
+/**
+ * Slack Actions - /track Slash Command
+ *
+ * This file implements Slack's slash-command contract for starting,
+ * stopping and checking the timer from Slack:
+ * - /track start <project> #tag note...
+ * - /track stop
+ * - /track status
+ * - /track link <code>
+ *
+ * Requests are verified with the app's signing secret
+ * (SLACK_SIGNING_SECRET). Slack users are mapped to TimeTrac users by
+ * redeeming a code from GET /api/integrations/slack/link. Start and stop
+ * share startTrackEntry and stopTrackEntry with the HTTP API.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-21
+ */
+package actions
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/models"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/pop/v6"
+	"github.com/lib/pq"
+)
+
+// slackCommandPath is the route Slack posts slash commands to.
+const slackCommandPath = "/api/integrations/slack/command"
+
+// slackMaxBody bounds the slash-command body kept for signature checks.
+const slackMaxBody = 64 << 10
+
+// slackMaxSkew is how far X-Slack-Request-Timestamp may be from now.
+const slackMaxSkew = 5 * time.Minute
+
+// slackBodyKey is the request context key for the raw command body.
+type slackBodyKey struct{}
+
+/**
+ * captureSlackBody keeps a copy of the slash-command body in the request
+ * context. It must run as a PreWare: Buffalo parses form bodies into
+ * params before any middleware, after which the raw bytes needed for the
+ * signature are gone.
+ */
+func captureSlackBody(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodPost && req.URL.Path == slackCommandPath {
+			body, err := io.ReadAll(io.LimitReader(req.Body, slackMaxBody))
+			if err == nil {
+				req.Body = io.NopCloser(bytes.NewReader(body))
+				req = req.WithContext(context.WithValue(req.Context(), slackBodyKey{}, body))
+			}
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+/**
+ * verifySlackSignature checks a request against Slack's v0 signature:
+ * "v0=" + hex(HMAC-SHA256(secret, "v0:" + timestamp + ":" + body))
+ *
+ * @param secret - Slack signing secret
+ * @param timestamp - X-Slack-Request-Timestamp header
+ * @param body - Raw request body
+ * @param signature - X-Slack-Signature header
+ * @param now - Reference time for the replay window
+ * @return error - Why the request is rejected, nil if valid
+ */
+func verifySlackSignature(secret, timestamp string, body []byte, signature string, now time.Time) error {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errors.New("bad timestamp")
+	}
+	if d := now.Sub(time.Unix(ts, 0)); d > slackMaxSkew || d < -slackMaxSkew {
+		return errors.New("stale timestamp")
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "v0:%s:", timestamp)
+	mac.Write(body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return errors.New("bad signature")
+	}
+	return nil
+}
+
+/**
+ * parseSlackStart splits the arguments of `/track start`
+ *
+ * The first word is the project unless it is a #tag; further #words are
+ * tags and everything else forms the note.
+ *
+ * @param args - Text after "start"
+ * @return project, tags, note
+ */
+func parseSlackStart(args string) (string, []string, string) {
+	project := ""
+	tags := []string{}
+	note := []string{}
+	for i, word := range strings.Fields(args) {
+		switch {
+		case strings.HasPrefix(word, "#") && len(word) > 1:
+			tags = append(tags, word[1:])
+		case i == 0:
+			project = word
+		default:
+			note = append(note, word)
+		}
+	}
+	return project, tags, strings.Join(note, " ")
+}
+
+/**
+ * formatSlackDuration renders a duration as "1h 05m" or "12m"
+ */
+func formatSlackDuration(d time.Duration) string {
+	m := int(d / time.Minute)
+	if m < 60 {
+		return fmt.Sprintf("%dm", m)
+	}
+	return fmt.Sprintf("%dh %02dm", m/60, m%60)
+}
+
+/**
+ * slackReply renders an ephemeral slash-command response. Errors that
+ * must roll back the transaction use a 5xx status; Slack then shows a
+ * generic failure instead of the text.
+ */
+func slackReply(c buffalo.Context, status int, text string) error {
+	return c.Render(status, r.JSON(map[string]string{
+		"response_type": "ephemeral",
+		"text":          text,
+	}))
+}
+
+/**
+ * SlackLinkCode creates a code for linking a Slack user to the account
+ *
+ * GET /api/integrations/slack/link
+ *
+ * Response: {"code", "expires_at", "command": "/track link <code>"}; the
+ * code is valid for models.SlackLinkCodeTTL and can be used once.
+ *
+ * @param c - Buffalo context with authenticated user
+ * @return JSON code or error response
+ */
+func SlackLinkCode(c buffalo.Context) error {
+	tx := mustTx(c)
+	uid, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+
+	buf := make([]byte, 5)
+	if _, err := rand.Read(buf); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot create code"}))
+	}
+	code := models.SlackLinkCode{
+		UserID:    uid,
+		Code:      base32.StdEncoding.EncodeToString(buf),
+		ExpiresAt: time.Now().Add(models.SlackLinkCodeTTL).UTC(),
+	}
+	if err := tx.RawQuery("DELETE FROM slack_link_codes WHERE user_id = ? OR expires_at < ?", uid, time.Now().UTC()).Exec(); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+	if err := tx.Create(&code); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot create code"}))
+	}
+	return c.Render(http.StatusOK, r.JSON(map[string]any{
+		"code":       code.Code,
+		"expires_at": code.ExpiresAt,
+		"command":    "/track link " + code.Code,
+	}))
+}
+
+/**
+ * SlackCommand handles the /track slash command
+ *
+ * POST /api/integrations/slack/command
+ *
+ * Public route; requests must carry a valid Slack signature. Responses
+ * are ephemeral messages ({"response_type": "ephemeral", "text"}).
+ *
+ * Responses:
+ * - 200 with the message, including user errors such as "not linked"
+ * - 401 for a missing or invalid signature
+ * - 503 if SLACK_SIGNING_SECRET is not configured
+ *
+ * @param c - Buffalo context with the form-encoded command
+ * @return JSON Slack message
+ */
+func SlackCommand(c buffalo.Context) error {
+	secret := os.Getenv("SLACK_SIGNING_SECRET")
+	if secret == "" {
+		return c.Render(http.StatusServiceUnavailable, r.JSON(map[string]string{"error": "slack integration not configured"}))
+	}
+	body, _ := c.Request().Context().Value(slackBodyKey{}).([]byte)
+	req := c.Request()
+	if err := verifySlackSignature(secret, req.Header.Get("X-Slack-Request-Timestamp"), body,
+		req.Header.Get("X-Slack-Signature"), time.Now()); err != nil {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": err.Error()}))
+	}
+
+	tx := mustTx(c)
+	teamID, slackUserID := c.Param("team_id"), c.Param("user_id")
+	sub, args, _ := strings.Cut(strings.TrimSpace(c.Param("text")), " ")
+	args = strings.TrimSpace(args)
+
+	if sub == "link" {
+		return slackLink(c, tx, teamID, slackUserID, args)
+	}
+
+	var link models.SlackLink
+	if err := tx.Where("slack_team_id = ? AND slack_user_id = ?", teamID, slackUserID).First(&link); err != nil {
+		return slackReply(c, http.StatusOK,
+			"Your Slack account is not linked yet. Get a code in TimeTrac and run `/track link <code>`.")
+	}
+	now := time.Now()
+
+	switch sub {
+	case "start":
+		project, tags, note := parseSlackStart(args)
+		item := models.TimeTrac{
+			UserID:  link.UserID,
+			Project: project,
+			Tags:    pq.StringArray(tags),
+			Note:    note,
+			Color:   models.DefaultColor,
+		}
+		stopped, err := startTrackEntry(tx, &item, now, false)
+		if errors.Is(err, errProjectArchived) {
+			return slackReply(c, http.StatusOK, fmt.Sprintf("Project %q is archived. Unarchive it in TimeTrac first.", project))
+		}
+		if err != nil {
+			c.Logger().Errorf("slack start: %v", err)
+			return slackReply(c, http.StatusInternalServerError, "Could not start the timer.")
+		}
+		text := "Started " + slackEntryLabel(item) + "."
+		if stopped != nil {
+			queueTrackEvent(c, eventTrackStopped, *stopped)
+			text += fmt.Sprintf(" Stopped %s after %s.", slackEntryLabel(*stopped),
+				formatSlackDuration(time.Duration(stopped.DurationSeconds)*time.Second))
+		}
+		queueTrackEvent(c, eventTrackStarted, item)
+		queueGeocode(c, item)
+		return slackReply(c, http.StatusOK, text)
+
+	case "stop":
+		item, err := findRunningTrack(tx, link.UserID)
+		if err != nil {
+			return slackReply(c, http.StatusOK, "No timer is running.")
+		}
+		discarded, err := stopTrackEntry(tx, &item, now, now)
+		if err != nil {
+			c.Logger().Errorf("slack stop: %v", err)
+			return slackReply(c, http.StatusInternalServerError, "Could not stop the timer.")
+		}
+		queueTrackEvent(c, eventTrackStopped, item)
+		if discarded {
+			return slackReply(c, http.StatusOK, "Stopped "+slackEntryLabel(item)+"; it was too short and has been discarded.")
+		}
+		return slackReply(c, http.StatusOK, fmt.Sprintf("Stopped %s after %s.", slackEntryLabel(item),
+			formatSlackDuration(time.Duration(item.DurationSeconds)*time.Second)))
+
+	case "status":
+		item, err := findRunningTrack(tx, link.UserID)
+		if err != nil {
+			return slackReply(c, http.StatusOK, "No timer is running.")
+		}
+		items := []models.TimeTrac{item}
+		if err := attachPauses(tx, items, now); err != nil {
+			return slackReply(c, http.StatusInternalServerError, "Could not load the timer.")
+		}
+		state := "Running"
+		if items[0].Paused {
+			state = "Paused"
+		}
+		return slackReply(c, http.StatusOK, fmt.Sprintf("%s: %s for %s.", state, slackEntryLabel(items[0]),
+			formatSlackDuration(time.Duration(items[0].DurationSeconds)*time.Second)))
+	}
+
+	return slackReply(c, http.StatusOK,
+		"Usage: `/track start <project> #tag note...`, `/track stop`, `/track status`, `/track link <code>`")
+}
+
+/**
+ * slackLink redeems a linking code for the calling Slack user, replacing
+ * an earlier link of the same Slack user
+ */
+func slackLink(c buffalo.Context, tx *pop.Connection, teamID, slackUserID, code string) error {
+	if teamID == "" || slackUserID == "" || code == "" {
+		return slackReply(c, http.StatusOK, "Usage: `/track link <code>`")
+	}
+	var lc models.SlackLinkCode
+	err := tx.Where("code = ? AND expires_at > ?", strings.ToUpper(code), time.Now().UTC()).First(&lc)
+	if err != nil {
+		return slackReply(c, http.StatusOK, "That code is invalid or has expired. Get a new one in TimeTrac.")
+	}
+
+	if err := tx.RawQuery("DELETE FROM slack_links WHERE slack_team_id = ? AND slack_user_id = ?", teamID, slackUserID).Exec(); err != nil {
+		return slackReply(c, http.StatusInternalServerError, "Could not link your account.")
+	}
+	link := models.SlackLink{UserID: lc.UserID, SlackTeamID: teamID, SlackUserID: slackUserID}
+	if err := tx.Create(&link); err != nil {
+		return slackReply(c, http.StatusInternalServerError, "Could not link your account.")
+	}
+	if err := tx.Destroy(&lc); err != nil {
+		return slackReply(c, http.StatusInternalServerError, "Could not link your account.")
+	}
+	return slackReply(c, http.StatusOK, "Linked! Try `/track start <project>`.")
+}
+
+/**
+ * slackEntryLabel names an entry in Slack messages
+ */
+func slackEntryLabel(item models.TimeTrac) string {
+	label := "*" + item.Project + "*"
+	if item.Project == "" {
+		label = "an entry without project"
+	}
+	for _, tag := range item.Tags {
+		label += " #" + tag
+	}
+	return label
+}
@@ -0,0 +1,81 @@
+/**
+ * Sliding Sessions - Activity-Based Access Token Renewal
+ *
+ * With SESSION_SLIDING_WINDOW_MINUTES set, a request whose access token
+ * expires within that window gets a fresh token of the same session in
+ * the X-Refreshed-Token response header. Clients swap tokens when they
+ * see the header, so an active user is never logged out mid-workday;
+ * idle sessions still expire as before.
+ *
+ * On renewal the old token's auth_tokens row is shortened to
+ * slidingGracePeriod, so requests already in flight with it still
+ * succeed, after which it stops working. Only the request that shortens
+ * the row renews; concurrent requests with the old token get no header.
+ *
+ * Renewal commits on its own, outside the request transaction, so the
+ * new token is valid even when the request itself fails.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-21
+ */
+package actions
+
+import (
+	"time"
+
+	"backend/models"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gofrs/uuid"
+)
+
+// refreshedTokenHeader carries the renewed access token.
+const refreshedTokenHeader = "X-Refreshed-Token"
+
+// slidingGracePeriod is how long a renewed token keeps working.
+const slidingGracePeriod = 30 * time.Second
+
+/**
+ * slideSession renews the access token of the request when it is close
+ * to expiry. Failures are logged; the request goes on with its token.
+ *
+ * @param c - Buffalo context of the authenticated request
+ * @param auth - The validated token
+ * @param now - Request time
+ */
+func slideSession(c buffalo.Context, auth tokenAuth, now time.Time) {
+	window, _ := slidingWindow()
+	if window <= 0 || auth.expiresAt.Sub(now) > window {
+		return
+	}
+	// Tokens from before refresh token families cannot be renewed
+	family, err := uuid.FromString(auth.claims.Family)
+	if err != nil || family == uuid.Nil {
+		return
+	}
+
+	var token string
+	err = models.DB.Transaction(func(tx *pop.Connection) error {
+		graceUntil := now.Add(slidingGracePeriod).UTC()
+		n, err := tx.RawQuery(`
+			UPDATE auth_tokens SET expires_at = ?, updated_at = ?
+			WHERE jti = ? AND revoked_at IS NULL AND expires_at > ?
+		`, graceUntil, now.UTC(), auth.claims.ID, graceUntil).ExecWithCount()
+		if err != nil || n == 0 {
+			return err
+		}
+		token, _, err = issueAccessToken(tx, auth.user.ID, family, sessionClientFrom(c), now)
+		return err
+	})
+	if err != nil {
+		c.Logger().Errorf("sliding session: %v", err)
+		return
+	}
+	if token == "" {
+		return
+	}
+	currentTokenCache().forgetJTI(auth.claims.ID)
+	c.Response().Header().Set(refreshedTokenHeader, token)
+}
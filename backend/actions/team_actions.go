@@ -11,14 +11,21 @@
 package actions
 
 import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/nulls"
 	"github.com/gobuffalo/pop/v6"
 	"github.com/gofrs/uuid"
 
 	"backend/models"
+	"backend/validators"
 )
 
 /**
@@ -34,14 +41,66 @@ type CreateTeamRequest struct {
  */
 type InviteMemberRequest struct {
 	Email string `json:"email" validate:"required,email"`
-	Role  string `json:"role" validate:"required,oneof=admin manager member viewer"`
+	Role  string `json:"role"`  // Any team role but owner; team's default_member_role when empty
+	Force bool   `json:"force"` // Re-invite a user who declined recently (manage_members only)
+}
+
+/**
+ * UpdateTeamRequest represents the request payload for updating a team;
+ * omitted fields are left unchanged
+ */
+type UpdateTeamRequest struct {
+	Name        *string         `json:"name"`
+	Description *string         `json:"description"`
+	Settings    json.RawMessage `json:"settings"`
+}
+
+/**
+ * DeleteTeamRequest represents the request payload for deleting a team
+ */
+type DeleteTeamRequest struct {
+	ConfirmName string `json:"confirm_name"` // Must repeat the team name
 }
 
 /**
  * UpdateMemberRoleRequest represents the request payload for updating member role
  */
 type UpdateMemberRoleRequest struct {
-	Role string `json:"role" validate:"required,oneof=admin manager member viewer"`
+	Role string `json:"role"` // Any team role but owner
+}
+
+/**
+ * teamMembership loads the caller's active membership in the team named
+ * by the id parameter and checks permission (none when empty); when it
+ * fails the returned status and message describe the error response
+ */
+func teamMembership(c buffalo.Context, permission string) (models.TeamMember, int, string) {
+	teamID, err := uuid.FromString(c.Param("id"))
+	if err != nil {
+		return models.TeamMember{}, http.StatusBadRequest, "Invalid team ID"
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return models.TeamMember{}, http.StatusUnauthorized, "Unauthorized"
+	}
+	var member models.TeamMember
+	if err := mustTx(c).Where("team_id = ? AND user_id = ? AND status = ?", teamID, userID, "active").First(&member); err != nil {
+		return models.TeamMember{}, http.StatusForbidden, "Access denied"
+	}
+	if permission != "" && !member.HasPermission(permission) {
+		return models.TeamMember{}, http.StatusForbidden, "Insufficient permissions"
+	}
+	return member, 0, ""
+}
+
+/**
+ * renderTeamError renders a failed team request in the team envelope
+ */
+func renderTeamError(c buffalo.Context, status int, msg string) error {
+	return c.Render(status, r.JSON(map[string]interface{}{
+		"success": false,
+		"message": msg,
+	}))
 }
 
 /**
@@ -58,8 +117,17 @@ func CreateTeam(c buffalo.Context) error {
 		}))
 	}
 
+	name, err := models.NormalizeTeamName(req.Name)
+	if err != nil {
+		return c.Render(http.StatusUnprocessableEntity, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Invalid team name",
+			"error":   err.Error(),
+		}))
+	}
+
 	// Get current user from JWT
-	userID, ok := c.Value("user_id").(uuid.UUID)
+	userID, ok := currentUserID(c)
 	if !ok {
 		return c.Render(http.StatusUnauthorized, r.JSON(map[string]interface{}{
 			"success": false,
@@ -72,7 +140,7 @@ func CreateTeam(c buffalo.Context) error {
 	// Create team
 	team := &models.Team{
 		ID:          uuid.Must(uuid.NewV4()),
-		Name:        req.Name,
+		Name:        name,
 		Description: req.Description,
 		OwnerID:     userID,
 		Settings:    "{}",
@@ -88,6 +156,14 @@ func CreateTeam(c buffalo.Context) error {
 		}))
 	}
 
+	if err := models.SeedTeamRoles(tx, team.ID); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to create team",
+			"error":   err.Error(),
+		}))
+	}
+
 	// Add owner as team member
 	ownerMember := &models.TeamMember{
 		ID:        uuid.Must(uuid.NewV4()),
@@ -116,12 +192,29 @@ func CreateTeam(c buffalo.Context) error {
 	}))
 }
 
+/**
+ * userTeam is one entry of GET /api/teams: the team with the caller's
+ * membership and the team's size
+ */
+type userTeam struct {
+	models.Team
+	Role        models.TeamMemberRole `db:"role" json:"role"`                 // Caller's role
+	Suspended   bool                  `db:"suspended" json:"suspended"`       // Caller is suspended
+	JoinedAt    *time.Time            `db:"joined_at" json:"joined_at"`       // When the caller joined
+	MemberCount int                   `db:"member_count" json:"member_count"` // Active members
+}
+
 /**
  * GetTeams retrieves all teams for the current user
  * GET /api/teams
+ *
+ * Each team appears once, with the caller's role and join date and the
+ * number of active members, most recently joined first. Teams where the
+ * user is suspended are included with suspended: true. Archived teams are
+ * left out unless archived=true is given.
  */
 func GetTeams(c buffalo.Context) error {
-	userID, ok := c.Value("user_id").(uuid.UUID)
+	userID, ok := currentUserID(c)
 	if !ok {
 		return c.Render(http.StatusUnauthorized, r.JSON(map[string]interface{}{
 			"success": false,
@@ -131,13 +224,28 @@ func GetTeams(c buffalo.Context) error {
 
 	tx := c.Value("tx").(*pop.Connection)
 
-	// Get teams where user is a member
-	var teams []models.Team
-	query := tx.Q().
-		Join("team_members tm", "teams.id = tm.team_id").
-		Where("tm.user_id = ? AND tm.status = ?", userID, "active")
-
-	if err := query.All(&teams); err != nil {
+	// Get teams where user is a member; suspended members still see the team.
+	// DISTINCT ON keeps one row per team should memberships ever repeat,
+	// preferring the active one.
+	teams := []userTeam{}
+	if err := tx.RawQuery(`
+		SELECT * FROM (
+			SELECT DISTINCT ON (teams.id) teams.*, tm.role, tm.joined_at,
+			       (tm.status = 'suspended') AS suspended,
+			       COALESCE(mc.member_count, 0) AS member_count
+			FROM teams
+			JOIN team_members tm ON teams.id = tm.team_id
+			LEFT JOIN (
+				SELECT team_id, COUNT(*) AS member_count
+				FROM team_members WHERE status = 'active'
+				GROUP BY team_id
+			) mc ON mc.team_id = teams.id
+			WHERE tm.user_id = ? AND tm.status IN ('active', 'suspended')
+			  AND (? OR teams.archived_at IS NULL)
+			ORDER BY teams.id, tm.status = 'active' DESC, tm.joined_at DESC NULLS LAST
+		) ut
+		ORDER BY joined_at DESC NULLS LAST, name
+	`, userID, c.Param("archived") == "true").All(&teams); err != nil {
 		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
 			"success": false,
 			"message": "Failed to retrieve teams",
@@ -152,15 +260,58 @@ func GetTeams(c buffalo.Context) error {
 	}))
 }
 
+/**
+ * pendingInvitation is one entry of GET /api/pending. ID is the
+ * team_members row the client passes to the accept and decline routes.
+ */
+type pendingInvitation struct {
+	ID              uuid.UUID    `db:"id" json:"id"`
+	TeamID          uuid.UUID    `db:"team_id" json:"team_id"`
+	TeamName        string       `db:"team_name" json:"team_name"`
+	TeamDescription nulls.String `db:"team_description" json:"team_description"`
+	TeamAvatarURL   nulls.String `db:"team_avatar_url" json:"team_avatar_url"`
+	Role            string       `db:"role" json:"role"`
+	ExpiresAt       nulls.Time   `db:"expires_at" json:"expires_at"`
+	InvitedBy       nulls.UUID   `db:"invited_by" json:"invited_by"`
+	InviterEmail    nulls.String `db:"inviter_email" json:"inviter_email"`
+	CreatedAt       time.Time    `db:"created_at" json:"created_at"`
+}
+
 /**
  * GetPendingInvitations retrieves pending team invitations for the current user
  * GET /api/pending
+ *
+ * Invitations are the user's team_members rows with status "pending",
+ * newest first, with the time each one expires. Lapsed invitations the
+ * expiry job has not marked yet are left out. The inner join on teams
+ * drops rows whose team is gone; the inviter is optional because
+ * deleting their account nulls invited_by.
  */
 func GetPendingInvitations(c buffalo.Context) error {
-	// For now, return empty array since we don't have invitations implemented yet
-	// In a real implementation, this would query the database for user's pending invitations
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Unauthorized",
+		}))
+	}
 
-	pendingInvitations := []map[string]interface{}{}
+	pendingInvitations := []pendingInvitation{}
+	err := mustTx(c).RawQuery(`
+		SELECT tm.id, tm.team_id, t.name AS team_name, t.description AS team_description, t.avatar_url AS team_avatar_url,
+		       tm.role, tm.expires_at, tm.invited_by, u.email AS inviter_email, tm.created_at
+		FROM team_members tm
+		JOIN teams t ON t.id = tm.team_id
+		LEFT JOIN users u ON u.id = tm.invited_by
+		WHERE tm.user_id = ? AND tm.status = ? AND (tm.expires_at IS NULL OR tm.expires_at > ?)
+		ORDER BY tm.created_at DESC`, userID, "pending", time.Now().UTC()).All(&pendingInvitations)
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to retrieve pending invitations",
+			"error":   err.Error(),
+		}))
+	}
 
 	return c.Render(http.StatusOK, r.JSON(map[string]interface{}{
 		"success": true,
@@ -170,8 +321,32 @@ func GetPendingInvitations(c buffalo.Context) error {
 }
 
 /**
- * GetTeam retrieves a specific team with members
- * GET /api/teams/{id}
+ * declinedInvitation is one entry of declined_invitations in GET
+ * /api/teams/{id}
+ */
+type declinedInvitation struct {
+	ID         uuid.UUID  `db:"id" json:"id"`
+	UserID     uuid.UUID  `db:"user_id" json:"user_id"`
+	Email      string     `db:"email" json:"email"`
+	Role       string     `db:"role" json:"role"`
+	InvitedBy  nulls.UUID `db:"invited_by" json:"invited_by"`
+	DeclinedAt nulls.Time `db:"declined_at" json:"declined_at"`
+}
+
+/**
+ * GetTeam retrieves a specific team
+ * GET /api/teams/{id}[?include=members]
+ *
+ * Returns the team, the caller's role and the number of active members.
+ * Members are listed by GET /api/teams/{id}/members; include=members
+ * still embeds the first page of them (members, members_page) for
+ * clients written against the combined response, and accepts the same
+ * parameters; guests may not include members. Members who may manage
+ * members also get declined invitations as declined_invitations;
+ * members who may manage the team get its seat usage as seats (see
+ * team_seats.go).
+ *
+ * Deprecated: include=members will be removed in the next release.
  */
 func GetTeam(c buffalo.Context) error {
 	teamID, err := uuid.FromString(c.Param("id"))
@@ -182,7 +357,7 @@ func GetTeam(c buffalo.Context) error {
 		}))
 	}
 
-	userID, ok := c.Value("user_id").(uuid.UUID)
+	userID, ok := currentUserID(c)
 	if !ok {
 		return c.Render(http.StatusUnauthorized, r.JSON(map[string]interface{}{
 			"success": false,
@@ -210,28 +385,69 @@ func GetTeam(c buffalo.Context) error {
 		}))
 	}
 
-	// Get team members with user details
-	var members []struct {
-		models.TeamMember
-		User models.User `json:"user"`
+	var count struct {
+		Count int `db:"count"`
 	}
-	query := tx.Q().
-		Join("users u", "team_members.user_id = u.id").
-		Where("team_members.team_id = ?", teamID).
-		Select("team_members.*, u.email, u.created_at as user_created_at")
-
-	if err := query.All(&members); err != nil {
+	if err := tx.RawQuery("SELECT COUNT(*) AS count FROM team_members WHERE team_id = ? AND status = 'active'", teamID).First(&count); err != nil {
 		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
 			"success": false,
-			"message": "Failed to retrieve team members",
+			"message": "Failed to retrieve team",
 			"error":   err.Error(),
 		}))
 	}
 
 	response := map[string]interface{}{
-		"team":      team,
-		"members":   members,
-		"user_role": member.Role,
+		"team":         team,
+		"user_role":    member.Role,
+		"member_count": count.Count,
+	}
+
+	if includes(c, "members") {
+		if member.IsGuest() {
+			return renderTeamError(c, http.StatusForbidden, "Insufficient permissions")
+		}
+		members, pageInfo, msg, err := listTeamMembers(c, tx, teamID)
+		if msg != "" {
+			return renderTeamError(c, http.StatusBadRequest, msg)
+		}
+		if err != nil {
+			return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+				"success": false,
+				"message": "Failed to retrieve team members",
+				"error":   err.Error(),
+			}))
+		}
+		response["members"] = members
+		response["members_page"] = pageInfo
+	}
+
+	// Declined invitations are only shown to those who manage members
+	if member.HasPermission("manage_members") {
+		declined := []declinedInvitation{}
+		if err := tx.RawQuery(`
+			SELECT tm.id, tm.user_id, u.email, tm.role, tm.invited_by, tm.declined_at
+			FROM team_members tm JOIN users u ON u.id = tm.user_id
+			WHERE tm.team_id = ? AND tm.status = ?
+			ORDER BY tm.declined_at DESC`, teamID, "declined").All(&declined); err != nil {
+			return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+				"success": false,
+				"message": "Failed to retrieve team members",
+				"error":   err.Error(),
+			}))
+		}
+		response["declined_invitations"] = declined
+	}
+
+	if member.HasPermission("manage_team") {
+		seats, err := teamSeats(tx, teamID, false)
+		if err != nil {
+			return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+				"success": false,
+				"message": "Failed to retrieve team",
+				"error":   err.Error(),
+			}))
+		}
+		response["seats"] = seats
 	}
 
 	return c.Render(http.StatusOK, r.JSON(map[string]interface{}{
@@ -242,10 +458,17 @@ func GetTeam(c buffalo.Context) error {
 }
 
 /**
- * InviteMember invites a user to join the team
- * POST /api/teams/{id}/invite
+ * UpdateTeam changes a team's name, description or settings
+ * PATCH /api/teams/{id}
+ *
+ * Requires manage_team (owner and admins); other members get 403 and
+ * non-members 404. The name follows the creation rule. Settings are
+ * merged into the current ones key by key and must pass
+ * models.TeamSettings.Validate; max_members belongs to the team's plan
+ * and cannot be changed here. Members' webhooks subscribed to
+ * team.updated are notified.
  */
-func InviteMember(c buffalo.Context) error {
+func UpdateTeam(c buffalo.Context) error {
 	teamID, err := uuid.FromString(c.Param("id"))
 	if err != nil {
 		return c.Render(http.StatusBadRequest, r.JSON(map[string]interface{}{
@@ -254,7 +477,7 @@ func InviteMember(c buffalo.Context) error {
 		}))
 	}
 
-	var req InviteMemberRequest
+	var req UpdateTeamRequest
 	if err := c.Bind(&req); err != nil {
 		return c.Render(http.StatusBadRequest, r.JSON(map[string]interface{}{
 			"success": false,
@@ -263,7 +486,7 @@ func InviteMember(c buffalo.Context) error {
 		}))
 	}
 
-	userID, ok := c.Value("user_id").(uuid.UUID)
+	userID, ok := currentUserID(c)
 	if !ok {
 		return c.Render(http.StatusUnauthorized, r.JSON(map[string]interface{}{
 			"success": false,
@@ -271,9 +494,156 @@ func InviteMember(c buffalo.Context) error {
 		}))
 	}
 
-	tx := c.Value("tx").(*pop.Connection)
+	tx := mustTx(c)
+
+	var member models.TeamMember
+	if err := tx.Where("team_id = ? AND user_id = ? AND status = ?", teamID, userID, "active").First(&member); err != nil {
+		return c.Render(http.StatusNotFound, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Team not found",
+		}))
+	}
+
+	if !member.HasPermission("manage_team") {
+		return c.Render(http.StatusForbidden, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Insufficient permissions",
+		}))
+	}
+
+	var team models.Team
+	if err := tx.Find(&team, teamID); err != nil {
+		return c.Render(http.StatusNotFound, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Team not found",
+		}))
+	}
+
+	if req.Name != nil {
+		name, err := models.NormalizeTeamName(*req.Name)
+		if err != nil {
+			return c.Render(http.StatusUnprocessableEntity, r.JSON(map[string]interface{}{
+				"success": false,
+				"message": "Invalid team name",
+				"error":   err.Error(),
+			}))
+		}
+		team.Name = name
+	}
+	if req.Description != nil {
+		team.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Settings != nil {
+		// Keys the request omits keep their current values
+		settings := team.ParsedSettings()
+		maxMembers := settings.MaxMembers
+		if err := json.Unmarshal(req.Settings, &settings); err != nil {
+			return c.Render(http.StatusUnprocessableEntity, r.JSON(map[string]interface{}{
+				"success": false,
+				"message": "Invalid settings",
+				"error":   err.Error(),
+			}))
+		}
+		if settings.MaxMembers != maxMembers {
+			return c.Render(http.StatusUnprocessableEntity, r.JSON(map[string]interface{}{
+				"success": false,
+				"message": "Invalid settings",
+				"errors":  map[string]string{"max_members": "is set by the team's plan"},
+			}))
+		}
+		errs := settings.Validate()
+		if errs["default_member_role"] == "" {
+			ok, err := assignableRole(tx, team.ID, settings.DefaultMemberRole)
+			if err != nil {
+				return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+					"success": false,
+					"message": "Failed to update team",
+					"error":   err.Error(),
+				}))
+			}
+			if !ok {
+				if errs == nil {
+					errs = map[string]string{}
+				}
+				errs["default_member_role"] = "is not a role of this team"
+			}
+		}
+		if errs != nil {
+			return c.Render(http.StatusUnprocessableEntity, r.JSON(map[string]interface{}{
+				"success": false,
+				"message": "Invalid settings",
+				"errors":  errs,
+			}))
+		}
+		raw, err := json.Marshal(settings)
+		if err != nil {
+			return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+				"success": false,
+				"message": "Failed to update team",
+				"error":   err.Error(),
+			}))
+		}
+		team.Settings = string(raw)
+	}
+	team.UpdatedAt = time.Now()
+
+	if err := tx.Update(&team); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to update team",
+			"error":   err.Error(),
+		}))
+	}
+	if err := queueTeamWebhookDeliveries(tx, "team.updated", team, team.UpdatedAt, requestIDOf(c)); err != nil {
+		c.Logger().Errorf("webhook queue team.updated %s: %v", team.ID, err)
+	}
+	forgetTeamSettings(team.ID)
+	afterCommit(c, func() { forgetTeamSettings(team.ID) })
+
+	return c.Render(http.StatusOK, r.JSON(map[string]interface{}{
+		"success": true,
+		"data":    team,
+		"message": "Team updated successfully",
+	}))
+}
+
+/**
+ * DeleteTeam deletes a team
+ * DELETE /api/teams/{id}
+ *
+ * Only the owner may delete a team (delete_team), and confirm_name must
+ * repeat its name exactly. Memberships and email invitations go with the
+ * team through their foreign keys; members' time entries are personal
+ * and are kept. Responds 204 on success.
+ */
+func DeleteTeam(c buffalo.Context) error {
+	teamID, err := uuid.FromString(c.Param("id"))
+	if err != nil {
+		return c.Render(http.StatusBadRequest, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Invalid team ID",
+		}))
+	}
+
+	var req DeleteTeamRequest
+	if err := c.Bind(&req); err != nil {
+		return c.Render(http.StatusBadRequest, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Invalid request data",
+			"error":   err.Error(),
+		}))
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Unauthorized",
+		}))
+	}
+
+	tx := mustTx(c)
 
-	// Check if user has permission to invite members
 	var member models.TeamMember
 	if err := tx.Where("team_id = ? AND user_id = ? AND status = ?", teamID, userID, "active").First(&member); err != nil {
 		return c.Render(http.StatusForbidden, r.JSON(map[string]interface{}{
@@ -282,61 +652,495 @@ func InviteMember(c buffalo.Context) error {
 		}))
 	}
 
-	if !member.HasPermission("invite_members") {
+	if !member.HasPermission("delete_team") {
 		return c.Render(http.StatusForbidden, r.JSON(map[string]interface{}{
 			"success": false,
 			"message": "Insufficient permissions",
 		}))
 	}
 
-	// Find user by email
-	var user models.User
-	if err := tx.Where("email = ?", req.Email).First(&user); err != nil {
+	var team models.Team
+	if err := tx.Find(&team, teamID); err != nil {
 		return c.Render(http.StatusNotFound, r.JSON(map[string]interface{}{
 			"success": false,
-			"message": "User not found",
+			"message": "Team not found",
 		}))
 	}
 
-	// Check if user is already a member
-	var existingMember models.TeamMember
-	if err := tx.Where("team_id = ? AND user_id = ?", teamID, user.ID).First(&existingMember); err == nil {
-		return c.Render(http.StatusConflict, r.JSON(map[string]interface{}{
+	if req.ConfirmName != team.Name {
+		return c.Render(http.StatusUnprocessableEntity, r.JSON(map[string]interface{}{
 			"success": false,
-			"message": "User is already a team member",
+			"message": "Confirmation does not match the team name",
 		}))
 	}
 
+	if err := tx.Destroy(&team); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to delete team",
+			"error":   err.Error(),
+		}))
+	}
+	forgetTeamAvatar(c, team)
+
+	c.Response().WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+/**
+ * inviteResult is the outcome of inviting one address
+ *
+ * Status is one of:
+ * - invited: A registered user got a pending membership (Member), or an
+ *   address without an account was emailed an invitation (Invitation);
+ *   the two are reported alike, so callers cannot tell which addresses
+ *   have accounts
+ * - already_member: The user is a member or has an open invitation
+ * - declined_recently: The user declined within the cooldown (RetryAfter)
+ * - seat_limit: The team has no seat left (Seats)
+ * - role_above_inviter: The role grants a permission the inviter lacks
+ * - invalid_email, invalid_role: The request was rejected
+ * - duplicate: The address appeared earlier in the same batch
+ * - error: The invitation could not be stored
+ */
+type inviteResult struct {
+	Email      string                 `json:"email"`
+	Role       models.TeamMemberRole  `json:"role,omitempty"`
+	Status     string                 `json:"status"`
+	ExpiresAt  *time.Time             `json:"expires_at,omitempty"`
+	Member     *models.TeamMember     `json:"-"` // Pending membership of a registered user
+	Invitation *models.TeamInvitation `json:"-"` // Emailed invitation of any other address
+	RetryAfter *time.Time             `json:"retry_after,omitempty"`
+	Seats      *seatUsage             `json:"seats,omitempty"`
+}
+
+/**
+ * inviteToTeam invites one address on behalf of inviter, whose
+ * invite_members permission the caller has checked
+ *
+ * @param c - Request context (emails are queued after commit)
+ * @param tx - Request transaction
+ * @param team - Team invited to
+ * @param settings - The team's settings
+ * @param inviter - Inviter's active membership
+ * @param req - Address, role and force flag
+ * @param now - Request time
+ * @return inviteResult - Outcome; see its Status
+ * @return error - Database error, with Status "error"
+ */
+func inviteToTeam(c buffalo.Context, tx *pop.Connection, team models.Team, settings models.TeamSettings, inviter models.TeamMember, req InviteMemberRequest, now time.Time) (inviteResult, error) {
+	res := inviteResult{Email: req.Email, Status: "error"}
+	email, err := validators.NormalizeEmail(req.Email)
+	if err != nil {
+		res.Status = "invalid_email"
+		return res, nil
+	}
+	res.Email = email
+
+	role := models.TeamMemberRole(req.Role)
+	if role == "" {
+		role = settings.DefaultMemberRole
+	}
+	res.Role = role
+	if ok, err := assignableRole(tx, team.ID, role); err != nil {
+		return res, err
+	} else if !ok {
+		res.Status = "invalid_role"
+		return res, nil
+	}
+	if above, err := roleAboveInviter(tx, team.ID, role, inviter); err != nil {
+		return res, err
+	} else if above {
+		res.Status = "role_above_inviter"
+		return res, nil
+	}
+	expiresAt := nulls.NewTime(now.Add(settings.InvitationTTL()).UTC())
+	seats, err := teamSeats(tx, team.ID, false)
+	if err != nil {
+		return res, err
+	}
+	full := func() bool {
+		if seats.Full() {
+			res.Status, res.Seats = "seat_limit", &seats
+		}
+		return seats.Full()
+	}
+
+	// Addresses without an account get an emailed invitation instead
+	var user models.User
+	if err := tx.Where("email = ?", email).First(&user); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return res, err
+		}
+		if full() {
+			return res, nil
+		}
+		inv, err := inviteByEmail(c, tx, team, settings.InvitationTTL(), inviter.UserID, email, role)
+		if err != nil {
+			return res, err
+		}
+		res.Status, res.Invitation, res.ExpiresAt = "invited", &inv, &inv.ExpiresAt
+		return res, nil
+	}
+
+	// Check if user is already a member; a lapsed or declined invitation
+	// is sent again by flipping the row back to pending
+	var existingMember models.TeamMember
+	if err := tx.Where("team_id = ? AND user_id = ?", team.ID, user.ID).First(&existingMember); err == nil {
+		switch {
+		case existingMember.Status == "declined":
+			until := existingMember.DeclineCooldownUntil()
+			if now.Before(until) && !(req.Force && inviter.HasPermission("manage_members")) {
+				res.Status, res.RetryAfter = "declined_recently", &until
+				return res, nil
+			}
+		case existingMember.Status == "expired",
+			existingMember.Status == "pending" && existingMember.InvitationExpired(now):
+		default:
+			res.Status = "already_member"
+			return res, nil
+		}
+		if full() {
+			return res, nil
+		}
+		existingMember.Role = role
+		existingMember.Status = "pending"
+		existingMember.InvitedBy = nulls.NewUUID(inviter.UserID)
+		existingMember.ExpiresAt = expiresAt
+		existingMember.DeclinedAt = nulls.Time{}
+		existingMember.UpdatedAt = now
+		if err := tx.Update(&existingMember); err != nil {
+			return res, err
+		}
+		notifyInvited(c, tx, existingMember, inviter.UserID)
+		res.Status, res.Member, res.ExpiresAt = "invited", &existingMember, &existingMember.ExpiresAt.Time
+		return res, nil
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return res, err
+	}
+
+	if full() {
+		return res, nil
+	}
+
 	// Create team member invitation
 	teamMember := &models.TeamMember{
 		ID:        uuid.Must(uuid.NewV4()),
-		TeamID:    teamID,
+		TeamID:    team.ID,
 		UserID:    user.ID,
-		Role:      models.TeamMemberRole(req.Role),
+		Role:      role,
 		Status:    "pending",
-		InvitedBy: userID,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		InvitedBy: nulls.NewUUID(inviter.UserID),
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
+		UpdatedAt: now,
 	}
-
 	if err := tx.Create(teamMember); err != nil {
-		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+		return res, err
+	}
+	notifyInvited(c, tx, *teamMember, inviter.UserID)
+	res.Status, res.Member, res.ExpiresAt = "invited", teamMember, &teamMember.ExpiresAt.Time
+	return res, nil
+}
+
+/**
+ * invitingTeam loads the team and settings for an invitation request by
+ * inviter, refusing archived teams; when it fails the returned status and
+ * message describe the error response
+ */
+func invitingTeam(tx *pop.Connection, inviter models.TeamMember) (models.Team, models.TeamSettings, int, string) {
+	var team models.Team
+	if err := tx.Find(&team, inviter.TeamID); err != nil {
+		return team, models.TeamSettings{}, http.StatusNotFound, "Team not found"
+	}
+	if team.ArchivedAt.Valid {
+		return team, models.TeamSettings{}, http.StatusConflict, "Team is archived"
+	}
+	settings, err := teamSettingsFor(tx, inviter.TeamID)
+	if err != nil {
+		return team, settings, http.StatusInternalServerError, "Failed to send invitation"
+	}
+	return team, settings, 0, ""
+}
+
+/**
+ * InviteMember invites a user to join the team
+ * POST /api/teams/{id}/invite
+ *
+ * A registered user gets a pending membership they answer from
+ * GET /api/pending. Any other address is emailed an invitation token
+ * (see inviteByEmail). Both answer alike, with the address, role and
+ * expiry, so inviting does not tell whether an address has an account. Without a role the team's default_member_role
+ * setting applies. Invitations expire after the team's
+ * invitation_expiry_days setting (default 14 days); inviting someone
+ * whose invitation lapsed renews it. A user who declined can be invited
+ * again after models.InvitationDeclineCooldown, or earlier with
+ * "force": true by a member who may manage members. A team without a
+ * free seat answers 402 (see team_seats.go).
+ *
+ * The team's invite_policy setting limits who may invite, and nobody may
+ * invite at a role granting a permission they lack; both answer 403 with
+ * a code (invite_policy, role_above_inviter).
+ */
+func InviteMember(c buffalo.Context) error {
+	var req InviteMemberRequest
+	if err := c.Bind(&req); err != nil {
+		return c.Render(http.StatusBadRequest, r.JSON(map[string]interface{}{
 			"success": false,
-			"message": "Failed to send invitation",
+			"message": "Invalid request data",
 			"error":   err.Error(),
 		}))
 	}
 
+	member, status, msg := teamMembership(c, "invite_members")
+	if status != 0 {
+		return renderTeamError(c, status, msg)
+	}
+	tx := mustTx(c)
+	team, settings, status, msg := invitingTeam(tx, member)
+	if status != 0 {
+		return renderTeamError(c, status, msg)
+	}
+	if v := invitePolicy(settings, member); v != nil {
+		return renderMemberPolicyViolation(c, v)
+	}
+
+	res, err := inviteToTeam(c, tx, team, settings, member, req, time.Now())
+	if err != nil {
+		c.Logger().Errorf("invite %s: %v", res.Email, err)
+		return renderTeamError(c, http.StatusInternalServerError, "Failed to send invitation")
+	}
+	switch res.Status {
+	case "invalid_email":
+		return renderTeamError(c, http.StatusBadRequest, "Invalid email")
+	case "invalid_role":
+		return renderTeamError(c, http.StatusBadRequest, "Invalid role")
+	case "already_member":
+		return renderTeamError(c, http.StatusConflict, "User is already a team member")
+	case "seat_limit":
+		return renderSeatLimit(c, *res.Seats)
+	case "role_above_inviter":
+		return renderMemberPolicyViolation(c, roleAboveInviterViolation)
+	case "declined_recently":
+		return c.Render(http.StatusConflict, r.JSON(map[string]interface{}{
+			"success":     false,
+			"message":     "User declined an invitation recently",
+			"retry_after": res.RetryAfter,
+		}))
+	}
 	return c.Render(http.StatusCreated, r.JSON(map[string]interface{}{
 		"success": true,
-		"data":    teamMember,
+		"data":    res,
 		"message": "Invitation sent successfully",
 	}))
 }
 
+// maxBatchInvitations bounds the entries of one batch invitation request.
+const maxBatchInvitations = 50
+
+/**
+ * BatchInviteRequest is the payload of InviteMembersBatch
+ */
+type BatchInviteRequest struct {
+	Invitations []InviteMemberRequest `json:"invitations"` // 1 to maxBatchInvitations entries
+}
+
+/**
+ * InviteMembersBatch invites several addresses at once
+ * POST /api/teams/{id}/invitations/batch
+ *
+ * Payload: invitations, up to 50 of { email, role, force } as for
+ * InviteMember. Permission and invite_policy are checked once; then
+ * each entry is handled on its own, so a bad address does not fail the
+ * others. The response lists one result per entry, in order (see
+ * inviteResult for the statuses), and the number invited. Emails go out
+ * after the request commits.
+ */
+func InviteMembersBatch(c buffalo.Context) error {
+	var req BatchInviteRequest
+	if err := c.Bind(&req); err != nil {
+		return renderTeamError(c, http.StatusBadRequest, "Invalid request data")
+	}
+	if n := len(req.Invitations); n == 0 || n > maxBatchInvitations {
+		return renderFieldError(c, "invitations", fmt.Errorf("must have 1 to %d entries", maxBatchInvitations))
+	}
+
+	member, status, msg := teamMembership(c, "invite_members")
+	if status != 0 {
+		return renderTeamError(c, status, msg)
+	}
+	tx := mustTx(c)
+	team, settings, status, msg := invitingTeam(tx, member)
+	if status != 0 {
+		return renderTeamError(c, status, msg)
+	}
+	if v := invitePolicy(settings, member); v != nil {
+		return renderMemberPolicyViolation(c, v)
+	}
+
+	now := time.Now()
+	seen := map[string]bool{}
+	results := make([]inviteResult, len(req.Invitations))
+	invited := 0
+	for i, inv := range req.Invitations {
+		if email, err := validators.NormalizeEmail(inv.Email); err == nil {
+			if seen[email] {
+				results[i] = inviteResult{Email: email, Status: "duplicate"}
+				continue
+			}
+			seen[email] = true
+		}
+
+		// A savepoint keeps a failed entry from aborting the transaction
+		err := tx.RawQuery("SAVEPOINT batch_invite").Exec()
+		if err == nil {
+			results[i], err = inviteToTeam(c, tx, team, settings, member, inv, now)
+		}
+		if err != nil {
+			c.Logger().Errorf("invite %s: %v", inv.Email, err)
+			results[i].Status = "error"
+			if err := tx.RawQuery("ROLLBACK TO SAVEPOINT batch_invite").Exec(); err != nil {
+				return renderTeamError(c, http.StatusInternalServerError, "Failed to send invitations")
+			}
+			continue
+		}
+		if err := tx.RawQuery("RELEASE SAVEPOINT batch_invite").Exec(); err != nil {
+			return renderTeamError(c, http.StatusInternalServerError, "Failed to send invitations")
+		}
+		if results[i].Status == "invited" {
+			invited++
+		}
+	}
+
+	return c.Render(http.StatusOK, r.JSON(map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"results": results,
+			"invited": invited,
+		},
+		"message": fmt.Sprintf("%d of %d invitations sent", invited, len(results)),
+	}))
+}
+
+// Codes of memberChangePolicy violations, returned as "code".
+const (
+	codeOwnerViaTransfer = "owner_via_transfer" // The owner role is only handed over by transfer
+	codeOwnerProtected   = "owner_protected"    // The owner's membership cannot be changed
+	codeAdminProtected   = "admin_protected"    // Only the owner can change admins
+	codeSelfChange       = "self_change"        // Nobody can change or remove their own membership
+)
+
+/**
+ * memberPolicyViolation is a rule broken by a membership change
+ */
+type memberPolicyViolation struct {
+	Status  int
+	Code    string
+	Message string
+}
+
+/**
+ * memberChangePolicy checks whether actor, who holds manage_members, may
+ * give target newRole or, when newRole is empty, remove target
+ *
+ * Rules:
+ * - The owner role can only change hands by ownership transfer (422)
+ * - The owner's membership cannot be changed or removed (403)
+ * - Only the owner can change or remove admins, the roles holding
+ *   manage_members (403)
+ * - Nobody can change their own role or remove themselves (403)
+ *
+ * @return *memberPolicyViolation - The first rule broken, nil if allowed
+ */
+func memberChangePolicy(actor, target models.TeamMember, newRole models.TeamMemberRole) *memberPolicyViolation {
+	if newRole == models.RoleOwner {
+		return &memberPolicyViolation{http.StatusUnprocessableEntity, codeOwnerViaTransfer, "Ownership can only be transferred"}
+	}
+	verb := "change"
+	if newRole == "" {
+		verb = "remove"
+	}
+	return memberActionPolicy(actor, target, verb)
+}
+
+/**
+ * memberActionPolicy checks the rules of memberChangePolicy protecting
+ * target from actor, for any action named by verb (such as "suspend")
+ *
+ * @return *memberPolicyViolation - The first rule broken, nil if allowed
+ */
+func memberActionPolicy(actor, target models.TeamMember, verb string) *memberPolicyViolation {
+	switch {
+	case target.Role == models.RoleOwner:
+		return &memberPolicyViolation{http.StatusForbidden, codeOwnerProtected, "Cannot " + verb + " the team owner"}
+	case actor.UserID == target.UserID:
+		return &memberPolicyViolation{http.StatusForbidden, codeSelfChange, "Cannot " + verb + " your own membership"}
+	case target.HasPermission("manage_members") && actor.Role != models.RoleOwner:
+		return &memberPolicyViolation{http.StatusForbidden, codeAdminProtected, "Only the owner can " + verb + " admins"}
+	}
+	return nil
+}
+
+// Codes of invitation policy violations, returned as "code".
+const (
+	codeInvitePolicy     = "invite_policy"      // The team's invite_policy excludes the inviter
+	codeRoleAboveInviter = "role_above_inviter" // The role grants a permission the inviter lacks
+)
+
+/**
+ * invitePolicy checks whether the team's invite_policy setting lets
+ * inviter, who holds invite_members, invite anyone (403)
+ *
+ * @return *memberPolicyViolation - The violation, nil if allowed
+ */
+func invitePolicy(settings models.TeamSettings, inviter models.TeamMember) *memberPolicyViolation {
+	if settings.MayInvite(inviter) {
+		return nil
+	}
+	msg := "Only the owner and admins can invite"
+	if settings.InvitePolicy == models.InviteOwnerOnly {
+		msg = "Only the owner can invite"
+	}
+	return &memberPolicyViolation{http.StatusForbidden, codeInvitePolicy, msg}
+}
+
+/**
+ * roleAboveInviter reports whether role grants a permission inviter lacks;
+ * nobody may invite at a role higher than their own. Unknown roles are
+ * left to assignableRole.
+ */
+func roleAboveInviter(tx *pop.Connection, teamID uuid.UUID, role models.TeamMemberRole, inviter models.TeamMember) (bool, error) {
+	var granted models.TeamRole
+	if err := tx.Where("team_id = ? AND name = ?", teamID, role).First(&granted); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	return roleAboveMember(granted, inviter), nil
+}
+
+// roleAboveInviterViolation answers an invitation at a role higher than
+// the inviter's.
+var roleAboveInviterViolation = &memberPolicyViolation{http.StatusForbidden, codeRoleAboveInviter, "Cannot invite at a role higher than your own"}
+
+/**
+ * renderMemberPolicyViolation renders v in the team envelope with its
+ * code
+ */
+func renderMemberPolicyViolation(c buffalo.Context, v *memberPolicyViolation) error {
+	return c.Render(v.Status, r.JSON(map[string]interface{}{
+		"success": false,
+		"message": v.Message,
+		"code":    v.Code,
+	}))
+}
+
 /**
  * UpdateMemberRole updates a team member's role
  * PUT /api/teams/{id}/members/{member_id}
+ *
+ * Requires manage_members and is subject to memberChangePolicy.
  */
 func UpdateMemberRole(c buffalo.Context) error {
 	teamID, err := uuid.FromString(c.Param("id"))
@@ -364,7 +1168,7 @@ func UpdateMemberRole(c buffalo.Context) error {
 		}))
 	}
 
-	userID, ok := c.Value("user_id").(uuid.UUID)
+	userID, ok := currentUserID(c)
 	if !ok {
 		return c.Render(http.StatusUnauthorized, r.JSON(map[string]interface{}{
 			"success": false,
@@ -399,8 +1203,23 @@ func UpdateMemberRole(c buffalo.Context) error {
 		}))
 	}
 
+	role := models.TeamMemberRole(strings.TrimSpace(req.Role))
+	if role == "" {
+		return renderTeamError(c, http.StatusBadRequest, "Invalid role")
+	}
+	if v := memberChangePolicy(userMember, member, role); v != nil {
+		return renderMemberPolicyViolation(c, v)
+	}
+	if ok, err := assignableRole(tx, teamID, role); err != nil || !ok {
+		return c.Render(http.StatusBadRequest, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Invalid role",
+		}))
+	}
+
 	// Update role
-	member.Role = models.TeamMemberRole(req.Role)
+	from := member.Role
+	member.Role = role
 	member.UpdatedAt = time.Now()
 
 	if err := tx.Update(&member); err != nil {
@@ -410,6 +1229,9 @@ func UpdateMemberRole(c buffalo.Context) error {
 			"error":   err.Error(),
 		}))
 	}
+	if role != from && (member.Status == "active" || member.Status == "suspended") {
+		notifyRoleChanged(c, tx, member, userID, from)
+	}
 
 	return c.Render(http.StatusOK, r.JSON(map[string]interface{}{
 		"success": true,
@@ -421,6 +1243,8 @@ func UpdateMemberRole(c buffalo.Context) error {
 /**
  * RemoveMember removes a member from the team
  * DELETE /api/teams/{id}/members/{member_id}
+ *
+ * Requires manage_members and is subject to memberChangePolicy.
  */
 func RemoveMember(c buffalo.Context) error {
 	teamID, err := uuid.FromString(c.Param("id"))
@@ -439,7 +1263,7 @@ func RemoveMember(c buffalo.Context) error {
 		}))
 	}
 
-	userID, ok := c.Value("user_id").(uuid.UUID)
+	userID, ok := currentUserID(c)
 	if !ok {
 		return c.Render(http.StatusUnauthorized, r.JSON(map[string]interface{}{
 			"success": false,
@@ -474,12 +1298,8 @@ func RemoveMember(c buffalo.Context) error {
 		}))
 	}
 
-	// Prevent removing team owner
-	if member.Role == models.RoleOwner {
-		return c.Render(http.StatusBadRequest, r.JSON(map[string]interface{}{
-			"success": false,
-			"message": "Cannot remove team owner",
-		}))
+	if v := memberChangePolicy(userMember, member, ""); v != nil {
+		return renderMemberPolicyViolation(c, v)
 	}
 
 	// Remove member
@@ -490,6 +1310,9 @@ func RemoveMember(c buffalo.Context) error {
 			"error":   err.Error(),
 		}))
 	}
+	if member.Status == "active" || member.Status == "suspended" {
+		notifyRemoved(c, tx, member, userID)
+	}
 
 	return c.Render(http.StatusOK, r.JSON(map[string]interface{}{
 		"success": true,
@@ -500,6 +1323,9 @@ func RemoveMember(c buffalo.Context) error {
 /**
  * AcceptInvitation accepts a team invitation
  * POST /api/teams/invitations/{id}/accept
+ *
+ * Expired invitations answer 410; the inviter has to send a new one. A
+ * team without a free seat answers 402, an archived team 409.
  */
 func AcceptInvitation(c buffalo.Context) error {
 	memberID, err := uuid.FromString(c.Param("id"))
@@ -510,7 +1336,7 @@ func AcceptInvitation(c buffalo.Context) error {
 		}))
 	}
 
-	userID, ok := c.Value("user_id").(uuid.UUID)
+	userID, ok := currentUserID(c)
 	if !ok {
 		return c.Render(http.StatusUnauthorized, r.JSON(map[string]interface{}{
 			"success": false,
@@ -529,11 +1355,37 @@ func AcceptInvitation(c buffalo.Context) error {
 		}))
 	}
 
+	now := time.Now()
+	if member.InvitationExpired(now) {
+		return c.Render(http.StatusGone, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Invitation expired",
+		}))
+	}
+	archived, err := teamArchived(tx, member.TeamID)
+	var seats seatUsage
+	if err == nil && !archived {
+		seats, err = teamSeats(tx, member.TeamID, true)
+	}
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to accept invitation",
+			"error":   err.Error(),
+		}))
+	}
+	if archived {
+		return renderTeamError(c, http.StatusConflict, "Team is archived")
+	}
+	if seats.Full() {
+		return renderSeatLimit(c, seats)
+	}
+
 	// Accept invitation
 	member.Status = "active"
-	now := time.Now()
 	member.JoinedAt = &now
-	member.UpdatedAt = time.Now()
+	member.ExpiresAt = nulls.Time{}
+	member.UpdatedAt = now
 
 	if err := tx.Update(&member); err != nil {
 		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
@@ -553,6 +1405,9 @@ func AcceptInvitation(c buffalo.Context) error {
 /**
  * DeclineInvitation declines a team invitation
  * POST /api/teams/invitations/{id}/decline
+ *
+ * The membership row is kept with status "declined" (see InviteMember
+ * for re-invites).
  */
 func DeclineInvitation(c buffalo.Context) error {
 	memberID, err := uuid.FromString(c.Param("id"))
@@ -563,7 +1418,7 @@ func DeclineInvitation(c buffalo.Context) error {
 		}))
 	}
 
-	userID, ok := c.Value("user_id").(uuid.UUID)
+	userID, ok := currentUserID(c)
 	if !ok {
 		return c.Render(http.StatusUnauthorized, r.JSON(map[string]interface{}{
 			"success": false,
@@ -582,8 +1437,13 @@ func DeclineInvitation(c buffalo.Context) error {
 		}))
 	}
 
-	// Remove invitation
-	if err := tx.Destroy(&member); err != nil {
+	// Keep the row so admins see the answer and re-invites can be throttled
+	now := time.Now()
+	member.Status = "declined"
+	member.DeclinedAt = nulls.NewTime(now)
+	member.ExpiresAt = nulls.Time{}
+	member.UpdatedAt = now
+	if err := tx.Update(&member); err != nil {
 		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
 			"success": false,
 			"message": "Failed to decline invitation",
@@ -0,0 +1,123 @@
+/**
+ * Team Archive Actions - Archiving Finished Teams
+ *
+ * A team whose engagement has ended can be archived instead of deleted.
+ * Archived teams drop out of GET /api/teams unless ?archived=true is
+ * given, and they take no new entries, invitations or members (409), but
+ * their members can still read everything, including the team's tracks,
+ * summary and timesheets, for year-end reports. Only the owner archives
+ * and unarchives a team (delete_team); both notify webhooks subscribed
+ * to team.updated.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-22
+ */
+package actions
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"backend/models"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/nulls"
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gofrs/uuid"
+)
+
+// errTeamArchived rejects changes to an archived team.
+var errTeamArchived = errors.New("team is archived")
+
+/**
+ * teamArchived reports whether a team is archived
+ */
+func teamArchived(tx *pop.Connection, teamID uuid.UUID) (bool, error) {
+	return tx.Where("id = ? AND archived_at IS NOT NULL", teamID).Exists(&models.Team{})
+}
+
+/**
+ * entryTeamArchived reports whether an entry belongs to an archived team
+ */
+func entryTeamArchived(tx *pop.Connection, item models.TimeTrac) (bool, error) {
+	if !item.TeamID.Valid {
+		return false, nil
+	}
+	return teamArchived(tx, item.TeamID.UUID)
+}
+
+/**
+ * renderEntryTeamArchived answers 409 for a change that would add time
+ * to an archived team
+ */
+func renderEntryTeamArchived(c buffalo.Context, item models.TimeTrac) error {
+	return c.Render(http.StatusConflict, r.JSON(map[string]string{"error": "team is archived", "team_id": item.TeamID.UUID.String()}))
+}
+
+/**
+ * ArchiveTeam archives a team
+ * POST /api/teams/{id}/archive
+ *
+ * Owner only. Archiving an archived team answers 409.
+ */
+func ArchiveTeam(c buffalo.Context) error {
+	return setTeamArchived(c, true)
+}
+
+/**
+ * UnarchiveTeam makes an archived team active again
+ * POST /api/teams/{id}/unarchive
+ *
+ * Owner only. Unarchiving an active team answers 409.
+ */
+func UnarchiveTeam(c buffalo.Context) error {
+	return setTeamArchived(c, false)
+}
+
+/**
+ * setTeamArchived archives or unarchives the team named by the id
+ * parameter
+ */
+func setTeamArchived(c buffalo.Context, archive bool) error {
+	member, status, msg := teamMembership(c, "delete_team")
+	if status != 0 {
+		return renderTeamError(c, status, msg)
+	}
+	tx := mustTx(c)
+	var team models.Team
+	if err := tx.Find(&team, member.TeamID); err != nil {
+		return renderTeamError(c, http.StatusNotFound, "Team not found")
+	}
+	if team.ArchivedAt.Valid == archive {
+		if archive {
+			return renderTeamError(c, http.StatusConflict, "Team is already archived")
+		}
+		return renderTeamError(c, http.StatusConflict, "Team is not archived")
+	}
+
+	now := time.Now()
+	team.ArchivedAt, team.UpdatedAt = nulls.Time{}, now
+	message := "Team unarchived successfully"
+	if archive {
+		team.ArchivedAt = nulls.NewTime(now)
+		message = "Team archived successfully"
+	}
+	if err := tx.UpdateColumns(&team, "archived_at", "updated_at"); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to update team",
+			"error":   err.Error(),
+		}))
+	}
+	if err := queueTeamWebhookDeliveries(tx, "team.updated", team, now, requestIDOf(c)); err != nil {
+		c.Logger().Errorf("webhook queue team.updated %s: %v", team.ID, err)
+	}
+
+	return c.Render(http.StatusOK, r.JSON(map[string]interface{}{
+		"success": true,
+		"data":    team,
+		"message": message,
+	}))
+}
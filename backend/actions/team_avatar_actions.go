@@ -0,0 +1,220 @@
+/**
+ * Team Avatar Actions - Pictures Identifying Teams
+ *
+ * Owners and admins (manage_team) upload a team picture as
+ * multipart/form-data. It is validated like entry photos (see
+ * readImageUpload), turned upright, and stored in the photo store at
+ * most 512px on each side together with a 128px thumbnail. Teams carry
+ * the serving path as avatar_url wherever they are returned.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-22
+ */
+package actions
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"backend/models"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/nulls"
+	"github.com/gofrs/uuid"
+)
+
+// Team avatar sizes, in pixels along the longer side.
+const (
+	teamAvatarSize      = 512
+	teamAvatarThumbSize = 128
+)
+
+/**
+ * allowedAvatarTypes lists the MIME types accepted for team avatars;
+ * they must be decodable for resizing, which rules out WebP
+ */
+var allowedAvatarTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+}
+
+/**
+ * teamAvatarURL returns the API path serving a team's avatar
+ */
+func teamAvatarURL(teamID uuid.UUID) string {
+	return "/api/teams/" + teamID.String() + "/avatar"
+}
+
+/**
+ * forgetTeamAvatar removes a team's avatar objects from the store once
+ * the request has committed; failures leave harmless orphans
+ */
+func forgetTeamAvatar(c buffalo.Context, team models.Team) {
+	keys := []string{team.AvatarKey.String, team.AvatarThumbKey.String}
+	afterCommit(c, func() {
+		st, err := photoStore()
+		if err != nil {
+			return
+		}
+		for _, key := range keys {
+			if key != "" {
+				_ = st.Delete(context.Background(), key)
+			}
+		}
+	})
+}
+
+/**
+ * UploadTeamAvatar sets a team's avatar
+ * POST /api/teams/{id}/avatar
+ *
+ * Accepts multipart/form-data with the image in the `avatar` field. JPEG
+ * and PNG are accepted, detected from the content, up to PHOTO_MAX_BYTES
+ * (413 beyond, 415 for other types, 422 if the image cannot be decoded).
+ * An existing avatar is replaced. Requires manage_team.
+ */
+func UploadTeamAvatar(c buffalo.Context) error {
+	member, status, msg := teamMembership(c, "manage_team")
+	if status != 0 {
+		return renderTeamError(c, status, msg)
+	}
+	tx := mustTx(c)
+	var team models.Team
+	if err := tx.Find(&team, member.TeamID); err != nil {
+		return renderTeamError(c, http.StatusNotFound, "Team not found")
+	}
+
+	data, mime, status, msg := readImageUpload(c, "avatar", allowedAvatarTypes)
+	if status != 0 {
+		return renderTeamError(c, status, msg)
+	}
+	img, err := decodeUprightImage(data)
+	if err != nil {
+		return renderTeamError(c, http.StatusUnprocessableEntity, "Cannot read image: "+err.Error())
+	}
+	full, err := encodeImage(fitImage(img, teamAvatarSize), mime)
+	var thumb []byte
+	if err == nil {
+		thumb, err = encodeImage(fitImage(img, teamAvatarThumbSize), mime)
+	}
+	if err != nil {
+		return renderTeamError(c, http.StatusInternalServerError, "Failed to process avatar")
+	}
+
+	st, err := photoStore()
+	if err != nil {
+		return renderTeamError(c, http.StatusInternalServerError, "Avatar storage unavailable")
+	}
+	base := fmt.Sprintf("teams/%s/%s", team.ID, uuid.Must(uuid.NewV4()))
+	ext := photoExtensions[mime]
+	key, thumbKey := base+"."+ext, base+"-thumb."+ext
+	if err := st.Put(c, key, bytes.NewReader(full), int64(len(full)), mime); err != nil {
+		return renderTeamError(c, http.StatusInternalServerError, "Failed to store avatar")
+	}
+	if err := st.Put(c, thumbKey, bytes.NewReader(thumb), int64(len(thumb)), mime); err != nil {
+		_ = st.Delete(c, key)
+		return renderTeamError(c, http.StatusInternalServerError, "Failed to store avatar")
+	}
+
+	prev := team
+	team.AvatarKey = nulls.NewString(key)
+	team.AvatarThumbKey = nulls.NewString(thumbKey)
+	team.AvatarURL = nulls.NewString(teamAvatarURL(team.ID))
+	team.UpdatedAt = time.Now()
+	if err := tx.Update(&team); err != nil {
+		_ = st.Delete(c, key)
+		_ = st.Delete(c, thumbKey)
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to save avatar",
+			"error":   err.Error(),
+		}))
+	}
+	forgetTeamAvatar(c, prev)
+
+	return c.Render(http.StatusOK, r.JSON(map[string]interface{}{
+		"success": true,
+		"data":    team,
+		"message": "Avatar updated successfully",
+	}))
+}
+
+/**
+ * GetTeamAvatar serves a team's avatar
+ * GET /api/teams/{id}/avatar[?size=thumb]
+ *
+ * Available to members, including suspended ones and invitees, so that
+ * pending invitations can show it. Redirects to a signed URL or streams
+ * the image like entry photos; 404 when the team has no avatar.
+ */
+func GetTeamAvatar(c buffalo.Context) error {
+	teamID, err := uuid.FromString(c.Param("id"))
+	if err != nil {
+		return renderTeamError(c, http.StatusBadRequest, "Invalid team ID")
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return renderTeamError(c, http.StatusUnauthorized, "Unauthorized")
+	}
+	tx := mustTx(c)
+	known, err := tx.Where("team_id = ? AND user_id = ? AND status IN ('active', 'suspended', 'pending')", teamID, userID).
+		Exists(&models.TeamMember{})
+	if err != nil || !known {
+		return renderTeamError(c, http.StatusForbidden, "Access denied")
+	}
+
+	var team models.Team
+	if err := tx.Find(&team, teamID); err != nil {
+		return renderTeamError(c, http.StatusNotFound, "Team not found")
+	}
+	key := team.AvatarKey.String
+	if c.Param("size") == "thumb" {
+		key = team.AvatarThumbKey.String
+	}
+	if key == "" {
+		return renderTeamError(c, http.StatusNotFound, "Team has no avatar")
+	}
+	return serveStoredImage(c, key, "avatar")
+}
+
+/**
+ * DeleteTeamAvatar removes a team's avatar
+ * DELETE /api/teams/{id}/avatar
+ *
+ * Requires manage_team.
+ */
+func DeleteTeamAvatar(c buffalo.Context) error {
+	member, status, msg := teamMembership(c, "manage_team")
+	if status != 0 {
+		return renderTeamError(c, status, msg)
+	}
+	tx := mustTx(c)
+	var team models.Team
+	if err := tx.Find(&team, member.TeamID); err != nil {
+		return renderTeamError(c, http.StatusNotFound, "Team not found")
+	}
+
+	prev := team
+	team.AvatarKey = nulls.String{}
+	team.AvatarThumbKey = nulls.String{}
+	team.AvatarURL = nulls.String{}
+	team.UpdatedAt = time.Now()
+	if err := tx.Update(&team); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to remove avatar",
+			"error":   err.Error(),
+		}))
+	}
+	forgetTeamAvatar(c, prev)
+
+	return c.Render(http.StatusOK, r.JSON(map[string]interface{}{
+		"success": true,
+		"data":    team,
+		"message": "Avatar removed successfully",
+	}))
+}
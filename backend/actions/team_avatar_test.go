@@ -0,0 +1,88 @@
+package actions
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/png"
+	"net/http"
+	"time"
+
+	"backend/models"
+
+	"github.com/gobuffalo/httptest"
+)
+
+// testPNG encodes a w×h image as PNG.
+func (as *ActionSuite) testPNG(w, h int) []byte {
+	var buf bytes.Buffer
+	as.NoError(png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h))))
+	return buf.Bytes()
+}
+
+func (as *ActionSuite) Test_TeamAvatar() {
+	ownerToken := as.registerToken("avatar-owner@example.com")
+	owner := as.userID(ownerToken)
+	memberToken := as.registerToken("avatar-member@example.com")
+	member := as.userID(memberToken)
+	outsiderToken := as.registerToken("avatar-outsider@example.com")
+	team := as.teamFixture("Avatar Team", owner)
+	m := as.inviteFixture(team, member, owner, time.Now())
+	as.NoError(as.DB.RawQuery("UPDATE team_members SET status = 'active' WHERE id = ?", m.ID).Exec())
+
+	upload := func(token string, data []byte) *httptest.Response {
+		req := as.HTML("/api/teams/%s/avatar", team.ID)
+		req.Headers["Authorization"] = "Bearer " + token
+		res, err := req.MultiPartPost(nil, httptest.File{ParamName: "avatar", FileName: "avatar.png", Reader: bytes.NewReader(data)})
+		as.NoError(err)
+		return res
+	}
+	get := func(token, query string) *httptest.Response {
+		req := as.HTML("/api/teams/%s/avatar%s", team.ID, query)
+		req.Headers["Authorization"] = "Bearer " + token
+		return req.Get()
+	}
+
+	as.Equal(http.StatusNotFound, get(ownerToken, "").Code)
+
+	// Only manage_team may upload
+	as.Equal(http.StatusForbidden, upload(memberToken, as.testPNG(8, 8)).Code)
+
+	// Large images are scaled down, with a thumbnail
+	res := upload(ownerToken, as.testPNG(1024, 768))
+	as.Equal(http.StatusOK, res.Code)
+	var body struct {
+		Data models.Team `json:"data"`
+	}
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &body))
+	as.Equal(teamAvatarURL(team.ID), body.Data.AvatarURL.String)
+
+	for query, size := range map[string]int{"": teamAvatarSize, "?size=thumb": teamAvatarThumbSize} {
+		res := get(memberToken, query)
+		as.Equal(http.StatusOK, res.Code)
+		as.Equal("image/png", res.Header().Get("Content-Type"))
+		cfg, err := png.DecodeConfig(bytes.NewReader(res.Body.Bytes()))
+		as.NoError(err)
+		as.Equal(size, cfg.Width)
+	}
+	as.Equal(http.StatusForbidden, get(outsiderToken, "").Code)
+
+	// Rejected uploads leave the avatar in place
+	as.Equal(http.StatusUnsupportedMediaType, upload(ownerToken, []byte("GIF89a\x01\x00\x01\x00\x00\x00\x00;")).Code)
+	as.Equal(http.StatusUnprocessableEntity, upload(ownerToken, []byte("\x89PNG\r\n\x1a\nnot really a png")).Code)
+	as.T().Setenv("PHOTO_MAX_BYTES", "1024")
+	as.Equal(http.StatusRequestEntityTooLarge, upload(ownerToken, append(as.testPNG(8, 8), make([]byte, 2048)...)).Code)
+	as.Equal(http.StatusOK, get(memberToken, "").Code)
+
+	// Only manage_team may remove it
+	req := as.HTML("/api/teams/%s/avatar", team.ID)
+	req.Headers["Authorization"] = "Bearer " + memberToken
+	as.Equal(http.StatusForbidden, req.Delete().Code)
+	req.Headers["Authorization"] = "Bearer " + ownerToken
+	as.Equal(http.StatusOK, req.Delete().Code)
+	as.Equal(http.StatusNotFound, get(memberToken, "").Code)
+	var stored models.Team
+	as.NoError(as.DB.Find(&stored, team.ID))
+	as.False(stored.AvatarKey.Valid)
+	as.False(stored.AvatarURL.Valid)
+}
@@ -0,0 +1,361 @@
+/**
+ * Team Invitation Actions - Email Invitations for New Users
+ *
+ * Inviting an address without an account (POST /api/teams/{id}/invite)
+ * emails a link carrying a single-use token valid for the team's
+ * invitation_expiry_days setting. The link opens the app, which:
+ * - previews the invitation with GET /api/invitations/{token}; this is
+ *   public so the sign-up page can show which team is waiting
+ * - redeems it with POST /api/invitations/{token}/accept once the person
+ *   has registered or signed in, creating their team_members row
+ *
+ * Registering with an invited address also turns its open invitations
+ * into pending memberships, so they appear in GET /api/pending even if
+ * the email is lost. Re-inviting an address replaces its open
+ * invitation; only the newest link works.
+ *
+ * Configuration:
+ * - TEAM_INVITATION_URL: page the emailed link points to; the token is
+ *   added as the `token` query parameter (default
+ *   http://localhost:8100/invitations)
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-21
+ */
+package actions
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"backend/mailer"
+	"backend/models"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/envy"
+	"github.com/gobuffalo/nulls"
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gofrs/uuid"
+)
+
+/**
+ * invitationURL returns the link emailed for an invitation token
+ */
+func invitationURL(token string) string {
+	u, err := url.Parse(envy.Get("TEAM_INVITATION_URL", "http://localhost:8100/invitations"))
+	if err != nil {
+		u = &url.URL{Path: "/invitations"}
+	}
+	q := u.Query()
+	q.Set("token", token)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+/**
+ * inviteByEmail stores an invitation for an address without an account
+ * and emails its token after commit
+ *
+ * @param c - Request context
+ * @param tx - Request transaction
+ * @param team - Team the address is invited to
+ * @param ttl - Validity of the invitation
+ * @param inviter - Inviting user
+ * @param email - Normalized address
+ * @param role - Role granted on acceptance
+ * @return models.TeamInvitation - Stored invitation
+ * @return error - Database error
+ */
+func inviteByEmail(c buffalo.Context, tx *pop.Connection, team models.Team, ttl time.Duration, inviter uuid.UUID, email string, role models.TeamMemberRole) (models.TeamInvitation, error) {
+	raw, err := newRefreshTokenValue()
+	if err != nil {
+		return models.TeamInvitation{}, err
+	}
+	// Only the newest invitation for an address works
+	if err := tx.RawQuery(`DELETE FROM team_invitations WHERE team_id = ? AND email = ? AND accepted_at IS NULL`,
+		team.ID, email).Exec(); err != nil {
+		return models.TeamInvitation{}, err
+	}
+	inv := models.TeamInvitation{
+		TeamID:    team.ID,
+		Email:     email,
+		Role:      role,
+		TokenHash: models.HashRefreshToken(raw),
+		InvitedBy: nulls.NewUUID(inviter),
+		ExpiresAt: time.Now().Add(ttl).UTC(),
+	}
+	if err := tx.Create(&inv); err != nil {
+		return models.TeamInvitation{}, err
+	}
+
+	link := invitationURL(raw)
+	afterCommit(c, func() {
+		sendMail(mailer.Message{
+			To:      email,
+			Subject: fmt.Sprintf("You're invited to join %s on TimeTrac", team.Name),
+			Text: fmt.Sprintf("You have been invited to join the team %q on TimeTrac.\n\n"+
+				"Open this link to create an account or sign in and accept:\n\n%s\n\n"+
+				"The invitation expires in %d days. If you did not expect it, you can ignore this email.\n",
+				team.Name, link, int(ttl.Hours()/24)),
+		})
+	})
+
+	return inv, nil
+}
+
+/**
+ * findInvitation loads the invitation for a raw token; when it cannot be
+ * redeemed the returned status and message describe the error response
+ *
+ * @param tx - Database connection
+ * @param raw - Token from the link
+ * @param lock - Lock the row for redemption
+ */
+func findInvitation(tx *pop.Connection, raw string, lock bool) (models.TeamInvitation, int, string) {
+	var inv models.TeamInvitation
+	q := "SELECT * FROM team_invitations WHERE token_hash = ?"
+	if lock {
+		q += " FOR UPDATE"
+	}
+	err := tx.RawQuery(q, models.HashRefreshToken(raw)).First(&inv)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return inv, http.StatusNotFound, "Invitation not found"
+	case err != nil:
+		return inv, http.StatusInternalServerError, "Failed to load invitation"
+	case inv.AcceptedAt.Valid:
+		return inv, http.StatusConflict, "Invitation already accepted"
+	case inv.Expired(time.Now()):
+		return inv, http.StatusGone, "Invitation expired"
+	}
+	return inv, 0, ""
+}
+
+/**
+ * InvitationShow previews an emailed invitation
+ * GET /api/invitations/{token}
+ *
+ * Responses:
+ * - 200 with team name and description, invited address, role, inviter
+ *   and expiry
+ * - 404 for an unknown token, 409 once accepted, 410 once expired
+ */
+func InvitationShow(c buffalo.Context) error {
+	tx := mustTx(c)
+	inv, status, msg := findInvitation(tx, c.Param("token"), false)
+	if status != 0 {
+		return c.Render(status, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": msg,
+		}))
+	}
+
+	var preview struct {
+		TeamName        string       `db:"team_name" json:"team_name"`
+		TeamDescription nulls.String `db:"team_description" json:"team_description"`
+		InviterEmail    nulls.String `db:"inviter_email" json:"inviter_email"`
+	}
+	if err := tx.RawQuery(`
+		SELECT t.name AS team_name, t.description AS team_description, u.email AS inviter_email
+		FROM teams t LEFT JOIN users u ON u.id = ?
+		WHERE t.id = ?`, inv.InvitedBy, inv.TeamID).First(&preview); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to load invitation",
+		}))
+	}
+
+	return c.Render(http.StatusOK, r.JSON(map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"team_id":          inv.TeamID,
+			"team_name":        preview.TeamName,
+			"team_description": preview.TeamDescription,
+			"inviter_email":    preview.InviterEmail,
+			"email":            inv.Email,
+			"role":             inv.Role,
+			"expires_at":       inv.ExpiresAt,
+		},
+		"message": "Invitation retrieved successfully",
+	}))
+}
+
+/**
+ * InvitationAccept redeems an emailed invitation for the signed-in user
+ * POST /api/invitations/{token}/accept
+ *
+ * The token proves access to the invited mailbox, so it may be redeemed
+ * by an account with a different address. A pending, expired or
+ * declined membership the user already has for the team (see
+ * surfaceEmailInvitations) is activated.
+ *
+ * Responses:
+ * - 200 with the active membership
+ * - 404 for an unknown token, 410 once expired
+ * - 409 once accepted, or when the user already belongs to the team
+ * - 402 when the team has no free seat, 409 when it is archived
+ */
+func InvitationAccept(c buffalo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Unauthorized",
+		}))
+	}
+
+	tx := mustTx(c)
+	inv, status, msg := findInvitation(tx, c.Param("token"), true)
+	if status != 0 {
+		return c.Render(status, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": msg,
+		}))
+	}
+
+	archived, err := teamArchived(tx, inv.TeamID)
+	var seats seatUsage
+	if err == nil && !archived {
+		seats, err = teamSeats(tx, inv.TeamID, true)
+	}
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to accept invitation",
+			"error":   err.Error(),
+		}))
+	}
+	if archived {
+		return renderTeamError(c, http.StatusConflict, "Team is archived")
+	}
+	if seats.Full() {
+		return renderSeatLimit(c, seats)
+	}
+
+	now := time.Now().UTC()
+	var joined struct {
+		ID uuid.UUID `db:"id"`
+	}
+	err = tx.RawQuery(`
+		INSERT INTO team_members (id, team_id, user_id, role, status, invited_by, joined_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, 'active', ?, ?, ?, ?)
+		ON CONFLICT (team_id, user_id) DO UPDATE
+		SET status = 'active', role = EXCLUDED.role, joined_at = EXCLUDED.joined_at, expires_at = NULL,
+		    declined_at = NULL, updated_at = EXCLUDED.updated_at
+		WHERE team_members.status IN ('pending', 'expired', 'declined')
+		RETURNING id`,
+		uuid.Must(uuid.NewV4()), inv.TeamID, userID, inv.Role, inv.InvitedBy, now, now, now).First(&joined)
+	if errors.Is(err, sql.ErrNoRows) {
+		return c.Render(http.StatusConflict, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "User is already a team member",
+		}))
+	}
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to accept invitation",
+			"error":   err.Error(),
+		}))
+	}
+
+	inv.AcceptedAt = nulls.NewTime(now)
+	inv.AcceptedBy = nulls.NewUUID(userID)
+	if err := tx.UpdateColumns(&inv, "accepted_at", "accepted_by", "updated_at"); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to accept invitation",
+			"error":   err.Error(),
+		}))
+	}
+	var member models.TeamMember
+	if err := tx.Find(&member, joined.ID); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to accept invitation",
+			"error":   err.Error(),
+		}))
+	}
+
+	return c.Render(http.StatusOK, r.JSON(map[string]interface{}{
+		"success": true,
+		"data":    member,
+		"message": "Invitation accepted successfully",
+	}))
+}
+
+/**
+ * surfaceEmailInvitations gives an account a pending membership for
+ * every open invitation sent to its address. Only call it once the user
+ * proved the address is theirs (a verified Apple address, a consumed
+ * magic link): a pending membership is accepted without the token.
+ *
+ * @param tx - Request transaction
+ * @param u - User whose address is verified
+ * @param now - Reference time
+ * @return error - Database error
+ */
+func surfaceEmailInvitations(tx *pop.Connection, u models.User, now time.Time) error {
+	now = now.UTC()
+	return tx.RawQuery(`
+		INSERT INTO team_members (id, team_id, user_id, role, status, invited_by, expires_at, created_at, updated_at)
+		SELECT gen_random_uuid(), team_id, ?, role, 'pending', invited_by, expires_at, created_at, ?
+		FROM team_invitations
+		WHERE email = ? AND accepted_at IS NULL AND expires_at > ?
+		ON CONFLICT (team_id, user_id) DO NOTHING`, u.ID, now, u.Email, now).Exec()
+}
+
+/**
+ * ExpireTeamInvitations marks pending memberships past their expires_at
+ * as expired and, unless INVITATION_EXPIRY_NOTIFY is "false", emails
+ * each inviter that their invitation lapsed
+ *
+ * The UPDATE ... RETURNING statement only matches rows that are still
+ * pending, so concurrent runs never report an invitation twice.
+ *
+ * @param db - Database connection
+ * @param now - Reference time for the expiry check
+ * @return int - Number of expired invitations
+ */
+func ExpireTeamInvitations(db *pop.Connection, now time.Time) (int, error) {
+	var rows []struct {
+		TeamName     string       `db:"team_name"`
+		InviteeEmail string       `db:"invitee_email"`
+		InviterEmail nulls.String `db:"inviter_email"`
+	}
+	err := db.RawQuery(`
+		WITH expired AS (
+			UPDATE team_members SET status = 'expired', updated_at = ?
+			WHERE status = 'pending' AND expires_at <= ?
+			RETURNING team_id, user_id, invited_by
+		)
+		SELECT t.name AS team_name, invitee.email AS invitee_email, inviter.email AS inviter_email
+		FROM expired e
+		JOIN teams t ON t.id = e.team_id
+		JOIN users invitee ON invitee.id = e.user_id
+		LEFT JOIN users inviter ON inviter.id = e.invited_by
+	`, now.UTC(), now.UTC()).All(&rows)
+	if err != nil {
+		return 0, err
+	}
+
+	if envy.Get("INVITATION_EXPIRY_NOTIFY", "true") != "false" {
+		for _, row := range rows {
+			if !row.InviterEmail.Valid {
+				continue
+			}
+			sendMail(mailer.Message{
+				To:      row.InviterEmail.String,
+				Subject: fmt.Sprintf("Your invitation to %s expired", row.TeamName),
+				Text: fmt.Sprintf("%s did not answer your invitation to join %q on TimeTrac before it expired.\n\n"+
+					"You can invite them again from the team page.\n", row.InviteeEmail, row.TeamName),
+			})
+		}
+	}
+	return len(rows), nil
+}
@@ -0,0 +1,422 @@
+/**
+ * Team Join Code Actions - Joining a Team with a Shareable Code
+ *
+ * For workshops and similar events, owners and admins (manage_members)
+ * create a short code instead of emailing every participant:
+ * - POST /api/teams/{id}/join-codes creates one, optionally expiring,
+ *   limited to a number of uses and granting a role other than the
+ *   team's default_member_role
+ * - GET /api/teams/{id}/join-codes lists the codes still usable
+ * - DELETE /api/teams/{id}/join-codes/{code_id} revokes one
+ *
+ * Anyone signed in joins with POST /api/teams/join. Uses are counted
+ * down in the same statement that checks them, so concurrent joins never
+ * exceed the limit. Teams switch the feature off with the
+ * join_codes_enabled setting; existing codes then stop working too.
+ * Attempts are limited per user and per client IP, and every code that
+ * does not work gets the same answer, so codes cannot be guessed.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-22
+ */
+package actions
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/base32"
+	"errors"
+	"net/http"
+	"time"
+
+	"backend/models"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/nulls"
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gofrs/uuid"
+)
+
+// maxJoinCodeUses bounds the use limit of a join code.
+const maxJoinCodeUses = 10000
+
+var (
+	// joinCodeUserLimit bounds the join attempts of one user.
+	joinCodeUserLimit = newRateLimiter(20, 15*time.Minute)
+	// joinCodeIPLimit bounds the join attempts from one client.
+	joinCodeIPLimit = newRateLimiter(60, 15*time.Minute)
+)
+
+/**
+ * JoinCodeRequest is the payload for creating a join code
+ */
+type JoinCodeRequest struct {
+	Role      models.TeamMemberRole `json:"role"`       // Defaults to the team's default_member_role
+	ExpiresAt *time.Time            `json:"expires_at"` // Omitted: never expires
+	MaxUses   *int                  `json:"max_uses"`   // Omitted: unlimited
+}
+
+/**
+ * JoinTeamRequest is the payload for joining a team with a code
+ */
+type JoinTeamRequest struct {
+	Code string `json:"code"`
+}
+
+/**
+ * newJoinCode returns a random 8-character code; base32 has no 0 or 1,
+ * so it reads unambiguously off a slide
+ */
+func newJoinCode() (string, error) {
+	buf := make([]byte, 5)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base32.StdEncoding.EncodeToString(buf), nil
+}
+
+/**
+ * CreateTeamJoinCode creates a join code
+ * POST /api/teams/{id}/join-codes
+ *
+ * Payload (all optional): role, expires_at (RFC 3339, in the future),
+ * max_uses (1 to 10000). Answers 403 while the team has join codes
+ * disabled and 409 while it is archived. Requires manage_members; the
+ * invite rules of InviteMember apply to the creator and the role.
+ */
+func CreateTeamJoinCode(c buffalo.Context) error {
+	member, status, msg := teamMembership(c, "manage_members")
+	if status != 0 {
+		return renderTeamError(c, status, msg)
+	}
+	var req JoinCodeRequest
+	if c.Request().ContentLength > 0 {
+		if err := c.Bind(&req); err != nil {
+			return renderTeamError(c, http.StatusBadRequest, "Invalid request data")
+		}
+	}
+
+	tx := mustTx(c)
+	settings, err := teamSettingsFor(tx, member.TeamID)
+	if err != nil {
+		return renderTeamError(c, http.StatusInternalServerError, "Failed to load team settings")
+	}
+	if !settings.JoinCodesEnabled {
+		return renderTeamError(c, http.StatusForbidden, "Join codes are disabled for this team")
+	}
+	if v := invitePolicy(settings, member); v != nil {
+		return renderMemberPolicyViolation(c, v)
+	}
+	archived, err := teamArchived(tx, member.TeamID)
+	if err != nil {
+		return renderTeamError(c, http.StatusInternalServerError, "Failed to create join code")
+	}
+	if archived {
+		return renderTeamError(c, http.StatusConflict, "Team is archived")
+	}
+
+	now := time.Now().UTC()
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(now) {
+		return renderFieldError(c, "expires_at", errors.New("must be in the future"))
+	}
+	if req.MaxUses != nil && (*req.MaxUses < 1 || *req.MaxUses > maxJoinCodeUses) {
+		return renderFieldError(c, "max_uses", errors.New("must be from 1 to 10000"))
+	}
+	if req.Role == "" {
+		req.Role = settings.DefaultMemberRole
+	}
+	ok, err := assignableRole(tx, member.TeamID, req.Role)
+	if err == nil && !ok {
+		return renderFieldError(c, "role", errors.New("must be a role of this team, not owner"))
+	}
+	var above bool
+	if err == nil {
+		above, err = roleAboveInviter(tx, member.TeamID, req.Role, member)
+	}
+	if err == nil && above {
+		return renderMemberPolicyViolation(c, roleAboveInviterViolation)
+	}
+
+	jc := models.TeamJoinCode{
+		ID:        uuid.Must(uuid.NewV4()),
+		TeamID:    member.TeamID,
+		Role:      req.Role,
+		CreatedBy: nulls.NewUUID(member.UserID),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if req.ExpiresAt != nil {
+		jc.ExpiresAt = nulls.NewTime(req.ExpiresAt.UTC())
+	}
+	if req.MaxUses != nil {
+		jc.MaxUses = nulls.NewInt(*req.MaxUses)
+		jc.UsesRemaining = nulls.NewInt(*req.MaxUses)
+	}
+	// 40 random bits rarely collide; retry the few times they do
+	for attempt := 0; err == nil && jc.Code == "" && attempt < 3; attempt++ {
+		var code string
+		var taken bool
+		if code, err = newJoinCode(); err == nil {
+			taken, err = tx.Where("code = ?", code).Exists(&models.TeamJoinCode{})
+		}
+		if err == nil && !taken {
+			jc.Code = code
+		}
+	}
+	if err == nil && jc.Code == "" {
+		err = errors.New("no unused code found")
+	}
+	if err == nil {
+		err = tx.Create(&jc)
+	}
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to create join code",
+			"error":   err.Error(),
+		}))
+	}
+
+	return c.Render(http.StatusCreated, r.JSON(map[string]interface{}{
+		"success": true,
+		"data":    jc,
+		"message": "Join code created successfully",
+	}))
+}
+
+/**
+ * GetTeamJoinCodes lists the team's usable join codes, newest first
+ * GET /api/teams/{id}/join-codes
+ *
+ * Revoked, expired and used-up codes are left out. Requires
+ * manage_members.
+ */
+func GetTeamJoinCodes(c buffalo.Context) error {
+	member, status, msg := teamMembership(c, "manage_members")
+	if status != 0 {
+		return renderTeamError(c, status, msg)
+	}
+
+	codes := []models.TeamJoinCode{}
+	err := mustTx(c).
+		Where("team_id = ? AND revoked_at IS NULL", member.TeamID).
+		Where("expires_at IS NULL OR expires_at > ?", time.Now().UTC()).
+		Where("uses_remaining IS NULL OR uses_remaining > 0").
+		Order("created_at DESC").
+		All(&codes)
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to fetch join codes",
+			"error":   err.Error(),
+		}))
+	}
+
+	return c.Render(http.StatusOK, r.JSON(map[string]interface{}{
+		"success": true,
+		"data":    codes,
+		"message": "Join codes retrieved successfully",
+	}))
+}
+
+/**
+ * DeleteTeamJoinCode revokes a join code
+ * DELETE /api/teams/{id}/join-codes/{code_id}
+ *
+ * The row is kept so the code is never handed out again. Requires
+ * manage_members.
+ */
+func DeleteTeamJoinCode(c buffalo.Context) error {
+	member, status, msg := teamMembership(c, "manage_members")
+	if status != 0 {
+		return renderTeamError(c, status, msg)
+	}
+	codeID, err := uuid.FromString(c.Param("code_id"))
+	if err != nil {
+		return renderTeamError(c, http.StatusBadRequest, "Invalid join code ID")
+	}
+
+	tx := mustTx(c)
+	var jc models.TeamJoinCode
+	if err := tx.Where("id = ? AND team_id = ? AND revoked_at IS NULL", codeID, member.TeamID).First(&jc); err != nil {
+		return renderTeamError(c, http.StatusNotFound, "Join code not found")
+	}
+	now := time.Now().UTC()
+	jc.RevokedAt = nulls.NewTime(now)
+	jc.UpdatedAt = now
+	if err := tx.UpdateColumns(&jc, "revoked_at", "updated_at"); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to revoke join code",
+			"error":   err.Error(),
+		}))
+	}
+
+	return c.Render(http.StatusOK, r.JSON(map[string]interface{}{
+		"success": true,
+		"message": "Join code revoked successfully",
+	}))
+}
+
+/**
+ * joinCodePolicy checks a code against the team's current invite rules:
+ * a code invites on its creator's behalf, so it stops working once they
+ * are no longer an active member who may invite at the code's role
+ *
+ * @return *memberPolicyViolation - The violation, nil if allowed
+ */
+func joinCodePolicy(tx *pop.Connection, jc models.TeamJoinCode, settings models.TeamSettings) (*memberPolicyViolation, error) {
+	revoked := &memberPolicyViolation{http.StatusForbidden, codeInvitePolicy, "The creator of this code can no longer invite"}
+	if !jc.CreatedBy.Valid {
+		return revoked, nil
+	}
+	var creator models.TeamMember
+	if err := tx.Where("team_id = ? AND user_id = ? AND status = ?", jc.TeamID, jc.CreatedBy.UUID, "active").First(&creator); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return revoked, nil
+		}
+		return nil, err
+	}
+	if !creator.HasPermission("invite_members") || !settings.MayInvite(creator) {
+		return revoked, nil
+	}
+	above, err := roleAboveInviter(tx, jc.TeamID, jc.Role, creator)
+	if err != nil || !above {
+		return nil, err
+	}
+	return roleAboveInviterViolation, nil
+}
+
+/**
+ * renderJoinCodeInvalid is the one answer for a code that cannot be used,
+ * whatever the reason, so unknown codes look like revoked ones
+ */
+func renderJoinCodeInvalid(c buffalo.Context) error {
+	return renderTeamError(c, http.StatusNotFound, "Join code not found or no longer valid")
+}
+
+/**
+ * JoinTeamWithCode makes the signed-in user a member of the code's team
+ * POST /api/teams/join
+ *
+ * Payload: code (case and dashes do not matter). A pending, expired or
+ * declined membership the user already has is activated with the code's
+ * role, as with InvitationAccept.
+ *
+ * Responses:
+ * - 200 with the active membership
+ * - 404 for a code that cannot be used: unknown, revoked, expired or
+ *   used up, with join codes disabled for the team, or when the code's
+ *   creator could no longer invite at its role (see joinCodePolicy)
+ * - 402 when the team has no free seat, 409 when it is archived
+ * - 409 when the user is already a member (active or suspended)
+ * - 429 after too many attempts
+ */
+func JoinTeamWithCode(c buffalo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return renderTeamError(c, http.StatusUnauthorized, "Unauthorized")
+	}
+	var req JoinTeamRequest
+	if err := c.Bind(&req); err != nil {
+		return renderTeamError(c, http.StatusBadRequest, "Invalid request data")
+	}
+	code := models.NormalizeJoinCode(req.Code)
+	if code == "" {
+		return renderTeamError(c, http.StatusBadRequest, "Code is required")
+	}
+	now := time.Now().UTC()
+	if !joinCodeIPLimit.allow(sessionClientFrom(c).IP.String, now) || !joinCodeUserLimit.allow(userID.String(), now) {
+		return renderTeamError(c, http.StatusTooManyRequests, "Too many attempts, try again later")
+	}
+
+	tx := mustTx(c)
+	var jc models.TeamJoinCode
+	if err := tx.Where("code = ?", code).First(&jc); err != nil || !jc.Active(now) {
+		return renderJoinCodeInvalid(c)
+	}
+	settings, err := teamSettingsFor(tx, jc.TeamID)
+	if err != nil {
+		return renderTeamError(c, http.StatusInternalServerError, "Failed to load team settings")
+	}
+	if !settings.JoinCodesEnabled {
+		return renderJoinCodeInvalid(c)
+	}
+	member, err := tx.Where("team_id = ? AND user_id = ? AND status IN ('active', 'suspended')", jc.TeamID, userID).
+		Exists(&models.TeamMember{})
+	if err == nil && member {
+		return renderTeamError(c, http.StatusConflict, "User is already a team member")
+	}
+	var violation *memberPolicyViolation
+	if err == nil {
+		violation, err = joinCodePolicy(tx, jc, settings)
+	}
+	if err == nil && violation != nil {
+		return renderJoinCodeInvalid(c)
+	}
+	var archived bool
+	if err == nil {
+		archived, err = teamArchived(tx, jc.TeamID)
+	}
+	if err == nil && archived {
+		return renderTeamError(c, http.StatusConflict, "Team is archived")
+	}
+	var seats seatUsage
+	if err == nil {
+		seats, err = teamSeats(tx, jc.TeamID, true)
+	}
+	if err == nil && seats.Full() {
+		return renderSeatLimit(c, seats)
+	}
+
+	// Check and count the use in one statement; a failed join below rolls
+	// the request back, returning the use
+	if err == nil {
+		err = tx.RawQuery(`
+			UPDATE team_join_codes SET uses_remaining = uses_remaining - 1, updated_at = ?
+			WHERE id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)
+			  AND (uses_remaining IS NULL OR uses_remaining > 0)
+			RETURNING id, team_id, code, role, expires_at, max_uses, uses_remaining, created_by, revoked_at, created_at, updated_at`,
+			now, jc.ID, now).First(&jc)
+		if errors.Is(err, sql.ErrNoRows) {
+			return renderJoinCodeInvalid(c)
+		}
+	}
+
+	var joined struct {
+		ID uuid.UUID `db:"id"`
+	}
+	if err == nil {
+		err = tx.RawQuery(`
+			INSERT INTO team_members (id, team_id, user_id, role, status, invited_by, joined_at, created_at, updated_at)
+			VALUES (?, ?, ?, ?, 'active', ?, ?, ?, ?)
+			ON CONFLICT (team_id, user_id) DO UPDATE
+			SET status = 'active', role = EXCLUDED.role, joined_at = EXCLUDED.joined_at, expires_at = NULL,
+			    declined_at = NULL, updated_at = EXCLUDED.updated_at
+			WHERE team_members.status IN ('pending', 'expired', 'declined')
+			RETURNING id`,
+			uuid.Must(uuid.NewV4()), jc.TeamID, userID, jc.Role, jc.CreatedBy, now, now, now).First(&joined)
+		if errors.Is(err, sql.ErrNoRows) {
+			// Joined concurrently through another request
+			return renderTeamError(c, http.StatusConflict, "User is already a team member")
+		}
+	}
+	var joinedMember models.TeamMember
+	if err == nil {
+		err = tx.Find(&joinedMember, joined.ID)
+	}
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to join team",
+			"error":   err.Error(),
+		}))
+	}
+
+	return c.Render(http.StatusOK, r.JSON(map[string]interface{}{
+		"success": true,
+		"data":    joinedMember,
+		"message": "Joined team successfully",
+	}))
+}
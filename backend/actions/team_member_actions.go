@@ -0,0 +1,168 @@
+/**
+ * Team Member Actions - Listing a Team's Members
+ *
+ * GET /api/teams/{id}/members pages through a team's roster with search,
+ * role filter and sorting, so that large teams do not have to be loaded
+ * in one response. GetTeam only reports the member count.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-21
+ */
+package actions
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/models"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/nulls"
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gofrs/uuid"
+)
+
+// teamMembersPageMax is the most members returned per page.
+const teamMembersPageMax = 100
+
+/**
+ * teamMemberView is one member as listed by GET /api/teams/{id}/members.
+ * Users have no display name or avatar yet, so email is what clients
+ * show.
+ */
+type teamMemberView struct {
+	ID        uuid.UUID             `db:"id" json:"id"`                 // team_members row
+	TeamID    uuid.UUID             `db:"team_id" json:"team_id"`       // Team reference
+	UserID    uuid.UUID             `db:"user_id" json:"user_id"`       // Member's user ID
+	Email     string                `db:"email" json:"email"`           // Member's email address
+	Role      models.TeamMemberRole `db:"role" json:"role"`             // Role in the team
+	Status    string                `db:"status" json:"status"`         // pending | active | suspended | declined
+	InvitedBy nulls.UUID            `db:"invited_by" json:"invited_by"` // Who invited them
+	JoinedAt  *time.Time            `db:"joined_at" json:"joined_at"`   // When they accepted
+	CreatedAt time.Time             `db:"created_at" json:"created_at"` // When they were added or invited
+	UpdatedAt time.Time             `db:"updated_at" json:"updated_at"` // Last change to the membership
+}
+
+/**
+ * includes reports whether the comma-separated include parameter names
+ * what
+ */
+func includes(c buffalo.Context, what string) bool {
+	for _, v := range strings.Split(c.Param("include"), ",") {
+		if strings.TrimSpace(v) == what {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * listTeamMembers reads one page of a team's members as selected by the
+ * request parameters (see GetTeamMembers)
+ *
+ * @return []teamMemberView - The page
+ * @return map[string]interface{} - page, per_page and total
+ * @return string - Message for a 400 response, "" if the parameters are valid
+ * @return error - Database error
+ */
+func listTeamMembers(c buffalo.Context, tx *pop.Connection, teamID uuid.UUID) ([]teamMemberView, map[string]interface{}, string, error) {
+	var err error
+	page, perPage := 1, teamMembersPageMax
+	if v := c.Param("page"); v != "" {
+		if page, err = strconv.Atoi(v); err != nil || page < 1 {
+			return nil, nil, "Invalid page", nil
+		}
+	}
+	if v := c.Param("per_page"); v != "" {
+		if perPage, err = strconv.Atoi(v); err != nil || perPage < 1 || perPage > teamMembersPageMax {
+			return nil, nil, "Invalid per_page", nil
+		}
+	}
+	order := "tm.joined_at NULLS LAST, tm.created_at, tm.id"
+	switch c.Param("sort") {
+	case "", "joined_at":
+	case "name":
+		order = "u.email, tm.id"
+	default:
+		return nil, nil, "sort must be joined_at or name", nil
+	}
+
+	statuses := "'pending', 'active'"
+	if includes(c, "all") {
+		statuses = "'pending', 'active', 'suspended', 'declined'"
+	}
+	where := "tm.team_id = ? AND tm.status IN (" + statuses + ")"
+	args := []interface{}{teamID}
+	if q := strings.TrimSpace(c.Param("q")); q != "" {
+		where += " AND u.email ILIKE ?"
+		args = append(args, "%"+likeEscaper.Replace(q)+"%")
+	}
+	if role := strings.TrimSpace(c.Param("role")); role != "" {
+		where += " AND tm.role = ?"
+		args = append(args, role)
+	} else if !includes(c, "guests") {
+		where += " AND tm.role <> 'guest'"
+	}
+
+	members := []teamMemberView{}
+	query := tx.RawQuery(`
+		SELECT tm.id, tm.team_id, tm.user_id, u.email, tm.role, tm.status, tm.invited_by, tm.joined_at, tm.created_at, tm.updated_at
+		FROM team_members tm JOIN users u ON u.id = tm.user_id
+		WHERE `+where+`
+		ORDER BY `+order, args...).Paginate(page, perPage)
+	if err := query.All(&members); err != nil {
+		return nil, nil, "", err
+	}
+	return members, map[string]interface{}{
+		"page":     page,
+		"per_page": perPage,
+		"total":    query.Paginator.TotalEntriesSize,
+	}, "", nil
+}
+
+/**
+ * GetTeamMembers lists a team's members
+ * GET /api/teams/{id}/members
+ *
+ * Query parameters:
+ * - page (default 1), per_page (default and at most 100)
+ * - q: Case-insensitive substring of the member's email address (users
+ *   have no separate name yet)
+ * - role: Only members with this role
+ * - sort: joined_at (default, pending invitations last) or name
+ * - include=all: Also suspended members and declined invitations; by
+ *   default only pending and active members are listed
+ * - include=guests: Also guests, who are otherwise left out so that
+ *   pickers for management actions do not offer them (role=guest lists
+ *   only them)
+ *
+ * Requires view_team.
+ */
+func GetTeamMembers(c buffalo.Context) error {
+	member, status, msg := teamMembership(c, "view_team")
+	if status != 0 {
+		return renderTeamError(c, status, msg)
+	}
+
+	members, pageInfo, msg, err := listTeamMembers(c, mustTx(c), member.TeamID)
+	if msg != "" {
+		return renderTeamError(c, http.StatusBadRequest, msg)
+	}
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to retrieve team members",
+			"error":   err.Error(),
+		}))
+	}
+
+	pageInfo["items"] = members
+	return c.Render(http.StatusOK, r.JSON(map[string]interface{}{
+		"success": true,
+		"data":    pageInfo,
+		"message": "Team members retrieved successfully",
+	}))
+}
@@ -0,0 +1,127 @@
+/**
+ * Team Notifications - Email About Membership Changes
+ *
+ * Registered users hear about changes to their team memberships by
+ * email, not only when they next open the app:
+ * - an invitation, with links to accept or decline it in the app
+ * - a change of their role
+ * - their removal from a team
+ *
+ * Messages name the team and the member who acted. They are queued
+ * after the request commits and delivered in the background (see
+ * sendMail); users who turned off the team_notification_emails
+ * preference get none. Without SMTP_HOST they are only logged.
+ *
+ * Configuration:
+ * - TEAM_PENDING_URL: app page answering an invitation; member_id and
+ *   action (accept or decline) are added as query parameters (default
+ *   http://localhost:8100/invitations/pending)
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-22
+ */
+package actions
+
+import (
+	"fmt"
+	"net/url"
+
+	"backend/mailer"
+	"backend/models"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/envy"
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gofrs/uuid"
+)
+
+/**
+ * pendingInvitationURL returns the app link answering a pending
+ * membership with action (accept or decline)
+ */
+func pendingInvitationURL(memberID uuid.UUID, action string) string {
+	u, err := url.Parse(envy.Get("TEAM_PENDING_URL", "http://localhost:8100/invitations/pending"))
+	if err != nil {
+		u = &url.URL{Path: "/invitations/pending"}
+	}
+	q := u.Query()
+	q.Set("member_id", memberID.String())
+	q.Set("action", action)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+/**
+ * notifyMember emails the member's user after commit unless they opted
+ * out of team emails. compose receives the team's name and the acting
+ * user's email address.
+ *
+ * Lookup failures are logged and skip the email; they never fail the
+ * request that triggered it.
+ */
+func notifyMember(c buffalo.Context, tx *pop.Connection, member models.TeamMember, actor uuid.UUID, compose func(team, actor string) (subject, text string)) {
+	var user, acting models.User
+	var team models.Team
+	err := tx.Find(&user, member.UserID)
+	if err == nil {
+		err = tx.Find(&acting, actor)
+	}
+	if err == nil {
+		err = tx.Find(&team, member.TeamID)
+	}
+	var prefs models.UserPreferences
+	if err == nil {
+		prefs, err = userPreferences(tx, member.UserID)
+	}
+	if err != nil {
+		c.Logger().Errorf("team notification for member %s: %v", member.ID, err)
+		return
+	}
+	if !prefs.TeamNotificationEmails {
+		return
+	}
+
+	subject, text := compose(team.Name, acting.Email)
+	msg := mailer.Message{To: user.Email, Subject: subject, Text: text}
+	afterCommit(c, func() { sendMail(msg) })
+}
+
+/**
+ * notifyInvited tells a registered user about their pending membership
+ */
+func notifyInvited(c buffalo.Context, tx *pop.Connection, member models.TeamMember, inviter uuid.UUID) {
+	notifyMember(c, tx, member, inviter, func(team, actor string) (string, string) {
+		expires := ""
+		if member.ExpiresAt.Valid {
+			expires = fmt.Sprintf("The invitation expires on %s.\n", member.ExpiresAt.Time.Format("January 2, 2006"))
+		}
+		return fmt.Sprintf("%s invited you to join %s on TimeTrac", actor, team),
+			fmt.Sprintf("%s invited you to join the team %q on TimeTrac as %s.\n\n"+
+				"Accept:\n%s\n\nDecline:\n%s\n\n%s"+
+				"You can also answer from the invitations page in the app.\n",
+				actor, team, member.Role,
+				pendingInvitationURL(member.ID, "accept"), pendingInvitationURL(member.ID, "decline"), expires)
+	})
+}
+
+/**
+ * notifyRoleChanged tells a member their role in a team changed
+ */
+func notifyRoleChanged(c buffalo.Context, tx *pop.Connection, member models.TeamMember, actor uuid.UUID, from models.TeamMemberRole) {
+	notifyMember(c, tx, member, actor, func(team, actorEmail string) (string, string) {
+		return fmt.Sprintf("Your role in %s changed", team),
+			fmt.Sprintf("%s changed your role in the team %q on TimeTrac from %s to %s.\n",
+				actorEmail, team, from, member.Role)
+	})
+}
+
+/**
+ * notifyRemoved tells a former member they were removed from a team
+ */
+func notifyRemoved(c buffalo.Context, tx *pop.Connection, member models.TeamMember, actor uuid.UUID) {
+	notifyMember(c, tx, member, actor, func(team, actorEmail string) (string, string) {
+		return fmt.Sprintf("You were removed from %s", team),
+			fmt.Sprintf("%s removed you from the team %q on TimeTrac.\n", actorEmail, team)
+	})
+}
@@ -0,0 +1,549 @@
+/**
+ * Team Project Actions - Shared Project List of a Team
+ *
+ * This file provides CRUD for a team's projects under
+ * /api/teams/{id}/projects and the check that team entries use them.
+ * Members with view_team can list the projects; creating, changing,
+ * archiving and deleting them requires manage_projects.
+ *
+ * Entries reference projects by name. Renaming a project renames it on
+ * the team's entries so reports keep aggregating; archiving only hides
+ * it from pickers and new entries, and deleting leaves entries untouched.
+ *
+ * A project can carry a budget in hours and in money (see
+ * project_budget_actions.go). Money budgets are set and seen only by
+ * members with manage_rates.
+ *
+ * A restricted project is only visible to the members listed in
+ * project_members and to owners and admins. Guests see only the projects
+ * they are listed on, restricted or not. Everyone else cannot list
+ * it, start entries on it, or see other members' entries on it in team
+ * tracks and summaries. The rules are applied in SQL (projectVisibleSQL,
+ * entryVisibleSQL).
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-21
+ */
+package actions
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"backend/models"
+	"backend/validators"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/nulls"
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gofrs/uuid"
+)
+
+/**
+ * projectVisibleSQL is a condition that project "p" is visible to a user:
+ * unrestricted projects to members but guests, restricted ones to their
+ * project members and to roles with manage_projects (as in the team's
+ * permission matrix, the owner always). All three placeholders take the
+ * user ID.
+ */
+const projectVisibleSQL = `((NOT p.restricted AND NOT EXISTS (SELECT 1 FROM team_members gm
+		WHERE gm.team_id = p.team_id AND gm.user_id = ? AND gm.role = 'guest'))
+	OR EXISTS (SELECT 1 FROM team_members vm
+		LEFT JOIN team_roles vr ON vr.team_id = vm.team_id AND vr.name = vm.role
+		WHERE vm.team_id = p.team_id AND vm.user_id = ? AND vm.status = 'active'
+		  AND (vm.role = 'owner' OR 'manage_projects' = ANY(vr.permissions)))
+	OR EXISTS (SELECT 1 FROM project_members pm WHERE pm.project_id = p.id AND pm.user_id = ?))`
+
+/**
+ * entryVisibleSQL returns a condition that the team entry aliased alias
+ * is visible to a user: it is their own, or its project is not a
+ * restricted project hidden from them. All four placeholders take the
+ * user ID.
+ */
+func entryVisibleSQL(alias string) string {
+	return `(` + alias + `.user_id = ? OR NOT EXISTS (
+		SELECT 1 FROM projects p
+		WHERE p.team_id = ` + alias + `.team_id AND p.name = ` + alias + `.project AND NOT ` + projectVisibleSQL + `))`
+}
+
+/**
+ * errNotTeamProject is returned by checkTeamProject when a team entry
+ * names a project the team does not (or no longer) offer
+ */
+var errNotTeamProject = errors.New("is not an active project of this team")
+
+/**
+ * checkTeamProject verifies that name is an active project of the team
+ * visible to the user; the empty name (no project) is always allowed
+ *
+ * @return error - errNotTeamProject or a database error
+ */
+func checkTeamProject(tx *pop.Connection, teamID, uid uuid.UUID, name string) error {
+	if name == "" {
+		return nil
+	}
+	ok, err := tx.RawQuery(`
+		SELECT 1 FROM projects p
+		WHERE p.team_id = ? AND p.name = ? AND p.archived_at IS NULL AND `+projectVisibleSQL,
+		teamID, name, uid, uid, uid).Exists(&models.Project{})
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errNotTeamProject
+	}
+	return nil
+}
+
+/**
+ * TeamProjectRequest is the payload for creating or changing a team
+ * project; omitted fields are left unchanged on PATCH
+ */
+type TeamProjectRequest struct {
+	Name              *string  `json:"name"`
+	Color             *string  `json:"color"`               // Hex color, "" clears it
+	Restricted        *bool    `json:"restricted"`          // Limit to listed members
+	Archived          *bool    `json:"archived"`            // PATCH only
+	BudgetHours       *float64 `json:"budget_hours"`        // 0 clears it
+	BudgetAmountCents *int64   `json:"budget_amount_cents"` // 0 clears it; needs manage_rates
+}
+
+/**
+ * apply validates the request and copies it onto p
+ *
+ * @return string - Payload field at fault, "" when valid
+ * @return error - Validation error
+ */
+func (req TeamProjectRequest) apply(p *models.Project, now time.Time) (string, error) {
+	if req.Name != nil {
+		name, err := models.NormalizeProjectName(*req.Name)
+		if err != nil {
+			return "name", err
+		}
+		p.Name = name
+	}
+	if req.Color != nil {
+		p.Color = nulls.String{}
+		if v := strings.TrimSpace(*req.Color); v != "" {
+			color, err := validators.NormalizeColor(v)
+			if err != nil {
+				return "color", err
+			}
+			p.Color = nulls.NewString(color)
+		}
+	}
+	if req.Restricted != nil {
+		p.Restricted = *req.Restricted
+	}
+	// A changed budget alerts its thresholds anew
+	if req.BudgetHours != nil {
+		if *req.BudgetHours < 0 || *req.BudgetHours > models.MaxBudgetHours {
+			return "budget_hours", fmt.Errorf("must be from 0 to %d", models.MaxBudgetHours)
+		}
+		budget := nulls.Float64{}
+		if *req.BudgetHours > 0 {
+			budget = nulls.NewFloat64(*req.BudgetHours)
+		}
+		if budget != p.BudgetHours {
+			p.BudgetHours, p.BudgetAlertedHours = budget, 0
+		}
+	}
+	if req.BudgetAmountCents != nil {
+		if *req.BudgetAmountCents < 0 || *req.BudgetAmountCents > models.MaxBudgetAmountCents {
+			return "budget_amount_cents", fmt.Errorf("must be from 0 to %d", int64(models.MaxBudgetAmountCents))
+		}
+		budget := nulls.Int64{}
+		if *req.BudgetAmountCents > 0 {
+			budget = nulls.NewInt64(*req.BudgetAmountCents)
+		}
+		if budget != p.BudgetAmountCents {
+			p.BudgetAmountCents, p.BudgetAlertedAmount = budget, 0
+		}
+	}
+	if req.Archived != nil {
+		switch {
+		case *req.Archived && !p.ArchivedAt.Valid:
+			p.ArchivedAt = nulls.NewTime(now)
+		case !*req.Archived:
+			p.ArchivedAt = nulls.Time{}
+		}
+	}
+	return "", nil
+}
+
+/**
+ * projectNameTaken reports whether another project of the team uses name
+ */
+func projectNameTaken(tx *pop.Connection, p models.Project) (bool, error) {
+	return tx.Where("team_id = ? AND name = ? AND id <> ?", p.TeamID, p.Name, p.ID).Exists(&models.Project{})
+}
+
+/**
+ * teamProject loads the project named by the project_id parameter within
+ * the member's team, if the member can see it; when it fails the returned
+ * status and message describe the error response
+ */
+func teamProject(c buffalo.Context, member models.TeamMember) (models.Project, int, string) {
+	id, err := uuid.FromString(c.Param("project_id"))
+	if err != nil {
+		return models.Project{}, http.StatusBadRequest, "Invalid project ID"
+	}
+	var p models.Project
+	if err := mustTx(c).RawQuery(`SELECT p.* FROM projects p WHERE p.id = ? AND p.team_id = ? AND `+projectVisibleSQL,
+		id, member.TeamID, member.UserID, member.UserID, member.UserID).First(&p); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Project{}, http.StatusNotFound, "Project not found"
+		}
+		return models.Project{}, http.StatusInternalServerError, "Failed to load project"
+	}
+	return p, 0, ""
+}
+
+/**
+ * GetTeamProjects lists the team's projects visible to the caller by name
+ * GET /api/teams/{id}/projects?archived=true
+ *
+ * Archived projects are only included with `archived=true`. Money
+ * budgets are null without manage_rates.
+ */
+func GetTeamProjects(c buffalo.Context) error {
+	member, status, msg := teamMembership(c, "view_team")
+	if status != 0 {
+		return renderTeamError(c, status, msg)
+	}
+
+	projects := []models.Project{}
+	if err := mustTx(c).RawQuery(`
+		SELECT p.* FROM projects p
+		WHERE p.team_id = ? AND (? OR p.archived_at IS NULL) AND `+projectVisibleSQL+`
+		ORDER BY p.name
+	`, member.TeamID, c.Param("archived") == "true", member.UserID, member.UserID, member.UserID).All(&projects); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to retrieve projects",
+			"error":   err.Error(),
+		}))
+	}
+	for i := range projects {
+		projects[i] = projectView(projects[i], member)
+	}
+
+	return c.Render(http.StatusOK, r.JSON(map[string]interface{}{
+		"success": true,
+		"data":    projects,
+		"message": "Projects retrieved successfully",
+	}))
+}
+
+/**
+ * CreateTeamProject adds a project to the team
+ * POST /api/teams/{id}/projects
+ *
+ * Payload: name (required, unique within the team), color, restricted,
+ * budget_hours and budget_amount_cents (optional). Requires
+ * manage_projects, and manage_rates for a money budget; a duplicate name
+ * returns 409. The creator of a restricted project is listed as its first
+ * member.
+ */
+func CreateTeamProject(c buffalo.Context) error {
+	member, status, msg := teamMembership(c, "manage_projects")
+	if status != 0 {
+		return renderTeamError(c, status, msg)
+	}
+
+	var req TeamProjectRequest
+	if err := c.Bind(&req); err != nil {
+		return renderTeamError(c, http.StatusBadRequest, "Invalid request body")
+	}
+	if req.Name == nil {
+		return renderFieldError(c, "name", errors.New("is required"))
+	}
+	if req.BudgetAmountCents != nil && !member.HasPermission("manage_rates") {
+		return renderTeamError(c, http.StatusForbidden, "Insufficient permissions")
+	}
+	req.Archived = nil
+
+	now := time.Now()
+	project := models.Project{
+		ID:        uuid.Must(uuid.NewV4()),
+		TeamID:    nulls.NewUUID(member.TeamID),
+		CreatedBy: nulls.NewUUID(member.UserID),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if field, err := req.apply(&project, now); err != nil {
+		return renderFieldError(c, field, err)
+	}
+
+	tx := mustTx(c)
+	taken, err := projectNameTaken(tx, project)
+	if err == nil && taken {
+		return renderTeamError(c, http.StatusConflict, "A project with this name already exists")
+	}
+	if err == nil {
+		err = tx.Create(&project)
+	}
+	// The creator of a restricted project keeps access to it
+	if err == nil && project.Restricted {
+		err = addProjectMember(tx, project.ID, member.UserID, member.UserID)
+	}
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to create project",
+			"error":   err.Error(),
+		}))
+	}
+
+	return c.Render(http.StatusCreated, r.JSON(map[string]interface{}{
+		"success": true,
+		"data":    projectView(project, member),
+		"message": "Project created successfully",
+	}))
+}
+
+/**
+ * UpdateTeamProject renames, recolors, archives or restores a project
+ * PATCH /api/teams/{id}/projects/{project_id}
+ *
+ * Payload (all optional): name, color, restricted, archived,
+ * budget_hours, budget_amount_cents. A rename is applied to the team's
+ * entries as well. Requires manage_projects and access to the project,
+ * and manage_rates for the money budget.
+ */
+func UpdateTeamProject(c buffalo.Context) error {
+	member, status, msg := teamMembership(c, "manage_projects")
+	if status != 0 {
+		return renderTeamError(c, status, msg)
+	}
+	project, status, msg := teamProject(c, member)
+	if status != 0 {
+		return renderTeamError(c, status, msg)
+	}
+
+	var req TeamProjectRequest
+	if err := c.Bind(&req); err != nil {
+		return renderTeamError(c, http.StatusBadRequest, "Invalid request body")
+	}
+	if req.BudgetAmountCents != nil && !member.HasPermission("manage_rates") {
+		return renderTeamError(c, http.StatusForbidden, "Insufficient permissions")
+	}
+
+	now := time.Now()
+	oldName, wasRestricted := project.Name, project.Restricted
+	if field, err := req.apply(&project, now); err != nil {
+		return renderFieldError(c, field, err)
+	}
+	project.UpdatedAt = now
+
+	tx := mustTx(c)
+	taken, err := projectNameTaken(tx, project)
+	if err == nil && taken {
+		return renderTeamError(c, http.StatusConflict, "A project with this name already exists")
+	}
+	if err == nil {
+		err = tx.Update(&project)
+	}
+	// A manager who restricts a project keeps access to it
+	if err == nil && project.Restricted && !wasRestricted {
+		err = addProjectMember(tx, project.ID, member.UserID, member.UserID)
+	}
+	if err == nil && project.Name != oldName {
+		err = tx.RawQuery("UPDATE timetrac SET project = ?, updated_at = ? WHERE team_id = ? AND project = ?",
+			project.Name, now, member.TeamID, oldName).Exec()
+	}
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to update project",
+			"error":   err.Error(),
+		}))
+	}
+
+	return c.Render(http.StatusOK, r.JSON(map[string]interface{}{
+		"success": true,
+		"data":    projectView(project, member),
+		"message": "Project updated successfully",
+	}))
+}
+
+/**
+ * DeleteTeamProject removes a project from the team's list
+ * DELETE /api/teams/{id}/projects/{project_id}
+ *
+ * Entries keep the project name. Requires manage_projects and access to
+ * the project.
+ */
+func DeleteTeamProject(c buffalo.Context) error {
+	member, status, msg := teamMembership(c, "manage_projects")
+	if status != 0 {
+		return renderTeamError(c, status, msg)
+	}
+	project, status, msg := teamProject(c, member)
+	if status != 0 {
+		return renderTeamError(c, status, msg)
+	}
+
+	if err := mustTx(c).Destroy(&project); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to delete project",
+			"error":   err.Error(),
+		}))
+	}
+	c.Response().WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+/**
+ * addProjectMember grants user access to a project; granting it again
+ * is a no-op
+ */
+func addProjectMember(tx *pop.Connection, projectID, userID, addedBy uuid.UUID) error {
+	now := time.Now()
+	return tx.RawQuery(`
+		INSERT INTO project_members (id, project_id, user_id, added_by, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (project_id, user_id) DO NOTHING
+	`, uuid.Must(uuid.NewV4()), projectID, userID, addedBy, now, now).Exec()
+}
+
+/**
+ * projectMember is one entry of a project's access list
+ */
+type projectMember struct {
+	UserID  uuid.UUID `db:"user_id" json:"user_id"`
+	Email   string    `db:"email" json:"email"`
+	AddedAt time.Time `db:"added_at" json:"added_at"`
+}
+
+/**
+ * GetProjectMembers lists the members given access to a project
+ * GET /api/teams/{id}/projects/{project_id}/members
+ *
+ * Requires manage_projects and access to the project. Owners and admins
+ * see every project without being listed.
+ */
+func GetProjectMembers(c buffalo.Context) error {
+	member, status, msg := teamMembership(c, "manage_projects")
+	if status != 0 {
+		return renderTeamError(c, status, msg)
+	}
+	project, status, msg := teamProject(c, member)
+	if status != 0 {
+		return renderTeamError(c, status, msg)
+	}
+
+	members := []projectMember{}
+	if err := mustTx(c).RawQuery(`
+		SELECT pm.user_id, u.email, pm.created_at AS added_at
+		FROM project_members pm JOIN users u ON u.id = pm.user_id
+		WHERE pm.project_id = ?
+		ORDER BY u.email
+	`, project.ID).All(&members); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to retrieve project members",
+			"error":   err.Error(),
+		}))
+	}
+
+	return c.Render(http.StatusOK, r.JSON(map[string]interface{}{
+		"success": true,
+		"data":    members,
+		"message": "Project members retrieved successfully",
+	}))
+}
+
+/**
+ * AddProjectMemberRequest is the payload for granting project access
+ */
+type AddProjectMemberRequest struct {
+	UserID uuid.UUID `json:"user_id"`
+}
+
+/**
+ * AddProjectMember gives an active team member access to a project
+ * POST /api/teams/{id}/projects/{project_id}/members
+ *
+ * Payload: user_id. Adding a listed member again succeeds without change.
+ * Requires manage_projects and access to the project.
+ */
+func AddProjectMember(c buffalo.Context) error {
+	member, status, msg := teamMembership(c, "manage_projects")
+	if status != 0 {
+		return renderTeamError(c, status, msg)
+	}
+	project, status, msg := teamProject(c, member)
+	if status != 0 {
+		return renderTeamError(c, status, msg)
+	}
+
+	var req AddProjectMemberRequest
+	if err := c.Bind(&req); err != nil {
+		return renderTeamError(c, http.StatusBadRequest, "Invalid request body")
+	}
+
+	tx := mustTx(c)
+	active, err := tx.Where("team_id = ? AND user_id = ? AND status = ?", member.TeamID, req.UserID, "active").
+		Exists(&models.TeamMember{})
+	if err == nil && !active {
+		return renderFieldError(c, "user_id", errors.New("is not an active member of this team"))
+	}
+	if err == nil {
+		err = addProjectMember(tx, project.ID, req.UserID, member.UserID)
+	}
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to add project member",
+			"error":   err.Error(),
+		}))
+	}
+
+	return c.Render(http.StatusOK, r.JSON(map[string]interface{}{
+		"success": true,
+		"message": "Project member added successfully",
+	}))
+}
+
+/**
+ * RemoveProjectMember takes a member's access to a project away
+ * DELETE /api/teams/{id}/projects/{project_id}/members/{user_id}
+ *
+ * Requires manage_projects and access to the project; 404 when the user
+ * is not listed.
+ */
+func RemoveProjectMember(c buffalo.Context) error {
+	member, status, msg := teamMembership(c, "manage_projects")
+	if status != 0 {
+		return renderTeamError(c, status, msg)
+	}
+	project, status, msg := teamProject(c, member)
+	if status != 0 {
+		return renderTeamError(c, status, msg)
+	}
+	userID, err := uuid.FromString(c.Param("user_id"))
+	if err != nil {
+		return renderTeamError(c, http.StatusBadRequest, "Invalid user ID")
+	}
+
+	n, err := mustTx(c).RawQuery("DELETE FROM project_members WHERE project_id = ? AND user_id = ?", project.ID, userID).ExecWithCount()
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to remove project member",
+			"error":   err.Error(),
+		}))
+	}
+	if n == 0 {
+		return renderTeamError(c, http.StatusNotFound, "Project member not found")
+	}
+	c.Response().WriteHeader(http.StatusNoContent)
+	return nil
+}
@@ -0,0 +1,172 @@
+/**
+ * Team Rate Actions - Billing Rates of Team Members
+ *
+ * Agencies record what an hour of each member's time costs. Rates are
+ * kept on the membership (hourly_rate_cents, currency) and every change
+ * is snapshotted in member_rate_history, from which GET
+ * /api/teams/{id}/summary prices entries at the rate that applied when
+ * they started. Rates and costs are confidential: only members with
+ * manage_rates (owners and admins by default) see or change them.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-22
+ */
+package actions
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"backend/models"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/nulls"
+	"github.com/gofrs/uuid"
+)
+
+/**
+ * MemberRateRequest is the payload for setting a member's rate
+ */
+type MemberRateRequest struct {
+	HourlyRateCents *int   `json:"hourly_rate_cents"` // null clears the rate
+	Currency        string `json:"currency"`          // Defaults to the team's default_currency
+}
+
+/**
+ * rateMember loads the member named by the member_id parameter for an
+ * actor with manage_rates; when it fails the returned status and
+ * message describe the error response
+ */
+func rateMember(c buffalo.Context) (models.TeamMember, models.TeamMember, int, string) {
+	actor, status, msg := teamMembership(c, "manage_rates")
+	if status != 0 {
+		return actor, models.TeamMember{}, status, msg
+	}
+	memberID, err := uuid.FromString(c.Param("member_id"))
+	if err != nil {
+		return actor, models.TeamMember{}, http.StatusBadRequest, "Invalid member ID"
+	}
+	var member models.TeamMember
+	if err := mustTx(c).Where("id = ? AND team_id = ?", memberID, actor.TeamID).First(&member); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return actor, member, http.StatusNotFound, "Member not found"
+		}
+		return actor, member, http.StatusInternalServerError, "Failed to load member"
+	}
+	return actor, member, 0, ""
+}
+
+/**
+ * renderMemberRate renders a member's current rate and its history,
+ * newest first
+ */
+func renderMemberRate(c buffalo.Context, member models.TeamMember, message string) error {
+	history := []models.MemberRate{}
+	if err := mustTx(c).Where("team_id = ? AND user_id = ?", member.TeamID, member.UserID).
+		Order("effective_from DESC").All(&history); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to load rate history",
+			"error":   err.Error(),
+		}))
+	}
+	return c.Render(http.StatusOK, r.JSON(map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"member_id":         member.ID,
+			"user_id":           member.UserID,
+			"hourly_rate_cents": member.HourlyRateCents,
+			"currency":          member.Currency,
+			"history":           history,
+		},
+		"message": message,
+	}))
+}
+
+/**
+ * GetMemberRate returns a member's billing rate and its history
+ * GET /api/teams/{id}/members/{member_id}/rate
+ *
+ * Requires manage_rates.
+ */
+func GetMemberRate(c buffalo.Context) error {
+	_, member, status, msg := rateMember(c)
+	if status != 0 {
+		return renderTeamError(c, status, msg)
+	}
+	return renderMemberRate(c, member, "Rate retrieved successfully")
+}
+
+/**
+ * SetMemberRate changes a member's billing rate from now on
+ * PUT /api/teams/{id}/members/{member_id}/rate
+ *
+ * Payload: hourly_rate_cents (0 to models.MaxHourlyRateCents, null to
+ * clear), currency (ISO 4217, default the team's default_currency).
+ * Entries already tracked keep the rate that applied to them. Setting
+ * the current rate again changes nothing. Requires manage_rates.
+ */
+func SetMemberRate(c buffalo.Context) error {
+	actor, member, status, msg := rateMember(c)
+	if status != 0 {
+		return renderTeamError(c, status, msg)
+	}
+	var req MemberRateRequest
+	if err := c.Bind(&req); err != nil {
+		return renderTeamError(c, http.StatusBadRequest, "Invalid request data")
+	}
+
+	tx := mustTx(c)
+	rate, currency := nulls.Int{}, nulls.String{}
+	if req.HourlyRateCents != nil {
+		if *req.HourlyRateCents < 0 || *req.HourlyRateCents > models.MaxHourlyRateCents {
+			return renderFieldError(c, "hourly_rate_cents", fmt.Errorf("must be from 0 to %d", models.MaxHourlyRateCents))
+		}
+		code := strings.ToUpper(strings.TrimSpace(req.Currency))
+		if code == "" {
+			settings, err := teamSettingsFor(tx, member.TeamID)
+			if err != nil {
+				return renderTeamError(c, http.StatusInternalServerError, "Failed to load team settings")
+			}
+			code = settings.DefaultCurrency
+		}
+		if !models.ValidCurrency(code) {
+			return renderFieldError(c, "currency", errors.New("must be a three-letter ISO 4217 code"))
+		}
+		rate, currency = nulls.NewInt(*req.HourlyRateCents), nulls.NewString(code)
+	}
+	if rate == member.HourlyRateCents && currency == member.Currency {
+		return renderMemberRate(c, member, "Rate unchanged")
+	}
+
+	now := time.Now().UTC()
+	member.HourlyRateCents, member.Currency = rate, currency
+	member.UpdatedAt = now
+	err := tx.UpdateColumns(&member, "hourly_rate_cents", "currency", "updated_at")
+	if err == nil {
+		err = tx.Create(&models.MemberRate{
+			ID:              uuid.Must(uuid.NewV4()),
+			TeamID:          member.TeamID,
+			UserID:          member.UserID,
+			HourlyRateCents: rate,
+			Currency:        currency,
+			EffectiveFrom:   now,
+			SetBy:           nulls.NewUUID(actor.UserID),
+			CreatedAt:       now,
+			UpdatedAt:       now,
+		})
+	}
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to set rate",
+			"error":   err.Error(),
+		}))
+	}
+	return renderMemberRate(c, member, "Rate updated successfully")
+}
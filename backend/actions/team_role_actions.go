@@ -0,0 +1,421 @@
+/**
+ * Team Role Actions - Custom Roles and the Permission Matrix
+ *
+ * Teams start with the built-in roles (see models.SeedTeamRoles) and can
+ * define their own under /api/teams/{id}/roles. Members with view_team
+ * can read the roles; changing them requires manage_roles, which only
+ * owners hold by default.
+ *
+ * The owner role always grants everything and the guest role nothing;
+ * neither can be changed. Other built-ins can get different permissions
+ * but keep their names. A role
+ * still held by members or open email invitations can only be deleted
+ * by naming a replacement role for them.
+ *
+ * Nobody grants more than they hold: a role can only be given
+ * permissions the caller has, and roles granting more than the caller
+ * cannot be changed or deleted by them (403). delete_team and
+ * transfer_ownership stay with the owner (models.OwnerOnlyPermissions).
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-21
+ */
+package actions
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"backend/models"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gofrs/uuid"
+	"github.com/lib/pq"
+)
+
+/**
+ * assignableRole reports whether members can be given role: it must be a
+ * role of the team, and ownership is only ever transferred, never granted
+ */
+func assignableRole(tx *pop.Connection, teamID uuid.UUID, role models.TeamMemberRole) (bool, error) {
+	if role == "" || role == models.RoleOwner {
+		return false, nil
+	}
+	return tx.Where("team_id = ? AND name = ?", teamID, role).Exists(&models.TeamRole{})
+}
+
+/**
+ * roleAboveMember reports whether role grants a permission member lacks
+ */
+func roleAboveMember(role models.TeamRole, member models.TeamMember) bool {
+	for _, p := range role.Permissions {
+		if !member.HasPermission(p) {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * setDefaultMemberRole replaces the team's default_member_role from with
+ * to, when it is from; the cached settings are dropped on commit
+ */
+func setDefaultMemberRole(c buffalo.Context, tx *pop.Connection, teamID uuid.UUID, from, to models.TeamMemberRole) error {
+	var team models.Team
+	if err := tx.Find(&team, teamID); err != nil {
+		return err
+	}
+	settings := team.ParsedSettings()
+	if settings.DefaultMemberRole != from {
+		return nil
+	}
+	settings.DefaultMemberRole = to
+	raw, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	if err := tx.RawQuery("UPDATE teams SET settings = ?, updated_at = ? WHERE id = ?", string(raw), time.Now(), teamID).Exec(); err != nil {
+		return err
+	}
+	forgetTeamSettings(teamID)
+	afterCommit(c, func() { forgetTeamSettings(teamID) })
+	return nil
+}
+
+/**
+ * TeamRoleRequest is the payload for creating or changing a role;
+ * omitted fields are left unchanged on PATCH
+ */
+type TeamRoleRequest struct {
+	Name        *string   `json:"name"`
+	Permissions *[]string `json:"permissions"`
+}
+
+/**
+ * DeleteTeamRoleRequest is the optional payload for deleting a role
+ */
+type DeleteTeamRoleRequest struct {
+	ReplacementRole string `json:"replacement_role"` // Role given to current holders
+}
+
+/**
+ * teamRole loads the role named by the role_id parameter within the
+ * team; when it fails the returned status and message describe the
+ * error response
+ */
+func teamRole(c buffalo.Context, teamID uuid.UUID) (models.TeamRole, int, string) {
+	id, err := uuid.FromString(c.Param("role_id"))
+	if err != nil {
+		return models.TeamRole{}, http.StatusBadRequest, "Invalid role ID"
+	}
+	var role models.TeamRole
+	if err := mustTx(c).Where("id = ? AND team_id = ?", id, teamID).First(&role); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.TeamRole{}, http.StatusNotFound, "Role not found"
+		}
+		return models.TeamRole{}, http.StatusInternalServerError, "Failed to load role"
+	}
+	return role, 0, ""
+}
+
+/**
+ * apply validates the request and copies it onto role
+ *
+ * @return string - Payload field at fault, "" when valid
+ * @return error - Validation error
+ */
+func (req TeamRoleRequest) apply(role *models.TeamRole) (string, error) {
+	if req.Name != nil {
+		name := strings.ToLower(strings.TrimSpace(*req.Name))
+		if name != string(role.Name) {
+			if role.BuiltIn {
+				return "name", errors.New("built-in roles cannot be renamed")
+			}
+			if !models.ValidCustomRoleName(name) {
+				return "name", errors.New("must be 2 to 50 lowercase letters, digits or underscores, starting with a letter, and not a built-in role")
+			}
+			role.Name = models.TeamMemberRole(name)
+		}
+	}
+	if req.Permissions != nil {
+		if role.Name == models.RoleOwner {
+			return "permissions", errors.New("the owner role always has every permission")
+		}
+		if role.Name == models.RoleGuest {
+			return "permissions", errors.New("the guest role never has any permission")
+		}
+		perms, err := models.NormalizeRolePermissions(*req.Permissions)
+		if err != nil {
+			return "permissions", err
+		}
+		role.Permissions = pq.StringArray(perms)
+	}
+	return "", nil
+}
+
+/**
+ * GetTeamRoles lists the team's roles, built-ins first
+ * GET /api/teams/{id}/roles
+ */
+func GetTeamRoles(c buffalo.Context) error {
+	member, status, msg := teamMembership(c, "view_team")
+	if status != 0 {
+		return renderTeamError(c, status, msg)
+	}
+
+	roles := []models.TeamRole{}
+	if err := mustTx(c).Where("team_id = ?", member.TeamID).Order("built_in DESC, created_at, name").All(&roles); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to retrieve roles",
+			"error":   err.Error(),
+		}))
+	}
+
+	return c.Render(http.StatusOK, r.JSON(map[string]interface{}{
+		"success": true,
+		"data":    roles,
+		"message": "Roles retrieved successfully",
+	}))
+}
+
+/**
+ * CreateTeamRole defines a custom role
+ * POST /api/teams/{id}/roles
+ *
+ * Payload: name (required, lowercase letters, digits and underscores),
+ * permissions (strings from models.TeamPermissions, held by the caller,
+ * else 403). Requires manage_roles; a name already in use returns 409.
+ */
+func CreateTeamRole(c buffalo.Context) error {
+	member, status, msg := teamMembership(c, "manage_roles")
+	if status != 0 {
+		return renderTeamError(c, status, msg)
+	}
+
+	var req TeamRoleRequest
+	if err := c.Bind(&req); err != nil {
+		return renderTeamError(c, http.StatusBadRequest, "Invalid request body")
+	}
+	if req.Name == nil {
+		return renderFieldError(c, "name", errors.New("is required"))
+	}
+
+	role := models.TeamRole{ID: uuid.Must(uuid.NewV4()), TeamID: member.TeamID, Permissions: pq.StringArray{}}
+	if field, err := req.apply(&role); err != nil {
+		return renderFieldError(c, field, err)
+	}
+	if roleAboveMember(role, member) {
+		return renderTeamError(c, http.StatusForbidden, "Cannot grant permissions you do not have")
+	}
+
+	tx := mustTx(c)
+	taken, err := tx.Where("team_id = ? AND name = ?", role.TeamID, role.Name).Exists(&models.TeamRole{})
+	if err == nil && taken {
+		return renderTeamError(c, http.StatusConflict, "A role with this name already exists")
+	}
+	if err == nil {
+		err = tx.Create(&role)
+	}
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to create role",
+			"error":   err.Error(),
+		}))
+	}
+
+	return c.Render(http.StatusCreated, r.JSON(map[string]interface{}{
+		"success": true,
+		"data":    role,
+		"message": "Role created successfully",
+	}))
+}
+
+/**
+ * UpdateTeamRole renames a custom role or changes a role's permissions
+ * PATCH /api/teams/{id}/roles/{role_id}
+ *
+ * Payload (all optional): name (custom roles only), permissions (held by
+ * the caller). Members, open invitations, join codes and the
+ * default_member_role setting follow a rename. Requires manage_roles,
+ * and that the role grants nothing the caller lacks (403).
+ */
+func UpdateTeamRole(c buffalo.Context) error {
+	member, status, msg := teamMembership(c, "manage_roles")
+	if status != 0 {
+		return renderTeamError(c, status, msg)
+	}
+	role, status, msg := teamRole(c, member.TeamID)
+	if status != 0 {
+		return renderTeamError(c, status, msg)
+	}
+
+	if roleAboveMember(role, member) {
+		return renderTeamError(c, http.StatusForbidden, "Cannot change a role higher than your own")
+	}
+
+	var req TeamRoleRequest
+	if err := c.Bind(&req); err != nil {
+		return renderTeamError(c, http.StatusBadRequest, "Invalid request body")
+	}
+	oldName := role.Name
+	if field, err := req.apply(&role); err != nil {
+		return renderFieldError(c, field, err)
+	}
+	if roleAboveMember(role, member) {
+		return renderTeamError(c, http.StatusForbidden, "Cannot grant permissions you do not have")
+	}
+
+	tx := mustTx(c)
+	var err error
+	if role.Name != oldName {
+		var taken bool
+		taken, err = tx.Where("team_id = ? AND name = ?", role.TeamID, role.Name).Exists(&models.TeamRole{})
+		if err == nil && taken {
+			return renderTeamError(c, http.StatusConflict, "A role with this name already exists")
+		}
+	}
+	if err == nil {
+		// team_members.role follows through ON UPDATE CASCADE
+		err = tx.Update(&role)
+	}
+	if err == nil && role.Name != oldName {
+		err = tx.RawQuery("UPDATE team_invitations SET role = ? WHERE team_id = ? AND role = ?",
+			role.Name, role.TeamID, oldName).Exec()
+	}
+	if err == nil && role.Name != oldName {
+		err = tx.RawQuery("UPDATE team_join_codes SET role = ? WHERE team_id = ? AND role = ?",
+			role.Name, role.TeamID, oldName).Exec()
+	}
+	if err == nil && role.Name != oldName {
+		err = setDefaultMemberRole(c, tx, role.TeamID, oldName, role.Name)
+	}
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to update role",
+			"error":   err.Error(),
+		}))
+	}
+
+	return c.Render(http.StatusOK, r.JSON(map[string]interface{}{
+		"success": true,
+		"data":    role,
+		"message": "Role updated successfully",
+	}))
+}
+
+/**
+ * DeleteTeamRole deletes a custom role
+ * DELETE /api/teams/{id}/roles/{role_id}
+ *
+ * Payload or query parameter (optional): replacement_role. While members (of any status),
+ * open email invitations or unrevoked join codes hold the role, the request returns 409 with
+ * their count unless a replacement role is named; they are then moved
+ * to it, as is the default_member_role setting (else it returns to
+ * member). Built-in roles cannot be deleted. Requires manage_roles, and
+ * that the role grants nothing the caller lacks (403).
+ */
+func DeleteTeamRole(c buffalo.Context) error {
+	member, status, msg := teamMembership(c, "manage_roles")
+	if status != 0 {
+		return renderTeamError(c, status, msg)
+	}
+	role, status, msg := teamRole(c, member.TeamID)
+	if status != 0 {
+		return renderTeamError(c, status, msg)
+	}
+	if role.BuiltIn {
+		return renderTeamError(c, http.StatusConflict, "Built-in roles cannot be deleted")
+	}
+	if roleAboveMember(role, member) {
+		return renderTeamError(c, http.StatusForbidden, "Cannot delete a role higher than your own")
+	}
+
+	req := DeleteTeamRoleRequest{ReplacementRole: c.Param("replacement_role")}
+	if c.Request().ContentLength > 0 {
+		if err := c.Bind(&req); err != nil {
+			return renderTeamError(c, http.StatusBadRequest, "Invalid request body")
+		}
+	}
+
+	tx := mustTx(c)
+	var holders struct {
+		Count int `db:"count"`
+	}
+	if err := tx.RawQuery(`
+		SELECT (SELECT COUNT(*) FROM team_members WHERE team_id = ? AND role = ?)
+		     + (SELECT COUNT(*) FROM team_invitations WHERE team_id = ? AND role = ? AND accepted_at IS NULL)
+		     + (SELECT COUNT(*) FROM team_join_codes WHERE team_id = ? AND role = ? AND revoked_at IS NULL) AS count
+	`, role.TeamID, role.Name, role.TeamID, role.Name, role.TeamID, role.Name).First(&holders); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to delete role",
+			"error":   err.Error(),
+		}))
+	}
+
+	if holders.Count > 0 {
+		replacement := models.TeamMemberRole(strings.TrimSpace(req.ReplacementRole))
+		if replacement == "" {
+			return c.Render(http.StatusConflict, r.JSON(map[string]interface{}{
+				"success":      false,
+				"message":      "Role is in use; name a replacement_role",
+				"member_count": holders.Count,
+			}))
+		}
+		ok, err := assignableRole(tx, role.TeamID, replacement)
+		if err == nil && (!ok || replacement == role.Name) {
+			return renderFieldError(c, "replacement_role", errors.New("must be another role of this team, not owner"))
+		}
+		now := time.Now()
+		if err == nil {
+			err = tx.RawQuery("UPDATE team_members SET role = ?, updated_at = ? WHERE team_id = ? AND role = ?",
+				replacement, now, role.TeamID, role.Name).Exec()
+		}
+		if err == nil {
+			err = tx.RawQuery("UPDATE team_invitations SET role = ?, updated_at = ? WHERE team_id = ? AND role = ?",
+				replacement, now, role.TeamID, role.Name).Exec()
+		}
+		if err == nil {
+			err = tx.RawQuery("UPDATE team_join_codes SET role = ?, updated_at = ? WHERE team_id = ? AND role = ?",
+				replacement, now, role.TeamID, role.Name).Exec()
+		}
+		if err != nil {
+			return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+				"success": false,
+				"message": "Failed to delete role",
+				"error":   err.Error(),
+			}))
+		}
+	}
+
+	defaultRole := models.TeamMemberRole(strings.TrimSpace(req.ReplacementRole))
+	if holders.Count == 0 || defaultRole == "" {
+		defaultRole = models.DefaultTeamSettings().DefaultMemberRole
+	}
+	if err := setDefaultMemberRole(c, tx, role.TeamID, role.Name, defaultRole); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to delete role",
+			"error":   err.Error(),
+		}))
+	}
+
+	if err := tx.Destroy(&role); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to delete role",
+			"error":   err.Error(),
+		}))
+	}
+	c.Response().WriteHeader(http.StatusNoContent)
+	return nil
+}
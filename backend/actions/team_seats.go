@@ -0,0 +1,122 @@
+/**
+ * Team Seats - Member Limits of Teams
+ *
+ * A team's plan may cap its seats with the max_members setting (0 =
+ * unlimited). Active and suspended members take a seat; pending
+ * invitations do not, since several may be open at once. The cap is
+ * checked when inviting and again, with the team row locked, whenever a
+ * membership becomes active (accepting an invitation, joining with a
+ * code), so concurrent accepts cannot overfill a team. A full team
+ * answers 402 with its seat usage.
+ *
+ * Members cannot change max_members through the API; operators set it
+ * with `buffalo task teams:seats <team_id> <max_members>`.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-22
+ */
+package actions
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"backend/models"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/nulls"
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gofrs/uuid"
+)
+
+/**
+ * seatUsage is how many seats a team uses out of its limit
+ */
+type seatUsage struct {
+	Used  int       `json:"used"`  // Active and suspended members
+	Limit nulls.Int `json:"limit"` // max_members, null when unlimited
+}
+
+/**
+ * Full reports whether no seat is left for another member
+ */
+func (s seatUsage) Full() bool {
+	return s.Limit.Valid && s.Used >= s.Limit.Int
+}
+
+/**
+ * teamSeats returns a team's seat usage
+ *
+ * @param tx - Request transaction
+ * @param teamID - Team
+ * @param lock - Lock the team row until the transaction ends, so that
+ *   whoever takes the last seat does so alone
+ */
+func teamSeats(tx *pop.Connection, teamID uuid.UUID, lock bool) (seatUsage, error) {
+	q := "SELECT settings FROM teams WHERE id = ?"
+	if lock {
+		q += " FOR UPDATE"
+	}
+	var team struct {
+		Settings string `db:"settings"`
+	}
+	if err := tx.RawQuery(q, teamID).First(&team); err != nil {
+		return seatUsage{}, err
+	}
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := tx.RawQuery("SELECT COUNT(*) AS count FROM team_members WHERE team_id = ? AND status IN ('active', 'suspended')",
+		teamID).First(&count); err != nil {
+		return seatUsage{}, err
+	}
+	seats := seatUsage{Used: count.Count}
+	if max := models.ParseTeamSettings(team.Settings).MaxMembers; max > 0 {
+		seats.Limit = nulls.NewInt(max)
+	}
+	return seats, nil
+}
+
+/**
+ * renderSeatLimit renders the 402 response of a full team
+ */
+func renderSeatLimit(c buffalo.Context, seats seatUsage) error {
+	return c.Render(http.StatusPaymentRequired, r.JSON(map[string]interface{}{
+		"success": false,
+		"message": "Team member limit reached",
+		"seats":   seats,
+	}))
+}
+
+/**
+ * SetTeamSeatLimit sets a team's max_members setting (0 = unlimited),
+ * keeping its other settings
+ *
+ * @param db - Database connection
+ * @param teamID - Team
+ * @param max - Seat limit, 0 to models.MaxTeamSeats
+ * @return error - Unknown team, invalid limit or database error
+ */
+func SetTeamSeatLimit(db *pop.Connection, teamID uuid.UUID, max int) error {
+	var team models.Team
+	if err := db.Find(&team, teamID); err != nil {
+		return err
+	}
+	settings := team.ParsedSettings()
+	settings.MaxMembers = max
+	if errs := settings.Validate(); errs["max_members"] != "" {
+		return fmt.Errorf("max_members %s", errs["max_members"])
+	}
+	raw, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	if err := db.RawQuery("UPDATE teams SET settings = ?, updated_at = ? WHERE id = ?", string(raw), time.Now(), teamID).Exec(); err != nil {
+		return err
+	}
+	forgetTeamSettings(teamID)
+	return nil
+}
@@ -0,0 +1,95 @@
+/**
+ * Team Settings - Cached Typed Settings for Handlers
+ *
+ * Handlers that depend on a team's configuration (default invitation
+ * role, invitation expiry, note requirements) read it with
+ * teamSettingsFor instead of parsing teams.settings themselves. Parsed
+ * settings are cached per team for teamSettingsTTL; UpdateTeam drops the
+ * entry when its change commits, so this process sees new settings on
+ * the next request and other instances within the TTL.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-21
+ */
+package actions
+
+import (
+	"sync"
+	"time"
+
+	"backend/models"
+
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gofrs/uuid"
+)
+
+const (
+	// teamSettingsTTL bounds how long a change by another instance goes unseen.
+	teamSettingsTTL = 30 * time.Second
+	// teamSettingsCacheSize bounds the number of cached teams.
+	teamSettingsCacheSize = 5000
+)
+
+/**
+ * cachedTeamSettings is one team's parsed settings
+ */
+type cachedTeamSettings struct {
+	settings models.TeamSettings
+	loadedAt time.Time
+}
+
+var (
+	teamSettingsMu    sync.Mutex
+	teamSettingsCache = map[uuid.UUID]cachedTeamSettings{}
+)
+
+/**
+ * teamSettingsFor returns a team's settings, from the cache when fresh
+ *
+ * @param tx - Database connection used on a miss
+ * @param teamID - Team to read
+ * @return models.TeamSettings - Settings with defaults applied
+ * @return error - Database error, sql.ErrNoRows for unknown teams
+ */
+func teamSettingsFor(tx *pop.Connection, teamID uuid.UUID) (models.TeamSettings, error) {
+	now := time.Now()
+	teamSettingsMu.Lock()
+	entry, ok := teamSettingsCache[teamID]
+	teamSettingsMu.Unlock()
+	if ok && now.Sub(entry.loadedAt) < teamSettingsTTL {
+		return entry.settings, nil
+	}
+
+	var row struct {
+		Settings string `db:"settings"`
+	}
+	if err := tx.RawQuery("SELECT COALESCE(settings, '') AS settings FROM teams WHERE id = ?", teamID).First(&row); err != nil {
+		return models.TeamSettings{}, err
+	}
+	settings := models.ParseTeamSettings(row.Settings)
+
+	teamSettingsMu.Lock()
+	defer teamSettingsMu.Unlock()
+	if len(teamSettingsCache) >= teamSettingsCacheSize {
+		for id, e := range teamSettingsCache {
+			if now.Sub(e.loadedAt) >= teamSettingsTTL {
+				delete(teamSettingsCache, id)
+			}
+		}
+		if len(teamSettingsCache) >= teamSettingsCacheSize {
+			clear(teamSettingsCache)
+		}
+	}
+	teamSettingsCache[teamID] = cachedTeamSettings{settings: settings, loadedAt: now}
+	return settings, nil
+}
+
+/**
+ * forgetTeamSettings drops a team's cached settings
+ */
+func forgetTeamSettings(teamID uuid.UUID) {
+	teamSettingsMu.Lock()
+	delete(teamSettingsCache, teamID)
+	teamSettingsMu.Unlock()
+}
@@ -0,0 +1,385 @@
+/**
+ * Team Summary Actions - Tracked Time Totals for a Team
+ *
+ * GET /api/teams/{id}/summary aggregates a team's entries (those recorded
+ * with its team_id) in SQL, grouped by member, project or day, for the
+ * charts on the team dashboard. Roles with view_analytics see the whole
+ * team, with guests' time as one group; everyone else sees only their own
+ * time. Roles with manage_rates also get the cost of that time (see
+ * team_rate_actions.go).
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-21
+ */
+package actions
+
+import (
+	"net/http"
+	"time"
+
+	"backend/models"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gofrs/uuid"
+)
+
+/**
+ * teamSummaryProject is a project's share of a member's time
+ */
+type teamSummaryProject struct {
+	Project         string  `db:"project" json:"project"`
+	TotalSeconds    float64 `db:"total_seconds" json:"total_seconds"`
+	BillableSeconds float64 `db:"billable_seconds" json:"billable_seconds"`
+	EntryCount      int     `db:"entry_count" json:"entry_count"`
+}
+
+/**
+ * teamSummaryCost is the cost of a summary row per currency; a map is
+ * nil when none of the row's entries started while a rate was set
+ */
+type teamSummaryCost struct {
+	CostCents         map[string]int64
+	BillableCostCents map[string]int64
+}
+
+/**
+ * teamSummaryMember is one row of group=member
+ */
+type teamSummaryMember struct {
+	UserID          uuid.UUID             `db:"user_id" json:"user_id"`
+	Email           string                `db:"email" json:"email"`
+	Role            models.TeamMemberRole `db:"role" json:"role"`
+	Status          string                `db:"status" json:"status"`
+	TotalSeconds    float64               `db:"total_seconds" json:"total_seconds"`
+	BillableSeconds float64               `db:"billable_seconds" json:"billable_seconds"`
+	EntryCount      int                   `db:"entry_count" json:"entry_count"`
+	Projects        []teamSummaryProject  `db:"-" json:"projects"`
+
+	// Set only for callers with manage_rates, to a nil map when none of
+	// the member's entries was priced (see teamSummaryCost)
+	CostCents         *map[string]int64 `db:"-" json:"cost_cents,omitempty"`
+	BillableCostCents *map[string]int64 `db:"-" json:"billable_cost_cents,omitempty"`
+}
+
+/**
+ * teamSummaryGroup is one row of group=project (Key is the project) or
+ * group=day (Key is YYYY-MM-DD in the requested zone)
+ */
+type teamSummaryGroup struct {
+	Key             string  `db:"key" json:"-"`
+	TotalSeconds    float64 `db:"total_seconds" json:"total_seconds"`
+	BillableSeconds float64 `db:"billable_seconds" json:"billable_seconds"`
+	EntryCount      int     `db:"entry_count" json:"entry_count"`
+	MemberCount     int     `db:"member_count" json:"member_count"`
+}
+
+/**
+ * teamSummaryEntries returns the CTE "e" selecting the team's entries in
+ * [from, to) visible to viewer with their net seconds and the member's
+ * rate when they started (rate_cents and currency, NULL without one),
+ * and its arguments
+ *
+ * @param only - Restrict to this user's entries, uuid.Nil for everyone
+ */
+func teamSummaryEntries(teamID, viewer uuid.UUID, from, to time.Time, only uuid.UUID) (string, []interface{}) {
+	args := []interface{}{teamID, from.UTC(), to.UTC(), viewer, viewer, viewer, viewer}
+	scope := ""
+	if only != uuid.Nil {
+		scope = " AND t.user_id = ?"
+		args = append(args, only)
+	}
+	return `WITH e AS (
+		SELECT t.user_id,
+		       COALESCE(t.project, '') AS project,
+		       t.billable,
+		       t.start_at,
+		       ` + trackNetSecondsSQL + ` AS seconds,
+		       rate.hourly_rate_cents AS rate_cents,
+		       rate.currency
+		FROM timetrac t
+		LEFT JOIN LATERAL (
+			SELECT h.hourly_rate_cents, h.currency
+			FROM member_rate_history h
+			WHERE h.team_id = t.team_id AND h.user_id = t.user_id AND h.effective_from <= t.start_at
+			ORDER BY h.effective_from DESC
+			LIMIT 1
+		) rate ON true
+		WHERE t.team_id = ? AND t.deleted_at IS NULL AND t.start_at >= ? AND t.start_at < ?
+		  AND ` + entryVisibleSQL("t") + scope + `
+	)`, args
+}
+
+/**
+ * teamSummaryCosts prices the entries of CTE "e" grouped by the SQL
+ * expression key, returning the cost of each key that had priced entries
+ */
+func teamSummaryCosts(tx *pop.Connection, entries string, args []interface{}, key string) (map[string]*teamSummaryCost, error) {
+	rows := []struct {
+		Key               string `db:"key"`
+		Currency          string `db:"currency"`
+		CostCents         int64  `db:"cost_cents"`
+		BillableCostCents int64  `db:"billable_cost_cents"`
+	}{}
+	err := tx.RawQuery(entries+`
+		SELECT `+key+` AS key, e.currency,
+		       ROUND(SUM(e.seconds * e.rate_cents) / 3600)::bigint AS cost_cents,
+		       ROUND(COALESCE(SUM(e.seconds * e.rate_cents) FILTER (WHERE e.billable), 0) / 3600)::bigint AS billable_cost_cents
+		FROM e
+		WHERE e.rate_cents IS NOT NULL
+		GROUP BY 1, 2`, args...).All(&rows)
+	if err != nil {
+		return nil, err
+	}
+	costs := map[string]*teamSummaryCost{}
+	for _, row := range rows {
+		cost := costs[row.Key]
+		if cost == nil {
+			cost = &teamSummaryCost{CostCents: map[string]int64{}, BillableCostCents: map[string]int64{}}
+			costs[row.Key] = cost
+		}
+		cost.CostCents[row.Currency] = row.CostCents
+		cost.BillableCostCents[row.Currency] = row.BillableCostCents
+	}
+	return costs, nil
+}
+
+/**
+ * teamSummaryGuests totals the time of the team's active and suspended
+ * guests from CTE "e" as one group=member entry, priced when withCost
+ */
+func teamSummaryGuests(tx *pop.Connection, entries string, args []interface{}, teamID uuid.UUID, withCost bool) (map[string]interface{}, error) {
+	guestArgs := append(append([]interface{}{}, args...), teamID)
+	var g teamSummaryGroup
+	if err := tx.RawQuery(entries+`
+		SELECT 'guests' AS key,
+		       COALESCE(SUM(e.seconds), 0) AS total_seconds,
+		       COALESCE(SUM(e.seconds) FILTER (WHERE e.billable), 0) AS billable_seconds,
+		       COUNT(e.user_id) AS entry_count,
+		       COUNT(DISTINCT m.user_id) AS member_count
+		FROM team_members m
+		LEFT JOIN e ON e.user_id = m.user_id
+		WHERE m.team_id = ? AND m.status IN ('active', 'suspended') AND m.role = 'guest'
+	`, guestArgs...).First(&g); err != nil {
+		return nil, err
+	}
+	out := map[string]interface{}{
+		"total_seconds":    g.TotalSeconds,
+		"billable_seconds": g.BillableSeconds,
+		"entry_count":      g.EntryCount,
+		"member_count":     g.MemberCount,
+	}
+	if withCost {
+		costs, err := teamSummaryCosts(tx, entries, guestArgs,
+			"CASE WHEN e.user_id IN (SELECT user_id FROM team_members WHERE team_id = ? AND role = 'guest') THEN 'guests' ELSE '' END")
+		if err != nil {
+			return nil, err
+		}
+		cost := costs["guests"]
+		if cost == nil {
+			cost = &teamSummaryCost{}
+		}
+		out["cost_cents"] = cost.CostCents
+		out["billable_cost_cents"] = cost.BillableCostCents
+	}
+	return out, nil
+}
+
+/**
+ * GetTeamSummary returns a team's tracked time for a period
+ * GET /api/teams/{id}/summary?from=<RFC3339>&to=<RFC3339>&tz=<IANA zone>&group=member|project|day
+ *
+ * Entries are included by start_at within [from, to), by default the last
+ * 7 calendar days including today. Durations exclude pauses; running
+ * entries count up to now. Day boundaries follow `tz`, then the caller's
+ * timezone preference, then UTC.
+ *
+ * Groups (rows carry total_seconds, billable_seconds and entry_count):
+ * - member (default): One row per active or suspended member (see
+ *   status), including members who tracked nothing, with a per-project
+ *   breakdown in `projects`
+ * - project: { project, member_count, ... } ordered by time desc
+ * - day: { day, member_count, ... } in date order, days without time omitted
+ *
+ * Without view_analytics only the caller's own time is summarized. Other
+ * members' time on restricted projects hidden from the caller is left out.
+ * With it, group=member lists guests not one by one but together as
+ * `guests` next to `rows` ({ member_count, ... }, member_count counting
+ * the guests).
+ *
+ * With manage_rates, rows also carry cost_cents and billable_cost_cents:
+ * the cost of their entries per currency, each entry priced at the rate
+ * its member had when it started. Entries from before a member had a
+ * rate are not priced; a row with no priced entries has null costs.
+ */
+func GetTeamSummary(c buffalo.Context) error {
+	member, status, msg := teamMembership(c, "")
+	if status != 0 {
+		return renderTeamError(c, status, msg)
+	}
+	teamID, userID := member.TeamID, member.UserID
+	tx := mustTx(c)
+
+	group := c.Param("group")
+	switch group {
+	case "":
+		group = "member"
+	case "member", "project", "day":
+	default:
+		return renderTeamError(c, http.StatusBadRequest, "group must be member, project or day")
+	}
+
+	loc, badTz, err := requestLocation(c, tx, userID)
+	if err != nil {
+		if badTz != "" {
+			return renderBadTimezone(c, badTz)
+		}
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to build summary",
+			"error":   err.Error(),
+		}))
+	}
+	to := time.Now().In(loc)
+	from, to, msg := requestRange(c, startOfDay(to, loc).AddDate(0, 0, -6), to)
+	if msg != "" {
+		return renderTeamError(c, http.StatusBadRequest, msg)
+	}
+
+	only := uuid.Nil
+	if !member.HasPermission("view_analytics") {
+		only = userID
+	}
+	entries, args := teamSummaryEntries(teamID, userID, from, to, only)
+	withCost := member.HasPermission("manage_rates")
+
+	var rows, guests interface{}
+	switch group {
+	case "member":
+		memberArgs := append(args, teamID)
+		memberScope := " AND m.role <> 'guest'"
+		if only != uuid.Nil {
+			memberScope = " AND m.user_id = ?"
+			memberArgs = append(memberArgs, only)
+		}
+		members := []teamSummaryMember{}
+		err = tx.RawQuery(entries+`
+			SELECT m.user_id, u.email, m.role, m.status,
+			       COALESCE(SUM(e.seconds), 0) AS total_seconds,
+			       COALESCE(SUM(e.seconds) FILTER (WHERE e.billable), 0) AS billable_seconds,
+			       COUNT(e.user_id) AS entry_count
+			FROM team_members m
+			JOIN users u ON u.id = m.user_id
+			LEFT JOIN e ON e.user_id = m.user_id
+			WHERE m.team_id = ? AND m.status IN ('active', 'suspended')`+memberScope+`
+			GROUP BY m.user_id, u.email, m.role, m.status
+			ORDER BY total_seconds DESC, u.email
+		`, memberArgs...).All(&members)
+		if err == nil {
+			type memberProject struct {
+				UserID uuid.UUID `db:"user_id"`
+				teamSummaryProject
+			}
+			breakdown := []memberProject{}
+			err = tx.RawQuery(entries+`
+				SELECT e.user_id, e.project,
+				       SUM(e.seconds) AS total_seconds,
+				       COALESCE(SUM(e.seconds) FILTER (WHERE e.billable), 0) AS billable_seconds,
+				       COUNT(*) AS entry_count
+				FROM e
+				GROUP BY 1, 2
+				ORDER BY total_seconds DESC, 2
+			`, args...).All(&breakdown)
+			byUser := map[uuid.UUID][]teamSummaryProject{}
+			for _, bp := range breakdown {
+				byUser[bp.UserID] = append(byUser[bp.UserID], bp.teamSummaryProject)
+			}
+			for i := range members {
+				members[i].Projects = byUser[members[i].UserID]
+				if members[i].Projects == nil {
+					members[i].Projects = []teamSummaryProject{}
+				}
+			}
+		}
+		if err == nil && withCost {
+			var costs map[string]*teamSummaryCost
+			costs, err = teamSummaryCosts(tx, entries, args, "e.user_id::text")
+			for i := range members {
+				cost := costs[members[i].UserID.String()]
+				if cost == nil {
+					cost = &teamSummaryCost{}
+				}
+				members[i].CostCents, members[i].BillableCostCents = &cost.CostCents, &cost.BillableCostCents
+			}
+		}
+		if err == nil && only == uuid.Nil {
+			guests, err = teamSummaryGuests(tx, entries, args, teamID, withCost)
+		}
+		rows = members
+
+	case "project", "day":
+		key, order := "e.project", "total_seconds DESC, 1"
+		if group == "day" {
+			// Stored timestamps are UTC wall-clock; shift them into the zone before truncating
+			key, order = "to_char(date_trunc('day', (e.start_at AT TIME ZONE 'UTC') AT TIME ZONE ?), 'YYYY-MM-DD')", "1"
+			args = append(args, loc.String())
+		}
+		groups := []teamSummaryGroup{}
+		err = tx.RawQuery(entries+`
+			SELECT `+key+` AS key,
+			       SUM(e.seconds) AS total_seconds,
+			       COALESCE(SUM(e.seconds) FILTER (WHERE e.billable), 0) AS billable_seconds,
+			       COUNT(*) AS entry_count,
+			       COUNT(DISTINCT e.user_id) AS member_count
+			FROM e
+			GROUP BY 1
+			ORDER BY `+order, args...).All(&groups)
+		var costs map[string]*teamSummaryCost
+		if err == nil && withCost {
+			costs, err = teamSummaryCosts(tx, entries, args, key)
+		}
+		out := make([]map[string]interface{}, len(groups))
+		for i, g := range groups {
+			out[i] = map[string]interface{}{
+				group:              g.Key,
+				"total_seconds":    g.TotalSeconds,
+				"billable_seconds": g.BillableSeconds,
+				"entry_count":      g.EntryCount,
+				"member_count":     g.MemberCount,
+			}
+			if withCost {
+				cost := costs[g.Key]
+				if cost == nil {
+					cost = &teamSummaryCost{}
+				}
+				out[i]["cost_cents"] = cost.CostCents
+				out[i]["billable_cost_cents"] = cost.BillableCostCents
+			}
+		}
+		rows = out
+	}
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to build summary",
+			"error":   err.Error(),
+		}))
+	}
+
+	data := map[string]interface{}{
+		"from":     from,
+		"to":       to,
+		"timezone": loc.String(),
+		"group":    group,
+		"rows":     rows,
+	}
+	if guests != nil {
+		data["guests"] = guests
+	}
+	return c.Render(http.StatusOK, r.JSON(map[string]interface{}{
+		"success": true,
+		"data":    data,
+		"message": "Team summary retrieved successfully",
+	}))
+}
@@ -0,0 +1,105 @@
+/**
+ * Team Suspension Actions - Pausing a Member's Access
+ *
+ * Suspending sets a membership's status to "suspended" instead of
+ * deleting it. Every team endpoint only admits active members, so a
+ * suspended member is refused (403) while the row, their entries and
+ * their place in team reports are kept. GET /api/teams still lists the
+ * team for them, flagged `suspended: true`. Reactivating restores the
+ * previous role.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-21
+ */
+package actions
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"backend/models"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gofrs/uuid"
+)
+
+/**
+ * SuspendMember suspends an active member
+ * POST /api/teams/{id}/members/{member_id}/suspend
+ *
+ * Requires manage_members, and the rules of memberChangePolicy apply:
+ * the owner, the caller themselves and, unless the owner asks, admins
+ * cannot be suspended (403 with a code). Only active members can (409).
+ */
+func SuspendMember(c buffalo.Context) error {
+	return setMemberSuspended(c, true)
+}
+
+/**
+ * ReactivateMember lifts a suspension
+ * POST /api/teams/{id}/members/{member_id}/reactivate
+ *
+ * Requires manage_members and follows the rules of SuspendMember; only
+ * suspended members can be reactivated (409).
+ */
+func ReactivateMember(c buffalo.Context) error {
+	return setMemberSuspended(c, false)
+}
+
+/**
+ * setMemberSuspended moves the member named by member_id between the
+ * active and suspended states
+ */
+func setMemberSuspended(c buffalo.Context, suspend bool) error {
+	actor, status, msg := teamMembership(c, "manage_members")
+	if status != 0 {
+		return renderTeamError(c, status, msg)
+	}
+	memberID, err := uuid.FromString(c.Param("member_id"))
+	if err != nil {
+		return renderTeamError(c, http.StatusBadRequest, "Invalid member ID")
+	}
+
+	tx := mustTx(c)
+	var member models.TeamMember
+	if err := tx.Where("id = ? AND team_id = ?", memberID, actor.TeamID).First(&member); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return renderTeamError(c, http.StatusNotFound, "Member not found")
+		}
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to update member",
+			"error":   err.Error(),
+		}))
+	}
+
+	from, to, verb, done := "suspended", "active", "reactivate", "Member reactivated successfully"
+	if suspend {
+		from, to, verb, done = "active", "suspended", "suspend", "Member suspended successfully"
+	}
+	if v := memberActionPolicy(actor, member, verb); v != nil {
+		return renderMemberPolicyViolation(c, v)
+	}
+	if member.Status != from {
+		return renderTeamError(c, http.StatusConflict, "Member is not "+from)
+	}
+
+	member.Status = to
+	member.UpdatedAt = time.Now()
+	if err := tx.Update(&member); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to update member",
+			"error":   err.Error(),
+		}))
+	}
+
+	return c.Render(http.StatusOK, r.JSON(map[string]interface{}{
+		"success": true,
+		"data":    member,
+		"message": done,
+	}))
+}
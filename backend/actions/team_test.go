@@ -0,0 +1,1905 @@
+package actions
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/mailer"
+	"backend/models"
+	"backend/reports"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/gofrs/uuid"
+)
+
+// userID returns the user a bearer token was issued to.
+func (as *ActionSuite) userID(token string) uuid.UUID {
+	claims, err := ParseJWT(token)
+	as.NoError(err)
+	return uuid.FromStringOrNil(claims.UserID)
+}
+
+// teamFixture creates a team owned by owner, with owner as active member.
+func (as *ActionSuite) teamFixture(name string, owner uuid.UUID) models.Team {
+	now := time.Now()
+	team := models.Team{ID: uuid.Must(uuid.NewV4()), Name: name, OwnerID: owner, Settings: "{}", CreatedAt: now, UpdatedAt: now}
+	as.NoError(as.DB.Create(&team))
+	as.NoError(models.SeedTeamRoles(as.DB, team.ID))
+	as.NoError(as.DB.RawQuery(`INSERT INTO team_members (id, team_id, user_id, role, status, joined_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, 'active', ?, ?, ?)`, uuid.Must(uuid.NewV4()), team.ID, owner, models.RoleOwner, now, now, now).Exec())
+	return team
+}
+
+// inviteFixture adds a pending membership for user, sent by inviter.
+func (as *ActionSuite) inviteFixture(team models.Team, user, inviter uuid.UUID, at time.Time) models.TeamMember {
+	m := models.TeamMember{
+		ID: uuid.Must(uuid.NewV4()), TeamID: team.ID, UserID: user, Role: models.RoleMember,
+		Status: "pending", InvitedBy: nulls.NewUUID(inviter), CreatedAt: at, UpdatedAt: at,
+	}
+	as.NoError(as.DB.Create(&m))
+	return m
+}
+
+// projectFixture adds projects with the given names to team.
+func (as *ActionSuite) projectFixture(team models.Team, names ...string) {
+	for _, name := range names {
+		as.NoError(as.DB.Create(&models.Project{ID: uuid.Must(uuid.NewV4()), TeamID: nulls.NewUUID(team.ID), Name: name}))
+	}
+}
+
+func (as *ActionSuite) Test_PendingInvitations() {
+	ownerToken := as.registerToken("team-owner@example.com")
+	owner := as.userID(ownerToken)
+	token := as.registerToken("invitee@example.com")
+	uid := as.userID(token)
+
+	older := as.teamFixture("Older Team", owner)
+	newer := as.teamFixture("Newer Team", owner)
+	gone := as.teamFixture("Gone Team", owner)
+	first := as.inviteFixture(older, uid, owner, time.Now().Add(-time.Hour))
+	second := as.inviteFixture(newer, uid, owner, time.Now())
+	as.inviteFixture(gone, uid, owner, time.Now())
+	as.NoError(as.DB.Destroy(&gone))
+
+	res := as.authJSON(token, "/api/pending").Get()
+	as.Equal(http.StatusOK, res.Code)
+	var body struct {
+		Data []pendingInvitation `json:"data"`
+	}
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &body))
+	as.Len(body.Data, 2)
+	as.Equal(second.ID, body.Data[0].ID)
+	as.Equal("Newer Team", body.Data[0].TeamName)
+	as.Equal("team-owner@example.com", body.Data[0].InviterEmail.String)
+	as.Equal(first.ID, body.Data[1].ID)
+
+	// The owner's own active membership is not an invitation
+	res = as.authJSON(ownerToken, "/api/pending").Get()
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &body))
+	as.Empty(body.Data)
+}
+
+func (as *ActionSuite) Test_InviteByEmail() {
+	sent := make(captureSender, 4)
+	prev := mailSender
+	mailSender = sent
+	defer func() { mailSender = prev }()
+
+	ownerToken := as.registerToken("inviter@example.com")
+	team := as.teamFixture("Email Team", as.userID(ownerToken))
+	tokenFromMail := func() string {
+		select {
+		case msg := <-sent:
+			as.Equal("newcomer@example.com", msg.To)
+			i := strings.Index(msg.Text, "token=")
+			as.True(i >= 0)
+			return strings.Fields(msg.Text[i+len("token="):])[0]
+		case <-time.After(2 * time.Second):
+			as.Fail("no email sent")
+			return ""
+		}
+	}
+
+	res := as.authJSON(ownerToken, "/api/teams/%s/invite", team.ID).Post(map[string]string{"email": "Newcomer@Example.com", "role": "member"})
+	as.Equal(http.StatusCreated, res.Code)
+	stale := tokenFromMail()
+	// Re-inviting replaces the open invitation
+	res = as.authJSON(ownerToken, "/api/teams/%s/invite", team.ID).Post(map[string]string{"email": "newcomer@example.com", "role": "viewer"})
+	as.Equal(http.StatusCreated, res.Code)
+	token := tokenFromMail()
+	as.Equal(http.StatusNotFound, as.JSON("/api/invitations/%s", stale).Get().Code)
+
+	res = as.JSON("/api/invitations/%s", token).Get()
+	as.Equal(http.StatusOK, res.Code)
+	var preview struct {
+		Data struct {
+			TeamName     string `json:"team_name"`
+			InviterEmail string `json:"inviter_email"`
+			Role         string `json:"role"`
+		} `json:"data"`
+	}
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &preview))
+	as.Equal("Email Team", preview.Data.TeamName)
+	as.Equal("inviter@example.com", preview.Data.InviterEmail)
+	as.Equal("viewer", preview.Data.Role)
+
+	// Registering proves nothing about the mailbox: the invitation is not
+	// surfaced, only its emailed token is accepted
+	newcomer := as.registerToken("newcomer@example.com")
+	res = as.authJSON(newcomer, "/api/pending").Get()
+	var pending struct {
+		Data []pendingInvitation `json:"data"`
+	}
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &pending))
+	as.Empty(pending.Data)
+
+	res = as.authJSON(newcomer, "/api/invitations/%s/accept", token).Post(nil)
+	as.Equal(http.StatusOK, res.Code)
+	var member models.TeamMember
+	as.NoError(as.DB.Where("team_id = ? AND user_id = ?", team.ID, as.userID(newcomer)).First(&member))
+	as.Equal("active", member.Status)
+	as.Equal(models.RoleViewer, member.Role)
+
+	as.Equal(http.StatusConflict, as.authJSON(newcomer, "/api/invitations/%s/accept", token).Post(nil).Code)
+	as.Equal(http.StatusConflict, as.JSON("/api/invitations/%s", token).Get().Code)
+}
+
+func (as *ActionSuite) Test_InviteByEmail_Expired() {
+	sent := make(captureSender, 1)
+	prev := mailSender
+	mailSender = sent
+	defer func() { mailSender = prev }()
+
+	ownerToken := as.registerToken("expiring-owner@example.com")
+	team := as.teamFixture("Expiring Team", as.userID(ownerToken))
+	res := as.authJSON(ownerToken, "/api/teams/%s/invite", team.ID).Post(map[string]string{"email": "late@example.com", "role": "member"})
+	as.Equal(http.StatusCreated, res.Code)
+	msg := <-sent
+	token := strings.Fields(msg.Text[strings.Index(msg.Text, "token=")+len("token="):])[0]
+	as.NoError(as.DB.RawQuery("UPDATE team_invitations SET expires_at = ? WHERE team_id = ?", time.Now().Add(-time.Minute).UTC(), team.ID).Exec())
+
+	as.Equal(http.StatusGone, as.JSON("/api/invitations/%s", token).Get().Code)
+	late := as.registerToken("late@example.com")
+	as.Equal(http.StatusGone, as.authJSON(late, "/api/invitations/%s/accept", token).Post(nil).Code)
+
+	// Expired invitations are not surfaced on registration either
+	res = as.authJSON(late, "/api/pending").Get()
+	var pending struct {
+		Data []pendingInvitation `json:"data"`
+	}
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &pending))
+	as.Empty(pending.Data)
+
+	res = as.authJSON(ownerToken, "/api/teams/%s/invite", team.ID).Post(map[string]string{"email": "late@example.com", "role": "owner"})
+	as.Equal(http.StatusBadRequest, res.Code)
+}
+
+func (as *ActionSuite) Test_InvitationExpiry() {
+	sent := make(captureSender, 1)
+	prev := mailSender
+	mailSender = sent
+	defer func() { mailSender = prev }()
+
+	ownerToken := as.registerToken("expiry-owner@example.com")
+	owner := as.userID(ownerToken)
+	team := as.teamFixture("Expiry Team", owner)
+	as.NoError(as.DB.RawQuery(`UPDATE teams SET settings = '{"invitation_expiry_days": 3}' WHERE id = ?`, team.ID).Exec())
+	token := as.registerToken("expiry-invitee@example.com")
+
+	res := as.authJSON(ownerToken, "/api/teams/%s/invite", team.ID).Post(map[string]string{"email": "expiry-invitee@example.com", "role": "member"})
+	as.Equal(http.StatusCreated, res.Code)
+	as.Equal("expiry-invitee@example.com", (<-sent).To) // The invitation itself
+	var member models.TeamMember
+	as.NoError(as.DB.Where("team_id = ? AND user_id = ?", team.ID, as.userID(token)).First(&member))
+	as.WithinDuration(time.Now().Add(3*24*time.Hour), member.ExpiresAt.Time, time.Minute)
+
+	res = as.authJSON(token, "/api/pending").Get()
+	var pending struct {
+		Data []pendingInvitation `json:"data"`
+	}
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &pending))
+	as.Len(pending.Data, 1)
+	as.True(pending.Data[0].ExpiresAt.Valid)
+
+	as.NoError(as.DB.RawQuery("UPDATE team_members SET expires_at = ? WHERE id = ?", time.Now().Add(-time.Minute).UTC(), member.ID).Exec())
+	as.Equal(http.StatusGone, as.authJSON(token, "/api/teams/invitations/%s/accept", member.ID).Post(nil).Code)
+
+	n, err := ExpireTeamInvitations(as.DB, time.Now())
+	as.NoError(err)
+	as.Equal(1, n)
+	as.NoError(as.DB.Find(&member, member.ID))
+	as.Equal("expired", member.Status)
+	select {
+	case msg := <-sent:
+		as.Equal("expiry-owner@example.com", msg.To)
+		as.Contains(msg.Text, "expiry-invitee@example.com")
+	case <-time.After(2 * time.Second):
+		as.Fail("inviter not notified")
+	}
+	n, err = ExpireTeamInvitations(as.DB, time.Now())
+	as.NoError(err)
+	as.Zero(n)
+
+	// Inviting again renews the row instead of reporting a member
+	res = as.authJSON(ownerToken, "/api/teams/%s/invite", team.ID).Post(map[string]string{"email": "expiry-invitee@example.com", "role": "viewer"})
+	as.Equal(http.StatusCreated, res.Code)
+	as.NoError(as.DB.Find(&member, member.ID))
+	as.Equal("pending", member.Status)
+	as.Equal(models.RoleViewer, member.Role)
+	as.Equal(http.StatusOK, as.authJSON(token, "/api/teams/invitations/%s/accept", member.ID).Post(nil).Code)
+}
+
+func (as *ActionSuite) Test_DeclineInvitation() {
+	ownerToken := as.registerToken("decline-owner@example.com")
+	owner := as.userID(ownerToken)
+	team := as.teamFixture("Decline Team", owner)
+	token := as.registerToken("decliner@example.com")
+	invite := map[string]any{"email": "decliner@example.com", "role": "member"}
+
+	as.Equal(http.StatusCreated, as.authJSON(ownerToken, "/api/teams/%s/invite", team.ID).Post(invite).Code)
+	var member models.TeamMember
+	as.NoError(as.DB.Where("team_id = ? AND user_id = ?", team.ID, as.userID(token)).First(&member))
+	as.Equal(http.StatusOK, as.authJSON(token, "/api/teams/invitations/%s/decline", member.ID).Post(nil).Code)
+
+	as.NoError(as.DB.Find(&member, member.ID))
+	as.Equal("declined", member.Status)
+	as.True(member.DeclinedAt.Valid)
+	res := as.authJSON(token, "/api/pending").Get()
+	var pending struct {
+		Data []pendingInvitation `json:"data"`
+	}
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &pending))
+	as.Empty(pending.Data)
+
+	// Re-inviting waits for the cool-down unless forced
+	as.Equal(http.StatusConflict, as.authJSON(ownerToken, "/api/teams/%s/invite", team.ID).Post(invite).Code)
+	invite["force"] = true
+	as.Equal(http.StatusCreated, as.authJSON(ownerToken, "/api/teams/%s/invite", team.ID).Post(invite).Code)
+	as.NoError(as.DB.Find(&member, member.ID))
+	as.Equal("pending", member.Status)
+	as.False(member.DeclinedAt.Valid)
+
+	// After the cool-down no override is needed
+	as.Equal(http.StatusOK, as.authJSON(token, "/api/teams/invitations/%s/decline", member.ID).Post(nil).Code)
+	as.NoError(as.DB.RawQuery("UPDATE team_members SET declined_at = ? WHERE id = ?",
+		time.Now().Add(-models.InvitationDeclineCooldown-time.Minute).UTC(), member.ID).Exec())
+	delete(invite, "force")
+	as.Equal(http.StatusCreated, as.authJSON(ownerToken, "/api/teams/%s/invite", team.ID).Post(invite).Code)
+}
+
+func (as *ActionSuite) Test_DeleteTeam() {
+	ownerToken := as.registerToken("delete-owner@example.com")
+	team := as.teamFixture("Doomed Team", as.userID(ownerToken))
+	adminToken := as.registerToken("delete-admin@example.com")
+	admin := as.inviteFixture(team, as.userID(adminToken), as.userID(ownerToken), time.Now())
+	as.NoError(as.DB.RawQuery("UPDATE team_members SET status = 'active', role = ? WHERE id = ?", models.RoleAdmin, admin.ID).Exec())
+	res := as.authJSON(ownerToken, "/api/tracks/").Post(map[string]any{"start_at": time.Now().Add(-time.Hour).UTC(), "end_at": time.Now().UTC()})
+	as.Equal(http.StatusCreated, res.Code)
+
+	// Admins lack delete_team, and the name must be confirmed
+	res, err := as.authJSON(adminToken, "/api/teams/%s", team.ID).Do(http.MethodDelete, map[string]string{"confirm_name": "Doomed Team"})
+	as.NoError(err)
+	as.Equal(http.StatusForbidden, res.Code)
+	res, err = as.authJSON(ownerToken, "/api/teams/%s", team.ID).Do(http.MethodDelete, map[string]string{"confirm_name": "doomed team"})
+	as.NoError(err)
+	as.Equal(http.StatusUnprocessableEntity, res.Code)
+
+	res, err = as.authJSON(ownerToken, "/api/teams/%s", team.ID).Do(http.MethodDelete, map[string]string{"confirm_name": "Doomed Team"})
+	as.NoError(err)
+	as.Equal(http.StatusNoContent, res.Code)
+	count, err := as.DB.Where("team_id = ?", team.ID).Count(&models.TeamMember{})
+	as.NoError(err)
+	as.Zero(count)
+	count, err = as.DB.Where("user_id = ?", as.userID(ownerToken)).Count(&models.TimeTrac{})
+	as.NoError(err)
+	as.Equal(1, count)
+
+	res = as.authJSON(adminToken, "/api/teams/").Get()
+	as.Equal(http.StatusOK, res.Code)
+	as.NotContains(res.Body.String(), team.ID.String())
+}
+
+func (as *ActionSuite) Test_UpdateTeam() {
+	ownerToken := as.registerToken("update-owner@example.com")
+	owner := as.userID(ownerToken)
+	team := as.teamFixture("Before", owner)
+	viewerToken := as.registerToken("update-viewer@example.com")
+	viewer := as.inviteFixture(team, as.userID(viewerToken), owner, time.Now())
+	as.NoError(as.DB.RawQuery("UPDATE team_members SET status = 'active', role = ? WHERE id = ?", models.RoleViewer, viewer.ID).Exec())
+	outsiderToken := as.registerToken("update-outsider@example.com")
+
+	res := as.authJSON(ownerToken, "/api/webhooks/").Post(map[string]any{"url": "https://example.com/hook", "events": []string{"team.updated"}})
+	as.Equal(http.StatusCreated, res.Code)
+
+	patch := func(token string, body any) int {
+		res, err := as.authJSON(token, "/api/teams/%s", team.ID).Do(http.MethodPatch, body)
+		as.NoError(err)
+		return res.Code
+	}
+	as.Equal(http.StatusNotFound, patch(outsiderToken, map[string]string{"name": "Hijacked"}))
+	as.Equal(http.StatusForbidden, patch(viewerToken, map[string]string{"name": "Hijacked"}))
+	as.Equal(http.StatusUnprocessableEntity, patch(ownerToken, map[string]string{"name": "ab"}))
+	as.Equal(http.StatusUnprocessableEntity, patch(ownerToken, map[string]any{"settings": []int{1}}))
+	as.Equal(http.StatusUnprocessableEntity, patch(ownerToken, map[string]any{"settings": map[string]any{"invitation_expiry_days": 500}}))
+
+	as.Equal(http.StatusOK, patch(ownerToken, map[string]any{
+		"name":     " After ",
+		"settings": map[string]any{"invitation_expiry_days": 7},
+	}))
+	as.NoError(as.DB.Find(&team, team.ID))
+	as.Equal("After", team.Name)
+	as.Equal(7*24*time.Hour, team.InvitationTTL())
+
+	// Settings merge key by key and keep unknown keys
+	as.NoError(as.DB.RawQuery(`UPDATE teams SET settings = '{"invitation_expiry_days": 7, "beta": 1}' WHERE id = ?`, team.ID).Exec())
+	as.Equal(http.StatusOK, patch(ownerToken, map[string]any{"settings": map[string]any{"default_member_role": "viewer"}}))
+	as.NoError(as.DB.Find(&team, team.ID))
+	settings := team.ParsedSettings()
+	as.Equal(models.RoleViewer, settings.DefaultMemberRole)
+	as.Equal(7, settings.InvitationExpiryDays)
+	as.Contains(team.Settings, `"beta":1`)
+
+	// Invitations without a role use the new default at once
+	inviteeToken := as.registerToken("update-invitee@example.com")
+	res = as.authJSON(ownerToken, "/api/teams/%s/invite", team.ID).Post(map[string]string{"email": "update-invitee@example.com"})
+	as.Equal(http.StatusCreated, res.Code)
+	var invited models.TeamMember
+	as.NoError(as.DB.Where("team_id = ? AND user_id = ?", team.ID, as.userID(inviteeToken)).First(&invited))
+	as.Equal(models.RoleViewer, invited.Role)
+
+	count, err := as.DB.Where("event = ?", "team.updated").Count(&models.WebhookDelivery{})
+	as.NoError(err)
+	as.Equal(1, count)
+}
+
+func (as *ActionSuite) Test_TeamTracks() {
+	ownerToken := as.registerToken("tracks-owner@example.com")
+	owner := as.userID(ownerToken)
+	memberToken := as.registerToken("tracks-member@example.com")
+	member := as.userID(memberToken)
+	outsiderToken := as.registerToken("tracks-outsider@example.com")
+
+	team := as.teamFixture("Field Crew", owner)
+	as.projectFixture(team, "site")
+	m := as.inviteFixture(team, member, owner, time.Now())
+	as.NoError(as.DB.RawQuery("UPDATE team_members SET status = 'active' WHERE id = ?", m.ID).Exec())
+
+	res := as.authJSON(memberToken, "/api/tracks").Post(map[string]any{
+		"project": "personal", "start_at": "2025-09-01T08:00:00Z", "end_at": "2025-09-01T09:00:00Z",
+	})
+	as.Equal(http.StatusCreated, res.Code)
+	res = as.authJSON(memberToken, "/api/tracks/start").Post(map[string]any{
+		"project": "site", "team_id": team.ID, "location_lat": 52.123456, "location_lng": 13.654321,
+	})
+	as.Equal(http.StatusCreated, res.Code)
+
+	// Only active members may file entries under the team
+	res = as.authJSON(outsiderToken, "/api/tracks/start").Post(map[string]any{"team_id": team.ID})
+	as.Equal(http.StatusUnprocessableEntity, res.Code)
+	as.Contains(res.Body.String(), "team_id")
+	res = as.authJSON(outsiderToken, "/api/teams/%s/tracks", team.ID).Get()
+	as.Equal(http.StatusForbidden, res.Code)
+
+	var body struct {
+		Data struct {
+			Items []struct {
+				Project     string        `json:"project"`
+				UserID      uuid.UUID     `json:"user_id"`
+				UserEmail   string        `json:"user_email"`
+				LocationLat nulls.Float64 `json:"location_lat"`
+			} `json:"items"`
+			Total int `json:"total"`
+		} `json:"data"`
+	}
+	res = as.authJSON(ownerToken, "/api/teams/%s/tracks?member=%s", team.ID, member).Get()
+	as.Equal(http.StatusOK, res.Code)
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &body))
+	as.Equal(1, body.Data.Total)
+	as.Len(body.Data.Items, 1)
+	as.Equal("site", body.Data.Items[0].Project)
+	as.Equal(member, body.Data.Items[0].UserID)
+	as.Equal("tracks-member@example.com", body.Data.Items[0].UserEmail)
+	as.Equal(52.12, body.Data.Items[0].LocationLat.Float64)
+
+	// The team setting caps what the owner's preference allows
+	as.NoError(as.DB.RawQuery(`UPDATE teams SET settings = '{"member_location_visibility":"hidden"}' WHERE id = ?`, team.ID).Exec())
+	forgetTeamSettings(team.ID)
+	res = as.authJSON(ownerToken, "/api/teams/%s/tracks", team.ID).Get()
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &body))
+	as.Len(body.Data.Items, 1)
+	as.False(body.Data.Items[0].LocationLat.Valid)
+
+	// The member still sees their own location exactly
+	res = as.authJSON(memberToken, "/api/teams/%s/tracks", team.ID).Get()
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &body))
+	as.Equal(52.123456, body.Data.Items[0].LocationLat.Float64)
+
+	res = as.authJSON(ownerToken, "/api/teams/%s/tracks?from=2030-01-01T00:00:00Z", team.ID).Get()
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &body))
+	as.Empty(body.Data.Items)
+}
+
+func (as *ActionSuite) Test_TeamSummary() {
+	ownerToken := as.registerToken("summary-owner@example.com")
+	owner := as.userID(ownerToken)
+	viewerToken := as.registerToken("summary-viewer@example.com")
+	viewer := as.userID(viewerToken)
+	idleToken := as.registerToken("summary-idle@example.com")
+
+	team := as.teamFixture("Summary Team", owner)
+	as.projectFixture(team, "alpha", "beta")
+	for _, uid := range []uuid.UUID{viewer, as.userID(idleToken)} {
+		m := as.inviteFixture(team, uid, owner, time.Now())
+		as.NoError(as.DB.RawQuery("UPDATE team_members SET status = 'active' WHERE id = ?", m.ID).Exec())
+	}
+	as.NoError(as.DB.RawQuery("UPDATE team_members SET role = 'viewer' WHERE team_id = ? AND user_id = ?", team.ID, viewer).Exec())
+
+	entry := func(token, project, start, end string, billable bool) {
+		res := as.authJSON(token, "/api/tracks").Post(map[string]any{
+			"project": project, "team_id": team.ID, "billable": billable, "start_at": start, "end_at": end,
+		})
+		as.Equal(http.StatusCreated, res.Code)
+	}
+	entry(ownerToken, "alpha", "2025-09-01T08:00:00Z", "2025-09-01T10:00:00Z", true)
+	entry(ownerToken, "beta", "2025-09-02T08:00:00Z", "2025-09-02T09:00:00Z", false)
+	entry(viewerToken, "alpha", "2025-09-02T12:00:00Z", "2025-09-02T12:30:00Z", true)
+	// Personal time is not team time
+	res := as.authJSON(ownerToken, "/api/tracks").Post(map[string]any{
+		"project": "alpha", "start_at": "2025-09-03T08:00:00Z", "end_at": "2025-09-03T12:00:00Z",
+	})
+	as.Equal(http.StatusCreated, res.Code)
+
+	const period = "from=2025-09-01T00:00:00Z&to=2025-09-08T00:00:00Z&tz=UTC"
+	var members struct {
+		Data struct {
+			Rows []teamSummaryMember `json:"rows"`
+		} `json:"data"`
+	}
+	res = as.authJSON(ownerToken, "/api/teams/%s/summary?%s", team.ID, period).Get()
+	as.Equal(http.StatusOK, res.Code)
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &members))
+	as.Len(members.Data.Rows, 3)
+	as.Equal(owner, members.Data.Rows[0].UserID)
+	as.Equal(float64(3*3600), members.Data.Rows[0].TotalSeconds)
+	as.Equal(float64(2*3600), members.Data.Rows[0].BillableSeconds)
+	as.Equal(2, members.Data.Rows[0].EntryCount)
+	as.Len(members.Data.Rows[0].Projects, 2)
+	as.Equal("alpha", members.Data.Rows[0].Projects[0].Project)
+	as.Equal("summary-idle@example.com", members.Data.Rows[2].Email)
+	as.Zero(members.Data.Rows[2].TotalSeconds)
+	as.Empty(members.Data.Rows[2].Projects)
+
+	// Without view_analytics a member sees only their own row
+	res = as.authJSON(viewerToken, "/api/teams/%s/summary?%s", team.ID, period).Get()
+	as.Equal(http.StatusOK, res.Code)
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &members))
+	as.Len(members.Data.Rows, 1)
+	as.Equal(viewer, members.Data.Rows[0].UserID)
+	as.Equal(float64(1800), members.Data.Rows[0].TotalSeconds)
+
+	var groups struct {
+		Data struct {
+			Rows []map[string]any `json:"rows"`
+		} `json:"data"`
+	}
+	res = as.authJSON(ownerToken, "/api/teams/%s/summary?%s&group=project", team.ID, period).Get()
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &groups))
+	as.Len(groups.Data.Rows, 2)
+	as.Equal("alpha", groups.Data.Rows[0]["project"])
+	as.Equal(float64(2), groups.Data.Rows[0]["member_count"])
+
+	res = as.authJSON(ownerToken, "/api/teams/%s/summary?%s&group=day", team.ID, period).Get()
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &groups))
+	as.Len(groups.Data.Rows, 2)
+	as.Equal("2025-09-01", groups.Data.Rows[0]["day"])
+	as.Equal(float64(5400), groups.Data.Rows[1]["total_seconds"])
+
+	res = as.authJSON(ownerToken, "/api/teams/%s/summary?group=week", team.ID).Get()
+	as.Equal(http.StatusBadRequest, res.Code)
+}
+
+func (as *ActionSuite) Test_TeamProjects() {
+	ownerToken := as.registerToken("projects-owner@example.com")
+	owner := as.userID(ownerToken)
+	memberToken := as.registerToken("projects-member@example.com")
+
+	team := as.teamFixture("Project Team", owner)
+	m := as.inviteFixture(team, as.userID(memberToken), owner, time.Now())
+	as.NoError(as.DB.RawQuery("UPDATE team_members SET status = 'active' WHERE id = ?", m.ID).Exec())
+
+	var created struct {
+		Data models.Project `json:"data"`
+	}
+	res := as.authJSON(ownerToken, "/api/teams/%s/projects", team.ID).Post(map[string]any{"name": " Website ", "color": "#ABC"})
+	as.Equal(http.StatusCreated, res.Code)
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &created))
+	as.Equal("Website", created.Data.Name)
+	as.Equal("#aabbcc", created.Data.Color.String)
+
+	res = as.authJSON(ownerToken, "/api/teams/%s/projects", team.ID).Post(map[string]any{"name": "Website"})
+	as.Equal(http.StatusConflict, res.Code)
+	// Members lack manage_projects
+	res = as.authJSON(memberToken, "/api/teams/%s/projects", team.ID).Post(map[string]any{"name": "Side"})
+	as.Equal(http.StatusForbidden, res.Code)
+
+	entry := func(project string) int {
+		return as.authJSON(memberToken, "/api/tracks").Post(map[string]any{
+			"project": project, "team_id": team.ID, "start_at": "2025-09-01T08:00:00Z", "end_at": "2025-09-01T09:00:00Z",
+			"allow_overlap": true,
+		}).Code
+	}
+	as.Equal(http.StatusUnprocessableEntity, entry("Elsewhere"))
+	as.Equal(http.StatusCreated, entry("Website"))
+
+	var picker []map[string]any
+	res = as.authJSON(memberToken, "/api/projects").Get()
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &picker))
+	as.Len(picker, 1)
+	as.Equal("Website", picker[0]["name"])
+	as.Equal("Project Team", picker[0]["team_name"])
+	as.Equal(float64(1), picker[0]["entries"])
+
+	// A rename carries over to the team's entries
+	res = as.authJSON(ownerToken, "/api/teams/%s/projects/%s", team.ID, created.Data.ID).Patch(map[string]any{"name": "Web"})
+	as.Equal(http.StatusOK, res.Code)
+	n, err := as.DB.Where("team_id = ? AND project = ?", team.ID, "Web").Count(&models.TimeTrac{})
+	as.NoError(err)
+	as.Equal(1, n)
+
+	// Archived projects leave the picker and take no new entries
+	res = as.authJSON(ownerToken, "/api/teams/%s/projects/%s", team.ID, created.Data.ID).Patch(map[string]any{"archived": true})
+	as.Equal(http.StatusOK, res.Code)
+	res = as.authJSON(memberToken, "/api/projects").Get()
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &picker))
+	as.Empty(picker)
+	as.Equal(http.StatusUnprocessableEntity, entry("Web"))
+
+	res = as.authJSON(ownerToken, "/api/teams/%s/projects/%s", team.ID, created.Data.ID).Delete()
+	as.Equal(http.StatusNoContent, res.Code)
+	n, err = as.DB.Where("team_id = ?", team.ID).Count(&models.TimeTrac{})
+	as.NoError(err)
+	as.Equal(1, n)
+}
+
+func (as *ActionSuite) Test_RestrictedProjects() {
+	ownerToken := as.registerToken("restricted-owner@example.com")
+	owner := as.userID(ownerToken)
+	insiderToken := as.registerToken("restricted-insider@example.com")
+	insider := as.userID(insiderToken)
+	outsiderToken := as.registerToken("restricted-outsider@example.com")
+
+	team := as.teamFixture("Agency", owner)
+	for _, uid := range []uuid.UUID{insider, as.userID(outsiderToken)} {
+		m := as.inviteFixture(team, uid, owner, time.Now())
+		as.NoError(as.DB.RawQuery("UPDATE team_members SET status = 'active' WHERE id = ?", m.ID).Exec())
+	}
+
+	var created struct {
+		Data models.Project `json:"data"`
+	}
+	res := as.authJSON(ownerToken, "/api/teams/%s/projects", team.ID).Post(map[string]any{"name": "Secret", "restricted": true})
+	as.Equal(http.StatusCreated, res.Code)
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &created))
+	res = as.authJSON(ownerToken, "/api/teams/%s/projects/%s/members", team.ID, created.Data.ID).Post(map[string]any{"user_id": insider})
+	as.Equal(http.StatusOK, res.Code)
+
+	entry := func(token string) int {
+		return as.authJSON(token, "/api/tracks").Post(map[string]any{
+			"project": "Secret", "team_id": team.ID, "start_at": "2025-09-01T08:00:00Z", "end_at": "2025-09-01T09:00:00Z",
+		}).Code
+	}
+	as.Equal(http.StatusCreated, entry(insiderToken))
+	as.Equal(http.StatusUnprocessableEntity, entry(outsiderToken))
+
+	var list struct {
+		Data []json.RawMessage `json:"data"`
+	}
+	res = as.authJSON(outsiderToken, "/api/teams/%s/projects", team.ID).Get()
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &list))
+	as.Empty(list.Data)
+	res = as.authJSON(insiderToken, "/api/teams/%s/projects", team.ID).Get()
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &list))
+	as.Len(list.Data, 1)
+
+	var tracks struct {
+		Data struct {
+			Items []json.RawMessage `json:"items"`
+		} `json:"data"`
+	}
+	res = as.authJSON(outsiderToken, "/api/teams/%s/tracks", team.ID).Get()
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &tracks))
+	as.Empty(tracks.Data.Items)
+	res = as.authJSON(ownerToken, "/api/teams/%s/tracks", team.ID).Get()
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &tracks))
+	as.Len(tracks.Data.Items, 1)
+
+	var summary struct {
+		Data struct {
+			Rows []map[string]any `json:"rows"`
+		} `json:"data"`
+	}
+	const period = "from=2025-09-01T00:00:00Z&to=2025-09-02T00:00:00Z&group=project"
+	res = as.authJSON(outsiderToken, "/api/teams/%s/summary?%s", team.ID, period).Get()
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &summary))
+	as.Empty(summary.Data.Rows)
+
+	res = as.authJSON(ownerToken, "/api/teams/%s/projects/%s/members/%s", team.ID, created.Data.ID, insider).Delete()
+	as.Equal(http.StatusNoContent, res.Code)
+	as.Equal(http.StatusUnprocessableEntity, entry(insiderToken))
+}
+
+func (as *ActionSuite) Test_TeamRoles() {
+	ownerToken := as.registerToken("roles-owner@example.com")
+	owner := as.userID(ownerToken)
+	memberToken := as.registerToken("roles-member@example.com")
+
+	team := as.teamFixture("Role Team", owner)
+	m := as.inviteFixture(team, as.userID(memberToken), owner, time.Now())
+	as.NoError(as.DB.RawQuery("UPDATE team_members SET status = 'active' WHERE id = ?", m.ID).Exec())
+
+	res := as.authJSON(ownerToken, "/api/teams/%s/roles", team.ID).Post(map[string]any{"name": "accountant", "permissions": []string{"export"}})
+	as.Equal(http.StatusUnprocessableEntity, res.Code)
+	res = as.authJSON(ownerToken, "/api/teams/%s/roles", team.ID).Post(map[string]any{"name": "admin"})
+	as.Equal(http.StatusUnprocessableEntity, res.Code)
+
+	var created struct {
+		Data models.TeamRole `json:"data"`
+	}
+	res = as.authJSON(ownerToken, "/api/teams/%s/roles", team.ID).Post(map[string]any{
+		"name": "Accountant", "permissions": []string{"manage_projects", "view_team"},
+	})
+	as.Equal(http.StatusCreated, res.Code)
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &created))
+	as.Equal(models.TeamMemberRole("accountant"), created.Data.Name)
+
+	// Members cannot change roles
+	res = as.authJSON(memberToken, "/api/teams/%s/roles", team.ID).Post(map[string]any{"name": "boss"})
+	as.Equal(http.StatusForbidden, res.Code)
+	res = as.authJSON(memberToken, "/api/teams/%s/projects", team.ID).Post(map[string]any{"name": "Ledger"})
+	as.Equal(http.StatusForbidden, res.Code)
+
+	// The stored matrix decides what the new role may do
+	res = as.authJSON(ownerToken, "/api/teams/%s/members/%s", team.ID, m.ID).Put(map[string]any{"role": "accountant"})
+	as.Equal(http.StatusOK, res.Code)
+	res = as.authJSON(memberToken, "/api/teams/%s/projects", team.ID).Post(map[string]any{"name": "Ledger"})
+	as.Equal(http.StatusCreated, res.Code)
+	res = as.authJSON(ownerToken, "/api/teams/%s/members/%s", team.ID, m.ID).Put(map[string]any{"role": "owner"})
+	as.Equal(http.StatusUnprocessableEntity, res.Code)
+
+	// A role in use needs a replacement before it can go
+	res = as.authJSON(ownerToken, "/api/teams/%s/roles/%s", team.ID, created.Data.ID).Delete()
+	as.Equal(http.StatusConflict, res.Code)
+	as.Contains(res.Body.String(), `"member_count":1`)
+	res = as.authJSON(ownerToken, "/api/teams/%s/roles/%s?replacement_role=viewer", team.ID, created.Data.ID).Delete()
+	as.Equal(http.StatusNoContent, res.Code)
+	as.NoError(as.DB.Reload(&m))
+	as.Equal(models.RoleViewer, m.Role)
+
+	var builtIn models.TeamRole
+	as.NoError(as.DB.Where("team_id = ? AND name = ?", team.ID, models.RoleViewer).First(&builtIn))
+	res = as.authJSON(ownerToken, "/api/teams/%s/roles/%s", team.ID, builtIn.ID).Delete()
+	as.Equal(http.StatusConflict, res.Code)
+	res = as.authJSON(ownerToken, "/api/teams/%s/roles/%s", team.ID, builtIn.ID).Patch(map[string]any{"name": "reader"})
+	as.Equal(http.StatusUnprocessableEntity, res.Code)
+}
+
+func (as *ActionSuite) Test_TeamRoles_NoEscalation() {
+	ownerToken := as.registerToken("escalate-owner@example.com")
+	owner := as.userID(ownerToken)
+	smithToken := as.registerToken("escalate-smith@example.com")
+
+	team := as.teamFixture("Escalation", owner)
+	res := as.authJSON(ownerToken, "/api/teams/%s/roles", team.ID).Post(map[string]any{
+		"name": "rolesmith", "permissions": []string{"view_team", "manage_roles", "manage_projects"},
+	})
+	as.Equal(http.StatusCreated, res.Code)
+	m := as.inviteFixture(team, as.userID(smithToken), owner, time.Now())
+	as.NoError(as.DB.RawQuery("UPDATE team_members SET status = 'active', role = 'rolesmith' WHERE id = ?", m.ID).Exec())
+
+	// Owner-only permissions are never granted, not even by the owner
+	res = as.authJSON(ownerToken, "/api/teams/%s/roles", team.ID).Post(map[string]any{"name": "heir", "permissions": []string{"transfer_ownership"}})
+	as.Equal(http.StatusUnprocessableEntity, res.Code)
+
+	// Only what the caller holds can be granted
+	res = as.authJSON(smithToken, "/api/teams/%s/roles", team.ID).Post(map[string]any{"name": "payroll", "permissions": []string{"manage_rates"}})
+	as.Equal(http.StatusForbidden, res.Code)
+	res = as.authJSON(smithToken, "/api/teams/%s/roles", team.ID).Post(map[string]any{"name": "planner", "permissions": []string{"view_team", "manage_projects"}})
+	as.Equal(http.StatusCreated, res.Code)
+	var own models.TeamRole
+	as.NoError(as.DB.Where("team_id = ? AND name = ?", team.ID, "rolesmith").First(&own))
+	res = as.authJSON(smithToken, "/api/teams/%s/roles/%s", team.ID, own.ID).Patch(map[string]any{
+		"permissions": []string{"view_team", "manage_roles", "manage_projects", "manage_members"},
+	})
+	as.Equal(http.StatusForbidden, res.Code)
+
+	// Nor can roles above the caller be changed or deleted
+	var admin models.TeamRole
+	as.NoError(as.DB.Where("team_id = ? AND name = ?", team.ID, models.RoleAdmin).First(&admin))
+	res = as.authJSON(smithToken, "/api/teams/%s/roles/%s", team.ID, admin.ID).Patch(map[string]any{"permissions": []string{"view_team"}})
+	as.Equal(http.StatusForbidden, res.Code)
+
+	// Restricted projects follow manage_projects in the matrix
+	res = as.authJSON(ownerToken, "/api/teams/%s/projects", team.ID).Post(map[string]any{"name": "Vault", "restricted": true})
+	as.Equal(http.StatusCreated, res.Code)
+	res = as.authJSON(smithToken, "/api/tracks").Post(map[string]any{
+		"project": "Vault", "team_id": team.ID, "start_at": "2025-09-01T08:00:00Z", "end_at": "2025-09-01T09:00:00Z",
+	})
+	as.Equal(http.StatusCreated, res.Code)
+}
+
+func (as *ActionSuite) Test_TeamRoles_DefaultMemberRole() {
+	ownerToken := as.registerToken("default-role-owner@example.com")
+	team := as.teamFixture("Default Role", as.userID(ownerToken))
+	var created struct {
+		Data models.TeamRole `json:"data"`
+	}
+	res := as.authJSON(ownerToken, "/api/teams/%s/roles", team.ID).Post(map[string]any{"name": "intern", "permissions": []string{"view_team"}})
+	as.Equal(http.StatusCreated, res.Code)
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &created))
+
+	patch := func(role string) int {
+		res, err := as.authJSON(ownerToken, "/api/teams/%s", team.ID).Do(http.MethodPatch, map[string]any{"settings": map[string]any{"default_member_role": role}})
+		as.NoError(err)
+		return res.Code
+	}
+	as.Equal(http.StatusUnprocessableEntity, patch("ghost"))
+	as.Equal(http.StatusOK, patch("intern"))
+
+	// The setting follows a rename and falls back to member on deletion
+	res = as.authJSON(ownerToken, "/api/teams/%s/roles/%s", team.ID, created.Data.ID).Patch(map[string]any{"name": "trainee"})
+	as.Equal(http.StatusOK, res.Code)
+	as.NoError(as.DB.Find(&team, team.ID))
+	as.Equal(models.TeamMemberRole("trainee"), team.ParsedSettings().DefaultMemberRole)
+	res = as.authJSON(ownerToken, "/api/teams/%s/roles/%s", team.ID, created.Data.ID).Delete()
+	as.Equal(http.StatusNoContent, res.Code)
+	as.NoError(as.DB.Find(&team, team.ID))
+	as.Equal(models.RoleMember, team.ParsedSettings().DefaultMemberRole)
+}
+
+func (as *ActionSuite) Test_SuspendMember() {
+	ownerToken := as.registerToken("suspend-owner@example.com")
+	owner := as.userID(ownerToken)
+	memberToken := as.registerToken("suspend-member@example.com")
+
+	team := as.teamFixture("Contractors", owner)
+	as.projectFixture(team, "build")
+	m := as.inviteFixture(team, as.userID(memberToken), owner, time.Now())
+	as.NoError(as.DB.RawQuery("UPDATE team_members SET status = 'active' WHERE id = ?", m.ID).Exec())
+	res := as.authJSON(memberToken, "/api/tracks").Post(map[string]any{
+		"project": "build", "team_id": team.ID, "start_at": "2025-09-01T08:00:00Z", "end_at": "2025-09-01T09:00:00Z",
+	})
+	as.Equal(http.StatusCreated, res.Code)
+
+	res = as.authJSON(memberToken, "/api/teams/%s/members/%s/suspend", team.ID, m.ID).Post(nil)
+	as.Equal(http.StatusForbidden, res.Code)
+	var owners models.TeamMember
+	as.NoError(as.DB.Where("team_id = ? AND user_id = ?", team.ID, owner).First(&owners))
+	res = as.authJSON(ownerToken, "/api/teams/%s/members/%s/suspend", team.ID, owners.ID).Post(nil)
+	as.Equal(http.StatusForbidden, res.Code)
+	as.Contains(res.Body.String(), codeOwnerProtected)
+
+	res = as.authJSON(ownerToken, "/api/teams/%s/members/%s/suspend", team.ID, m.ID).Post(nil)
+	as.Equal(http.StatusOK, res.Code)
+	res = as.authJSON(ownerToken, "/api/teams/%s/members/%s/suspend", team.ID, m.ID).Post(nil)
+	as.Equal(http.StatusConflict, res.Code)
+
+	// The team stays listed but is closed to the suspended member
+	var teams struct {
+		Data []struct {
+			ID        uuid.UUID `json:"id"`
+			Suspended bool      `json:"suspended"`
+		} `json:"data"`
+	}
+	res = as.authJSON(memberToken, "/api/teams").Get()
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &teams))
+	as.Len(teams.Data, 1)
+	as.True(teams.Data[0].Suspended)
+	res = as.authJSON(memberToken, "/api/teams/%s", team.ID).Get()
+	as.Equal(http.StatusForbidden, res.Code)
+	res = as.authJSON(memberToken, "/api/teams/%s/tracks", team.ID).Get()
+	as.Equal(http.StatusForbidden, res.Code)
+
+	// Their time stays in the team's reports
+	var summary struct {
+		Data struct {
+			Rows []teamSummaryMember `json:"rows"`
+		} `json:"data"`
+	}
+	res = as.authJSON(ownerToken, "/api/teams/%s/summary?from=2025-09-01T00:00:00Z&to=2025-09-02T00:00:00Z", team.ID).Get()
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &summary))
+	as.Len(summary.Data.Rows, 2)
+	as.Equal("suspended", summary.Data.Rows[0].Status)
+	as.Equal(float64(3600), summary.Data.Rows[0].TotalSeconds)
+
+	res = as.authJSON(ownerToken, "/api/teams/%s/members/%s/reactivate", team.ID, m.ID).Post(nil)
+	as.Equal(http.StatusOK, res.Code)
+	res = as.authJSON(memberToken, "/api/teams/%s/tracks", team.ID).Get()
+	as.Equal(http.StatusOK, res.Code)
+}
+
+func (as *ActionSuite) Test_SuspendMember_Policy() {
+	ownerToken := as.registerToken("suspend-policy-owner@example.com")
+	owner := as.userID(ownerToken)
+	adminToken := as.registerToken("suspend-policy-admin@example.com")
+	otherToken := as.registerToken("suspend-policy-other@example.com")
+
+	team := as.teamFixture("Suspension Policy", owner)
+	admin := as.inviteFixture(team, as.userID(adminToken), owner, time.Now())
+	other := as.inviteFixture(team, as.userID(otherToken), owner, time.Now())
+	for _, m := range []models.TeamMember{admin, other} {
+		as.NoError(as.DB.RawQuery("UPDATE team_members SET status = 'active', role = 'admin' WHERE id = ?", m.ID).Exec())
+	}
+
+	// Admins neither suspend each other nor themselves; the owner can
+	res := as.authJSON(adminToken, "/api/teams/%s/members/%s/suspend", team.ID, other.ID).Post(nil)
+	as.Equal(http.StatusForbidden, res.Code)
+	as.Contains(res.Body.String(), codeAdminProtected)
+	res = as.authJSON(adminToken, "/api/teams/%s/members/%s/suspend", team.ID, admin.ID).Post(nil)
+	as.Equal(http.StatusForbidden, res.Code)
+	as.Contains(res.Body.String(), codeSelfChange)
+	res = as.authJSON(ownerToken, "/api/teams/%s/members/%s/suspend", team.ID, other.ID).Post(nil)
+	as.Equal(http.StatusOK, res.Code)
+	res = as.authJSON(adminToken, "/api/teams/%s/members/%s/reactivate", team.ID, other.ID).Post(nil)
+	as.Equal(http.StatusForbidden, res.Code)
+}
+
+func (as *ActionSuite) Test_Timesheets() {
+	ownerToken := as.registerToken("sheets-owner@example.com")
+	owner := as.userID(ownerToken)
+	memberToken := as.registerToken("sheets-member@example.com")
+
+	team := as.teamFixture("Timesheets", owner)
+	as.projectFixture(team, "audit")
+	m := as.inviteFixture(team, as.userID(memberToken), owner, time.Now())
+	as.NoError(as.DB.RawQuery("UPDATE team_members SET status = 'active' WHERE id = ?", m.ID).Exec())
+	var entry models.TimeTrac
+	res := as.authJSON(memberToken, "/api/tracks").Post(map[string]any{
+		"project": "audit", "team_id": team.ID, "start_at": "2025-09-02T08:00:00Z", "end_at": "2025-09-02T10:00:00Z",
+	})
+	as.Equal(http.StatusCreated, res.Code)
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &entry))
+
+	res = as.authJSON(memberToken, "/api/teams/%s/timesheets/2025-09-02/submit?tz=UTC", team.ID).Post(nil)
+	as.Equal(http.StatusUnprocessableEntity, res.Code)
+	res = as.authJSON(memberToken, "/api/teams/%s/timesheets/2025-09-01/submit?tz=UTC", team.ID).Post(nil)
+	as.Equal(http.StatusCreated, res.Code)
+	res = as.authJSON(memberToken, "/api/teams/%s/timesheets/2025-09-01/submit?tz=UTC", team.ID).Post(nil)
+	as.Equal(http.StatusConflict, res.Code)
+
+	// Members cannot review; approvers see the pending week with its total
+	res = as.authJSON(memberToken, "/api/teams/%s/timesheets", team.ID).Get()
+	as.Equal(http.StatusForbidden, res.Code)
+	var pending struct {
+		Data []timesheetView `json:"data"`
+	}
+	res = as.authJSON(ownerToken, "/api/teams/%s/timesheets", team.ID).Get()
+	as.Equal(http.StatusOK, res.Code)
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &pending))
+	as.Len(pending.Data, 1)
+	as.Equal("2025-09-01", pending.Data[0].Week)
+	as.Equal(float64(7200), pending.Data[0].TotalSeconds)
+	sheet := pending.Data[0].ID
+
+	// Rejecting needs a comment; the member can then resubmit
+	res = as.authJSON(ownerToken, "/api/teams/%s/timesheets/%s/reject", team.ID, sheet).Post(map[string]any{})
+	as.Equal(http.StatusUnprocessableEntity, res.Code)
+	res = as.authJSON(ownerToken, "/api/teams/%s/timesheets/%s/reject", team.ID, sheet).Post(map[string]any{"comment": "missing Friday"})
+	as.Equal(http.StatusOK, res.Code)
+	res = as.authJSON(memberToken, "/api/teams/%s/timesheets/2025-09-01/submit?tz=UTC", team.ID).Post(nil)
+	as.Equal(http.StatusOK, res.Code)
+
+	// Approval locks the week's entries until it is revoked
+	res = as.authJSON(ownerToken, "/api/teams/%s/timesheets/%s/approve", team.ID, sheet).Post(nil)
+	as.Equal(http.StatusOK, res.Code)
+	res = as.authJSON(memberToken, "/api/tracks/%s", entry.ID).Patch(map[string]any{"note": "late edit"})
+	as.Equal(http.StatusLocked, res.Code)
+	res = as.authJSON(memberToken, "/api/tracks/%s", entry.ID).Delete()
+	as.Equal(http.StatusLocked, res.Code)
+
+	// Nor can entries be moved into the approved week
+	res = as.authJSON(memberToken, "/api/tracks").Post(map[string]any{
+		"project": "audit", "team_id": team.ID, "start_at": "2025-09-09T08:00:00Z", "end_at": "2025-09-09T09:00:00Z",
+	})
+	as.Equal(http.StatusCreated, res.Code)
+	var later models.TimeTrac
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &later))
+	res = as.authJSON(memberToken, "/api/tracks/%s", later.ID).Patch(map[string]any{
+		"start_at": "2025-09-03T08:00:00Z", "end_at": "2025-09-03T09:00:00Z",
+	})
+	as.Equal(http.StatusLocked, res.Code)
+
+	res = as.authJSON(ownerToken, "/api/teams/%s/timesheets/%s/revoke", team.ID, sheet).Post(nil)
+	as.Equal(http.StatusOK, res.Code)
+	res = as.authJSON(memberToken, "/api/tracks/%s", entry.ID).Patch(map[string]any{"note": "late edit"})
+	as.Equal(http.StatusOK, res.Code)
+}
+
+func (as *ActionSuite) Test_Timesheets_LockedPaths() {
+	ownerToken := as.registerToken("locks-owner@example.com")
+	owner := as.userID(ownerToken)
+	memberToken := as.registerToken("locks-member@example.com")
+
+	team := as.teamFixture("Locks", owner)
+	as.projectFixture(team, "audit")
+	m := as.inviteFixture(team, as.userID(memberToken), owner, time.Now())
+	as.NoError(as.DB.RawQuery("UPDATE team_members SET status = 'active' WHERE id = ?", m.ID).Exec())
+	create := func(start, end string) models.TimeTrac {
+		res := as.authJSON(memberToken, "/api/tracks").Post(map[string]any{
+			"project": "audit", "team_id": team.ID, "start_at": start, "end_at": end,
+		})
+		as.Equal(http.StatusCreated, res.Code)
+		var entry models.TimeTrac
+		as.NoError(json.Unmarshal(res.Body.Bytes(), &entry))
+		return entry
+	}
+	first := create("2025-09-02T08:00:00Z", "2025-09-02T10:00:00Z")
+	second := create("2025-09-02T11:00:00Z", "2025-09-02T12:00:00Z")
+	short := create("2025-09-03T08:00:00Z", "2025-09-03T08:00:30Z")
+	trashed := create("2025-09-04T08:00:00Z", "2025-09-04T09:00:00Z")
+	as.Equal(http.StatusOK, as.authJSON(memberToken, "/api/tracks/%s", trashed.ID).Delete().Code)
+
+	res := as.authJSON(memberToken, "/api/teams/%s/timesheets/2025-09-01/submit?tz=UTC", team.ID).Post(nil)
+	as.Equal(http.StatusCreated, res.Code)
+	var sheet struct {
+		Data timesheetView `json:"data"`
+	}
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &sheet))
+	res = as.authJSON(ownerToken, "/api/teams/%s/timesheets/%s/approve", team.ID, sheet.Data.ID).Post(nil)
+	as.Equal(http.StatusOK, res.Code)
+
+	res = as.authJSON(memberToken, "/api/tracks").Post(map[string]any{
+		"project": "audit", "team_id": team.ID, "start_at": "2025-09-05T08:00:00Z", "end_at": "2025-09-05T09:00:00Z",
+	})
+	as.Equal(http.StatusLocked, res.Code)
+	res = as.authJSON(memberToken, "/api/tracks/%s/split", first.ID).Post(map[string]any{"at": "2025-09-02T09:00:00Z"})
+	as.Equal(http.StatusLocked, res.Code)
+	res = as.authJSON(memberToken, "/api/tracks/merge").Post(map[string]any{"ids": []uuid.UUID{first.ID, second.ID}})
+	as.Equal(http.StatusLocked, res.Code)
+	res = as.authJSON(memberToken, "/api/tracks/%s/restore", trashed.ID).Post(nil)
+	as.Equal(http.StatusLocked, res.Code)
+
+	// Bulk cleanup skips the locked short entry
+	res = as.authJSON(memberToken, "/api/tracks/short?under=60").Delete()
+	as.Equal(http.StatusOK, res.Code)
+	var cleaned struct {
+		Deleted int `json:"deleted"`
+	}
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &cleaned))
+	as.Equal(0, cleaned.Deleted)
+
+	// A sync change is an error result
+	res = as.authJSON(memberToken, "/api/tracks/sync").Post(map[string]any{"operations": []map[string]any{
+		{"type": "delete", "id": short.ID, "base_updated_at": short.UpdatedAt},
+	}})
+	as.Equal(http.StatusOK, res.Code)
+	var synced struct {
+		Results []syncResult `json:"results"`
+	}
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &synced))
+	as.Len(synced.Results, 1)
+	as.Equal(syncError, synced.Results[0].Status)
+	as.Equal(errEntryLocked.Error(), synced.Results[0].Error)
+}
+
+func (as *ActionSuite) Test_Timesheets_NoSelfReview() {
+	ownerToken := as.registerToken("self-review@example.com")
+	team := as.teamFixture("Self Review", as.userID(ownerToken))
+	as.projectFixture(team, "audit")
+	res := as.authJSON(ownerToken, "/api/tracks").Post(map[string]any{
+		"project": "audit", "team_id": team.ID, "start_at": "2025-09-02T08:00:00Z", "end_at": "2025-09-02T10:00:00Z",
+	})
+	as.Equal(http.StatusCreated, res.Code)
+
+	res = as.authJSON(ownerToken, "/api/teams/%s/timesheets/2025-09-01/submit?tz=UTC", team.ID).Post(nil)
+	as.Equal(http.StatusCreated, res.Code)
+	var sheet struct {
+		Data timesheetView `json:"data"`
+	}
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &sheet))
+	res = as.authJSON(ownerToken, "/api/teams/%s/timesheets/%s/approve", team.ID, sheet.Data.ID).Post(nil)
+	as.Equal(http.StatusForbidden, res.Code)
+}
+
+func (as *ActionSuite) Test_GetTeams() {
+	ownerToken := as.registerToken("list-owner@example.com")
+	owner := as.userID(ownerToken)
+	token := as.registerToken("list-member@example.com")
+	uid := as.userID(token)
+
+	// Both teams share the same two people; a third only invited the caller
+	older := as.teamFixture("Older Crew", owner)
+	newer := as.teamFixture("Newer Crew", owner)
+	pending := as.teamFixture("Pending Crew", owner)
+	joined := time.Now().Add(-48 * time.Hour)
+	for _, team := range []models.Team{older, newer} {
+		m := as.inviteFixture(team, uid, owner, joined)
+		as.NoError(as.DB.RawQuery("UPDATE team_members SET status = 'active', role = 'manager', joined_at = ? WHERE id = ?", joined, m.ID).Exec())
+		joined = joined.Add(24 * time.Hour)
+	}
+	as.inviteFixture(pending, uid, owner, time.Now())
+
+	var body struct {
+		Data []userTeam `json:"data"`
+	}
+	res := as.authJSON(token, "/api/teams").Get()
+	as.Equal(http.StatusOK, res.Code)
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &body))
+	as.Len(body.Data, 2)
+	as.Equal(newer.ID, body.Data[0].ID)
+	as.Equal(older.ID, body.Data[1].ID)
+	for _, team := range body.Data {
+		as.Equal(models.RoleManager, team.Role)
+		as.Equal(2, team.MemberCount)
+		as.NotNil(team.JoinedAt)
+		as.False(team.Suspended)
+	}
+
+	res = as.authJSON(ownerToken, "/api/teams").Get()
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &body))
+	as.Len(body.Data, 3)
+	for _, team := range body.Data {
+		as.Equal(models.RoleOwner, team.Role)
+	}
+}
+
+func (as *ActionSuite) Test_GetTeam_Members() {
+	ownerToken := as.registerToken("roster-owner@example.com")
+	owner := as.userID(ownerToken)
+	team := as.teamFixture("Roster", owner)
+	for i, status := range []string{"active", "pending", "suspended", "declined"} {
+		uid := as.userID(as.registerToken(fmt.Sprintf("roster-%d@example.com", i)))
+		m := as.inviteFixture(team, uid, owner, time.Now())
+		as.NoError(as.DB.RawQuery("UPDATE team_members SET status = ? WHERE id = ?", status, m.ID).Exec())
+	}
+
+	// GetTeam only counts active members unless asked for the roster
+	var team1 struct {
+		Data struct {
+			MemberCount int              `json:"member_count"`
+			Members     []teamMemberView `json:"members"`
+			MembersPage struct {
+				Total int `json:"total"`
+			} `json:"members_page"`
+		} `json:"data"`
+	}
+	res := as.authJSON(ownerToken, "/api/teams/%s", team.ID).Get()
+	as.Equal(http.StatusOK, res.Code)
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &team1))
+	as.Equal(2, team1.Data.MemberCount)
+	as.Nil(team1.Data.Members)
+	res = as.authJSON(ownerToken, "/api/teams/%s?include=members", team.ID).Get()
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &team1))
+	as.Len(team1.Data.Members, 3)
+	as.Equal(3, team1.Data.MembersPage.Total)
+
+	var body struct {
+		Data struct {
+			Items []teamMemberView `json:"items"`
+			Total int              `json:"total"`
+		} `json:"data"`
+	}
+	res = as.authJSON(ownerToken, "/api/teams/%s/members", team.ID).Get()
+	as.Equal(http.StatusOK, res.Code)
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &body))
+	as.Len(body.Data.Items, 3)
+	as.Equal("roster-owner@example.com", body.Data.Items[0].Email)
+	as.Equal(models.RoleOwner, body.Data.Items[0].Role)
+	for _, m := range body.Data.Items {
+		as.NotEqual("suspended", m.Status)
+	}
+
+	res = as.authJSON(ownerToken, "/api/teams/%s/members?include=all&per_page=2&page=3&sort=name", team.ID).Get()
+	as.Equal(http.StatusOK, res.Code)
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &body))
+	as.Len(body.Data.Items, 1)
+	as.Equal(5, body.Data.Total)
+	as.Equal("roster-owner@example.com", body.Data.Items[0].Email)
+
+	res = as.authJSON(ownerToken, "/api/teams/%s/members?q=ROSTER-1&role=member", team.ID).Get()
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &body))
+	as.Len(body.Data.Items, 1)
+	as.Equal("pending", body.Data.Items[0].Status)
+	res = as.authJSON(ownerToken, "/api/teams/%s/members?q=%%25", team.ID).Get()
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &body))
+	as.Empty(body.Data.Items)
+
+	res = as.authJSON(ownerToken, "/api/teams/%s/members?per_page=101", team.ID).Get()
+	as.Equal(http.StatusBadRequest, res.Code)
+	res = as.authJSON(ownerToken, "/api/teams/%s/members?sort=role", team.ID).Get()
+	as.Equal(http.StatusBadRequest, res.Code)
+}
+
+func (as *ActionSuite) Test_InviteMembersBatch() {
+	ownerToken := as.registerToken("batch-owner@example.com")
+	owner := as.userID(ownerToken)
+	as.registerToken("batch-known@example.com")
+	memberToken := as.registerToken("batch-member@example.com")
+	team := as.teamFixture("Onboarding", owner)
+	m := as.inviteFixture(team, as.userID(memberToken), owner, time.Now())
+	as.NoError(as.DB.RawQuery("UPDATE team_members SET status = 'active' WHERE id = ?", m.ID).Exec())
+
+	invitations := []map[string]string{
+		{"email": "batch-known@example.com", "role": "member"},
+		{"email": "newcomer-batch@example.com"},
+		{"email": "not an address"},
+		{"email": "someone@example.com", "role": "owner"},
+		{"email": "batch-member@example.com"},
+		{"email": "Batch-Known@example.com"},
+	}
+	res := as.authJSON(memberToken, "/api/teams/%s/invitations/batch", team.ID).Post(map[string]any{"invitations": invitations})
+	as.Equal(http.StatusForbidden, res.Code)
+
+	var body struct {
+		Data struct {
+			Results []inviteResult `json:"results"`
+			Invited int            `json:"invited"`
+		} `json:"data"`
+	}
+	res = as.authJSON(ownerToken, "/api/teams/%s/invitations/batch", team.ID).Post(map[string]any{"invitations": invitations})
+	as.Equal(http.StatusOK, res.Code)
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &body))
+	as.Equal(2, body.Data.Invited)
+	statuses := []string{}
+	for _, r := range body.Data.Results {
+		statuses = append(statuses, r.Status)
+	}
+	as.Equal([]string{"invited", "invited", "invalid_email", "invalid_role", "already_member", "duplicate"}, statuses)
+
+	pending, err := as.DB.Where("team_id = ? AND status = 'pending'", team.ID).Count(&models.TeamMember{})
+	as.NoError(err)
+	as.Equal(1, pending)
+
+	tooMany := make([]map[string]string, maxBatchInvitations+1)
+	for i := range tooMany {
+		tooMany[i] = map[string]string{"email": fmt.Sprintf("bulk-%d@example.com", i)}
+	}
+	res = as.authJSON(ownerToken, "/api/teams/%s/invitations/batch", team.ID).Post(map[string]any{"invitations": tooMany})
+	as.Equal(http.StatusUnprocessableEntity, res.Code)
+}
+
+func Test_MemberChangePolicy(t *testing.T) {
+	member := func(role models.TeamMemberRole) models.TeamMember {
+		return models.TeamMember{UserID: uuid.Must(uuid.NewV4()), Role: role}
+	}
+	owner, admin, other, manager := member(models.RoleOwner), member(models.RoleAdmin), member(models.RoleAdmin), member(models.RoleManager)
+	hr := member("hr")
+	deputy := member("deputy")
+	deputy.Permissions = []string{"view_team", "manage_members"}
+	cases := []struct {
+		name          string
+		actor, target models.TeamMember
+		role          models.TeamMemberRole
+		code          string
+	}{
+		{"owner promotes to owner", owner, admin, models.RoleOwner, codeOwnerViaTransfer},
+		{"admin demotes owner", admin, owner, models.RoleMember, codeOwnerProtected},
+		{"admin removes owner", admin, owner, "", codeOwnerProtected},
+		{"admin demotes admin", admin, other, models.RoleMember, codeAdminProtected},
+		{"custom role removes admin", hr, admin, "", codeAdminProtected},
+		{"admin removes custom admin role", admin, deputy, "", codeAdminProtected},
+		{"admin demotes self", admin, admin, models.RoleMember, codeSelfChange},
+		{"owner removes self", owner, owner, "", codeOwnerProtected},
+		{"owner demotes admin", owner, admin, models.RoleMember, ""},
+		{"admin promotes manager", admin, manager, models.RoleAdmin, ""},
+		{"admin removes manager", admin, manager, "", ""},
+	}
+	for _, tc := range cases {
+		v := memberChangePolicy(tc.actor, tc.target, tc.role)
+		switch {
+		case tc.code == "" && v != nil:
+			t.Errorf("%s: refused with %s", tc.name, v.Code)
+		case tc.code != "" && (v == nil || v.Code != tc.code):
+			t.Errorf("%s: got %+v, want %s", tc.name, v, tc.code)
+		}
+	}
+}
+
+func (as *ActionSuite) Test_JoinCodes() {
+	ownerToken := as.registerToken("codes-owner@example.com")
+	owner := as.userID(ownerToken)
+	firstToken := as.registerToken("codes-first@example.com")
+	secondToken := as.registerToken("codes-second@example.com")
+	team := as.teamFixture("Workshop", owner)
+
+	var created struct {
+		Data models.TeamJoinCode `json:"data"`
+	}
+	res := as.authJSON(firstToken, "/api/teams/%s/join-codes", team.ID).Post(map[string]any{"max_uses": 1})
+	as.Equal(http.StatusForbidden, res.Code)
+	res = as.authJSON(ownerToken, "/api/teams/%s/join-codes", team.ID).Post(map[string]any{"role": "owner"})
+	as.Equal(http.StatusUnprocessableEntity, res.Code)
+	res = as.authJSON(ownerToken, "/api/teams/%s/join-codes", team.ID).Post(map[string]any{"max_uses": 1, "role": "viewer"})
+	as.Equal(http.StatusCreated, res.Code)
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &created))
+	as.Len(created.Data.Code, 8)
+
+	join := func(token, code string) int {
+		return as.authJSON(token, "/api/teams/join").Post(map[string]string{"code": code}).Code
+	}
+	as.Equal(http.StatusNotFound, join(firstToken, "NOSUCHCODE"))
+	as.Equal(http.StatusOK, join(firstToken, strings.ToLower(created.Data.Code)))
+	m := models.TeamMember{}
+	as.NoError(as.DB.Where("team_id = ? AND user_id = ?", team.ID, as.userID(firstToken)).First(&m))
+	as.Equal("active", m.Status)
+	as.Equal(models.RoleViewer, m.Role)
+	as.Equal(http.StatusConflict, join(firstToken, created.Data.Code))
+
+	// The single use is spent; the code no longer shows up
+	as.Equal(http.StatusNotFound, join(secondToken, created.Data.Code))
+	var list struct {
+		Data []models.TeamJoinCode `json:"data"`
+	}
+	res = as.authJSON(ownerToken, "/api/teams/%s/join-codes", team.ID).Get()
+	as.Equal(http.StatusOK, res.Code)
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &list))
+	as.Len(list.Data, 0)
+
+	res = as.authJSON(ownerToken, "/api/teams/%s/join-codes", team.ID).Post(nil)
+	as.Equal(http.StatusCreated, res.Code)
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &created))
+	as.Equal(models.RoleMember, created.Data.Role)
+	as.False(created.Data.UsesRemaining.Valid)
+	res = as.authJSON(ownerToken, "/api/teams/%s/join-codes/%s", team.ID, created.Data.ID).Delete()
+	as.Equal(http.StatusOK, res.Code)
+	as.Equal(http.StatusNotFound, join(secondToken, created.Data.Code))
+
+	// Disabling the feature stops creating and redeeming codes
+	res = as.authJSON(ownerToken, "/api/teams/%s/join-codes", team.ID).Post(nil)
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &created))
+	as.NoError(as.DB.RawQuery(`UPDATE teams SET settings = '{"join_codes_enabled": false}' WHERE id = ?`, team.ID).Exec())
+	as.Equal(http.StatusNotFound, join(secondToken, created.Data.Code))
+	res = as.authJSON(ownerToken, "/api/teams/%s/join-codes", team.ID).Post(nil)
+	as.Equal(http.StatusForbidden, res.Code)
+
+	// Unusable codes all get the same answer, and guessing is throttled
+	unknown := as.authJSON(secondToken, "/api/teams/join").Post(map[string]string{"code": "NOSUCHCODE"})
+	disabled := as.authJSON(secondToken, "/api/teams/join").Post(map[string]string{"code": created.Data.Code})
+	as.Equal(unknown.Code, disabled.Code)
+	as.Equal(unknown.Body.String(), disabled.Body.String())
+	code := http.StatusNotFound
+	for i := 0; i < 30 && code == http.StatusNotFound; i++ {
+		code = join(secondToken, "NOSUCHCODE")
+	}
+	as.Equal(http.StatusTooManyRequests, code)
+}
+
+func (as *ActionSuite) Test_MemberRates() {
+	ownerToken := as.registerToken("rates-owner@example.com")
+	owner := as.userID(ownerToken)
+	workerToken := as.registerToken("rates-worker@example.com")
+	worker := as.userID(workerToken)
+	team := as.teamFixture("Agency", owner)
+	as.projectFixture(team, "alpha")
+	m := as.inviteFixture(team, worker, owner, time.Now())
+	as.NoError(as.DB.RawQuery("UPDATE team_members SET status = 'active' WHERE id = ?", m.ID).Exec())
+
+	// Members cannot see rates, not even their own
+	res := as.authJSON(workerToken, "/api/teams/%s/members/%s/rate", team.ID, m.ID).Get()
+	as.Equal(http.StatusForbidden, res.Code)
+
+	var rate struct {
+		Data struct {
+			HourlyRateCents nulls.Int           `json:"hourly_rate_cents"`
+			Currency        nulls.String        `json:"currency"`
+			History         []models.MemberRate `json:"history"`
+		} `json:"data"`
+	}
+	put := func(body map[string]any) int {
+		res := as.authJSON(ownerToken, "/api/teams/%s/members/%s/rate", team.ID, m.ID).Put(body)
+		if res.Code == http.StatusOK {
+			as.NoError(json.Unmarshal(res.Body.Bytes(), &rate))
+		}
+		return res.Code
+	}
+	as.Equal(http.StatusUnprocessableEntity, put(map[string]any{"hourly_rate_cents": -1}))
+	as.Equal(http.StatusUnprocessableEntity, put(map[string]any{"hourly_rate_cents": 5000, "currency": "euro"}))
+	as.Equal(http.StatusOK, put(map[string]any{"hourly_rate_cents": 5000}))
+	as.Equal(5000, rate.Data.HourlyRateCents.Int)
+	as.Equal(models.DefaultTeamCurrency, rate.Data.Currency.String)
+	as.Len(rate.Data.History, 1)
+	as.Equal(http.StatusOK, put(map[string]any{"hourly_rate_cents": 5000, "currency": "usd"}))
+	as.Len(rate.Data.History, 1)
+
+	res = as.authJSON(ownerToken, "/api/teams/%s/members", team.ID).Get()
+	as.Equal(http.StatusOK, res.Code)
+	as.NotContains(res.Body.String(), "hourly_rate")
+
+	// Entries are priced at the rate in force when they started
+	as.NoError(as.DB.RawQuery("DELETE FROM member_rate_history WHERE team_id = ?", team.ID).Exec())
+	for _, h := range []struct {
+		cents int
+		from  string
+	}{{6000, "2025-09-02T00:00:00Z"}, {9000, "2025-09-03T00:00:00Z"}} {
+		from, _ := time.Parse(time.RFC3339, h.from)
+		as.NoError(as.DB.Create(&models.MemberRate{
+			ID: uuid.Must(uuid.NewV4()), TeamID: team.ID, UserID: worker,
+			HourlyRateCents: nulls.NewInt(h.cents), Currency: nulls.NewString("EUR"), EffectiveFrom: from,
+		}))
+	}
+	entry := func(start, end string, billable bool) {
+		res := as.authJSON(workerToken, "/api/tracks").Post(map[string]any{
+			"project": "alpha", "team_id": team.ID, "billable": billable, "start_at": start, "end_at": end,
+		})
+		as.Equal(http.StatusCreated, res.Code)
+	}
+	entry("2025-09-01T08:00:00Z", "2025-09-01T09:00:00Z", true) // Before any rate
+	entry("2025-09-02T08:00:00Z", "2025-09-02T10:00:00Z", true)
+	entry("2025-09-03T08:00:00Z", "2025-09-03T09:00:00Z", false)
+
+	const period = "from=2025-09-01T00:00:00Z&to=2025-09-08T00:00:00Z&tz=UTC"
+	var members struct {
+		Data struct {
+			Rows []teamSummaryMember `json:"rows"`
+		} `json:"data"`
+	}
+	res = as.authJSON(ownerToken, "/api/teams/%s/summary?%s", team.ID, period).Get()
+	as.Equal(http.StatusOK, res.Code)
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &members))
+	as.Len(members.Data.Rows, 2)
+	as.Equal(worker, members.Data.Rows[0].UserID)
+	as.Equal(map[string]int64{"EUR": 21000}, *members.Data.Rows[0].CostCents)
+	as.Equal(map[string]int64{"EUR": 12000}, *members.Data.Rows[0].BillableCostCents)
+	as.Nil(members.Data.Rows[1].CostCents) // The owner tracked nothing: null cost
+
+	var days struct {
+		Data struct {
+			Rows []map[string]any `json:"rows"`
+		} `json:"data"`
+	}
+	res = as.authJSON(ownerToken, "/api/teams/%s/summary?group=day&%s", team.ID, period).Get()
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &days))
+	as.Len(days.Data.Rows, 3)
+	as.Nil(days.Data.Rows[0]["cost_cents"])
+	as.Equal(map[string]any{"EUR": float64(12000)}, days.Data.Rows[1]["cost_cents"])
+
+	// Without manage_rates the summary carries no costs
+	res = as.authJSON(workerToken, "/api/teams/%s/summary?%s", team.ID, period).Get()
+	as.Equal(http.StatusOK, res.Code)
+	as.NotContains(res.Body.String(), "cost_cents")
+}
+
+func (as *ActionSuite) Test_TeamNotifications() {
+	sent := make(captureSender, 4)
+	prev := mailSender
+	mailSender = sent
+	defer func() { mailSender = prev }()
+	next := func() mailer.Message {
+		select {
+		case msg := <-sent:
+			return msg
+		case <-time.After(2 * time.Second):
+			as.Fail("no email sent")
+			return mailer.Message{}
+		}
+	}
+
+	ownerToken := as.registerToken("notify-owner@example.com")
+	team := as.teamFixture("Notify Team", as.userID(ownerToken))
+	token := as.registerToken("notify-member@example.com")
+
+	res := as.authJSON(ownerToken, "/api/teams/%s/invite", team.ID).Post(map[string]string{"email": "notify-member@example.com", "role": "member"})
+	as.Equal(http.StatusCreated, res.Code)
+	var member models.TeamMember
+	as.NoError(as.DB.Where("team_id = ? AND user_id = ?", team.ID, as.userID(token)).First(&member))
+	msg := next()
+	as.Equal("notify-member@example.com", msg.To)
+	as.Contains(msg.Subject, "Notify Team")
+	as.Contains(msg.Text, "notify-owner@example.com")
+	as.Contains(msg.Text, "member_id="+member.ID.String()+"&action=accept")
+	as.Contains(msg.Text, "action=decline")
+	as.Equal(http.StatusOK, as.authJSON(token, "/api/teams/invitations/%s/accept", member.ID).Post(nil).Code)
+
+	res = as.authJSON(ownerToken, "/api/teams/%s/members/%s", team.ID, member.ID).Put(map[string]string{"role": "viewer"})
+	as.Equal(http.StatusOK, res.Code)
+	msg = next()
+	as.Equal("notify-member@example.com", msg.To)
+	as.Contains(msg.Text, "from member to viewer")
+
+	// Opting out silences the removal email
+	res = as.authJSON(token, "/api/me/preferences").Patch(map[string]bool{"team_notification_emails": false})
+	as.Equal(http.StatusOK, res.Code)
+	res = as.authJSON(ownerToken, "/api/teams/%s/members/%s", team.ID, member.ID).Delete()
+	as.Equal(http.StatusOK, res.Code)
+	select {
+	case msg := <-sent:
+		as.Fail("unexpected email", msg.Subject)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func (as *ActionSuite) Test_SeatLimits() {
+	ownerToken := as.registerToken("seats-owner@example.com")
+	team := as.teamFixture("Seats Team", as.userID(ownerToken))
+	firstToken := as.registerToken("seats-first@example.com")
+	secondToken := as.registerToken("seats-second@example.com")
+	as.registerToken("seats-third@example.com")
+	as.NoError(SetTeamSeatLimit(as.DB, team.ID, 2))
+
+	// Pending invitations do not take a seat
+	invite := func(email string) int {
+		return as.authJSON(ownerToken, "/api/teams/%s/invite", team.ID).Post(map[string]string{"email": email, "role": "member"}).Code
+	}
+	as.Equal(http.StatusCreated, invite("seats-first@example.com"))
+	as.Equal(http.StatusCreated, invite("seats-second@example.com"))
+	accept := func(token string) int {
+		var m models.TeamMember
+		as.NoError(as.DB.Where("team_id = ? AND user_id = ?", team.ID, as.userID(token)).First(&m))
+		return as.authJSON(token, "/api/teams/invitations/%s/accept", m.ID).Post(nil).Code
+	}
+	as.Equal(http.StatusOK, accept(firstToken))
+
+	// The team is full now
+	as.Equal(http.StatusPaymentRequired, accept(secondToken))
+	res := as.authJSON(ownerToken, "/api/teams/%s/invite", team.ID).Post(map[string]string{"email": "seats-third@example.com", "role": "member"})
+	as.Equal(http.StatusPaymentRequired, res.Code)
+	var full struct {
+		Seats seatUsage `json:"seats"`
+	}
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &full))
+	as.Equal(2, full.Seats.Used)
+	as.Equal(2, full.Seats.Limit.Int)
+
+	var code struct {
+		Data models.TeamJoinCode `json:"data"`
+	}
+	res = as.authJSON(ownerToken, "/api/teams/%s/join-codes", team.ID).Post(nil)
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &code))
+	res = as.authJSON(secondToken, "/api/teams/join").Post(map[string]string{"code": code.Data.Code})
+	as.Equal(http.StatusPaymentRequired, res.Code)
+
+	var detail struct {
+		Data struct {
+			Seats *seatUsage `json:"seats"`
+		} `json:"data"`
+	}
+	res = as.authJSON(ownerToken, "/api/teams/%s", team.ID).Get()
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &detail))
+	as.NotNil(detail.Data.Seats)
+	as.Equal(2, detail.Data.Seats.Used)
+	res = as.authJSON(firstToken, "/api/teams/%s", team.ID).Get()
+	detail.Data.Seats = nil
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &detail))
+	as.Nil(detail.Data.Seats)
+
+	// Only operators change the limit
+	res = as.authJSON(ownerToken, "/api/teams/%s", team.ID).Patch(map[string]any{"settings": map[string]int{"max_members": 10}})
+	as.Equal(http.StatusUnprocessableEntity, res.Code)
+	as.NoError(SetTeamSeatLimit(as.DB, team.ID, 0))
+	as.Equal(http.StatusOK, accept(secondToken))
+}
+
+func (as *ActionSuite) Test_ArchiveTeam() {
+	ownerToken := as.registerToken("archive-owner@example.com")
+	memberToken := as.registerToken("archive-member@example.com")
+	as.registerToken("archive-late@example.com")
+	team := as.teamFixture("Finished Client", as.userID(ownerToken))
+	as.projectFixture(team, "launch")
+	as.NoError(as.DB.RawQuery(`INSERT INTO team_members (id, team_id, user_id, role, status, joined_at, created_at, updated_at)
+		VALUES (?, ?, ?, 'admin', 'active', now(), now(), now())`, uuid.Must(uuid.NewV4()), team.ID, as.userID(memberToken)).Exec())
+
+	start := time.Now().Add(-3 * time.Hour).UTC().Truncate(time.Second)
+	entries := make([]models.TimeTrac, 2)
+	for i := range entries {
+		res := as.authJSON(memberToken, "/api/tracks/").Post(map[string]any{
+			"team_id": team.ID, "project": "launch", "start_at": start.Add(time.Duration(i) * time.Hour),
+			"end_at": start.Add(time.Duration(i)*time.Hour + 30*time.Minute),
+		})
+		as.Equal(http.StatusCreated, res.Code)
+		as.NoError(json.Unmarshal(res.Body.Bytes(), &entries[i]))
+	}
+	as.Equal(http.StatusOK, as.authJSON(memberToken, "/api/tracks/%s", entries[1].ID).Delete().Code)
+
+	as.Equal(http.StatusForbidden, as.authJSON(memberToken, "/api/teams/%s/archive", team.ID).Post(nil).Code)
+	as.Equal(http.StatusOK, as.authJSON(ownerToken, "/api/teams/%s/archive", team.ID).Post(nil).Code)
+	as.Equal(http.StatusConflict, as.authJSON(ownerToken, "/api/teams/%s/archive", team.ID).Post(nil).Code)
+
+	// Its entries cannot be edited, stopped, merged, split, restored or changed by a sync
+	as.Equal(http.StatusConflict, as.authJSON(memberToken, "/api/tracks/%s", entries[0].ID).Patch(map[string]string{"note": "late"}).Code)
+	as.Equal(http.StatusConflict, as.authJSON(memberToken, "/api/tracks/stop").Post(map[string]any{"id": entries[0].ID, "force": true}).Code)
+	res := as.authJSON(memberToken, "/api/tracks/").Post(map[string]any{"project": "Own", "start_at": start.Add(2 * time.Hour), "end_at": start.Add(150 * time.Minute)})
+	as.Equal(http.StatusCreated, res.Code)
+	var personal models.TimeTrac
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &personal))
+	res = as.authJSON(memberToken, "/api/tracks/merge").Post(map[string]any{"ids": []uuid.UUID{personal.ID, entries[0].ID}, "force": true})
+	as.Equal(http.StatusConflict, res.Code)
+	res = as.authJSON(memberToken, "/api/tracks/%s/split", entries[0].ID).Post(map[string]any{"at": start.Add(10 * time.Minute)})
+	as.Equal(http.StatusConflict, res.Code)
+	as.Equal(http.StatusConflict, as.authJSON(memberToken, "/api/tracks/%s/restore", entries[1].ID).Post(nil).Code)
+	var synced struct {
+		Results []syncResult `json:"results"`
+	}
+	res = as.authJSON(memberToken, "/api/tracks/sync").Post(map[string]any{"operations": []map[string]any{{
+		"type": "update", "id": entries[0].ID, "base_updated_at": entries[0].UpdatedAt, "data": map[string]any{"note": "late"},
+	}}})
+	as.Equal(http.StatusOK, res.Code)
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &synced))
+	as.Len(synced.Results, 1)
+	as.Equal("error", synced.Results[0].Status)
+	as.Equal("team is archived", synced.Results[0].Error)
+
+	var list struct {
+		Data []models.Team `json:"data"`
+	}
+	res = as.authJSON(memberToken, "/api/teams").Get()
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &list))
+	as.Len(list.Data, 0)
+	res = as.authJSON(memberToken, "/api/teams?archived=true").Get()
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &list))
+	as.Len(list.Data, 1)
+	as.True(list.Data[0].ArchivedAt.Valid)
+
+	// No new entries or invitations; reports stay readable
+	res = as.authJSON(memberToken, "/api/tracks/start").Post(map[string]any{"team_id": team.ID, "project": "launch"})
+	as.Equal(http.StatusConflict, res.Code)
+	res = as.authJSON(ownerToken, "/api/teams/%s/invite", team.ID).Post(map[string]string{"email": "archive-late@example.com"})
+	as.Equal(http.StatusConflict, res.Code)
+	as.Equal(http.StatusConflict, as.authJSON(ownerToken, "/api/teams/%s/join-codes", team.ID).Post(nil).Code)
+	as.Equal(http.StatusOK, as.authJSON(memberToken, "/api/teams/%s/summary", team.ID).Get().Code)
+	as.Equal(http.StatusOK, as.authJSON(memberToken, "/api/teams/%s/tracks", team.ID).Get().Code)
+
+	as.Equal(http.StatusOK, as.authJSON(ownerToken, "/api/teams/%s/unarchive", team.ID).Post(nil).Code)
+	res = as.authJSON(memberToken, "/api/tracks/start").Post(map[string]any{"team_id": team.ID, "project": "launch"})
+	as.Equal(http.StatusCreated, res.Code)
+}
+
+func (as *ActionSuite) Test_TracksRestart_Team() {
+	ownerToken := as.registerToken("restart-owner@example.com")
+	memberToken := as.registerToken("restart-member@example.com")
+	team := as.teamFixture("Restart Team", as.userID(ownerToken))
+	as.projectFixture(team, "launch")
+	as.NoError(as.DB.RawQuery(`INSERT INTO team_members (id, team_id, user_id, role, status, joined_at, created_at, updated_at)
+		VALUES (?, ?, ?, 'member', 'active', now(), now(), now())`, uuid.Must(uuid.NewV4()), team.ID, as.userID(memberToken)).Exec())
+
+	start := time.Now().Add(-2 * time.Hour).UTC().Truncate(time.Second)
+	var src models.TimeTrac
+	res := as.authJSON(memberToken, "/api/tracks/").Post(map[string]any{
+		"team_id": team.ID, "project": "launch", "billable": true, "start_at": start, "end_at": start.Add(time.Hour),
+	})
+	as.Equal(http.StatusCreated, res.Code)
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &src))
+
+	// The restarted entry stays in the team and keeps billable
+	var restarted struct {
+		Entry models.TimeTrac `json:"entry"`
+	}
+	res = as.authJSON(memberToken, "/api/tracks/%s/restart", src.ID).Post(nil)
+	as.Equal(http.StatusCreated, res.Code)
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &restarted))
+	as.Equal(nulls.NewUUID(team.ID), restarted.Entry.TeamID)
+	as.True(restarted.Entry.Billable)
+	as.False(restarted.Entry.EndAt.Valid)
+
+	// The team rules apply again: not once the team is archived, nor to
+	// a suspended member
+	as.Equal(http.StatusOK, as.authJSON(ownerToken, "/api/teams/%s/archive", team.ID).Post(nil).Code)
+	as.Equal(http.StatusConflict, as.authJSON(memberToken, "/api/tracks/%s/restart", src.ID).Post(nil).Code)
+	as.Equal(http.StatusOK, as.authJSON(ownerToken, "/api/teams/%s/unarchive", team.ID).Post(nil).Code)
+	as.NoError(as.DB.RawQuery("UPDATE team_members SET status = 'suspended' WHERE team_id = ? AND user_id = ?", team.ID, as.userID(memberToken)).Exec())
+	as.Equal(http.StatusUnprocessableEntity, as.authJSON(memberToken, "/api/tracks/%s/restart", src.ID).Post(nil).Code)
+}
+
+func (as *ActionSuite) Test_InvitePolicy() {
+	ownerToken := as.registerToken("policy-owner@example.com")
+	managerToken := as.registerToken("policy-manager@example.com")
+	as.registerToken("policy-guest@example.com")
+	team := as.teamFixture("Policy Team", as.userID(ownerToken))
+	as.NoError(as.DB.RawQuery(`INSERT INTO team_members (id, team_id, user_id, role, status, joined_at, created_at, updated_at)
+		VALUES (?, ?, ?, 'manager', 'active', now(), now(), now())`, uuid.Must(uuid.NewV4()), team.ID, as.userID(managerToken)).Exec())
+
+	code := func(raw []byte) string {
+		var body struct {
+			Code string `json:"code"`
+		}
+		as.NoError(json.Unmarshal(raw, &body))
+		return body.Code
+	}
+
+	// Managers may not invite above their own role
+	res := as.authJSON(managerToken, "/api/teams/%s/invite", team.ID).Post(map[string]string{"email": "policy-guest@example.com", "role": "admin"})
+	as.Equal(http.StatusForbidden, res.Code)
+	as.Equal("role_above_inviter", code(res.Body.Bytes()))
+	res = as.authJSON(managerToken, "/api/teams/%s/invitations/batch", team.ID).Post(map[string]any{
+		"invitations": []map[string]string{{"email": "policy-guest@example.com", "role": "admin"}},
+	})
+	as.Equal(http.StatusOK, res.Code)
+	as.Contains(res.Body.String(), "role_above_inviter")
+
+	// A tighter policy applies to the next invitation
+	res = as.authJSON(ownerToken, "/api/teams/%s", team.ID).Patch(map[string]any{"settings": map[string]string{"invite_policy": "admins"}})
+	as.Equal(http.StatusOK, res.Code)
+	res = as.authJSON(managerToken, "/api/teams/%s/invite", team.ID).Post(map[string]string{"email": "policy-guest@example.com"})
+	as.Equal(http.StatusForbidden, res.Code)
+	as.Equal("invite_policy", code(res.Body.Bytes()))
+	res = as.authJSON(ownerToken, "/api/teams/%s", team.ID).Patch(map[string]any{"settings": map[string]string{"invite_policy": "everyone"}})
+	as.Equal(http.StatusUnprocessableEntity, res.Code)
+
+	// Without a role the default_member_role applies
+	res = as.authJSON(ownerToken, "/api/teams/%s", team.ID).Patch(map[string]any{"settings": map[string]string{"default_member_role": "viewer"}})
+	as.Equal(http.StatusOK, res.Code)
+	res = as.authJSON(ownerToken, "/api/teams/%s/invite", team.ID).Post(map[string]string{"email": "policy-guest@example.com"})
+	as.Equal(http.StatusCreated, res.Code)
+	var invited struct {
+		Data models.TeamMember `json:"data"`
+	}
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &invited))
+	as.Equal(models.RoleViewer, invited.Data.Role)
+}
+
+func (as *ActionSuite) Test_GuestRole() {
+	ownerToken := as.registerToken("guest-owner@example.com")
+	guestToken := as.registerToken("guest-client@example.com")
+	guest := as.userID(guestToken)
+	team := as.teamFixture("Agency Team", as.userID(ownerToken))
+	as.projectFixture(team, "internal", "review")
+	as.NoError(as.DB.RawQuery(`INSERT INTO team_members (id, team_id, user_id, role, status, joined_at, created_at, updated_at)
+		VALUES (?, ?, ?, 'guest', 'active', now(), now(), now())`, uuid.Must(uuid.NewV4()), team.ID, guest).Exec())
+	var review models.Project
+	as.NoError(as.DB.Where("team_id = ? AND name = ?", team.ID, "review").First(&review))
+	res := as.authJSON(ownerToken, "/api/teams/%s/projects/%s/members", team.ID, review.ID).Post(map[string]any{"user_id": guest})
+	as.Equal(http.StatusOK, res.Code)
+
+	// Guests log time only on projects they were added to
+	start := func(token, project string) int {
+		return as.authJSON(token, "/api/tracks/start").Post(map[string]any{"team_id": team.ID, "project": project}).Code
+	}
+	as.Equal(http.StatusUnprocessableEntity, start(guestToken, ""))
+	as.Equal(http.StatusUnprocessableEntity, start(guestToken, "internal"))
+	as.Equal(http.StatusCreated, start(guestToken, "review"))
+	as.Equal(http.StatusCreated, start(ownerToken, "internal"))
+
+	// They see their own entries only, and no members
+	var tracks struct {
+		Data struct {
+			Total int `json:"total"`
+		} `json:"data"`
+	}
+	res = as.authJSON(guestToken, "/api/teams/%s/tracks", team.ID).Get()
+	as.Equal(http.StatusOK, res.Code)
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &tracks))
+	as.Equal(1, tracks.Data.Total)
+	as.Equal(http.StatusForbidden, as.authJSON(guestToken, "/api/teams/%s/members", team.ID).Get().Code)
+	as.Equal(http.StatusForbidden, as.authJSON(guestToken, "/api/teams/%s?include=members", team.ID).Get().Code)
+
+	// Member pickers leave guests out unless asked
+	var members struct {
+		Data struct {
+			Items []teamMemberView `json:"items"`
+		} `json:"data"`
+	}
+	res = as.authJSON(ownerToken, "/api/teams/%s/members", team.ID).Get()
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &members))
+	as.Len(members.Data.Items, 1)
+	res = as.authJSON(ownerToken, "/api/teams/%s/members?include=guests", team.ID).Get()
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &members))
+	as.Len(members.Data.Items, 2)
+
+	// Admins see guests' time as one group
+	var summary struct {
+		Data struct {
+			Rows   []teamSummaryMember `json:"rows"`
+			Guests *struct {
+				EntryCount  int `json:"entry_count"`
+				MemberCount int `json:"member_count"`
+			} `json:"guests"`
+		} `json:"data"`
+	}
+	res = as.authJSON(ownerToken, "/api/teams/%s/summary", team.ID).Get()
+	as.Equal(http.StatusOK, res.Code)
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &summary))
+	as.Len(summary.Data.Rows, 1)
+	as.NotNil(summary.Data.Guests)
+	as.Equal(1, summary.Data.Guests.EntryCount)
+	as.Equal(1, summary.Data.Guests.MemberCount)
+	summary.Data.Guests = nil
+	res = as.authJSON(guestToken, "/api/teams/%s/summary", team.ID).Get()
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &summary))
+	as.Len(summary.Data.Rows, 1)
+	as.Equal(guest, summary.Data.Rows[0].UserID)
+	as.Nil(summary.Data.Guests)
+
+	var roles struct {
+		Data []models.TeamRole `json:"data"`
+	}
+	res = as.authJSON(ownerToken, "/api/teams/%s/roles", team.ID).Get()
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &roles))
+	for _, role := range roles.Data {
+		if role.Name == models.RoleGuest {
+			res = as.authJSON(ownerToken, "/api/teams/%s/roles/%s", team.ID, role.ID).Patch(map[string]any{"permissions": []string{"view_team"}})
+			as.Equal(http.StatusUnprocessableEntity, res.Code)
+		}
+	}
+}
+
+func (as *ActionSuite) Test_ProjectBudgets() {
+	sent := make(captureSender, 4)
+	prev := mailSender
+	mailSender = sent
+	defer func() { mailSender = prev }()
+
+	ownerToken := as.registerToken("budget-owner@example.com")
+	owner := as.userID(ownerToken)
+	memberToken := as.registerToken("budget-member@example.com")
+	member := as.userID(memberToken)
+	outsiderToken := as.registerToken("budget-outsider@example.com")
+	team := as.teamFixture("Budget Team", owner)
+	m := as.inviteFixture(team, member, owner, time.Now())
+	as.NoError(as.DB.RawQuery("UPDATE team_members SET status = 'active' WHERE id = ?", m.ID).Exec())
+	as.NoError(as.DB.Create(&models.MemberRate{
+		ID: uuid.Must(uuid.NewV4()), TeamID: team.ID, UserID: member,
+		HourlyRateCents: nulls.NewInt(3000), Currency: nulls.NewString(models.DefaultTeamCurrency),
+		EffectiveFrom: time.Now().AddDate(0, 0, -1),
+	}))
+	res := as.authJSON(ownerToken, "/api/webhooks/").Post(map[string]any{"url": "https://example.com/hook", "events": []string{"project.budget"}})
+	as.Equal(http.StatusCreated, res.Code)
+
+	var created struct {
+		Data models.Project `json:"data"`
+	}
+	res = as.authJSON(ownerToken, "/api/teams/%s/projects", team.ID).Post(map[string]any{"name": "Site", "budget_hours": 2, "budget_amount_cents": 10000})
+	as.Equal(http.StatusCreated, res.Code)
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &created))
+	as.Equal(2.0, created.Data.BudgetHours.Float64)
+	as.Equal(int64(10000), created.Data.BudgetAmountCents.Int64)
+	res = as.authJSON(ownerToken, "/api/teams/%s/projects/%s", team.ID, created.Data.ID).Patch(map[string]any{"budget_hours": -1})
+	as.Equal(http.StatusUnprocessableEntity, res.Code)
+
+	// Money budgets are confidential like rates
+	var listed struct {
+		Data []models.Project `json:"data"`
+	}
+	res = as.authJSON(memberToken, "/api/teams/%s/projects", team.ID).Get()
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &listed))
+	as.Equal(2.0, listed.Data[0].BudgetHours.Float64)
+	as.False(listed.Data[0].BudgetAmountCents.Valid)
+
+	track := func(start, end time.Time) {
+		res := as.authJSON(memberToken, "/api/tracks").Post(map[string]any{"project": "Site", "team_id": team.ID, "start_at": start})
+		as.Equal(http.StatusCreated, res.Code)
+		var item models.TimeTrac
+		as.NoError(json.Unmarshal(res.Body.Bytes(), &item))
+		res = as.authJSON(memberToken, "/api/tracks/stop").Post(map[string]any{"id": item.ID, "end_at": end})
+		as.Equal(http.StatusOK, res.Code)
+	}
+	next := func() mailer.Message {
+		select {
+		case msg := <-sent:
+			return msg
+		case <-time.After(2 * time.Second):
+			as.Fail("no email sent")
+			return mailer.Message{}
+		}
+	}
+	now := time.Now().Add(-time.Minute)
+
+	// 100 minutes of 2 hours cross 80%; 5000 of 10000 cents cross nothing
+	track(now.Add(-160*time.Minute), now.Add(-60*time.Minute))
+	msg := next()
+	as.Equal("budget-owner@example.com", msg.To)
+	as.Contains(msg.Subject, "Site reached 80% of its hours budget")
+	n, err := as.DB.Where("event = ?", "project.budget").Count(&models.WebhookDelivery{})
+	as.NoError(err)
+	as.Equal(1, n)
+	var d models.WebhookDelivery
+	as.NoError(as.DB.Where("event = ?", "project.budget").First(&d))
+	as.NotEmpty(d.RequestID)
+
+	// 140 minutes are past the budget; 80% is not alerted again
+	track(now.Add(-40*time.Minute), now)
+	msg = next()
+	as.Contains(msg.Subject, "Site used up its hours budget")
+	select {
+	case msg := <-sent:
+		as.Fail("unexpected email", msg.Subject)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	var burndown struct {
+		Data projectBurndown `json:"data"`
+	}
+	res = as.authJSON(ownerToken, "/api/projects/%s/burndown?tz=UTC", created.Data.ID).Get()
+	as.Equal(http.StatusOK, res.Code)
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &burndown))
+	as.InDelta(140.0/60, burndown.Data.UsedHours, 0.01)
+	as.InDelta(7000, *burndown.Data.UsedAmountCents, 5)
+	last := burndown.Data.Days[len(burndown.Data.Days)-1]
+	as.Equal(time.Now().UTC().Format("2006-01-02"), last.Date)
+	as.InDelta(burndown.Data.UsedHours, last.CumulativeHours, 0.001)
+
+	res = as.authJSON(memberToken, "/api/projects/%s/burndown?tz=UTC", created.Data.ID).Get()
+	as.Equal(http.StatusOK, res.Code)
+	as.NotContains(res.Body.String(), `"amount_cents"`)
+	as.NotContains(res.Body.String(), "used_amount_cents")
+	res = as.authJSON(outsiderToken, "/api/projects/%s/burndown", created.Data.ID).Get()
+	as.Equal(http.StatusNotFound, res.Code)
+	res = as.authJSON(ownerToken, "/api/projects/%s/burndown?from=2025-01-01&to=2026-06-01", created.Data.ID).Get()
+	as.Equal(http.StatusUnprocessableEntity, res.Code)
+
+	// Project reports carry the budgets
+	var report struct {
+		Data reports.Document `json:"data"`
+	}
+	res = as.authJSON(ownerToken, "/api/reports/generate").Post(map[string]any{"template": "project", "team_id": team.ID, "timezone": "UTC"})
+	as.Equal(http.StatusOK, res.Code)
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &report))
+	budget := report.Data.Sections[1]
+	as.Equal(reports.SectionBudget, budget.Kind)
+	as.Equal("Site", budget.Table.Rows[0][0])
+	as.Equal("Budget "+models.DefaultTeamCurrency, budget.Table.Columns[6].Title)
+}
+
+func (as *ActionSuite) Test_InviteByEmail_MagicLinkSurfaces() {
+	sent := make(captureSender, 4)
+	prev := mailSender
+	mailSender = sent
+	defer func() { mailSender = prev }()
+	tokenFromMail := func(to string) string {
+		for {
+			select {
+			case msg := <-sent:
+				if msg.To != to {
+					continue
+				}
+				i := strings.Index(msg.Text, "token=")
+				as.True(i >= 0)
+				return strings.Fields(msg.Text[i+len("token="):])[0]
+			case <-time.After(2 * time.Second):
+				as.Fail("no email sent")
+				return ""
+			}
+		}
+	}
+
+	ownerToken := as.registerToken("surface-owner@example.com")
+	team := as.teamFixture("Surface Team", as.userID(ownerToken))
+	res := as.authJSON(ownerToken, "/api/teams/%s/invite", team.ID).Post(map[string]string{"email": "surface@example.com", "role": "member"})
+	as.Equal(http.StatusCreated, res.Code)
+	tokenFromMail("surface@example.com")
+	as.registerToken("surface@example.com")
+
+	// A magic link proves the mailbox and surfaces the invitation
+	as.Equal(http.StatusAccepted, as.JSON("/api/auth/magic-link").Post(map[string]string{"email": "surface@example.com"}).Code)
+	res = as.JSON("/api/auth/magic-link/consume?token=%s", tokenFromMail("surface@example.com")).Get()
+	as.Equal(http.StatusOK, res.Code)
+	var pair tokenPair
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &pair))
+	res = as.authJSON(pair.Token, "/api/pending").Get()
+	var pending struct {
+		Data []pendingInvitation `json:"data"`
+	}
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &pending))
+	as.Len(pending.Data, 1)
+	as.Equal(team.ID, pending.Data[0].TeamID)
+}
+
+func (as *ActionSuite) Test_InviteMember_NoEnumeration() {
+	sent := make(captureSender, 4)
+	prev := mailSender
+	mailSender = sent
+	defer func() { mailSender = prev }()
+
+	ownerToken := as.registerToken("enum-owner@example.com")
+	team := as.teamFixture("Enum Team", as.userID(ownerToken))
+	as.registerToken("enum-known@example.com")
+
+	invite := func(email string) (int, map[string]any) {
+		res := as.authJSON(ownerToken, "/api/teams/%s/invite", team.ID).Post(map[string]string{"email": email, "role": "member"})
+		var body struct {
+			Data    map[string]any `json:"data"`
+			Message string         `json:"message"`
+		}
+		as.NoError(json.Unmarshal(res.Body.Bytes(), &body))
+		delete(body.Data, "email")
+		delete(body.Data, "expires_at")
+		body.Data["message"] = body.Message
+		return res.Code, body.Data
+	}
+	knownCode, known := invite("enum-known@example.com")
+	unknownCode, unknown := invite("enum-unknown@example.com")
+	as.Equal(http.StatusCreated, knownCode)
+	as.Equal(knownCode, unknownCode)
+	as.Equal(known, unknown)
+}
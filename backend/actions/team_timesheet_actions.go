@@ -0,0 +1,357 @@
+/**
+ * Team Timesheet Actions - Weekly Submission and Approval
+ *
+ * Members submit their week of team entries with
+ * POST /api/teams/{id}/timesheets/{week}/submit, where week is the date
+ * of its Monday. Roles with approve_timesheets list pending submissions
+ * and approve or reject them; a rejection carries a comment and the
+ * member can submit again. Nobody reviews their own timesheet.
+ *
+ * An approved week locks the member's entries for that team until the
+ * approval is revoked: editing, deleting, restoring, splitting, merging
+ * or syncing them answers 423 (an error result in a sync), as does
+ * creating or starting an entry in the week. Bulk cleanups skip them
+ * (entryUnlockedSQL).
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-21
+ */
+package actions
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"backend/models"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/nulls"
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gofrs/uuid"
+)
+
+/**
+ * timesheetView is a timesheet as returned by the API, with its week as
+ * a date, its member's email and the net time of the week's entries
+ */
+type timesheetView struct {
+	models.Timesheet
+	Week         string  `db:"-" json:"week"`
+	UserEmail    string  `db:"user_email" json:"user_email"`
+	TotalSeconds float64 `db:"total_seconds" json:"total_seconds"`
+}
+
+/**
+ * ReviewTimesheetRequest is the payload for approving or rejecting a
+ * timesheet
+ */
+type ReviewTimesheetRequest struct {
+	Comment string `json:"comment"` // Required when rejecting
+}
+
+// timesheetViewSQL selects timesheets as timesheetView rows; callers
+// append the WHERE clause.
+const timesheetViewSQL = `
+	SELECT ts.*, u.email AS user_email,
+	       COALESCE((
+	           SELECT SUM(` + trackNetSecondsSQL + `)
+	           FROM timetrac t
+	           WHERE t.team_id = ts.team_id AND t.user_id = ts.user_id AND t.deleted_at IS NULL
+	             AND t.start_at >= ts.starts_at AND t.start_at < ts.ends_at
+	       ), 0) AS total_seconds
+	FROM timesheets ts
+	JOIN users u ON u.id = ts.user_id`
+
+/**
+ * loadTimesheetView reads one timesheet with its email and total
+ */
+func loadTimesheetView(tx *pop.Connection, id uuid.UUID) (timesheetView, error) {
+	var view timesheetView
+	if err := tx.RawQuery(timesheetViewSQL+` WHERE ts.id = ?`, id).First(&view); err != nil {
+		return view, err
+	}
+	view.Week = view.Timesheet.Week()
+	return view, nil
+}
+
+/**
+ * entryLocked reports whether item lies in a week approved for its team;
+ * personal entries are never locked
+ *
+ * @param tx - Request transaction
+ * @param item - Entry, checked by owner, team and start_at
+ * @return bool - Whether the entry must not be changed
+ * @return error - Database error
+ */
+func entryLocked(tx *pop.Connection, item models.TimeTrac) (bool, error) {
+	if !item.TeamID.Valid {
+		return false, nil
+	}
+	return tx.Where("team_id = ? AND user_id = ? AND status = ? AND starts_at <= ? AND ends_at > ?",
+		item.TeamID.UUID, item.UserID, models.TimesheetApproved, item.StartAt.UTC(), item.StartAt.UTC()).
+		Exists(&models.Timesheet{})
+}
+
+// entryUnlockedSQL is the SQL condition of entryLocked for timetrac rows:
+// true for the entries outside approved weeks.
+const entryUnlockedSQL = `NOT EXISTS (
+	SELECT 1 FROM timesheets ts
+	WHERE ts.team_id = timetrac.team_id AND ts.user_id = timetrac.user_id
+	  AND ts.status = 'approved'
+	  AND ts.starts_at <= timetrac.start_at AND ts.ends_at > timetrac.start_at
+)`
+
+/**
+ * errEntryLocked is returned by startTrackEntry for an entry starting in
+ * an approved week
+ */
+var errEntryLocked = errors.New("entry is in an approved timesheet")
+
+/**
+ * renderEntryLocked renders the 423 response for an entry in an approved
+ * week
+ */
+func renderEntryLocked(c buffalo.Context) error {
+	return c.Render(http.StatusLocked, r.JSON(map[string]string{"error": errEntryLocked.Error()}))
+}
+
+/**
+ * GetTeamTimesheets lists a team's timesheets, oldest week first
+ * GET /api/teams/{id}/timesheets[?status=submitted|approved|rejected|draft]
+ *
+ * Lists the submissions waiting for review unless another status is
+ * asked for. Requires approve_timesheets.
+ */
+func GetTeamTimesheets(c buffalo.Context) error {
+	member, status, msg := teamMembership(c, "approve_timesheets")
+	if status != 0 {
+		return renderTeamError(c, status, msg)
+	}
+
+	state := c.Param("status")
+	switch state {
+	case "":
+		state = models.TimesheetSubmitted
+	case models.TimesheetDraft, models.TimesheetSubmitted, models.TimesheetApproved, models.TimesheetRejected:
+	default:
+		return renderTeamError(c, http.StatusBadRequest, "Invalid status")
+	}
+
+	views := []timesheetView{}
+	if err := mustTx(c).RawQuery(timesheetViewSQL+`
+		WHERE ts.team_id = ? AND ts.status = ?
+		ORDER BY ts.week_start, u.email
+	`, member.TeamID, state).All(&views); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to retrieve timesheets",
+			"error":   err.Error(),
+		}))
+	}
+	for i := range views {
+		views[i].Week = views[i].Timesheet.Week()
+	}
+
+	return c.Render(http.StatusOK, r.JSON(map[string]interface{}{
+		"success": true,
+		"data":    views,
+		"message": "Timesheets retrieved successfully",
+	}))
+}
+
+/**
+ * SubmitTimesheet submits the caller's week of team entries for review
+ * POST /api/teams/{id}/timesheets/{week}/submit
+ *
+ * week is the date of a Monday (YYYY-MM-DD); the week runs to the next
+ * Monday in `tz`, else the caller's timezone preference. Weeks that have
+ * not started yet are rejected (422). A week can be submitted when it
+ * never was, or after a rejection or a revoked approval; otherwise 409.
+ * Any active member can submit.
+ */
+func SubmitTimesheet(c buffalo.Context) error {
+	member, status, msg := teamMembership(c, "")
+	if status != 0 {
+		return renderTeamError(c, status, msg)
+	}
+	tx := mustTx(c)
+
+	loc, badTz, err := requestLocation(c, tx, member.UserID)
+	if err != nil {
+		if badTz != "" {
+			return renderBadTimezone(c, badTz)
+		}
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to submit timesheet",
+			"error":   err.Error(),
+		}))
+	}
+	start, end, err := models.ParseTimesheetWeek(c.Param("week"), loc)
+	if err != nil {
+		return renderFieldError(c, "week", err)
+	}
+	now := time.Now()
+	if start.After(now) {
+		return renderFieldError(c, "week", errors.New("week has not started yet"))
+	}
+
+	weekStart := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	var ts models.Timesheet
+	err = tx.Where("team_id = ? AND user_id = ? AND week_start = ?", member.TeamID, member.UserID, weekStart).First(&ts)
+	created := errors.Is(err, sql.ErrNoRows)
+	if created {
+		ts = models.Timesheet{
+			ID:        uuid.Must(uuid.NewV4()),
+			TeamID:    member.TeamID,
+			UserID:    member.UserID,
+			WeekStart: weekStart,
+		}
+		err = nil
+	}
+	if err == nil && (ts.Status == models.TimesheetSubmitted || ts.Status == models.TimesheetApproved) {
+		return renderTeamError(c, http.StatusConflict, "Timesheet is already "+ts.Status)
+	}
+
+	ts.StartsAt, ts.EndsAt = start.UTC(), end.UTC()
+	ts.Status = models.TimesheetSubmitted
+	ts.SubmittedAt = nulls.NewTime(now)
+	ts.Comment = nulls.String{}
+	if err == nil && created {
+		err = tx.Create(&ts)
+	} else if err == nil {
+		ts.UpdatedAt = now
+		err = tx.Update(&ts)
+	}
+	var view timesheetView
+	if err == nil {
+		view, err = loadTimesheetView(tx, ts.ID)
+	}
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to submit timesheet",
+			"error":   err.Error(),
+		}))
+	}
+
+	code := http.StatusOK
+	if created {
+		code = http.StatusCreated
+	}
+	return c.Render(code, r.JSON(map[string]interface{}{
+		"success": true,
+		"data":    view,
+		"message": "Timesheet submitted successfully",
+	}))
+}
+
+/**
+ * ApproveTimesheet approves a submitted timesheet, locking its entries
+ * POST /api/teams/{id}/timesheets/{timesheet_id}/approve
+ *
+ * Requires approve_timesheets; the timesheet must be submitted (409).
+ */
+func ApproveTimesheet(c buffalo.Context) error {
+	return reviewTimesheet(c, models.TimesheetSubmitted, models.TimesheetApproved, "Timesheet approved successfully")
+}
+
+/**
+ * RejectTimesheet sends a submitted timesheet back to its member
+ * POST /api/teams/{id}/timesheets/{timesheet_id}/reject
+ *
+ * Payload: comment (required). Requires approve_timesheets; the
+ * timesheet must be submitted (409).
+ */
+func RejectTimesheet(c buffalo.Context) error {
+	return reviewTimesheet(c, models.TimesheetSubmitted, models.TimesheetRejected, "Timesheet rejected successfully")
+}
+
+/**
+ * RevokeTimesheetApproval returns an approved timesheet to draft,
+ * unlocking its entries
+ * POST /api/teams/{id}/timesheets/{timesheet_id}/revoke
+ *
+ * Requires approve_timesheets; the timesheet must be approved (409).
+ */
+func RevokeTimesheetApproval(c buffalo.Context) error {
+	return reviewTimesheet(c, models.TimesheetApproved, models.TimesheetDraft, "Timesheet approval revoked successfully")
+}
+
+/**
+ * reviewTimesheet moves the timesheet named by timesheet_id from one
+ * status to another, recording the reviewer; a member's own timesheet is
+ * refused (403)
+ */
+func reviewTimesheet(c buffalo.Context, from, to, done string) error {
+	member, status, msg := teamMembership(c, "approve_timesheets")
+	if status != 0 {
+		return renderTeamError(c, status, msg)
+	}
+	id, err := uuid.FromString(c.Param("timesheet_id"))
+	if err != nil {
+		return renderTeamError(c, http.StatusBadRequest, "Invalid timesheet ID")
+	}
+
+	var req ReviewTimesheetRequest
+	if c.Request().ContentLength > 0 {
+		if err := c.Bind(&req); err != nil {
+			return renderTeamError(c, http.StatusBadRequest, "Invalid request body")
+		}
+	}
+	comment := strings.TrimSpace(req.Comment)
+	if to == models.TimesheetRejected && comment == "" {
+		return renderFieldError(c, "comment", errors.New("is required when rejecting"))
+	}
+
+	tx := mustTx(c)
+	var ts models.Timesheet
+	if err := tx.Where("id = ? AND team_id = ?", id, member.TeamID).First(&ts); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return renderTeamError(c, http.StatusNotFound, "Timesheet not found")
+		}
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to update timesheet",
+			"error":   err.Error(),
+		}))
+	}
+	if ts.UserID == member.UserID {
+		return renderTeamError(c, http.StatusForbidden, "You cannot review your own timesheet")
+	}
+	if ts.Status != from {
+		return renderTeamError(c, http.StatusConflict, "Timesheet is not "+from)
+	}
+
+	now := time.Now()
+	ts.Status = to
+	ts.ReviewedBy = nulls.NewUUID(member.UserID)
+	ts.ReviewedAt = nulls.NewTime(now)
+	ts.Comment = nulls.String{}
+	if comment != "" {
+		ts.Comment = nulls.NewString(comment)
+	}
+	ts.UpdatedAt = now
+	err = tx.Update(&ts)
+	var view timesheetView
+	if err == nil {
+		view, err = loadTimesheetView(tx, ts.ID)
+	}
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to update timesheet",
+			"error":   err.Error(),
+		}))
+	}
+
+	return c.Render(http.StatusOK, r.JSON(map[string]interface{}{
+		"success": true,
+		"data":    view,
+		"message": done,
+	}))
+}
@@ -0,0 +1,233 @@
+/**
+ * Team Track Actions - Time Entries Shared with a Team
+ *
+ * Entries are personal unless they are started or recorded with a
+ * team_id (TracksStart, TracksCreate); the owner must be an active member
+ * of that team. GET /api/teams/{id}/tracks lists a team's entries to its
+ * members. Personal entries never appear there, and other members'
+ * locations are shown no more precisely than both the owner's
+ * location_visibility preference and the team's
+ * member_location_visibility setting allow.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-21
+ */
+package actions
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/models"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/nulls"
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gofrs/uuid"
+)
+
+/**
+ * entryTeam checks the team_id given for a new entry: the user must be an
+ * active member, the team must not be archived, the project must be one
+ * of the team's active projects (see checkTeamProject), which guests
+ * must always name, and the team's require_note_on_entries setting
+ * applies
+ *
+ * @param tx - Request transaction
+ * @param uid - Entry owner
+ * @param teamID - Requested team, nil for a personal entry
+ * @param project - Entry project ("" for none)
+ * @param note - Entry note
+ * @return nulls.UUID - Value for TimeTrac.TeamID
+ * @return string - Payload field at fault, "" for database errors
+ * @return error - Validation error, errTeamArchived or database error
+ */
+func entryTeam(tx *pop.Connection, uid uuid.UUID, teamID *uuid.UUID, project, note string) (nulls.UUID, string, error) {
+	if teamID == nil {
+		return nulls.UUID{}, "", nil
+	}
+	var member models.TeamMember
+	if err := tx.Where("team_id = ? AND user_id = ? AND status = ?", *teamID, uid, "active").First(&member); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nulls.UUID{}, "team_id", errors.New("not an active member of this team")
+		}
+		return nulls.UUID{}, "", err
+	}
+	archived, err := teamArchived(tx, *teamID)
+	if err != nil {
+		return nulls.UUID{}, "", err
+	}
+	if archived {
+		return nulls.UUID{}, "", errTeamArchived
+	}
+	if member.IsGuest() && project == "" {
+		return nulls.UUID{}, "project", errors.New("guests must name a project they were added to")
+	}
+	if err := checkTeamProject(tx, *teamID, uid, project); err != nil {
+		if errors.Is(err, errNotTeamProject) {
+			return nulls.UUID{}, "project", err
+		}
+		return nulls.UUID{}, "", err
+	}
+	settings, err := teamSettingsFor(tx, *teamID)
+	if err != nil {
+		return nulls.UUID{}, "", err
+	}
+	if settings.RequireNoteOnEntries && strings.TrimSpace(note) == "" {
+		return nulls.UUID{}, "note", errors.New("this team requires a note on every entry")
+	}
+	return nulls.NewUUID(*teamID), "", nil
+}
+
+/**
+ * teamTrack is one entry of GET /api/teams/{id}/tracks, naming its owner
+ */
+type teamTrack struct {
+	models.TimeTrac
+	UserID    uuid.UUID `json:"user_id"`
+	UserEmail string    `json:"user_email"`
+}
+
+/**
+ * GetTeamTracks lists a team's entries, newest first
+ * GET /api/teams/{id}/tracks
+ *
+ * Query parameters:
+ * - page (default 1), per_page (default 50, up to 200)
+ * - from, to: RFC 3339 bounds on start_at, [from, to)
+ * - member: Only entries of this user ID
+ *
+ * Requires view_team, except that guests get their own entries only.
+ * Trashed entries are left out, as are other members' entries on
+ * restricted projects the caller cannot see.
+ */
+func GetTeamTracks(c buffalo.Context) error {
+	member, status, msg := teamMembership(c, "")
+	if status == 0 && !member.IsGuest() && !member.HasPermission("view_team") {
+		status, msg = http.StatusForbidden, "Insufficient permissions"
+	}
+	if status != 0 {
+		return renderTeamError(c, status, msg)
+	}
+	teamID, userID := member.TeamID, member.UserID
+	tx := mustTx(c)
+
+	var err error
+	badParam := func(msg string) error {
+		return renderTeamError(c, http.StatusBadRequest, msg)
+	}
+	page, perPage := 1, 50
+	if v := c.Param("page"); v != "" {
+		if page, err = strconv.Atoi(v); err != nil || page < 1 {
+			return badParam("Invalid page")
+		}
+	}
+	if v := c.Param("per_page"); v != "" {
+		if perPage, err = strconv.Atoi(v); err != nil || perPage < 1 || perPage > tracksPageMax {
+			return badParam("Invalid per_page")
+		}
+	}
+
+	q := tx.Where("team_id = ? AND deleted_at IS NULL", teamID).
+		Where(entryVisibleSQL("timetrac"), userID, userID, userID, userID)
+	if member.IsGuest() {
+		q = q.Where("user_id = ?", userID)
+	}
+	if v := c.Param("from"); v != "" {
+		from, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return badParam("Invalid from")
+		}
+		q = q.Where("start_at >= ?", from.UTC())
+	}
+	if v := c.Param("to"); v != "" {
+		to, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return badParam("Invalid to")
+		}
+		q = q.Where("start_at < ?", to.UTC())
+	}
+	if v := c.Param("member"); v != "" {
+		memberID, err := uuid.FromString(v)
+		if err != nil {
+			return badParam("Invalid member")
+		}
+		q = q.Where("user_id = ?", memberID)
+	}
+
+	list := []models.TimeTrac{}
+	q = q.Order("start_at DESC, id DESC").Paginate(page, perPage)
+	if err := q.All(&list); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to retrieve team entries",
+			"error":   err.Error(),
+		}))
+	}
+
+	settings, err := teamSettingsFor(tx, teamID)
+	if err == nil {
+		err = attachPauses(tx, list, time.Now())
+	}
+	if err == nil {
+		err = applyCappedLocationPrivacy(tx, userID, list, settings.MemberLocationVisibility)
+	}
+	emails := map[uuid.UUID]string{}
+	if err == nil {
+		emails, err = userEmails(tx, list)
+	}
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]interface{}{
+			"success": false,
+			"message": "Failed to retrieve team entries",
+			"error":   err.Error(),
+		}))
+	}
+	omitClient(list)
+
+	items := make([]teamTrack, len(list))
+	for i, item := range list {
+		items[i] = teamTrack{TimeTrac: item, UserID: item.UserID, UserEmail: emails[item.UserID]}
+	}
+
+	return c.Render(http.StatusOK, r.JSON(map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"items":    items,
+			"page":     page,
+			"per_page": perPage,
+			"total":    q.Paginator.TotalEntriesSize,
+		},
+		"message": "Team entries retrieved successfully",
+	}))
+}
+
+/**
+ * userEmails returns the addresses of the owners of items
+ */
+func userEmails(tx *pop.Connection, items []models.TimeTrac) (map[uuid.UUID]string, error) {
+	emails := map[uuid.UUID]string{}
+	ids := []interface{}{}
+	for _, it := range items {
+		if _, seen := emails[it.UserID]; !seen {
+			emails[it.UserID] = ""
+			ids = append(ids, it.UserID)
+		}
+	}
+	if len(ids) == 0 {
+		return emails, nil
+	}
+	var users []models.User
+	if err := tx.Select("id", "email").Where("id in (?)", ids...).All(&users); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	for _, u := range users {
+		emails[u.ID] = u.Email
+	}
+	return emails, nil
+}
@@ -3,6 +3,7 @@
  *
  * This package handles all time tracking related API endpoints including:
  * - Starting time entries with location and photo data
+ * - Creating manual entries with overlap detection
  * - Stopping time entries
  * - Updating existing entries
  * - Deleting entries
@@ -17,15 +18,22 @@
 package actions
 
 import (
+	"database/sql"
+	"encoding/base64"
+	"errors"
 	"net/http"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"backend/models"
+	"backend/validators"
 
 	"github.com/gobuffalo/buffalo"
 	"github.com/gobuffalo/nulls"
 	"github.com/gobuffalo/pop/v6"
+	"github.com/gobuffalo/validate/v3"
 	"github.com/gofrs/uuid"
 	"github.com/lib/pq"
 )
@@ -62,151 +70,1557 @@ func currentUserID(c buffalo.Context) (uuid.UUID, bool) {
 }
 
 /**
- * TracksIndex retrieves all time tracking entries for the authenticated user
+ * overlapConflict describes an existing entry whose time range collides
+ * with a requested one. It is returned to the client on 409 responses.
+ */
+type overlapConflict struct {
+	ID      uuid.UUID  `db:"id"       json:"id"`
+	StartAt time.Time  `db:"start_at" json:"start_at"`
+	EndAt   nulls.Time `db:"end_at"   json:"end_at"`
+}
+
+/**
+ * findOverlaps returns the user's entries whose range intersects start..end
+ *
+ * Ranges are treated as half-open [start, end) so that back-to-back entries
+ * do not conflict. A NULL end (running entry or open request) extends to
+ * infinity. The check is a single indexed query on (user_id, start_at, end_at).
+ *
+ * @param tx - Database transaction
+ * @param uid - Owner of the entries
+ * @param start - Requested range start
+ * @param end - Requested range end (invalid = open-ended)
+ * @param excludeID - Entry to ignore, e.g. the one being edited (uuid.Nil for none)
+ * @return []overlapConflict - Conflicting entries ordered by start time
+ */
+func findOverlaps(tx *pop.Connection, uid uuid.UUID, start time.Time, end nulls.Time, excludeID uuid.UUID) ([]overlapConflict, error) {
+	conflicts := []overlapConflict{}
+	err := tx.RawQuery(`
+		SELECT id, start_at, end_at FROM timetrac
+		WHERE user_id = ? AND id <> ? AND deleted_at IS NULL
+		  AND start_at < COALESCE(?::timestamp, 'infinity'::timestamp)
+		  AND COALESCE(end_at, 'infinity'::timestamp) > ?
+		ORDER BY start_at
+	`, uid, excludeID, end, start).All(&conflicts)
+	return conflicts, err
+}
+
+/**
+ * allowOverlap reports whether the caller opted out of overlap detection,
+ * either through the JSON payload or an `allow_overlap=true` query parameter.
+ */
+func allowOverlap(c buffalo.Context, fromPayload bool) bool {
+	return fromPayload || c.Param("allow_overlap") == "true"
+}
+
+/**
+ * renderOverlapConflict renders the 409 response listing conflicting entries
+ */
+func renderOverlapConflict(c buffalo.Context, conflicts []overlapConflict) error {
+	return c.Render(http.StatusConflict, r.JSON(map[string]any{
+		"error":     "entry overlaps existing entries",
+		"conflicts": conflicts,
+	}))
+}
+
+/**
+ * recordRevision stores the audited fields of an entry before it changes.
+ * It must be called with the request transaction so the revision and the
+ * update are committed (or rolled back) together.
+ *
+ * @param tx - Database transaction
+ * @param prev - The entry as loaded, before any modification
+ * @param changedBy - User making the change
+ */
+func recordRevision(tx *pop.Connection, prev models.TimeTrac, changedBy uuid.UUID) error {
+	rev := models.NewTimeTracRevision(prev, changedBy)
+	return tx.Create(&rev)
+}
+
+/**
+ * renderFieldError renders a 422 response naming the invalid field
+ *
+ * Response: {"error": "validation failed", "fields": {"<field>": "<message>"}}
+ */
+func renderFieldError(c buffalo.Context, field string, err error) error {
+	return c.Render(http.StatusUnprocessableEntity, r.JSON(map[string]any{
+		"error":  "validation failed",
+		"fields": map[string]string{field: err.Error()},
+	}))
+}
+
+/**
+ * renderValidationErrors renders a 422 response listing every rule each
+ * field violates
+ *
+ * Response: {"error": "validation failed", "errors": {"<field>": ["<message>", ...]}}
+ */
+func renderValidationErrors(c buffalo.Context, errs map[string][]string) error {
+	return c.Render(http.StatusUnprocessableEntity, r.JSON(map[string]any{
+		"error":  "validation failed",
+		"errors": errs,
+	}))
+}
+
+/**
+ * externalRefValue stores a normalized issue reference, "" as NULL
+ */
+func externalRefValue(ref string) nulls.String {
+	if ref == "" {
+		return nulls.String{}
+	}
+	return nulls.NewString(ref)
+}
+
+/**
+ * invalidTrackError carries the model validation errors of an entry
+ * that could not be saved
+ */
+type invalidTrackError struct {
+	verrs *validate.Errors
+}
+
+/**
+ * Error lists the failures as "<field> <message>", sorted by field, so
+ * the text is usable as a sync operation error
+ */
+func (e invalidTrackError) Error() string {
+	fields := e.fields()
+	msgs := make([]string, 0, len(fields))
+	for field, msg := range fields {
+		msgs = append(msgs, field+" "+msg)
+	}
+	sort.Strings(msgs)
+	return strings.Join(msgs, "; ")
+}
+
+/**
+ * fields returns the first message per field, keyed by JSON field name
+ */
+func (e invalidTrackError) fields() map[string]string {
+	fields := map[string]string{}
+	for field, msgs := range e.verrs.Errors {
+		if len(msgs) > 0 {
+			fields[field] = msgs[0]
+		}
+	}
+	return fields
+}
+
+/**
+ * createTrack inserts an entry after running models.TimeTrac.Validate
+ *
+ * @return error - invalidTrackError if validation fails, or a database error
+ */
+func createTrack(tx *pop.Connection, item *models.TimeTrac) error {
+	verrs, err := tx.ValidateAndCreate(item)
+	if err != nil {
+		return err
+	}
+	if verrs.HasAny() {
+		return invalidTrackError{verrs}
+	}
+	return nil
+}
+
+/**
+ * updateTrack saves an entry after running models.TimeTrac.Validate
+ *
+ * @return error - invalidTrackError if validation fails, or a database error
+ */
+func updateTrack(tx *pop.Connection, item *models.TimeTrac) error {
+	verrs, err := tx.ValidateAndUpdate(item)
+	if err != nil {
+		return err
+	}
+	if verrs.HasAny() {
+		return invalidTrackError{verrs}
+	}
+	return nil
+}
+
+/**
+ * renderTrackSaveError renders the result of a failed createTrack or
+ * updateTrack: 422 with field errors for validation failures, otherwise
+ * 500 with the given message
+ */
+func renderTrackSaveError(c buffalo.Context, err error, msg string) error {
+	var invalid invalidTrackError
+	if errors.As(err, &invalid) {
+		return c.Render(http.StatusUnprocessableEntity, r.JSON(map[string]any{
+			"error":  "validation failed",
+			"fields": invalid.fields(),
+		}))
+	}
+	return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": msg}))
+}
+
+/**
+ * shouldDiscard reports whether stopping item at end produces an
+ * accidental entry: shorter than threshold seconds, without note or photo
+ *
+ * @param item - Entry being stopped
+ * @param end - Stop time
+ * @param threshold - discard_under_seconds preference, 0 disables
+ */
+func shouldDiscard(item models.TimeTrac, end time.Time, threshold int) bool {
+	return threshold > 0 &&
+		end.Sub(item.StartAt) < time.Duration(threshold)*time.Second &&
+		!item.HasAttachments()
+}
+
+/**
+ * stopTrack ends a running entry at the given instant and closes its open
+ * pause, if any.
+ *
+ * @param tx - Database transaction
+ * @param item - Running entry, updated in place
+ * @param at - End timestamp to set
+ */
+func stopTrack(tx *pop.Connection, item *models.TimeTrac, at time.Time) error {
+	item.EndAt = nulls.NewTime(at)
+	item.UpdatedAt = at
+	if err := updateTrack(tx, item); err != nil {
+		return err
+	}
+	return closeOpenPause(tx, item.ID, at)
+}
+
+/**
+ * stopRunning stops every running entry of the user at the given instant
+ *
+ * Normally at most one entry is running, but older data may contain more;
+ * all of them are closed. The most recently started one is returned so
+ * callers can report what was stopped.
+ *
+ * @param tx - Database transaction
+ * @param uid - Owner of the entries
+ * @param at - End timestamp to set
+ * @return *models.TimeTrac - Most recent stopped entry, nil if none was running
+ */
+func stopRunning(tx *pop.Connection, uid uuid.UUID, at time.Time) (*models.TimeTrac, error) {
+	var running []models.TimeTrac
+	if err := tx.Where("user_id = ? AND end_at IS NULL AND deleted_at IS NULL", uid).Order("start_at DESC").All(&running); err != nil {
+		return nil, err
+	}
+	for i := range running {
+		if err := stopTrack(tx, &running[i], at); err != nil {
+			return nil, err
+		}
+	}
+	if len(running) == 0 {
+		return nil, nil
+	}
+	return &running[0], nil
+}
+
+/**
+ * tracksPageMax is the largest page size accepted by TracksIndex
+ */
+const tracksPageMax = 200
+
+/**
+ * encodeTrackCursor builds the opaque cursor pointing after the given
+ * entry in (start_at DESC, id DESC) order
+ */
+func encodeTrackCursor(t models.TimeTrac) string {
+	raw := t.StartAt.UTC().Format(time.RFC3339Nano) + "|" + t.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+/**
+ * decodeTrackCursor parses a cursor produced by encodeTrackCursor
+ *
+ * @return time.Time - start_at of the last entry on the previous page
+ * @return uuid.UUID - ID of that entry
+ */
+func decodeTrackCursor(cursor string) (time.Time, uuid.UUID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, uuid.Nil, err
+	}
+	at, id, found := strings.Cut(string(raw), "|")
+	if !found {
+		return time.Time{}, uuid.Nil, errors.New("malformed cursor")
+	}
+	start, err := time.Parse(time.RFC3339Nano, at)
+	if err != nil {
+		return time.Time{}, uuid.Nil, err
+	}
+	uid, err := uuid.FromString(id)
+	return start, uid, err
+}
+
+/**
+ * TracksIndex retrieves time tracking entries for the authenticated user
+ *
+ * GET /api/tracks
+ *
+ * Entries are ordered by start time (most recent first), ties broken by ID.
+ *
+ * Cursor mode (preferred): ?limit=<1-200>&cursor=<next_cursor>
+ * - Responds with {"items": [...], "next_cursor": "..."}; next_cursor is
+ *   null on the last page
+ * - Stable while entries are added or removed between requests
+ *
+ * Page mode (compatibility): ?page=<n>&per_page=<1-200>
+ * - Responds with a bare array; per_page defaults to 50
+ *
+ * Without parameters the 200 most recent entries are returned as a bare
+ * array, as before pagination existed.
+ *
+ * Filter (all modes): ?external_ref=<ref> returns only entries for that
+ * issue reference.
+ *
+ * @param c - Buffalo context with authenticated user
+ * @return JSON entries or error response
+ */
+func TracksIndex(c buffalo.Context) error {
+	tx := mustTx(c)
+	uid, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+
+	params := c.Params()
+	if params.Get("cursor") != "" || params.Get("limit") != "" {
+		return tracksIndexCursor(c, tx, uid)
+	}
+
+	q := tx.Where("user_id = ? AND deleted_at IS NULL", uid).Order("start_at DESC, id DESC")
+	if ref := params.Get("external_ref"); ref != "" {
+		q = q.Where("external_ref = ?", ref)
+	}
+	if params.Get("page") != "" {
+		page, err := strconv.Atoi(params.Get("page"))
+		if err != nil || page < 1 {
+			return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "bad page"}))
+		}
+		perPage := 50
+		if v := params.Get("per_page"); v != "" {
+			if perPage, err = strconv.Atoi(v); err != nil || perPage < 1 || perPage > tracksPageMax {
+				return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "bad per_page"}))
+			}
+		}
+		q = q.Paginate(page, perPage)
+	} else {
+		q = q.Limit(tracksPageMax)
+	}
+
+	list := []models.TimeTrac{}
+	if err := q.All(&list); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+	if err := attachPauses(tx, list, time.Now()); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+	omitClient(list)
+	return c.Render(http.StatusOK, r.JSON(list))
+}
+
+/**
+ * omitClient drops the client metadata from listed entries to keep list
+ * payloads small; it is only returned in single-entry responses
+ */
+func omitClient(list []models.TimeTrac) {
+	for i := range list {
+		list[i].Client = nil
+	}
+}
+
+/**
+ * tracksIndexCursor serves TracksIndex in cursor mode using (start_at, id)
+ * keyset pagination
+ */
+func tracksIndexCursor(c buffalo.Context, tx *pop.Connection, uid uuid.UUID) error {
+	limit := 50
+	if v := c.Param("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > tracksPageMax {
+			return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "bad limit"}))
+		}
+		limit = n
+	}
+
+	q := tx.Where("user_id = ? AND deleted_at IS NULL", uid)
+	if ref := c.Param("external_ref"); ref != "" {
+		q = q.Where("external_ref = ?", ref)
+	}
+	if cursor := c.Param("cursor"); cursor != "" {
+		at, id, err := decodeTrackCursor(cursor)
+		if err != nil {
+			return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "bad cursor"}))
+		}
+		q = q.Where("(start_at, id) < (?, ?)", at, id)
+	}
+
+	// Fetch one extra row to learn whether another page exists
+	list := []models.TimeTrac{}
+	if err := q.Order("start_at DESC, id DESC").Limit(limit + 1).All(&list); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+	var next *string
+	if len(list) > limit {
+		list = list[:limit]
+		cur := encodeTrackCursor(list[limit-1])
+		next = &cur
+	}
+	if err := attachPauses(tx, list, time.Now()); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+	omitClient(list)
+	return c.Render(http.StatusOK, r.JSON(map[string]any{
+		"items":       list,
+		"next_cursor": next,
+	}))
+}
+
+/**
+ * TracksSummary returns tracked time totals for a period, grouped by project
+ * and by calendar day
+ *
+ * GET /api/tracks/summary?from=<RFC3339>&to=<RFC3339>&tz=<IANA zone>
+ *
+ * Entries are included by start_at within [from, to). The default period
+ * is the last 7 calendar days including today. Day boundaries follow `tz`,
+ * falling back to the user's timezone preference and then UTC. Durations
+ * exclude pause time and running entries count up to now.
+ *
+ * Response:
+ * - from, to, timezone: The effective period and zone
+ * - total_seconds: Sum over all projects
+ * - by_project: [{ project, entries, seconds }] ordered by seconds desc
+ * - by_day: [{ day, seconds }] with day as YYYY-MM-DD in the zone
+ * - goal: Current week's { goal_minutes, progress_percent, remaining_minutes },
+ *   independent of the requested period (null fields when no goal is set)
+ *
+ * @param c - Buffalo context with authenticated user
+ * @return JSON summary or error response
+ */
+func TracksSummary(c buffalo.Context) error {
+	tx := mustTx(c)
+	uid, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+
+	loc, badTz, err := requestLocation(c, tx, uid)
+	if err != nil {
+		if badTz != "" {
+			return renderBadTimezone(c, badTz)
+		}
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+
+	to := time.Now().In(loc)
+	from, to, msg := requestRange(c, startOfDay(to, loc).AddDate(0, 0, -6), to)
+	if msg != "" {
+		return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": msg}))
+	}
+
+	type projectTotal struct {
+		Project string  `db:"project" json:"project"`
+		Entries int     `db:"entries" json:"entries"`
+		Seconds float64 `db:"seconds" json:"seconds"`
+	}
+	byProject := []projectTotal{}
+	if err := tx.RawQuery(`
+		SELECT COALESCE(t.project, '') AS project,
+		       COUNT(*) AS entries,
+		       COALESCE(SUM(`+trackNetSecondsSQL+`), 0) AS seconds
+		FROM timetrac t
+		WHERE t.user_id = ? AND t.deleted_at IS NULL AND t.start_at >= ? AND t.start_at < ?
+		GROUP BY 1
+		ORDER BY seconds DESC
+	`, uid, from.UTC(), to.UTC()).All(&byProject); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+
+	// Stored timestamps are UTC wall-clock; shift them into the zone before truncating
+	type dayTotal struct {
+		Day     string  `db:"day" json:"day"`
+		Seconds float64 `db:"seconds" json:"seconds"`
+	}
+	byDay := []dayTotal{}
+	if err := tx.RawQuery(`
+		SELECT to_char(date_trunc('day', (t.start_at AT TIME ZONE 'UTC') AT TIME ZONE ?), 'YYYY-MM-DD') AS day,
+		       COALESCE(SUM(`+trackNetSecondsSQL+`), 0) AS seconds
+		FROM timetrac t
+		WHERE t.user_id = ? AND t.deleted_at IS NULL AND t.start_at >= ? AND t.start_at < ?
+		GROUP BY 1
+		ORDER BY 1
+	`, loc.String(), uid, from.UTC(), to.UTC()).All(&byDay); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+
+	var total float64
+	for _, pt := range byProject {
+		total += pt.Seconds
+	}
+
+	goal, err := currentWeekGoal(tx, uid, loc, time.Now())
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+
+	return c.Render(http.StatusOK, r.JSON(map[string]any{
+		"from":          from,
+		"to":            to,
+		"timezone":      loc.String(),
+		"total_seconds": total,
+		"by_project":    byProject,
+		"by_day":        byDay,
+		"goal":          goal,
+	}))
+}
+
+/**
+ * TracksCreate adds a manual (already finished) time tracking entry
+ *
+ * POST /api/tracks
+ *
+ * This endpoint records an entry after the fact, e.g. work that was not
+ * timed live. Unlike TracksStart it does not touch any running entry.
+ *
+ * Payload:
+ * - start_at: Entry start (required)
+ * - end_at: Entry end (required, must be after start_at)
+ * - project, tags, note, color, external_ref, client, team_id, billable:
+ *   Same as TracksStart
+ * - allow_overlap: Skip overlap detection (optional, also accepted as query param)
+ *
+ * Responses:
+ * - 201 with the created entry
+ * - 409 with the conflicting entries if the range overlaps existing ones
+ *
+ * Retries carrying the same Idempotency-Key header replay the first
+ * response (see idempotent).
+ *
+ * @param c - Buffalo context with authenticated user
+ * @return JSON TimeTrac entry or error response
+ */
+func TracksCreate(c buffalo.Context) error {
+	type payload struct {
+		Project      string              `json:"project"`
+		Tags         []string            `json:"tags"`
+		Note         string              `json:"note"`
+		Color        string              `json:"color"`
+		StartAt      *time.Time          `json:"start_at"`
+		EndAt        *time.Time          `json:"end_at"`
+		ExternalRef  string              `json:"external_ref"`
+		AllowOverlap bool                `json:"allow_overlap"`
+		Client       *models.TrackClient `json:"client"`
+		TeamID       *uuid.UUID          `json:"team_id"`
+		Billable     bool                `json:"billable"`
+	}
+	var p payload
+	if err := c.Bind(&p); err != nil {
+		return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "bad payload"}))
+	}
+	if p.StartAt == nil || p.EndAt == nil || !p.EndAt.After(*p.StartAt) {
+		return c.Render(http.StatusUnprocessableEntity, r.JSON(map[string]string{"error": "start_at and end_at required, end_at must be after start_at"}))
+	}
+
+	p.Project = strings.TrimSpace(p.Project)
+	p.Color = strings.TrimSpace(p.Color)
+	if p.Color == "" {
+		p.Color = defaultEntryColor(c)
+	}
+	color, err := validators.NormalizeColor(p.Color)
+	if err != nil {
+		return renderFieldError(c, "color", err)
+	}
+	p.Color = color
+	client, err := p.Client.Normalize()
+	if err != nil {
+		return renderFieldError(c, "client", err)
+	}
+	ref, err := validators.NormalizeExternalRef(p.ExternalRef)
+	if err != nil {
+		return renderFieldError(c, "external_ref", err)
+	}
+
+	tx := mustTx(c)
+	uid, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+	teamID, field, err := entryTeam(tx, uid, p.TeamID, p.Project, p.Note)
+	if field != "" {
+		return renderFieldError(c, field, err)
+	}
+	if errors.Is(err, errTeamArchived) {
+		return c.Render(http.StatusConflict, r.JSON(map[string]string{"error": "team is archived", "team_id": p.TeamID.String()}))
+	}
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+
+	if !allowOverlap(c, p.AllowOverlap) {
+		conflicts, err := findOverlaps(tx, uid, *p.StartAt, nulls.NewTime(*p.EndAt), uuid.Nil)
+		if err != nil {
+			return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+		}
+		if len(conflicts) > 0 {
+			return renderOverlapConflict(c, conflicts)
+		}
+	}
+
+	item := models.TimeTrac{
+		UserID:      uid,
+		Project:     p.Project,
+		Tags:        pq.StringArray(p.Tags),
+		Note:        p.Note,
+		Color:       p.Color,
+		StartAt:     *p.StartAt,
+		EndAt:       nulls.NewTime(*p.EndAt),
+		ExternalRef: externalRefValue(ref),
+		Client:      client,
+		TeamID:      teamID,
+		Billable:    p.Billable,
+	}
+	if locked, err := entryLocked(tx, item); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	} else if locked {
+		return renderEntryLocked(c)
+	}
+	if err := createTrack(tx, &item); err != nil {
+		return renderTrackSaveError(c, err, "cannot create")
+	}
+	return c.Render(http.StatusCreated, r.JSON(item))
+}
+
+/**
+ * TracksStart creates a new time tracking entry and starts the timer
+ *
+ * POST /api/tracks/start
+ *
+ * This endpoint creates a new time tracking entry with optional location data,
+ * photo attachments, and metadata. It automatically stops any currently
+ * running entry for the user before starting a new one.
+ *
+ * Payload:
+ * - project: Project name (optional)
+ * - tags: Array of tag strings (optional)
+ * - note: Text note (optional)
+ * - color: Hex color #RGB or #RRGGBB (defaults to the default_color
+ *   preference, stored as lowercase #rrggbb; anything else is rejected
+ *   with 422)
+ * - location_lat: GPS latitude (optional)
+ * - location_lng: GPS longitude (optional)
+ * - location_addr: Human-readable address (optional; resolved from the
+ *   coordinates in the background when omitted)
+ * - photo_data: Base64 encoded image data (optional, deprecated in favor
+ *   of POST /api/tracks/{id}/photo; responses carry a Deprecation header)
+ * - external_ref: Issue reference such as ACME-123 or org/repo#45
+ *   (optional; up to 100 letters, digits and . _ - / # :)
+ * - client: {platform, app_version, device_name} of the creating device
+ *   (optional; other keys are dropped, at most 1 KB). Returned only to
+ *   the owner and not included in lists.
+ * - team_id: Team to share the entry with (optional; the caller must be
+ *   an active member, otherwise 422)
+ * - billable: Time can be billed to a client (optional, default false)
+ *
+ * Response:
+ * - The new TimeTrac entry, plus `stopped_entry` holding the entry that was
+ *   auto-stopped (null if nothing was running)
+ *
+ * Starting on an archived project returns 409 unless `?unarchive=true`
+ * is given, which restores the project first.
+ *
+ * If the running entry cannot be stopped the request fails, so a user never
+ * ends up with two concurrent running entries. Retries carrying the same
+ * Idempotency-Key header replay the first response instead of starting
+ * another entry.
+ *
+ * @param c - Buffalo context with authenticated user
+ * @return JSON TimeTrac entry or error response
+ */
+func TracksStart(c buffalo.Context) error {
+	type payload struct {
+		Project      string              `json:"project"`
+		Tags         []string            `json:"tags"`
+		Note         string              `json:"note"`
+		Color        string              `json:"color"`
+		LocationLat  *float64            `json:"location_lat"`
+		LocationLng  *float64            `json:"location_lng"`
+		LocationAddr *string             `json:"location_addr"`
+		PhotoData    *string             `json:"photo_data"`
+		ExternalRef  string              `json:"external_ref"`
+		Client       *models.TrackClient `json:"client"`
+		TeamID       *uuid.UUID          `json:"team_id"`
+		Billable     bool                `json:"billable"`
+	}
+	var p payload
+	if err := c.Bind(&p); err != nil {
+		return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "bad payload"}))
+	}
+
+	// Sanitize and validate input data
+	p.Project = strings.TrimSpace(p.Project)
+	p.Color = strings.TrimSpace(p.Color)
+	if p.Color == "" {
+		p.Color = defaultEntryColor(c)
+	}
+	color, err := validators.NormalizeColor(p.Color)
+	if err != nil {
+		return renderFieldError(c, "color", err)
+	}
+	p.Color = color
+	client, err := p.Client.Normalize()
+	if err != nil {
+		return renderFieldError(c, "client", err)
+	}
+	ref, err := validators.NormalizeExternalRef(p.ExternalRef)
+	if err != nil {
+		return renderFieldError(c, "external_ref", err)
+	}
+
+	tx := mustTx(c)
+	uid, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+	teamID, field, err := entryTeam(tx, uid, p.TeamID, p.Project, p.Note)
+	if field != "" {
+		return renderFieldError(c, field, err)
+	}
+	if errors.Is(err, errTeamArchived) {
+		return c.Render(http.StatusConflict, r.JSON(map[string]string{"error": "team is archived", "team_id": p.TeamID.String()}))
+	}
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+
+	// Create new time tracking entry
+	item := models.TimeTrac{
+		UserID:      uid,
+		Project:     p.Project,
+		Tags:        pq.StringArray(p.Tags),
+		Note:        p.Note,
+		Color:       p.Color,
+		EndAt:       nulls.Time{}, // NULL indicates running entry
+		ExternalRef: externalRefValue(ref),
+		Client:      client,
+		TeamID:      teamID,
+		Billable:    p.Billable,
+	}
+
+	// Add optional location data if provided
+	if p.LocationLat != nil {
+		item.LocationLat = nulls.NewFloat64(*p.LocationLat)
+	}
+	if p.LocationLng != nil {
+		item.LocationLng = nulls.NewFloat64(*p.LocationLng)
+	}
+	if p.LocationAddr != nil {
+		item.LocationAddr = nulls.NewString(strings.TrimSpace(*p.LocationAddr))
+	}
+
+	// Add optional photo data if provided (deprecated, see TracksUploadPhoto)
+	if p.PhotoData != nil {
+		item.PhotoData = nulls.NewString(*p.PhotoData)
+		markPhotoDataDeprecated(c)
+	}
+
+	return startEntry(c, tx, item)
+}
+
+/**
+ * startEntry starts a prepared entry now via startTrackEntry and renders
+ * the TracksStart response. It is the shared HTTP start path of
+ * TracksStart and template starts; an archived project is refused (409)
+ * unless `?unarchive=true`, a start in an approved week too (423).
+ *
+ * @param c - Buffalo context
+ * @param tx - Database transaction
+ * @param item - Entry to create; UserID must be set, StartAt is set here
+ * @return Rendered response
+ */
+func startEntry(c buffalo.Context, tx *pop.Connection, item models.TimeTrac) error {
+	stopped, err := startTrackEntry(tx, &item, time.Now(), c.Param("unarchive") == "true")
+	if errors.Is(err, errProjectArchived) {
+		return c.Render(http.StatusConflict, r.JSON(map[string]string{"error": "project is archived", "project": item.Project}))
+	}
+	if errors.Is(err, errEntryLocked) {
+		return renderEntryLocked(c)
+	}
+	if err != nil {
+		return renderTrackSaveError(c, err, "cannot create")
+	}
+
+	if stopped != nil {
+		queueTrackEvent(c, eventTrackStopped, *stopped)
+	}
+	queueTrackEvent(c, eventTrackStarted, item)
+	// Resolve the address in the background when the client could not
+	queueGeocode(c, item)
+
+	// Keep the entry fields at the top level for existing clients
+	return c.Render(http.StatusCreated, r.JSON(struct {
+		models.TimeTrac
+		StoppedEntry *models.TimeTrac `json:"stopped_entry"`
+	}{item, stopped}))
+}
+
+/**
+ * errProjectArchived is returned by startTrackEntry for an archived
+ * project when unarchiving was not requested
+ */
+var errProjectArchived = errors.New("project is archived")
+
+/**
+ * startTrackEntry starts a prepared entry at now. It holds the start
+ * logic shared by the HTTP handlers and the Slack command, and does not
+ * depend on a request:
+ * - an archived project returns errProjectArchived, or is restored when
+ *   unarchive is set
+ * - a team entry starting in an approved week returns errEntryLocked
+ * - the user's running entry is stopped first
+ *
+ * @param tx - Database transaction
+ * @param item - Entry to create; UserID must be set, StartAt is overwritten
+ * @param now - Start time
+ * @param unarchive - Restore an archived project instead of refusing
+ * @return *models.TimeTrac - The entry that was auto-stopped, or nil
+ * @return error - errProjectArchived, errEntryLocked, invalidTrackError
+ *   or a database error
+ */
+func startTrackEntry(tx *pop.Connection, item *models.TimeTrac, now time.Time, unarchive bool) (*models.TimeTrac, error) {
+	uid := item.UserID
+
+	item.StartAt = now
+	if locked, err := entryLocked(tx, *item); err != nil {
+		return nil, err
+	} else if locked {
+		return nil, errEntryLocked
+	}
+
+	// Team projects are archived per team and checked by entryTeam
+	if item.Project != "" && !item.TeamID.Valid {
+		archived, err := projectArchived(tx, uid, item.Project)
+		if err != nil {
+			return nil, err
+		}
+		if archived {
+			if !unarchive {
+				return nil, errProjectArchived
+			}
+			if err := unarchiveProject(tx, uid, item.Project); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Safety measure: stop any currently running entry for this user
+	stopped, err := stopRunning(tx, uid, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := createTrack(tx, item); err != nil {
+		return nil, err
+	}
+
+	if stopped != nil {
+		stoppedItems := []models.TimeTrac{*stopped}
+		if err := attachPauses(tx, stoppedItems, now); err != nil {
+			return nil, err
+		}
+		stopped = &stoppedItems[0]
+	}
+	return stopped, nil
+}
+
+/**
+ * findRunningTrack loads the user's most recently started running entry
+ */
+func findRunningTrack(tx *pop.Connection, uid uuid.UUID) (models.TimeTrac, error) {
+	var item models.TimeTrac
+	err := tx.Where("user_id = ? AND end_at IS NULL AND deleted_at IS NULL", uid).Order("start_at DESC").First(&item)
+	return item, err
+}
+
+/**
+ * stopTrackEntry ends a loaded entry at end. It holds the stop logic
+ * shared by the HTTP handlers and the Slack command:
+ * - an accidental entry (see shouldDiscard) is moved to trash instead,
+ *   as TracksDelete does, so it can still be restored
+ * - otherwise a revision is recorded, end_at set and an open pause closed
+ *
+ * @param tx - Database transaction
+ * @param item - Entry to stop, updated in place (with computed durations)
+ * @param end - Stop time, already validated against start_at
+ * @param now - Current time, used for updated_at
+ * @return bool - The entry was discarded
+ * @return error - invalidTrackError or a database error
+ */
+func stopTrackEntry(tx *pop.Connection, item *models.TimeTrac, end, now time.Time) (bool, error) {
+	// Accidental start/stop: drop the entry instead of keeping a few seconds
+	prefs, err := userPreferences(tx, item.UserID)
+	if err != nil {
+		return false, err
+	}
+	if shouldDiscard(*item, end, prefs.DiscardUnderSeconds) {
+		if err := tx.RawQuery(`
+			UPDATE timetrac SET deleted_at = ?, end_at = ?, updated_at = ?
+			WHERE id = ?
+			RETURNING *
+		`, now, end, now, item.ID).First(item); err != nil {
+			return false, err
+		}
+		if err := closeOpenPause(tx, item.ID, end); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	if err := recordRevision(tx, *item, item.UserID); err != nil {
+		return false, err
+	}
+	item.EndAt = nulls.NewTime(end)
+	item.UpdatedAt = now
+	if err := updateTrack(tx, item); err != nil {
+		return false, err
+	}
+
+	// Stopping while paused implicitly ends the open pause
+	if err := closeOpenPause(tx, item.ID, end); err != nil {
+		return false, err
+	}
+	items := []models.TimeTrac{*item}
+	if err := attachPauses(tx, items, now); err != nil {
+		return false, err
+	}
+	*item = items[0]
+	return false, nil
+}
+
+/**
+ * TracksStop stops a running time tracking entry
+ *
+ * POST /api/tracks/stop
+ *
+ * This endpoint stops a time tracking entry by setting the end_at timestamp.
+ * It can stop a specific entry by ID or the most recent running entry.
+ *
+ * Payload (optional):
+ * - id: Specific entry ID to stop (if not provided, stops most recent running entry)
+ * - end_at: Retroactive end time (must be after start_at and not in the future)
+ * - force: Overwrite end_at of an entry that is already stopped. This is
+ *   checked like an edit in TracksUpdate: 423 in an approved week, 409
+ *   when the new end overlaps other entries unless allow_overlap is set
+ * - allow_overlap: Accept overlaps when forcing a new end_at
+ *
+ * Entries of an archived team cannot be stopped (409).
+ *
+ * Behavior:
+ * - If ID is provided: stops the specific entry (must belong to user)
+ * - If no ID: stops the most recent running entry for the user
+ * - Sets end_at to end_at from the payload, or the current timestamp
+ * - Updates the updated_at field
+ * - Returns 409 with the existing entry if it is already stopped and
+ *   force is not set, so repeated stop requests are harmless
+ * - Replays the first response for retries carrying the same
+ *   Idempotency-Key header
+ * - Moves the entry to trash instead when it is shorter than the user's
+ *   discard_under_seconds preference and has no note or photo; the
+ *   response is then {"discarded": true, "entry": ...}
+ *
+ * @param c - Buffalo context with authenticated user
+ * @return JSON updated TimeTrac entry or error response
+ */
+func TracksStop(c buffalo.Context) error {
+	type payload struct {
+		ID           string     `json:"id"`
+		EndAt        *time.Time `json:"end_at"`
+		Force        bool       `json:"force"`
+		AllowOverlap bool       `json:"allow_overlap"`
+	}
+	var p payload
+	_ = c.Bind(&p)
+
+	tx := mustTx(c)
+	uid, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+
+	var item models.TimeTrac
+	var err error
+
+	// Handle specific entry ID or most recent running entry
+	if p.ID != "" {
+		// Stop specific entry by ID
+		id, e := uuid.FromString(p.ID)
+		if e != nil {
+			return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "bad id"}))
+		}
+		err = tx.Where("id = ? AND user_id = ? AND deleted_at IS NULL", id, uid).First(&item)
+	} else {
+		// Stop most recent running entry
+		item, err = findRunningTrack(tx, uid)
+	}
+
+	if err != nil {
+		return c.Render(http.StatusNotFound, r.JSON(map[string]string{"error": "no running entry"}))
+	}
+	if archived, err := entryTeamArchived(tx, item); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	} else if archived {
+		return renderEntryTeamArchived(c, item)
+	}
+
+	// Don't silently change the duration of an already stopped entry
+	if item.EndAt.Valid && !p.Force {
+		return c.Render(http.StatusConflict, r.JSON(map[string]any{
+			"error": "entry already stopped",
+			"entry": item,
+		}))
+	}
+
+	now := time.Now()
+	end := now
+	if p.EndAt != nil {
+		if !p.EndAt.After(item.StartAt) || p.EndAt.After(now) {
+			return c.Render(http.StatusUnprocessableEntity, r.JSON(map[string]string{"error": "end_at must be after start_at and not in the future"}))
+		}
+		end = *p.EndAt
+	}
+
+	// Overwriting end_at of a stopped entry is an edit, checked like TracksUpdate
+	if item.EndAt.Valid {
+		moved := item
+		moved.EndAt = nulls.NewTime(end)
+		for _, e := range []models.TimeTrac{item, moved} {
+			if locked, err := entryLocked(tx, e); err != nil {
+				return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+			} else if locked {
+				return renderEntryLocked(c)
+			}
+		}
+		if !allowOverlap(c, p.AllowOverlap) {
+			conflicts, err := findOverlaps(tx, uid, moved.StartAt, moved.EndAt, item.ID)
+			if err != nil {
+				return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+			}
+			if len(conflicts) > 0 {
+				return renderOverlapConflict(c, conflicts)
+			}
+		}
+	}
+
+	discarded, err := stopTrackEntry(tx, &item, end, now)
+	if err != nil {
+		return renderTrackSaveError(c, err, "cannot stop")
+	}
+	queueTrackEvent(c, eventTrackStopped, item)
+	if discarded {
+		return c.Render(http.StatusOK, r.JSON(map[string]any{"discarded": true, "entry": item}))
+	}
+	return c.Render(http.StatusOK, r.JSON(item))
+}
+
+/**
+ * TracksUpdate modifies an existing time tracking entry
+ *
+ * PATCH /api/tracks/{id}
+ *
+ * This endpoint allows partial updates to an existing time tracking entry.
+ * Only the fields provided in the payload will be updated.
+ *
+ * URL Parameters:
+ * - id: UUID of the time tracking entry to update
+ *
+ * Payload (all fields optional):
+ * - project: New project name
+ * - tags: New array of tag strings
+ * - note: New text note
+ * - color: New hex color code, validated like in TracksStart
+ * - start_at: New start timestamp
+ * - end_at: New end timestamp (only for stopped entries)
+ * - billable: Whether the time can be billed
+ * - allow_overlap: Skip overlap detection (optional, also accepted as query param)
+ *
+ * Time changes are checked against the user's other entries; an overlap
+ * yields 409 with the conflicting entry IDs and ranges. Entries of an
+ * archived team cannot be changed (409).
+ *
+ * Security:
+ * - Only the owner of the entry can update it
+ * - Entry must exist and belong to the authenticated user
+ *
+ * @param c - Buffalo context with authenticated user and entry ID
+ * @return JSON updated TimeTrac entry or error response
+ */
+func TracksUpdate(c buffalo.Context) error {
+	idStr := c.Param("id")
+	id, err := uuid.FromString(idStr)
+	if err != nil {
+		return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "bad id"}))
+	}
+
+	type payload struct {
+		Project      *string    `json:"project"`
+		Tags         *[]string  `json:"tags"`
+		Note         *string    `json:"note"`
+		Color        *string    `json:"color"`
+		StartAt      *time.Time `json:"start_at"`
+		EndAt        *time.Time `json:"end_at"`
+		ExternalRef  *string    `json:"external_ref"`
+		Billable     *bool      `json:"billable"`
+		AllowOverlap bool       `json:"allow_overlap"`
+	}
+	var p payload
+	if err := c.Bind(&p); err != nil {
+		return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "bad payload"}))
+	}
+
+	tx := mustTx(c)
+	uid, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+
+	// Find the entry and verify ownership
+	var item models.TimeTrac
+	if err := tx.Where("id = ? AND user_id = ? AND deleted_at IS NULL", id, uid).First(&item); err != nil {
+		return c.Render(http.StatusNotFound, r.JSON(map[string]string{"error": "not found"}))
+	}
+	prev := item
+	if archived, err := entryTeamArchived(tx, item); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	} else if archived {
+		return renderEntryTeamArchived(c, item)
+	}
+	if locked, err := entryLocked(tx, item); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	} else if locked {
+		return renderEntryLocked(c)
+	}
+
+	// Apply partial updates only for provided fields
+	if p.Project != nil {
+		item.Project = strings.TrimSpace(*p.Project)
+		if item.TeamID.Valid && item.Project != prev.Project {
+			if err := checkTeamProject(tx, item.TeamID.UUID, uid, item.Project); err != nil {
+				if errors.Is(err, errNotTeamProject) {
+					return renderFieldError(c, "project", err)
+				}
+				return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+			}
+		}
+	}
+	if p.Tags != nil {
+		item.Tags = pq.StringArray(*p.Tags)
+	}
+	if p.Note != nil {
+		item.Note = *p.Note
+	}
+	if p.Color != nil && strings.TrimSpace(*p.Color) != "" {
+		color, err := validators.NormalizeColor(*p.Color)
+		if err != nil {
+			return renderFieldError(c, "color", err)
+		}
+		item.Color = color
+	}
+	if p.ExternalRef != nil {
+		ref, err := validators.NormalizeExternalRef(*p.ExternalRef)
+		if err != nil {
+			return renderFieldError(c, "external_ref", err)
+		}
+		item.ExternalRef = externalRefValue(ref)
+	}
+	if p.Billable != nil {
+		item.Billable = *p.Billable
+	}
+
+	// Apply and validate time range changes
+	if p.StartAt != nil || p.EndAt != nil {
+		if p.EndAt != nil && !item.EndAt.Valid {
+			return c.Render(http.StatusUnprocessableEntity, r.JSON(map[string]string{"error": "cannot set end_at on a running entry"}))
+		}
+		if p.StartAt != nil {
+			item.StartAt = *p.StartAt
+		}
+		if p.EndAt != nil {
+			item.EndAt = nulls.NewTime(*p.EndAt)
+		}
+		if item.EndAt.Valid && !item.EndAt.Time.After(item.StartAt) {
+			return c.Render(http.StatusUnprocessableEntity, r.JSON(map[string]string{"error": "end_at must be after start_at"}))
+		}
+
+		// Nor can an entry be moved into an approved week
+		if locked, err := entryLocked(tx, item); err != nil {
+			return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+		} else if locked {
+			return renderEntryLocked(c)
+		}
+
+		if !allowOverlap(c, p.AllowOverlap) {
+			conflicts, err := findOverlaps(tx, uid, item.StartAt, item.EndAt, item.ID)
+			if err != nil {
+				return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+			}
+			if len(conflicts) > 0 {
+				return renderOverlapConflict(c, conflicts)
+			}
+		}
+	}
+	item.UpdatedAt = time.Now()
+
+	if err := recordRevision(tx, prev, uid); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot update"}))
+	}
+	if err := updateTrack(tx, &item); err != nil {
+		return renderTrackSaveError(c, err, "cannot update")
+	}
+	queueTrackEvent(c, eventTrackUpdated, item)
+	return c.Render(http.StatusOK, r.JSON(item))
+}
+
+/**
+ * TracksDelete moves a time tracking entry to trash
+ *
+ * DELETE /api/tracks/{id}[?hard=true]
+ *
+ * By default the entry is soft-deleted: deleted_at is set and the entry
+ * disappears from listings and summaries, but can be restored from trash
+ * for 30 days. A running entry is stopped as it is trashed. With
+ * `hard=true` the entry is removed permanently (also from trash).
  *
- * GET /api/tracks
+ * URL Parameters:
+ * - id: UUID of the time tracking entry to delete
+ *
+ * Security:
+ * - Only the owner of the entry can delete it
+ * - Uses direct SQL query for efficient deletion
+ * - Validates UUID format before processing
+ *
+ * @param c - Buffalo context with authenticated user and entry ID
+ * @return JSON success message or error response
+ */
+func TracksDelete(c buffalo.Context) error {
+	idStr := c.Param("id")
+	id, err := uuid.FromString(idStr)
+	if err != nil {
+		return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "bad id"}))
+	}
+
+	tx := mustTx(c)
+	uid, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+
+	// Entries in an approved timesheet week stay until the approval is revoked
+	var current models.TimeTrac
+	if err := tx.Where("id = ? AND user_id = ? AND deleted_at IS NULL", id, uid).First(&current); err == nil {
+		if locked, err := entryLocked(tx, current); err != nil {
+			return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot delete"}))
+		} else if locked {
+			return renderEntryLocked(c)
+		}
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot delete"}))
+	}
+
+	items := []models.TimeTrac{}
+	if c.Param("hard") == "true" {
+		// Direct SQL deletion for efficiency with ownership check
+		err = tx.RawQuery(`DELETE FROM timetrac WHERE id = ? AND user_id = ? RETURNING *`, id, uid).All(&items)
+	} else {
+		now := time.Now()
+		err = tx.RawQuery(`
+			UPDATE timetrac SET deleted_at = ?, end_at = COALESCE(end_at, ?), updated_at = ?
+			WHERE id = ? AND user_id = ? AND deleted_at IS NULL
+			RETURNING *
+		`, now, now, now, id, uid).All(&items)
+	}
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot delete"}))
+	}
+	// Purging an entry already in trash is not a new deletion
+	if len(items) == 1 && !(c.Param("hard") == "true" && items[0].DeletedAt.Valid) {
+		queueTrackEvent(c, eventTrackDeleted, items[0])
+	}
+	return c.Render(http.StatusOK, r.JSON(map[string]string{"status": "deleted"}))
+}
+
+/**
+ * TracksTrash lists the user's soft-deleted entries
  *
- * This endpoint returns a paginated list of time tracking entries for the
- * authenticated user, ordered by start time (most recent first).
+ * GET /api/tracks/trash
  *
- * Features:
- * - Returns up to 200 most recent entries
- * - Includes all entry data (project, tags, notes, location, photos)
- * - Automatically filters by authenticated user
+ * Returns up to 200 entries, most recently deleted first. Entries are
+ * purged permanently once they have been in trash for 30 days.
  *
  * @param c - Buffalo context with authenticated user
  * @return JSON array of TimeTrac entries or error response
  */
-func TracksIndex(c buffalo.Context) error {
+func TracksTrash(c buffalo.Context) error {
 	tx := mustTx(c)
 	uid, ok := currentUserID(c)
 	if !ok {
 		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
 	}
 
-	var list []models.TimeTrac
-	if err := tx.Where("user_id = ?", uid).
-		Order("start_at DESC").
+	list := []models.TimeTrac{}
+	if err := tx.Where("user_id = ? AND deleted_at IS NOT NULL", uid).
+		Order("deleted_at DESC").
 		Limit(200).
 		All(&list); err != nil {
 		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
 	}
+	omitClient(list)
 	return c.Render(http.StatusOK, r.JSON(list))
 }
 
 /**
- * TracksStart creates a new time tracking entry and starts the timer
+ * TracksRestore brings a soft-deleted entry back from trash
  *
- * POST /api/tracks/start
+ * POST /api/tracks/{id}/restore
  *
- * This endpoint creates a new time tracking entry with optional location data,
- * photo attachments, and metadata. It automatically stops any currently
- * running entry for the user before starting a new one.
+ * A team entry is not restored while its team is archived (409).
+ *
+ * @param c - Buffalo context with authenticated user and entry ID
+ * @return JSON restored TimeTrac entry or error response
+ */
+func TracksRestore(c buffalo.Context) error {
+	id, err := uuid.FromString(c.Param("id"))
+	if err != nil {
+		return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "bad id"}))
+	}
+
+	tx := mustTx(c)
+	uid, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+
+	var item models.TimeTrac
+	if err := tx.Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", id, uid).First(&item); err != nil {
+		return c.Render(http.StatusNotFound, r.JSON(map[string]string{"error": "not found in trash"}))
+	}
+	if archived, err := entryTeamArchived(tx, item); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	} else if archived {
+		return renderEntryTeamArchived(c, item)
+	}
+	if locked, err := entryLocked(tx, item); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	} else if locked {
+		return renderEntryLocked(c)
+	}
+
+	item.DeletedAt = nulls.Time{}
+	item.UpdatedAt = time.Now()
+	if err := updateTrack(tx, &item); err != nil {
+		return renderTrackSaveError(c, err, "cannot restore")
+	}
+	return c.Render(http.StatusOK, r.JSON(item))
+}
+
+/**
+ * splitPointValid reports whether an entry can be split at the given instant
+ *
+ * The split point must lie strictly inside the entry. For a running entry
+ * the upper bound is the current time, so only past instants are accepted.
+ *
+ * @param item - Entry to split
+ * @param at - Requested split instant
+ * @param now - Current time (upper bound for running entries)
+ * @return bool - True if the split point is usable
+ */
+func splitPointValid(item models.TimeTrac, at, now time.Time) bool {
+	end := now
+	if item.EndAt.Valid {
+		end = item.EndAt.Time
+	}
+	return at.After(item.StartAt) && at.Before(end)
+}
+
+/**
+ * TracksSplit splits one time tracking entry into two at a timestamp
+ *
+ * POST /api/tracks/{id}/split
+ *
+ * The original entry keeps start_at..at and a new entry is created for
+ * at..end_at, copying project, tags, note and color. Photo and location
+ * data stay with the original entry. Splitting a running entry leaves the
+ * new second half running.
+ *
+ * URL Parameters:
+ * - id: UUID of the entry to split
  *
  * Payload:
- * - project: Project name (optional)
- * - tags: Array of tag strings (optional)
- * - note: Text note (optional)
- * - color: Hex color code (defaults to #3b82f6)
- * - location_lat: GPS latitude (optional)
- * - location_lng: GPS longitude (optional)
- * - location_addr: Human-readable address (optional)
- * - photo_data: Base64 encoded image data (optional)
+ * - at: Split timestamp (must lie strictly inside the entry, and in the past
+ *   for running entries)
  *
- * @param c - Buffalo context with authenticated user
- * @return JSON TimeTrac entry or error response
+ * Both writes happen inside the request transaction. A team entry is not
+ * split while its team is archived (409).
+ *
+ * @param c - Buffalo context with authenticated user and entry ID
+ * @return JSON with both resulting entries or error response
  */
-func TracksStart(c buffalo.Context) error {
+func TracksSplit(c buffalo.Context) error {
+	id, err := uuid.FromString(c.Param("id"))
+	if err != nil {
+		return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "bad id"}))
+	}
+
 	type payload struct {
-		Project      string   `json:"project"`
-		Tags         []string `json:"tags"`
-		Note         string   `json:"note"`
-		Color        string   `json:"color"`
-		LocationLat  *float64 `json:"location_lat"`
-		LocationLng  *float64 `json:"location_lng"`
-		LocationAddr *string  `json:"location_addr"`
-		PhotoData    *string  `json:"photo_data"`
+		At *time.Time `json:"at"`
 	}
 	var p payload
-	if err := c.Bind(&p); err != nil {
+	if err := c.Bind(&p); err != nil || p.At == nil {
 		return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "bad payload"}))
 	}
 
-	// Sanitize and validate input data
-	p.Project = strings.TrimSpace(p.Project)
-	p.Color = strings.TrimSpace(p.Color)
-	if p.Color == "" {
-		p.Color = "#3b82f6" // Default blue color
-	}
-
 	tx := mustTx(c)
 	uid, ok := currentUserID(c)
 	if !ok {
 		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
 	}
 
-	// Safety measure: stop any currently running entry for this user
-	_ = tx.RawQuery(`UPDATE timetrac SET end_at = now(), updated_at = now() WHERE user_id = ? AND end_at IS NULL`, uid).Exec()
-
-	// Create new time tracking entry
-	item := models.TimeTrac{
-		UserID:  uid,
-		Project: p.Project,
-		Tags:    pq.StringArray(p.Tags),
-		Note:    p.Note,
-		Color:   p.Color,
-		StartAt: time.Now(),
-		EndAt:   nulls.Time{}, // NULL indicates running entry
+	var item models.TimeTrac
+	if err := tx.Where("id = ? AND user_id = ? AND deleted_at IS NULL", id, uid).First(&item); err != nil {
+		return c.Render(http.StatusNotFound, r.JSON(map[string]string{"error": "not found"}))
 	}
 
-	// Add optional location data if provided
-	if p.LocationLat != nil {
-		item.LocationLat = nulls.NewFloat64(*p.LocationLat)
-	}
-	if p.LocationLng != nil {
-		item.LocationLng = nulls.NewFloat64(*p.LocationLng)
+	now := time.Now()
+	if !splitPointValid(item, *p.At, now) {
+		return c.Render(http.StatusUnprocessableEntity, r.JSON(map[string]string{"error": "at must lie strictly inside the entry"}))
 	}
-	if p.LocationAddr != nil {
-		item.LocationAddr = nulls.NewString(strings.TrimSpace(*p.LocationAddr))
+	if archived, err := entryTeamArchived(tx, item); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	} else if archived {
+		return renderEntryTeamArchived(c, item)
 	}
 
-	// Add optional photo data if provided
-	if p.PhotoData != nil {
-		item.PhotoData = nulls.NewString(*p.PhotoData)
+	// Second half inherits the metadata and the original end (NULL if running)
+	second := models.TimeTrac{
+		UserID:   uid,
+		Project:  item.Project,
+		Tags:     item.Tags,
+		Note:     item.Note,
+		Color:    item.Color,
+		StartAt:  *p.At,
+		EndAt:    item.EndAt,
+		TeamID:   item.TeamID,
+		Billable: item.Billable,
+	}
+	for _, half := range []models.TimeTrac{item, second} {
+		if locked, err := entryLocked(tx, half); err != nil {
+			return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+		} else if locked {
+			return renderEntryLocked(c)
+		}
 	}
 
-	if err := tx.Create(&item); err != nil {
-		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot create"}))
+	if err := recordRevision(tx, item, uid); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot split"}))
 	}
-	return c.Render(http.StatusCreated, r.JSON(item))
+	item.EndAt = nulls.NewTime(*p.At)
+	item.UpdatedAt = now
+	if err := updateTrack(tx, &item); err != nil {
+		return renderTrackSaveError(c, err, "cannot split")
+	}
+	if err := createTrack(tx, &second); err != nil {
+		return renderTrackSaveError(c, err, "cannot split")
+	}
+
+	return c.Render(http.StatusOK, r.JSON(map[string]any{
+		"first":  item,
+		"second": second,
+	}))
 }
 
 /**
- * TracksStop stops a running time tracking entry
+ * mergeTracks folds an ordered list of entries into the first one
  *
- * POST /api/tracks/stop
+ * The result keeps the first entry's ID, project, color and attachments,
+ * spans the earliest start_at to the latest end_at, carries the distinct
+ * tags in order of appearance, and joins non-empty notes with newlines.
  *
- * This endpoint stops a time tracking entry by setting the end_at timestamp.
- * It can stop a specific entry by ID or the most recent running entry.
+ * @param entries - Entries in client-supplied order (at least one)
+ * @return models.TimeTrac - The merged entry
+ */
+func mergeTracks(entries []models.TimeTrac) models.TimeTrac {
+	merged := entries[0]
+	seen := map[string]bool{}
+	tags := pq.StringArray{}
+	notes := []string{}
+
+	for _, e := range entries {
+		if e.StartAt.Before(merged.StartAt) {
+			merged.StartAt = e.StartAt
+		}
+		if e.EndAt.Time.After(merged.EndAt.Time) {
+			merged.EndAt = e.EndAt
+		}
+		for _, t := range e.Tags {
+			if !seen[t] {
+				seen[t] = true
+				tags = append(tags, t)
+			}
+		}
+		if n := strings.TrimSpace(e.Note); n != "" {
+			notes = append(notes, n)
+		}
+	}
+
+	merged.Tags = tags
+	merged.Note = strings.Join(notes, "\n")
+	return merged
+}
+
+/**
+ * TracksMerge combines several stopped entries into one
  *
- * Payload (optional):
- * - id: Specific entry ID to stop (if not provided, stops most recent running entry)
+ * POST /api/tracks/merge
+ *
+ * Payload:
+ * - ids: Ordered list of 2–20 entry IDs owned by the user
+ * - force: Allow merging entries from different projects (optional)
  *
  * Behavior:
- * - If ID is provided: stops the specific entry (must belong to user)
- * - If no ID: stops the most recent running entry for the user
- * - Sets end_at to current timestamp
- * - Updates the updated_at field
+ * - The first entry in the list is kept and updated (see mergeTracks)
+ * - All other entries are moved to trash (see TracksDelete)
+ * - Running entries, entries in an approved week (423) and entries of
+ *   an archived team (409) cannot be merged
  *
  * @param c - Buffalo context with authenticated user
- * @return JSON updated TimeTrac entry or error response
+ * @return JSON with the merged entry and the deleted IDs, or error response
  */
-func TracksStop(c buffalo.Context) error {
+func TracksMerge(c buffalo.Context) error {
 	type payload struct {
-		ID string `json:"id"`
+		IDs   []string `json:"ids"`
+		Force bool     `json:"force"`
 	}
 	var p payload
-	_ = c.Bind(&p)
+	if err := c.Bind(&p); err != nil {
+		return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "bad payload"}))
+	}
+	if len(p.IDs) < 2 || len(p.IDs) > 20 {
+		return c.Render(http.StatusUnprocessableEntity, r.JSON(map[string]string{"error": "between 2 and 20 ids required"}))
+	}
+
+	ids := make([]uuid.UUID, 0, len(p.IDs))
+	args := make([]interface{}, 0, len(p.IDs))
+	seen := map[uuid.UUID]bool{}
+	for _, s := range p.IDs {
+		id, err := uuid.FromString(s)
+		if err != nil {
+			return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "bad id"}))
+		}
+		if seen[id] {
+			return c.Render(http.StatusUnprocessableEntity, r.JSON(map[string]string{"error": "duplicate id"}))
+		}
+		seen[id] = true
+		ids = append(ids, id)
+		args = append(args, id)
+	}
 
 	tx := mustTx(c)
 	uid, ok := currentUserID(c)
@@ -214,134 +1628,182 @@ func TracksStop(c buffalo.Context) error {
 		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
 	}
 
-	var item models.TimeTrac
-	var err error
+	var found []models.TimeTrac
+	if err := tx.Where("user_id = ? AND deleted_at IS NULL", uid).Where("id in (?)", args...).All(&found); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+	if len(found) != len(ids) {
+		return c.Render(http.StatusNotFound, r.JSON(map[string]string{"error": "not found"}))
+	}
 
-	// Handle specific entry ID or most recent running entry
-	if p.ID != "" {
-		// Stop specific entry by ID
-		id, e := uuid.FromString(p.ID)
-		if e != nil {
-			return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "bad id"}))
+	// Restore the client-supplied order and validate
+	byID := make(map[uuid.UUID]models.TimeTrac, len(found))
+	for _, e := range found {
+		byID[e.ID] = e
+	}
+	project := byID[ids[0]].Project
+	entries := make([]models.TimeTrac, 0, len(ids))
+	for _, id := range ids {
+		e := byID[id]
+		if !e.EndAt.Valid {
+			return c.Render(http.StatusUnprocessableEntity, r.JSON(map[string]string{"error": "cannot merge a running entry"}))
 		}
-		err = tx.Where("id = ? AND user_id = ?", id, uid).First(&item)
-	} else {
-		// Stop most recent running entry
-		err = tx.Where("user_id = ? AND end_at IS NULL", uid).Order("start_at DESC").First(&item)
+		if e.Project != project && !p.Force {
+			return c.Render(http.StatusUnprocessableEntity, r.JSON(map[string]string{"error": "entries span more than one project, use force=true"}))
+		}
+		if archived, err := entryTeamArchived(tx, e); err != nil {
+			return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+		} else if archived {
+			return renderEntryTeamArchived(c, e)
+		}
+		if locked, err := entryLocked(tx, e); err != nil {
+			return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+		} else if locked {
+			return renderEntryLocked(c)
+		}
+		entries = append(entries, e)
 	}
 
-	if err != nil {
-		return c.Render(http.StatusNotFound, r.JSON(map[string]string{"error": "no running entry"}))
+	// The merged span may reach into an approved week of the first entry's team
+	merged := mergeTracks(entries)
+	if locked, err := entryLocked(tx, merged); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	} else if locked {
+		return renderEntryLocked(c)
+	}
+	if err := recordRevision(tx, entries[0], uid); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot merge"}))
+	}
+	merged.UpdatedAt = time.Now()
+	if err := updateTrack(tx, &merged); err != nil {
+		return renderTrackSaveError(c, err, "cannot merge")
 	}
 
-	// Update entry with end time
+	// The merged-away entries go to trash like TracksDelete, so a merge can
+	// be undone by restoring them
 	now := time.Now()
-	item.EndAt = nulls.NewTime(now)
-	item.UpdatedAt = now
-
-	if err := tx.Update(&item); err != nil {
-		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot stop"}))
+	deleted := make([]uuid.UUID, 0, len(ids)-1)
+	for _, e := range entries[1:] {
+		var trashed models.TimeTrac
+		if err := tx.RawQuery(`
+			UPDATE timetrac SET deleted_at = ?, updated_at = ?
+			WHERE id = ? AND user_id = ? AND deleted_at IS NULL
+			RETURNING *
+		`, now, now, e.ID, uid).First(&trashed); err != nil {
+			return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot merge"}))
+		}
+		queueTrackEvent(c, eventTrackDeleted, trashed)
+		deleted = append(deleted, e.ID)
 	}
-	return c.Render(http.StatusOK, r.JSON(item))
+	queueTrackEvent(c, eventTrackUpdated, merged)
+
+	return c.Render(http.StatusOK, r.JSON(map[string]any{
+		"entry":       merged,
+		"deleted_ids": deleted,
+	}))
 }
 
 /**
- * TracksUpdate modifies an existing time tracking entry
+ * TracksRestart starts a new running entry based on an existing one
  *
- * PATCH /api/tracks/{id}
+ * POST /api/tracks/{id}/restart
  *
- * This endpoint allows partial updates to an existing time tracking entry.
- * Only the fields provided in the payload will be updated.
+ * Copies project, tags, note, color, team and billable from the source
+ * entry into a fresh entry starting now. Photo and location are not
+ * copied. The new entry goes through the start path of TracksStart: the
+ * team rules are checked again (422 once the user left the team, 409
+ * once it is archived), an archived project is refused (409) unless
+ * `?unarchive=true`, a start in an approved week too (423), and any
+ * running entry is stopped first.
  *
  * URL Parameters:
- * - id: UUID of the time tracking entry to update
- *
- * Payload (all fields optional):
- * - project: New project name
- * - tags: New array of tag strings
- * - note: New text note
- * - color: New hex color code
+ * - id: UUID of the source entry (must belong to the user)
  *
- * Security:
- * - Only the owner of the entry can update it
- * - Entry must exist and belong to the authenticated user
+ * Response:
+ * - entry: The new running entry
+ * - stopped: The entry that was stopped, or null
  *
  * @param c - Buffalo context with authenticated user and entry ID
- * @return JSON updated TimeTrac entry or error response
+ * @return JSON with the new and stopped entries or error response
  */
-func TracksUpdate(c buffalo.Context) error {
-	idStr := c.Param("id")
-	id, err := uuid.FromString(idStr)
+func TracksRestart(c buffalo.Context) error {
+	id, err := uuid.FromString(c.Param("id"))
 	if err != nil {
 		return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "bad id"}))
 	}
 
-	type payload struct {
-		Project *string   `json:"project"`
-		Tags    *[]string `json:"tags"`
-		Note    *string   `json:"note"`
-		Color   *string   `json:"color"`
-	}
-	var p payload
-	if err := c.Bind(&p); err != nil {
-		return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "bad payload"}))
-	}
-
 	tx := mustTx(c)
 	uid, ok := currentUserID(c)
 	if !ok {
 		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
 	}
 
-	// Find the entry and verify ownership
-	var item models.TimeTrac
-	if err := tx.Where("id = ? AND user_id = ?", id, uid).First(&item); err != nil {
+	var src models.TimeTrac
+	if err := tx.Where("id = ? AND user_id = ? AND deleted_at IS NULL", id, uid).First(&src); err != nil {
 		return c.Render(http.StatusNotFound, r.JSON(map[string]string{"error": "not found"}))
 	}
 
-	// Apply partial updates only for provided fields
-	if p.Project != nil {
-		item.Project = strings.TrimSpace(*p.Project)
+	var srcTeam *uuid.UUID
+	if src.TeamID.Valid {
+		srcTeam = &src.TeamID.UUID
 	}
-	if p.Tags != nil {
-		item.Tags = pq.StringArray(*p.Tags)
+	teamID, field, err := entryTeam(tx, uid, srcTeam, src.Project, src.Note)
+	if field != "" {
+		return renderFieldError(c, field, err)
 	}
-	if p.Note != nil {
-		item.Note = *p.Note
+	if errors.Is(err, errTeamArchived) {
+		return renderEntryTeamArchived(c, src)
 	}
-	if p.Color != nil && strings.TrimSpace(*p.Color) != "" {
-		item.Color = strings.TrimSpace(*p.Color)
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
 	}
-	item.UpdatedAt = time.Now()
 
-	if err := tx.Update(&item); err != nil {
-		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot update"}))
+	item := models.TimeTrac{
+		UserID:   uid,
+		Project:  src.Project,
+		Tags:     src.Tags,
+		Note:     src.Note,
+		Color:    src.Color,
+		TeamID:   teamID,
+		Billable: src.Billable,
 	}
-	return c.Render(http.StatusOK, r.JSON(item))
+	stopped, err := startTrackEntry(tx, &item, time.Now(), c.Param("unarchive") == "true")
+	if errors.Is(err, errProjectArchived) {
+		return c.Render(http.StatusConflict, r.JSON(map[string]string{"error": "project is archived", "project": item.Project}))
+	}
+	if errors.Is(err, errEntryLocked) {
+		return renderEntryLocked(c)
+	}
+	if err != nil {
+		return renderTrackSaveError(c, err, "cannot create")
+	}
+
+	if stopped != nil {
+		queueTrackEvent(c, eventTrackStopped, *stopped)
+	}
+	queueTrackEvent(c, eventTrackStarted, item)
+	// Resolve the address in the background when the client could not
+	queueGeocode(c, item)
+	return c.Render(http.StatusCreated, r.JSON(map[string]any{
+		"entry":   item,
+		"stopped": stopped,
+	}))
 }
 
 /**
- * TracksDelete permanently removes a time tracking entry
+ * TracksHistory returns the edit history of a time tracking entry
  *
- * DELETE /api/tracks/{id}
- *
- * This endpoint permanently deletes a time tracking entry from the database.
- * The deletion is irreversible and only affects entries owned by the authenticated user.
- *
- * URL Parameters:
- * - id: UUID of the time tracking entry to delete
+ * GET /api/tracks/{id}/history
  *
- * Security:
- * - Only the owner of the entry can delete it
- * - Uses direct SQL query for efficient deletion
- * - Validates UUID format before processing
+ * Each revision holds the values of project, note, tags, start_at and
+ * end_at as they were before a change, plus who changed them and when.
+ * Entries in trash keep their history.
  *
  * @param c - Buffalo context with authenticated user and entry ID
- * @return JSON success message or error response
+ * @return JSON array of revisions newest-first or error response
  */
-func TracksDelete(c buffalo.Context) error {
-	idStr := c.Param("id")
-	id, err := uuid.FromString(idStr)
+func TracksHistory(c buffalo.Context) error {
+	id, err := uuid.FromString(c.Param("id"))
 	if err != nil {
 		return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "bad id"}))
 	}
@@ -352,10 +1814,17 @@ func TracksDelete(c buffalo.Context) error {
 		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
 	}
 
-	// Direct SQL deletion for efficiency with ownership check
-	_, err = tx.Store.Exec(`DELETE FROM timetrac WHERE id = $1 AND user_id = $2`, id, uid)
+	owned, err := tx.Where("id = ? AND user_id = ?", id, uid).Exists(&models.TimeTrac{})
 	if err != nil {
-		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot delete"}))
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
 	}
-	return c.Render(http.StatusOK, r.JSON(map[string]string{"status": "deleted"}))
+	if !owned {
+		return c.Render(http.StatusNotFound, r.JSON(map[string]string{"error": "not found"}))
+	}
+
+	revisions := []models.TimeTracRevision{}
+	if err := tx.Where("track_id = ?", id).Order("created_at DESC").All(&revisions); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+	return c.Render(http.StatusOK, r.JSON(revisions))
 }
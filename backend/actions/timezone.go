@@ -0,0 +1,71 @@
+/**
+ * Timezone - Day Boundaries in the User's Zone
+ *
+ * Summaries, exports and calendars group entries by day. The zone comes
+ * from the `tz` query parameter (an IANA name) or the user's timezone
+ * preference, and defaults to UTC; SQL uses it with AT TIME ZONE.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-20
+ */
+package actions
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gofrs/uuid"
+)
+
+/**
+ * requestLocation resolves the time zone used for day boundaries
+ *
+ * The `tz` query parameter wins, then the user's stored timezone
+ * preference, then UTC. An invalid `tz` value is reported as an error so
+ * the handler can answer 422 with renderBadTimezone.
+ *
+ * @param c - Buffalo context (reads the `tz` parameter)
+ * @param tx - Database transaction
+ * @param uid - Current user ID
+ * @return *time.Location - Effective location
+ * @return string - The offending zone name when err is not nil
+ */
+func requestLocation(c buffalo.Context, tx *pop.Connection, uid uuid.UUID) (*time.Location, string, error) {
+	if tz := strings.TrimSpace(c.Param("tz")); tz != "" {
+		loc, err := time.LoadLocation(tz)
+		return loc, tz, err
+	}
+
+	prefs, err := userPreferences(tx, uid)
+	if err != nil {
+		return time.UTC, "", err
+	}
+	loc, err := time.LoadLocation(prefs.Timezone)
+	if err != nil {
+		// A stored zone that no longer loads falls back to UTC
+		return time.UTC, "", nil
+	}
+	return loc, "", nil
+}
+
+/**
+ * renderBadTimezone renders the 422 response for an unknown IANA zone name
+ */
+func renderBadTimezone(c buffalo.Context, tz string) error {
+	return c.Render(http.StatusUnprocessableEntity, r.JSON(map[string]string{
+		"error": "invalid timezone",
+		"tz":    tz,
+	}))
+}
+
+/**
+ * startOfDay returns midnight of t's calendar day in loc
+ */
+func startOfDay(t time.Time, loc *time.Location) time.Time {
+	y, m, d := t.In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, loc)
+}
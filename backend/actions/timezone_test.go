@@ -0,0 +1,68 @@
+package actions
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func Test_StartOfDay(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2025, 9, 2, 20, 0, 0, 0, time.UTC)
+	if got, want := startOfDay(at, time.UTC), time.Date(2025, 9, 2, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("UTC: got %v, want %v", got, want)
+	}
+	// 20:00 UTC is already the next morning in Tokyo
+	if got, want := startOfDay(at, tokyo), time.Date(2025, 9, 3, 0, 0, 0, 0, tokyo); !got.Equal(want) {
+		t.Errorf("Tokyo: got %v, want %v", got, want)
+	}
+}
+
+func (as *ActionSuite) Test_TracksSummary_Timezone() {
+	token := as.registerToken("summary-tz@example.com")
+	start := time.Date(2025, 9, 2, 20, 0, 0, 0, time.UTC)
+	res := as.authJSON(token, "/api/tracks/").Post(map[string]any{"project": "Web", "start_at": start, "end_at": start.Add(time.Hour)})
+	as.Equal(http.StatusCreated, res.Code)
+
+	type day struct {
+		Day     string  `json:"day"`
+		Seconds float64 `json:"seconds"`
+	}
+	summary := func(tz string) (string, []day) {
+		res := as.authJSON(token, "/api/tracks/summary?from=2025-09-01T00:00:00Z&to=2025-09-05T00:00:00Z%s", tz).Get()
+		as.Equal(http.StatusOK, res.Code)
+		var body struct {
+			Timezone string `json:"timezone"`
+			ByDay    []day  `json:"by_day"`
+		}
+		as.NoError(json.Unmarshal(res.Body.Bytes(), &body))
+		return body.Timezone, body.ByDay
+	}
+
+	// Without a zone days are UTC
+	zone, days := summary("")
+	as.Equal("UTC", zone)
+	as.Equal([]day{{"2025-09-02", 3600}}, days)
+
+	// The evening entry belongs to the next day in Tokyo
+	zone, days = summary("&tz=Asia/Tokyo")
+	as.Equal("Asia/Tokyo", zone)
+	as.Equal([]day{{"2025-09-03", 3600}}, days)
+
+	// An unknown zone is reported back
+	res = as.authJSON(token, "/api/tracks/summary?tz=Mars/Olympus").Get()
+	as.Equal(http.StatusUnprocessableEntity, res.Code)
+	as.Contains(res.Body.String(), "Mars/Olympus")
+
+	// The stored preference applies when tz is omitted, and tz still wins
+	as.Equal(http.StatusOK, as.authJSON(token, "/api/me/preferences").Patch(map[string]string{"timezone": "Asia/Tokyo"}).Code)
+	zone, days = summary("")
+	as.Equal("Asia/Tokyo", zone)
+	as.Equal([]day{{"2025-09-03", 3600}}, days)
+	zone, _ = summary("&tz=UTC")
+	as.Equal("UTC", zone)
+}
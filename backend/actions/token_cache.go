@@ -0,0 +1,185 @@
+/**
+ * Token Cache - Per-Process Cache of Valid Access Tokens
+ *
+ * AuthRequired looks up every token's auth_tokens row (see
+ * userFromToken). Tokens found valid are cached by jti, together with
+ * their user, for TOKEN_CACHE_TTL_SECONDS (default 60, 0 disables the
+ * cache):
+ * - Only valid tokens are cached; unknown, revoked and expired tokens
+ *   always go to the database
+ * - Revocations in this process drop the affected entries at once and
+ *   again after the commit, so they take effect on the next request
+ * - Revocations by another instance take effect within the TTL
+ *
+ * Hits and misses are exported at /metrics.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-21
+ */
+package actions
+
+import (
+	"sync"
+	"time"
+
+	"backend/models"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/envy"
+	"github.com/gobuffalo/nulls"
+	"github.com/gofrs/uuid"
+)
+
+const (
+	// defaultTokenCacheTTL is used when TOKEN_CACHE_TTL_SECONDS is unset.
+	defaultTokenCacheTTL = time.Minute
+	// maxTokenCacheTTL bounds how long a revocation elsewhere can go unseen.
+	maxTokenCacheTTL = 5 * time.Minute
+	// tokenCacheSize bounds the number of cached tokens.
+	tokenCacheSize = 10000
+)
+
+var (
+	tokenCacheHits   = newCounter("token_cache_hits_total", "Access token lookups answered from the cache.")
+	tokenCacheMisses = newCounter("token_cache_misses_total", "Access token lookups that queried the database.")
+)
+
+/**
+ * cachedToken is a token found valid in the database
+ */
+type cachedToken struct {
+	user      models.User
+	family    string
+	expiresAt time.Time  // auth_tokens.expires_at
+	lastUsed  nulls.Time // auth_tokens.last_used_at as last written
+	loadedAt  time.Time
+}
+
+/**
+ * tokenCache maps jti to cachedToken
+ */
+type tokenCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cachedToken
+}
+
+var (
+	authCacheOnce sync.Once
+	authCache     *tokenCache
+)
+
+/**
+ * currentTokenCache returns the process cache, configured on first use
+ */
+func currentTokenCache() *tokenCache {
+	authCacheOnce.Do(func() {
+		ttl, err := parseTTL(envy.Get("TOKEN_CACHE_TTL_SECONDS", ""), time.Second, defaultTokenCacheTTL, 0, maxTokenCacheTTL)
+		if err != nil {
+			app.Logger.Warnf("TOKEN_CACHE_TTL_SECONDS: %v; using %s", err, ttl)
+		}
+		authCache = newTokenCache(ttl)
+	})
+	return authCache
+}
+
+/**
+ * newTokenCache returns an empty cache; a ttl of 0 disables it
+ */
+func newTokenCache(ttl time.Duration) *tokenCache {
+	return &tokenCache{ttl: ttl, entries: map[string]cachedToken{}}
+}
+
+/**
+ * get returns the cached token while it is fresh and unexpired
+ */
+func (tc *tokenCache) get(jti string, now time.Time) (cachedToken, bool) {
+	if tc.ttl <= 0 {
+		return cachedToken{}, false
+	}
+	tc.mu.Lock()
+	e, ok := tc.entries[jti]
+	tc.mu.Unlock()
+	if !ok || now.Sub(e.loadedAt) >= tc.ttl || !now.Before(e.expiresAt) {
+		tokenCacheMisses.Inc()
+		return cachedToken{}, false
+	}
+	tokenCacheHits.Inc()
+	return e, true
+}
+
+/**
+ * put caches a token found valid
+ */
+func (tc *tokenCache) put(jti string, e cachedToken) {
+	if tc.ttl <= 0 {
+		return
+	}
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	if len(tc.entries) >= tokenCacheSize {
+		clear(tc.entries)
+	}
+	tc.entries[jti] = e
+}
+
+/**
+ * touched records a last_used_at update of a cached token
+ */
+func (tc *tokenCache) touched(jti string, at time.Time) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	if e, ok := tc.entries[jti]; ok {
+		e.lastUsed = nulls.NewTime(at)
+		tc.entries[jti] = e
+	}
+}
+
+/**
+ * forget drops the entries for which match returns true
+ */
+func (tc *tokenCache) forget(match func(jti string, e cachedToken) bool) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	for jti, e := range tc.entries {
+		if match(jti, e) {
+			delete(tc.entries, jti)
+		}
+	}
+}
+
+/**
+ * forgetJTI drops one cached token
+ */
+func (tc *tokenCache) forgetJTI(jti string) {
+	tc.forget(func(k string, _ cachedToken) bool { return k == jti })
+}
+
+/**
+ * forgetFamily drops the cached tokens of a session
+ */
+func (tc *tokenCache) forgetFamily(family string) {
+	if family == "" {
+		return
+	}
+	tc.forget(func(_ string, e cachedToken) bool { return e.family == family })
+}
+
+/**
+ * forgetUser drops the cached tokens of a user
+ */
+func (tc *tokenCache) forgetUser(uid uuid.UUID) {
+	tc.forget(func(_ string, e cachedToken) bool { return e.user.ID == uid })
+}
+
+/**
+ * forgetTokens runs forget now and again after the request commits, so
+ * a concurrent request cannot keep a revoked token cached
+ */
+func forgetTokens(c buffalo.Context, forget func(tc *tokenCache)) {
+	tc := currentTokenCache()
+	forget(tc)
+	afterCommit(c, func() { forget(tc) })
+}
@@ -0,0 +1,138 @@
+/**
+ * Track Cleanup Actions - Finding and Removing Accidental Entries
+ *
+ * Fat-fingered start/stop sequences leave very short entries behind. This
+ * file lists such entries and moves them to trash in bulk. Entries with a
+ * note or photo are considered intentional and are never included.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-20
+ */
+package actions
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"backend/models"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gofrs/uuid"
+)
+
+// shortEntryDefaultSeconds is the `under` threshold when neither the
+// request nor the discard_under_seconds preference sets one.
+const shortEntryDefaultSeconds = 60
+
+// shortEntriesWhere selects the user's stopped entries shorter than the
+// threshold that have neither note nor photo.
+const shortEntriesWhere = `user_id = ? AND deleted_at IS NULL AND end_at IS NOT NULL
+	AND EXTRACT(EPOCH FROM (end_at - start_at)) < ?
+	AND COALESCE(TRIM(note), '') = '' AND COALESCE(photo_data, '') = '' AND photo_key IS NULL`
+
+/**
+ * shortThreshold resolves the `under` parameter: the request value, else
+ * the user's discard_under_seconds preference, else 60 seconds
+ *
+ * @return int - Threshold in seconds
+ * @return bool - False if the parameter is malformed
+ * @return error - Database error while loading preferences
+ */
+func shortThreshold(c buffalo.Context, uid uuid.UUID) (int, bool, error) {
+	if v := c.Param("under"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 3600 {
+			return 0, false, nil
+		}
+		return n, true, nil
+	}
+	prefs, err := userPreferences(mustTx(c), uid)
+	if err != nil {
+		return 0, true, err
+	}
+	if prefs.DiscardUnderSeconds > 0 {
+		return prefs.DiscardUnderSeconds, true, nil
+	}
+	return shortEntryDefaultSeconds, true, nil
+}
+
+/**
+ * TracksShortIndex lists entries shorter than a threshold
+ *
+ * GET /api/tracks/short?under=<seconds>
+ *
+ * `under` is 1–3600 and defaults to the discard_under_seconds preference
+ * (or 60). Entries with a note or photo are excluded.
+ *
+ * @param c - Buffalo context with authenticated user
+ * @return JSON {"under": n, "items": [...]} or error response
+ */
+func TracksShortIndex(c buffalo.Context) error {
+	tx := mustTx(c)
+	uid, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+
+	under, valid, err := shortThreshold(c, uid)
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+	if !valid {
+		return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "under must be between 1 and 3600"}))
+	}
+
+	list := []models.TimeTrac{}
+	if err := tx.Where(shortEntriesWhere, uid, under).Order("start_at DESC").Limit(tracksPageMax).All(&list); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+	return c.Render(http.StatusOK, r.JSON(map[string]any{"under": under, "items": list}))
+}
+
+/**
+ * TracksShortDelete moves all entries shorter than a threshold to trash
+ *
+ * DELETE /api/tracks/short?under=<seconds>
+ *
+ * Uses the same selection as TracksShortIndex, leaving out entries in an
+ * approved timesheet week. Entries can be restored from trash until they
+ * are purged.
+ *
+ * @param c - Buffalo context with authenticated user
+ * @return JSON {"deleted": n, "ids": [...]} or error response
+ */
+func TracksShortDelete(c buffalo.Context) error {
+	tx := mustTx(c)
+	uid, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+
+	under, valid, err := shortThreshold(c, uid)
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+	if !valid {
+		return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "under must be between 1 and 3600"}))
+	}
+
+	var rows []struct {
+		ID uuid.UUID `db:"id"`
+	}
+	now := time.Now()
+	if err := tx.RawQuery(`
+		UPDATE timetrac SET deleted_at = ?, updated_at = ?
+		WHERE `+shortEntriesWhere+` AND `+entryUnlockedSQL+`
+		RETURNING id
+	`, now, now, uid, under).All(&rows); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot delete"}))
+	}
+
+	ids := make([]uuid.UUID, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+	}
+	return c.Render(http.StatusOK, r.JSON(map[string]any{"deleted": len(ids), "ids": ids}))
+}
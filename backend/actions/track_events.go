@@ -0,0 +1,206 @@
+/**
+ * Track Events - Live Updates for Running Timers
+ *
+ * This file implements a Server-Sent Events stream so clients see timers
+ * started or stopped on another device without polling:
+ * - An in-process pub/sub hub with one channel set per user
+ * - queueTrackEvent, publishing events once the request transaction has
+ *   committed (see after_commit.go)
+ * - The SSE endpoint itself
+ *
+ * Events: track.started, track.stopped, track.updated, track.deleted;
+ * the data is the entry as JSON. Queued events are also delivered to the
+ * user's webhooks (see webhook_actions.go), and a stopped team entry
+ * checks its project's budget (see project_budget_actions.go).
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-20
+ */
+package actions
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"backend/models"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gofrs/uuid"
+)
+
+// Event names sent on the stream.
+const (
+	eventTrackStarted = "track.started"
+	eventTrackStopped = "track.stopped"
+	eventTrackUpdated = "track.updated"
+	eventTrackDeleted = "track.deleted"
+)
+
+// sseKeepAlive is the interval of keep-alive comments, below the common
+// 30s idle timeout of proxies.
+const sseKeepAlive = 25 * time.Second
+
+/**
+ * trackEvent is one message for a user's stream
+ */
+type trackEvent struct {
+	UserID uuid.UUID
+	Name   string
+	Entry  models.TimeTrac
+}
+
+/**
+ * eventHub fans events out to the open streams of each user
+ */
+type eventHub struct {
+	mu   sync.Mutex
+	subs map[uuid.UUID]map[chan trackEvent]struct{}
+}
+
+func newEventHub() *eventHub {
+	return &eventHub{subs: map[uuid.UUID]map[chan trackEvent]struct{}{}}
+}
+
+// trackEvents is the hub used by the app, created in App().
+var trackEvents *eventHub
+
+/**
+ * Subscribe opens a channel for the user's events. The returned function
+ * must be called to release it.
+ */
+func (h *eventHub) Subscribe(uid uuid.UUID) (<-chan trackEvent, func()) {
+	ch := make(chan trackEvent, 16)
+	h.mu.Lock()
+	if h.subs[uid] == nil {
+		h.subs[uid] = map[chan trackEvent]struct{}{}
+	}
+	h.subs[uid][ch] = struct{}{}
+	h.mu.Unlock()
+
+	return ch, func() {
+		h.mu.Lock()
+		delete(h.subs[uid], ch)
+		if len(h.subs[uid]) == 0 {
+			delete(h.subs, uid)
+		}
+		h.mu.Unlock()
+	}
+}
+
+/**
+ * Publish delivers an event to all streams of its user. Slow subscribers
+ * whose buffer is full miss the event rather than blocking the request.
+ */
+func (h *eventHub) Publish(ev trackEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs[ev.UserID] {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+/**
+ * queueTrackEvent records an event to publish once the request's
+ * transaction has committed (see afterCommit) and queues its webhook
+ * deliveries and budget alerts in that transaction
+ */
+func queueTrackEvent(c buffalo.Context, name string, item models.TimeTrac) {
+	ev := trackEvent{UserID: item.UserID, Name: name, Entry: item}
+	afterCommit(c, func() { trackEvents.Publish(ev) })
+	if tx, ok := c.Value("tx").(*pop.Connection); ok {
+		if err := queueWebhookDeliveries(tx, name, item, time.Now(), requestIDOf(c)); err != nil {
+			c.Logger().Errorf("webhook queue %s %s: %v", name, item.ID, err)
+		}
+		if name == eventTrackStopped {
+			checkProjectBudget(c, tx, item)
+		}
+	}
+}
+
+/**
+ * TracksEvents streams the user's timer events as Server-Sent Events
+ *
+ * GET /api/tracks/events
+ *
+ * Authentication uses the Bearer header or, because EventSource cannot
+ * set headers, a `token` query parameter; disabled and deactivated
+ * accounts are refused either way. The route runs without a
+ * request transaction so an open stream holds no database connection.
+ *
+ * A keep-alive comment is sent every 25 seconds.
+ *
+ * @param c - Buffalo context
+ * @return Event stream or error response
+ */
+func TracksEvents(c buffalo.Context) error {
+	raw := strings.TrimPrefix(c.Request().Header.Get("Authorization"), "Bearer ")
+	if raw == "" {
+		raw = c.Param("token")
+	}
+	if raw == "" {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "missing bearer token"}))
+	}
+	u, msg := userFromToken(models.DB, raw)
+	if msg != "" {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": msg}))
+	}
+	// Same account checks as AuthRequired
+	if u.DisabledAt.Valid {
+		return c.Render(http.StatusForbidden, r.JSON(map[string]string{"error": "account disabled"}))
+	}
+	if u.DeactivatedAt.Valid {
+		return renderDeactivated(c)
+	}
+
+	res := c.Response()
+	flusher, ok := res.(http.Flusher)
+	if !ok {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "streaming unsupported"}))
+	}
+
+	h := res.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	h.Set("X-Accel-Buffering", "no") // disable nginx buffering
+	res.WriteHeader(http.StatusOK)
+	fmt.Fprint(res, ": connected\n\n")
+	flusher.Flush()
+
+	events, unsubscribe := trackEvents.Subscribe(u.ID)
+	defer unsubscribe()
+
+	keepAlive := time.NewTicker(sseKeepAlive)
+	defer keepAlive.Stop()
+
+	done := c.Request().Context().Done()
+	for {
+		select {
+		case <-done:
+			return nil
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(res, ": keep-alive\n\n"); err != nil {
+				return nil
+			}
+			flusher.Flush()
+		case ev := <-events:
+			data, err := json.Marshal(ev.Entry)
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(res, "event: %s\ndata: %s\n\n", ev.Name, data); err != nil {
+				return nil
+			}
+			flusher.Flush()
+		}
+	}
+}
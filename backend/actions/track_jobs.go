@@ -0,0 +1,208 @@
+/**
+ * Track Jobs - Background Maintenance for Time Entries
+ *
+ * This file contains periodic jobs that operate on time tracking data
+ * outside of a request:
+ * - Auto-stopping forgotten running entries
+ * - Purging entries that have been in trash longer than the retention
+ *
+ * Jobs are exposed as plain functions so they can be run from a grift
+ * task (`buffalo task tracks:autostop`) and from the in-process ticker
+ * started by App().
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-20
+ */
+package actions
+
+import (
+	"strconv"
+	"time"
+
+	"backend/models"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/envy"
+	"github.com/gobuffalo/nulls"
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gofrs/uuid"
+)
+
+/**
+ * AutoStopForgottenTracks stops entries that have been running longer than
+ * their owner's max_running_hours preference
+ *
+ * The entry's end_at is set to start_at + limit, the `auto-stopped` tag is
+ * added and stopped_reason is recorded. The UPDATE ... RETURNING statement
+ * only matches rows that are still running, so concurrent runs on several
+ * instances never stop the same entry twice. After the commit the owner's
+ * devices are notified (see push.go).
+ *
+ * @param db - Database connection
+ * @param now - Reference time for the running-duration check
+ * @return int - Number of stopped entries
+ */
+func AutoStopForgottenTracks(db *pop.Connection, now time.Time) (int, error) {
+	var rows []struct {
+		ID     uuid.UUID  `db:"id"`
+		UserID uuid.UUID  `db:"user_id"`
+		EndAt  nulls.Time `db:"end_at"`
+	}
+	err := db.Transaction(func(tx *pop.Connection) error {
+		if err := tx.RawQuery(`
+			WITH limits AS (
+				SELECT t.id, make_interval(hours => COALESCE(up.max_running_hours, ?)) AS max_running
+				FROM timetrac t
+				LEFT JOIN user_preferences up ON up.user_id = t.user_id
+				WHERE t.end_at IS NULL AND t.deleted_at IS NULL
+			)
+			UPDATE timetrac t
+			SET end_at = t.start_at + l.max_running,
+			    tags = CASE WHEN ? = ANY(t.tags) THEN t.tags ELSE array_append(t.tags, ?) END,
+			    stopped_reason = ?,
+			    updated_at = ?
+			FROM limits l
+			WHERE t.id = l.id AND t.end_at IS NULL AND t.start_at + l.max_running < ?
+			RETURNING t.id, t.user_id, t.end_at
+		`, models.DefaultMaxRunningHours, models.AutoStoppedTag, models.AutoStoppedTag,
+			models.StopReasonAutoStopped, now, now).All(&rows); err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			if err := closeOpenPause(tx, row.ID, row.EndAt.Time); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, row := range rows {
+		notifyUser(db, row.UserID, autoStopTitle, autoStopBody, map[string]string{
+			"type":     "track.auto_stopped",
+			"track_id": row.ID.String(),
+		})
+	}
+	return len(rows), nil
+}
+
+// Text of the notification sent for auto-stopped entries.
+const (
+	autoStopTitle = "Your timer was stopped"
+	autoStopBody  = "A running entry passed your maximum running time and was stopped automatically."
+)
+
+/**
+ * PurgeTrashedTracks permanently deletes entries that were soft-deleted
+ * more than models.TrashRetention ago.
+ *
+ * @param db - Database connection
+ * @param now - Reference time for the retention check
+ * @return int - Number of purged entries
+ */
+func PurgeTrashedTracks(db *pop.Connection, now time.Time) (int, error) {
+	var purged []struct {
+		ID uuid.UUID `db:"id"`
+	}
+	err := db.RawQuery(`
+		DELETE FROM timetrac WHERE deleted_at IS NOT NULL AND deleted_at < ?
+		RETURNING id
+	`, now.Add(-models.TrashRetention)).All(&purged)
+	return len(purged), err
+}
+
+/**
+ * InvalidTrack is an existing entry that fails models.TimeTrac.Validate
+ */
+type InvalidTrack struct {
+	Entry  models.TimeTrac
+	Errors map[string]string
+}
+
+/**
+ * FindInvalidTracks lists stored entries (including trashed ones) that
+ * violate the model validation, for manual repair. Nothing is modified.
+ *
+ * @param db - Database connection
+ * @param now - Reference time for the start_at horizon
+ * @return []InvalidTrack - Violating entries, oldest first
+ */
+func FindInvalidTracks(db *pop.Connection, now time.Time) ([]InvalidTrack, error) {
+	var items []models.TimeTrac
+	err := db.Where(`user_id IS NULL OR end_at < start_at OR start_at < ? OR start_at > ?`,
+		now.Add(-models.TrackPastHorizon).UTC(), now.Add(models.TrackFutureHorizon).UTC(),
+	).Order("start_at ASC").All(&items)
+	if err != nil {
+		return nil, err
+	}
+
+	invalid := make([]InvalidTrack, 0, len(items))
+	for _, item := range items {
+		if verrs := item.ValidateAt(now); verrs.HasAny() {
+			invalid = append(invalid, InvalidTrack{Entry: item, Errors: invalidTrackError{verrs}.fields()})
+		}
+	}
+	return invalid, nil
+}
+
+/**
+ * startTrackJobs runs AutoStopForgottenTracks, PurgeTrashedTracks,
+ * PurgeExpiredIdempotencyKeys, PurgeWebhookDeliveries,
+ * PurgeExpiredRefreshTokens, PurgeMagicLinkTokens, PurgeLoginEvents and
+ * ExpireTeamInvitations periodically in the background. The interval is
+ * read from TRACK_JOBS_INTERVAL_MINUTES (default 15); a value of 0
+ * disables the ticker. A failing job is logged and does not hold up the
+ * others.
+ */
+func startTrackJobs(app *buffalo.App) {
+	minutes, err := strconv.Atoi(envy.Get("TRACK_JOBS_INTERVAL_MINUTES", "15"))
+	if err != nil || minutes <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(minutes) * time.Minute)
+		defer ticker.Stop()
+		for range ticker.C {
+			if n, err := AutoStopForgottenTracks(models.DB, time.Now()); err != nil {
+				app.Logger.Errorf("auto-stop: %v", err)
+			} else if n > 0 {
+				app.Logger.Infof("auto-stop: stopped %d forgotten entries", n)
+			}
+
+			if n, err := PurgeTrashedTracks(models.DB, time.Now()); err != nil {
+				app.Logger.Errorf("trash purge: %v", err)
+			} else if n > 0 {
+				app.Logger.Infof("trash purge: removed %d entries", n)
+			}
+
+			if _, err := PurgeExpiredIdempotencyKeys(models.DB, time.Now()); err != nil {
+				app.Logger.Errorf("idempotency purge: %v", err)
+			}
+
+			if _, err := PurgeWebhookDeliveries(models.DB, time.Now()); err != nil {
+				app.Logger.Errorf("webhook delivery purge: %v", err)
+			}
+
+			if _, err := PurgeExpiredRefreshTokens(models.DB, time.Now()); err != nil {
+				app.Logger.Errorf("refresh token purge: %v", err)
+			}
+
+			if _, err := PurgeMagicLinkTokens(models.DB, time.Now()); err != nil {
+				app.Logger.Errorf("magic link purge: %v", err)
+			}
+
+			if _, err := PurgeLoginEvents(models.DB, time.Now()); err != nil {
+				app.Logger.Errorf("login event purge: %v", err)
+			}
+
+			if _, err := ExpireTeamInvitations(models.DB, time.Now()); err != nil {
+				app.Logger.Errorf("invitation expiry: %v", err)
+			}
+		}
+	}()
+}
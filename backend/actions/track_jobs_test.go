@@ -0,0 +1,39 @@
+package actions
+
+import (
+	"net/http"
+	"time"
+
+	"backend/models"
+)
+
+func (as *ActionSuite) Test_AutoStopForgottenTracks() {
+	strictToken := as.registerToken("autostop-strict@example.com")
+	defaultToken := as.registerToken("autostop-default@example.com")
+	as.Equal(http.StatusUnprocessableEntity, as.authJSON(strictToken, "/api/me/preferences").Patch(map[string]int{"max_running_hours": 0}).Code)
+	as.Equal(http.StatusOK, as.authJSON(strictToken, "/api/me/preferences").Patch(map[string]int{"max_running_hours": 2}).Code)
+
+	// Both entries have been running for three hours
+	started := time.Now().Add(-3 * time.Hour).UTC().Truncate(time.Second)
+	strict, relaxed := as.startTrack(strictToken), as.startTrack(defaultToken)
+	as.NoError(as.DB.RawQuery("UPDATE timetrac SET start_at = ? WHERE id IN (?, ?)", started, strict.ID, relaxed.ID).Exec())
+
+	n, err := AutoStopForgottenTracks(as.DB, time.Now())
+	as.NoError(err)
+	as.Equal(1, n)
+
+	// Only the entry past its owner's limit stops, at start + limit
+	var item models.TimeTrac
+	as.NoError(as.DB.Find(&item, strict.ID))
+	as.True(item.EndAt.Valid)
+	as.True(item.EndAt.Time.Equal(started.Add(2 * time.Hour)))
+	as.Contains([]string(item.Tags), models.AutoStoppedTag)
+	as.Equal(models.StopReasonAutoStopped, item.StopReason.String)
+	as.NoError(as.DB.Find(&item, relaxed.ID))
+	as.False(item.EndAt.Valid)
+
+	// A second run finds nothing left to stop
+	n, err = AutoStopForgottenTracks(as.DB, time.Now())
+	as.NoError(err)
+	as.Zero(n)
+}
@@ -0,0 +1,192 @@
+/**
+ * Track Pause Actions - Pause and Resume for Running Entries
+ *
+ * This file provides the pause/resume endpoints and the helpers that keep
+ * computed durations free of break time:
+ * - Pausing and resuming a running entry
+ * - Closing open pauses when an entry is stopped
+ * - Attaching pause totals to entries before rendering
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-20
+ */
+package actions
+
+import (
+	"net/http"
+	"time"
+
+	"backend/models"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/nulls"
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gofrs/uuid"
+)
+
+/**
+ * trackNetSecondsSQL is the SQL expression for an entry's tracked seconds
+ * minus its pauses. It expects the timetrac table to be aliased as `t`.
+ * Running entries and open pauses are measured up to now(); a pause that
+ * ends before it starts counts as zero, as in TrackPauses.Total.
+ */
+const trackNetSecondsSQL = `GREATEST(
+	EXTRACT(EPOCH FROM (COALESCE(t.end_at, now()::timestamp) - t.start_at))
+	- COALESCE((
+		SELECT SUM(GREATEST(EXTRACT(EPOCH FROM (COALESCE(p.resumed_at, t.end_at, now()::timestamp) - p.paused_at)), 0))
+		FROM track_pauses p WHERE p.track_id = t.id
+	), 0), 0)`
+
+/**
+ * attachPauses loads the pauses for the given entries in one query and
+ * fills their computed duration fields.
+ *
+ * @param tx - Database transaction
+ * @param items - Entries to update in place
+ * @param now - Reference time for running entries and open pauses
+ */
+func attachPauses(tx *pop.Connection, items []models.TimeTrac, now time.Time) error {
+	if len(items) == 0 {
+		return nil
+	}
+	ids := make([]interface{}, 0, len(items))
+	for _, it := range items {
+		ids = append(ids, it.ID)
+	}
+
+	var pauses []models.TrackPause
+	if err := tx.Where("track_id in (?)", ids...).All(&pauses); err != nil {
+		return err
+	}
+	byTrack := map[uuid.UUID]models.TrackPauses{}
+	for _, p := range pauses {
+		byTrack[p.TrackID] = append(byTrack[p.TrackID], p)
+	}
+	for i := range items {
+		items[i].ApplyPauses(byTrack[items[i].ID], now)
+	}
+	return nil
+}
+
+/**
+ * closeOpenPause ends the entry's open pause (if any) at the given time.
+ * Used when an entry is stopped while paused. A time before the pause
+ * started, as a retroactive stop can give, is clamped to paused_at.
+ */
+func closeOpenPause(tx *pop.Connection, trackID uuid.UUID, at time.Time) error {
+	return tx.RawQuery(`
+		UPDATE track_pauses SET resumed_at = GREATEST(?, paused_at), updated_at = ?
+		WHERE track_id = ? AND resumed_at IS NULL
+	`, at, at, trackID).Exec()
+}
+
+/**
+ * TracksPause pauses a running time tracking entry
+ *
+ * POST /api/tracks/{id}/pause
+ *
+ * Opens a pause starting now. Pause time is excluded from the entry's
+ * computed duration and from summaries.
+ *
+ * Responses:
+ * - 200 with the entry and its computed durations
+ * - 409 if the entry is not running or is already paused
+ *
+ * @param c - Buffalo context with authenticated user and entry ID
+ * @return JSON TimeTrac entry or error response
+ */
+func TracksPause(c buffalo.Context) error {
+	tx := mustTx(c)
+	uid, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+
+	id, err := uuid.FromString(c.Param("id"))
+	if err != nil {
+		return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "bad id"}))
+	}
+	var item models.TimeTrac
+	if err := tx.Where("id = ? AND user_id = ? AND deleted_at IS NULL", id, uid).First(&item); err != nil {
+		return c.Render(http.StatusNotFound, r.JSON(map[string]string{"error": "not found"}))
+	}
+	if item.EndAt.Valid {
+		return c.Render(http.StatusConflict, r.JSON(map[string]string{"error": "entry is not running"}))
+	}
+
+	open, err := tx.Where("track_id = ? AND resumed_at IS NULL", item.ID).Exists(&models.TrackPause{})
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+	if open {
+		return c.Render(http.StatusConflict, r.JSON(map[string]string{"error": "entry is already paused"}))
+	}
+
+	now := time.Now()
+	pause := models.TrackPause{TrackID: item.ID, PausedAt: now}
+	if err := tx.Create(&pause); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot pause"}))
+	}
+
+	items := []models.TimeTrac{item}
+	if err := attachPauses(tx, items, now); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+	queueTrackEvent(c, eventTrackUpdated, items[0])
+	return c.Render(http.StatusOK, r.JSON(items[0]))
+}
+
+/**
+ * TracksResume resumes a paused time tracking entry
+ *
+ * POST /api/tracks/{id}/resume
+ *
+ * Responses:
+ * - 200 with the entry and its computed durations
+ * - 409 if the entry is not running or not paused
+ *
+ * @param c - Buffalo context with authenticated user and entry ID
+ * @return JSON TimeTrac entry or error response
+ */
+func TracksResume(c buffalo.Context) error {
+	tx := mustTx(c)
+	uid, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+
+	id, err := uuid.FromString(c.Param("id"))
+	if err != nil {
+		return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "bad id"}))
+	}
+	var item models.TimeTrac
+	if err := tx.Where("id = ? AND user_id = ? AND deleted_at IS NULL", id, uid).First(&item); err != nil {
+		return c.Render(http.StatusNotFound, r.JSON(map[string]string{"error": "not found"}))
+	}
+	if item.EndAt.Valid {
+		return c.Render(http.StatusConflict, r.JSON(map[string]string{"error": "entry is not running"}))
+	}
+
+	var pause models.TrackPause
+	if err := tx.Where("track_id = ? AND resumed_at IS NULL", item.ID).First(&pause); err != nil {
+		return c.Render(http.StatusConflict, r.JSON(map[string]string{"error": "entry is not paused"}))
+	}
+
+	now := time.Now()
+	pause.ResumedAt = nulls.NewTime(now)
+	if now.Before(pause.PausedAt) {
+		pause.ResumedAt = nulls.NewTime(pause.PausedAt)
+	}
+	pause.UpdatedAt = now
+	if err := tx.Update(&pause); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot resume"}))
+	}
+
+	items := []models.TimeTrac{item}
+	if err := attachPauses(tx, items, now); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+	queueTrackEvent(c, eventTrackUpdated, items[0])
+	return c.Render(http.StatusOK, r.JSON(items[0]))
+}
@@ -0,0 +1,406 @@
+/**
+ * Track Photo Actions - Photo Attachments for Time Entries
+ *
+ * This file handles photo uploads as multipart/form-data, replacing the
+ * deprecated base64 `photo_data` field in the TracksStart payload:
+ * - Uploading a photo for an entry
+ * - Serving an entry's photo
+ * - Removing an entry's photo
+ *
+ * Photos are kept in the storage backend selected by PHOTO_STORAGE (see
+ * package storage); the entry only records the object key.
+ *
+ * Uploaded files are validated by sniffing their content, never by the
+ * client-supplied Content-Type.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-20
+ */
+package actions
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"backend/models"
+	"backend/storage"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/nulls"
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gofrs/uuid"
+)
+
+/**
+ * allowedPhotoTypes lists the MIME types accepted for entry photos
+ */
+var allowedPhotoTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/webp": true,
+}
+
+/**
+ * photoExtensions maps accepted MIME types to storage key extensions
+ */
+var photoExtensions = map[string]string{
+	"image/jpeg": "jpg",
+	"image/png":  "png",
+	"image/webp": "webp",
+}
+
+// photoURLExpiry is how long signed photo URLs stay valid.
+const photoURLExpiry = 15 * time.Minute
+
+var (
+	photoStoreOnce sync.Once
+	photoStoreVal  storage.Store
+	photoStoreErr  error
+)
+
+/**
+ * photoStore returns the storage backend for photos, configured from the
+ * environment on first use.
+ */
+func photoStore() (storage.Store, error) {
+	photoStoreOnce.Do(func() {
+		photoStoreVal, photoStoreErr = storage.FromEnv()
+	})
+	return photoStoreVal, photoStoreErr
+}
+
+/**
+ * photoKey builds a fresh storage key for an entry's photo
+ *
+ * @param trackID - Entry the photo belongs to
+ * @param mime - Accepted photo MIME type
+ * @return string - Key of the form tracks/{id}/{uuid}.{ext}
+ */
+func photoKey(trackID uuid.UUID, mime string) string {
+	return fmt.Sprintf("tracks/%s/%s.%s", trackID, uuid.Must(uuid.NewV4()), photoExtensions[mime])
+}
+
+/**
+ * photoURL returns the API path serving an entry's photo
+ */
+func photoURL(trackID uuid.UUID) string {
+	return "/api/tracks/" + trackID.String() + "/photo"
+}
+
+/**
+ * StorePhoto uploads photo bytes for an entry and points the entry at the
+ * new object. The entry is not saved; the caller updates it and then
+ * removes the returned previous key (if any) from the store.
+ *
+ * @param st - Storage backend
+ * @param item - Entry to attach the photo to
+ * @param data - Validated photo content
+ * @param mime - Accepted photo MIME type
+ * @return string - Previous storage key, "" if none
+ */
+func StorePhoto(st storage.Store, item *models.TimeTrac, data []byte, mime string) (string, error) {
+	key := photoKey(item.ID, mime)
+	if err := st.Put(context.Background(), key, bytes.NewReader(data), int64(len(data)), mime); err != nil {
+		return "", err
+	}
+	prev := item.PhotoKey.String
+	item.PhotoKey = nulls.NewString(key)
+	item.PhotoURL = nulls.NewString(photoURL(item.ID))
+	item.PhotoData = nulls.String{}
+	return prev, nil
+}
+
+/**
+ * photoMaxBytes returns the upload size limit from PHOTO_MAX_BYTES
+ * (default 5 MB)
+ */
+func photoMaxBytes() int64 {
+	if v := os.Getenv("PHOTO_MAX_BYTES"); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil && n > 0 {
+			return n
+		}
+	}
+	return 5 << 20
+}
+
+/**
+ * sniffPhotoType detects the MIME type of an image from its content
+ *
+ * @param data - File content (at least the first 512 bytes are inspected)
+ * @return string - Detected MIME type
+ * @return bool - True if the type is an accepted photo type
+ */
+func sniffPhotoType(data []byte) (string, bool) {
+	mime := http.DetectContentType(data)
+	return mime, allowedPhotoTypes[mime]
+}
+
+/**
+ * markPhotoDataDeprecated flags responses to requests that still send the
+ * base64 `photo_data` field.
+ */
+func markPhotoDataDeprecated(c buffalo.Context) {
+	c.Response().Header().Set("Deprecation", "true")
+	c.Response().Header().Set("Warning", `299 - "photo_data is deprecated, upload via POST /api/tracks/{id}/photo"`)
+}
+
+/**
+ * TracksUploadPhoto attaches a photo to a time tracking entry
+ *
+ * POST /api/tracks/{id}/photo
+ *
+ * Accepts multipart/form-data with the image in the `photo` field.
+ * JPEG, PNG and WebP are accepted, detected from the file content. The
+ * size limit is configured with PHOTO_MAX_BYTES. An existing photo is
+ * replaced.
+ *
+ * Responses:
+ * - 200 with the updated entry
+ * - 413 if the file is larger than the limit
+ * - 415 if the content is not an accepted image type
+ *
+ * @param c - Buffalo context with authenticated user, entry ID and file
+ * @return JSON TimeTrac entry or error response
+ */
+func TracksUploadPhoto(c buffalo.Context) error {
+	id, err := uuid.FromString(c.Param("id"))
+	if err != nil {
+		return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "bad id"}))
+	}
+
+	tx := mustTx(c)
+	uid, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+
+	var item models.TimeTrac
+	if err := tx.Where("id = ? AND user_id = ? AND deleted_at IS NULL", id, uid).First(&item); err != nil {
+		return c.Render(http.StatusNotFound, r.JSON(map[string]string{"error": "not found"}))
+	}
+
+	data, mime, status, msg := readImageUpload(c, "photo", allowedPhotoTypes)
+	if status == http.StatusUnsupportedMediaType {
+		return c.Render(status, r.JSON(map[string]string{"error": msg, "type": mime}))
+	}
+	if status != 0 {
+		return c.Render(status, r.JSON(map[string]string{"error": msg}))
+	}
+
+	st, err := photoStore()
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "photo storage unavailable"}))
+	}
+	prev, err := StorePhoto(st, &item, data, mime)
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot store photo"}))
+	}
+	item.UpdatedAt = time.Now()
+	if err := tx.Update(&item); err != nil {
+		_ = st.Delete(c, item.PhotoKey.String)
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot save photo"}))
+	}
+	if prev != "" {
+		// Best effort: an orphaned object is harmless
+		_ = st.Delete(c, prev)
+	}
+	return c.Render(http.StatusOK, r.JSON(item))
+}
+
+/**
+ * TracksGetPhoto serves the photo attached to a time tracking entry
+ *
+ * GET /api/tracks/{id}/photo
+ *
+ * Redirects to a short-lived signed URL when the storage backend supports
+ * it (S3), otherwise streams the image through the API (disk).
+ *
+ * Responses:
+ * - 302 to a signed URL, or 200 with the image
+ * - 404 if the entry or its photo does not exist
+ *
+ * @param c - Buffalo context with authenticated user and entry ID
+ * @return Image, redirect or error response
+ */
+func TracksGetPhoto(c buffalo.Context) error {
+	id, err := uuid.FromString(c.Param("id"))
+	if err != nil {
+		return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "bad id"}))
+	}
+
+	tx := mustTx(c)
+	uid, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+
+	var item models.TimeTrac
+	if err := tx.Where("id = ? AND user_id = ? AND deleted_at IS NULL", id, uid).First(&item); err != nil {
+		return c.Render(http.StatusNotFound, r.JSON(map[string]string{"error": "not found"}))
+	}
+	if !item.PhotoKey.Valid {
+		return c.Render(http.StatusNotFound, r.JSON(map[string]string{"error": "no photo"}))
+	}
+
+	return serveStoredImage(c, item.PhotoKey.String, "photo")
+}
+
+/**
+ * serveStoredImage answers with an image from the photo store: a
+ * redirect to a short-lived signed URL when the backend supports it
+ * (S3), otherwise the image streamed through the API (disk)
+ *
+ * @param c - Buffalo context
+ * @param key - Storage key; its extension gives the Content-Type
+ * @param what - Name of the image in error messages
+ * @return Image, redirect or error response
+ */
+func serveStoredImage(c buffalo.Context, key, what string) error {
+	st, err := photoStore()
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": what + " storage unavailable"}))
+	}
+	if u, err := st.SignedURL(c, key, photoURLExpiry); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot sign " + what + " url"}))
+	} else if u != "" {
+		return c.Redirect(http.StatusFound, u)
+	}
+
+	body, err := st.Get(c, key)
+	if errors.Is(err, storage.ErrNotFound) {
+		return c.Render(http.StatusNotFound, r.JSON(map[string]string{"error": "no " + what}))
+	}
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot read " + what}))
+	}
+	defer body.Close()
+
+	mime := "application/octet-stream"
+	for m, ext := range photoExtensions {
+		if strings.HasSuffix(key, "."+ext) {
+			mime = m
+		}
+	}
+	c.Response().Header().Set("Content-Type", mime)
+	c.Response().Header().Set("Cache-Control", "private, max-age=300")
+	c.Response().WriteHeader(http.StatusOK)
+	_, err = io.Copy(c.Response(), body)
+	return err
+}
+
+/**
+ * TracksDeletePhoto removes the photo attachment of a time tracking entry
+ *
+ * DELETE /api/tracks/{id}/photo
+ *
+ * @param c - Buffalo context with authenticated user and entry ID
+ * @return JSON TimeTrac entry or error response
+ */
+func TracksDeletePhoto(c buffalo.Context) error {
+	id, err := uuid.FromString(c.Param("id"))
+	if err != nil {
+		return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "bad id"}))
+	}
+
+	tx := mustTx(c)
+	uid, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+
+	var item models.TimeTrac
+	if err := tx.Where("id = ? AND user_id = ? AND deleted_at IS NULL", id, uid).First(&item); err != nil {
+		return c.Render(http.StatusNotFound, r.JSON(map[string]string{"error": "not found"}))
+	}
+
+	prev := item.PhotoKey.String
+	item.PhotoData = nulls.String{}
+	item.PhotoKey = nulls.String{}
+	item.PhotoURL = nulls.String{}
+	item.UpdatedAt = time.Now()
+	if err := tx.Update(&item); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot delete photo"}))
+	}
+	if prev != "" {
+		if st, err := photoStore(); err == nil {
+			_ = st.Delete(c, prev)
+		}
+	}
+	return c.Render(http.StatusOK, r.JSON(item))
+}
+
+/**
+ * decodePhotoData decodes a legacy photo_data value, either a data URL
+ * (`data:image/png;base64,...`) or bare base64.
+ */
+func decodePhotoData(v string) ([]byte, error) {
+	if strings.HasPrefix(v, "data:") {
+		i := strings.Index(v, ",")
+		if i < 0 {
+			return nil, errors.New("malformed data URL")
+		}
+		v = v[i+1:]
+	}
+	return base64.StdEncoding.DecodeString(strings.TrimSpace(v))
+}
+
+/**
+ * MigratePhotoData moves legacy base64 photos from the photo_data column
+ * into the photo store and clears the column. Entries whose data cannot be
+ * decoded or is not an accepted image are left untouched and counted as
+ * skipped.
+ *
+ * @param db - Database connection
+ * @return int - Number of entries migrated
+ * @return int - Number of entries skipped
+ */
+func MigratePhotoData(db *pop.Connection) (int, int, error) {
+	st, err := photoStore()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var items []models.TimeTrac
+	if err := db.Where("photo_data IS NOT NULL AND photo_data <> ''").All(&items); err != nil {
+		return 0, 0, err
+	}
+
+	migrated, skipped := 0, 0
+	for i := range items {
+		item := &items[i]
+		data, err := decodePhotoData(item.PhotoData.String)
+		if err != nil {
+			skipped++
+			continue
+		}
+		mime, ok := sniffPhotoType(data)
+		if !ok {
+			skipped++
+			continue
+		}
+		prev, err := StorePhoto(st, item, data, mime)
+		if err != nil {
+			return migrated, skipped, err
+		}
+		if err := db.Update(item); err != nil {
+			_ = st.Delete(context.Background(), item.PhotoKey.String)
+			return migrated, skipped, err
+		}
+		if prev != "" {
+			_ = st.Delete(context.Background(), prev)
+		}
+		migrated++
+	}
+	return migrated, skipped, nil
+}
@@ -0,0 +1,505 @@
+/**
+ * Track Stats Actions - Aggregated Statistics for Dashboards
+ *
+ * This file provides read-only statistics computed entirely in SQL, so
+ * results stay correct for users with many entries:
+ * - Comparing the current week or month with the previous one
+ * - Totals per tag
+ * - Totals per issue reference
+ * - Tracked time by weekday and hour of day
+ *
+ * Period boundaries follow the `tz` parameter or the user's timezone
+ * preference (see requestLocation).
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-20
+ */
+package actions
+
+import (
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"backend/models"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gofrs/uuid"
+)
+
+/**
+ * periodBounds returns the current calendar period containing now and
+ * the one before it, in loc
+ *
+ * Weeks are ISO weeks starting on Monday; months are calendar months.
+ *
+ * @param now - Reference time
+ * @param loc - Time zone for the boundaries
+ * @param period - "week" or "month"
+ * @return time.Time - Start of the current period (= end of the previous one)
+ * @return time.Time - End of the current period
+ * @return time.Time - Start of the previous period
+ */
+func periodBounds(now time.Time, loc *time.Location, period string) (curStart, curEnd, prevStart time.Time) {
+	day := startOfDay(now, loc)
+	if period == "month" {
+		curStart = time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, loc)
+		return curStart, curStart.AddDate(0, 1, 0), curStart.AddDate(0, -1, 0)
+	}
+	// time.Weekday counts from Sunday; ISO weeks start on Monday
+	offset := (int(day.Weekday()) + 6) % 7
+	curStart = day.AddDate(0, 0, -offset)
+	return curStart, curStart.AddDate(0, 0, 7), curStart.AddDate(0, 0, -7)
+}
+
+/**
+ * requestRange reads the optional RFC3339 `from` and `to` parameters
+ *
+ * @param c - Buffalo context
+ * @param from - Default start
+ * @param to - Default end
+ * @return time.Time - Effective start
+ * @return time.Time - Effective end
+ * @return string - Error message for a 400 response, "" if valid
+ */
+func requestRange(c buffalo.Context, from, to time.Time) (time.Time, time.Time, string) {
+	if v := c.Param("from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return from, to, "bad from"
+		}
+		from = t
+	}
+	if v := c.Param("to"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return from, to, "bad to"
+		}
+		to = t
+	}
+	return from, to, ""
+}
+
+/**
+ * percentDelta returns the relative change from prev to cur in percent,
+ * rounded to one decimal, or nil when prev is zero
+ */
+func percentDelta(cur, prev float64) *float64 {
+	if prev == 0 {
+		return nil
+	}
+	d := math.Round((cur-prev)/prev*1000) / 10
+	return &d
+}
+
+/**
+ * TracksStatsCompare compares the current period with the previous one
+ *
+ * GET /api/tracks/stats/compare?period=week|month&tz=<IANA zone>
+ *
+ * The current period is the ISO week (default) or calendar month
+ * containing now; entries are assigned by start_at. Durations exclude
+ * pause time and running entries count up to now.
+ *
+ * Response:
+ * - period, timezone
+ * - current, previous: {from, to, total_seconds, entries, projects}
+ * - delta_percent: {total_seconds, entries, projects}; a value is null
+ *   when the previous period is zero
+ * - goal: current week's {goal_minutes, progress_percent,
+ *   remaining_minutes}, see currentWeekGoal
+ *
+ * @param c - Buffalo context with authenticated user
+ * @return JSON comparison or error response
+ */
+func TracksStatsCompare(c buffalo.Context) error {
+	tx := mustTx(c)
+	uid, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+
+	period := c.Param("period")
+	if period == "" {
+		period = "week"
+	}
+	if period != "week" && period != "month" {
+		return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "period must be week or month"}))
+	}
+
+	loc, badTz, err := requestLocation(c, tx, uid)
+	if err != nil {
+		if badTz != "" {
+			return renderBadTimezone(c, badTz)
+		}
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+	curStart, curEnd, prevStart := periodBounds(time.Now(), loc, period)
+
+	type periodTotal struct {
+		Current      bool      `db:"current" json:"-"`
+		From         time.Time `db:"-" json:"from"`
+		To           time.Time `db:"-" json:"to"`
+		TotalSeconds float64   `db:"total_seconds" json:"total_seconds"`
+		Entries      int       `db:"entries" json:"entries"`
+		Projects     int       `db:"projects" json:"projects"`
+	}
+	rows := []periodTotal{}
+	if err := tx.RawQuery(`
+		SELECT t.start_at >= ? AS current,
+		       COALESCE(SUM(`+trackNetSecondsSQL+`), 0) AS total_seconds,
+		       COUNT(*) AS entries,
+		       COUNT(DISTINCT NULLIF(t.project, '')) AS projects
+		FROM timetrac t
+		WHERE t.user_id = ? AND t.deleted_at IS NULL AND t.start_at >= ? AND t.start_at < ?
+		GROUP BY 1
+	`, curStart.UTC(), uid, prevStart.UTC(), curEnd.UTC()).All(&rows); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+
+	cur := periodTotal{From: curStart, To: curEnd}
+	prev := periodTotal{From: prevStart, To: curStart}
+	for _, row := range rows {
+		if row.Current {
+			cur.TotalSeconds, cur.Entries, cur.Projects = row.TotalSeconds, row.Entries, row.Projects
+		} else {
+			prev.TotalSeconds, prev.Entries, prev.Projects = row.TotalSeconds, row.Entries, row.Projects
+		}
+	}
+
+	goal, err := currentWeekGoal(tx, uid, loc, time.Now())
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+
+	return c.Render(http.StatusOK, r.JSON(map[string]any{
+		"goal":     goal,
+		"period":   period,
+		"timezone": loc.String(),
+		"current":  cur,
+		"previous": prev,
+		"delta_percent": map[string]*float64{
+			"total_seconds": percentDelta(cur.TotalSeconds, prev.TotalSeconds),
+			"entries":       percentDelta(float64(cur.Entries), float64(prev.Entries)),
+			"projects":      percentDelta(float64(cur.Projects), float64(prev.Projects)),
+		},
+	}))
+}
+
+/**
+ * untaggedBucket labels time of entries without tags in TracksTagsSummary
+ */
+const untaggedBucket = "(untagged)"
+
+/**
+ * TracksTagsSummary returns tracked time per tag
+ *
+ * GET /api/tracks/tags/summary?from=<RFC3339>&to=<RFC3339>&tz=<IANA zone>
+ *
+ * Entries are included by start_at within [from, to); the default period
+ * is the last 7 calendar days including today, as in TracksSummary.
+ * Durations exclude pause time and running entries count up to now.
+ *
+ * An entry with several tags counts fully toward each of them, so the
+ * per-tag totals can add up to more than total_seconds; `overlapping` is
+ * true whenever that happens in the period. Entries without tags are
+ * reported under "(untagged)".
+ *
+ * Response:
+ * - from, to, timezone
+ * - total_seconds: Tracked time in the period, each entry counted once
+ * - overlapping: Some entry in the period has more than one tag
+ * - tags: [{ tag, total_seconds, entry_count }] ordered by total_seconds desc
+ *
+ * @param c - Buffalo context with authenticated user
+ * @return JSON tag summary or error response
+ */
+func TracksTagsSummary(c buffalo.Context) error {
+	tx := mustTx(c)
+	uid, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+
+	loc, badTz, err := requestLocation(c, tx, uid)
+	if err != nil {
+		if badTz != "" {
+			return renderBadTimezone(c, badTz)
+		}
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+	to := time.Now().In(loc)
+	from, to, msg := requestRange(c, startOfDay(to, loc).AddDate(0, 0, -6), to)
+	if msg != "" {
+		return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": msg}))
+	}
+
+	type tagTotal struct {
+		Tag          string  `db:"tag" json:"tag"`
+		TotalSeconds float64 `db:"total_seconds" json:"total_seconds"`
+		EntryCount   int     `db:"entry_count" json:"entry_count"`
+	}
+	tags := []tagTotal{}
+	if err := tx.RawQuery(`
+		SELECT tag,
+		       COALESCE(SUM(`+trackNetSecondsSQL+`), 0) AS total_seconds,
+		       COUNT(*) AS entry_count
+		FROM timetrac t
+		CROSS JOIN LATERAL unnest(
+			CASE WHEN COALESCE(cardinality(t.tags), 0) = 0 THEN ARRAY[?]::text[] ELSE t.tags END
+		) AS tag
+		WHERE t.user_id = ? AND t.deleted_at IS NULL AND t.start_at >= ? AND t.start_at < ?
+		GROUP BY tag
+		ORDER BY total_seconds DESC, tag
+	`, untaggedBucket, uid, from.UTC(), to.UTC()).All(&tags); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+
+	var totals struct {
+		TotalSeconds float64 `db:"total_seconds"`
+		MultiTagged  int     `db:"multi_tagged"`
+	}
+	if err := tx.RawQuery(`
+		SELECT COALESCE(SUM(`+trackNetSecondsSQL+`), 0) AS total_seconds,
+		       COUNT(*) FILTER (WHERE cardinality(t.tags) > 1) AS multi_tagged
+		FROM timetrac t
+		WHERE t.user_id = ? AND t.deleted_at IS NULL AND t.start_at >= ? AND t.start_at < ?
+	`, uid, from.UTC(), to.UTC()).First(&totals); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+
+	return c.Render(http.StatusOK, r.JSON(map[string]any{
+		"from":          from,
+		"to":            to,
+		"timezone":      loc.String(),
+		"total_seconds": totals.TotalSeconds,
+		"overlapping":   totals.MultiTagged > 0,
+		"tags":          tags,
+	}))
+}
+
+/**
+ * TracksRefsSummary returns tracked time per issue reference
+ *
+ * GET /api/tracks/refs/summary?from=<RFC3339>&to=<RFC3339>&tz=<IANA zone>
+ *
+ * Entries are included by start_at within [from, to); the default period
+ * is the last 7 calendar days including today, as in TracksSummary.
+ * Durations exclude pause time and running entries count up to now.
+ * Entries without external_ref are not included.
+ *
+ * Response:
+ * - from, to, timezone
+ * - refs: [{ external_ref, total_seconds, entry_count }] ordered by
+ *   total_seconds desc
+ *
+ * @param c - Buffalo context with authenticated user
+ * @return JSON reference summary or error response
+ */
+func TracksRefsSummary(c buffalo.Context) error {
+	tx := mustTx(c)
+	uid, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+
+	loc, badTz, err := requestLocation(c, tx, uid)
+	if err != nil {
+		if badTz != "" {
+			return renderBadTimezone(c, badTz)
+		}
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+	to := time.Now().In(loc)
+	from, to, msg := requestRange(c, startOfDay(to, loc).AddDate(0, 0, -6), to)
+	if msg != "" {
+		return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": msg}))
+	}
+
+	type refTotal struct {
+		ExternalRef  string  `db:"external_ref" json:"external_ref"`
+		TotalSeconds float64 `db:"total_seconds" json:"total_seconds"`
+		EntryCount   int     `db:"entry_count" json:"entry_count"`
+	}
+	refs := []refTotal{}
+	if err := tx.RawQuery(`
+		SELECT t.external_ref,
+		       COALESCE(SUM(`+trackNetSecondsSQL+`), 0) AS total_seconds,
+		       COUNT(*) AS entry_count
+		FROM timetrac t
+		WHERE t.user_id = ? AND t.deleted_at IS NULL AND t.external_ref IS NOT NULL
+		  AND t.start_at >= ? AND t.start_at < ?
+		GROUP BY t.external_ref
+		ORDER BY total_seconds DESC, t.external_ref
+	`, uid, from.UTC(), to.UTC()).All(&refs); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+
+	return c.Render(http.StatusOK, r.JSON(map[string]any{
+		"from":     from,
+		"to":       to,
+		"timezone": loc.String(),
+		"refs":     refs,
+	}))
+}
+
+/**
+ * hourMatrix holds tracked seconds by ISO weekday (0 = Monday) and local
+ * hour of day
+ */
+type hourMatrix [7][24]float64
+
+/**
+ * activeSpans returns the tracked parts of [start, end): the range minus
+ * the given pauses, with open pauses lasting until end
+ *
+ * @param start - Entry start
+ * @param end - Entry end (now for running entries)
+ * @param pauses - Pauses of the entry, in any order
+ * @return [][2]time.Time - Non-overlapping spans in chronological order
+ */
+func activeSpans(start, end time.Time, pauses models.TrackPauses) [][2]time.Time {
+	sorted := append(models.TrackPauses{}, pauses...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].PausedAt.Before(sorted[j].PausedAt) })
+
+	spans := [][2]time.Time{}
+	cur := start
+	for _, p := range sorted {
+		pauseEnd := end
+		if p.ResumedAt.Valid && p.ResumedAt.Time.Before(end) {
+			pauseEnd = p.ResumedAt.Time
+		}
+		if p.PausedAt.After(cur) {
+			until := p.PausedAt
+			if until.After(end) {
+				until = end
+			}
+			spans = append(spans, [2]time.Time{cur, until})
+		}
+		if pauseEnd.After(cur) {
+			cur = pauseEnd
+		}
+		if !cur.Before(end) {
+			return spans
+		}
+	}
+	if cur.Before(end) {
+		spans = append(spans, [2]time.Time{cur, end})
+	}
+	return spans
+}
+
+/**
+ * add distributes [from, to) over the local hours it covers in loc
+ *
+ * The walk advances to the next local hour boundary rather than building
+ * wall-clock times, so the repeated hour at the end of DST is counted
+ * twice in its bucket and the skipped hour at the start gets nothing.
+ */
+func (m *hourMatrix) add(from, to time.Time, loc *time.Location) {
+	for t := from; t.Before(to); {
+		lt := t.In(loc)
+		next := t.Add(time.Hour -
+			time.Duration(lt.Minute())*time.Minute -
+			time.Duration(lt.Second())*time.Second -
+			time.Duration(lt.Nanosecond()))
+		if next.After(to) {
+			next = to
+		}
+		m[(int(lt.Weekday())+6)%7][lt.Hour()] += next.Sub(t).Seconds()
+		t = next
+	}
+}
+
+/**
+ * TracksStatsDistribution returns tracked time by weekday and hour of day
+ *
+ * GET /api/tracks/stats/distribution?from=<RFC3339>&to=<RFC3339>&tz=<IANA zone>
+ *
+ * The default period is the last 90 calendar days including today. Entries
+ * overlapping the period are clipped to it and split across every local
+ * hour they cover; pause time is excluded and running entries count up
+ * to now.
+ *
+ * Response:
+ * - from, to, timezone
+ * - weekdays: ["mon", ..., "sun"], the row labels
+ * - matrix: 7 rows (Monday first) of 24 hourly totals in seconds
+ * - total_seconds: Sum of the matrix
+ *
+ * @param c - Buffalo context with authenticated user
+ * @return JSON distribution or error response
+ */
+func TracksStatsDistribution(c buffalo.Context) error {
+	tx := mustTx(c)
+	uid, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+
+	loc, badTz, err := requestLocation(c, tx, uid)
+	if err != nil {
+		if badTz != "" {
+			return renderBadTimezone(c, badTz)
+		}
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+	now := time.Now()
+	from, to, msg := requestRange(c, startOfDay(now.In(loc), loc).AddDate(0, 0, -89), now.In(loc))
+	if msg != "" {
+		return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": msg}))
+	}
+
+	items := []models.TimeTrac{}
+	if err := tx.Where("user_id = ? AND deleted_at IS NULL AND start_at < ? AND (end_at IS NULL OR end_at > ?)",
+		uid, to.UTC(), from.UTC()).All(&items); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+
+	byTrack := map[uuid.UUID]models.TrackPauses{}
+	if len(items) > 0 {
+		ids := make([]interface{}, 0, len(items))
+		for _, it := range items {
+			ids = append(ids, it.ID)
+		}
+		var pauses []models.TrackPause
+		if err := tx.Where("track_id in (?)", ids...).All(&pauses); err != nil {
+			return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+		}
+		for _, p := range pauses {
+			byTrack[p.TrackID] = append(byTrack[p.TrackID], p)
+		}
+	}
+
+	var m hourMatrix
+	var total float64
+	for _, it := range items {
+		end := now
+		if it.EndAt.Valid {
+			end = it.EndAt.Time
+		}
+		for _, span := range activeSpans(it.StartAt, end, byTrack[it.ID]) {
+			if span[0].Before(from) {
+				span[0] = from
+			}
+			if span[1].After(to) {
+				span[1] = to
+			}
+			if span[0].Before(span[1]) {
+				m.add(span[0], span[1], loc)
+				total += span[1].Sub(span[0]).Seconds()
+			}
+		}
+	}
+
+	return c.Render(http.StatusOK, r.JSON(map[string]any{
+		"from":          from,
+		"to":            to,
+		"timezone":      loc.String(),
+		"weekdays":      []string{"mon", "tue", "wed", "thu", "fri", "sat", "sun"},
+		"matrix":        m,
+		"total_seconds": total,
+	}))
+}
@@ -0,0 +1,377 @@
+/**
+ * Track Sync Actions - Offline Batch Synchronization
+ *
+ * The mobile app records changes while offline and uploads them in one
+ * batch when it reconnects. Each operation carries the updated_at the
+ * device last saw (`base_updated_at`); an operation on an entry that was
+ * changed on the server since then is reported as a conflict together
+ * with the server copy instead of overwriting it.
+ *
+ * Operations run in the request transaction, each inside its own
+ * savepoint, so a failing operation is undone without affecting the
+ * others.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-20
+ */
+package actions
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"backend/models"
+	"backend/validators"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/nulls"
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gofrs/uuid"
+	"github.com/lib/pq"
+)
+
+// syncMaxOperations bounds the size of one sync batch.
+const syncMaxOperations = 500
+
+// Sync operation result statuses.
+const (
+	syncApplied  = "applied"
+	syncConflict = "conflict"
+	syncError    = "error"
+)
+
+/**
+ * syncFields holds the entry fields an operation may set; nil fields are
+ * left unchanged
+ */
+type syncFields struct {
+	Project      *string    `json:"project"`
+	Tags         *[]string  `json:"tags"`
+	Note         *string    `json:"note"`
+	Color        *string    `json:"color"`
+	LocationLat  *float64   `json:"location_lat"`
+	LocationLng  *float64   `json:"location_lng"`
+	LocationAddr *string    `json:"location_addr"`
+	StartAt      *time.Time `json:"start_at"`
+	EndAt        *time.Time `json:"end_at"`
+}
+
+/**
+ * syncOperation is one queued offline change
+ */
+type syncOperation struct {
+	Type          string     `json:"type"` // create | update | delete | stop
+	ID            uuid.UUID  `json:"id"`   // Client-generated entry UUID
+	BaseUpdatedAt *time.Time `json:"base_updated_at"`
+	Data          syncFields `json:"data"`
+}
+
+/**
+ * syncResult is the outcome of one operation
+ */
+type syncResult struct {
+	ID     uuid.UUID        `json:"id"`
+	Type   string           `json:"type"`
+	Status string           `json:"status"`          // applied | conflict | error
+	Entry  *models.TimeTrac `json:"entry,omitempty"` // Server copy after the operation
+	Error  string           `json:"error,omitempty"`
+}
+
+/**
+ * errSyncConflict signals that the server row changed after base_updated_at
+ */
+var errSyncConflict = errors.New("entry was changed on the server")
+
+/**
+ * syncInvalid is an operation error safe to report to the client;
+ * other errors are database failures and are reported generically
+ */
+type syncInvalid string
+
+func (e syncInvalid) Error() string { return string(e) }
+
+/**
+ * apply copies the provided fields onto item and validates the result
+ */
+func (f syncFields) apply(item *models.TimeTrac) error {
+	if f.Project != nil {
+		item.Project = strings.TrimSpace(*f.Project)
+	}
+	if f.Tags != nil {
+		item.Tags = pq.StringArray(*f.Tags)
+	}
+	if f.Note != nil {
+		item.Note = *f.Note
+	}
+	if f.Color != nil {
+		color, err := validators.NormalizeColor(*f.Color)
+		if err != nil {
+			return syncInvalid("color " + err.Error())
+		}
+		item.Color = color
+	}
+	if f.LocationLat != nil {
+		item.LocationLat = nulls.NewFloat64(*f.LocationLat)
+	}
+	if f.LocationLng != nil {
+		item.LocationLng = nulls.NewFloat64(*f.LocationLng)
+	}
+	if f.LocationAddr != nil {
+		item.LocationAddr = nulls.NewString(strings.TrimSpace(*f.LocationAddr))
+	}
+	if f.StartAt != nil {
+		item.StartAt = *f.StartAt
+	}
+	if f.EndAt != nil {
+		item.EndAt = nulls.NewTime(*f.EndAt)
+	}
+	if item.StartAt.IsZero() {
+		return syncInvalid("start_at required")
+	}
+	if item.EndAt.Valid && !item.EndAt.Time.After(item.StartAt) {
+		return syncInvalid("end_at must be after start_at")
+	}
+	return nil
+}
+
+/**
+ * changedSince reports whether the server row was modified after the
+ * client's base version. Postgres keeps microseconds, so both sides are
+ * compared at that precision.
+ */
+func changedSince(item models.TimeTrac, base time.Time) bool {
+	return item.UpdatedAt.Truncate(time.Microsecond).After(base.Truncate(time.Microsecond))
+}
+
+/**
+ * syncUnlocked refuses, as an invalid operation, to change an entry in an
+ * approved timesheet week
+ */
+func syncUnlocked(tx *pop.Connection, item models.TimeTrac) error {
+	locked, err := entryLocked(tx, item)
+	if err != nil {
+		return err
+	}
+	if locked {
+		return syncInvalid(errEntryLocked.Error())
+	}
+	return nil
+}
+
+/**
+ * applySyncOperation executes one operation
+ *
+ * @return *models.TimeTrac - Server copy after the operation (also on conflict)
+ * @return error - errSyncConflict, a validation error or a database error
+ */
+func applySyncOperation(c buffalo.Context, tx *pop.Connection, uid uuid.UUID, op syncOperation, now time.Time) (*models.TimeTrac, error) {
+	var item models.TimeTrac
+	err := tx.Where("id = ? AND user_id = ?", op.ID, uid).First(&item)
+	found := err == nil
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	if op.Type == "create" {
+		// Retried syncs replay creates; the client UUID makes them idempotent
+		if found {
+			return &item, nil
+		}
+		taken, err := tx.Where("id = ?", op.ID).Exists(&models.TimeTrac{})
+		if err != nil {
+			return nil, err
+		}
+		if taken {
+			return nil, syncInvalid("id already in use")
+		}
+		item = models.TimeTrac{ID: op.ID, UserID: uid, Color: models.DefaultColor}
+		if err := op.Data.apply(&item); err != nil {
+			return nil, err
+		}
+		if err := createTrack(tx, &item); err != nil {
+			return nil, err
+		}
+		if !item.EndAt.Valid {
+			queueTrackEvent(c, eventTrackStarted, item)
+		}
+		return &item, nil
+	}
+
+	if !found {
+		return nil, syncInvalid("not found")
+	}
+	if op.BaseUpdatedAt == nil {
+		return &item, syncInvalid("base_updated_at required")
+	}
+	if archived, err := entryTeamArchived(tx, item); err != nil {
+		return nil, err
+	} else if archived {
+		return &item, syncInvalid(errTeamArchived.Error())
+	}
+
+	switch op.Type {
+	case "delete":
+		if item.DeletedAt.Valid {
+			return &item, nil
+		}
+		if changedSince(item, *op.BaseUpdatedAt) {
+			return &item, errSyncConflict
+		}
+		if err := syncUnlocked(tx, item); err != nil {
+			return &item, err
+		}
+		if err := recordRevision(tx, item, uid); err != nil {
+			return nil, err
+		}
+		item.DeletedAt = nulls.NewTime(now)
+		if !item.EndAt.Valid {
+			item.EndAt = nulls.NewTime(now)
+		}
+		item.UpdatedAt = now
+		if err := updateTrack(tx, &item); err != nil {
+			return nil, err
+		}
+		queueTrackEvent(c, eventTrackDeleted, item)
+		return &item, nil
+
+	case "update", "stop":
+		if item.DeletedAt.Valid || changedSince(item, *op.BaseUpdatedAt) {
+			return &item, errSyncConflict
+		}
+		if op.Type == "stop" && item.EndAt.Valid {
+			return &item, errSyncConflict
+		}
+		if err := syncUnlocked(tx, item); err != nil {
+			return &item, err
+		}
+		prev := item
+		if op.Type == "stop" {
+			end := now
+			if op.Data.EndAt != nil {
+				end = *op.Data.EndAt
+			}
+			if !end.After(item.StartAt) || end.After(now) {
+				return &item, syncInvalid("end_at must be after start_at and not in the future")
+			}
+			if err := recordRevision(tx, prev, uid); err != nil {
+				return nil, err
+			}
+			if err := stopTrack(tx, &item, end); err != nil {
+				return nil, err
+			}
+			queueTrackEvent(c, eventTrackStopped, item)
+			return &item, nil
+		}
+
+		if op.Data.EndAt != nil && !item.EndAt.Valid {
+			return &item, syncInvalid("cannot set end_at on a running entry")
+		}
+		if err := op.Data.apply(&item); err != nil {
+			return &prev, err
+		}
+		if err := syncUnlocked(tx, item); err != nil {
+			return &prev, err
+		}
+		if err := recordRevision(tx, prev, uid); err != nil {
+			return nil, err
+		}
+		item.UpdatedAt = now
+		if err := updateTrack(tx, &item); err != nil {
+			return nil, err
+		}
+		queueTrackEvent(c, eventTrackUpdated, item)
+		return &item, nil
+	}
+	return nil, syncInvalid("unknown operation type")
+}
+
+/**
+ * TracksSync applies a batch of offline changes
+ *
+ * POST /api/tracks/sync
+ *
+ * Payload:
+ * - operations: Array (max 500) of {type, id, base_updated_at, data}
+ *   - type: create | update | delete | stop
+ *   - id: Entry UUID, generated by the client for creates
+ *   - base_updated_at: updated_at of the entry as last seen by the client
+ *     (required except for create)
+ *   - data: Entry fields (project, tags, note, color, location_*,
+ *     start_at, end_at); for stop only end_at (default now)
+ *
+ * Operations are applied in order. Each result has status:
+ * - applied: with the resulting server copy
+ * - conflict: the entry changed on the server after base_updated_at (or
+ *   is already stopped/deleted); carries the server copy, nothing changed
+ * - error: invalid operation (such as a change to an entry in an
+ *   approved timesheet week, or of an archived team); nothing changed
+ *
+ * Creates are idempotent on the client UUID. Overlap detection is not
+ * applied; entries recorded offline are accepted as they were tracked.
+ *
+ * @param c - Buffalo context with authenticated user
+ * @return JSON {"results": [...]} or error response
+ */
+func TracksSync(c buffalo.Context) error {
+	var p struct {
+		Operations []syncOperation `json:"operations"`
+	}
+	if err := json.NewDecoder(c.Request().Body).Decode(&p); err != nil {
+		return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "bad payload"}))
+	}
+	if len(p.Operations) > syncMaxOperations {
+		return c.Render(http.StatusRequestEntityTooLarge, r.JSON(map[string]string{"error": "too many operations"}))
+	}
+
+	tx := mustTx(c)
+	uid, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+
+	now := time.Now()
+	results := make([]syncResult, 0, len(p.Operations))
+	for _, op := range p.Operations {
+		res := syncResult{ID: op.ID, Type: op.Type}
+		if op.ID == uuid.Nil {
+			res.Status, res.Error = syncError, "id required"
+			results = append(results, res)
+			continue
+		}
+
+		// A failed statement aborts the whole Postgres transaction unless
+		// it is rolled back to a savepoint
+		if err := tx.RawQuery("SAVEPOINT sync_op").Exec(); err != nil {
+			return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+		}
+		entry, err := applySyncOperation(c, tx, uid, op, now)
+		switch {
+		case err == nil:
+			res.Status, res.Entry = syncApplied, entry
+			err = tx.RawQuery("RELEASE SAVEPOINT sync_op").Exec()
+		case errors.Is(err, errSyncConflict):
+			res.Status, res.Entry, res.Error = syncConflict, entry, err.Error()
+			err = tx.RawQuery("ROLLBACK TO SAVEPOINT sync_op").Exec()
+		default:
+			res.Status, res.Error = syncError, err.Error()
+			var invalid syncInvalid
+			var invalidTrack invalidTrackError
+			if !errors.As(err, &invalid) && !errors.As(err, &invalidTrack) {
+				c.Logger().Errorf("sync %s %s: %v", op.Type, op.ID, err)
+				res.Error = "db error"
+			}
+			err = tx.RawQuery("ROLLBACK TO SAVEPOINT sync_op").Exec()
+		}
+		if err != nil {
+			return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+		}
+		results = append(results, res)
+	}
+
+	return c.Render(http.StatusOK, r.JSON(map[string]any{"results": results}))
+}
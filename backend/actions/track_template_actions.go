@@ -0,0 +1,317 @@
+/**
+ * Track Template Actions - Quick-Start Presets
+ *
+ * This file provides CRUD for a user's entry templates, reordering, and
+ * starting a running entry from a template. Starting uses the same path
+ * as TracksStart (startEntry), including auto-stopping the previous
+ * entry.
+ *
+ * Templates live under /api/tracks/templates rather than /api/templates:
+ * report templates moved to /api/reports/templates, but /api/templates
+ * still redirects there for existing clients.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-20
+ */
+package actions
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"backend/models"
+	"backend/validators"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gofrs/uuid"
+	"github.com/lib/pq"
+)
+
+/**
+ * templatePayload is the request body for creating and updating
+ * templates; nil fields are left unchanged on update
+ */
+type templatePayload struct {
+	Name    *string   `json:"name"`
+	Project *string   `json:"project"`
+	Tags    *[]string `json:"tags"`
+	Note    *string   `json:"note"`
+	Color   *string   `json:"color"`
+}
+
+/**
+ * apply copies the provided fields onto t and validates the result
+ *
+ * @return string - Invalid field name, "" if valid
+ * @return error - Validation message for the field
+ */
+func (p templatePayload) apply(t *models.TrackTemplate) (string, error) {
+	if p.Name != nil {
+		t.Name = strings.TrimSpace(*p.Name)
+	}
+	if p.Project != nil {
+		t.Project = strings.TrimSpace(*p.Project)
+	}
+	if p.Tags != nil {
+		t.Tags = pq.StringArray(*p.Tags)
+	}
+	if p.Note != nil {
+		t.Note = *p.Note
+	}
+	if p.Color != nil {
+		color, err := validators.NormalizeColor(*p.Color)
+		if err != nil {
+			return "color", err
+		}
+		t.Color = color
+	}
+	if t.Name == "" {
+		return "name", errTemplateNameRequired
+	}
+	return "", nil
+}
+
+var errTemplateNameRequired = validationError("is required")
+
+/**
+ * validationError is a plain validation message for renderFieldError
+ */
+type validationError string
+
+func (e validationError) Error() string { return string(e) }
+
+/**
+ * loadTemplate finds one of the user's templates
+ */
+func loadTemplate(c buffalo.Context, uid uuid.UUID) (models.TrackTemplate, bool) {
+	var t models.TrackTemplate
+	id, err := uuid.FromString(c.Param("id"))
+	if err != nil {
+		return t, false
+	}
+	err = mustTx(c).Where("id = ? AND user_id = ?", id, uid).First(&t)
+	return t, err == nil
+}
+
+/**
+ * TemplatesIndex lists the user's templates by position
+ *
+ * GET /api/tracks/templates
+ *
+ * @param c - Buffalo context with authenticated user
+ * @return JSON array of TrackTemplate or error response
+ */
+func TemplatesIndex(c buffalo.Context) error {
+	tx := mustTx(c)
+	uid, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+
+	list := []models.TrackTemplate{}
+	if err := tx.Where("user_id = ?", uid).Order("position ASC, created_at ASC").All(&list); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+	return c.Render(http.StatusOK, r.JSON(list))
+}
+
+/**
+ * TemplatesCreate adds a template at the end of the list
+ *
+ * POST /api/tracks/templates
+ *
+ * Payload: name (required), project, tags, note, color (default #3b82f6)
+ *
+ * Responses:
+ * - 201 with the template
+ * - 409 if the user already has 50 templates
+ * - 422 with field errors
+ *
+ * @param c - Buffalo context with authenticated user
+ * @return JSON TrackTemplate or error response
+ */
+func TemplatesCreate(c buffalo.Context) error {
+	var p templatePayload
+	if err := c.Bind(&p); err != nil {
+		return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "bad payload"}))
+	}
+
+	tx := mustTx(c)
+	uid, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+
+	t := models.TrackTemplate{UserID: uid, Tags: pq.StringArray{}, Color: "#3b82f6"}
+	if field, err := p.apply(&t); err != nil {
+		return renderFieldError(c, field, err)
+	}
+
+	// The user row lock serializes concurrent creates; the insert itself
+	// only adds a row while the user is below the limit
+	if err := tx.RawQuery("SELECT id FROM users WHERE id = ? FOR UPDATE", uid).Exec(); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+	now := time.Now()
+	created := []models.TrackTemplate{}
+	if err := tx.RawQuery(`
+		INSERT INTO track_templates (id, user_id, name, project, tags, note, color, position, created_at, updated_at)
+		SELECT ?, ?, ?, ?, ?, ?, ?, COALESCE(MAX(position), -1) + 1, ?, ?
+		FROM track_templates
+		WHERE user_id = ?
+		HAVING COUNT(*) < ?
+		RETURNING *
+	`, uuid.Must(uuid.NewV4()), uid, t.Name, t.Project, t.Tags, t.Note, t.Color, now, now, uid, models.MaxTrackTemplates).All(&created); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot create"}))
+	}
+	if len(created) == 0 {
+		return c.Render(http.StatusConflict, r.JSON(map[string]string{"error": "template limit reached"}))
+	}
+	t = created[0]
+
+	return c.Render(http.StatusCreated, r.JSON(t))
+}
+
+/**
+ * TemplatesUpdate modifies a template
+ *
+ * PATCH /api/tracks/templates/{id}
+ *
+ * @param c - Buffalo context with authenticated user and template ID
+ * @return JSON TrackTemplate or error response
+ */
+func TemplatesUpdate(c buffalo.Context) error {
+	var p templatePayload
+	if err := c.Bind(&p); err != nil {
+		return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "bad payload"}))
+	}
+
+	uid, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+	t, found := loadTemplate(c, uid)
+	if !found {
+		return c.Render(http.StatusNotFound, r.JSON(map[string]string{"error": "not found"}))
+	}
+	if field, err := p.apply(&t); err != nil {
+		return renderFieldError(c, field, err)
+	}
+
+	t.UpdatedAt = time.Now()
+	if err := mustTx(c).Update(&t); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot update"}))
+	}
+	return c.Render(http.StatusOK, r.JSON(t))
+}
+
+/**
+ * TemplatesDelete removes a template
+ *
+ * DELETE /api/tracks/templates/{id}
+ *
+ * @param c - Buffalo context with authenticated user and template ID
+ * @return JSON status or error response
+ */
+func TemplatesDelete(c buffalo.Context) error {
+	uid, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+	t, found := loadTemplate(c, uid)
+	if !found {
+		return c.Render(http.StatusNotFound, r.JSON(map[string]string{"error": "not found"}))
+	}
+	if err := mustTx(c).Destroy(&t); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot delete"}))
+	}
+	return c.Render(http.StatusOK, r.JSON(map[string]string{"status": "deleted"}))
+}
+
+/**
+ * TemplatesReorder sets the order of the user's templates
+ *
+ * PUT /api/tracks/templates/positions
+ *
+ * Payload: {"ids": [...]} with every template ID of the user in the new
+ * order; positions are assigned 0, 1, 2, ...
+ *
+ * @param c - Buffalo context with authenticated user
+ * @return JSON array of TrackTemplate in the new order or error response
+ */
+func TemplatesReorder(c buffalo.Context) error {
+	var p struct {
+		IDs []uuid.UUID `json:"ids"`
+	}
+	if err := c.Bind(&p); err != nil {
+		return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "bad payload"}))
+	}
+
+	tx := mustTx(c)
+	uid, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+
+	list := []models.TrackTemplate{}
+	if err := tx.Where("user_id = ?", uid).All(&list); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+	byID := map[uuid.UUID]*models.TrackTemplate{}
+	for i := range list {
+		byID[list[i].ID] = &list[i]
+	}
+	if len(p.IDs) != len(list) {
+		return c.Render(http.StatusUnprocessableEntity, r.JSON(map[string]string{"error": "ids must list every template exactly once"}))
+	}
+
+	now := time.Now()
+	ordered := make([]models.TrackTemplate, 0, len(p.IDs))
+	for pos, id := range p.IDs {
+		t, ok := byID[id]
+		if !ok {
+			return c.Render(http.StatusUnprocessableEntity, r.JSON(map[string]string{"error": "ids must list every template exactly once"}))
+		}
+		delete(byID, id)
+		t.Position = pos
+		t.UpdatedAt = now
+		if err := tx.Update(t); err != nil {
+			return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot reorder"}))
+		}
+		ordered = append(ordered, *t)
+	}
+	return c.Render(http.StatusOK, r.JSON(ordered))
+}
+
+/**
+ * TemplatesStart starts a running entry from a template
+ *
+ * POST /api/tracks/templates/{id}/start
+ *
+ * Behaves like TracksStart with the template's project, tags, note and
+ * color, including auto-stopping the running entry and the archived
+ * project check. The response has the same shape as TracksStart.
+ *
+ * @param c - Buffalo context with authenticated user and template ID
+ * @return JSON TimeTrac entry or error response
+ */
+func TemplatesStart(c buffalo.Context) error {
+	uid, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+	t, found := loadTemplate(c, uid)
+	if !found {
+		return c.Render(http.StatusNotFound, r.JSON(map[string]string{"error": "not found"}))
+	}
+
+	return startEntry(c, mustTx(c), models.TimeTrac{
+		UserID:  uid,
+		Project: t.Project,
+		Tags:    append(pq.StringArray{}, t.Tags...),
+		Note:    t.Note,
+		Color:   t.Color,
+	})
+}
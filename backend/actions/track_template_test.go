@@ -0,0 +1,43 @@
+package actions
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"backend/models"
+)
+
+func (as *ActionSuite) Test_TemplatesCreate_Limit() {
+	token := as.registerToken("templates@example.com")
+
+	for i := 0; i < models.MaxTrackTemplates; i++ {
+		res := as.authJSON(token, "/api/tracks/templates").Post(map[string]any{"name": fmt.Sprintf("T%d", i), "project": "Web"})
+		as.Equal(http.StatusCreated, res.Code)
+		var t models.TrackTemplate
+		as.NoError(json.Unmarshal(res.Body.Bytes(), &t))
+		as.Equal(i, t.Position)
+		as.Equal("#3b82f6", t.Color)
+	}
+
+	res := as.authJSON(token, "/api/tracks/templates").Post(map[string]any{"name": "One too many"})
+	as.Equal(http.StatusConflict, res.Code)
+	n, err := as.DB.Where("name = ?", "One too many").Count(&models.TrackTemplate{})
+	as.NoError(err)
+	as.Equal(0, n)
+
+	// The limit is per user
+	other := as.registerToken("templates-other@example.com")
+	as.Equal(http.StatusCreated, as.authJSON(other, "/api/tracks/templates").Post(map[string]any{"name": "Mine"}).Code)
+
+	// Deleting one frees a slot, appended after the highest position
+	var list []models.TrackTemplate
+	as.NoError(json.Unmarshal(as.authJSON(token, "/api/tracks/templates").Get().Body.Bytes(), &list))
+	as.Len(list, models.MaxTrackTemplates)
+	as.Equal(http.StatusOK, as.authJSON(token, "/api/tracks/templates/%s", list[0].ID).Delete().Code)
+	res = as.authJSON(token, "/api/tracks/templates").Post(map[string]any{"name": "Replacement"})
+	as.Equal(http.StatusCreated, res.Code)
+	var t models.TrackTemplate
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &t))
+	as.Equal(models.MaxTrackTemplates, t.Position)
+}
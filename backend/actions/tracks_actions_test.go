@@ -1,8 +1,19 @@
 package actions
 
 import (
+	"errors"
+	"net/http"
 	"net/http/httptest"
+	"net/netip"
+	"strings"
 	"testing"
+	"time"
+
+	"backend/models"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/gofrs/uuid"
+	"github.com/lib/pq"
 )
 
 // Smoke-test protected routes wiring (no DB asserts). In CI where DB may be
@@ -17,3 +28,375 @@ func Test_Tracks_RequireAuth(t *testing.T) {
 		t.Fatalf("expected 401/500 without token, got %d", w.Code)
 	}
 }
+
+func Test_SplitPointValid(t *testing.T) {
+	now := time.Date(2025, 9, 20, 12, 0, 0, 0, time.UTC)
+	start := now.Add(-4 * time.Hour)
+
+	stopped := models.TimeTrac{StartAt: start, EndAt: nulls.NewTime(now.Add(-1 * time.Hour))}
+	running := models.TimeTrac{StartAt: start}
+
+	cases := []struct {
+		name string
+		item models.TimeTrac
+		at   time.Time
+		want bool
+	}{
+		{"inside stopped", stopped, start.Add(time.Hour), true},
+		{"at start", stopped, start, false},
+		{"at end", stopped, stopped.EndAt.Time, false},
+		{"after end", stopped, now, false},
+		{"running past", running, now.Add(-time.Minute), true},
+		{"running future", running, now.Add(time.Minute), false},
+	}
+	for _, tc := range cases {
+		if got := splitPointValid(tc.item, tc.at, now); got != tc.want {
+			t.Errorf("%s: splitPointValid = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func Test_MergeTracks(t *testing.T) {
+	base := time.Date(2025, 9, 20, 9, 0, 0, 0, time.UTC)
+	entries := []models.TimeTrac{
+		{Project: "Web", Tags: pq.StringArray{"ui"}, Note: "first", StartAt: base.Add(time.Hour), EndAt: nulls.NewTime(base.Add(2 * time.Hour))},
+		{Project: "Web", Tags: pq.StringArray{"ui", "api"}, Note: "", StartAt: base, EndAt: nulls.NewTime(base.Add(30 * time.Minute))},
+		{Project: "Web", Tags: pq.StringArray{"db"}, Note: "third", StartAt: base.Add(3 * time.Hour), EndAt: nulls.NewTime(base.Add(4 * time.Hour))},
+	}
+
+	m := mergeTracks(entries)
+	if !m.StartAt.Equal(base) {
+		t.Errorf("start = %v, want %v", m.StartAt, base)
+	}
+	if !m.EndAt.Time.Equal(base.Add(4 * time.Hour)) {
+		t.Errorf("end = %v, want %v", m.EndAt.Time, base.Add(4*time.Hour))
+	}
+	if got := strings.Join(m.Tags, ","); got != "ui,api,db" {
+		t.Errorf("tags = %q, want ui,api,db", got)
+	}
+	if m.Note != "first\nthird" {
+		t.Errorf("note = %q", m.Note)
+	}
+}
+
+func Test_SniffPhotoType(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	jpeg := []byte("\xff\xd8\xff\xe0\x00\x10JFIF\x00")
+	webp := []byte("RIFF\x00\x00\x00\x00WEBPVP8 ")
+	gif := []byte("GIF89a")
+
+	for _, tc := range []struct {
+		data []byte
+		want bool
+	}{{png, true}, {jpeg, true}, {webp, true}, {gif, false}, {[]byte("<svg></svg>"), false}} {
+		if mime, ok := sniffPhotoType(tc.data); ok != tc.want {
+			t.Errorf("sniffPhotoType(%q) = %s, %v; want ok=%v", tc.data, mime, ok, tc.want)
+		}
+	}
+}
+
+func Test_TrackCursor_RoundTrip(t *testing.T) {
+	item := models.TimeTrac{
+		ID:      uuid.Must(uuid.NewV4()),
+		StartAt: time.Date(2025, 9, 20, 9, 30, 15, 123456000, time.UTC),
+	}
+	at, id, err := decodeTrackCursor(encodeTrackCursor(item))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !at.Equal(item.StartAt) || id != item.ID {
+		t.Fatalf("round trip mismatch: %v %v", at, id)
+	}
+
+	for _, bad := range []string{"not base64!", "bm8tc2VwYXJhdG9y", ""} {
+		if _, _, err := decodeTrackCursor(bad); err == nil {
+			t.Errorf("cursor %q accepted", bad)
+		}
+	}
+}
+
+func Test_PeriodBounds(t *testing.T) {
+	vienna, err := time.LoadLocation("Europe/Vienna")
+	if err != nil {
+		t.Skip("tzdata not available")
+	}
+	// Sunday 23:30 in Vienna is still the ISO week that began on Monday 15th
+	now := time.Date(2025, 9, 21, 21, 30, 0, 0, time.UTC)
+
+	curStart, curEnd, prevStart := periodBounds(now, vienna, "week")
+	if want := time.Date(2025, 9, 15, 0, 0, 0, 0, vienna); !curStart.Equal(want) {
+		t.Errorf("week start = %v, want %v", curStart, want)
+	}
+	if want := time.Date(2025, 9, 22, 0, 0, 0, 0, vienna); !curEnd.Equal(want) {
+		t.Errorf("week end = %v, want %v", curEnd, want)
+	}
+	if want := time.Date(2025, 9, 8, 0, 0, 0, 0, vienna); !prevStart.Equal(want) {
+		t.Errorf("previous week start = %v, want %v", prevStart, want)
+	}
+
+	curStart, curEnd, prevStart = periodBounds(now, vienna, "month")
+	if !curStart.Equal(time.Date(2025, 9, 1, 0, 0, 0, 0, vienna)) ||
+		!curEnd.Equal(time.Date(2025, 10, 1, 0, 0, 0, 0, vienna)) ||
+		!prevStart.Equal(time.Date(2025, 8, 1, 0, 0, 0, 0, vienna)) {
+		t.Errorf("month bounds = %v, %v, %v", curStart, curEnd, prevStart)
+	}
+}
+
+func Test_PercentDelta(t *testing.T) {
+	if d := percentDelta(150, 100); d == nil || *d != 50 {
+		t.Errorf("150 vs 100 = %v, want 50", d)
+	}
+	if d := percentDelta(1, 3); d == nil || *d != -66.7 {
+		t.Errorf("1 vs 3 = %v, want -66.7", d)
+	}
+	if d := percentDelta(10, 0); d != nil {
+		t.Errorf("delta against zero = %v, want nil", *d)
+	}
+}
+
+func Test_EventHub(t *testing.T) {
+	h := newEventHub()
+	alice, bob := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+
+	events, unsubscribe := h.Subscribe(alice)
+	h.Publish(trackEvent{UserID: bob, Name: eventTrackStarted})
+	h.Publish(trackEvent{UserID: alice, Name: eventTrackStopped})
+
+	select {
+	case ev := <-events:
+		if ev.Name != eventTrackStopped {
+			t.Fatalf("got %q, other users' events must not be delivered", ev.Name)
+		}
+	default:
+		t.Fatal("event not delivered")
+	}
+
+	unsubscribe()
+	if len(h.subs) != 0 {
+		t.Fatal("unsubscribe must release the user's channel set")
+	}
+	// Publishing without subscribers is a no-op
+	h.Publish(trackEvent{UserID: alice, Name: eventTrackUpdated})
+}
+
+func Test_ShouldDiscard(t *testing.T) {
+	start := time.Date(2025, 9, 20, 9, 0, 0, 0, time.UTC)
+	item := models.TimeTrac{StartAt: start}
+
+	if !shouldDiscard(item, start.Add(3*time.Second), 10) {
+		t.Error("3s entry under a 10s threshold should be discarded")
+	}
+	if shouldDiscard(item, start.Add(3*time.Second), 0) {
+		t.Error("threshold 0 disables discarding")
+	}
+	if shouldDiscard(item, start.Add(10*time.Second), 10) {
+		t.Error("entry exactly at the threshold must be kept")
+	}
+
+	withNote := item
+	withNote.Note = "call with client"
+	withPhoto := item
+	withPhoto.PhotoKey = nulls.NewString("tracks/x/y.jpg")
+	for _, it := range []models.TimeTrac{withNote, withPhoto} {
+		if shouldDiscard(it, start.Add(time.Second), 10) {
+			t.Error("entries with a note or photo must never be discarded")
+		}
+	}
+}
+
+func Test_NewGoalProgress(t *testing.T) {
+	g := newGoalProgress(1200, 15*3600) // 20h goal, 15h tracked
+	if g.ProgressPercent == nil || *g.ProgressPercent != 75 || *g.RemainingMinutes != 300 {
+		t.Errorf("got %+v", g)
+	}
+	over := newGoalProgress(60, 2*3600)
+	if *over.ProgressPercent != 200 || *over.RemainingMinutes != 0 {
+		t.Errorf("exceeded goal: got %v%%, %d remaining", *over.ProgressPercent, *over.RemainingMinutes)
+	}
+	if none := newGoalProgress(0, 3600); none.ProgressPercent != nil || none.RemainingMinutes != nil {
+		t.Error("without a goal progress fields must be null")
+	}
+}
+
+func Test_HourMatrix_Add(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("tzdata unavailable")
+	}
+
+	// Across midnight: Sunday 23:30 to Monday 00:45
+	var m hourMatrix
+	m.add(time.Date(2025, 9, 21, 23, 30, 0, 0, ny), time.Date(2025, 9, 22, 0, 45, 0, 0, ny), ny)
+	if m[6][23] != 1800 || m[0][0] != 2700 {
+		t.Errorf("midnight split: sun 23h = %v, mon 0h = %v", m[6][23], m[0][0])
+	}
+
+	// Spring forward (Sun 2025-03-09): 01:30 EST to 03:30 EDT is one real hour
+	m = hourMatrix{}
+	from := time.Date(2025, 3, 9, 1, 30, 0, 0, ny)
+	m.add(from, from.Add(time.Hour), ny)
+	if m[6][1] != 1800 || m[6][2] != 0 || m[6][3] != 1800 {
+		t.Errorf("spring forward: 1h = %v, 2h = %v, 3h = %v", m[6][1], m[6][2], m[6][3])
+	}
+
+	// Fall back (Sun 2025-11-02): 00:30 to 02:30 local covers hour 1 twice
+	m = hourMatrix{}
+	from = time.Date(2025, 11, 2, 0, 30, 0, 0, ny)
+	m.add(from, from.Add(3*time.Hour), ny)
+	if m[6][0] != 1800 || m[6][1] != 7200 || m[6][2] != 1800 {
+		t.Errorf("fall back: 0h = %v, 1h = %v, 2h = %v", m[6][0], m[6][1], m[6][2])
+	}
+}
+
+func Test_ActiveSpans(t *testing.T) {
+	start := time.Date(2025, 9, 20, 9, 0, 0, 0, time.UTC)
+	end := start.Add(4 * time.Hour)
+
+	pauses := models.TrackPauses{
+		{PausedAt: start.Add(2 * time.Hour), ResumedAt: nulls.NewTime(start.Add(150 * time.Minute))},
+		{PausedAt: start.Add(time.Hour), ResumedAt: nulls.NewTime(start.Add(90 * time.Minute))},
+		{PausedAt: start.Add(210 * time.Minute)}, // open until end
+	}
+	spans := activeSpans(start, end, pauses)
+
+	want := [][2]time.Duration{{0, 60}, {90, 120}, {150, 210}}
+	if len(spans) != len(want) {
+		t.Fatalf("spans = %v", spans)
+	}
+	for i, w := range want {
+		if !spans[i][0].Equal(start.Add(w[0]*time.Minute)) || !spans[i][1].Equal(start.Add(w[1]*time.Minute)) {
+			t.Errorf("span %d = %v", i, spans[i])
+		}
+	}
+
+	if got := activeSpans(start, end, nil); len(got) != 1 || !got[0][1].Equal(end) {
+		t.Errorf("no pauses = %v", got)
+	}
+}
+
+func Test_VerifySlackSignature(t *testing.T) {
+	// Example from Slack's "Verifying requests from Slack" documentation
+	secret := "8f742231b10e8888abcd99yyyzzz85a5"
+	ts := "1531420618"
+	body := []byte("token=xyzz0WbapA4vBCDEFasx0q6G&team_id=T1DC2JH3J&team_domain=testteamnow&channel_id=G8PSS9T3V&channel_name=foobar&user_id=U2CERLKJA&user_name=roadrunner&command=%2Fwebhook-collect&text=&response_url=https%3A%2F%2Fhooks.slack.com%2Fcommands%2FT1DC2JH3J%2F397700885554%2F96rGlfmibIGlgcZRskXaIFfN&trigger_id=398738663015.47445629121.803a0bc887a14d10d2c447fce8b6703c")
+	sig := "v0=a2114d57b48eac39b9ad189dd8316235a7b4a8d21a10bd27519666489c69b503"
+	now := time.Unix(1531420618, 0).Add(time.Minute)
+
+	if err := verifySlackSignature(secret, ts, body, sig, now); err != nil {
+		t.Errorf("valid signature rejected: %v", err)
+	}
+	if err := verifySlackSignature(secret, ts, append(body, 'x'), sig, now); err == nil {
+		t.Error("tampered body accepted")
+	}
+	if err := verifySlackSignature(secret, ts, body, sig, now.Add(10*time.Minute)); err == nil {
+		t.Error("stale timestamp accepted")
+	}
+}
+
+func Test_ParseSlackStart(t *testing.T) {
+	project, tags, note := parseSlackStart("Website #frontend #bug fix login crash")
+	if project != "Website" || strings.Join(tags, ",") != "frontend,bug" || note != "fix login crash" {
+		t.Errorf("got %q %v %q", project, tags, note)
+	}
+
+	project, tags, note = parseSlackStart("#meeting standup")
+	if project != "" || strings.Join(tags, ",") != "meeting" || note != "standup" {
+		t.Errorf("got %q %v %q", project, tags, note)
+	}
+
+	if got := formatSlackDuration(65 * time.Minute); got != "1h 05m" {
+		t.Errorf("duration = %q", got)
+	}
+}
+
+func Test_SignWebhook(t *testing.T) {
+	got := signWebhook("whsec_test", []byte(`{"event":"track.started"}`))
+	if !strings.HasPrefix(got, "sha256=") || len(got) != len("sha256=")+64 {
+		t.Fatalf("unexpected signature format %q", got)
+	}
+	if got != signWebhook("whsec_test", []byte(`{"event":"track.started"}`)) {
+		t.Error("signature not deterministic")
+	}
+	if got == signWebhook("whsec_other", []byte(`{"event":"track.started"}`)) {
+		t.Error("signature ignores secret")
+	}
+}
+
+func Test_ValidWebhookURL(t *testing.T) {
+	for _, u := range []string{"https://example.com/hooks", "http://203.0.113.9:8080/x"} {
+		if msg := validWebhookURL(u); msg != "" {
+			t.Errorf("%s rejected: %s", u, msg)
+		}
+	}
+	for _, u := range []string{"", "example.com", "ftp://example.com", "https://", "https://user:pw@example.com",
+		"http://localhost:8080/x", "http://api.localhost/", "http://127.0.0.1/", "http://10.1.2.3/", "http://[::1]/",
+		"http://169.254.169.254/latest/meta-data/", "http://[::ffff:192.168.0.1]/"} {
+		if validWebhookURL(u) == "" {
+			t.Errorf("%q accepted", u)
+		}
+	}
+}
+
+func Test_SendWebhook(t *testing.T) {
+	status := http.StatusNoContent
+	var gotSig, gotEvent, gotRequestID string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSig = r.Header.Get("X-Signature")
+		gotEvent = r.Header.Get("X-Webhook-Event")
+		gotRequestID = r.Header.Get("X-Request-ID")
+		w.WriteHeader(status)
+		w.Write([]byte("nope"))
+	}))
+	defer srv.Close()
+
+	d := dueWebhookDelivery{ID: uuid.Must(uuid.NewV4()), Event: "track.stopped", Payload: `{"a":1}`, URL: srv.URL, Secret: "whsec_test", RequestID: "req-1"}
+	code, err := sendWebhook(srv.Client(), d)
+	if err != nil || code != http.StatusNoContent {
+		t.Fatalf("got %d %v", code, err)
+	}
+	if gotSig != signWebhook("whsec_test", []byte(`{"a":1}`)) || gotEvent != "track.stopped" || gotRequestID != "req-1" {
+		t.Errorf("headers: %q %q %q", gotSig, gotEvent, gotRequestID)
+	}
+
+	status = http.StatusInternalServerError
+	code, err = sendWebhook(srv.Client(), d)
+	if err == nil || code != http.StatusInternalServerError || err.Error() != "HTTP 500" {
+		t.Errorf("got %d %v", code, err)
+	}
+}
+
+func Test_BlockedWebhookIP(t *testing.T) {
+	for _, ip := range []string{"127.0.0.1", "10.0.0.1", "172.16.5.4", "192.168.1.1", "169.254.169.254", "0.0.0.0",
+		"100.64.0.1", "::1", "fe80::1", "fc00::1", "fd00:ec2::254", "::ffff:127.0.0.1", "224.0.0.1"} {
+		if !blockedWebhookIP(netip.MustParseAddr(ip)) {
+			t.Errorf("%s allowed", ip)
+		}
+	}
+	for _, ip := range []string{"93.184.216.34", "2606:2800:220:1::1"} {
+		if blockedWebhookIP(netip.MustParseAddr(ip)) {
+			t.Errorf("%s blocked", ip)
+		}
+	}
+}
+
+func Test_WebhookClient(t *testing.T) {
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		http.Redirect(w, r, "http://169.254.169.254/latest/meta-data/", http.StatusFound)
+	}))
+	defer srv.Close()
+
+	// The test server listens on loopback, which webhooks cannot reach
+	d := dueWebhookDelivery{ID: uuid.Must(uuid.NewV4()), Event: "track.stopped", Payload: `{}`, URL: srv.URL, Secret: "whsec_test"}
+	if code, err := sendWebhook(newWebhookClient(), d); !errors.Is(err, errWebhookAddress) || code != 0 || hits != 0 {
+		t.Errorf("loopback: got %d %v, %d hits", code, err, hits)
+	}
+
+	// Redirects are answered, not followed
+	client := srv.Client()
+	client.CheckRedirect = newWebhookClient().CheckRedirect
+	if code, err := sendWebhook(client, d); code != http.StatusFound || err == nil || hits != 1 {
+		t.Errorf("redirect: got %d %v, %d hits", code, err, hits)
+	}
+}
@@ -0,0 +1,30 @@
+package actions
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"backend/models"
+)
+
+func (as *ActionSuite) Test_TracksHistory_DeletedChanger() {
+	token := as.registerToken("history-owner@example.com")
+	as.registerToken("history-editor@example.com")
+	item := as.startTrack(token)
+
+	res := as.authJSON(token, "/api/tracks/%s", item.ID).Patch(map[string]string{"note": "edited"})
+	as.Equal(http.StatusOK, res.Code)
+
+	// The revision outlives the user who made it; changed_by turns null
+	as.NoError(as.DB.RawQuery(`UPDATE timetrac_revisions SET changed_by = (SELECT id FROM users WHERE email = ?)
+		WHERE track_id = ?`, "history-editor@example.com", item.ID).Exec())
+	as.NoError(as.DB.RawQuery("DELETE FROM users WHERE email = ?", "history-editor@example.com").Exec())
+
+	res = as.authJSON(token, "/api/tracks/%s/history", item.ID).Get()
+	as.Equal(http.StatusOK, res.Code)
+	var revisions []models.TimeTracRevision
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &revisions))
+	as.Len(revisions, 1)
+	as.False(revisions[0].ChangedBy.Valid)
+	as.Contains(res.Body.String(), `"changed_by":null`)
+}
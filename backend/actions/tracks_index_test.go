@@ -0,0 +1,63 @@
+package actions
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+
+	"backend/models"
+
+	"github.com/gofrs/uuid"
+)
+
+func (as *ActionSuite) Test_TracksIndex_CursorWalk() {
+	token := as.registerToken("cursor-walk@example.com")
+
+	// Seven entries; two share a start time to exercise the id tie-break
+	base := time.Now().Add(-48 * time.Hour).UTC().Truncate(time.Second)
+	starts := []time.Duration{0, 1, 2, 3, 3, 4, 5}
+	for _, h := range starts {
+		start := base.Add(h * time.Hour)
+		res := as.authJSON(token, "/api/tracks/").Post(map[string]any{
+			"project":       "Web",
+			"start_at":      start,
+			"end_at":        start.Add(30 * time.Minute),
+			"allow_overlap": true,
+		})
+		as.Equal(http.StatusCreated, res.Code)
+	}
+
+	seen := map[uuid.UUID]bool{}
+	var prev *models.TimeTrac
+	cursor := ""
+	pages := 0
+	for {
+		res := as.authJSON(token, "/api/tracks/?limit=3&cursor=%s", url.QueryEscape(cursor)).Get()
+		as.Equal(http.StatusOK, res.Code)
+
+		var page struct {
+			Items      []models.TimeTrac `json:"items"`
+			NextCursor *string           `json:"next_cursor"`
+		}
+		as.NoError(json.Unmarshal(res.Body.Bytes(), &page))
+		pages++
+
+		for i := range page.Items {
+			it := page.Items[i]
+			as.False(seen[it.ID], "entry returned twice")
+			seen[it.ID] = true
+			if prev != nil {
+				as.False(it.StartAt.After(prev.StartAt), "entries out of order")
+			}
+			prev = &it
+		}
+		if page.NextCursor == nil {
+			break
+		}
+		cursor = *page.NextCursor
+	}
+
+	as.Equal(3, pages)
+	as.Len(seen, len(starts))
+}
@@ -0,0 +1,45 @@
+package actions
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"backend/models"
+
+	"github.com/gofrs/uuid"
+)
+
+func (as *ActionSuite) Test_TracksMerge_Trash() {
+	token := as.registerToken("merge-trash@example.com")
+	start := time.Now().Add(-5 * time.Hour).UTC().Truncate(time.Second)
+	create := func(offset time.Duration) models.TimeTrac {
+		res := as.authJSON(token, "/api/tracks/").Post(map[string]any{"project": "Web", "start_at": start.Add(offset), "end_at": start.Add(offset + time.Hour)})
+		as.Equal(http.StatusCreated, res.Code)
+		var item models.TimeTrac
+		as.NoError(json.Unmarshal(res.Body.Bytes(), &item))
+		return item
+	}
+	kept, merged := create(0), create(2*time.Hour)
+
+	res := as.authJSON(token, "/api/tracks/merge").Post(map[string]any{"ids": []uuid.UUID{kept.ID, merged.ID}})
+	as.Equal(http.StatusOK, res.Code)
+	var body struct {
+		Entry      models.TimeTrac `json:"entry"`
+		DeletedIDs []uuid.UUID     `json:"deleted_ids"`
+	}
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &body))
+	as.Equal(kept.ID, body.Entry.ID)
+	as.Equal([]uuid.UUID{merged.ID}, body.DeletedIDs)
+
+	// The merged-away entry is in trash, not gone
+	var trashed models.TimeTrac
+	as.NoError(as.DB.Find(&trashed, merged.ID))
+	as.True(trashed.DeletedAt.Valid)
+	list := []models.TimeTrac{}
+	res = as.authJSON(token, "/api/tracks/trash").Get()
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &list))
+	as.Len(list, 1)
+	as.Equal(merged.ID, list[0].ID)
+	as.Equal(http.StatusOK, as.authJSON(token, "/api/tracks/%s/restore", merged.ID).Post(nil).Code)
+}
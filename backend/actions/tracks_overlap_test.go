@@ -0,0 +1,68 @@
+package actions
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"backend/models"
+)
+
+func (as *ActionSuite) Test_TracksOverlap() {
+	token := as.registerToken("overlap@example.com")
+	base := time.Now().Add(-48 * time.Hour).UTC().Truncate(time.Hour)
+
+	create := func(from, to time.Duration, extra map[string]any) (int, []byte) {
+		body := map[string]any{"project": "Web", "start_at": base.Add(from), "end_at": base.Add(to)}
+		for k, v := range extra {
+			body[k] = v
+		}
+		res := as.authJSON(token, "/api/tracks/").Post(body)
+		return res.Code, res.Body.Bytes()
+	}
+	var conflict struct {
+		Conflicts []overlapConflict `json:"conflicts"`
+	}
+
+	code, body := create(0, time.Hour, nil)
+	as.Equal(http.StatusCreated, code)
+	var first models.TimeTrac
+	as.NoError(json.Unmarshal(body, &first))
+
+	// An overlapping entry is refused with the entry it collides with
+	code, body = create(30*time.Minute, 90*time.Minute, nil)
+	as.Equal(http.StatusConflict, code)
+	as.NoError(json.Unmarshal(body, &conflict))
+	as.Len(conflict.Conflicts, 1)
+	as.Equal(first.ID, conflict.Conflicts[0].ID)
+
+	// Back-to-back entries do not overlap
+	code, body = create(time.Hour, 2*time.Hour, nil)
+	as.Equal(http.StatusCreated, code)
+	var second models.TimeTrac
+	as.NoError(json.Unmarshal(body, &second))
+
+	// The escape hatch works in the payload and as a query parameter
+	code, _ = create(30*time.Minute, 90*time.Minute, map[string]any{"allow_overlap": true})
+	as.Equal(http.StatusCreated, code)
+	res := as.authJSON(token, "/api/tracks/?allow_overlap=true").Post(map[string]any{"project": "Web", "start_at": base.Add(15 * time.Minute), "end_at": base.Add(45 * time.Minute)})
+	as.Equal(http.StatusCreated, res.Code)
+
+	// Moving an entry onto another is refused too, but not onto itself
+	res = as.authJSON(token, "/api/tracks/%s", second.ID).Patch(map[string]any{"start_at": base.Add(-time.Hour), "end_at": base.Add(-30 * time.Minute)})
+	as.Equal(http.StatusOK, res.Code)
+	res = as.authJSON(token, "/api/tracks/%s", second.ID).Patch(map[string]any{"start_at": base.Add(-time.Hour), "end_at": base.Add(10 * time.Minute)})
+	as.Equal(http.StatusConflict, res.Code)
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &conflict))
+	as.Equal(first.ID, conflict.Conflicts[0].ID)
+
+	// Other users' entries and deleted entries are ignored
+	fresh := as.registerToken("overlap-fresh@example.com")
+	entry := map[string]any{"project": "Web", "start_at": base, "end_at": base.Add(time.Hour)}
+	res = as.authJSON(fresh, "/api/tracks/").Post(entry)
+	as.Equal(http.StatusCreated, res.Code)
+	var item models.TimeTrac
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &item))
+	as.Equal(http.StatusOK, as.authJSON(fresh, "/api/tracks/%s", item.ID).Delete().Code)
+	as.Equal(http.StatusCreated, as.authJSON(fresh, "/api/tracks/").Post(entry).Code)
+}
@@ -0,0 +1,68 @@
+package actions
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"backend/models"
+)
+
+func (as *ActionSuite) Test_TracksExternalRef() {
+	token := as.registerToken("refs@example.com")
+	start := time.Now().Add(-6 * time.Hour).UTC().Truncate(time.Second)
+	create := func(offset time.Duration, ref string) models.TimeTrac {
+		res := as.authJSON(token, "/api/tracks/").Post(map[string]any{
+			"project": "Web", "external_ref": ref, "start_at": start.Add(offset), "end_at": start.Add(offset + time.Hour),
+		})
+		as.Equal(http.StatusCreated, res.Code)
+		var item models.TimeTrac
+		as.NoError(json.Unmarshal(res.Body.Bytes(), &item))
+		return item
+	}
+
+	acme := create(0, " ACME-123 ")
+	as.Equal("ACME-123", acme.ExternalRef.String)
+	repo := create(time.Hour, "org/repo#45")
+	plain := create(2*time.Hour, "")
+	as.False(plain.ExternalRef.Valid)
+	create(3*time.Hour, "ACME-123")
+
+	// Only length and characters are checked
+	res := as.authJSON(token, "/api/tracks/").Post(map[string]any{"external_ref": "not a ref", "start_at": start, "end_at": start.Add(time.Hour), "allow_overlap": true})
+	as.Equal(http.StatusUnprocessableEntity, res.Code)
+	as.Contains(res.Body.String(), "external_ref")
+
+	// Updates set and clear the reference
+	res = as.authJSON(token, "/api/tracks/%s", plain.ID).Patch(map[string]string{"external_ref": "org/repo#45"})
+	as.Equal(http.StatusOK, res.Code)
+	res = as.authJSON(token, "/api/tracks/%s", repo.ID).Patch(map[string]string{"external_ref": ""})
+	as.Equal(http.StatusOK, res.Code)
+	var cleared models.TimeTrac
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &cleared))
+	as.False(cleared.ExternalRef.Valid)
+
+	// The listing filters by reference
+	body := as.authJSON(token, "/api/tracks/?external_ref=ACME-123").Get().Body.String()
+	as.Contains(body, acme.ID.String())
+	as.NotContains(body, plain.ID.String())
+	as.NotContains(body, repo.ID.String())
+
+	// The summary groups by reference and leaves out entries without one
+	res = as.authJSON(token, "/api/tracks/refs/summary").Get()
+	as.Equal(http.StatusOK, res.Code)
+	var summary struct {
+		Refs []struct {
+			ExternalRef  string  `json:"external_ref"`
+			TotalSeconds float64 `json:"total_seconds"`
+			EntryCount   int     `json:"entry_count"`
+		} `json:"refs"`
+	}
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &summary))
+	as.Len(summary.Refs, 2)
+	as.Equal("ACME-123", summary.Refs[0].ExternalRef)
+	as.Equal(7200.0, summary.Refs[0].TotalSeconds)
+	as.Equal(2, summary.Refs[0].EntryCount)
+	as.Equal("org/repo#45", summary.Refs[1].ExternalRef)
+	as.Equal(1, summary.Refs[1].EntryCount)
+}
@@ -0,0 +1,61 @@
+package actions
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"backend/models"
+)
+
+func (as *ActionSuite) Test_TracksRestart() {
+	token := as.registerToken("restart@example.com")
+	start := time.Now().Add(-3 * time.Hour).UTC().Truncate(time.Second)
+	res := as.authJSON(token, "/api/tracks/").Post(map[string]any{
+		"project": "Web", "tags": []string{"ui", "bug"}, "note": "login form", "color": "#10b981",
+		"start_at": start, "end_at": start.Add(time.Hour),
+	})
+	as.Equal(http.StatusCreated, res.Code)
+	var src models.TimeTrac
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &src))
+	as.NoError(as.DB.RawQuery(`UPDATE timetrac SET location_lat = 48.2, location_lng = 16.37, location_addr = 'Vienna',
+		photo_key = 'tracks/x.jpg', photo_url = '/api/tracks/x/photo' WHERE id = ?`, src.ID).Exec())
+	running := as.startTrack(token)
+
+	var body struct {
+		Entry   models.TimeTrac  `json:"entry"`
+		Stopped *models.TimeTrac `json:"stopped"`
+	}
+	res = as.authJSON(token, "/api/tracks/%s/restart", src.ID).Post(nil)
+	as.Equal(http.StatusCreated, res.Code)
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &body))
+
+	// Project, tags, note and color are copied; location and photo are not
+	e := body.Entry
+	as.NotEqual(src.ID, e.ID)
+	as.Equal("Web", e.Project)
+	as.Equal([]string{"ui", "bug"}, []string(e.Tags))
+	as.Equal("login form", e.Note)
+	as.Equal("#10b981", e.Color)
+	as.False(e.EndAt.Valid)
+	as.WithinDuration(time.Now(), e.StartAt, time.Minute)
+	as.False(e.LocationLat.Valid)
+	as.False(e.LocationAddr.Valid)
+	as.False(e.PhotoURL.Valid)
+
+	// The previously running entry was stopped and is returned
+	as.NotNil(body.Stopped)
+	as.Equal(running.ID, body.Stopped.ID)
+	as.True(body.Stopped.EndAt.Valid)
+
+	// Restarting again stops the entry restarted before
+	res = as.authJSON(token, "/api/tracks/%s/restart", src.ID).Post(nil)
+	as.Equal(http.StatusCreated, res.Code)
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &body))
+	as.Equal(e.ID, body.Stopped.ID)
+
+	// Other users' entries cannot be restarted
+	other := as.registerToken("restart-other@example.com")
+	as.Equal(http.StatusNotFound, as.authJSON(other, "/api/tracks/%s/restart", src.ID).Post(nil).Code)
+	as.Equal(http.StatusBadRequest, as.authJSON(token, "/api/tracks/nope/restart").Post(nil).Code)
+}
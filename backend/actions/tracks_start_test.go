@@ -0,0 +1,48 @@
+package actions
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"backend/models"
+)
+
+func (as *ActionSuite) Test_TracksStart_StoppedEntry() {
+	token := as.registerToken("start-stopped@example.com")
+
+	type startResponse struct {
+		models.TimeTrac
+		StoppedEntry *models.TimeTrac `json:"stopped_entry"`
+	}
+	start := func(project string) startResponse {
+		res := as.authJSON(token, "/api/tracks/start").Post(map[string]string{"project": project})
+		as.Equal(http.StatusCreated, res.Code)
+		var body startResponse
+		as.NoError(json.Unmarshal(res.Body.Bytes(), &body))
+		return body
+	}
+
+	// Nothing was running
+	first := start("Web")
+	as.Nil(first.StoppedEntry)
+	as.False(first.EndAt.Valid)
+
+	// The running entry is stopped through the model and returned
+	second := start("Docs")
+	as.Equal("Docs", second.Project)
+	as.NotNil(second.StoppedEntry)
+	as.Equal(first.ID, second.StoppedEntry.ID)
+	as.True(second.StoppedEntry.EndAt.Valid)
+	as.False(second.StoppedEntry.UpdatedAt.Before(second.StoppedEntry.EndAt.Time))
+
+	var stored models.TimeTrac
+	as.NoError(as.DB.Find(&stored, first.ID))
+	as.True(stored.EndAt.Valid)
+	as.True(stored.UpdatedAt.After(first.UpdatedAt))
+
+	// Only the new entry is left running
+	running := []models.TimeTrac{}
+	as.NoError(as.DB.Where("user_id = ? AND end_at IS NULL", as.userID(token)).All(&running))
+	as.Len(running, 1)
+	as.Equal(second.ID, running[0].ID)
+}
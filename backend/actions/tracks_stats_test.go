@@ -0,0 +1,46 @@
+package actions
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+func (as *ActionSuite) Test_TracksTagsSummary() {
+	token := as.registerToken("tag-summary@example.com")
+
+	start := time.Now().Add(-6 * time.Hour).UTC().Truncate(time.Second)
+	for i, tags := range [][]string{{"client", "meeting"}, {"client"}, {}} {
+		at := start.Add(time.Duration(i) * time.Hour)
+		res := as.authJSON(token, "/api/tracks/").Post(map[string]any{
+			"tags":     tags,
+			"start_at": at,
+			"end_at":   at.Add(30 * time.Minute),
+		})
+		as.Equal(http.StatusCreated, res.Code)
+	}
+
+	from := url.QueryEscape(start.Add(-time.Hour).Format(time.RFC3339))
+	res := as.authJSON(token, "/api/tracks/tags/summary?from=%s", from).Get()
+	as.Equal(http.StatusOK, res.Code)
+
+	var body struct {
+		TotalSeconds float64 `json:"total_seconds"`
+		Overlapping  bool    `json:"overlapping"`
+		Tags         []struct {
+			Tag          string  `json:"tag"`
+			TotalSeconds float64 `json:"total_seconds"`
+			EntryCount   int     `json:"entry_count"`
+		} `json:"tags"`
+	}
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &body))
+	as.True(body.Overlapping)
+	as.InDelta(90*60, body.TotalSeconds, 1)
+
+	byTag := map[string]int{}
+	for _, t := range body.Tags {
+		byTag[t.Tag] = t.EntryCount
+	}
+	as.Equal(map[string]int{"client": 2, "meeting": 1, untaggedBucket: 1}, byTag)
+}
@@ -0,0 +1,140 @@
+package actions
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"backend/models"
+)
+
+func (as *ActionSuite) startTrack(token string) models.TimeTrac {
+	res := as.authJSON(token, "/api/tracks/start").Post(map[string]string{"project": "Web"})
+	as.Equal(http.StatusCreated, res.Code)
+
+	var item models.TimeTrac
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &item))
+	return item
+}
+
+func (as *ActionSuite) Test_TracksStop_DoubleStop() {
+	token := as.registerToken("double-stop@example.com")
+	item := as.startTrack(token)
+
+	res := as.authJSON(token, "/api/tracks/stop").Post(map[string]string{"id": item.ID.String()})
+	as.Equal(http.StatusOK, res.Code)
+
+	var stopped models.TimeTrac
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &stopped))
+
+	res = as.authJSON(token, "/api/tracks/stop").Post(map[string]string{"id": item.ID.String()})
+	as.Equal(http.StatusConflict, res.Code)
+
+	var body struct {
+		Entry models.TimeTrac `json:"entry"`
+	}
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &body))
+	as.True(body.Entry.EndAt.Time.Equal(stopped.EndAt.Time), "end_at must not change on a second stop")
+}
+
+func (as *ActionSuite) Test_TracksStop_Force() {
+	token := as.registerToken("force-stop@example.com")
+	item := as.startTrack(token)
+
+	res := as.authJSON(token, "/api/tracks/stop").Post(map[string]string{"id": item.ID.String()})
+	as.Equal(http.StatusOK, res.Code)
+
+	res = as.authJSON(token, "/api/tracks/stop").Post(map[string]any{"id": item.ID.String(), "force": true})
+	as.Equal(http.StatusOK, res.Code)
+
+	// A forced end that runs into a later entry is an overlap like in an edit
+	start := time.Now().Add(-3 * time.Hour).UTC().Truncate(time.Second)
+	as.NoError(as.DB.RawQuery("UPDATE timetrac SET start_at = ?, end_at = ? WHERE id = ?", start, start.Add(time.Hour), item.ID).Exec())
+	res = as.authJSON(token, "/api/tracks/").Post(map[string]any{"project": "Web", "start_at": start.Add(90 * time.Minute), "end_at": start.Add(2 * time.Hour)})
+	as.Equal(http.StatusCreated, res.Code)
+	res = as.authJSON(token, "/api/tracks/stop").Post(map[string]any{"id": item.ID.String(), "force": true})
+	as.Equal(http.StatusConflict, res.Code)
+	as.Contains(res.Body.String(), "conflicts")
+	res = as.authJSON(token, "/api/tracks/stop").Post(map[string]any{"id": item.ID.String(), "force": true, "allow_overlap": true})
+	as.Equal(http.StatusOK, res.Code)
+}
+
+func (as *ActionSuite) Test_TracksStop_Retroactive() {
+	token := as.registerToken("retro-stop@example.com")
+	item := as.startTrack(token)
+
+	// Move the start into the past so there is room for a retroactive end
+	item.StartAt = time.Now().Add(-time.Hour)
+	as.NoError(as.DB.RawQuery("UPDATE timetrac SET start_at = ? WHERE id = ?", item.StartAt, item.ID).Exec())
+
+	// end_at in the future is rejected
+	res := as.authJSON(token, "/api/tracks/stop").Post(map[string]any{
+		"id":     item.ID.String(),
+		"end_at": time.Now().Add(time.Hour),
+	})
+	as.Equal(http.StatusUnprocessableEntity, res.Code)
+
+	// end_at before start_at is rejected
+	res = as.authJSON(token, "/api/tracks/stop").Post(map[string]any{
+		"id":     item.ID.String(),
+		"end_at": item.StartAt.Add(-time.Minute),
+	})
+	as.Equal(http.StatusUnprocessableEntity, res.Code)
+
+	// A past end_at after start_at is accepted as-is
+	end := time.Now().Add(-30 * time.Minute).Truncate(time.Second)
+	res = as.authJSON(token, "/api/tracks/stop").Post(map[string]any{
+		"id":     item.ID.String(),
+		"end_at": end,
+	})
+	as.Equal(http.StatusOK, res.Code)
+
+	var stopped models.TimeTrac
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &stopped))
+	as.True(stopped.EndAt.Time.Equal(end))
+}
+
+func (as *ActionSuite) Test_TracksStop_RetroactiveWhilePaused() {
+	token := as.registerToken("retro-paused@example.com")
+	item := as.startTrack(token)
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+	as.NoError(as.DB.RawQuery("UPDATE timetrac SET start_at = ? WHERE id = ?", start, item.ID).Exec())
+
+	res := as.authJSON(token, "/api/tracks/%s/pause", item.ID).Post(nil)
+	as.Equal(http.StatusOK, res.Code)
+
+	// Ending the entry before the pause began closes the pause at its start
+	end := start.Add(30 * time.Minute)
+	res = as.authJSON(token, "/api/tracks/stop").Post(map[string]any{"id": item.ID.String(), "end_at": end})
+	as.Equal(http.StatusOK, res.Code)
+
+	var pause models.TrackPause
+	as.NoError(as.DB.Where("track_id = ?", item.ID).First(&pause))
+	as.True(pause.ResumedAt.Valid)
+	as.True(pause.ResumedAt.Time.Equal(pause.PausedAt))
+
+	var net struct {
+		Seconds float64 `db:"seconds"`
+	}
+	as.NoError(as.DB.RawQuery("SELECT "+trackNetSecondsSQL+" AS seconds FROM timetrac t WHERE t.id = ?", item.ID).First(&net))
+	as.Equal(float64(30*60), net.Seconds)
+}
+
+func (as *ActionSuite) Test_TracksStop_DiscardToTrash() {
+	token := as.registerToken("discard-stop@example.com")
+	res := as.authJSON(token, "/api/me/preferences").Patch(map[string]any{"discard_under_seconds": 60})
+	as.Equal(http.StatusOK, res.Code)
+	item := as.startTrack(token)
+
+	res = as.authJSON(token, "/api/tracks/stop").Post(map[string]string{"id": item.ID.String()})
+	as.Equal(http.StatusOK, res.Code)
+	as.Contains(res.Body.String(), `"discarded":true`)
+
+	// The accidental entry is in trash, not gone, and can be restored
+	var trashed models.TimeTrac
+	as.NoError(as.DB.Find(&trashed, item.ID))
+	as.True(trashed.DeletedAt.Valid)
+	as.True(trashed.EndAt.Valid)
+	res = as.authJSON(token, "/api/tracks/%s/restore", item.ID).Post(nil)
+	as.Equal(http.StatusOK, res.Code)
+}
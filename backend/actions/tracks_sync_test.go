@@ -0,0 +1,50 @@
+package actions
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+func (as *ActionSuite) Test_TracksSync_CreateIsIdempotentAndConflictsDetected() {
+	token := as.registerToken("sync@example.com")
+	id := uuid.Must(uuid.NewV4())
+	start := time.Now().Add(-2 * time.Hour).UTC()
+
+	sync := func(ops ...map[string]any) []syncResult {
+		res := as.authJSON(token, "/api/tracks/sync").Post(map[string]any{"operations": ops})
+		as.Equal(http.StatusOK, res.Code)
+		var body struct {
+			Results []syncResult `json:"results"`
+		}
+		as.NoError(json.Unmarshal(res.Body.Bytes(), &body))
+		return body.Results
+	}
+	create := map[string]any{
+		"type": "create",
+		"id":   id,
+		"data": map[string]any{"project": "Offline", "start_at": start, "end_at": start.Add(time.Hour)},
+	}
+
+	first := sync(create)
+	as.Equal(syncApplied, first[0].Status)
+	replay := sync(create)
+	as.Equal(syncApplied, replay[0].Status)
+	as.True(first[0].Entry.UpdatedAt.Equal(replay[0].Entry.UpdatedAt), "a replayed create must not change the entry")
+
+	// Device A edits based on the created version
+	base := first[0].Entry.UpdatedAt
+	edit := sync(map[string]any{"type": "update", "id": id, "base_updated_at": base, "data": map[string]any{"note": "from A"}})
+	as.Equal(syncApplied, edit[0].Status)
+
+	// Device B edits the same stale version: conflict with the server copy
+	stale := sync(
+		map[string]any{"type": "update", "id": id, "base_updated_at": base, "data": map[string]any{"note": "from B"}},
+		map[string]any{"type": "update", "id": uuid.Must(uuid.NewV4()), "base_updated_at": base},
+	)
+	as.Equal(syncConflict, stale[0].Status)
+	as.Equal("from A", stale[0].Entry.Note)
+	as.Equal(syncError, stale[1].Status)
+}
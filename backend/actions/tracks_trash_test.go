@@ -0,0 +1,68 @@
+package actions
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"backend/models"
+)
+
+func (as *ActionSuite) Test_TracksTrash() {
+	token := as.registerToken("trash@example.com")
+	start := time.Now().Add(-5 * time.Hour).UTC().Truncate(time.Second)
+	create := func(offset time.Duration) models.TimeTrac {
+		res := as.authJSON(token, "/api/tracks/").Post(map[string]any{"project": "Web", "start_at": start.Add(offset), "end_at": start.Add(offset + time.Hour)})
+		as.Equal(http.StatusCreated, res.Code)
+		var item models.TimeTrac
+		as.NoError(json.Unmarshal(res.Body.Bytes(), &item))
+		return item
+	}
+	kept, trashed := create(0), create(2*time.Hour)
+	trash := func() []models.TimeTrac {
+		res := as.authJSON(token, "/api/tracks/trash").Get()
+		as.Equal(http.StatusOK, res.Code)
+		var list []models.TimeTrac
+		as.NoError(json.Unmarshal(res.Body.Bytes(), &list))
+		return list
+	}
+
+	// Deleting moves the entry to trash and out of the listing
+	as.Equal(http.StatusOK, as.authJSON(token, "/api/tracks/%s", trashed.ID).Delete().Code)
+	index := as.authJSON(token, "/api/tracks/").Get().Body.String()
+	as.Contains(index, kept.ID.String())
+	as.NotContains(index, trashed.ID.String())
+	list := trash()
+	as.Len(list, 1)
+	as.Equal(trashed.ID, list[0].ID)
+
+	// Only the owner restores, and only from trash
+	other := as.registerToken("trash-other@example.com")
+	as.Equal(http.StatusNotFound, as.authJSON(other, "/api/tracks/%s/restore", trashed.ID).Post(nil).Code)
+	as.Equal(http.StatusNotFound, as.authJSON(token, "/api/tracks/%s/restore", kept.ID).Post(nil).Code)
+	res := as.authJSON(token, "/api/tracks/%s/restore", trashed.ID).Post(nil)
+	as.Equal(http.StatusOK, res.Code)
+	var restored models.TimeTrac
+	as.NoError(json.Unmarshal(res.Body.Bytes(), &restored))
+	as.False(restored.DeletedAt.Valid)
+	as.Empty(trash())
+	as.Contains(as.authJSON(token, "/api/tracks/").Get().Body.String(), trashed.ID.String())
+
+	// hard=true removes the row right away
+	as.Equal(http.StatusOK, as.authJSON(token, "/api/tracks/%s?hard=true", kept.ID).Delete().Code)
+	n, err := as.DB.Where("id = ?", kept.ID).Count(&models.TimeTrac{})
+	as.NoError(err)
+	as.Zero(n)
+	as.Empty(trash())
+
+	// Trash is purged after the retention period
+	as.Equal(http.StatusOK, as.authJSON(token, "/api/tracks/%s", trashed.ID).Delete().Code)
+	now := time.Now()
+	n, err = PurgeTrashedTracks(as.DB, now)
+	as.NoError(err)
+	as.Zero(n)
+	n, err = PurgeTrashedTracks(as.DB, now.Add(models.TrashRetention+time.Minute))
+	as.NoError(err)
+	as.Equal(1, n)
+	as.Empty(trash())
+}
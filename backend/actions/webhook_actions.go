@@ -0,0 +1,386 @@
+/**
+ * Webhook Actions - Outgoing Notifications for Track Events
+ *
+ * This file provides the endpoints managing a user's webhooks and their
+ * recent deliveries, and queues deliveries for track events and for
+ * changes to the teams the user belongs to.
+ *
+ * Deliveries are inserted in the request transaction (see
+ * queueWebhookDeliveries) so an event is only sent when the change it
+ * describes commits; the worker in webhook_jobs.go sends them.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-21
+ */
+package actions
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"backend/models"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gofrs/uuid"
+)
+
+// webhookDeliveriesShown is the number of deliveries listed per webhook.
+const webhookDeliveriesShown = 50
+
+// webhookMinSecret is the minimum length of a caller-provided secret.
+const webhookMinSecret = 16
+
+/**
+ * webhookPayload is the request body for creating and updating webhooks;
+ * nil fields are left unchanged on update
+ */
+type webhookPayload struct {
+	URL    *string   `json:"url"`
+	Secret *string   `json:"secret"`
+	Events *[]string `json:"events"`
+	Active *bool     `json:"active"`
+}
+
+/**
+ * apply copies the provided fields onto w and validates the result
+ *
+ * @return string - Validation error message, "" if valid
+ */
+func (p webhookPayload) apply(w *models.Webhook) string {
+	if p.URL != nil {
+		w.URL = strings.TrimSpace(*p.URL)
+	}
+	if p.Secret != nil {
+		w.Secret = strings.TrimSpace(*p.Secret)
+	}
+	if p.Events != nil {
+		events := []string{}
+		for _, e := range *p.Events {
+			e = strings.TrimSpace(e)
+			if !slices.Contains(models.WebhookEvents, e) {
+				return "unknown event " + e
+			}
+			if !slices.Contains(events, e) {
+				events = append(events, e)
+			}
+		}
+		w.Events = events
+	}
+	if p.Active != nil {
+		w.Active = *p.Active
+	}
+
+	if msg := validWebhookURL(w.URL); msg != "" {
+		return msg
+	}
+	if len(w.Secret) < webhookMinSecret {
+		return "secret must be at least 16 characters"
+	}
+	if len(w.Events) == 0 {
+		return "events required"
+	}
+	return ""
+}
+
+/**
+ * validWebhookURL checks that raw is an absolute http(s) URL whose host
+ * is not obviously internal; names resolving to internal addresses are
+ * refused when delivering (see webhookDialControl)
+ *
+ * @return string - Validation error message, "" if valid
+ */
+func validWebhookURL(raw string) string {
+	if raw == "" {
+		return "url required"
+	}
+	if len(raw) > 2048 {
+		return "url too long"
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "url must be an absolute http or https URL"
+	}
+	if u.User != nil {
+		return "url must not contain credentials"
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return "url must not point to a local or private address"
+	}
+	if ip, err := netip.ParseAddr(host); err == nil && blockedWebhookIP(ip) {
+		return "url must not point to a local or private address"
+	}
+	return ""
+}
+
+/**
+ * newWebhookSecret returns a random signing secret
+ */
+func newWebhookSecret() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(buf), nil
+}
+
+/**
+ * findWebhook loads one of the user's webhooks by the {id} route param
+ *
+ * @return int - HTTP status of the failure, 0 on success
+ */
+func findWebhook(c buffalo.Context, tx *pop.Connection, w *models.Webhook) (int, string) {
+	id, err := uuid.FromString(c.Param("id"))
+	if err != nil {
+		return http.StatusBadRequest, "bad id"
+	}
+	uid, ok := currentUserID(c)
+	if !ok {
+		return http.StatusUnauthorized, "unauthorized"
+	}
+	if err := tx.Where("id = ? AND user_id = ?", id, uid).First(w); err != nil {
+		return http.StatusNotFound, "not found"
+	}
+	return 0, ""
+}
+
+/**
+ * WebhooksIndex lists the authenticated user's webhooks
+ *
+ * GET /api/webhooks
+ *
+ * Secrets are not included.
+ *
+ * @param c - Buffalo context with authenticated user
+ * @return JSON array of Webhook or error response
+ */
+func WebhooksIndex(c buffalo.Context) error {
+	tx := mustTx(c)
+	uid, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+
+	list := []models.Webhook{}
+	if err := tx.Where("user_id = ?", uid).Order("created_at ASC").All(&list); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+	return c.Render(http.StatusOK, r.JSON(list))
+}
+
+/**
+ * WebhooksCreate subscribes an endpoint to track events
+ *
+ * POST /api/webhooks
+ *
+ * Payload:
+ * - url: http(s) endpoint (required)
+ * - events: Subsets of track.started, track.stopped, track.deleted,
+ *   team.updated, project.budget (default: all)
+ * - secret: Signing secret, at least 16 characters (default: generated)
+ * - active: Default true
+ *
+ * The response is the only one that includes the secret. A user may keep
+ * up to 10 webhooks; creating more returns 409.
+ *
+ * @param c - Buffalo context with authenticated user
+ * @return JSON Webhook with secret or error response
+ */
+func WebhooksCreate(c buffalo.Context) error {
+	var p webhookPayload
+	if err := c.Bind(&p); err != nil {
+		return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "bad payload"}))
+	}
+
+	tx := mustTx(c)
+	uid, ok := currentUserID(c)
+	if !ok {
+		return c.Render(http.StatusUnauthorized, r.JSON(map[string]string{"error": "unauthorized"}))
+	}
+
+	count, err := tx.Where("user_id = ?", uid).Count(&models.Webhook{})
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+	if count >= models.MaxWebhooks {
+		return c.Render(http.StatusConflict, r.JSON(map[string]string{"error": "webhook limit reached"}))
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot create"}))
+	}
+	w := models.Webhook{UserID: uid, Secret: secret, Events: slices.Clone(models.WebhookEvents), Active: true}
+	if msg := p.apply(&w); msg != "" {
+		return c.Render(http.StatusUnprocessableEntity, r.JSON(map[string]string{"error": msg}))
+	}
+
+	if err := tx.Create(&w); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot create"}))
+	}
+	return c.Render(http.StatusCreated, r.JSON(struct {
+		models.Webhook
+		Secret string `json:"secret"`
+	}{w, w.Secret}))
+}
+
+/**
+ * WebhooksUpdate modifies a webhook
+ *
+ * PATCH /api/webhooks/{id}
+ *
+ * Accepts the same fields as WebhooksCreate, all optional. A new secret
+ * applies to deliveries attempted from then on, including retries.
+ *
+ * @param c - Buffalo context with authenticated user and webhook ID
+ * @return JSON Webhook or error response
+ */
+func WebhooksUpdate(c buffalo.Context) error {
+	var p webhookPayload
+	if err := c.Bind(&p); err != nil {
+		return c.Render(http.StatusBadRequest, r.JSON(map[string]string{"error": "bad payload"}))
+	}
+
+	tx := mustTx(c)
+	var w models.Webhook
+	if status, msg := findWebhook(c, tx, &w); status != 0 {
+		return c.Render(status, r.JSON(map[string]string{"error": msg}))
+	}
+	if msg := p.apply(&w); msg != "" {
+		return c.Render(http.StatusUnprocessableEntity, r.JSON(map[string]string{"error": msg}))
+	}
+
+	w.UpdatedAt = time.Now()
+	if err := tx.Update(&w); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot update"}))
+	}
+	return c.Render(http.StatusOK, r.JSON(w))
+}
+
+/**
+ * WebhooksDelete removes a webhook and its deliveries
+ *
+ * DELETE /api/webhooks/{id}
+ *
+ * @param c - Buffalo context with authenticated user and webhook ID
+ * @return JSON status or error response
+ */
+func WebhooksDelete(c buffalo.Context) error {
+	tx := mustTx(c)
+	var w models.Webhook
+	if status, msg := findWebhook(c, tx, &w); status != 0 {
+		return c.Render(status, r.JSON(map[string]string{"error": msg}))
+	}
+	if err := tx.Destroy(&w); err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "cannot delete"}))
+	}
+	return c.Render(http.StatusOK, r.JSON(map[string]string{"status": "deleted"}))
+}
+
+/**
+ * WebhooksDeliveries lists a webhook's most recent deliveries
+ *
+ * GET /api/webhooks/{id}/deliveries
+ *
+ * Returns up to 50 deliveries, newest first, with their payload, status,
+ * attempt count and the HTTP status or error of the latest attempt.
+ * Finished deliveries are kept for 30 days.
+ *
+ * @param c - Buffalo context with authenticated user and webhook ID
+ * @return JSON array of WebhookDelivery or error response
+ */
+func WebhooksDeliveries(c buffalo.Context) error {
+	tx := mustTx(c)
+	var w models.Webhook
+	if status, msg := findWebhook(c, tx, &w); status != 0 {
+		return c.Render(status, r.JSON(map[string]string{"error": msg}))
+	}
+
+	list := []models.WebhookDelivery{}
+	err := tx.Where("webhook_id = ?", w.ID).
+		Order("created_at DESC").
+		Limit(webhookDeliveriesShown).
+		All(&list)
+	if err != nil {
+		return c.Render(http.StatusInternalServerError, r.JSON(map[string]string{"error": "db error"}))
+	}
+	return c.Render(http.StatusOK, r.JSON(list))
+}
+
+/**
+ * webhookBody is the JSON body POSTed to webhooks
+ */
+type webhookBody struct {
+	Event      string          `json:"event"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Entry      models.TimeTrac `json:"entry"`
+}
+
+/**
+ * queueWebhookDeliveries inserts a pending delivery of the event for each
+ * of the user's active webhooks subscribed to it. Events webhooks cannot
+ * subscribe to are ignored.
+ *
+ * @param tx - Request transaction
+ * @param name - Event name
+ * @param item - Entry the event is about
+ * @param now - Event time
+ * @param requestID - ID of the request raising the event
+ */
+func queueWebhookDeliveries(tx *pop.Connection, name string, item models.TimeTrac, now time.Time, requestID string) error {
+	if !slices.Contains(models.WebhookEvents, name) {
+		return nil
+	}
+	body, err := json.Marshal(webhookBody{Event: name, OccurredAt: now.UTC(), Entry: item})
+	if err != nil {
+		return err
+	}
+	return tx.RawQuery(`
+		INSERT INTO webhook_deliveries (webhook_id, event, payload, status, attempts, next_attempt_at, request_id, created_at, updated_at)
+		SELECT id, ?, ?, ?, 0, ?, ?, ?, ?
+		FROM webhooks
+		WHERE user_id = ? AND active AND ? = ANY(events)
+	`, name, string(body), models.WebhookDeliveryPending, now.UTC(), requestID, now.UTC(), now.UTC(), item.UserID, name).Exec()
+}
+
+/**
+ * teamWebhookBody is the JSON body POSTed to webhooks for team events
+ */
+type teamWebhookBody struct {
+	Event      string      `json:"event"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Team       models.Team `json:"team"`
+}
+
+/**
+ * queueTeamWebhookDeliveries inserts a pending delivery of a team event
+ * for each active webhook, subscribed to it, of the team's active members
+ *
+ * @param tx - Request transaction
+ * @param name - Event name
+ * @param team - Team the event is about
+ * @param now - Event time
+ * @param requestID - ID of the request raising the event
+ */
+func queueTeamWebhookDeliveries(tx *pop.Connection, name string, team models.Team, now time.Time, requestID string) error {
+	body, err := json.Marshal(teamWebhookBody{Event: name, OccurredAt: now.UTC(), Team: team})
+	if err != nil {
+		return err
+	}
+	return tx.RawQuery(`
+		INSERT INTO webhook_deliveries (webhook_id, event, payload, status, attempts, next_attempt_at, request_id, created_at, updated_at)
+		SELECT w.id, ?, ?, ?, 0, ?, ?, ?, ?
+		FROM webhooks w
+		JOIN team_members tm ON tm.user_id = w.user_id
+		WHERE tm.team_id = ? AND tm.status = 'active' AND w.active AND ? = ANY(w.events)
+	`, name, string(body), models.WebhookDeliveryPending, now.UTC(), requestID, now.UTC(), now.UTC(), team.ID, name).Exec()
+}
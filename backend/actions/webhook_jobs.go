@@ -0,0 +1,316 @@
+/**
+ * Webhook Jobs - Background Delivery of Webhook Events
+ *
+ * A worker polls webhook_deliveries for due pending rows and POSTs their
+ * payload to the webhook URL:
+ * - The body is signed with HMAC-SHA256 using the webhook's secret; the
+ *   hex digest is sent as `X-Signature: sha256=<hex>`
+ * - X-Webhook-Event and X-Webhook-Delivery carry the event name and the
+ *   delivery ID, which stays the same across retries; X-Request-ID the ID
+ *   of the request that raised the event
+ * - Any 2xx response counts as delivered. Other responses and network
+ *   errors are retried with exponential backoff (models.WebhookBackoff)
+ *   until models.WebhookMaxAttempts attempts have failed
+ * - Only the status code of a response is recorded, never its body, and
+ *   redirects are not followed
+ * - Webhooks cannot reach loopback, private, link-local or otherwise
+ *   internal addresses (cloud metadata included): the address is checked
+ *   when connecting, after DNS resolution, so a name resolving to one is
+ *   refused however it resolved when the webhook was saved
+ *
+ * Rows are claimed with FOR UPDATE SKIP LOCKED and leased by moving their
+ * next_attempt_at, so several app instances can run the worker.
+ *
+ * Configuration:
+ * - WEBHOOK_POLL_SECONDS: poll interval (default 5), 0 disables
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-21
+ */
+package actions
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"backend/models"
+
+	"github.com/gobuffalo/buffalo"
+	"github.com/gobuffalo/envy"
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gofrs/uuid"
+)
+
+const (
+	// webhookBatchSize is the number of deliveries claimed per poll.
+	webhookBatchSize = 20
+	// webhookTimeout bounds one POST including reading the response.
+	webhookTimeout = 10 * time.Second
+	// webhookLease keeps a claimed delivery from being claimed again
+	// while it is being sent.
+	webhookLease = 2 * time.Minute
+	// webhookDeliveryRetention is how long finished deliveries are kept.
+	webhookDeliveryRetention = 30 * 24 * time.Hour
+)
+
+/**
+ * dueWebhookDelivery is a claimed delivery with its target
+ */
+type dueWebhookDelivery struct {
+	ID        uuid.UUID `db:"id"`
+	Event     string    `db:"event"`
+	Payload   string    `db:"payload"`
+	Attempts  int       `db:"attempts"`
+	RequestID string    `db:"request_id"`
+	URL       string    `db:"url"`
+	Secret    string    `db:"secret"`
+}
+
+/**
+ * signWebhook returns the X-Signature value for a body
+ *
+ * @param secret - Webhook secret
+ * @param body - Exact request body
+ * @return string - "sha256=" followed by the hex HMAC-SHA256
+ */
+func signWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// errWebhookAddress refuses connections to internal addresses.
+var errWebhookAddress = errors.New("webhook address not allowed")
+
+// webhookBlockedPrefixes are internal ranges netip has no predicate for.
+var webhookBlockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),     // "This" network
+	netip.MustParsePrefix("100.64.0.0/10"), // Carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),  // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"), // Benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),   // Reserved, broadcast
+	netip.MustParsePrefix("64:ff9b::/96"),  // NAT64 of IPv4 addresses
+	netip.MustParsePrefix("fd00:ec2::/32"), // AWS metadata over IPv6
+	netip.MustParsePrefix("2001:db8::/32"), // Documentation
+}
+
+/**
+ * blockedWebhookIP reports whether webhooks may not connect to ip:
+ * loopback, private, link-local (169.254.169.254, the metadata endpoint
+ * of cloud providers, included), multicast, unspecified and reserved
+ * addresses
+ */
+func blockedWebhookIP(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if !ip.IsValid() || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return true
+	}
+	for _, p := range webhookBlockedPrefixes {
+		if p.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * webhookDialControl refuses connections to blocked addresses; it runs
+ * on the resolved address of every connection attempt
+ */
+func webhookDialControl(network, address string, _ syscall.RawConn) error {
+	ap, err := netip.ParseAddrPort(address)
+	if err != nil || blockedWebhookIP(ap.Addr()) {
+		return errWebhookAddress
+	}
+	return nil
+}
+
+/**
+ * newWebhookClient returns the HTTP client sending deliveries: it connects
+ * to public addresses only, directly rather than through a proxy, and
+ * does not follow redirects
+ */
+func newWebhookClient() *http.Client {
+	dialer := &net.Dialer{Timeout: webhookTimeout, Control: webhookDialControl}
+	return &http.Client{
+		Timeout: webhookTimeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: webhookTimeout,
+			MaxIdleConns:        webhookBatchSize,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+/**
+ * startWebhookWorker starts polling for due webhook deliveries
+ */
+func startWebhookWorker(app *buffalo.App) {
+	seconds, err := strconv.Atoi(envy.Get("WEBHOOK_POLL_SECONDS", "5"))
+	if err != nil || seconds <= 0 {
+		return
+	}
+	client := newWebhookClient()
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(seconds) * time.Second)
+		defer ticker.Stop()
+		for range ticker.C {
+			if _, err := DeliverWebhooks(models.DB, client, time.Now()); err != nil {
+				app.Logger.Errorf("webhooks: %v", err)
+			}
+		}
+	}()
+}
+
+/**
+ * DeliverWebhooks claims due deliveries and sends them concurrently
+ *
+ * @param db - Database connection
+ * @param client - HTTP client used for the POSTs
+ * @param now - Reference time
+ * @return int - Number of deliveries attempted
+ */
+func DeliverWebhooks(db *pop.Connection, client *http.Client, now time.Time) (int, error) {
+	due := []dueWebhookDelivery{}
+	err := db.RawQuery(`
+		UPDATE webhook_deliveries d SET next_attempt_at = ?, updated_at = ?
+		FROM webhooks w
+		WHERE w.id = d.webhook_id AND d.id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = ? AND next_attempt_at <= ?
+			ORDER BY next_attempt_at
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING d.id, d.event, d.payload, d.attempts, d.request_id, w.url, w.secret
+	`, now.Add(webhookLease).UTC(), now.UTC(), models.WebhookDeliveryPending, now.UTC(), webhookBatchSize).All(&due)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for _, d := range due {
+		wg.Add(1)
+		go func(d dueWebhookDelivery) {
+			defer wg.Done()
+			code, sendErr := sendWebhook(client, d)
+			if err := recordWebhookAttempt(db, d, code, sendErr, time.Now()); err != nil {
+				mu.Lock()
+				errs = append(errs, withRequestID(fmt.Errorf("delivery %s: %w", d.ID, err), d.RequestID))
+				mu.Unlock()
+			}
+		}(d)
+	}
+	wg.Wait()
+	return len(due), errors.Join(errs...)
+}
+
+/**
+ * sendWebhook POSTs one delivery
+ *
+ * @return int - HTTP status code, 0 if no response was received
+ * @return error - Network error or non-2xx response
+ */
+func sendWebhook(client *http.Client, d dueWebhookDelivery) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+	defer cancel()
+
+	body := []byte(d.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "TimeTrac-Webhooks/1.0")
+	req.Header.Set("X-Signature", signWebhook(d.Secret, body))
+	req.Header.Set("X-Webhook-Event", d.Event)
+	req.Header.Set("X-Webhook-Delivery", d.ID.String())
+	if d.RequestID != "" {
+		req.Header.Set(requestIDHeader, d.RequestID)
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	// The body is drained to reuse the connection, never recorded
+	io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("HTTP %d", res.StatusCode)
+	}
+	return res.StatusCode, nil
+}
+
+/**
+ * recordWebhookAttempt stores the outcome of an attempt and schedules the
+ * retry or marks the delivery finished
+ */
+func recordWebhookAttempt(db *pop.Connection, d dueWebhookDelivery, code int, sendErr error, now time.Time) error {
+	attempts := d.Attempts + 1
+	var status any
+	if code != 0 {
+		status = code
+	}
+
+	if sendErr == nil {
+		return db.RawQuery(`
+			UPDATE webhook_deliveries
+			SET status = ?, attempts = ?, last_status_code = ?, last_error = NULL, delivered_at = ?, updated_at = ?
+			WHERE id = ?
+		`, models.WebhookDeliverySucceeded, attempts, status, now.UTC(), now.UTC(), d.ID).Exec()
+	}
+
+	state := models.WebhookDeliveryPending
+	if attempts >= models.WebhookMaxAttempts {
+		state = models.WebhookDeliveryFailed
+	}
+	errMsg := sendErr.Error()
+	if len(errMsg) > 500 {
+		errMsg = errMsg[:500]
+	}
+	errMsg = strings.ToValidUTF8(errMsg, "")
+	return db.RawQuery(`
+		UPDATE webhook_deliveries
+		SET status = ?, attempts = ?, last_status_code = ?, last_error = ?, next_attempt_at = ?, updated_at = ?
+		WHERE id = ?
+	`, state, attempts, status, errMsg, now.Add(models.WebhookBackoff(attempts)).UTC(), now.UTC(), d.ID).Exec()
+}
+
+/**
+ * PurgeWebhookDeliveries deletes finished deliveries older than 30 days
+ *
+ * @param db - Database connection
+ * @param now - Reference time
+ * @return int - Number of deleted deliveries
+ */
+func PurgeWebhookDeliveries(db *pop.Connection, now time.Time) (int, error) {
+	return db.RawQuery(`DELETE FROM webhook_deliveries WHERE status <> ? AND created_at < ?`,
+		models.WebhookDeliveryPending, now.Add(-webhookDeliveryRetention).UTC()).ExecWithCount()
+}
@@ -0,0 +1,217 @@
+/**
+ * AppleID - Verification of Sign in with Apple Identity Tokens
+ *
+ * This package verifies the identity token (a JWT) the iOS app receives
+ * from Sign in with Apple:
+ * - RS256 signature against Apple's published keys, cached and refetched
+ *   when a token names an unknown key (Apple rotates keys)
+ * - Issuer, audience (the app's bundle or service ID) and expiry
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-21
+ */
+package appleid
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// DefaultKeysURL is Apple's JWKS endpoint.
+const DefaultKeysURL = "https://appleid.apple.com/auth/keys"
+
+// Issuer is the iss claim of Apple identity tokens.
+const Issuer = "https://appleid.apple.com"
+
+// PrivateRelayDomain is the domain of "Hide My Email" addresses.
+const PrivateRelayDomain = "privaterelay.appleid.com"
+
+const (
+	// keysMaxAge is how long fetched keys are used without refetching.
+	keysMaxAge = 24 * time.Hour
+	// refetchInterval limits refetches triggered by unknown key IDs.
+	refetchInterval = time.Minute
+)
+
+// ErrUnknownKey is returned for tokens signed by a key Apple does not publish.
+var ErrUnknownKey = errors.New("appleid: unknown signing key")
+
+/**
+ * flexBool decodes Apple's boolean claims, sent either as JSON booleans
+ * or as the strings "true" and "false"
+ */
+type flexBool bool
+
+func (b *flexBool) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	*b = flexBool(s == "true")
+	return nil
+}
+
+/**
+ * Claims are the identity token claims used for sign-in
+ */
+type Claims struct {
+	Email          string   `json:"email"`
+	EmailVerified  flexBool `json:"email_verified"`
+	IsPrivateEmail flexBool `json:"is_private_email"`
+	jwt.RegisteredClaims
+}
+
+/**
+ * PrivateRelay reports whether the email is an Apple private relay
+ * address
+ */
+func (c *Claims) PrivateRelay() bool {
+	return bool(c.IsPrivateEmail) || strings.HasSuffix(strings.ToLower(c.Email), "@"+PrivateRelayDomain)
+}
+
+/**
+ * Verifier checks identity tokens for a set of client IDs
+ */
+type Verifier struct {
+	KeysURL   string   // JWKS endpoint, DefaultKeysURL if empty
+	Audiences []string // Accepted client IDs (bundle ID, service ID)
+	HTTP      *http.Client
+
+	mu          sync.Mutex
+	keys        map[string]*rsa.PublicKey
+	fetchedAt   time.Time
+	lastAttempt time.Time
+}
+
+/**
+ * NewVerifier returns a Verifier accepting tokens for the given client IDs
+ */
+func NewVerifier(keysURL string, audiences []string) *Verifier {
+	if keysURL == "" {
+		keysURL = DefaultKeysURL
+	}
+	return &Verifier{
+		KeysURL:   keysURL,
+		Audiences: audiences,
+		HTTP:      &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+/**
+ * Verify validates an identity token and returns its claims
+ *
+ * @param ctx - Context for a key fetch
+ * @param token - Identity token from the app
+ * @param now - Reference time for expiry
+ * @return *Claims - Verified claims, with a non-empty subject
+ */
+func (v *Verifier) Verify(ctx context.Context, token string, now time.Time) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.key(ctx, kid, now)
+	},
+		jwt.WithValidMethods([]string{"RS256"}),
+		jwt.WithIssuer(Issuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(func() time.Time { return now }),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if !slices.ContainsFunc(claims.Audience, func(aud string) bool { return slices.Contains(v.Audiences, aud) }) {
+		return nil, fmt.Errorf("appleid: audience %v not accepted", claims.Audience)
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("appleid: token has no subject")
+	}
+	return claims, nil
+}
+
+/**
+ * key returns the public key with the given ID, fetching Apple's keys
+ * when the cache is stale or lacks the ID
+ */
+func (v *Verifier) key(ctx context.Context, kid string, now time.Time) (*rsa.PublicKey, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	stale := now.Sub(v.fetchedAt) > keysMaxAge
+	if key, ok := v.keys[kid]; ok && !stale {
+		return key, nil
+	}
+	if stale || now.Sub(v.lastAttempt) >= refetchInterval {
+		v.lastAttempt = now
+		keys, err := v.fetch(ctx)
+		if err != nil {
+			// Keep verifying with the old keys while Apple is unreachable
+			if key, ok := v.keys[kid]; ok {
+				return key, nil
+			}
+			return nil, err
+		}
+		v.keys, v.fetchedAt = keys, now
+	}
+	if key, ok := v.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, ErrUnknownKey
+}
+
+/**
+ * fetch downloads and decodes Apple's JWKS
+ */
+func (v *Verifier) fetch(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.KeysURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := v.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("appleid: keys endpoint returned %d", res.StatusCode)
+	}
+
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&set); err != nil {
+		return nil, err
+	}
+
+	keys := map[string]*rsa.PublicKey{}
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		n, errN := base64.RawURLEncoding.DecodeString(k.N)
+		e, errE := base64.RawURLEncoding.DecodeString(k.E)
+		if errN != nil || errE != nil {
+			continue
+		}
+		keys[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("appleid: no usable keys")
+	}
+	return keys, nil
+}
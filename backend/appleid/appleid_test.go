@@ -0,0 +1,128 @@
+package appleid
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func testKeyServer(t *testing.T, keys map[string]*rsa.PublicKey) (*httptest.Server, *int) {
+	t.Helper()
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		set := map[string][]map[string]string{"keys": {}}
+		for kid, pub := range keys {
+			set["keys"] = append(set["keys"], map[string]string{
+				"kty": "RSA", "kid": kid, "alg": "RS256", "use": "sig",
+				"n": base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		}
+		json.NewEncoder(w).Encode(set)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &hits
+}
+
+func signToken(t *testing.T, key *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+	tok := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	tok.Header["kid"] = kid
+	s, err := tok.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func Test_Verify(t *testing.T) {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	srv, hits := testKeyServer(t, map[string]*rsa.PublicKey{"k1": &key.PublicKey})
+	v := NewVerifier(srv.URL, []string{"com.example.timetrac"})
+	now := time.Now()
+
+	base := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":              Issuer,
+			"aud":              "com.example.timetrac",
+			"sub":              "001234.abcdef",
+			"exp":              now.Add(5 * time.Minute).Unix(),
+			"iat":              now.Unix(),
+			"email":            "x7@privaterelay.appleid.com",
+			"email_verified":   "true",
+			"is_private_email": "true",
+		}
+	}
+
+	claims, err := v.Verify(context.Background(), signToken(t, key, "k1", base()), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Subject != "001234.abcdef" || !bool(claims.EmailVerified) || !claims.PrivateRelay() {
+		t.Errorf("unexpected claims %+v", claims)
+	}
+
+	wrongAud := base()
+	wrongAud["aud"] = "com.other.app"
+	if _, err := v.Verify(context.Background(), signToken(t, key, "k1", wrongAud), now); err == nil {
+		t.Error("foreign audience accepted")
+	}
+
+	expired := base()
+	expired["exp"] = now.Add(-time.Minute).Unix()
+	if _, err := v.Verify(context.Background(), signToken(t, key, "k1", expired), now); err == nil {
+		t.Error("expired token accepted")
+	}
+
+	wrongIss := base()
+	wrongIss["iss"] = "https://evil.example.com"
+	if _, err := v.Verify(context.Background(), signToken(t, key, "k1", wrongIss), now); err == nil {
+		t.Error("foreign issuer accepted")
+	}
+
+	if *hits != 1 {
+		t.Errorf("keys fetched %d times, want 1 (cached)", *hits)
+	}
+}
+
+func Test_Verify_UnknownKeyRefetch(t *testing.T) {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	rogue, _ := rsa.GenerateKey(rand.Reader, 2048)
+	published := map[string]*rsa.PublicKey{"k1": &key.PublicKey}
+	srv, hits := testKeyServer(t, published)
+	v := NewVerifier(srv.URL, []string{"aud"})
+	now := time.Now()
+	claims := jwt.MapClaims{"iss": Issuer, "aud": "aud", "sub": "s", "exp": now.Add(time.Minute).Unix()}
+
+	if _, err := v.Verify(context.Background(), signToken(t, key, "k1", claims), now); err != nil {
+		t.Fatal(err)
+	}
+
+	// Unknown kid: one refetch, then rejected without hammering Apple
+	_, err := v.Verify(context.Background(), signToken(t, rogue, "k2", claims), now.Add(2*time.Minute))
+	if !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("got %v, want ErrUnknownKey", err)
+	}
+	v.Verify(context.Background(), signToken(t, rogue, "k2", claims), now.Add(2*time.Minute+time.Second))
+	if *hits != 2 {
+		t.Errorf("keys fetched %d times, want 2", *hits)
+	}
+
+	// Rotated key appears after a refetch
+	published["k2"] = &rogue.PublicKey
+	claims["exp"] = now.Add(10 * time.Minute).Unix()
+	if _, err := v.Verify(context.Background(), signToken(t, rogue, "k2", claims), now.Add(4*time.Minute)); err != nil {
+		t.Errorf("rotated key rejected: %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+/**
+ * Geocode - Reverse Geocoding for Entry Locations
+ *
+ * This package resolves GPS coordinates to a human-readable address using
+ * a Nominatim-compatible HTTP provider:
+ * - Per-provider rate limiting (Nominatim allows 1 request per second)
+ * - In-process cache keyed by rounded coordinates
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-20
+ */
+package geocode
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// cachePrecision is the number of decimals coordinates are rounded to for
+// caching; 4 decimals is roughly 11 m.
+const cachePrecision = 4
+
+// cacheSize bounds the number of cached addresses.
+const cacheSize = 1024
+
+/**
+ * Client resolves coordinates against one provider
+ */
+type Client struct {
+	ProviderURL string        // Nominatim-compatible reverse endpoint
+	UserAgent   string        // Sent with every request, required by Nominatim
+	MinInterval time.Duration // Minimum time between provider requests
+	HTTP        *http.Client
+
+	mu      sync.Mutex
+	last    time.Time
+	cacheMu sync.Mutex
+	cache   map[string]string
+}
+
+/**
+ * NewClient returns a Client for the given provider
+ *
+ * @param providerURL - Reverse endpoint; there is no default, coordinates
+ *   are only sent to a provider the operator chose
+ * @param minInterval - Minimum time between provider requests
+ * @return *Client - Configured client
+ */
+func NewClient(providerURL string, minInterval time.Duration) *Client {
+	return &Client{
+		ProviderURL: providerURL,
+		UserAgent:   "buffalo-angular-timetrac/1.0",
+		MinInterval: minInterval,
+		HTTP:        &http.Client{Timeout: 10 * time.Second},
+		cache:       map[string]string{},
+	}
+}
+
+/**
+ * CacheKey rounds coordinates to the cache precision
+ */
+func CacheKey(lat, lng float64) string {
+	pow := math.Pow(10, cachePrecision)
+	return strconv.FormatFloat(math.Round(lat*pow)/pow, 'f', cachePrecision, 64) + "," +
+		strconv.FormatFloat(math.Round(lng*pow)/pow, 'f', cachePrecision, 64)
+}
+
+/**
+ * Reverse returns the address for the given coordinates
+ *
+ * Cached results are returned without contacting the provider; otherwise
+ * the call waits for the rate limit before sending the request.
+ *
+ * @param ctx - Request context
+ * @param lat - Latitude
+ * @param lng - Longitude
+ * @return string - Address (display name)
+ */
+func (c *Client) Reverse(ctx context.Context, lat, lng float64) (string, error) {
+	key := CacheKey(lat, lng)
+	c.cacheMu.Lock()
+	addr, ok := c.cache[key]
+	c.cacheMu.Unlock()
+	if ok {
+		return addr, nil
+	}
+
+	if err := c.wait(ctx); err != nil {
+		return "", err
+	}
+
+	q := url.Values{}
+	q.Set("format", "jsonv2")
+	q.Set("lat", strconv.FormatFloat(lat, 'f', -1, 64))
+	q.Set("lon", strconv.FormatFloat(lng, 'f', -1, 64))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.ProviderURL+"?"+q.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", c.UserAgent)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("geocode: provider returned %s", resp.Status)
+	}
+
+	var body struct {
+		DisplayName string `json:"display_name"`
+		Error       string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", err
+	}
+	if body.Error != "" {
+		return "", errors.New("geocode: " + body.Error)
+	}
+	if body.DisplayName == "" {
+		return "", errors.New("geocode: no address found")
+	}
+
+	c.cacheMu.Lock()
+	if len(c.cache) >= cacheSize {
+		c.cache = map[string]string{}
+	}
+	c.cache[key] = body.DisplayName
+	c.cacheMu.Unlock()
+	return body.DisplayName, nil
+}
+
+/**
+ * wait blocks until the next provider request is allowed
+ */
+func (c *Client) wait(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if d := time.Until(c.last.Add(c.MinInterval)); d > 0 {
+		t := time.NewTimer(d)
+		defer t.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+	c.last = time.Now()
+	return nil
+}
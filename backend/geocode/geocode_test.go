@@ -0,0 +1,56 @@
+package geocode
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func Test_CacheKey(t *testing.T) {
+	if got := CacheKey(48.208176, 16.373819); got != "48.2082,16.3738" {
+		t.Fatalf("got %q", got)
+	}
+	if CacheKey(48.20817, 16.37381) != CacheKey(48.20824, 16.37376) {
+		t.Fatal("nearby coordinates should share a cache key")
+	}
+}
+
+func Test_Client_Reverse_Caches(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if r.URL.Query().Get("lat") == "" || r.Header.Get("User-Agent") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"display_name":"Stephansplatz 1, 1010 Wien, Austria"}`))
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL, 0)
+	for i := 0; i < 2; i++ {
+		addr, err := c.Reverse(context.Background(), 48.2085, 16.3731)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if addr != "Stephansplatz 1, 1010 Wien, Austria" {
+			t.Fatalf("got %q", addr)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("expected 1 provider call, got %d", calls)
+	}
+}
+
+func Test_Client_Reverse_ProviderError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"error":"Unable to geocode"}`))
+	}))
+	defer srv.Close()
+
+	if _, err := NewClient(srv.URL, 0).Reverse(context.Background(), 0, 0); err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -0,0 +1,50 @@
+package grifts
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"backend/actions"
+	"backend/models"
+
+	"github.com/gobuffalo/grift/grift"
+)
+
+var _ = grift.Namespace("reports", func() {
+
+	grift.Desc("run", "Runs due scheduled reports (for cron when SCHEDULED_REPORTS_POLL_SECONDS=0)")
+	grift.Add("run", func(c *grift.Context) error {
+		actions.App() // Configures the mailer
+		n, err := actions.RunScheduledReports(models.DB, time.Now())
+		if err != nil {
+			return err
+		}
+		fmt.Printf("ran %d scheduled reports\n", n)
+		return nil
+	})
+
+	grift.Desc("cleanup", "Removes expired report files, report jobs and old report runs; --dry-run lists them instead")
+	grift.Add("cleanup", func(c *grift.Context) error {
+		now := time.Now()
+		if slices.Contains(c.Args, "--dry-run") {
+			plan, err := actions.PlanReportCleanup(models.DB, now)
+			if err != nil {
+				return err
+			}
+			for _, a := range plan.Artifacts {
+				fmt.Printf("%s user=%s file=%s size=%d expired=%s\n",
+					a.ID, a.UserID, a.FileKey, a.Size, a.ExpiresAt.Format(time.RFC3339))
+			}
+			fmt.Printf("would remove %d report files, %d report jobs and %d report runs\n", len(plan.Artifacts), plan.Jobs, plan.Runs)
+			return nil
+		}
+		artifacts, jobs, runs, err := actions.CleanupReports(models.DB, now)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("removed %d report files, %d report jobs and %d report runs\n", artifacts, jobs, runs)
+		return nil
+	})
+
+})
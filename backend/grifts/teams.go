@@ -0,0 +1,37 @@
+package grifts
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"backend/actions"
+	"backend/models"
+
+	"github.com/gobuffalo/grift/grift"
+	"github.com/gofrs/uuid"
+)
+
+var _ = grift.Namespace("teams", func() {
+
+	grift.Desc("seats", "Sets the member limit of a team (0 = unlimited)")
+	grift.Add("seats", func(c *grift.Context) error {
+		if len(c.Args) != 2 {
+			return errors.New("usage: teams:seats <team_id> <max_members>")
+		}
+		teamID, err := uuid.FromString(c.Args[0])
+		if err != nil {
+			return fmt.Errorf("invalid team ID %s", c.Args[0])
+		}
+		max, err := strconv.Atoi(c.Args[1])
+		if err != nil {
+			return fmt.Errorf("invalid max_members %s", c.Args[1])
+		}
+		if err := actions.SetTeamSeatLimit(models.DB, teamID, max); err != nil {
+			return err
+		}
+		fmt.Printf("team %s now has max_members %d\n", teamID, max)
+		return nil
+	})
+
+})
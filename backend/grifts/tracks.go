@@ -0,0 +1,63 @@
+package grifts
+
+import (
+	"fmt"
+	"time"
+
+	"backend/actions"
+	"backend/models"
+
+	"github.com/gobuffalo/grift/grift"
+)
+
+var _ = grift.Namespace("tracks", func() {
+
+	grift.Desc("autostop", "Stops entries running longer than the owner's max_running_hours")
+	grift.Add("autostop", func(c *grift.Context) error {
+		n, err := actions.AutoStopForgottenTracks(models.DB, time.Now())
+		if err != nil {
+			return err
+		}
+		fmt.Printf("auto-stopped %d entries\n", n)
+		return nil
+	})
+
+	grift.Desc("purge-trash", "Permanently deletes entries that have been in trash for 30 days")
+	grift.Add("purge-trash", func(c *grift.Context) error {
+		n, err := actions.PurgeTrashedTracks(models.DB, time.Now())
+		if err != nil {
+			return err
+		}
+		fmt.Printf("purged %d entries from trash\n", n)
+		return nil
+	})
+
+	grift.Desc("migrate-photos", "Moves base64 photo_data blobs into the configured photo storage")
+	grift.Add("migrate-photos", func(c *grift.Context) error {
+		n, skipped, err := actions.MigratePhotoData(models.DB)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("migrated %d photos (%d skipped)\n", n, skipped)
+		return nil
+	})
+
+	grift.Desc("invalid", "Lists entries violating the time range validation (read-only)")
+	grift.Add("invalid", func(c *grift.Context) error {
+		invalid, err := actions.FindInvalidTracks(models.DB, time.Now())
+		if err != nil {
+			return err
+		}
+		for _, it := range invalid {
+			end := "running"
+			if it.Entry.EndAt.Valid {
+				end = it.Entry.EndAt.Time.Format(time.RFC3339)
+			}
+			fmt.Printf("%s user=%s start=%s end=%s %v\n",
+				it.Entry.ID, it.Entry.UserID, it.Entry.StartAt.Format(time.RFC3339), end, it.Errors)
+		}
+		fmt.Printf("%d invalid entries\n", len(invalid))
+		return nil
+	})
+
+})
@@ -0,0 +1,39 @@
+package grifts
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"backend/actions"
+	"backend/models"
+
+	"github.com/gobuffalo/grift/grift"
+	"github.com/gobuffalo/pop/v6"
+)
+
+var _ = grift.Namespace("users", func() {
+
+	grift.Desc("grant-admin", "Makes the user with the given email an admin")
+	grift.Add("grant-admin", func(c *grift.Context) error {
+		if len(c.Args) != 1 {
+			return errors.New("usage: users:grant-admin <email>")
+		}
+		email := strings.ToLower(strings.TrimSpace(c.Args[0]))
+		return models.DB.Transaction(func(tx *pop.Connection) error {
+			var u models.User
+			if err := tx.Where("email = ?", email).First(&u); err != nil {
+				return fmt.Errorf("no user %s: %w", email, err)
+			}
+			if err := tx.RawQuery("UPDATE users SET is_admin = true WHERE id = ?", u.ID).Exec(); err != nil {
+				return err
+			}
+			if err := actions.RecordAdminAction(tx, u, models.AdminActionGrantAdmin); err != nil {
+				return err
+			}
+			fmt.Printf("%s is now an admin\n", email)
+			return nil
+		})
+	})
+
+})
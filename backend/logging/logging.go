@@ -0,0 +1,410 @@
+/**
+ * Logging - Structured Log Lines
+ *
+ * This package writes leveled log lines with fields, one per event:
+ * - JSON, an object per line holding time, level, msg and the fields,
+ *   for log aggregators
+ * - Pretty, "time LEVEL msg key=value ...", for reading in a terminal
+ *
+ * Fields whose names are sensitive (see RedactedFields) are written as
+ * Redacted, at any depth of maps and url.Values, so request parameters
+ * can be logged as they are.
+ *
+ * The app logs through Default; handlers log their own events with it
+ * too, adding fields with With.
+ *
+ * Configuration (see FromEnv):
+ * - LOG_LEVEL: debug, info (default), warn or error
+ * - LOG_FORMAT: json (default) or pretty
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-22
+ */
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+/**
+ * Level is the severity of a log line
+ */
+type Level int
+
+// Levels, from the most verbose.
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = []string{"debug", "info", "warn", "error"}
+
+func (l Level) String() string {
+	if l < LevelDebug || l > LevelError {
+		return "level(" + strconv.Itoa(int(l)) + ")"
+	}
+	return levelNames[l]
+}
+
+/**
+ * ParseLevel returns the level named s, ignoring case; "warning" is warn
+ */
+func ParseLevel(s string) (Level, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "warning" {
+		return LevelWarn, nil
+	}
+	for i, name := range levelNames {
+		if s == name {
+			return Level(i), nil
+		}
+	}
+	return LevelInfo, fmt.Errorf("unknown level %q; use debug, info, warn or error", s)
+}
+
+// Output formats.
+const (
+	FormatJSON   = "json"
+	FormatPretty = "pretty"
+)
+
+// Redacted replaces the values of sensitive fields.
+const Redacted = "[REDACTED]"
+
+// RedactedFields are the names of sensitive fields; a field is redacted
+// when its name contains one of them, ignoring case.
+var RedactedFields = []string{"password", "photo_data", "token", "signature", "secret", "code"}
+
+// Reserved keys of a line; fields of these names are left out.
+const (
+	keyTime  = "time"
+	keyLevel = "level"
+	keyMsg   = "msg"
+)
+
+/**
+ * Fields are the key/value pairs of a log line
+ */
+type Fields map[string]any
+
+/**
+ * Logger writes log lines of at least its level to one output
+ */
+type Logger struct {
+	out    io.Writer
+	mu     *sync.Mutex // Shared by the loggers made by With
+	level  Level
+	pretty bool
+	fields Fields
+	now    func() time.Time
+}
+
+/**
+ * New returns a Logger writing lines of at least level to w
+ *
+ * @param w - Output
+ * @param level - Least severe level written
+ * @param format - FormatJSON or FormatPretty
+ * @return *Logger - Configured logger
+ */
+func New(w io.Writer, level Level, format string) *Logger {
+	return &Logger{out: w, mu: &sync.Mutex{}, level: level, pretty: format == FormatPretty, now: time.Now}
+}
+
+/**
+ * FromEnv returns a Logger writing to stdout configured from the
+ * environment; invalid settings are reported and replaced by their
+ * defaults
+ *
+ * @param getenv - Environment lookup (os.Getenv outside tests)
+ * @return *Logger - Configured logger
+ */
+func FromEnv(getenv func(string) string) (*Logger, error) {
+	var errs []error
+	level := LevelInfo
+	if s := getenv("LOG_LEVEL"); s != "" {
+		var err error
+		if level, err = ParseLevel(s); err != nil {
+			errs = append(errs, fmt.Errorf("LOG_LEVEL: %w", err))
+		}
+	}
+	format := strings.ToLower(getenv("LOG_FORMAT"))
+	switch format {
+	case "":
+		format = FormatJSON
+	case FormatJSON, FormatPretty:
+	default:
+		errs = append(errs, fmt.Errorf("LOG_FORMAT: unknown format %q; use json or pretty", format))
+		format = FormatJSON
+	}
+	return New(os.Stdout, level, format), errors.Join(errs...)
+}
+
+var std atomic.Pointer[Logger]
+
+func init() {
+	std.Store(New(os.Stdout, LevelInfo, FormatJSON))
+}
+
+/**
+ * Default returns the app's logger, JSON at info level on stdout until
+ * SetDefault replaces it
+ */
+func Default() *Logger { return std.Load() }
+
+/**
+ * SetDefault replaces the logger returned by Default
+ */
+func SetDefault(l *Logger) { std.Store(l) }
+
+/**
+ * Level returns the least severe level the logger writes
+ */
+func (l *Logger) Level() Level { return l.level }
+
+/**
+ * Enabled reports whether lines of level are written
+ */
+func (l *Logger) Enabled(level Level) bool { return level >= l.level }
+
+/**
+ * With returns a logger adding fields to every line, after the fields of
+ * l; both write to the same output
+ */
+func (l *Logger) With(fields Fields) *Logger {
+	c := *l
+	c.fields = make(Fields, len(l.fields)+len(fields))
+	for k, v := range l.fields {
+		c.fields[k] = v
+	}
+	for k, v := range fields {
+		c.fields[k] = v
+	}
+	return &c
+}
+
+// Debug writes a debug line.
+func (l *Logger) Debug(msg string, fields ...Fields) { l.Log(LevelDebug, msg, fields...) }
+
+// Info writes an info line.
+func (l *Logger) Info(msg string, fields ...Fields) { l.Log(LevelInfo, msg, fields...) }
+
+// Warn writes a warn line.
+func (l *Logger) Warn(msg string, fields ...Fields) { l.Log(LevelWarn, msg, fields...) }
+
+// Error writes an error line.
+func (l *Logger) Error(msg string, fields ...Fields) { l.Log(LevelError, msg, fields...) }
+
+/**
+ * Log writes a line of level with the logger's fields and fields, when
+ * the level is enabled; errors writing it are ignored
+ */
+func (l *Logger) Log(level Level, msg string, fields ...Fields) {
+	if !l.Enabled(level) {
+		return
+	}
+	all := l.fields
+	if len(fields) > 0 {
+		all = l.With(mergeFields(fields)).fields
+	}
+	var line []byte
+	if l.pretty {
+		line = l.prettyLine(level, msg, all)
+	} else {
+		line = l.jsonLine(level, msg, all)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.out.Write(line)
+}
+
+/**
+ * mergeFields merges fields, later ones winning
+ */
+func mergeFields(fields []Fields) Fields {
+	if len(fields) == 1 {
+		return fields[0]
+	}
+	merged := Fields{}
+	for _, f := range fields {
+		for k, v := range f {
+			merged[k] = v
+		}
+	}
+	return merged
+}
+
+/**
+ * sortedKeys returns the keys of fields to write, sorted
+ */
+func sortedKeys(fields Fields) []string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		if k != keyTime && k != keyLevel && k != keyMsg {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+/**
+ * jsonLine renders a line as a JSON object: time, level and msg, then
+ * the fields sorted by name
+ */
+func (l *Logger) jsonLine(level Level, msg string, fields Fields) []byte {
+	var b bytes.Buffer
+	b.WriteString(`{"time":`)
+	writeJSON(&b, l.now().UTC().Format(time.RFC3339Nano))
+	b.WriteString(`,"level":`)
+	writeJSON(&b, level.String())
+	b.WriteString(`,"msg":`)
+	writeJSON(&b, msg)
+	for _, k := range sortedKeys(fields) {
+		b.WriteByte(',')
+		writeJSON(&b, k)
+		b.WriteByte(':')
+		writeJSON(&b, redactField(k, fields[k]))
+	}
+	b.WriteString("}\n")
+	return b.Bytes()
+}
+
+/**
+ * writeJSON writes v as JSON, or its fmt rendering as a JSON string when
+ * it has no JSON encoding
+ */
+func writeJSON(b *bytes.Buffer, v any) {
+	if err, ok := v.(error); ok {
+		v = err.Error()
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		data, _ = json.Marshal(fmt.Sprint(v))
+	}
+	b.Write(data)
+}
+
+/**
+ * prettyLine renders a line as "time LEVEL msg key=value ..."
+ */
+func (l *Logger) prettyLine(level Level, msg string, fields Fields) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%s %-5s %s", l.now().Format("2006-01-02 15:04:05.000"), strings.ToUpper(level.String()), msg)
+	for _, k := range sortedKeys(fields) {
+		v := redactField(k, fields[k])
+		var s string
+		switch v := v.(type) {
+		case string:
+			s = v
+		case error:
+			s = v.Error()
+		case fmt.Stringer:
+			s = v.String()
+		case Fields, map[string]any, map[string]string, map[string][]string, url.Values, []any, []string:
+			data, _ := json.Marshal(v)
+			s = string(data)
+		default:
+			s = fmt.Sprint(v)
+		}
+		if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+			s = strconv.Quote(s)
+		}
+		fmt.Fprintf(&b, " %s=%s", k, s)
+	}
+	b.WriteByte('\n')
+	return b.Bytes()
+}
+
+/**
+ * Sensitive reports whether a field name is one of RedactedFields
+ */
+func Sensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range RedactedFields {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * redactField returns the value of the field key as it is logged
+ */
+func redactField(key string, v any) any {
+	if Sensitive(key) {
+		return Redacted
+	}
+	return Redact(v)
+}
+
+/**
+ * Redact returns a copy of v, when it is a map (Fields, map[string]any,
+ * map[string]string, map[string][]string or url.Values) or a slice of
+ * values, with the values of sensitive keys replaced by Redacted; other
+ * values are returned as they are
+ */
+func Redact(v any) any {
+	switch v := v.(type) {
+	case Fields:
+		return redactMap(v)
+	case map[string]any:
+		return redactMap(v)
+	case map[string]string:
+		out := make(map[string]string, len(v))
+		for k, s := range v {
+			if Sensitive(k) {
+				s = Redacted
+			}
+			out[k] = s
+		}
+		return out
+	case url.Values:
+		return redactValues(v)
+	case map[string][]string:
+		return redactValues(v)
+	case []any:
+		out := make([]any, len(v))
+		for i, e := range v {
+			out[i] = Redact(e)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+func redactMap(m map[string]any) map[string]any {
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		out[k] = redactField(k, v)
+	}
+	return out
+}
+
+func redactValues(m map[string][]string) map[string][]string {
+	out := make(map[string][]string, len(m))
+	for k, vs := range m {
+		if Sensitive(k) {
+			vs = []string{Redacted}
+		}
+		out[k] = vs
+	}
+	return out
+}
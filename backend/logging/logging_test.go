@@ -0,0 +1,108 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fixed returns a logger writing to buf at a fixed time.
+func fixed(buf *bytes.Buffer, level Level, format string) *Logger {
+	l := New(buf, level, format)
+	l.now = func() time.Time { return time.Date(2025, 3, 3, 9, 30, 0, 0, time.UTC) }
+	return l
+}
+
+func Test_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	l := fixed(&buf, LevelInfo, FormatJSON).With(Fields{"request_id": "r1"})
+	l.Info("request", Fields{"status": 200, "err": errors.New("boom"), "msg": "ignored"})
+
+	want := `{"time":"2025-03-03T09:30:00Z","level":"info","msg":"request","err":"boom","request_id":"r1","status":200}` + "\n"
+	if buf.String() != want {
+		t.Errorf("got  %s\nwant %s", buf.String(), want)
+	}
+}
+
+func Test_Pretty(t *testing.T) {
+	var buf bytes.Buffer
+	fixed(&buf, LevelDebug, FormatPretty).Warn("slow query", Fields{"table": "tracks", "sql": "SELECT 1", "empty": ""})
+
+	want := `2025-03-03 09:30:00.000 WARN  slow query empty="" sql="SELECT 1" table=tracks` + "\n"
+	if buf.String() != want {
+		t.Errorf("got  %q\nwant %q", buf.String(), want)
+	}
+}
+
+func Test_Level(t *testing.T) {
+	var buf bytes.Buffer
+	l := fixed(&buf, LevelWarn, FormatJSON)
+	l.Debug("a")
+	l.Info("b")
+	l.Warn("c")
+	l.Error("d")
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"msg":"c"`) || !strings.Contains(lines[1], `"level":"error"`) {
+		t.Errorf("got %q", lines)
+	}
+
+	for in, want := range map[string]Level{"debug": LevelDebug, "INFO": LevelInfo, "warning": LevelWarn, " error ": LevelError} {
+		if got, err := ParseLevel(in); err != nil || got != want {
+			t.Errorf("ParseLevel(%q) = %v, %v", in, got, err)
+		}
+	}
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Error("unknown level accepted")
+	}
+}
+
+func Test_Redact(t *testing.T) {
+	var buf bytes.Buffer
+	fixed(&buf, LevelInfo, FormatJSON).Info("request", Fields{
+		"password":     "hunter2",
+		"Access_Token": "at",
+		"params": url.Values{
+			"email":         {"a@example.com"},
+			"new_password":  {"hunter3"},
+			"refresh_token": {"rt"},
+		},
+		"body": map[string]any{
+			"photo_data": "aGVsbG8=",
+			"nested":     map[string]string{"token": "t", "note": "kept"},
+		},
+	})
+
+	var line map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"hunter2", "hunter3", `"at"`, `"rt"`, "aGVsbG8=", `"t"`} {
+		if strings.Contains(buf.String(), secret) {
+			t.Errorf("%s logged: %s", secret, buf.String())
+		}
+	}
+	if line["password"] != Redacted || !strings.Contains(buf.String(), `"email":["a@example.com"]`) || !strings.Contains(buf.String(), `"note":"kept"`) {
+		t.Errorf("got %s", buf.String())
+	}
+}
+
+func Test_FromEnv(t *testing.T) {
+	env := map[string]string{"LOG_LEVEL": "debug", "LOG_FORMAT": "pretty"}
+	l, err := FromEnv(func(k string) string { return env[k] })
+	if err != nil || l.Level() != LevelDebug || !l.pretty {
+		t.Errorf("got %v %v %v", l.Level(), l.pretty, err)
+	}
+
+	env = map[string]string{"LOG_LEVEL": "loud", "LOG_FORMAT": "xml"}
+	l, err = FromEnv(func(k string) string { return env[k] })
+	if err == nil || !strings.Contains(err.Error(), "LOG_LEVEL") || !strings.Contains(err.Error(), "LOG_FORMAT") {
+		t.Errorf("error %v", err)
+	}
+	if l.Level() != LevelInfo || l.pretty {
+		t.Errorf("defaults not used: %v %v", l.Level(), l.pretty)
+	}
+}
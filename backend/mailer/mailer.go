@@ -0,0 +1,217 @@
+/**
+ * Mailer - Outgoing Email
+ *
+ * This package sends plain-text transactional email (sign-in links,
+ * notifications, reports with their files attached):
+ * - SMTP delivery with STARTTLS and PLAIN authentication
+ * - A logging sender for development, used when no SMTP host is set
+ *
+ * Configuration (see FromEnv):
+ * - SMTP_HOST, SMTP_PORT (default 587)
+ * - SMTP_USERNAME, SMTP_PASSWORD: optional credentials
+ * - MAIL_FROM: sender address (default no-reply@localhost)
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-21
+ */
+package mailer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// DefaultFrom is the sender address used when MAIL_FROM is not set.
+const DefaultFrom = "no-reply@localhost"
+
+// ErrHeaderInjection is returned for header values containing line breaks.
+var ErrHeaderInjection = errors.New("mailer: line break in header")
+
+/**
+ * Message is a plain-text email
+ */
+type Message struct {
+	To          string
+	Subject     string
+	Text        string
+	Attachments []Attachment
+}
+
+/**
+ * Attachment is a file sent with a message
+ */
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+/**
+ * Sender delivers messages
+ */
+type Sender interface {
+	Send(msg Message) error
+}
+
+/**
+ * SMTP sends messages through an SMTP server
+ */
+type SMTP struct {
+	Host     string
+	Port     string
+	Username string // No authentication when empty
+	Password string
+	From     string
+}
+
+/**
+ * Send delivers msg to its recipient
+ */
+func (s *SMTP) Send(msg Message) error {
+	data, err := Compose(s.From, msg, time.Now())
+	if err != nil {
+		return err
+	}
+	var auth smtp.Auth
+	if s.Username != "" {
+		auth = smtp.PlainAuth("", s.Username, s.Password, s.Host)
+	}
+	from, _ := mail.ParseAddress(s.From)
+	to, _ := mail.ParseAddress(msg.To)
+	return smtp.SendMail(net.JoinHostPort(s.Host, s.Port), auth, from.Address, []string{to.Address}, data)
+}
+
+/**
+ * Log writes messages to a log function instead of sending them
+ */
+type Log struct {
+	Logf func(format string, args ...any)
+}
+
+/**
+ * Send logs msg
+ */
+func (l Log) Send(msg Message) error {
+	l.Logf("mail to %s: %s\n%s", msg.To, msg.Subject, msg.Text)
+	for _, a := range msg.Attachments {
+		l.Logf("mail to %s: attachment %s (%s, %d bytes)", msg.To, a.Filename, a.ContentType, len(a.Data))
+	}
+	return nil
+}
+
+/**
+ * FromEnv returns an SMTP sender configured from the environment, or nil
+ * when SMTP_HOST is not set
+ *
+ * @param getenv - Environment lookup (os.Getenv outside tests)
+ * @return *SMTP - Configured sender or nil
+ */
+func FromEnv(getenv func(string) string) (*SMTP, error) {
+	host := getenv("SMTP_HOST")
+	if host == "" {
+		return nil, nil
+	}
+	s := &SMTP{
+		Host:     host,
+		Port:     getenv("SMTP_PORT"),
+		Username: getenv("SMTP_USERNAME"),
+		Password: getenv("SMTP_PASSWORD"),
+		From:     getenv("MAIL_FROM"),
+	}
+	if s.Port == "" {
+		s.Port = "587"
+	}
+	if s.From == "" {
+		s.From = DefaultFrom
+	}
+	if _, err := mail.ParseAddress(s.From); err != nil {
+		return nil, fmt.Errorf("MAIL_FROM: %w", err)
+	}
+	return s, nil
+}
+
+/**
+ * Compose renders msg as an RFC 5322 message; a message with attachments
+ * is multipart/mixed with the text first and the files base64 encoded
+ *
+ * @param from - Sender address
+ * @param msg - Message to render
+ * @param now - Date header value
+ * @return []byte - Headers and body with CRLF line endings
+ */
+func Compose(from string, msg Message, now time.Time) ([]byte, error) {
+	for _, v := range []string{from, msg.To, msg.Subject} {
+		if strings.ContainsAny(v, "\r\n") {
+			return nil, ErrHeaderInjection
+		}
+	}
+	fromAddr, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("mailer: from: %w", err)
+	}
+	toAddr, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return nil, fmt.Errorf("mailer: to: %w", err)
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", fromAddr)
+	fmt.Fprintf(&b, "To: %s\r\n", toAddr)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	text := strings.ReplaceAll(strings.ReplaceAll(msg.Text, "\r\n", "\n"), "\n", "\r\n")
+	if len(msg.Attachments) == 0 {
+		b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+		b.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+		b.WriteString(text)
+		return b.Bytes(), nil
+	}
+
+	mw := multipart.NewWriter(&b)
+	fmt.Fprintf(&b, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", mw.Boundary())
+	part, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/plain; charset=utf-8"},
+		"Content-Transfer-Encoding": {"8bit"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	part.Write([]byte(text))
+	for _, a := range msg.Attachments {
+		disposition := mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename})
+		contentType := mime.FormatMediaType(a.ContentType, nil)
+		if disposition == "" || contentType == "" {
+			return nil, fmt.Errorf("mailer: invalid attachment %q", a.Filename)
+		}
+		part, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {contentType},
+			"Content-Disposition":       {disposition},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		enc := base64.StdEncoding.EncodeToString(a.Data)
+		for len(enc) > 76 {
+			part.Write([]byte(enc[:76] + "\r\n"))
+			enc = enc[76:]
+		}
+		part.Write([]byte(enc + "\r\n"))
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
@@ -0,0 +1,111 @@
+package mailer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_Compose(t *testing.T) {
+	now := time.Date(2025, 9, 21, 8, 0, 0, 0, time.UTC)
+	data, err := Compose("TimeTrac <no-reply@example.com>", Message{
+		To:      "user@example.com",
+		Subject: "Your sign-in link ✓",
+		Text:    "Hello\nclick here",
+	}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(data)
+	for _, want := range []string{
+		"From: \"TimeTrac\" <no-reply@example.com>\r\n",
+		"To: <user@example.com>\r\n",
+		"Subject: =?utf-8?q?",
+		"Date: Sun, 21 Sep 2025 08:00:00 +0000\r\n",
+		"\r\n\r\nHello\r\nclick here",
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("message lacks %q:\n%s", want, s)
+		}
+	}
+}
+
+func Test_Compose_Attachments(t *testing.T) {
+	pdf := bytes.Repeat([]byte("%PDF-1.3 "), 20)
+	data, err := Compose("no-reply@example.com", Message{
+		To:          "user@example.com",
+		Subject:     "Weekly report",
+		Text:        "See attached",
+		Attachments: []Attachment{{Filename: "wöchentlich.pdf", ContentType: "application/pdf", Data: pdf}},
+	}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/mixed" {
+		t.Fatalf("got %q, %v", mediaType, err)
+	}
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	text, err := mr.NextPart()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := io.ReadAll(text); string(b) != "See attached" {
+		t.Errorf("got text %q", b)
+	}
+	file, err := mr.NextPart()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.FileName() != "wöchentlich.pdf" {
+		t.Errorf("got filename %q", file.FileName())
+	}
+	b, _ := io.ReadAll(base64.NewDecoder(base64.StdEncoding, file))
+	if !bytes.Equal(b, pdf) {
+		t.Errorf("attachment differs")
+	}
+}
+
+func Test_Compose_RejectsHeaderInjection(t *testing.T) {
+	_, err := Compose("no-reply@example.com", Message{
+		To:      "user@example.com\r\nBcc: victim@example.com",
+		Subject: "hi",
+	}, time.Now())
+	if !errors.Is(err, ErrHeaderInjection) {
+		t.Errorf("got %v, want ErrHeaderInjection", err)
+	}
+}
+
+func Test_FromEnv(t *testing.T) {
+	env := map[string]string{}
+	getenv := func(k string) string { return env[k] }
+
+	if s, err := FromEnv(getenv); s != nil || err != nil {
+		t.Errorf("got %v, %v without SMTP_HOST", s, err)
+	}
+
+	env["SMTP_HOST"] = "smtp.example.com"
+	s, err := FromEnv(getenv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Port != "587" || s.From != DefaultFrom {
+		t.Errorf("unexpected defaults %+v", s)
+	}
+
+	env["MAIL_FROM"] = "not an address"
+	if _, err := FromEnv(getenv); err == nil {
+		t.Error("invalid MAIL_FROM accepted")
+	}
+}
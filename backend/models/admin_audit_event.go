@@ -0,0 +1,59 @@
+/**
+ * AdminAuditEvent Model - Record of Administrative Actions
+ *
+ * This package defines the AdminAuditEvent model. Every action taken
+ * through the admin endpoints, or the users:grant-admin task, is recorded
+ * with who took it, on which account and when. Events outlive the
+ * accounts involved: the target is kept by ID and email, and a deleted
+ * actor leaves the actor empty.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-21
+ */
+package models
+
+import (
+	"time"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/gofrs/uuid"
+)
+
+// Administrative actions.
+const (
+	AdminActionDisable        = "disable"
+	AdminActionEnable         = "enable"
+	AdminActionDeactivate     = "deactivate"
+	AdminActionReactivate     = "reactivate"
+	AdminActionRevokeSessions = "revoke_sessions"
+	AdminActionDelete         = "delete"
+	AdminActionGrantAdmin     = "grant_admin"
+)
+
+/**
+ * AdminAuditEvent is one administrative action
+ *
+ * Database Fields:
+ * - id: Primary key (UUID)
+ * - actor_id: Admin who acted (NULL for command line tasks or deleted admins)
+ * - target_id: Account acted on, kept after its deletion
+ * - target_email: Address of the account at the time
+ * - action: disable, enable, deactivate, reactivate, revoke_sessions,
+ *   delete or grant_admin
+ * - created_at, updated_at: Timestamps
+ */
+type AdminAuditEvent struct {
+	ID          uuid.UUID    `db:"id" json:"id"`                     // Unique event identifier
+	ActorID     nulls.UUID   `db:"actor_id" json:"actor_id"`         // Acting admin
+	TargetID    nulls.UUID   `db:"target_id" json:"target_id"`       // Account acted on
+	TargetEmail nulls.String `db:"target_email" json:"target_email"` // Its address at the time
+	Action      string       `db:"action" json:"action"`             // What was done
+	CreatedAt   time.Time    `db:"created_at" json:"created_at"`     // When
+	UpdatedAt   time.Time    `db:"updated_at" json:"-"`              // Last modification timestamp
+}
+
+/**
+ * TableName returns the database table name for the AdminAuditEvent model
+ */
+func (e AdminAuditEvent) TableName() string { return "admin_audit_events" }
@@ -0,0 +1,96 @@
+/**
+ * APIKey Model - Personal Access Tokens for Integrations
+ *
+ * This package defines the APIKey model. A key has the form
+ * ttk_<prefix>_<secret>: the prefix is stored in clear text to find the
+ * key, the secret only as a SHA-256 hash. Keys do not expire unless an
+ * expiry is set, and their scopes limit what they may do.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-21
+ */
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"time"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/gofrs/uuid"
+	"github.com/lib/pq"
+)
+
+// APIKeyPrefix starts every API key, telling it apart from a JWT.
+const APIKeyPrefix = "ttk_"
+
+// API key scopes. Write implies read; introspect only allows
+// POST /api/auth/introspect, for other services.
+const (
+	APIKeyScopeRead       = "read"
+	APIKeyScopeWrite      = "write"
+	APIKeyScopeIntrospect = "introspect"
+)
+
+/**
+ * APIKeyScopes lists the scopes a key can be granted
+ */
+var APIKeyScopes = []string{APIKeyScopeRead, APIKeyScopeWrite, APIKeyScopeIntrospect}
+
+/**
+ * MaxAPIKeys is the number of API keys a user may keep
+ */
+const MaxAPIKeys = 20
+
+/**
+ * APIKey represents one personal access token
+ *
+ * Database Fields:
+ * - id: Primary key (UUID)
+ * - user_id: Owner the key acts as
+ * - name: Label chosen by the user, e.g. "cron export"
+ * - prefix: Public part of the key used for lookup (unique)
+ * - secret_hash: Hex SHA-256 of the secret part (see HashAPIKeySecret)
+ * - scopes: Granted scopes (see APIKeyScopes)
+ * - last_used_at: Last authenticated request, updated every few minutes
+ * - expires_at: Time after which the key is rejected (NULL = never)
+ * - created_at, updated_at: Timestamps
+ */
+type APIKey struct {
+	ID         uuid.UUID      `db:"id" json:"id"`                     // Unique key identifier
+	UserID     uuid.UUID      `db:"user_id" json:"-"`                 // Owner user ID (hidden from JSON)
+	Name       string         `db:"name" json:"name"`                 // User-chosen label
+	Prefix     string         `db:"prefix" json:"prefix"`             // Lookup prefix
+	SecretHash string         `db:"secret_hash" json:"-"`             // SHA-256 of the secret
+	Scopes     pq.StringArray `db:"scopes" json:"scopes"`             // Granted scopes
+	LastUsedAt nulls.Time     `db:"last_used_at" json:"last_used_at"` // Last authenticated request
+	ExpiresAt  nulls.Time     `db:"expires_at" json:"expires_at"`     // Expiration timestamp
+	CreatedAt  time.Time      `db:"created_at" json:"created_at"`     // Creation timestamp
+	UpdatedAt  time.Time      `db:"updated_at" json:"updated_at"`     // Last modification timestamp
+}
+
+/**
+ * TableName returns the database table name for the APIKey model
+ */
+func (k APIKey) TableName() string { return "api_keys" }
+
+/**
+ * Allows reports whether the key grants scope
+ */
+func (k APIKey) Allows(scope string) bool {
+	return slices.Contains(k.Scopes, scope) ||
+		(scope == APIKeyScopeRead && slices.Contains(k.Scopes, APIKeyScopeWrite))
+}
+
+/**
+ * HashAPIKeySecret returns the stored form of a key's secret part
+ *
+ * @param secret - Secret part of the key
+ * @return string - Hex-encoded SHA-256 digest
+ */
+func HashAPIKeySecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
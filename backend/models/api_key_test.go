@@ -0,0 +1,18 @@
+package models
+
+import "testing"
+
+func Test_APIKey_Allows(t *testing.T) {
+	read := APIKey{Scopes: []string{APIKeyScopeRead}}
+	write := APIKey{Scopes: []string{APIKeyScopeWrite}}
+
+	if !read.Allows(APIKeyScopeRead) || read.Allows(APIKeyScopeWrite) {
+		t.Error("read-only key scopes wrong")
+	}
+	if !write.Allows(APIKeyScopeRead) || !write.Allows(APIKeyScopeWrite) {
+		t.Error("write key must also read")
+	}
+	if (APIKey{}).Allows(APIKeyScopeRead) {
+		t.Error("key without scopes allowed")
+	}
+}
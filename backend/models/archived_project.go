@@ -0,0 +1,41 @@
+/**
+ * ArchivedProject Model - Projects Hidden from Pickers
+ *
+ * Projects are plain strings on time entries. This model records which
+ * project names a user has archived so they no longer clutter project
+ * pickers; entries keep their project and stay visible in reports.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-20
+ */
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+/**
+ * ArchivedProject represents one archived project name of a user
+ *
+ * Database Fields:
+ * - id: Primary key (UUID)
+ * - user_id: Owner
+ * - name: Project name as stored on entries
+ * - created_at: When the project was archived
+ * - updated_at: Last modification timestamp
+ */
+type ArchivedProject struct {
+	ID        uuid.UUID `db:"id" json:"-"`                  // Unique row identifier
+	UserID    uuid.UUID `db:"user_id" json:"-"`             // Owner user ID
+	Name      string    `db:"name" json:"name"`             // Archived project name
+	CreatedAt time.Time `db:"created_at" json:"created_at"` // Archive timestamp
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"` // Last modification timestamp
+}
+
+/**
+ * TableName returns the database table name for the ArchivedProject model
+ */
+func (p ArchivedProject) TableName() string { return "archived_projects" }
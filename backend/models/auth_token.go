@@ -11,7 +11,11 @@
  */
 package models
 
-import "time"
+import (
+	"time"
+
+	"github.com/gobuffalo/nulls"
+)
 
 /**
  * AuthToken represents a JWT token in the authentication system
@@ -25,9 +29,12 @@ import "time"
  * Database Fields:
  * - jti: JWT ID (unique identifier for the token)
  * - user_id: Foreign key to users table (string format)
+ * - family_id: Refresh token family the token was issued with
  * - revoked_at: Timestamp when token was revoked (NULL = active)
  * - expires_at: Token expiration timestamp
- * - created_at: Token creation timestamp
+ * - user_agent, ip: Client that signed in or refreshed
+ * - last_used_at: Last authenticated request, updated every few minutes
+ * - created_at, updated_at: Timestamps
  *
  * Security Features:
  * - Token revocation support for secure logout
@@ -45,9 +52,20 @@ import "time"
  * and is not typically exposed in API responses.
  */
 type AuthToken struct {
-	JTI       string    `db:"jti"`        // JWT ID (unique token identifier)
-	UserID    string    `db:"user_id"`    // Associated user ID
-	RevokedAt time.Time `db:"revoked_at"` // Token revocation timestamp (NULL = active)
-	ExpiresAt time.Time `db:"expires_at"` // Token expiration timestamp
-	CreatedAt time.Time `db:"created_at"` // Token creation timestamp
+	JTI        string       `db:"jti"`          // JWT ID (unique token identifier)
+	UserID     string       `db:"user_id"`      // Associated user ID
+	FamilyID   nulls.UUID   `db:"family_id"`    // Refresh token family
+	RevokedAt  nulls.Time   `db:"revoked_at"`   // Token revocation timestamp (NULL = active)
+	ExpiresAt  time.Time    `db:"expires_at"`   // Token expiration timestamp
+	UserAgent  nulls.String `db:"user_agent"`   // Client User-Agent
+	IP         nulls.String `db:"ip"`           // Client IP address
+	LastUsedAt nulls.Time   `db:"last_used_at"` // Last authenticated request
+	CreatedAt  time.Time    `db:"created_at"`   // Token creation timestamp
+	UpdatedAt  time.Time    `db:"updated_at"`   // Last modification timestamp
 }
+
+/**
+ * AuthTokenTouchInterval is the minimum time between last_used_at updates
+ * of a token
+ */
+const AuthTokenTouchInterval = 5 * time.Minute
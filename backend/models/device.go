@@ -0,0 +1,65 @@
+/**
+ * Device Model - Push Notification Targets
+ *
+ * This package defines the Device model. Mobile and web clients register
+ * their push token after sign-in so reminders and alerts can reach them.
+ * A push token belongs to one device and therefore to one user at a
+ * time: registering it again moves it to the registering user.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-21
+ */
+package models
+
+import (
+	"time"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/gofrs/uuid"
+)
+
+// Device platforms.
+const (
+	DevicePlatformIOS     = "ios"
+	DevicePlatformAndroid = "android"
+	DevicePlatformWeb     = "web"
+)
+
+/**
+ * DevicePlatforms lists the accepted platforms
+ */
+var DevicePlatforms = []string{DevicePlatformIOS, DevicePlatformAndroid, DevicePlatformWeb}
+
+/**
+ * MaxDevices is the number of devices a user may register
+ */
+const MaxDevices = 20
+
+/**
+ * Device represents one registered push target
+ *
+ * Database Fields:
+ * - id: Primary key (UUID)
+ * - user_id: Owner receiving the notifications
+ * - platform: ios, android or web
+ * - push_token: FCM registration token (unique)
+ * - name: Label shown in the device list, e.g. "Pixel 8"
+ * - last_seen_at: Last registration of the token
+ * - created_at, updated_at: Timestamps
+ */
+type Device struct {
+	ID         uuid.UUID    `db:"id" json:"id"`                     // Unique device identifier
+	UserID     uuid.UUID    `db:"user_id" json:"-"`                 // Owner user ID (hidden from JSON)
+	Platform   string       `db:"platform" json:"platform"`         // ios, android or web
+	PushToken  string       `db:"push_token" json:"-"`              // Provider token (hidden from JSON)
+	Name       nulls.String `db:"name" json:"name"`                 // User-visible label
+	LastSeenAt time.Time    `db:"last_seen_at" json:"last_seen_at"` // Last registration
+	CreatedAt  time.Time    `db:"created_at" json:"created_at"`     // Creation timestamp
+	UpdatedAt  time.Time    `db:"updated_at" json:"updated_at"`     // Last modification timestamp
+}
+
+/**
+ * TableName returns the database table name for the Device model
+ */
+func (d Device) TableName() string { return "devices" }
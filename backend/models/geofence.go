@@ -0,0 +1,138 @@
+/**
+ * Geofence Model - Job Site Areas for Location-Based Tracking
+ *
+ * This package defines the Geofence model, a circular area around a job
+ * site, and the GeofenceNotification model recording pending reminders
+ * when a user leaves a fence with a running entry.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-20
+ */
+package models
+
+import (
+	"math"
+	"time"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/gofrs/uuid"
+)
+
+// Geofence actions taken when the user leaves the fence.
+const (
+	GeofenceActionStop   = "stop"
+	GeofenceActionNotify = "notify"
+)
+
+/**
+ * StopReasonGeofenceExit marks entries stopped because the user left a
+ * geofence
+ */
+const StopReasonGeofenceExit = "geofence_exit"
+
+// earthRadiusMeters is the mean Earth radius used by HaversineMeters.
+const earthRadiusMeters = 6371008.8
+
+/**
+ * Geofence represents a circular area owned by a user
+ *
+ * Database Fields:
+ * - id: Primary key (UUID)
+ * - user_id: Owner
+ * - name: Display name, e.g. "Main office"
+ * - lat, lng: Center coordinates
+ * - radius_m: Radius in meters
+ * - action: "stop" or "notify"
+ * - created_at, updated_at: Timestamps
+ */
+type Geofence struct {
+	ID        uuid.UUID `db:"id" json:"id"`                 // Unique fence identifier
+	UserID    uuid.UUID `db:"user_id" json:"-"`             // Owner user ID (hidden from JSON)
+	Name      string    `db:"name" json:"name"`             // Display name
+	Lat       float64   `db:"lat" json:"lat"`               // Center latitude
+	Lng       float64   `db:"lng" json:"lng"`               // Center longitude
+	RadiusM   int       `db:"radius_m" json:"radius_m"`     // Radius in meters
+	Action    string    `db:"action" json:"action"`         // stop | notify
+	CreatedAt time.Time `db:"created_at" json:"created_at"` // Creation timestamp
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"` // Last modification timestamp
+}
+
+/**
+ * TableName returns the database table name for the Geofence model
+ */
+func (g Geofence) TableName() string { return "geofences" }
+
+/**
+ * Contains reports whether a point lies inside the fence
+ */
+func (g Geofence) Contains(lat, lng float64) bool {
+	return HaversineMeters(g.Lat, g.Lng, lat, lng) <= float64(g.RadiusM)
+}
+
+/**
+ * ExitMargin is the distance beyond the radius a point must reach before
+ * the user counts as having left the fence. It absorbs GPS jitter at the
+ * boundary: 20% of the radius, at least 25 m.
+ */
+func (g Geofence) ExitMargin() float64 {
+	return math.Max(25, 0.2*float64(g.RadiusM))
+}
+
+/**
+ * Exited reports whether a point is clearly outside the fence, i.e.
+ * beyond the radius plus the exit margin and the reported GPS accuracy.
+ *
+ * @param lat - Latitude of the point
+ * @param lng - Longitude of the point
+ * @param accuracyM - Reported GPS accuracy in meters (0 if unknown)
+ * @return bool - True if the point is outside the fence
+ */
+func (g Geofence) Exited(lat, lng, accuracyM float64) bool {
+	return HaversineMeters(g.Lat, g.Lng, lat, lng) > float64(g.RadiusM)+g.ExitMargin()+math.Max(accuracyM, 0)
+}
+
+/**
+ * GeofenceNotification records that a user left a notify-fence while an
+ * entry started inside it was still running
+ *
+ * Database Fields:
+ * - id: Primary key (UUID)
+ * - user_id: Recipient
+ * - geofence_id: Fence that was left
+ * - track_id: Running entry at the time
+ * - dismissed_at: When the user dismissed it (NULL = pending)
+ * - created_at, updated_at: Timestamps
+ *
+ * At most one notification exists per fence and entry.
+ */
+type GeofenceNotification struct {
+	ID          uuid.UUID  `db:"id" json:"id"`                     // Unique notification identifier
+	UserID      uuid.UUID  `db:"user_id" json:"-"`                 // Recipient user ID (hidden from JSON)
+	GeofenceID  uuid.UUID  `db:"geofence_id" json:"geofence_id"`   // Fence that was left
+	TrackID     uuid.UUID  `db:"track_id" json:"track_id"`         // Running entry
+	DismissedAt nulls.Time `db:"dismissed_at" json:"dismissed_at"` // NULL = pending
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`     // Creation timestamp
+	UpdatedAt   time.Time  `db:"updated_at" json:"updated_at"`     // Last modification timestamp
+}
+
+/**
+ * TableName returns the database table name for the GeofenceNotification model
+ */
+func (n GeofenceNotification) TableName() string { return "geofence_notifications" }
+
+/**
+ * HaversineMeters returns the great-circle distance between two points
+ *
+ * @param lat1, lng1 - First point in degrees
+ * @param lat2, lng2 - Second point in degrees
+ * @return float64 - Distance in meters
+ */
+func HaversineMeters(lat1, lng1, lat2, lng2 float64) float64 {
+	const rad = math.Pi / 180
+	dLat := (lat2 - lat1) * rad
+	dLng := (lng2 - lng1) * rad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusMeters * math.Asin(math.Min(1, math.Sqrt(a)))
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"math"
+	"testing"
+)
+
+func Test_HaversineMeters(t *testing.T) {
+	cases := []struct {
+		name                   string
+		lat1, lng1, lat2, lng2 float64
+		want, tolerance        float64
+	}{
+		{"same point", 48.2085, 16.3731, 48.2085, 16.3731, 0, 0.001},
+		{"one degree latitude", 0, 0, 1, 0, 111195, 5},
+		{"vienna to berlin", 48.2082, 16.3738, 52.5200, 13.4050, 523800, 2000},
+		{"across antimeridian", 0, 179.9, 0, -179.9, 22239, 5},
+	}
+	for _, tc := range cases {
+		got := HaversineMeters(tc.lat1, tc.lng1, tc.lat2, tc.lng2)
+		if math.Abs(got-tc.want) > tc.tolerance {
+			t.Errorf("%s: got %.1f m, want %.1f m", tc.name, got, tc.want)
+		}
+	}
+}
+
+func Test_Geofence_ExitHysteresis(t *testing.T) {
+	g := Geofence{Lat: 0, Lng: 0, RadiusM: 100}
+	// ~0.000009 degrees of latitude per meter
+	at := func(m float64) float64 { return m / 111195 }
+
+	if !g.Contains(at(90), 0) || g.Contains(at(110), 0) {
+		t.Fatal("Contains does not match the radius")
+	}
+	// Just outside the radius is jitter, not an exit
+	if g.Exited(at(110), 0, 0) {
+		t.Error("110 m should be within the exit margin")
+	}
+	if !g.Exited(at(130), 0, 0) {
+		t.Error("130 m should count as exited")
+	}
+	// Poor accuracy widens the margin
+	if g.Exited(at(130), 0, 50) {
+		t.Error("130 m with 50 m accuracy should not count as exited")
+	}
+}
@@ -0,0 +1,51 @@
+/**
+ * IdempotencyKey Model - Stored Responses for Retried Requests
+ *
+ * This package defines the IdempotencyKey model which stores the response
+ * of a request sent with an `Idempotency-Key` header, so a retry of the
+ * same request replays it instead of executing again.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-20
+ */
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+/**
+ * IdempotencyKeyTTL is how long a stored response is replayed
+ */
+const IdempotencyKeyTTL = 24 * time.Hour
+
+/**
+ * IdempotencyKey represents one client-supplied key and its response
+ *
+ * Database Fields:
+ * - id: Primary key (UUID)
+ * - user_id: Key owner; keys are unique per user
+ * - key: Client-supplied Idempotency-Key header value
+ * - request_path: Method and path the key was first used for
+ * - status, content_type, response_body: Stored response
+ * - created_at, updated_at: Timestamps
+ */
+type IdempotencyKey struct {
+	ID           uuid.UUID `db:"id" json:"id"`                       // Unique row identifier
+	UserID       uuid.UUID `db:"user_id" json:"-"`                   // Owner user ID
+	Key          string    `db:"key" json:"key"`                     // Idempotency-Key header value
+	RequestPath  string    `db:"request_path" json:"request_path"`   // e.g. "POST /api/tracks/start"
+	Status       int       `db:"status" json:"status"`               // Stored HTTP status
+	ContentType  string    `db:"content_type" json:"content_type"`   // Stored Content-Type
+	ResponseBody string    `db:"response_body" json:"response_body"` // Stored response body
+	CreatedAt    time.Time `db:"created_at" json:"created_at"`       // Creation timestamp
+	UpdatedAt    time.Time `db:"updated_at" json:"updated_at"`       // Last modification timestamp
+}
+
+/**
+ * TableName returns the database table name for the IdempotencyKey model
+ */
+func (k IdempotencyKey) TableName() string { return "idempotency_keys" }
@@ -0,0 +1,51 @@
+/**
+ * Identity Model - External Sign-In Accounts
+ *
+ * This package defines the Identity model linking an account at an
+ * external identity provider (e.g. Sign in with Apple) to a user. The
+ * provider's stable subject identifies the user on later sign-ins, when
+ * the provider may no longer send an email address.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-21
+ */
+package models
+
+import (
+	"time"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/gofrs/uuid"
+)
+
+// Identity providers.
+const (
+	IdentityProviderApple = "apple"
+)
+
+/**
+ * Identity maps a provider account to a user
+ *
+ * Database Fields:
+ * - id: Primary key (UUID)
+ * - user_id: Linked user
+ * - provider: Identity provider, e.g. "apple"
+ * - subject: Provider's user ID (unique per provider)
+ * - email: Latest email the provider reported, possibly a relay address
+ * - created_at, updated_at: Timestamps
+ */
+type Identity struct {
+	ID        uuid.UUID    `db:"id" json:"id"`                 // Unique identity identifier
+	UserID    uuid.UUID    `db:"user_id" json:"-"`             // Linked user
+	Provider  string       `db:"provider" json:"provider"`     // Identity provider
+	Subject   string       `db:"subject" json:"-"`             // Provider user ID
+	Email     nulls.String `db:"email" json:"email"`           // Provider email
+	CreatedAt time.Time    `db:"created_at" json:"created_at"` // Creation timestamp
+	UpdatedAt time.Time    `db:"updated_at" json:"updated_at"` // Last modification timestamp
+}
+
+/**
+ * TableName returns the database table name for the Identity model
+ */
+func (i Identity) TableName() string { return "identities" }
@@ -0,0 +1,70 @@
+/**
+ * LoginEvent Model - Sign-In History
+ *
+ * This package defines the LoginEvent model. Every sign-in attempt is
+ * recorded with the client it came from and its outcome, so users can
+ * review recent activity on their account. Failed attempts for unknown
+ * addresses are kept without a user, for throttling by email and IP.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-21
+ */
+package models
+
+import (
+	"time"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/gofrs/uuid"
+)
+
+// Sign-in methods.
+const (
+	LoginMethodPassword  = "password"
+	LoginMethodApple     = "apple"
+	LoginMethodMagicLink = "magic_link"
+)
+
+// Sign-in outcomes.
+const (
+	LoginSucceeded       = "success"
+	LoginInvalidPassword = "invalid_password"
+	LoginUnknownEmail    = "unknown_email"
+	LoginInvalidToken    = "invalid_token"
+	LoginDeactivated     = "deactivated"
+)
+
+/**
+ * LoginEventRetention is how long sign-in attempts are kept
+ */
+const LoginEventRetention = 90 * 24 * time.Hour
+
+/**
+ * LoginEvent is one sign-in attempt
+ *
+ * Database Fields:
+ * - id: Primary key (UUID)
+ * - user_id: Account signed in to (NULL when none matched)
+ * - email: Address given by the client, for password sign-ins
+ * - method: password, apple or magic_link
+ * - outcome: success or the reason of the failure
+ * - ip, user_agent: Client, as for session tokens
+ * - created_at, updated_at: Timestamps
+ */
+type LoginEvent struct {
+	ID        uuid.UUID    `db:"id" json:"id"`                 // Unique event identifier
+	UserID    nulls.UUID   `db:"user_id" json:"-"`             // Account, if known
+	Email     nulls.String `db:"email" json:"-"`               // Address tried
+	Method    string       `db:"method" json:"method"`         // Sign-in method
+	Outcome   string       `db:"outcome" json:"outcome"`       // success or failure reason
+	IP        nulls.String `db:"ip" json:"ip"`                 // Client IP address
+	UserAgent nulls.String `db:"user_agent" json:"user_agent"` // Client User-Agent
+	CreatedAt time.Time    `db:"created_at" json:"created_at"` // Attempt timestamp
+	UpdatedAt time.Time    `db:"updated_at" json:"-"`          // Last modification timestamp
+}
+
+/**
+ * TableName returns the database table name for the LoginEvent model
+ */
+func (e LoginEvent) TableName() string { return "login_events" }
@@ -0,0 +1,49 @@
+/**
+ * MagicLinkToken Model - Passwordless Sign-In Links
+ *
+ * This package defines the MagicLinkToken model. The token is an opaque
+ * random string emailed to the user inside a sign-in link; only its
+ * SHA-256 hash (see HashRefreshToken) is stored. A token can be consumed
+ * once, and requesting a new link invalidates the user's earlier ones.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-21
+ */
+package models
+
+import (
+	"time"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/gofrs/uuid"
+)
+
+// MagicLinkTTL is how long a sign-in link stays valid.
+const MagicLinkTTL = 15 * time.Minute
+
+/**
+ * MagicLinkToken is one emailed sign-in link
+ *
+ * Database Fields:
+ * - id: Primary key (UUID)
+ * - user_id: User the link signs in
+ * - token_hash: Hex SHA-256 of the token
+ * - expires_at: Time after which the link is rejected
+ * - used_at: When the link was consumed (NULL = unused)
+ * - created_at, updated_at: Timestamps
+ */
+type MagicLinkToken struct {
+	ID        uuid.UUID  `db:"id"`         // Unique token identifier
+	UserID    uuid.UUID  `db:"user_id"`    // User the link signs in
+	TokenHash string     `db:"token_hash"` // SHA-256 of the token
+	ExpiresAt time.Time  `db:"expires_at"` // Expiration timestamp
+	UsedAt    nulls.Time `db:"used_at"`    // Consumption timestamp
+	CreatedAt time.Time  `db:"created_at"` // Creation timestamp
+	UpdatedAt time.Time  `db:"updated_at"` // Last modification timestamp
+}
+
+/**
+ * TableName returns the database table name for the MagicLinkToken model
+ */
+func (t MagicLinkToken) TableName() string { return "magic_link_tokens" }
@@ -0,0 +1,60 @@
+/**
+ * MemberRate Model - Billing Rate History of Team Members
+ *
+ * This package defines MemberRate, one row of member_rate_history. Every
+ * change of a member's hourly rate (team_members.hourly_rate_cents) adds
+ * a row effective from the time of the change, so costs of past entries
+ * are computed with the rate that applied when they were tracked rather
+ * than the current one. A row without a rate marks the rate as cleared.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-22
+ */
+package models
+
+import (
+	"time"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/gofrs/uuid"
+)
+
+// MaxHourlyRateCents bounds the hourly rate a member can be given.
+const MaxHourlyRateCents = 10_000_000
+
+/**
+ * MemberRate is a member's hourly rate from a point in time on
+ *
+ * Database Fields:
+ * - id: Primary key (UUID)
+ * - team_id, user_id: Member the rate applies to
+ * - hourly_rate_cents: Rate in cents per hour (NULL: no rate)
+ * - currency: ISO 4217 code of the rate (NULL with the rate)
+ * - effective_from: Entries starting at or after this time use the rate
+ * - set_by: User who set it (NULL once their account is deleted)
+ * - created_at, updated_at: Timestamps
+ */
+type MemberRate struct {
+	ID              uuid.UUID    `db:"id" json:"id"`                               // Unique history row identifier
+	TeamID          uuid.UUID    `db:"team_id" json:"team_id"`                     // Team reference
+	UserID          uuid.UUID    `db:"user_id" json:"user_id"`                     // Member's user
+	HourlyRateCents nulls.Int    `db:"hourly_rate_cents" json:"hourly_rate_cents"` // Rate in cents per hour
+	Currency        nulls.String `db:"currency" json:"currency"`                   // ISO 4217 code
+	EffectiveFrom   time.Time    `db:"effective_from" json:"effective_from"`       // Start of validity
+	SetBy           nulls.UUID   `db:"set_by" json:"set_by"`                       // Who set the rate
+	CreatedAt       time.Time    `db:"created_at" json:"created_at"`               // Creation timestamp
+	UpdatedAt       time.Time    `db:"updated_at" json:"updated_at"`               // Last modification timestamp
+}
+
+/**
+ * TableName returns the database table name for the MemberRate model
+ */
+func (m MemberRate) TableName() string { return "member_rate_history" }
+
+/**
+ * ValidCurrency reports whether code is an ISO 4217 alphabetic code
+ */
+func ValidCurrency(code string) bool {
+	return currencyCode.MatchString(code)
+}
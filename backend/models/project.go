@@ -0,0 +1,146 @@
+/**
+ * Project Model - Shared Team Projects
+ *
+ * Personal projects are plain names on time entries (see ArchivedProject).
+ * A team keeps a common project list in the projects table so that its
+ * members' entries use the same names and aggregate in team reports.
+ * Entries still reference projects by name. A restricted project is only
+ * visible to the members listed for it (ProjectMember) and to team owners
+ * and admins. A project can have a budget in hours, in money (the team's
+ * default currency at the members' rates), or both.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-21
+ */
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/gofrs/uuid"
+)
+
+// MaxProjectNameLength bounds project names, in characters.
+const MaxProjectNameLength = 255
+
+// Bounds of project budgets.
+const (
+	MaxBudgetHours       = 1_000_000
+	MaxBudgetAmountCents = 100_000_000_000
+)
+
+// BudgetThresholds are the shares of a budget, in percent, whose
+// crossing raises an alert.
+var BudgetThresholds = []int{80, 100}
+
+/**
+ * Project represents a named project shared by a team
+ *
+ * Database Fields:
+ * - id: Primary key (UUID)
+ * - team_id: Owning team (NULL is reserved for personal projects)
+ * - name: Project name as stored on entries, unique per team
+ * - color: Hex color for pickers (optional)
+ * - archived_at: When the project was hidden from pickers (NULL = active)
+ * - restricted: Only listed members (project_members) plus owners and
+ *   admins can see the project and its entries
+ * - budget_hours: Hours budgeted (NULL = no hours budget)
+ * - budget_amount_cents: Money budgeted in the team's default currency
+ *   (NULL = no money budget)
+ * - budget_alerted_hours, budget_alerted_amount: Highest threshold of
+ *   BudgetThresholds alerted for each budget, 0 for none; reset when the
+ *   budget changes
+ * - created_by: User who created the project (NULL once deleted)
+ * - created_at: Creation timestamp
+ * - updated_at: Last modification timestamp
+ */
+type Project struct {
+	ID         uuid.UUID    `db:"id" json:"id"`                   // Unique project identifier
+	TeamID     nulls.UUID   `db:"team_id" json:"team_id"`         // Owning team
+	Name       string       `db:"name" json:"name"`               // Project name
+	Color      nulls.String `db:"color" json:"color"`             // Picker color (optional)
+	ArchivedAt nulls.Time   `db:"archived_at" json:"archived_at"` // Archive timestamp (NULL = active)
+	Restricted bool         `db:"restricted" json:"restricted"`   // Visible to listed members only
+
+	BudgetHours         nulls.Float64 `db:"budget_hours" json:"budget_hours"`               // Hours budget (optional)
+	BudgetAmountCents   nulls.Int64   `db:"budget_amount_cents" json:"budget_amount_cents"` // Money budget (optional, null without manage_rates)
+	BudgetAlertedHours  int           `db:"budget_alerted_hours" json:"-"`                  // Threshold alerted for hours
+	BudgetAlertedAmount int           `db:"budget_alerted_amount" json:"-"`                 // Threshold alerted for money
+
+	CreatedBy nulls.UUID `db:"created_by" json:"created_by"` // Creator user ID
+	CreatedAt time.Time  `db:"created_at" json:"created_at"` // Creation timestamp
+	UpdatedAt time.Time  `db:"updated_at" json:"updated_at"` // Last modification timestamp
+}
+
+/**
+ * TableName returns the database table name for the Project model
+ */
+func (p Project) TableName() string { return "projects" }
+
+/**
+ * ProjectMember grants a user access to a restricted project
+ *
+ * Database Fields:
+ * - id: Primary key (UUID)
+ * - project_id: Foreign key to projects
+ * - user_id: Member given access
+ * - added_by: Who granted access (NULL once deleted)
+ * - created_at: When access was granted
+ * - updated_at: Last modification timestamp
+ */
+type ProjectMember struct {
+	ID        uuid.UUID  `db:"id" json:"id"`                 // Unique row identifier
+	ProjectID uuid.UUID  `db:"project_id" json:"project_id"` // Restricted project
+	UserID    uuid.UUID  `db:"user_id" json:"user_id"`       // Member given access
+	AddedBy   nulls.UUID `db:"added_by" json:"added_by"`     // Granting user
+	CreatedAt time.Time  `db:"created_at" json:"created_at"` // Grant timestamp
+	UpdatedAt time.Time  `db:"updated_at" json:"updated_at"` // Last modification timestamp
+}
+
+/**
+ * TableName returns the database table name for the ProjectMember model
+ */
+func (pm ProjectMember) TableName() string { return "project_members" }
+
+/**
+ * NormalizeProjectName trims a project name and checks its length
+ *
+ * @param name - Client-supplied name
+ * @return string - Trimmed name
+ * @return error - Empty or too long
+ */
+func NormalizeProjectName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if n := utf8.RuneCountInString(name); n == 0 || n > MaxProjectNameLength {
+		return "", fmt.Errorf("name must be 1 to %d characters", MaxProjectNameLength)
+	}
+	return name, nil
+}
+
+/**
+ * BudgetThresholdCrossed returns the highest threshold of
+ * BudgetThresholds that used reaches and that is above the one already
+ * alerted, or 0 when there is none to alert
+ *
+ * @param alerted - Threshold alerted before, 0 for none
+ * @param used - Consumption, in the budget's unit
+ * @param budget - Budget, in the same unit; 0 or less has no thresholds
+ * @return int - Threshold to alert, in percent
+ */
+func BudgetThresholdCrossed(alerted int, used, budget float64) int {
+	if budget <= 0 {
+		return 0
+	}
+	crossed := 0
+	for _, t := range BudgetThresholds {
+		if t > alerted && used*100 >= budget*float64(t) {
+			crossed = t
+		}
+	}
+	return crossed
+}
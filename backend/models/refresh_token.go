@@ -0,0 +1,66 @@
+/**
+ * RefreshToken Model - Long-Lived Session Renewal
+ *
+ * This package defines the RefreshToken model. A refresh token is an
+ * opaque random string exchanged for a new access token and a new
+ * refresh token; only its SHA-256 hash is stored.
+ *
+ * Tokens descending from one login form a family. Each token can be used
+ * once; presenting a used token again means it was copied, and the whole
+ * family is revoked.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-21
+ */
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/gofrs/uuid"
+)
+
+/**
+ * RefreshToken is one link in a login's chain of refresh tokens
+ *
+ * Database Fields:
+ * - id: Primary key (UUID)
+ * - user_id: Owner
+ * - family_id: Shared by all tokens rotated from the same login
+ * - token_hash: Hex SHA-256 of the token (see HashRefreshToken)
+ * - expires_at: Time after which the token is rejected
+ * - used_at: When the token was rotated (NULL = unused)
+ * - revoked_at: When the family was revoked (NULL = active)
+ * - created_at, updated_at: Timestamps
+ */
+type RefreshToken struct {
+	ID        uuid.UUID  `db:"id"`         // Unique token identifier
+	UserID    uuid.UUID  `db:"user_id"`    // Owner user ID
+	FamilyID  uuid.UUID  `db:"family_id"`  // Login the token descends from
+	TokenHash string     `db:"token_hash"` // SHA-256 of the token
+	ExpiresAt time.Time  `db:"expires_at"` // Expiration timestamp
+	UsedAt    nulls.Time `db:"used_at"`    // Rotation timestamp
+	RevokedAt nulls.Time `db:"revoked_at"` // Revocation timestamp
+	CreatedAt time.Time  `db:"created_at"` // Creation timestamp
+	UpdatedAt time.Time  `db:"updated_at"` // Last modification timestamp
+}
+
+/**
+ * TableName returns the database table name for the RefreshToken model
+ */
+func (t RefreshToken) TableName() string { return "refresh_tokens" }
+
+/**
+ * HashRefreshToken returns the stored form of a refresh token
+ *
+ * @param raw - Token as sent by the client
+ * @return string - Hex-encoded SHA-256 digest
+ */
+func HashRefreshToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
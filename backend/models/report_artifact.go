@@ -0,0 +1,71 @@
+/**
+ * ReportArtifact Model - Stored Report Files
+ *
+ * This package defines the ReportArtifact model: a generated report file
+ * kept in the file store so it can be downloaded again until it expires.
+ * Report jobs and scheduled report runs write artifacts; the report
+ * history lists them.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-22
+ */
+package models
+
+import (
+	"time"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/gofrs/uuid"
+)
+
+// Where a report artifact came from.
+const (
+	ReportArtifactJob       = "job"       // A report job (POST /api/reports/jobs)
+	ReportArtifactScheduled = "scheduled" // A run of a scheduled report
+)
+
+/**
+ * ReportArtifact is a stored report file
+ *
+ * Database Fields:
+ * - id: Primary key (UUID)
+ * - user_id: Owner, the only user who can download it
+ * - team_id: Team whose entries are reported (NULL = the owner's own)
+ * - scheduled_report_id: Scheduled report that produced it, if any
+ * - source: job or scheduled
+ * - template, title: The report
+ * - format: json, csv or pdf
+ * - filename: Download name
+ * - period_from, period_to: Period covered, the end exclusive
+ * - timezone: IANA zone of days and times in the report
+ * - size: File size in bytes
+ * - file_key: Storage key of the file
+ * - expires_at: When the file is removed
+ * - created_at, updated_at: Timestamps
+ */
+type ReportArtifact struct {
+	ID                uuid.UUID  `db:"id" json:"id"`                                   // Unique artifact identifier
+	UserID            uuid.UUID  `db:"user_id" json:"-"`                               // Owner user ID (hidden from JSON)
+	TeamID            nulls.UUID `db:"team_id" json:"team_id"`                         // Reported team, null for personal reports
+	ScheduledReportID nulls.UUID `db:"scheduled_report_id" json:"scheduled_report_id"` // Producing scheduled report
+	Source            string     `db:"source" json:"source"`                           // job | scheduled
+	Template          string     `db:"template" json:"template"`                       // summary | project | detailed
+	Title             string     `db:"title" json:"title"`                             // Report title
+	Format            string     `db:"format" json:"format"`                           // json | csv | pdf
+	Filename          string     `db:"filename" json:"filename"`                       // Download name
+	PeriodFrom        time.Time  `db:"period_from" json:"from"`                        // Start of the period (inclusive)
+	PeriodTo          time.Time  `db:"period_to" json:"to"`                            // End of the period (exclusive)
+	Timezone          string     `db:"timezone" json:"timezone"`                       // IANA zone
+	Size              int64      `db:"size" json:"size"`                               // File size in bytes
+	FileKey           string     `db:"file_key" json:"-"`                              // Storage key
+	ExpiresAt         time.Time  `db:"expires_at" json:"expires_at"`                   // File removal time
+	CreatedAt         time.Time  `db:"created_at" json:"created_at"`                   // Creation timestamp
+	UpdatedAt         time.Time  `db:"updated_at" json:"updated_at"`                   // Last modification timestamp
+	DownloadURL       string     `db:"-" json:"download_url"`                          // Set by the API
+}
+
+/**
+ * TableName returns the database table name for the ReportArtifact model
+ */
+func (a ReportArtifact) TableName() string { return "report_artifacts" }
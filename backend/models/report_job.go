@@ -0,0 +1,78 @@
+/**
+ * ReportJob Model - Reports Generated in the Background
+ *
+ * This package defines the ReportJob model: a report a user asked for
+ * through POST /api/reports/jobs, queued for the report workers because
+ * it may take longer than a request. A job moves from queued to running
+ * to completed or failed; a completed job points at the ReportArtifact
+ * holding its file.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-22
+ */
+package models
+
+import (
+	"time"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/gofrs/uuid"
+)
+
+// Report job states.
+const (
+	ReportJobQueued    = "queued"
+	ReportJobRunning   = "running"
+	ReportJobCompleted = "completed"
+	ReportJobFailed    = "failed"
+)
+
+/**
+ * ReportJob is a report generated in the background
+ *
+ * Database Fields:
+ * - id: Primary key (UUID)
+ * - user_id: Owner, the user who asked for the report
+ * - team_id: Team whose entries are reported (NULL = the owner's own)
+ * - status: queued, running, completed or failed
+ * - progress: Percent done, 100 once completed
+ * - template, title, config: The report to generate
+ * - format: json, csv or pdf
+ * - period_from, period_to: Period covered, the end exclusive
+ * - timezone: IANA zone of days and times in the report
+ * - error: Why the job failed
+ * - artifact_id: Stored file of a completed job (NULL once removed)
+ * - started_at, finished_at: When a worker picked the job up and let go
+ * - expires_at: When the job is removed
+ * - request_id: Request that queued the job
+ * - created_at, updated_at: Timestamps
+ */
+type ReportJob struct {
+	ID          uuid.UUID    `db:"id" json:"id"`                           // Unique job identifier
+	UserID      uuid.UUID    `db:"user_id" json:"-"`                       // Owner user ID (hidden from JSON)
+	TeamID      nulls.UUID   `db:"team_id" json:"team_id"`                 // Reported team, null for personal reports
+	Status      string       `db:"status" json:"status"`                   // queued | running | completed | failed
+	Progress    int          `db:"progress" json:"progress"`               // Percent done
+	Template    string       `db:"template" json:"template"`               // summary | project | detailed
+	Title       string       `db:"title" json:"title"`                     // Report title, "" for the template's
+	Format      string       `db:"format" json:"format"`                   // json | csv | pdf
+	Config      ReportConfig `db:"config" json:"config"`                   // Grouping, sections and filters
+	PeriodFrom  time.Time    `db:"period_from" json:"from"`                // Start of the period (inclusive)
+	PeriodTo    time.Time    `db:"period_to" json:"to"`                    // End of the period (exclusive)
+	Timezone    string       `db:"timezone" json:"timezone"`               // IANA zone
+	Error       nulls.String `db:"error" json:"error"`                     // Failure reason
+	ArtifactID  nulls.UUID   `db:"artifact_id" json:"artifact_id"`         // Stored file of a completed job
+	StartedAt   nulls.Time   `db:"started_at" json:"started_at"`           // Picked up by a worker
+	FinishedAt  nulls.Time   `db:"finished_at" json:"finished_at"`         // Completed or failed
+	ExpiresAt   nulls.Time   `db:"expires_at" json:"expires_at"`           // Removal time
+	RequestID   string       `db:"request_id" json:"request_id,omitempty"` // ID of the queuing request
+	CreatedAt   time.Time    `db:"created_at" json:"created_at"`           // Queue time
+	UpdatedAt   time.Time    `db:"updated_at" json:"updated_at"`           // Last modification timestamp
+	DownloadURL string       `db:"-" json:"download_url,omitempty"`        // Set on completed jobs by the API
+}
+
+/**
+ * TableName returns the database table name for the ReportJob model
+ */
+func (j ReportJob) TableName() string { return "report_jobs" }
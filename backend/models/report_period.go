@@ -0,0 +1,132 @@
+/**
+ * Report Periods - Relative Date Ranges for Reports
+ *
+ * A report config can name its period relative to the time the report is
+ * generated instead of fixing from and to: "last_week" covers the week
+ * before the one a scheduled report runs in, every time it runs. Periods
+ * are whole days in the report's time zone, so they follow DST changes
+ * and month lengths; weeks start on the config's week_start.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-22
+ */
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Report period presets; last_n_days takes its count after a colon.
+const (
+	PeriodToday     = "today"
+	PeriodYesterday = "yesterday"
+	PeriodThisWeek  = "this_week"
+	PeriodLastWeek  = "last_week"
+	PeriodThisMonth = "this_month"
+	PeriodLastMonth = "last_month"
+	PeriodLastNDays = "last_n_days"
+)
+
+// MaxReportPeriodDays bounds the count of a last_n_days period.
+const MaxReportPeriodDays = 366
+
+// errBadPeriod describes the accepted period values.
+var errBadPeriod = errors.New("period must be today, yesterday, this_week, last_week, this_month, last_month or last_n_days:N")
+
+// weekdays maps the accepted week_start values to their weekday.
+var weekdays = map[string]time.Weekday{
+	"monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday, "thursday": time.Thursday,
+	"friday": time.Friday, "saturday": time.Saturday, "sunday": time.Sunday,
+}
+
+/**
+ * ParseWeekStart returns the weekday a week_start value names; "" is
+ * Monday, as in ISO weeks
+ */
+func ParseWeekStart(v string) (time.Weekday, error) {
+	if v == "" {
+		return time.Monday, nil
+	}
+	d, ok := weekdays[v]
+	if !ok {
+		return 0, errors.New("week_start must be a weekday such as monday or sunday")
+	}
+	return d, nil
+}
+
+/**
+ * WeekStartOf returns midnight of the first day of t's week in t's zone
+ */
+func WeekStartOf(t time.Time, weekStart time.Weekday) time.Time {
+	y, m, d := t.Date()
+	offset := (int(t.Weekday()) - int(weekStart) + 7) % 7
+	return time.Date(y, m, d-offset, 0, 0, 0, 0, t.Location())
+}
+
+/**
+ * ResolvePeriod turns a period preset into the range it covers at now
+ *
+ * Weeks and months are calendar weeks and months; this_week and
+ * this_month cover the whole current week or month. last_n_days:N is the
+ * N complete days before today.
+ *
+ * @param period - Preset, such as last_week or last_n_days:30
+ * @param weekStart - First day of a week
+ * @param now - Reference time
+ * @param loc - Zone the days are in
+ * @return time.Time - Start of the period (inclusive)
+ * @return time.Time - End of the period (exclusive)
+ * @return error - The preset is not known
+ */
+func ResolvePeriod(period string, weekStart time.Weekday, now time.Time, loc *time.Location) (time.Time, time.Time, error) {
+	t := now.In(loc)
+	y, m, d := t.Date()
+	day := func(offset int) time.Time { return time.Date(y, m, d+offset, 0, 0, 0, 0, loc) }
+
+	switch period {
+	case PeriodToday:
+		return day(0), day(1), nil
+	case PeriodYesterday:
+		return day(-1), day(0), nil
+	case PeriodThisWeek, PeriodLastWeek:
+		from := WeekStartOf(t, weekStart)
+		if period == PeriodLastWeek {
+			from = from.AddDate(0, 0, -7)
+		}
+		return from, from.AddDate(0, 0, 7), nil
+	case PeriodThisMonth:
+		from := time.Date(y, m, 1, 0, 0, 0, 0, loc)
+		return from, from.AddDate(0, 1, 0), nil
+	case PeriodLastMonth:
+		to := time.Date(y, m, 1, 0, 0, 0, 0, loc)
+		return to.AddDate(0, -1, 0), to, nil
+	}
+	n, err := periodDays(period)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return day(-n), day(0), nil
+}
+
+/**
+ * periodDays returns the count of a last_n_days:N period
+ */
+func periodDays(period string) (int, error) {
+	v, ok := strings.CutPrefix(period, PeriodLastNDays+":")
+	if !ok {
+		return 0, errBadPeriod
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, errBadPeriod
+	}
+	if n < 1 || n > MaxReportPeriodDays {
+		return 0, fmt.Errorf("last_n_days must count 1 to %d days", MaxReportPeriodDays)
+	}
+	return n, nil
+}
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_ResolvePeriod(t *testing.T) {
+	vienna, err := time.LoadLocation("Europe/Vienna")
+	if err != nil {
+		t.Skip("tzdata not available")
+	}
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("tzdata not available")
+	}
+	at := func(s string, loc *time.Location) time.Time {
+		v, err := time.ParseInLocation("2006-01-02 15:04", s, loc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	for _, tc := range []struct {
+		name      string
+		period    string
+		weekStart time.Weekday
+		now       time.Time
+		loc       *time.Location
+		from, to  string // Local dates; the range ends at midnight of to
+	}{
+		{"today", PeriodToday, time.Monday, at("2025-03-05 10:00", time.UTC), time.UTC, "2025-03-05", "2025-03-06"},
+		{"yesterday across the year", PeriodYesterday, time.Monday, at("2025-01-01 00:30", vienna), vienna, "2024-12-31", "2025-01-01"},
+		// 23:30 UTC on Dec 31 is already Jan 1 in Vienna
+		{"today in the report's zone", PeriodToday, time.Monday, at("2024-12-31 23:30", time.UTC), vienna, "2025-01-01", "2025-01-02"},
+		{"this ISO week across the year", PeriodThisWeek, time.Monday, at("2025-01-01 12:00", time.UTC), time.UTC, "2024-12-30", "2025-01-06"},
+		{"last ISO week across the year", PeriodLastWeek, time.Monday, at("2025-01-05 12:00", time.UTC), time.UTC, "2024-12-23", "2024-12-30"},
+		{"last week on its first day", PeriodLastWeek, time.Monday, at("2025-03-10 00:00", time.UTC), time.UTC, "2025-03-03", "2025-03-10"},
+		{"this week from sunday", PeriodThisWeek, time.Sunday, at("2025-03-08 12:00", time.UTC), time.UTC, "2025-03-02", "2025-03-09"},
+		{"last week from sunday on a sunday", PeriodLastWeek, time.Sunday, at("2025-03-09 12:00", time.UTC), time.UTC, "2025-03-02", "2025-03-09"},
+		{"last week from saturday", PeriodLastWeek, time.Saturday, at("2025-03-07 12:00", time.UTC), time.UTC, "2025-02-22", "2025-03-01"},
+		{"this month", PeriodThisMonth, time.Monday, at("2025-02-28 23:59", time.UTC), time.UTC, "2025-02-01", "2025-03-01"},
+		{"last month of a leap year", PeriodLastMonth, time.Monday, at("2024-03-31 12:00", time.UTC), time.UTC, "2024-02-01", "2024-03-01"},
+		{"last month across the year", PeriodLastMonth, time.Monday, at("2025-01-15 12:00", vienna), vienna, "2024-12-01", "2025-01-01"},
+		{"last 7 days", PeriodLastNDays + ":7", time.Monday, at("2025-03-05 10:00", time.UTC), time.UTC, "2025-02-26", "2025-03-05"},
+		{"last 30 days across the year", PeriodLastNDays + ":30", time.Monday, at("2025-01-10 10:00", time.UTC), time.UTC, "2024-12-11", "2025-01-10"},
+		// Weeks and days spanning a DST change keep local midnights
+		{"week into summer time", PeriodLastWeek, time.Monday, at("2025-04-02 09:00", vienna), vienna, "2025-03-24", "2025-03-31"},
+		{"week out of summer time", PeriodThisWeek, time.Monday, at("2025-11-03 09:00", newYork), newYork, "2025-11-03", "2025-11-10"},
+		{"day of the fall back", PeriodYesterday, time.Monday, at("2025-11-03 09:00", newYork), newYork, "2025-11-02", "2025-11-03"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			from, to, err := ResolvePeriod(tc.period, tc.weekStart, tc.now, tc.loc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := at(tc.from+" 00:00", tc.loc); !from.Equal(want) {
+				t.Errorf("from: got %s, want %s", from, want)
+			}
+			if want := at(tc.to+" 00:00", tc.loc); !to.Equal(want) {
+				t.Errorf("to: got %s, want %s", to, want)
+			}
+		})
+	}
+
+	// A week containing a DST change lasts 167 or 169 hours
+	from, to, _ := ResolvePeriod(PeriodLastWeek, time.Monday, at("2025-04-02 09:00", vienna), vienna)
+	if got := to.Sub(from); got != 167*time.Hour {
+		t.Errorf("spring week: got %s", got)
+	}
+	from, to, _ = ResolvePeriod(PeriodYesterday, time.Monday, at("2025-11-03 09:00", newYork), newYork)
+	if got := to.Sub(from); got != 25*time.Hour {
+		t.Errorf("fall back day: got %s", got)
+	}
+
+	for _, bad := range []string{"", "last_year", "last_n_days", "last_n_days:", "last_n_days:0", "last_n_days:367", "last_n_days:x"} {
+		if _, _, err := ResolvePeriod(bad, time.Monday, time.Now(), time.UTC); err == nil {
+			t.Errorf("%q: no error", bad)
+		}
+	}
+}
+
+func Test_ParseWeekStart(t *testing.T) {
+	for v, want := range map[string]time.Weekday{"": time.Monday, "monday": time.Monday, "sunday": time.Sunday, "saturday": time.Saturday} {
+		if got, err := ParseWeekStart(v); err != nil || got != want {
+			t.Errorf("%q: got %v %v", v, got, err)
+		}
+	}
+	if _, err := ParseWeekStart("Sunday"); err == nil {
+		t.Error("Sunday: no error")
+	}
+	if err := (ReportConfig{Period: "last_n_days:400"}).Validate(); err == nil {
+		t.Error("config with last_n_days:400: no error")
+	}
+}
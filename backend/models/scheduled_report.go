@@ -0,0 +1,438 @@
+/**
+ * ScheduledReport Model - Reports Sent on a Schedule
+ *
+ * This package defines the ScheduledReport model: a report template a
+ * user wants produced daily, weekly or monthly at a local time, for
+ * their own entries or for one of their teams, and who receives it.
+ * NextRun computes the following run in the report's time zone; a
+ * monthly day the month does not have falls on its last day. Failed runs
+ * are retried with ReportRetryBackoff before the next regular run. A
+ * ReportRun records how a run was delivered to one recipient.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-22
+ */
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/gofrs/uuid"
+	"github.com/lib/pq"
+)
+
+// Report schedule frequencies.
+const (
+	ReportDaily   = "daily"
+	ReportWeekly  = "weekly"
+	ReportMonthly = "monthly"
+)
+
+// ReportTemplates lists the templates a scheduled report can use:
+// totals per day, totals per project, or every entry.
+var ReportTemplates = []string{"summary", "project", "detailed"}
+
+// ReportGroupings lists the values of a report config's group_by; "none"
+// leaves out the per-group table. An entry counts toward each of its
+// tags under "tag"; "member" only applies to team reports.
+var ReportGroupings = []string{"day", "week", "month", "project", "tag", "member", "none"}
+
+// MaxReportRecipients is the number of addresses a report can go to.
+const MaxReportRecipients = 20
+
+// MaxReportConfigProjects bounds the project filter of a report.
+const MaxReportConfigProjects = 50
+
+// Outcomes of a report run (last_status).
+const (
+	ReportRunSucceeded = "succeeded"
+	ReportRunFailed    = "failed"
+	ReportRunSkipped   = "skipped" // The report could not apply, e.g. its team is archived
+)
+
+// Delivery states of a ReportRun.
+const (
+	ReportDeliverySent    = "sent"
+	ReportDeliveryFailed  = "failed"
+	ReportDeliverySkipped = "skipped" // Not sent, e.g. the recipient left the team
+)
+
+// How a ReportRun carried the report file.
+const (
+	ReportByAttachment = "attachment"
+	ReportByLink       = "link" // Too large to attach; the email links to it
+)
+
+// MaxReportAttempts is the number of consecutive failures after which a
+// report is no longer retried before its next regular run.
+const MaxReportAttempts = 5
+
+// timeOfDay matches a 24-hour HH:MM time.
+var timeOfDay = regexp.MustCompile(`^([01][0-9]|2[0-3]):[0-5][0-9]$`)
+
+/**
+ * ReportConfig shapes a report and narrows what it covers
+ *
+ * Only these keys are stored; anything else sent by the client is
+ * dropped when the payload is decoded.
+ */
+type ReportConfig struct {
+	GroupBy        string   `json:"group_by,omitempty"`   // One of ReportGroupings; the template's default when empty
+	IncludeDetails bool     `json:"include_details"`      // List every entry (always on for detailed reports)
+	IncludeCharts  bool     `json:"include_charts"`       // Chart the groups
+	Projects       []string `json:"projects,omitempty"`   // Only these projects; all when empty
+	BillableOnly   bool     `json:"billable_only"`        // Only billable time
+	Period         string   `json:"period,omitempty"`     // Preset resolved when generating (see ResolvePeriod)
+	WeekStart      string   `json:"week_start,omitempty"` // First day of weeks, monday when empty
+	Locale         string   `json:"locale,omitempty"`     // Language of the report; the owner's preference when empty
+}
+
+/**
+ * Validate checks group_by, the project filter, period, week_start and
+ * locale
+ */
+func (rc ReportConfig) Validate() error {
+	if rc.GroupBy != "" && !slices.Contains(ReportGroupings, rc.GroupBy) {
+		return errors.New("group_by must be day, week, month, project, tag, member or none")
+	}
+	if len(rc.Projects) > MaxReportConfigProjects {
+		return fmt.Errorf("projects must list at most %d projects", MaxReportConfigProjects)
+	}
+	for _, p := range rc.Projects {
+		if strings.TrimSpace(p) == "" {
+			return errors.New("projects must not contain empty names")
+		}
+	}
+	if _, err := ParseWeekStart(rc.WeekStart); err != nil {
+		return err
+	}
+	if rc.Period != "" {
+		if _, _, err := ResolvePeriod(rc.Period, time.Monday, time.Now(), time.UTC); err != nil {
+			return err
+		}
+	}
+	if rc.Locale != "" && !ValidLocale(rc.Locale) {
+		return fmt.Errorf("locale must be one of %s", strings.Join(Locales, ", "))
+	}
+	return nil
+}
+
+/**
+ * WeekStartDay returns the first day of the config's weeks, Monday when
+ * week_start is empty or invalid
+ */
+func (rc ReportConfig) WeekStartDay() time.Weekday {
+	d, err := ParseWeekStart(rc.WeekStart)
+	if err != nil {
+		return time.Monday
+	}
+	return d
+}
+
+/**
+ * ValidateFor checks the config like Validate and that its grouping
+ * applies to a team or personal report
+ */
+func (rc ReportConfig) ValidateFor(team bool) error {
+	if err := rc.Validate(); err != nil {
+		return err
+	}
+	if rc.GroupBy == "member" && !team {
+		return errors.New("group_by member is only available for team reports")
+	}
+	return nil
+}
+
+/**
+ * Value implements driver.Valuer, storing the config as JSON
+ */
+func (rc ReportConfig) Value() (driver.Value, error) {
+	b, err := json.Marshal(rc)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+/**
+ * Scan implements sql.Scanner for the JSONB column
+ */
+func (rc *ReportConfig) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*rc = ReportConfig{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, rc)
+	case string:
+		return json.Unmarshal([]byte(v), rc)
+	default:
+		return fmt.Errorf("cannot scan %T into ReportConfig", src)
+	}
+}
+
+/**
+ * ScheduledReport is a report produced on a schedule
+ *
+ * Database Fields:
+ * - id: Primary key (UUID)
+ * - user_id: Owner
+ * - team_id: Team whose entries are reported (NULL = the owner's own)
+ * - name: Display name
+ * - template: One of ReportTemplates
+ * - config: JSONB ReportConfig
+ * - frequency: daily, weekly or monthly
+ * - weekday: Day of a weekly report, 0 (Sunday) to 6
+ * - day_of_month: Day of a monthly report, 1 to 31
+ * - time_of_day: Local HH:MM the report runs at
+ * - timezone: IANA zone of time_of_day
+ * - recipients: Email addresses receiving the report
+ * - is_active: Paused reports do not run
+ * - last_run_at: When the report last ran (NULL = never)
+ * - next_run_at: When it runs next, or is retried (NULL while paused)
+ * - locked_at: When a runner claimed the report (NULL = not running)
+ * - last_status: Outcome of the last run, one of the ReportRun constants
+ * - last_error: Why the last run failed or was skipped
+ * - failure_count: Consecutive failed runs
+ * - created_at, updated_at: Timestamps
+ */
+type ScheduledReport struct {
+	ID         uuid.UUID      `db:"id" json:"id"`                       // Unique report identifier
+	UserID     uuid.UUID      `db:"user_id" json:"-"`                   // Owner user ID (hidden from JSON)
+	TeamID     nulls.UUID     `db:"team_id" json:"team_id"`             // Reported team, null for personal reports
+	Name       string         `db:"name" json:"name"`                   // Display name
+	Template   string         `db:"template" json:"template"`           // summary | project | detailed
+	Config     ReportConfig   `db:"config" json:"config"`               // Filters
+	Frequency  string         `db:"frequency" json:"frequency"`         // daily | weekly | monthly
+	Weekday    nulls.Int      `db:"weekday" json:"weekday"`             // 0 (Sunday) to 6, weekly only
+	DayOfMonth nulls.Int      `db:"day_of_month" json:"day_of_month"`   // 1 to 31, monthly only
+	TimeOfDay  string         `db:"time_of_day" json:"time_of_day"`     // Local HH:MM
+	Timezone   string         `db:"timezone" json:"timezone"`           // IANA zone
+	Recipients pq.StringArray `db:"recipients" json:"recipients"`       // Email addresses
+	IsActive   bool           `db:"is_active" json:"is_active"`         // Whether the report runs
+	LastRunAt  nulls.Time     `db:"last_run_at" json:"last_run_at"`     // Last run
+	NextRunAt  nulls.Time     `db:"next_run_at" json:"next_run_at"`     // Next run, null while paused
+	LockedAt   nulls.Time     `db:"locked_at" json:"-"`                 // Claimed by a runner
+	LastStatus nulls.String   `db:"last_status" json:"last_status"`     // Outcome of the last run
+	LastError  nulls.String   `db:"last_error" json:"last_error"`       // Failure or skip reason
+	Failures   int            `db:"failure_count" json:"failure_count"` // Consecutive failed runs
+	CreatedAt  time.Time      `db:"created_at" json:"created_at"`       // Creation timestamp
+	UpdatedAt  time.Time      `db:"updated_at" json:"updated_at"`       // Last modification timestamp
+}
+
+/**
+ * TableName returns the database table name for the ScheduledReport model
+ */
+func (s ScheduledReport) TableName() string { return "scheduled_reports" }
+
+/**
+ * ValidateSchedule checks the schedule fields; weekday is required for
+ * weekly reports and day_of_month for monthly ones, and both must be
+ * unset otherwise
+ *
+ * @return string - Field at fault, "" when valid
+ * @return error - Validation error
+ */
+func (s ScheduledReport) ValidateSchedule() (string, error) {
+	switch s.Frequency {
+	case ReportDaily, ReportWeekly, ReportMonthly:
+	default:
+		return "frequency", errors.New("must be daily, weekly or monthly")
+	}
+	if s.Frequency == ReportWeekly {
+		if !s.Weekday.Valid || s.Weekday.Int < 0 || s.Weekday.Int > 6 {
+			return "weekday", errors.New("must be from 0 (Sunday) to 6 for weekly reports")
+		}
+	} else if s.Weekday.Valid {
+		return "weekday", errors.New("only applies to weekly reports")
+	}
+	if s.Frequency == ReportMonthly {
+		if !s.DayOfMonth.Valid || s.DayOfMonth.Int < 1 || s.DayOfMonth.Int > 31 {
+			return "day_of_month", errors.New("must be from 1 to 31 for monthly reports")
+		}
+	} else if s.DayOfMonth.Valid {
+		return "day_of_month", errors.New("only applies to monthly reports")
+	}
+	if !timeOfDay.MatchString(s.TimeOfDay) {
+		return "time_of_day", errors.New("must be a 24-hour HH:MM time")
+	}
+	if _, err := time.LoadLocation(s.Timezone); err != nil || s.Timezone == "" {
+		return "timezone", errors.New("must be an IANA time zone")
+	}
+	return "", nil
+}
+
+/**
+ * ValidTemplate reports whether name is one of ReportTemplates
+ */
+func ValidTemplate(name string) bool {
+	return slices.Contains(ReportTemplates, name)
+}
+
+/**
+ * DefaultGroupBy returns the grouping a template uses when its config
+ * has no group_by: days for summaries, projects for project reports and
+ * none for detailed reports
+ */
+func DefaultGroupBy(template string) string {
+	switch template {
+	case "project":
+		return "project"
+	case "detailed":
+		return "none"
+	default:
+		return "day"
+	}
+}
+
+/**
+ * ReportRetryBackoff returns the wait before retrying a report after the
+ * given number of consecutive failures: 5m, 10m, 20m, 40m, ...
+ *
+ * @param failures - Failed runs so far (>= 1)
+ * @return time.Duration - Delay before the retry
+ */
+func ReportRetryBackoff(failures int) time.Duration {
+	if failures < 1 {
+		failures = 1
+	}
+	return 5 * time.Minute << (failures - 1)
+}
+
+/**
+ * location returns the report's time zone, UTC if it does not load
+ */
+func (s ScheduledReport) location() *time.Location {
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+/**
+ * Period returns the period a run at at covers in the report's time zone:
+ * the config's period preset when it has one, otherwise the last
+ * complete period before at: the previous day for daily reports, the 7
+ * days before at's day for weekly ones and the previous calendar month
+ * for monthly ones
+ *
+ * @param at - Run time
+ * @return time.Time - Start of the period (inclusive)
+ * @return time.Time - End of the period (exclusive)
+ */
+func (s ScheduledReport) Period(at time.Time) (time.Time, time.Time) {
+	loc := s.location()
+	if s.Config.Period != "" {
+		if from, to, err := ResolvePeriod(s.Config.Period, s.Config.WeekStartDay(), at, loc); err == nil {
+			return from, to
+		}
+	}
+	y, m, d := at.In(loc).Date()
+	switch s.Frequency {
+	case ReportWeekly:
+		to := time.Date(y, m, d, 0, 0, 0, 0, loc)
+		return to.AddDate(0, 0, -7), to
+	case ReportMonthly:
+		to := time.Date(y, m, 1, 0, 0, 0, 0, loc)
+		return to.AddDate(0, -1, 0), to
+	default:
+		to := time.Date(y, m, d, 0, 0, 0, 0, loc)
+		return to.AddDate(0, 0, -1), to
+	}
+}
+
+/**
+ * NextRun returns the first scheduled time strictly after after
+ *
+ * Times are local to the report's time zone (UTC if it does not load). A
+ * monthly day_of_month beyond the end of a month runs on its last day;
+ * a local time skipped by a DST change runs at the shifted time Go's
+ * time.Date yields.
+ *
+ * @param after - Reference time, usually now or the last run
+ * @return time.Time - Next run in UTC
+ */
+func (s ScheduledReport) NextRun(after time.Time) time.Time {
+	loc := s.location()
+	var hour, minute int
+	fmt.Sscanf(s.TimeOfDay, "%d:%d", &hour, &minute)
+
+	t := after.In(loc)
+	y, m, d := t.Date()
+	at := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, hour, minute, 0, 0, loc)
+	}
+	switch s.Frequency {
+	case ReportWeekly:
+		d += (s.Weekday.Int - int(t.Weekday()) + 7) % 7
+		next := at(y, m, d)
+		if !next.After(after) {
+			next = at(y, m, d+7)
+		}
+		return next.UTC()
+	case ReportMonthly:
+		for i := 0; ; i++ {
+			// Day 0 of the following month is the month's last day
+			last := time.Date(y, m+time.Month(i)+1, 0, 0, 0, 0, 0, loc).Day()
+			next := at(y, m+time.Month(i), min(s.DayOfMonth.Int, last))
+			if next.After(after) {
+				return next.UTC()
+			}
+		}
+	default:
+		next := at(y, m, d)
+		if !next.After(after) {
+			next = at(y, m, d+1)
+		}
+		return next.UTC()
+	}
+}
+
+/**
+ * ReportRun is the delivery of one run of a scheduled report to one
+ * recipient
+ *
+ * Database Fields:
+ * - id: Primary key (UUID), also identifies the download link
+ * - scheduled_report_id: Report that ran
+ * - run_at: When the run started; shared by the rows of a run
+ * - period_from, period_to: Period the report covered
+ * - recipient: Email address
+ * - status: sent, failed or skipped
+ * - delivery: attachment or link (NULL when nothing was sent)
+ * - error: Why the delivery failed or was skipped
+ * - artifact_id: Stored report file (NULL when the run failed before
+ *   storing one, or once it was removed)
+ * - sent_at: When the email was handed to the mail server
+ * - created_at, updated_at: Timestamps
+ */
+type ReportRun struct {
+	ID                uuid.UUID    `db:"id" json:"id"`                                   // Unique run identifier
+	ScheduledReportID uuid.UUID    `db:"scheduled_report_id" json:"scheduled_report_id"` // Report that ran
+	RunAt             time.Time    `db:"run_at" json:"run_at"`                           // Start of the run
+	PeriodFrom        time.Time    `db:"period_from" json:"period_from"`                 // Start of the period (inclusive)
+	PeriodTo          time.Time    `db:"period_to" json:"period_to"`                     // End of the period (exclusive)
+	Recipient         string       `db:"recipient" json:"recipient"`                     // Email address
+	Status            string       `db:"status" json:"status"`                           // sent | failed | skipped
+	Delivery          nulls.String `db:"delivery" json:"delivery"`                       // attachment | link
+	Error             nulls.String `db:"error" json:"error"`                             // Failure or skip reason
+	ArtifactID        nulls.UUID   `db:"artifact_id" json:"artifact_id"`                 // Stored report file
+	SentAt            nulls.Time   `db:"sent_at" json:"sent_at"`                         // Hand-off to the mail server
+	CreatedAt         time.Time    `db:"created_at" json:"created_at"`                   // Creation timestamp
+	UpdatedAt         time.Time    `db:"updated_at" json:"updated_at"`                   // Last modification timestamp
+}
+
+/**
+ * TableName returns the database table name for the ReportRun model
+ */
+func (r ReportRun) TableName() string { return "report_runs" }
@@ -0,0 +1,128 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gobuffalo/nulls"
+)
+
+func Test_ScheduledReport_ValidateSchedule(t *testing.T) {
+	ok := ScheduledReport{Frequency: ReportWeekly, Weekday: nulls.NewInt(1), TimeOfDay: "09:30", Timezone: "Europe/Vienna"}
+	if field, err := ok.ValidateSchedule(); err != nil {
+		t.Errorf("valid schedule: %s %v", field, err)
+	}
+	for field, s := range map[string]ScheduledReport{
+		"frequency":    {Frequency: "hourly", TimeOfDay: "09:30", Timezone: "UTC"},
+		"weekday":      {Frequency: ReportDaily, Weekday: nulls.NewInt(1), TimeOfDay: "09:30", Timezone: "UTC"},
+		"day_of_month": {Frequency: ReportMonthly, DayOfMonth: nulls.NewInt(32), TimeOfDay: "09:30", Timezone: "UTC"},
+		"time_of_day":  {Frequency: ReportDaily, TimeOfDay: "24:00", Timezone: "UTC"},
+		"timezone":     {Frequency: ReportDaily, TimeOfDay: "09:30", Timezone: "Mars/Olympus"},
+	} {
+		if got, _ := s.ValidateSchedule(); got != field {
+			t.Errorf("%s: got %q", field, got)
+		}
+	}
+}
+
+func Test_ReportConfig_ValidateFor(t *testing.T) {
+	if err := (ReportConfig{GroupBy: "tag"}).ValidateFor(false); err != nil {
+		t.Errorf("tag: %v", err)
+	}
+	if err := (ReportConfig{GroupBy: "member"}).ValidateFor(true); err != nil {
+		t.Errorf("team member: %v", err)
+	}
+	if err := (ReportConfig{GroupBy: "member"}).ValidateFor(false); err == nil {
+		t.Error("personal member: no error")
+	}
+	if err := (ReportConfig{GroupBy: "planet"}).ValidateFor(true); err == nil {
+		t.Error("planet: no error")
+	}
+	if err := (ReportConfig{Locale: "de-DE"}).ValidateFor(false); err != nil {
+		t.Errorf("de-DE: %v", err)
+	}
+	if err := (ReportConfig{Locale: "de"}).ValidateFor(false); err == nil {
+		t.Error("de: no error")
+	}
+}
+
+func Test_ScheduledReport_NextRun(t *testing.T) {
+	vienna, err := time.LoadLocation("Europe/Vienna")
+	if err != nil {
+		t.Skip("no tzdata")
+	}
+	at := func(s string) time.Time {
+		v, err := time.ParseInLocation("2006-01-02 15:04", s, vienna)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	base := ScheduledReport{TimeOfDay: "09:00", Timezone: "Europe/Vienna"}
+	cases := []struct {
+		name  string
+		setup func(*ScheduledReport)
+		after string
+		want  string
+	}{
+		{"daily later today", func(s *ScheduledReport) { s.Frequency = ReportDaily }, "2025-03-10 08:00", "2025-03-10 09:00"},
+		{"daily at the time", func(s *ScheduledReport) { s.Frequency = ReportDaily }, "2025-03-10 09:00", "2025-03-11 09:00"},
+		{"weekly next monday", func(s *ScheduledReport) { s.Frequency, s.Weekday = ReportWeekly, nulls.NewInt(1) }, "2025-03-11 10:00", "2025-03-17 09:00"},
+		{"weekly same day later", func(s *ScheduledReport) { s.Frequency, s.Weekday = ReportWeekly, nulls.NewInt(1) }, "2025-03-10 08:59", "2025-03-10 09:00"},
+		{"month end clamps", func(s *ScheduledReport) { s.Frequency, s.DayOfMonth = ReportMonthly, nulls.NewInt(31) }, "2025-01-31 10:00", "2025-02-28 09:00"},
+		{"leap february", func(s *ScheduledReport) { s.Frequency, s.DayOfMonth = ReportMonthly, nulls.NewInt(30) }, "2024-01-30 10:00", "2024-02-29 09:00"},
+		{"across the year", func(s *ScheduledReport) { s.Frequency, s.DayOfMonth = ReportMonthly, nulls.NewInt(1) }, "2025-12-01 09:00", "2026-01-01 09:00"},
+		{"across DST", func(s *ScheduledReport) { s.Frequency = ReportDaily }, "2025-03-29 10:00", "2025-03-30 09:00"},
+	}
+	for _, c := range cases {
+		s := base
+		c.setup(&s)
+		if got := s.NextRun(at(c.after)); !got.Equal(at(c.want)) {
+			t.Errorf("%s: got %s, want %s", c.name, got.In(vienna), c.want)
+		}
+	}
+}
+
+func Test_ScheduledReport_Period(t *testing.T) {
+	vienna, err := time.LoadLocation("Europe/Vienna")
+	if err != nil {
+		t.Skip("no tzdata")
+	}
+	day := func(s string) time.Time {
+		v, err := time.ParseInLocation("2006-01-02", s, vienna)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	cases := []struct {
+		frequency, at, from, to string
+	}{
+		{ReportDaily, "2025-03-10", "2025-03-09", "2025-03-10"},
+		{ReportWeekly, "2025-03-10", "2025-03-03", "2025-03-10"},
+		{ReportMonthly, "2025-03-01", "2025-02-01", "2025-03-01"},
+		{ReportMonthly, "2026-01-15", "2025-12-01", "2026-01-01"},
+	}
+	for _, c := range cases {
+		s := ScheduledReport{Frequency: c.frequency, Timezone: "Europe/Vienna"}
+		from, to := s.Period(day(c.at).Add(9 * time.Hour))
+		if !from.Equal(day(c.from)) || !to.Equal(day(c.to)) {
+			t.Errorf("%s at %s: got [%s, %s), want [%s, %s)", c.frequency, c.at, from, to, c.from, c.to)
+		}
+	}
+
+	// A period preset wins over the frequency's period
+	s := ScheduledReport{Frequency: ReportDaily, Timezone: "Europe/Vienna", Config: ReportConfig{Period: PeriodLastWeek, WeekStart: "sunday"}}
+	from, to := s.Period(day("2025-03-12").Add(9 * time.Hour))
+	if !from.Equal(day("2025-03-02")) || !to.Equal(day("2025-03-09")) {
+		t.Errorf("last_week: got [%s, %s)", from, to)
+	}
+}
+
+func Test_ReportRetryBackoff(t *testing.T) {
+	for failures, want := range map[int]time.Duration{0: 5 * time.Minute, 1: 5 * time.Minute, 3: 20 * time.Minute} {
+		if got := ReportRetryBackoff(failures); got != want {
+			t.Errorf("ReportRetryBackoff(%d) = %s, want %s", failures, got, want)
+		}
+	}
+}
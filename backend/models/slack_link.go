@@ -0,0 +1,64 @@
+/**
+ * SlackLink Model - Slack Identity Linking
+ *
+ * This package defines the models connecting a Slack user to a TimeTrac
+ * user for the /track slash command: the link itself and the short-lived
+ * code a user pastes into Slack to create it.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-21
+ */
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+/**
+ * SlackLinkCodeTTL is how long a linking code can be redeemed
+ */
+const SlackLinkCodeTTL = 10 * time.Minute
+
+/**
+ * SlackLink maps a Slack user in a workspace to a TimeTrac user
+ *
+ * Database Fields:
+ * - id: Primary key (UUID)
+ * - user_id: Linked TimeTrac user
+ * - slack_team_id, slack_user_id: Slack identity (unique together)
+ * - created_at, updated_at: Timestamps
+ */
+type SlackLink struct {
+	ID          uuid.UUID `db:"id" json:"id"`                       // Unique link identifier
+	UserID      uuid.UUID `db:"user_id" json:"-"`                   // Linked user
+	SlackTeamID string    `db:"slack_team_id" json:"slack_team_id"` // Slack workspace ID
+	SlackUserID string    `db:"slack_user_id" json:"slack_user_id"` // Slack user ID
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`       // Creation timestamp
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`       // Last modification timestamp
+}
+
+/**
+ * TableName returns the database table name for the SlackLink model
+ */
+func (s SlackLink) TableName() string { return "slack_links" }
+
+/**
+ * SlackLinkCode is a one-time code proving TimeTrac account ownership
+ * from within Slack
+ */
+type SlackLinkCode struct {
+	ID        uuid.UUID `db:"id" json:"-"`                  // Unique code record identifier
+	Code      string    `db:"code" json:"code"`             // Code pasted into Slack (unique)
+	UserID    uuid.UUID `db:"user_id" json:"-"`             // User the code links to
+	ExpiresAt time.Time `db:"expires_at" json:"expires_at"` // Redemption deadline
+	CreatedAt time.Time `db:"created_at" json:"created_at"` // Creation timestamp
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"` // Last modification timestamp
+}
+
+/**
+ * TableName returns the database table name for the SlackLinkCode model
+ */
+func (s SlackLinkCode) TableName() string { return "slack_link_codes" }
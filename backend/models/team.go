@@ -12,8 +12,12 @@
 package models
 
 import (
+	"fmt"
+	"strings"
 	"time"
+	"unicode/utf8"
 
+	"github.com/gobuffalo/nulls"
 	"github.com/gofrs/uuid"
 )
 
@@ -31,25 +35,72 @@ import (
  * - name: Team name
  * - description: Team description (optional)
  * - owner_id: Foreign key to users table (team owner)
- * - settings: JSON settings for team preferences
+ * - settings: JSON settings for team preferences (see TeamSettings)
+ * - avatar_key, avatar_thumb_key: Storage keys of the avatar (512px) and
+ *   its thumbnail (NULL = no avatar)
+ * - avatar_url: API path serving the avatar (NULL = no avatar)
+ * - archived_at: When the team was archived (NULL = active)
  * - created_at: Team creation timestamp
  * - updated_at: Last modification timestamp
  *
  * JSON Serialization:
- * - All fields are included in API responses
+ * - All fields but the avatar storage keys are included in API responses
  * - Settings field contains team-specific configuration
  */
 type Team struct {
-	ID          uuid.UUID `db:"id" json:"id"`                   // Unique team identifier
-	Name        string    `db:"name" json:"name"`               // Team name
-	Description string    `db:"description" json:"description"` // Team description
-	OwnerID     uuid.UUID `db:"owner_id" json:"owner_id"`       // Team owner user ID
-	Settings    string    `db:"settings" json:"settings"`       // JSON settings
-	CreatedAt   time.Time `db:"created_at" json:"created_at"`   // Team creation timestamp
-	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`   // Last modification timestamp
+	ID             uuid.UUID    `db:"id" json:"id"`                   // Unique team identifier
+	Name           string       `db:"name" json:"name"`               // Team name
+	Description    string       `db:"description" json:"description"` // Team description
+	OwnerID        uuid.UUID    `db:"owner_id" json:"owner_id"`       // Team owner user ID
+	Settings       string       `db:"settings" json:"settings"`       // JSON settings
+	AvatarKey      nulls.String `db:"avatar_key" json:"-"`            // Avatar storage key
+	AvatarThumbKey nulls.String `db:"avatar_thumb_key" json:"-"`      // Thumbnail storage key
+	AvatarURL      nulls.String `db:"avatar_url" json:"avatar_url"`   // Avatar API path
+	ArchivedAt     nulls.Time   `db:"archived_at" json:"archived_at"` // When archived, null while active
+	CreatedAt      time.Time    `db:"created_at" json:"created_at"`   // Team creation timestamp
+	UpdatedAt      time.Time    `db:"updated_at" json:"updated_at"`   // Last modification timestamp
 }
 
 /**
  * TableName returns the database table name for the Team model
  */
 func (t Team) TableName() string { return "teams" }
+
+// MaxInvitationExpiryDays bounds the invitation_expiry_days setting.
+const MaxInvitationExpiryDays = 90
+
+/**
+ * ParsedSettings returns the team's typed settings (see
+ * ParseTeamSettings)
+ */
+func (t Team) ParsedSettings() TeamSettings {
+	return ParseTeamSettings(t.Settings)
+}
+
+/**
+ * InvitationTTL returns how long the team's invitations stay valid
+ */
+func (t Team) InvitationTTL() time.Duration {
+	return t.ParsedSettings().InvitationTTL()
+}
+
+// Team name length bounds, in characters.
+const (
+	MinTeamNameLength = 3
+	MaxTeamNameLength = 255
+)
+
+/**
+ * NormalizeTeamName trims a team name and checks its length
+ *
+ * @param name - Client-supplied name
+ * @return string - Trimmed name
+ * @return error - Length violation
+ */
+func NormalizeTeamName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if n := utf8.RuneCountInString(name); n < MinTeamNameLength || n > MaxTeamNameLength {
+		return "", fmt.Errorf("name must be %d to %d characters", MinTeamNameLength, MaxTeamNameLength)
+	}
+	return name, nil
+}
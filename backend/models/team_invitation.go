@@ -0,0 +1,65 @@
+/**
+ * TeamInvitation Model - Email Invitations for New Users
+ *
+ * This package defines the TeamInvitation model. Inviting an address
+ * that has no account yet creates one of these instead of a pending
+ * team_members row; the invitee receives an opaque token by email and
+ * redeems it after registering or signing in. Only the token's SHA-256
+ * hash (see HashRefreshToken) is stored.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-21
+ */
+package models
+
+import (
+	"time"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/gofrs/uuid"
+)
+
+// TeamInvitationTTL is how long an invitation stays valid unless the
+// team's settings say otherwise (see Team.InvitationTTL).
+const TeamInvitationTTL = 14 * 24 * time.Hour
+
+/**
+ * TeamInvitation is one emailed invitation to join a team
+ *
+ * Database Fields:
+ * - id: Primary key (UUID)
+ * - team_id: Team the invitation is for
+ * - email: Normalized address the invitation was sent to
+ * - role: Role granted on acceptance
+ * - token_hash: Hex SHA-256 of the emailed token
+ * - invited_by: Inviting user (NULL once their account is deleted)
+ * - expires_at: Time after which the token is rejected
+ * - accepted_at, accepted_by: When and by whom it was redeemed
+ * - created_at, updated_at: Timestamps
+ */
+type TeamInvitation struct {
+	ID         uuid.UUID      `db:"id" json:"id"`                   // Unique invitation identifier
+	TeamID     uuid.UUID      `db:"team_id" json:"team_id"`         // Team reference
+	Email      string         `db:"email" json:"email"`             // Invited address
+	Role       TeamMemberRole `db:"role" json:"role"`               // Role granted on acceptance
+	TokenHash  string         `db:"token_hash" json:"-"`            // SHA-256 of the token
+	InvitedBy  nulls.UUID     `db:"invited_by" json:"invited_by"`   // Who sent the invitation
+	ExpiresAt  time.Time      `db:"expires_at" json:"expires_at"`   // Expiration timestamp
+	AcceptedAt nulls.Time     `db:"accepted_at" json:"accepted_at"` // Redemption timestamp
+	AcceptedBy nulls.UUID     `db:"accepted_by" json:"accepted_by"` // Redeeming user
+	CreatedAt  time.Time      `db:"created_at" json:"created_at"`   // Creation timestamp
+	UpdatedAt  time.Time      `db:"updated_at" json:"updated_at"`   // Last modification timestamp
+}
+
+/**
+ * TableName returns the database table name for the TeamInvitation model
+ */
+func (t TeamInvitation) TableName() string { return "team_invitations" }
+
+/**
+ * Expired reports whether the invitation can no longer be redeemed
+ */
+func (t TeamInvitation) Expired(now time.Time) bool {
+	return !now.Before(t.ExpiresAt)
+}
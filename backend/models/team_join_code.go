@@ -0,0 +1,77 @@
+/**
+ * TeamJoinCode Model - Shareable Codes for Joining a Team
+ *
+ * This package defines the TeamJoinCode model. Instead of inviting each
+ * person by email, an owner or admin hands out a short code (e.g. on a
+ * workshop slide); anyone signed in who enters it becomes an active
+ * member with the code's role. A code may expire, may be limited to a
+ * number of uses, and can be revoked.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-22
+ */
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/gofrs/uuid"
+)
+
+/**
+ * TeamJoinCode is one shareable code granting membership of a team
+ *
+ * Database Fields:
+ * - id: Primary key (UUID)
+ * - team_id: Team the code joins
+ * - code: The code itself (unique, upper case)
+ * - role: Role given to people joining with it
+ * - expires_at: Time after which it is rejected (NULL: never)
+ * - max_uses: Number of joins allowed (NULL: unlimited)
+ * - uses_remaining: Joins left (NULL: unlimited)
+ * - created_by: Creating user (NULL once their account is deleted)
+ * - revoked_at: When the code was revoked
+ * - created_at, updated_at: Timestamps
+ */
+type TeamJoinCode struct {
+	ID            uuid.UUID      `db:"id" json:"id"`                         // Unique code record identifier
+	TeamID        uuid.UUID      `db:"team_id" json:"team_id"`               // Team reference
+	Code          string         `db:"code" json:"code"`                     // Code people enter
+	Role          TeamMemberRole `db:"role" json:"role"`                     // Role granted on joining
+	ExpiresAt     nulls.Time     `db:"expires_at" json:"expires_at"`         // Expiration timestamp
+	MaxUses       nulls.Int      `db:"max_uses" json:"max_uses"`             // Use limit
+	UsesRemaining nulls.Int      `db:"uses_remaining" json:"uses_remaining"` // Joins left
+	CreatedBy     nulls.UUID     `db:"created_by" json:"created_by"`         // Who created the code
+	RevokedAt     nulls.Time     `db:"revoked_at" json:"revoked_at"`         // Revocation timestamp
+	CreatedAt     time.Time      `db:"created_at" json:"created_at"`         // Creation timestamp
+	UpdatedAt     time.Time      `db:"updated_at" json:"updated_at"`         // Last modification timestamp
+}
+
+/**
+ * TableName returns the database table name for the TeamJoinCode model
+ */
+func (t TeamJoinCode) TableName() string { return "team_join_codes" }
+
+/**
+ * Active reports whether the code can still be used to join
+ */
+func (t TeamJoinCode) Active(now time.Time) bool {
+	if t.RevokedAt.Valid {
+		return false
+	}
+	if t.ExpiresAt.Valid && !now.Before(t.ExpiresAt.Time) {
+		return false
+	}
+	return !t.UsesRemaining.Valid || t.UsesRemaining.Int > 0
+}
+
+/**
+ * NormalizeJoinCode brings a code as typed by a person to its stored
+ * form: surrounding space and dashes removed, upper case
+ */
+func NormalizeJoinCode(code string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+}
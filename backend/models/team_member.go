@@ -12,8 +12,12 @@
 package models
 
 import (
+	"database/sql"
+	"errors"
 	"time"
 
+	"github.com/gobuffalo/nulls"
+	"github.com/gobuffalo/pop/v6"
 	"github.com/gofrs/uuid"
 )
 
@@ -22,12 +26,17 @@ import (
  */
 type TeamMemberRole string
 
+// InvitationDeclineCooldown is how long a user who declined cannot be
+// invited to the same team again without an explicit override.
+const InvitationDeclineCooldown = 7 * 24 * time.Hour
+
 const (
 	RoleOwner   TeamMemberRole = "owner"   // Team owner with full permissions
 	RoleAdmin   TeamMemberRole = "admin"   // Team admin with management permissions
 	RoleManager TeamMemberRole = "manager" // Project manager with limited admin permissions
 	RoleMember  TeamMemberRole = "member"  // Regular team member
 	RoleViewer  TeamMemberRole = "viewer"  // Read-only access
+	RoleGuest   TeamMemberRole = "guest"   // External collaborator, own entries only
 )
 
 /**
@@ -42,29 +51,50 @@ const (
  * - id: Primary key (UUID)
  * - team_id: Foreign key to teams table
  * - user_id: Foreign key to users table
- * - role: Member role (owner, admin, manager, member, viewer)
- * - status: Membership status (active, pending, suspended)
- * - invited_by: User ID who invited this member
+ * - role: Name of a role of the team (see TeamRole)
+ * - status: Membership status (active, pending, expired, declined, suspended)
+ * - invited_by: User ID who invited this member (NULL for the owner)
  * - joined_at: When the member joined the team
+ * - expires_at: When a pending invitation lapses (NULL otherwise)
+ * - declined_at: When the invitee declined (NULL otherwise)
+ * - hourly_rate_cents, currency: Current billing rate (NULL when unset);
+ *   history in member_rate_history
  * - created_at: Membership creation timestamp
  * - updated_at: Last modification timestamp
  *
  * JSON Serialization:
- * - All fields are included in API responses
+ * - All fields but the billing rate are included in API responses
  * - Role field uses string values for easy frontend handling
  */
 type TeamMember struct {
-	ID        uuid.UUID      `db:"id" json:"id"`                 // Unique membership identifier
-	TeamID    uuid.UUID      `db:"team_id" json:"team_id"`       // Team reference
-	UserID    uuid.UUID      `db:"user_id" json:"user_id"`       // User reference
-	Role      TeamMemberRole `db:"role" json:"role"`             // Member role
-	Status    string         `db:"status" json:"status"`         // Membership status
-	InvitedBy uuid.UUID      `db:"invited_by" json:"invited_by"` // Who invited this member
-	JoinedAt  *time.Time     `db:"joined_at" json:"joined_at"`   // When member joined
-	CreatedAt time.Time      `db:"created_at" json:"created_at"` // Membership creation timestamp
-	UpdatedAt time.Time      `db:"updated_at" json:"updated_at"` // Last modification timestamp
+	ID         uuid.UUID      `db:"id" json:"id"`                   // Unique membership identifier
+	TeamID     uuid.UUID      `db:"team_id" json:"team_id"`         // Team reference
+	UserID     uuid.UUID      `db:"user_id" json:"user_id"`         // User reference
+	Role       TeamMemberRole `db:"role" json:"role"`               // Member role
+	Status     string         `db:"status" json:"status"`           // Membership status
+	InvitedBy  nulls.UUID     `db:"invited_by" json:"invited_by"`   // Who invited this member (NULL for owners)
+	JoinedAt   *time.Time     `db:"joined_at" json:"joined_at"`     // When member joined
+	ExpiresAt  nulls.Time     `db:"expires_at" json:"expires_at"`   // When a pending invitation lapses
+	DeclinedAt nulls.Time     `db:"declined_at" json:"declined_at"` // When the invitee declined
+	CreatedAt  time.Time      `db:"created_at" json:"created_at"`   // Membership creation timestamp
+	UpdatedAt  time.Time      `db:"updated_at" json:"updated_at"`   // Last modification timestamp
+
+	// Billing rate, only shown through the rate endpoints (see MemberRate)
+	HourlyRateCents nulls.Int    `db:"hourly_rate_cents" json:"-"` // Current hourly rate in cents
+	Currency        nulls.String `db:"currency" json:"-"`          // ISO 4217 code of the rate
+
+	// Permissions of the role, loaded by AfterFind (nil = built-in defaults)
+	Permissions []string `db:"-" json:"permissions,omitempty"`
+
+	rolesLoaded bool // Permissions were loaded with a TeamMembers list
 }
 
+/**
+ * TeamMembers is a list of memberships; finding it loads the permissions
+ * of all their roles in one query (see LoadRolePermissions)
+ */
+type TeamMembers []TeamMember
+
 /**
  * TableName returns the database table name for the TeamMember model
  */
@@ -72,28 +102,155 @@ func (tm TeamMember) TableName() string { return "team_members" }
 
 /**
  * HasPermission checks if the team member has a specific permission
+ *
+ * Owners hold every permission, and they alone OwnerOnlyPermissions.
+ * Otherwise the member's role row (Permissions, loaded by AfterFind)
+ * decides; without one the built-in defaults apply.
  */
 func (tm TeamMember) HasPermission(permission string) bool {
-	switch tm.Role {
+	if tm.Role == RoleOwner {
+		return true
+	}
+	if OwnerOnlyPermission(permission) {
+		return false
+	}
+	if tm.Permissions != nil {
+		for _, p := range tm.Permissions {
+			if p == permission {
+				return true
+			}
+		}
+		return false
+	}
+	return builtInHasPermission(tm.Role, permission)
+}
+
+/**
+ * builtInHasPermission holds the default permissions of the built-in
+ * roles
+ */
+func builtInHasPermission(role TeamMemberRole, permission string) bool {
+	switch role {
 	case RoleOwner:
 		return true // Owner has all permissions
 	case RoleAdmin:
-		return permission != "delete_team" && permission != "transfer_ownership"
+		return permission != "delete_team" && permission != "transfer_ownership" && permission != "manage_roles"
 	case RoleManager:
 		return permission == "view_team" || permission == "manage_projects" ||
-			permission == "view_analytics" || permission == "invite_members"
+			permission == "view_analytics" || permission == "invite_members" ||
+			permission == "approve_timesheets"
 	case RoleMember:
 		return permission == "view_team" || permission == "view_analytics"
 	case RoleViewer:
 		return permission == "view_team"
+	case RoleGuest:
+		return false // Guests only log time on projects they are added to
 	default:
 		return false
 	}
 }
 
+/**
+ * AfterFind loads the permissions of the member's role, unless the list
+ * it was found in loaded them already
+ */
+func (tm *TeamMember) AfterFind(tx *pop.Connection) error {
+	if tm.rolesLoaded {
+		return nil
+	}
+	var role TeamRole
+	err := tx.Where("team_id = ? AND name = ?", tm.TeamID, tm.Role).First(&role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	tm.Permissions = []string(role.Permissions)
+	if tm.Permissions == nil {
+		tm.Permissions = []string{}
+	}
+	return nil
+}
+
+/**
+ * AfterFind loads the permissions of the members' roles; pop calls it
+ * before the AfterFind of each member, which then has nothing left to do
+ */
+func (ms *TeamMembers) AfterFind(tx *pop.Connection) error {
+	return LoadRolePermissions(tx, *ms)
+}
+
+/**
+ * LoadRolePermissions sets the Permissions of members from their roles'
+ * rows, reading the rows of all their teams in one query
+ */
+func LoadRolePermissions(tx *pop.Connection, members []TeamMember) error {
+	if len(members) == 0 {
+		return nil
+	}
+	seen := map[uuid.UUID]bool{}
+	teamIDs := []interface{}{}
+	for _, m := range members {
+		if !seen[m.TeamID] {
+			seen[m.TeamID] = true
+			teamIDs = append(teamIDs, m.TeamID)
+		}
+	}
+	roles := []TeamRole{}
+	if err := tx.Where("team_id in (?)", teamIDs...).All(&roles); err != nil {
+		return err
+	}
+	type roleKey struct {
+		team uuid.UUID
+		name TeamMemberRole
+	}
+	perms := make(map[roleKey][]string, len(roles))
+	for _, role := range roles {
+		p := []string(role.Permissions)
+		if p == nil {
+			p = []string{}
+		}
+		perms[roleKey{role.TeamID, role.Name}] = p
+	}
+	for i := range members {
+		members[i].Permissions = perms[roleKey{members[i].TeamID, members[i].Role}]
+		members[i].rolesLoaded = true
+	}
+	return nil
+}
+
 /**
  * IsActive checks if the team member is active
  */
 func (tm TeamMember) IsActive() bool {
 	return tm.Status == "active"
 }
+
+/**
+ * IsGuest checks if the member is an external collaborator: guests see
+ * only their own entries and log time only on projects they were added
+ * to
+ */
+func (tm TeamMember) IsGuest() bool {
+	return tm.Role == RoleGuest
+}
+
+/**
+ * InvitationExpired reports whether a pending invitation can no longer
+ * be accepted
+ */
+func (tm TeamMember) InvitationExpired(now time.Time) bool {
+	return tm.ExpiresAt.Valid && !now.Before(tm.ExpiresAt.Time)
+}
+
+/**
+ * DeclineCooldownUntil returns when a declined invitation may be sent
+ * again; the zero time for rows that were not declined
+ */
+func (tm TeamMember) DeclineCooldownUntil() time.Time {
+	if tm.Status != "declined" || !tm.DeclinedAt.Valid {
+		return time.Time{}
+	}
+	return tm.DeclinedAt.Time.Add(InvitationDeclineCooldown)
+}
@@ -0,0 +1,165 @@
+/**
+ * TeamRole Model - Per-Team Roles and Their Permissions
+ *
+ * Every team has the five built-in roles (owner, admin, manager, member,
+ * viewer) and may define its own. A role is a named set of permission
+ * strings; team_members.role holds the role name and references the
+ * team's row. Owners can change what the built-ins other than owner
+ * grant, but built-ins cannot be renamed or deleted.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-21
+ */
+package models
+
+import (
+	"errors"
+	"regexp"
+	"time"
+
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gofrs/uuid"
+	"github.com/lib/pq"
+)
+
+// TeamPermissions lists every permission a role can grant.
+var TeamPermissions = []string{
+	"view_team",          // See the team, its members and entries
+	"view_analytics",     // See everyone's time in team summaries
+	"invite_members",     // Invite people to the team
+	"manage_members",     // Change, suspend and remove members
+	"manage_rates",       // See and set members' billing rates and costs
+	"manage_projects",    // Create and change team projects
+	"approve_timesheets", // Approve or reject members' weekly timesheets
+	"manage_team",        // Change the team's name and settings
+	"manage_roles",       // Define roles and their permissions
+	"delete_team",        // Delete the team
+	"transfer_ownership", // Hand the team to another member
+}
+
+// OwnerOnlyPermissions are held by the owner alone: no other role can
+// grant them.
+var OwnerOnlyPermissions = []string{"delete_team", "transfer_ownership"}
+
+// BuiltInRoles are the roles every team starts with.
+var BuiltInRoles = []TeamMemberRole{RoleOwner, RoleAdmin, RoleManager, RoleMember, RoleViewer, RoleGuest}
+
+// roleName matches a custom role name: lowercase letters, digits and
+// underscores, starting with a letter.
+var roleName = regexp.MustCompile(`^[a-z][a-z0-9_]{1,49}$`)
+
+/**
+ * TeamRole represents a role defined for a team
+ *
+ * Database Fields:
+ * - id: Primary key (UUID)
+ * - team_id: Foreign key to teams table
+ * - name: Role name, unique within the team
+ * - permissions: Granted permission strings (see TeamPermissions)
+ * - built_in: One of BuiltInRoles (cannot be renamed or deleted)
+ * - created_at: Role creation timestamp
+ * - updated_at: Last modification timestamp
+ */
+type TeamRole struct {
+	ID          uuid.UUID      `db:"id" json:"id"`                   // Unique role identifier
+	TeamID      uuid.UUID      `db:"team_id" json:"team_id"`         // Team reference
+	Name        TeamMemberRole `db:"name" json:"name"`               // Role name
+	Permissions pq.StringArray `db:"permissions" json:"permissions"` // Granted permissions
+	BuiltIn     bool           `db:"built_in" json:"built_in"`       // Built-in role
+	CreatedAt   time.Time      `db:"created_at" json:"created_at"`   // Role creation timestamp
+	UpdatedAt   time.Time      `db:"updated_at" json:"updated_at"`   // Last modification timestamp
+}
+
+/**
+ * TableName returns the database table name for the TeamRole model
+ */
+func (r TeamRole) TableName() string { return "team_roles" }
+
+/**
+ * BuiltInPermissions returns what a built-in role grants by default
+ */
+func BuiltInPermissions(role TeamMemberRole) []string {
+	perms := []string{}
+	for _, p := range TeamPermissions {
+		if builtInHasPermission(role, p) {
+			perms = append(perms, p)
+		}
+	}
+	return perms
+}
+
+/**
+ * SeedTeamRoles creates the built-in roles of a new team
+ */
+func SeedTeamRoles(tx *pop.Connection, teamID uuid.UUID) error {
+	for _, name := range BuiltInRoles {
+		role := TeamRole{
+			ID:          uuid.Must(uuid.NewV4()),
+			TeamID:      teamID,
+			Name:        name,
+			Permissions: BuiltInPermissions(name),
+			BuiltIn:     true,
+		}
+		if err := tx.Create(&role); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/**
+ * OwnerOnlyPermission reports whether permission is one of
+ * OwnerOnlyPermissions
+ */
+func OwnerOnlyPermission(permission string) bool {
+	for _, p := range OwnerOnlyPermissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * NormalizeRolePermissions checks a client-supplied permission set and
+ * returns it deduplicated in TeamPermissions order; OwnerOnlyPermissions
+ * are refused
+ */
+func NormalizeRolePermissions(perms []string) ([]string, error) {
+	want := map[string]bool{}
+	for _, p := range perms {
+		want[p] = true
+	}
+	out := []string{}
+	for _, p := range TeamPermissions {
+		if want[p] {
+			out = append(out, p)
+			delete(want, p)
+		}
+	}
+	for p := range want {
+		return nil, errors.New("unknown permission " + p)
+	}
+	for _, p := range out {
+		if OwnerOnlyPermission(p) {
+			return nil, errors.New(p + " is reserved to the owner")
+		}
+	}
+	return out, nil
+}
+
+/**
+ * ValidCustomRoleName reports whether name can be used for a custom role
+ */
+func ValidCustomRoleName(name string) bool {
+	if !roleName.MatchString(name) {
+		return false
+	}
+	for _, r := range BuiltInRoles {
+		if TeamMemberRole(name) == r {
+			return false
+		}
+	}
+	return true
+}
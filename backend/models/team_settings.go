@@ -0,0 +1,279 @@
+/**
+ * TeamSettings - Typed Team Configuration
+ *
+ * This package defines TeamSettings, the typed form of the JSON stored in
+ * teams.settings. Reading never fails: missing or invalid values fall
+ * back to their defaults (ParseTeamSettings). Writes are checked with
+ * Validate. Keys this version does not know are kept as they are, so
+ * settings written by a newer release survive a round-trip.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-21
+ */
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// DefaultTeamCurrency is the currency assumed when a team sets none.
+const DefaultTeamCurrency = "USD"
+
+// MaxTeamSeats bounds the max_members setting.
+const MaxTeamSeats = 10000
+
+// Values of the invite_policy setting: who among the members holding
+// invite_members may invite.
+const (
+	InviteOwnerOnly     = "owner_only"      // Only the owner
+	InviteAdmins        = "admins"          // The owner and roles with manage_members
+	InviteManagersAndUp = "managers_and_up" // Every role with invite_members
+)
+
+// currencyCode matches an ISO 4217 alphabetic code.
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+/**
+ * TeamSettings holds a team's configuration
+ *
+ * JSON keys:
+ * - default_member_role: Role used when an invitation names none
+ * - invitation_expiry_days: Days an invitation stays valid (1 to 90)
+ * - require_note_on_entries: Team entries need a note
+ * - default_currency: ISO 4217 code for rates and reports
+ * - member_location_visibility: How entry locations appear to other
+ *   members (exact, approximate, hidden)
+ * - join_codes_enabled: People may join with a shareable code
+ * - max_members: Seats, i.e. active and suspended members allowed
+ *   (0 = unlimited); set by the team's plan, not by its members
+ * - invite_policy: Who may invite (owner_only, admins, managers_and_up)
+ */
+type TeamSettings struct {
+	DefaultMemberRole        TeamMemberRole
+	InvitationExpiryDays     int
+	RequireNoteOnEntries     bool
+	DefaultCurrency          string
+	MemberLocationVisibility string
+	JoinCodesEnabled         bool
+	MaxMembers               int
+	InvitePolicy             string
+
+	// extra holds unknown keys, written back unchanged
+	extra map[string]json.RawMessage
+}
+
+/**
+ * DefaultTeamSettings returns the settings of a team that set nothing
+ */
+func DefaultTeamSettings() TeamSettings {
+	return TeamSettings{
+		DefaultMemberRole:        RoleMember,
+		InvitationExpiryDays:     int(TeamInvitationTTL.Hours() / 24),
+		DefaultCurrency:          DefaultTeamCurrency,
+		MemberLocationVisibility: LocationApproximate,
+		JoinCodesEnabled:         true,
+		InvitePolicy:             InviteManagersAndUp,
+	}
+}
+
+/**
+ * ParseTeamSettings reads stored settings, replacing missing or invalid
+ * values with their defaults
+ *
+ * @param raw - Contents of teams.settings (may be empty)
+ * @return TeamSettings - Usable settings
+ */
+func ParseTeamSettings(raw string) TeamSettings {
+	s := DefaultTeamSettings()
+	if raw == "" {
+		return s
+	}
+	// Unmarshal keeps every value it could decode
+	_ = json.Unmarshal([]byte(raw), &s)
+
+	d := DefaultTeamSettings()
+	if s.checkDefaultMemberRole() != nil {
+		s.DefaultMemberRole = d.DefaultMemberRole
+	}
+	if s.checkInvitationExpiryDays() != nil {
+		s.InvitationExpiryDays = d.InvitationExpiryDays
+	}
+	if s.checkDefaultCurrency() != nil {
+		s.DefaultCurrency = d.DefaultCurrency
+	}
+	if s.checkMemberLocationVisibility() != nil {
+		s.MemberLocationVisibility = d.MemberLocationVisibility
+	}
+	if s.checkMaxMembers() != nil {
+		s.MaxMembers = d.MaxMembers
+	}
+	if s.checkInvitePolicy() != nil {
+		s.InvitePolicy = d.InvitePolicy
+	}
+	return s
+}
+
+/**
+ * InvitationTTL returns how long the team's invitations stay valid
+ */
+func (s TeamSettings) InvitationTTL() time.Duration {
+	return time.Duration(s.InvitationExpiryDays) * 24 * time.Hour
+}
+
+/**
+ * MayInvite reports whether the invite_policy lets member invite; the
+ * member must hold invite_members as well. Admins are the roles holding
+ * manage_members, built-in or not.
+ */
+func (s TeamSettings) MayInvite(member TeamMember) bool {
+	switch s.InvitePolicy {
+	case InviteOwnerOnly:
+		return member.Role == RoleOwner
+	case InviteAdmins:
+		return member.HasPermission("manage_members")
+	}
+	return true
+}
+
+/**
+ * Validate checks settings supplied by a client
+ *
+ * @return map[string]string - Message per invalid key, nil when valid
+ */
+func (s TeamSettings) Validate() map[string]string {
+	errs := map[string]string{}
+	for key, err := range map[string]error{
+		"default_member_role":        s.checkDefaultMemberRole(),
+		"invitation_expiry_days":     s.checkInvitationExpiryDays(),
+		"default_currency":           s.checkDefaultCurrency(),
+		"member_location_visibility": s.checkMemberLocationVisibility(),
+		"max_members":                s.checkMaxMembers(),
+		"invite_policy":              s.checkInvitePolicy(),
+	} {
+		if err != nil {
+			errs[key] = err.Error()
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// The check methods report why a value is invalid, nil when it is valid.
+
+// checkDefaultMemberRole checks the name only; whether the team has the
+// role is up to the caller.
+func (s TeamSettings) checkDefaultMemberRole() error {
+	switch s.DefaultMemberRole {
+	case RoleAdmin, RoleManager, RoleMember, RoleViewer:
+		return nil
+	}
+	if ValidCustomRoleName(string(s.DefaultMemberRole)) {
+		return nil
+	}
+	return errors.New("must be admin, manager, member, viewer or a custom role")
+}
+
+func (s TeamSettings) checkInvitationExpiryDays() error {
+	if s.InvitationExpiryDays < 1 || s.InvitationExpiryDays > MaxInvitationExpiryDays {
+		return fmt.Errorf("must be from 1 to %d", MaxInvitationExpiryDays)
+	}
+	return nil
+}
+
+func (s TeamSettings) checkDefaultCurrency() error {
+	if !currencyCode.MatchString(s.DefaultCurrency) {
+		return errors.New("must be a three-letter ISO 4217 code")
+	}
+	return nil
+}
+
+func (s TeamSettings) checkMemberLocationVisibility() error {
+	if !ValidLocationVisibility(s.MemberLocationVisibility) {
+		return errors.New("must be exact, approximate or hidden")
+	}
+	return nil
+}
+
+func (s TeamSettings) checkMaxMembers() error {
+	if s.MaxMembers < 0 || s.MaxMembers > MaxTeamSeats {
+		return fmt.Errorf("must be from 0 to %d", MaxTeamSeats)
+	}
+	return nil
+}
+
+func (s TeamSettings) checkInvitePolicy() error {
+	switch s.InvitePolicy {
+	case InviteOwnerOnly, InviteAdmins, InviteManagersAndUp:
+		return nil
+	}
+	return errors.New("must be owner_only, admins or managers_and_up")
+}
+
+/**
+ * teamSettingsFields maps the known JSON keys to their fields
+ */
+func (s *TeamSettings) teamSettingsFields() map[string]any {
+	return map[string]any{
+		"default_member_role":        &s.DefaultMemberRole,
+		"invitation_expiry_days":     &s.InvitationExpiryDays,
+		"require_note_on_entries":    &s.RequireNoteOnEntries,
+		"default_currency":           &s.DefaultCurrency,
+		"member_location_visibility": &s.MemberLocationVisibility,
+		"join_codes_enabled":         &s.JoinCodesEnabled,
+		"max_members":                &s.MaxMembers,
+		"invite_policy":              &s.InvitePolicy,
+	}
+}
+
+/**
+ * UnmarshalJSON applies the keys present in data on top of s, so it also
+ * merges a partial update into existing settings. Every decodable key is
+ * applied; the first type error is returned.
+ */
+func (s *TeamSettings) UnmarshalJSON(data []byte) error {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+	if obj == nil {
+		return errors.New("settings must be a JSON object")
+	}
+
+	fields := s.teamSettingsFields()
+	var first error
+	for key, v := range obj {
+		field, known := fields[key]
+		if !known {
+			if s.extra == nil {
+				s.extra = map[string]json.RawMessage{}
+			}
+			s.extra[key] = v
+			continue
+		}
+		if err := json.Unmarshal(v, field); err != nil && first == nil {
+			first = fmt.Errorf("%s: wrong type", key)
+		}
+	}
+	return first
+}
+
+/**
+ * MarshalJSON writes the known keys and any unknown ones read earlier
+ */
+func (s TeamSettings) MarshalJSON() ([]byte, error) {
+	obj := make(map[string]any, len(s.extra)+8)
+	for key, v := range s.extra {
+		obj[key] = v
+	}
+	for key, field := range s.teamSettingsFields() {
+		obj[key] = field
+	}
+	return json.Marshal(obj)
+}
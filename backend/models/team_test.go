@@ -0,0 +1,248 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gobuffalo/nulls"
+)
+
+func Test_Team_InvitationTTL(t *testing.T) {
+	cases := []struct {
+		settings string
+		want     time.Duration
+	}{
+		{"", TeamInvitationTTL},
+		{"{}", TeamInvitationTTL},
+		{"not json", TeamInvitationTTL},
+		{`{"invitation_expiry_days": 3}`, 3 * 24 * time.Hour},
+		{`{"invitation_expiry_days": 0}`, TeamInvitationTTL},
+		{`{"invitation_expiry_days": 365}`, TeamInvitationTTL},
+	}
+	for _, tc := range cases {
+		if got := (Team{Settings: tc.settings}).InvitationTTL(); got != tc.want {
+			t.Errorf("%q: got %s, want %s", tc.settings, got, tc.want)
+		}
+	}
+}
+
+func Test_NormalizeTeamName(t *testing.T) {
+	if got, err := NormalizeTeamName("  Core Team "); err != nil || got != "Core Team" {
+		t.Errorf("got %q, %v", got, err)
+	}
+	for _, name := range []string{"", "ab", "  ab  ", strings.Repeat("x", MaxTeamNameLength+1)} {
+		if _, err := NormalizeTeamName(name); err == nil {
+			t.Errorf("%q: accepted", name)
+		}
+	}
+	// Length counts characters, not bytes
+	if _, err := NormalizeTeamName("äöü"); err != nil {
+		t.Errorf("umlauts: %v", err)
+	}
+}
+
+func Test_ParseTeamSettings(t *testing.T) {
+	s := ParseTeamSettings(`{"default_member_role": "viewer", "default_currency": "eur", "invitation_expiry_days": "7", "theme": {"dark": true}}`)
+	if s.DefaultMemberRole != RoleViewer {
+		t.Errorf("role: got %q", s.DefaultMemberRole)
+	}
+	// Invalid values fall back to their defaults
+	if s.DefaultCurrency != DefaultTeamCurrency || s.InvitationExpiryDays != 14 || s.MemberLocationVisibility != LocationApproximate {
+		t.Errorf("defaults: got %+v", s)
+	}
+
+	// Unknown keys survive a round-trip
+	out, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back map[string]any
+	if err := json.Unmarshal(out, &back); err != nil {
+		t.Fatal(err)
+	}
+	if theme, ok := back["theme"].(map[string]any); !ok || theme["dark"] != true {
+		t.Errorf("theme lost: %s", out)
+	}
+	if back["default_member_role"] != "viewer" {
+		t.Errorf("role lost: %s", out)
+	}
+}
+
+func Test_TeamSettings_Validate(t *testing.T) {
+	if errs := DefaultTeamSettings().Validate(); errs != nil {
+		t.Errorf("defaults invalid: %v", errs)
+	}
+	s := DefaultTeamSettings()
+	if err := json.Unmarshal([]byte(`{"default_member_role": "owner", "default_currency": "EURO", "member_location_visibility": "public"}`), &s); err != nil {
+		t.Fatal(err)
+	}
+	errs := s.Validate()
+	for _, key := range []string{"default_member_role", "default_currency", "member_location_visibility"} {
+		if errs[key] == "" {
+			t.Errorf("%s: accepted", key)
+		}
+	}
+	if _, ok := errs["invitation_expiry_days"]; ok {
+		t.Errorf("untouched key reported: %v", errs)
+	}
+	s = DefaultTeamSettings()
+	s.DefaultMemberRole = "accountant"
+	if errs := s.Validate(); errs != nil {
+		t.Errorf("custom default role refused: %v", errs)
+	}
+
+	// Wrong types and non-objects are rejected
+	for _, bad := range []string{`null`, `[]`, `{"require_note_on_entries": "yes"}`} {
+		s := DefaultTeamSettings()
+		if err := json.Unmarshal([]byte(bad), &s); err == nil {
+			t.Errorf("%s: accepted", bad)
+		}
+	}
+}
+
+func Test_TeamSettings_MayInvite(t *testing.T) {
+	s := DefaultTeamSettings()
+	if !s.MayInvite(TeamMember{Role: RoleManager}) {
+		t.Error("managers_and_up: manager refused")
+	}
+	s.InvitePolicy = InviteAdmins
+	if s.MayInvite(TeamMember{Role: RoleManager}) || !s.MayInvite(TeamMember{Role: RoleAdmin}) {
+		t.Error("admins: wrong roles allowed")
+	}
+	// Custom roles count as admins by their permissions
+	if !s.MayInvite(TeamMember{Role: "lead", Permissions: []string{"invite_members", "manage_members"}}) {
+		t.Error("admins: custom role with manage_members refused")
+	}
+	s.InvitePolicy = InviteOwnerOnly
+	if s.MayInvite(TeamMember{Role: RoleAdmin}) || !s.MayInvite(TeamMember{Role: RoleOwner}) {
+		t.Error("owner_only: wrong roles allowed")
+	}
+	s.InvitePolicy = "everyone"
+	if s.Validate()["invite_policy"] == "" {
+		t.Error("unknown policy accepted")
+	}
+}
+
+func Test_TeamMember_HasPermission(t *testing.T) {
+	// Without a loaded role the built-in defaults apply
+	if !(TeamMember{Role: RoleManager}).HasPermission("invite_members") {
+		t.Error("manager cannot invite")
+	}
+	if (TeamMember{Role: RoleAdmin}).HasPermission("manage_roles") {
+		t.Error("admin can manage roles by default")
+	}
+
+	// A loaded role decides, except for owners
+	m := TeamMember{Role: RoleManager, Permissions: []string{"view_team"}}
+	if m.HasPermission("invite_members") || !m.HasPermission("view_team") {
+		t.Errorf("stored permissions ignored: %v", m.Permissions)
+	}
+	if !(TeamMember{Role: RoleOwner, Permissions: []string{}}).HasPermission("delete_team") {
+		t.Error("owner lost a permission")
+	}
+	if (TeamMember{Role: "auditor"}).HasPermission("view_team") {
+		t.Error("unknown role without permissions granted access")
+	}
+
+	// Owner-only permissions stay with the owner whatever a role stores
+	if (TeamMember{Role: "deputy", Permissions: []string{"delete_team", "transfer_ownership"}}).HasPermission("delete_team") {
+		t.Error("custom role can delete the team")
+	}
+}
+
+func Test_BuiltInPermissions(t *testing.T) {
+	if got := BuiltInPermissions(RoleOwner); len(got) != len(TeamPermissions) {
+		t.Errorf("owner = %v", got)
+	}
+	if got := BuiltInPermissions(RoleViewer); len(got) != 1 || got[0] != "view_team" {
+		t.Errorf("viewer = %v", got)
+	}
+}
+
+func Test_NormalizeRolePermissions(t *testing.T) {
+	got, err := NormalizeRolePermissions([]string{"manage_projects", "view_team", "view_team"})
+	if err != nil || len(got) != 2 || got[0] != "view_team" || got[1] != "manage_projects" {
+		t.Errorf("got %v, %v", got, err)
+	}
+	if _, err := NormalizeRolePermissions([]string{"export_everything"}); err == nil {
+		t.Error("unknown permission accepted")
+	}
+	if _, err := NormalizeRolePermissions([]string{"view_team", "transfer_ownership"}); err == nil {
+		t.Error("owner-only permission accepted")
+	}
+}
+
+func Test_ValidCustomRoleName(t *testing.T) {
+	for name, want := range map[string]bool{
+		"accountant": true, "lead_2": true, "admin": false, "Owner": false, "x": false, "2nd": false, "team lead": false,
+	} {
+		if got := ValidCustomRoleName(name); got != want {
+			t.Errorf("%q: got %v", name, got)
+		}
+	}
+}
+
+func Test_ParseTimesheetWeek(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("tzdata unavailable")
+	}
+	start, end, err := ParseTimesheetWeek("2025-10-13", loc)
+	if err != nil || start.Hour() != 0 || end.Sub(start) != 7*24*time.Hour {
+		t.Errorf("plain week: %v - %v, %v", start, end, err)
+	}
+	// Clocks go back on Sunday 26 October, so that week is an hour longer
+	start, end, err = ParseTimesheetWeek("2025-10-20", loc)
+	if err != nil || end.Weekday() != time.Monday || end.Sub(start) != 7*24*time.Hour+time.Hour {
+		t.Errorf("DST week: %v - %v, %v", start, end, err)
+	}
+	for _, bad := range []string{"2025-10-21", "13.10.2025", ""} {
+		if _, _, err := ParseTimesheetWeek(bad, loc); err == nil {
+			t.Errorf("%q accepted", bad)
+		}
+	}
+}
+
+func Test_TeamJoinCode_Active(t *testing.T) {
+	now := time.Now()
+	for _, tc := range []struct {
+		name string
+		code TeamJoinCode
+		want bool
+	}{
+		{"unlimited", TeamJoinCode{}, true},
+		{"uses left", TeamJoinCode{UsesRemaining: nulls.NewInt(1)}, true},
+		{"used up", TeamJoinCode{UsesRemaining: nulls.NewInt(0)}, false},
+		{"expired", TeamJoinCode{ExpiresAt: nulls.NewTime(now)}, false},
+		{"revoked", TeamJoinCode{RevokedAt: nulls.NewTime(now.Add(-time.Hour))}, false},
+	} {
+		if got := tc.code.Active(now); got != tc.want {
+			t.Errorf("%s: got %v", tc.name, got)
+		}
+	}
+	if got := NormalizeJoinCode(" abcd-efgh "); got != "ABCDEFGH" {
+		t.Errorf("normalize: got %q", got)
+	}
+}
+
+func Test_BudgetThresholdCrossed(t *testing.T) {
+	for _, tc := range []struct {
+		alerted      int
+		used, budget float64
+		want         int
+	}{
+		{0, 7.9, 10, 0},
+		{0, 8, 10, 80},
+		{0, 12, 10, 100}, // Past both at once alerts the highest
+		{80, 9, 10, 0},
+		{80, 10, 10, 100},
+		{100, 20, 10, 0},
+		{0, 5, 0, 0}, // No budget
+	} {
+		if got := BudgetThresholdCrossed(tc.alerted, tc.used, tc.budget); got != tc.want {
+			t.Errorf("alerted %d, %v of %v: got %d, want %d", tc.alerted, tc.used, tc.budget, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+/**
+ * Timesheet Model - Weekly Submission and Approval of Team Time
+ *
+ * A timesheet covers one member's entries for one team over one ISO week
+ * (Monday to Monday in the member's timezone). The member submits it,
+ * and someone with approve_timesheets approves or rejects it. While a
+ * week is approved, the member's entries for that team in it cannot be
+ * changed or deleted until the approval is revoked.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-21
+ */
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/gofrs/uuid"
+)
+
+// Timesheet statuses. A week nobody has submitted has no row at all.
+const (
+	TimesheetDraft     = "draft"     // Approval revoked; can be resubmitted
+	TimesheetSubmitted = "submitted" // Waiting for review
+	TimesheetApproved  = "approved"  // Entries locked
+	TimesheetRejected  = "rejected"  // Sent back with a comment
+)
+
+/**
+ * Timesheet represents a member's week of team entries under review
+ *
+ * Database Fields:
+ * - id: Primary key (UUID)
+ * - team_id: Foreign key to teams table
+ * - user_id: Member whose week it is
+ * - week_start: Monday of the week, as a calendar date
+ * - starts_at, ends_at: The week as UTC instants, [starts_at, ends_at),
+ *   fixed at submission from the member's timezone
+ * - status: One of the Timesheet* constants
+ * - submitted_at: Last submission
+ * - reviewed_by: Who last approved, rejected or revoked (NULL once deleted)
+ * - reviewed_at: When that happened
+ * - comment: Reason given with a rejection
+ * - created_at: Row creation timestamp
+ * - updated_at: Last modification timestamp
+ */
+type Timesheet struct {
+	ID          uuid.UUID    `db:"id" json:"id"`                     // Unique timesheet identifier
+	TeamID      uuid.UUID    `db:"team_id" json:"team_id"`           // Team reference
+	UserID      uuid.UUID    `db:"user_id" json:"user_id"`           // Member reference
+	WeekStart   time.Time    `db:"week_start" json:"-"`              // Monday of the week
+	StartsAt    time.Time    `db:"starts_at" json:"starts_at"`       // Week start instant
+	EndsAt      time.Time    `db:"ends_at" json:"ends_at"`           // Week end instant (exclusive)
+	Status      string       `db:"status" json:"status"`             // Review status
+	SubmittedAt nulls.Time   `db:"submitted_at" json:"submitted_at"` // Last submission
+	ReviewedBy  nulls.UUID   `db:"reviewed_by" json:"reviewed_by"`   // Last reviewer
+	ReviewedAt  nulls.Time   `db:"reviewed_at" json:"reviewed_at"`   // Last review
+	Comment     nulls.String `db:"comment" json:"comment"`           // Rejection reason
+	CreatedAt   time.Time    `db:"created_at" json:"created_at"`     // Row creation timestamp
+	UpdatedAt   time.Time    `db:"updated_at" json:"updated_at"`     // Last modification timestamp
+}
+
+/**
+ * TableName returns the database table name for the Timesheet model
+ */
+func (ts Timesheet) TableName() string { return "timesheets" }
+
+/**
+ * Week returns week_start as YYYY-MM-DD
+ */
+func (ts Timesheet) Week() string { return ts.WeekStart.Format(time.DateOnly) }
+
+/**
+ * ParseTimesheetWeek reads a week given as the date of its Monday and
+ * returns its bounds in loc
+ *
+ * @param week - Date in YYYY-MM-DD form
+ * @param loc - Member's timezone
+ * @return time.Time - Monday 00:00 in loc
+ * @return time.Time - The following Monday 00:00 in loc
+ * @return error - Not a date, or not a Monday
+ */
+func ParseTimesheetWeek(week string, loc *time.Location) (time.Time, time.Time, error) {
+	day, err := time.ParseInLocation(time.DateOnly, week, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New("week must be a date in YYYY-MM-DD form")
+	}
+	if day.Weekday() != time.Monday {
+		return time.Time{}, time.Time{}, errors.New("week must be the date of a Monday")
+	}
+	return day, day.AddDate(0, 0, 7), nil
+}
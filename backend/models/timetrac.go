@@ -12,13 +12,42 @@
 package models
 
 import (
+	"math"
+	"strconv"
+	"strings"
 	"time"
+	"unicode"
 
+	"github.com/gobuffalo/envy"
 	"github.com/gobuffalo/nulls"
+	"github.com/gobuffalo/pop/v6"
+	"github.com/gobuffalo/validate/v3"
 	"github.com/gofrs/uuid"
 	"github.com/lib/pq"
 )
 
+/**
+ * Time horizon for entry start times, relative to now
+ *
+ * Configured with TRACK_PAST_HORIZON_DAYS (default 3650) and
+ * TRACK_FUTURE_HORIZON_HOURS (default 24).
+ */
+var (
+	TrackPastHorizon   = envDuration("TRACK_PAST_HORIZON_DAYS", 3650, 24*time.Hour)
+	TrackFutureHorizon = envDuration("TRACK_FUTURE_HORIZON_HOURS", 24, time.Hour)
+)
+
+/**
+ * envDuration reads a positive integer count of unit from the environment
+ */
+func envDuration(key string, def int, unit time.Duration) time.Duration {
+	n, err := strconv.Atoi(envy.Get(key, strconv.Itoa(def)))
+	if err != nil || n <= 0 {
+		n = def
+	}
+	return time.Duration(n) * unit
+}
+
 /**
  * TimeTrac represents a single time tracking entry
  *
@@ -42,6 +71,12 @@ import (
  * - photo_data: Base64 encoded image data (nullable)
  * - start_at: Time tracking start timestamp
  * - end_at: Time tracking end timestamp (NULL = running)
+ * - stopped_reason: Set when the system stopped the entry, e.g. "auto_stopped"
+ * - external_ref: Issue reference such as ACME-123 or org/repo#45 (nullable)
+ * - client: JSONB device metadata (platform, app_version, device_name)
+ * - team_id: Team the entry was recorded for (NULL = personal)
+ * - billable: Time can be billed to a client (team summaries report it)
+ * - deleted_at: Soft-delete timestamp (NULL = active, otherwise in trash)
  * - created_at: Entry creation timestamp
  * - updated_at: Last modification timestamp
  *
@@ -56,6 +91,7 @@ import (
  * - User ID is hidden from JSON responses for security
  * - All other fields are included in API responses
  * - Nullable fields use nulls package for proper JSON handling
+ * - paused, paused_seconds and duration_seconds are computed, not stored
  */
 type TimeTrac struct {
 	ID           uuid.UUID      `db:"id"         json:"id"`               // Unique entry identifier
@@ -72,6 +108,31 @@ type TimeTrac struct {
 	EndAt        nulls.Time     `db:"end_at"     json:"end_at"`           // Time tracking end (NULL = running)
 	CreatedAt    time.Time      `db:"created_at" json:"created_at"`       // Entry creation timestamp
 	UpdatedAt    time.Time      `db:"updated_at" json:"updated_at"`       // Last modification timestamp
+
+	// System metadata
+	StopReason nulls.String `db:"stopped_reason" json:"stopped_reason"` // Why the system stopped the entry (optional)
+	DeletedAt  nulls.Time   `db:"deleted_at" json:"deleted_at"`         // When the entry was moved to trash (NULL = active)
+
+	// Photo attachment in the configured storage backend
+	PhotoKey nulls.String `db:"photo_key" json:"-"`         // Storage key, e.g. tracks/{id}/{uuid}.jpg
+	PhotoURL nulls.String `db:"photo_url" json:"photo_url"` // API path serving the photo
+
+	// Ticket the entry belongs to, e.g. "ACME-123" or "org/repo#45"
+	ExternalRef nulls.String `db:"external_ref" json:"external_ref"`
+
+	// Device that created the entry; owner-only and omitted from lists
+	Client *TrackClient `db:"client" json:"client,omitempty"`
+
+	// Team the entry was recorded for; personal entries have none
+	TeamID nulls.UUID `db:"team_id" json:"team_id"`
+
+	// Time that can be billed to a client
+	Billable bool `db:"billable" json:"billable"`
+
+	// Computed fields (not persisted), filled by ApplyPauses
+	Paused          bool  `db:"-" json:"paused"`           // Entry has an open pause
+	PausedSeconds   int64 `db:"-" json:"paused_seconds"`   // Total pause time
+	DurationSeconds int64 `db:"-" json:"duration_seconds"` // Tracked time minus pauses
 }
 
 /**
@@ -83,3 +144,184 @@ type TimeTrac struct {
  * @return string - The database table name
  */
 func (t TimeTrac) TableName() string { return "timetrac" }
+
+/**
+ * Validate is pop's validation hook, run by ValidateAndCreate,
+ * ValidateAndUpdate and ValidateAndSave
+ *
+ * @param tx - Database connection (unused)
+ * @return *validate.Errors - Field errors keyed by JSON field name
+ * @return error - Always nil
+ */
+func (t *TimeTrac) Validate(tx *pop.Connection) (*validate.Errors, error) {
+	return t.ValidateAt(time.Now()), nil
+}
+
+/**
+ * ValidateAt checks the entry's invariants relative to now:
+ * - user_id is set
+ * - start_at is set and within TrackPastHorizon / TrackFutureHorizon
+ * - end_at, when set, is after start_at
+ *
+ * @param now - Reference time for the horizon
+ * @return *validate.Errors - Field errors keyed by JSON field name
+ */
+func (t *TimeTrac) ValidateAt(now time.Time) *validate.Errors {
+	errs := validate.NewErrors()
+	if t.UserID == uuid.Nil {
+		errs.Add("user_id", "is required")
+	}
+	switch {
+	case t.StartAt.IsZero():
+		errs.Add("start_at", "is required")
+	case t.StartAt.Before(now.Add(-TrackPastHorizon)):
+		errs.Add("start_at", "is too far in the past")
+	case t.StartAt.After(now.Add(TrackFutureHorizon)):
+		errs.Add("start_at", "is too far in the future")
+	}
+	if t.EndAt.Valid && !t.StartAt.IsZero() && !t.EndAt.Time.After(t.StartAt) {
+		errs.Add("end_at", "must be after start_at")
+	}
+	return errs
+}
+
+/**
+ * ApplyPauses fills the computed duration fields from the entry's pauses
+ *
+ * Running entries and open pauses are measured up to `now`.
+ *
+ * @param pauses - Pauses belonging to this entry
+ * @param now - Reference time for running entries and open pauses
+ */
+func (t *TimeTrac) ApplyPauses(pauses TrackPauses, now time.Time) {
+	end := now
+	if t.EndAt.Valid {
+		end = t.EndAt.Time
+	}
+	paused := pauses.Total(end)
+
+	t.Paused = false
+	for _, p := range pauses {
+		if !p.ResumedAt.Valid {
+			t.Paused = true
+		}
+	}
+	t.PausedSeconds = int64(paused / time.Second)
+	t.DurationSeconds = int64((end.Sub(t.StartAt) - paused) / time.Second)
+	if t.DurationSeconds < 0 {
+		t.DurationSeconds = 0
+	}
+}
+
+/**
+ * HasAttachments reports whether the entry carries a note or a photo.
+ * Such entries are never discarded automatically.
+ */
+func (t TimeTrac) HasAttachments() bool {
+	return strings.TrimSpace(t.Note) != "" ||
+		(t.PhotoData.Valid && t.PhotoData.String != "") ||
+		(t.PhotoKey.Valid && t.PhotoKey.String != "")
+}
+
+/**
+ * ForViewer returns the entry as it may be shown to the given viewer
+ *
+ * Every response that can contain another user's entries must pass them
+ * through ForViewer. The owner always sees exact data; everyone else never
+ * sees the client metadata and sees the location according to the
+ * owner's location_visibility:
+ * - exact: unchanged
+ * - approximate: coordinates rounded to 2 decimals (about 1 km), street
+ *   removed from the address
+ * - hidden (or unknown): coordinates and address cleared
+ *
+ * @param viewerID - User the response is rendered for
+ * @param visibility - Owner's location_visibility preference
+ * @return TimeTrac - Copy safe to serialize for the viewer
+ */
+func (t TimeTrac) ForViewer(viewerID uuid.UUID, visibility string) TimeTrac {
+	if viewerID == t.UserID {
+		return t
+	}
+	t.Client = nil
+	switch visibility {
+	case LocationExact:
+	case LocationApproximate:
+		if t.LocationLat.Valid {
+			t.LocationLat = nulls.NewFloat64(math.Round(t.LocationLat.Float64*100) / 100)
+		}
+		if t.LocationLng.Valid {
+			t.LocationLng = nulls.NewFloat64(math.Round(t.LocationLng.Float64*100) / 100)
+		}
+		if t.LocationAddr.Valid {
+			addr := stripStreet(t.LocationAddr.String)
+			t.LocationAddr = nulls.NewString(addr)
+			if addr == "" {
+				t.LocationAddr = nulls.String{}
+			}
+		}
+	default:
+		t.LocationLat = nulls.Float64{}
+		t.LocationLng = nulls.Float64{}
+		t.LocationAddr = nulls.String{}
+	}
+	return t
+}
+
+/**
+ * stripStreet removes the street part from a comma-separated address
+ *
+ * The first component is taken to be the street ("Stephansplatz 1, ...").
+ * When it is only a house number, as in Nominatim's display names
+ * ("1, Stephansplatz, ..."), the following street name is removed too.
+ */
+func stripStreet(addr string) string {
+	parts := strings.Split(addr, ",")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	drop := 1
+	if first := parts[0]; len(first) <= 6 && first != "" && unicode.IsDigit(rune(first[0])) {
+		drop = 2
+	}
+	if len(parts) <= drop {
+		return ""
+	}
+	return strings.Join(parts[drop:], ", ")
+}
+
+/**
+ * TrashRetention is how long soft-deleted entries stay in trash before
+ * they are removed permanently.
+ */
+const TrashRetention = 30 * 24 * time.Hour
+
+/**
+ * StopReasonAutoStopped marks entries closed by the forgotten-timer job
+ */
+const StopReasonAutoStopped = "auto_stopped"
+
+/**
+ * AutoStoppedTag is appended to the tags of auto-stopped entries
+ */
+const AutoStoppedTag = "auto-stopped"
+
+/**
+ * StricterLocationVisibility returns whichever of two location_visibility
+ * levels reveals less; unknown levels count as hidden
+ */
+func StricterLocationVisibility(a, b string) string {
+	rank := func(v string) int {
+		switch v {
+		case LocationExact:
+			return 0
+		case LocationApproximate:
+			return 1
+		}
+		return 2
+	}
+	if rank(a) >= rank(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,68 @@
+/**
+ * TimeTracRevision Model - Edit History for Time Entries
+ *
+ * This package defines the TimeTracRevision model which stores the values
+ * a time tracking entry had before it was modified, forming an audit trail
+ * for billing disputes.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-20
+ */
+package models
+
+import (
+	"time"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/gofrs/uuid"
+	"github.com/lib/pq"
+)
+
+/**
+ * TimeTracRevision represents one modification of a time tracking entry
+ *
+ * Database Fields:
+ * - id: Primary key (UUID)
+ * - track_id: Foreign key to timetrac table
+ * - changed_by: User who made the change, null once that user is deleted
+ * - project, note, tags, start_at, end_at: Values before the change
+ * - created_at: When the change was made
+ * - updated_at: Last modification timestamp
+ */
+type TimeTracRevision struct {
+	ID        uuid.UUID      `db:"id" json:"id"`                 // Unique revision identifier
+	TrackID   uuid.UUID      `db:"track_id" json:"track_id"`     // Modified entry
+	ChangedBy nulls.UUID     `db:"changed_by" json:"changed_by"` // User who made the change
+	Project   string         `db:"project" json:"project"`       // Previous project
+	Note      string         `db:"note" json:"note"`             // Previous note
+	Tags      pq.StringArray `db:"tags" json:"tags"`             // Previous tags
+	StartAt   time.Time      `db:"start_at" json:"start_at"`     // Previous start
+	EndAt     nulls.Time     `db:"end_at" json:"end_at"`         // Previous end
+	CreatedAt time.Time      `db:"created_at" json:"changed_at"` // When the change was made
+	UpdatedAt time.Time      `db:"updated_at" json:"-"`          // Last modification timestamp
+}
+
+/**
+ * TableName returns the database table name for the TimeTracRevision model
+ */
+func (r TimeTracRevision) TableName() string { return "timetrac_revisions" }
+
+/**
+ * NewTimeTracRevision snapshots the audited fields of an entry before a change
+ *
+ * @param prev - The entry as it was before the change
+ * @param changedBy - User making the change
+ * @return TimeTracRevision - Unsaved revision
+ */
+func NewTimeTracRevision(prev TimeTrac, changedBy uuid.UUID) TimeTracRevision {
+	return TimeTracRevision{
+		TrackID:   prev.ID,
+		ChangedBy: nulls.NewUUID(changedBy),
+		Project:   prev.Project,
+		Note:      prev.Note,
+		Tags:      prev.Tags,
+		StartAt:   prev.StartAt,
+		EndAt:     prev.EndAt,
+	}
+}
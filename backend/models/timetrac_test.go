@@ -0,0 +1,177 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/gofrs/uuid"
+)
+
+func Test_TimeTrac_ApplyPauses(t *testing.T) {
+	start := time.Date(2025, 9, 20, 9, 0, 0, 0, time.UTC)
+	now := start.Add(3 * time.Hour)
+
+	pauses := TrackPauses{
+		{PausedAt: start.Add(30 * time.Minute), ResumedAt: nulls.NewTime(start.Add(45 * time.Minute))},
+		{PausedAt: start.Add(2 * time.Hour)},
+	}
+
+	running := TimeTrac{StartAt: start}
+	running.ApplyPauses(pauses, now)
+	if !running.Paused {
+		t.Error("running entry with open pause should be paused")
+	}
+	if running.PausedSeconds != int64((75 * time.Minute).Seconds()) {
+		t.Errorf("paused = %d", running.PausedSeconds)
+	}
+	if running.DurationSeconds != int64((105 * time.Minute).Seconds()) {
+		t.Errorf("duration = %d", running.DurationSeconds)
+	}
+
+	stopped := TimeTrac{StartAt: start, EndAt: nulls.NewTime(start.Add(time.Hour))}
+	stopped.ApplyPauses(pauses[:1], now)
+	if stopped.Paused {
+		t.Error("stopped entry should not be paused")
+	}
+	if stopped.DurationSeconds != int64((45 * time.Minute).Seconds()) {
+		t.Errorf("duration = %d", stopped.DurationSeconds)
+	}
+}
+
+func Test_TimeTrac_ForViewer(t *testing.T) {
+	owner := uuid.Must(uuid.NewV4())
+	other := uuid.Must(uuid.NewV4())
+	item := TimeTrac{
+		UserID:       owner,
+		LocationLat:  nulls.NewFloat64(48.208176),
+		LocationLng:  nulls.NewFloat64(16.373819),
+		LocationAddr: nulls.NewString("Stephansplatz 1, 1010 Wien, Austria"),
+	}
+
+	if got := item.ForViewer(owner, LocationHidden); got.LocationLat.Float64 != 48.208176 || !got.LocationAddr.Valid {
+		t.Errorf("owner must always see exact location, got %+v", got)
+	}
+	if got := item.ForViewer(other, LocationExact); got.LocationAddr.String != item.LocationAddr.String {
+		t.Errorf("exact changed the address: %q", got.LocationAddr.String)
+	}
+
+	approx := item.ForViewer(other, LocationApproximate)
+	if approx.LocationLat.Float64 != 48.21 || approx.LocationLng.Float64 != 16.37 {
+		t.Errorf("approximate coordinates: %v, %v", approx.LocationLat.Float64, approx.LocationLng.Float64)
+	}
+	if approx.LocationAddr.String != "1010 Wien, Austria" {
+		t.Errorf("approximate address: %q", approx.LocationAddr.String)
+	}
+	if item.LocationLat.Float64 != 48.208176 {
+		t.Error("ForViewer must not modify the original entry")
+	}
+
+	for _, vis := range []string{LocationHidden, ""} {
+		hidden := item.ForViewer(other, vis)
+		if hidden.LocationLat.Valid || hidden.LocationLng.Valid || hidden.LocationAddr.Valid {
+			t.Errorf("%q visibility leaked location: %+v", vis, hidden)
+		}
+	}
+}
+
+func Test_StripStreet(t *testing.T) {
+	cases := map[string]string{
+		"Stephansplatz 1, 1010 Wien, Austria":              "1010 Wien, Austria",
+		"1, Stephansplatz, Innere Stadt, Wien, Österreich": "Innere Stadt, Wien, Österreich",
+		"12a, Main Street, Springfield":                    "Springfield",
+		"Somewhere":                                        "",
+	}
+	for in, want := range cases {
+		if got := stripStreet(in); got != want {
+			t.Errorf("stripStreet(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func Test_TimeTrac_ValidateAt(t *testing.T) {
+	now := time.Date(2025, 9, 20, 12, 0, 0, 0, time.UTC)
+	uid := uuid.Must(uuid.NewV4())
+
+	cases := []struct {
+		name  string
+		item  TimeTrac
+		field string
+	}{
+		{"valid running", TimeTrac{UserID: uid, StartAt: now.Add(-time.Hour)}, ""},
+		{"valid stopped", TimeTrac{UserID: uid, StartAt: now.Add(-time.Hour), EndAt: nulls.NewTime(now)}, ""},
+		{"missing user", TimeTrac{StartAt: now}, "user_id"},
+		{"missing start", TimeTrac{UserID: uid}, "start_at"},
+		{"end before start", TimeTrac{UserID: uid, StartAt: now, EndAt: nulls.NewTime(now.Add(-time.Minute))}, "end_at"},
+		{"end at start", TimeTrac{UserID: uid, StartAt: now, EndAt: nulls.NewTime(now)}, "end_at"},
+		{"too far in past", TimeTrac{UserID: uid, StartAt: now.Add(-TrackPastHorizon - time.Hour)}, "start_at"},
+		{"too far in future", TimeTrac{UserID: uid, StartAt: now.Add(TrackFutureHorizon + time.Hour)}, "start_at"},
+	}
+	for _, tc := range cases {
+		verrs := tc.item.ValidateAt(now)
+		if tc.field == "" {
+			if verrs.HasAny() {
+				t.Errorf("%s: unexpected errors %v", tc.name, verrs.Errors)
+			}
+			continue
+		}
+		if len(verrs.Get(tc.field)) == 0 {
+			t.Errorf("%s: expected error on %s, got %v", tc.name, tc.field, verrs.Errors)
+		}
+	}
+}
+
+func Test_TrackClient_Normalize(t *testing.T) {
+	var none *TrackClient
+	if got, err := none.Normalize(); got != nil || err != nil {
+		t.Errorf("nil client = %v, %v", got, err)
+	}
+	if got, _ := (&TrackClient{Platform: "  "}).Normalize(); got != nil {
+		t.Errorf("blank client should normalize to nil, got %+v", got)
+	}
+
+	got, err := (&TrackClient{Platform: " ios ", AppVersion: "2.1.0"}).Normalize()
+	if err != nil || got.Platform != "ios" || got.AppVersion != "2.1.0" {
+		t.Errorf("client = %+v, %v", got, err)
+	}
+
+	long := TrackClient{DeviceName: strings.Repeat("x", MaxTrackClientBytes)}
+	if _, err := long.Normalize(); err != ErrTrackClientTooLarge {
+		t.Errorf("oversized client err = %v", err)
+	}
+}
+
+func Test_TrackClient_ValueScan(t *testing.T) {
+	in := &TrackClient{Platform: "android", DeviceName: "Pixel"}
+	v, err := in.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out TrackClient
+	if err := out.Scan([]byte(v.(string))); err != nil {
+		t.Fatal(err)
+	}
+	if out != *in {
+		t.Errorf("round trip = %+v", out)
+	}
+
+	// Unknown keys stored by older clients are ignored
+	if err := out.Scan(`{"platform":"web","extra":{"a":1}}`); err != nil || out.Platform != "web" {
+		t.Errorf("scan = %+v, %v", out, err)
+	}
+}
+
+func Test_StricterLocationVisibility(t *testing.T) {
+	cases := [][3]string{
+		{LocationExact, LocationExact, LocationExact},
+		{LocationExact, LocationApproximate, LocationApproximate},
+		{LocationHidden, LocationApproximate, LocationHidden},
+		{LocationApproximate, "bogus", "bogus"},
+	}
+	for _, tc := range cases {
+		if got := StricterLocationVisibility(tc[0], tc[1]); got != tc[2] {
+			t.Errorf("%s, %s: got %s, want %s", tc[0], tc[1], got, tc[2])
+		}
+	}
+}
@@ -0,0 +1,102 @@
+/**
+ * TrackClient Model - Device Metadata on Time Entries
+ *
+ * This package defines TrackClient, the optional description of the app
+ * and device that created an entry. It is stored in the timetrac.client
+ * JSONB column and used to debug sync issues.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-21
+ */
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+/**
+ * MaxTrackClientBytes caps the stored JSON of a TrackClient
+ */
+const MaxTrackClientBytes = 1024
+
+/**
+ * ErrTrackClientTooLarge is returned by Normalize when the encoded
+ * metadata exceeds MaxTrackClientBytes
+ */
+var ErrTrackClientTooLarge = errors.New("must not exceed 1 KB")
+
+/**
+ * TrackClient describes the client that created an entry
+ *
+ * Only these keys are stored; anything else sent by the client is
+ * dropped when the payload is decoded.
+ */
+type TrackClient struct {
+	Platform   string `json:"platform,omitempty"`    // e.g. "ios", "android", "web"
+	AppVersion string `json:"app_version,omitempty"` // Client app version
+	DeviceName string `json:"device_name,omitempty"` // User-visible device name
+}
+
+/**
+ * Normalize trims the fields and checks the size limit
+ *
+ * @return *TrackClient - nil if every field is empty
+ * @return error - ErrTrackClientTooLarge
+ */
+func (tc *TrackClient) Normalize() (*TrackClient, error) {
+	if tc == nil {
+		return nil, nil
+	}
+	out := TrackClient{
+		Platform:   strings.TrimSpace(tc.Platform),
+		AppVersion: strings.TrimSpace(tc.AppVersion),
+		DeviceName: strings.TrimSpace(tc.DeviceName),
+	}
+	if out == (TrackClient{}) {
+		return nil, nil
+	}
+	b, err := json.Marshal(out)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > MaxTrackClientBytes {
+		return nil, ErrTrackClientTooLarge
+	}
+	return &out, nil
+}
+
+/**
+ * Value implements driver.Valuer, storing the metadata as JSON
+ */
+func (tc *TrackClient) Value() (driver.Value, error) {
+	if tc == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(tc)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+/**
+ * Scan implements sql.Scanner for the JSONB column
+ */
+func (tc *TrackClient) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*tc = TrackClient{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, tc)
+	case string:
+		return json.Unmarshal([]byte(v), tc)
+	default:
+		return fmt.Errorf("cannot scan %T into TrackClient", src)
+	}
+}
@@ -0,0 +1,67 @@
+/**
+ * TrackPause Model - Pause Intervals for Running Entries
+ *
+ * This package defines the TrackPause model which records breaks taken
+ * while a time tracking entry is running, so that tracked durations
+ * exclude them.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-20
+ */
+package models
+
+import (
+	"time"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/gofrs/uuid"
+)
+
+/**
+ * TrackPause represents a single pause of a time tracking entry
+ *
+ * Database Fields:
+ * - id: Primary key (UUID)
+ * - track_id: Foreign key to timetrac table
+ * - paused_at: When the pause started
+ * - resumed_at: When the pause ended (NULL = currently paused)
+ * - created_at, updated_at: Timestamps
+ *
+ * At most one open pause (resumed_at = NULL) exists per entry.
+ */
+type TrackPause struct {
+	ID        uuid.UUID  `db:"id" json:"id"`                 // Unique pause identifier
+	TrackID   uuid.UUID  `db:"track_id" json:"track_id"`     // Paused entry
+	PausedAt  time.Time  `db:"paused_at" json:"paused_at"`   // Pause start
+	ResumedAt nulls.Time `db:"resumed_at" json:"resumed_at"` // Pause end (NULL = open)
+	CreatedAt time.Time  `db:"created_at" json:"created_at"` // Creation timestamp
+	UpdatedAt time.Time  `db:"updated_at" json:"updated_at"` // Last modification timestamp
+}
+
+/**
+ * TableName returns the database table name for the TrackPause model
+ */
+func (p TrackPause) TableName() string { return "track_pauses" }
+
+/**
+ * TrackPauses is a list of pauses, typically for one entry
+ */
+type TrackPauses []TrackPause
+
+/**
+ * Total returns the summed pause time, closing open pauses at `until`
+ */
+func (ps TrackPauses) Total(until time.Time) time.Duration {
+	var total time.Duration
+	for _, p := range ps {
+		end := until
+		if p.ResumedAt.Valid {
+			end = p.ResumedAt.Time
+		}
+		if end.After(p.PausedAt) {
+			total += end.Sub(p.PausedAt)
+		}
+	}
+	return total
+}
@@ -0,0 +1,53 @@
+/**
+ * TrackTemplate Model - Quick-Start Presets for Time Entries
+ *
+ * This package defines the TrackTemplate model: a named preset of
+ * project, tags, note and color from which a running entry can be
+ * started with one tap.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-20
+ */
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/lib/pq"
+)
+
+/**
+ * MaxTrackTemplates is the number of templates a user may keep
+ */
+const MaxTrackTemplates = 50
+
+/**
+ * TrackTemplate represents one quick-start preset
+ *
+ * Database Fields:
+ * - id: Primary key (UUID)
+ * - user_id: Owner
+ * - name: Display name
+ * - project, tags, note, color: Values copied to started entries
+ * - position: Sort order in the picker (ascending)
+ * - created_at, updated_at: Timestamps
+ */
+type TrackTemplate struct {
+	ID        uuid.UUID      `db:"id" json:"id"`                 // Unique template identifier
+	UserID    uuid.UUID      `db:"user_id" json:"-"`             // Owner user ID (hidden from JSON)
+	Name      string         `db:"name" json:"name"`             // Display name
+	Project   string         `db:"project" json:"project"`       // Project for started entries
+	Tags      pq.StringArray `db:"tags" json:"tags"`             // Tags for started entries
+	Note      string         `db:"note" json:"note"`             // Note for started entries
+	Color     string         `db:"color" json:"color"`           // Hex color for started entries
+	Position  int            `db:"position" json:"position"`     // Sort order
+	CreatedAt time.Time      `db:"created_at" json:"created_at"` // Creation timestamp
+	UpdatedAt time.Time      `db:"updated_at" json:"updated_at"` // Last modification timestamp
+}
+
+/**
+ * TableName returns the database table name for the TrackTemplate model
+ */
+func (t TrackTemplate) TableName() string { return "track_templates" }
@@ -14,6 +14,7 @@ package models
 import (
 	"time"
 
+	"github.com/gobuffalo/nulls"
 	"github.com/gofrs/uuid"
 )
 
@@ -29,6 +30,13 @@ import (
  * - id: Primary key (UUID)
  * - email: User's email address (unique, indexed)
  * - password_hash: Bcrypt hashed password (not exposed in JSON)
+ * - last_login_at: Last successful sign-in (NULL = never)
+ * - is_admin: May use the /api/admin endpoints
+ * - disabled_at: When an admin disabled the account (NULL = enabled)
+ * - deactivated_at: When the account was deactivated (NULL = active);
+ *   sessions are revoked but all data is kept
+ * - self_deactivated: The user deactivated the account and may
+ *   reactivate it by signing in
  * - created_at: Account creation timestamp
  * - updated_at: Last modification timestamp
  *
@@ -42,9 +50,14 @@ import (
  * - UUID provides secure, non-sequential user identification
  */
 type User struct {
-	ID           uuid.UUID `db:"id" json:"id"`                 // Unique user identifier
-	Email        string    `db:"email" json:"email"`           // User's email address (login)
-	PasswordHash string    `db:"password_hash" json:"-"`       // Bcrypt hashed password (hidden from JSON)
-	CreatedAt    time.Time `db:"created_at" json:"created_at"` // Account creation timestamp
-	UpdatedAt    time.Time `db:"updated_at" json:"updated_at"` // Last modification timestamp
+	ID              uuid.UUID  `db:"id" json:"id"`                             // Unique user identifier
+	Email           string     `db:"email" json:"email"`                       // User's email address (login)
+	PasswordHash    string     `db:"password_hash" json:"-"`                   // Bcrypt hashed password (hidden from JSON)
+	LastLoginAt     nulls.Time `db:"last_login_at" json:"last_login_at"`       // Last successful sign-in
+	IsAdmin         bool       `db:"is_admin" json:"is_admin"`                 // Administrator
+	DisabledAt      nulls.Time `db:"disabled_at" json:"disabled_at"`           // Disabled by an admin
+	DeactivatedAt   nulls.Time `db:"deactivated_at" json:"deactivated_at"`     // Deactivation timestamp
+	SelfDeactivated bool       `db:"self_deactivated" json:"self_deactivated"` // Deactivated by the user
+	CreatedAt       time.Time  `db:"created_at" json:"created_at"`             // Account creation timestamp
+	UpdatedAt       time.Time  `db:"updated_at" json:"updated_at"`             // Last modification timestamp
 }
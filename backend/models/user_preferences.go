@@ -0,0 +1,144 @@
+/**
+ * UserPreferences Model - Per-User Settings
+ *
+ * This package defines the UserPreferences model which stores settings
+ * that change server-side behavior for a single user. Users without a
+ * stored row get DefaultUserPreferences.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-20
+ */
+package models
+
+import (
+	"slices"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+/**
+ * DefaultMaxRunningHours is the limit after which a forgotten running
+ * entry is stopped automatically.
+ */
+const DefaultMaxRunningHours = 12
+
+/**
+ * DefaultTimezone is used for day boundaries when neither the request nor
+ * the user's preferences name a zone.
+ */
+const DefaultTimezone = "UTC"
+
+/**
+ * DefaultColor is the color of new entries when neither the request nor
+ * the user's preferences choose one.
+ */
+const DefaultColor = "#3b82f6"
+
+/**
+ * RoundingIncrements lists the accepted rounding_minutes values; 0 keeps
+ * exact durations.
+ */
+var RoundingIncrements = []int{0, 1, 5, 6, 10, 15, 30, 60}
+
+/**
+ * ValidRoundingMinutes reports whether m is an accepted rounding increment
+ */
+func ValidRoundingMinutes(m int) bool {
+	return slices.Contains(RoundingIncrements, m)
+}
+
+/**
+ * DefaultLocale is the language of reports when neither the report nor
+ * the user's preferences choose one.
+ */
+const DefaultLocale = "en-US"
+
+/**
+ * Locales lists the languages reports and their emails are translated
+ * to (see the locales directory).
+ */
+var Locales = []string{"en-US", "de-DE"}
+
+/**
+ * ValidLocale reports whether l is one of Locales
+ */
+func ValidLocale(l string) bool {
+	return slices.Contains(Locales, l)
+}
+
+/**
+ * MaxReportRetentionDays bounds the report_retention_days preference.
+ */
+const MaxReportRetentionDays = 365
+
+// Location visibility levels for entries seen by other users.
+const (
+	LocationExact       = "exact"       // Coordinates and address as recorded
+	LocationApproximate = "approximate" // Coordinates rounded, street removed
+	LocationHidden      = "hidden"      // No location at all
+)
+
+/**
+ * ValidLocationVisibility reports whether v is a known visibility level
+ */
+func ValidLocationVisibility(v string) bool {
+	return v == LocationExact || v == LocationApproximate || v == LocationHidden
+}
+
+/**
+ * UserPreferences represents the stored preferences of one user
+ *
+ * Database Fields:
+ * - user_id: Primary key and foreign key to users table
+ * - max_running_hours: Auto-stop limit for running entries
+ * - timezone: IANA zone name used for day/week boundaries
+ * - location_visibility: How entry locations appear to other users
+ * - discard_under_seconds: Stopped entries shorter than this are discarded (0 = off)
+ * - weekly_goal_minutes: Target tracked time per week (0 = no goal)
+ * - default_color: Color of new entries that do not name one
+ * - rounding_minutes: Increment durations are rounded to in reports
+ *   (see RoundingIncrements, 0 = exact)
+ * - team_notification_emails: Email about team invitations, role changes
+ *   and removals
+ * - locale: Language and number format of reports (see Locales)
+ * - report_retention_days: How long stored reports are kept (0 = the
+ *   server's retention)
+ * - created_at, updated_at: Timestamps
+ */
+type UserPreferences struct {
+	ID                     uuid.UUID `db:"user_id" json:"-"`                                         // Owner user ID (primary key)
+	MaxRunningHours        int       `db:"max_running_hours" json:"max_running_hours"`               // Auto-stop limit in hours
+	Timezone               string    `db:"timezone" json:"timezone"`                                 // IANA zone for grouping
+	LocationVisibility     string    `db:"location_visibility" json:"location_visibility"`           // exact | approximate | hidden
+	DiscardUnderSeconds    int       `db:"discard_under_seconds" json:"discard_under_seconds"`       // Minimum entry duration, 0 = disabled
+	WeeklyGoalMinutes      int       `db:"weekly_goal_minutes" json:"weekly_goal_minutes"`           // Weekly target, 0 = none
+	DefaultColor           string    `db:"default_color" json:"default_color"`                       // Color of new entries
+	RoundingMinutes        int       `db:"rounding_minutes" json:"rounding_minutes"`                 // Report rounding, 0 = exact
+	TeamNotificationEmails bool      `db:"team_notification_emails" json:"team_notification_emails"` // Team emails wanted
+	Locale                 string    `db:"locale" json:"locale"`                                     // Report language, e.g. de-DE
+	ReportRetentionDays    int       `db:"report_retention_days" json:"report_retention_days"`       // Stored report lifetime, 0 = server default
+	CreatedAt              time.Time `db:"created_at" json:"created_at"`                             // Creation timestamp
+	UpdatedAt              time.Time `db:"updated_at" json:"updated_at"`                             // Last modification timestamp
+}
+
+/**
+ * TableName returns the database table name for the UserPreferences model
+ */
+func (p UserPreferences) TableName() string { return "user_preferences" }
+
+/**
+ * DefaultUserPreferences returns the preferences used when none are stored
+ */
+func DefaultUserPreferences(userID uuid.UUID) UserPreferences {
+	return UserPreferences{
+		ID:                     userID,
+		MaxRunningHours:        DefaultMaxRunningHours,
+		Timezone:               DefaultTimezone,
+		LocationVisibility:     LocationExact,
+		DefaultColor:           DefaultColor,
+		TeamNotificationEmails: true,
+		Locale:                 DefaultLocale,
+	}
+}
@@ -0,0 +1,122 @@
+/**
+ * Webhook Model - Outgoing Notifications for Track Events
+ *
+ * This package defines the Webhook model, a user's subscription of an
+ * HTTP endpoint to track events, and the WebhookDelivery model, one
+ * queued or attempted POST of an event to that endpoint.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-21
+ */
+package models
+
+import (
+	"time"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/gofrs/uuid"
+	"github.com/lib/pq"
+)
+
+// Webhook delivery states.
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+/**
+ * MaxWebhooks is the number of webhooks a user may keep
+ */
+const MaxWebhooks = 10
+
+/**
+ * WebhookMaxAttempts is the number of POSTs made for one delivery before
+ * it is marked failed
+ */
+const WebhookMaxAttempts = 5
+
+/**
+ * WebhookEvents lists the event names a webhook can subscribe to
+ */
+var WebhookEvents = []string{"track.started", "track.stopped", "track.deleted", "team.updated", "project.budget"}
+
+/**
+ * Webhook represents one endpoint subscribed to a user's track events
+ *
+ * Database Fields:
+ * - id: Primary key (UUID)
+ * - user_id: Owner
+ * - url: http(s) endpoint receiving the POSTs
+ * - secret: HMAC-SHA256 key used to sign the bodies
+ * - events: Subscribed event names (see WebhookEvents)
+ * - active: Paused webhooks receive nothing
+ * - created_at, updated_at: Timestamps
+ */
+type Webhook struct {
+	ID        uuid.UUID      `db:"id" json:"id"`                 // Unique webhook identifier
+	UserID    uuid.UUID      `db:"user_id" json:"-"`             // Owner user ID (hidden from JSON)
+	URL       string         `db:"url" json:"url"`               // Endpoint URL
+	Secret    string         `db:"secret" json:"-"`              // Signing secret (only shown on creation)
+	Events    pq.StringArray `db:"events" json:"events"`         // Subscribed events
+	Active    bool           `db:"active" json:"active"`         // Whether events are delivered
+	CreatedAt time.Time      `db:"created_at" json:"created_at"` // Creation timestamp
+	UpdatedAt time.Time      `db:"updated_at" json:"updated_at"` // Last modification timestamp
+}
+
+/**
+ * TableName returns the database table name for the Webhook model
+ */
+func (w Webhook) TableName() string { return "webhooks" }
+
+/**
+ * WebhookDelivery is one event queued for, or sent to, a webhook
+ *
+ * Database Fields:
+ * - id: Primary key (UUID), sent as X-Webhook-Delivery
+ * - webhook_id: Target webhook
+ * - event: Event name
+ * - payload: JSON body as sent
+ * - status: pending, succeeded or failed
+ * - attempts: POSTs made so far
+ * - next_attempt_at: When a pending delivery is due
+ * - last_status_code, last_error: Outcome of the latest attempt
+ * - delivered_at: Time of the successful attempt
+ * - request_id: Request that queued the delivery, sent as X-Request-ID
+ * - created_at, updated_at: Timestamps
+ */
+type WebhookDelivery struct {
+	ID             uuid.UUID    `db:"id" json:"id"`                             // Unique delivery identifier
+	WebhookID      uuid.UUID    `db:"webhook_id" json:"webhook_id"`             // Target webhook
+	Event          string       `db:"event" json:"event"`                       // Event name
+	Payload        string       `db:"payload" json:"payload"`                   // JSON body
+	Status         string       `db:"status" json:"status"`                     // pending | succeeded | failed
+	Attempts       int          `db:"attempts" json:"attempts"`                 // POSTs made so far
+	NextAttemptAt  time.Time    `db:"next_attempt_at" json:"next_attempt_at"`   // Due time while pending
+	LastStatusCode nulls.Int    `db:"last_status_code" json:"last_status_code"` // HTTP status of the latest attempt
+	LastError      nulls.String `db:"last_error" json:"last_error"`             // Error of the latest attempt
+	DeliveredAt    nulls.Time   `db:"delivered_at" json:"delivered_at"`         // Successful delivery time
+	RequestID      string       `db:"request_id" json:"request_id,omitempty"`   // ID of the queuing request
+	CreatedAt      time.Time    `db:"created_at" json:"created_at"`             // Queue time
+	UpdatedAt      time.Time    `db:"updated_at" json:"updated_at"`             // Last modification timestamp
+}
+
+/**
+ * TableName returns the database table name for the WebhookDelivery model
+ */
+func (d WebhookDelivery) TableName() string { return "webhook_deliveries" }
+
+/**
+ * WebhookBackoff returns the wait before the next attempt after the given
+ * number of failed attempts: 30s, 1m, 2m, 4m, ...
+ *
+ * @param attempts - Failed attempts so far (>= 1)
+ * @return time.Duration - Delay before the next attempt
+ */
+func WebhookBackoff(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	return 30 * time.Second << (attempts - 1)
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_WebhookBackoff(t *testing.T) {
+	want := []time.Duration{30 * time.Second, time.Minute, 2 * time.Minute, 4 * time.Minute}
+	for i, w := range want {
+		if got := WebhookBackoff(i + 1); got != w {
+			t.Errorf("attempt %d: got %v, want %v", i+1, got, w)
+		}
+	}
+	if got := WebhookBackoff(0); got != 30*time.Second {
+		t.Errorf("attempt 0: got %v", got)
+	}
+}
@@ -0,0 +1,66 @@
+/**
+ * WeeklyGoal Model - Snapshots of a User's Weekly Time Goal
+ *
+ * The current goal lives in UserPreferences.WeeklyGoalMinutes. Each time
+ * it changes, the value is recorded for the week it takes effect, so
+ * past weeks keep the goal that was active then.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-20
+ */
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+/**
+ * WeeklyGoal represents the goal in effect from one week on
+ *
+ * Database Fields:
+ * - id: Primary key (UUID)
+ * - user_id: Owner
+ * - week_start: Monday of the week (in the user's timezone) the goal applies from
+ * - goal_minutes: Target minutes per week, 0 = no goal
+ * - created_at, updated_at: Timestamps
+ *
+ * At most one row exists per user and week; a later change in the same
+ * week overwrites it.
+ */
+type WeeklyGoal struct {
+	ID          uuid.UUID `db:"id" json:"-"`                      // Unique row identifier
+	UserID      uuid.UUID `db:"user_id" json:"-"`                 // Owner user ID
+	WeekStart   time.Time `db:"week_start" json:"week_start"`     // Monday the goal applies from
+	GoalMinutes int       `db:"goal_minutes" json:"goal_minutes"` // Target minutes per week
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`     // Creation timestamp
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`     // Last modification timestamp
+}
+
+/**
+ * TableName returns the database table name for the WeeklyGoal model
+ */
+func (g WeeklyGoal) TableName() string { return "weekly_goals" }
+
+/**
+ * WeeklyGoals is a user's goal history ordered by week_start ascending
+ */
+type WeeklyGoals []WeeklyGoal
+
+/**
+ * For returns the goal in effect for the week starting at weekStart: the
+ * latest snapshot taken in or before that week, 0 if there is none
+ */
+func (gs WeeklyGoals) For(weekStart time.Time) int {
+	goal := 0
+	day := weekStart.Format("2006-01-02")
+	for _, g := range gs {
+		if g.WeekStart.Format("2006-01-02") > day {
+			break
+		}
+		goal = g.GoalMinutes
+	}
+	return goal
+}
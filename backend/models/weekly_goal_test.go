@@ -0,0 +1,30 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_WeeklyGoals_For(t *testing.T) {
+	day := func(m time.Month, d int) time.Time { return time.Date(2025, m, d, 0, 0, 0, 0, time.UTC) }
+	goals := WeeklyGoals{
+		{WeekStart: day(9, 1), GoalMinutes: 1200},
+		{WeekStart: day(9, 15), GoalMinutes: 900},
+	}
+
+	cases := []struct {
+		week time.Time
+		want int
+	}{
+		{day(8, 25), 0}, // before the first snapshot there was no goal
+		{day(9, 1), 1200},
+		{day(9, 8), 1200},
+		{day(9, 15), 900},
+		{day(9, 22), 900},
+	}
+	for _, tc := range cases {
+		if got := goals.For(tc.week); got != tc.want {
+			t.Errorf("week %s: got %d, want %d", tc.week.Format("2006-01-02"), got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,270 @@
+/**
+ * Push - Mobile Push Notifications
+ *
+ * This package sends push notifications to registered devices:
+ * - Firebase Cloud Messaging (HTTP v1 API), which reaches Android, iOS
+ *   and web clients through their FCM registration tokens
+ * - A logging notifier for development, used when FCM is not configured
+ *
+ * Configuration (see FromEnv):
+ * - FCM_CREDENTIALS_FILE: Google service account JSON key of the
+ *   Firebase project; the project ID is read from it
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-21
+ */
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// DefaultSendURL is the FCM API base URL.
+const DefaultSendURL = "https://fcm.googleapis.com"
+
+// fcmScope is the OAuth scope for sending messages.
+const fcmScope = "https://www.googleapis.com/auth/firebase.messaging"
+
+// ErrUnregistered is returned for push tokens the provider no longer
+// accepts; their devices should be removed.
+var ErrUnregistered = errors.New("push: token unregistered")
+
+/**
+ * Notification is one message to one device
+ */
+type Notification struct {
+	Token string            // Device push token
+	Title string            // Shown by the OS
+	Body  string            // Shown by the OS
+	Data  map[string]string // Passed to the app, e.g. the entry ID
+}
+
+/**
+ * Notifier delivers notifications
+ */
+type Notifier interface {
+	Send(ctx context.Context, n Notification) error
+}
+
+/**
+ * Log writes notifications to a log function instead of sending them
+ */
+type Log struct {
+	Logf func(format string, args ...any)
+}
+
+/**
+ * Send logs n
+ */
+func (l Log) Send(_ context.Context, n Notification) error {
+	l.Logf("push to %s: %s: %s %v", n.Token, n.Title, n.Body, n.Data)
+	return nil
+}
+
+/**
+ * FCM sends notifications through Firebase Cloud Messaging, signing in
+ * with a service account
+ */
+type FCM struct {
+	ProjectID   string
+	ClientEmail string          // Service account
+	PrivateKey  *rsa.PrivateKey // Service account key
+	TokenURL    string          // OAuth token endpoint
+	SendURL     string          // API base, DefaultSendURL if empty
+	HTTP        *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+/**
+ * serviceAccount is the relevant part of a service account JSON key
+ */
+type serviceAccount struct {
+	ProjectID   string `json:"project_id"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+/**
+ * FromEnv returns an FCM notifier configured from the environment, or
+ * nil when FCM_CREDENTIALS_FILE is not set
+ *
+ * @param getenv - Environment lookup (os.Getenv outside tests)
+ * @return *FCM - Configured notifier or nil
+ */
+func FromEnv(getenv func(string) string) (*FCM, error) {
+	path := getenv("FCM_CREDENTIALS_FILE")
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("FCM_CREDENTIALS_FILE: %w", err)
+	}
+	f, err := ParseCredentials(data)
+	if err != nil {
+		return nil, fmt.Errorf("FCM_CREDENTIALS_FILE: %w", err)
+	}
+	return f, nil
+}
+
+/**
+ * ParseCredentials returns an FCM notifier for a service account JSON key
+ */
+func ParseCredentials(data []byte) (*FCM, error) {
+	var sa serviceAccount
+	if err := json.Unmarshal(data, &sa); err != nil {
+		return nil, err
+	}
+	if sa.ProjectID == "" || sa.ClientEmail == "" || sa.TokenURI == "" {
+		return nil, errors.New("project_id, client_email and token_uri are required")
+	}
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(sa.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("private_key: %w", err)
+	}
+	return &FCM{
+		ProjectID:   sa.ProjectID,
+		ClientEmail: sa.ClientEmail,
+		PrivateKey:  key,
+		TokenURL:    sa.TokenURI,
+		HTTP:        &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+/**
+ * token returns an OAuth access token, fetching a new one shortly
+ * before the cached one expires
+ */
+func (f *FCM) token(ctx context.Context) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := time.Now()
+	if f.accessToken != "" && now.Before(f.expiresAt.Add(-time.Minute)) {
+		return f.accessToken, nil
+	}
+
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   f.ClientEmail,
+		"scope": fcmScope,
+		"aud":   f.TokenURL,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(f.PrivateKey)
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	res, err := f.HTTP.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("push: token endpoint returned %d", res.StatusCode)
+	}
+	var body struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return "", err
+	}
+	if body.AccessToken == "" {
+		return "", errors.New("push: token endpoint returned no token")
+	}
+	f.accessToken = body.AccessToken
+	f.expiresAt = now.Add(time.Duration(body.ExpiresIn) * time.Second)
+	return f.accessToken, nil
+}
+
+/**
+ * Send delivers n; tokens FCM reports as unregistered yield
+ * ErrUnregistered
+ */
+func (f *FCM) Send(ctx context.Context, n Notification) error {
+	access, err := f.token(ctx)
+	if err != nil {
+		return err
+	}
+
+	type notification struct {
+		Title string `json:"title"`
+		Body  string `json:"body"`
+	}
+	payload, err := json.Marshal(map[string]any{
+		"message": map[string]any{
+			"token":        n.Token,
+			"notification": notification{Title: n.Title, Body: n.Body},
+			"data":         n.Data,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	base := f.SendURL
+	if base == "" {
+		base = DefaultSendURL
+	}
+	endpoint := base + "/v1/projects/" + url.PathEscape(f.ProjectID) + "/messages:send"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+access)
+	res, err := f.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusOK {
+		_, _ = io.Copy(io.Discard, res.Body)
+		return nil
+	}
+
+	var body struct {
+		Error struct {
+			Status  string `json:"status"`
+			Details []struct {
+				ErrorCode string `json:"errorCode"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	_ = json.NewDecoder(io.LimitReader(res.Body, 64<<10)).Decode(&body)
+	if res.StatusCode == http.StatusNotFound || body.Error.Status == "NOT_FOUND" {
+		return ErrUnregistered
+	}
+	for _, d := range body.Error.Details {
+		if d.ErrorCode == "UNREGISTERED" {
+			return ErrUnregistered
+		}
+	}
+	return fmt.Errorf("push: FCM returned %d %s", res.StatusCode, body.Error.Status)
+}
@@ -0,0 +1,122 @@
+package push
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// fakeFCM serves the token and send endpoints.
+func fakeFCM(t *testing.T, key *rsa.PrivateKey, send http.HandlerFunc) (*httptest.Server, *int) {
+	t.Helper()
+	tokens := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		_, err := jwt.Parse(r.FormValue("assertion"), func(*jwt.Token) (any, error) { return &key.PublicKey, nil })
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		tokens++
+		json.NewEncoder(w).Encode(map[string]any{"access_token": "at-1", "expires_in": 3600})
+	})
+	mux.HandleFunc("/v1/projects/proj/messages:send", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer at-1" {
+			http.Error(w, "unauthenticated", http.StatusUnauthorized)
+			return
+		}
+		send(w, r)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv, &tokens
+}
+
+func testCredentials(t *testing.T, tokenURL string) (*FCM, *rsa.PrivateKey) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pemKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	data, _ := json.Marshal(map[string]string{
+		"project_id":   "proj",
+		"client_email": "push@proj.iam.gserviceaccount.com",
+		"private_key":  string(pemKey),
+		"token_uri":    tokenURL,
+	})
+	f, err := ParseCredentials(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f, key
+}
+
+func Test_FCM_Send(t *testing.T) {
+	f, key := testCredentials(t, "http://placeholder/token")
+	var got map[string]map[string]any
+	srv, tokens := fakeFCM(t, key, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"name":"projects/proj/messages/1"}`))
+	})
+	f.TokenURL, f.SendURL = srv.URL+"/token", srv.URL
+
+	n := Notification{Token: "device-1", Title: "Timer stopped", Body: "Hi", Data: map[string]string{"track_id": "t1"}}
+	for i := 0; i < 2; i++ {
+		if err := f.Send(context.Background(), n); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if *tokens != 1 {
+		t.Errorf("fetched %d access tokens, want 1", *tokens)
+	}
+	if got["message"]["token"] != "device-1" {
+		t.Errorf("got %v", got)
+	}
+}
+
+func Test_FCM_Unregistered(t *testing.T) {
+	f, key := testCredentials(t, "http://placeholder/token")
+	srv, _ := fakeFCM(t, key, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":{"status":"NOT_FOUND","details":[{"errorCode":"UNREGISTERED"}]}}`))
+	})
+	f.TokenURL, f.SendURL = srv.URL+"/token", srv.URL
+	if err := f.Send(context.Background(), Notification{Token: "gone"}); !errors.Is(err, ErrUnregistered) {
+		t.Errorf("got %v, want ErrUnregistered", err)
+	}
+}
+
+func Test_FCM_ServerError(t *testing.T) {
+	f, key := testCredentials(t, "http://placeholder/token")
+	srv, _ := fakeFCM(t, key, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":{"status":"UNAVAILABLE"}}`, http.StatusServiceUnavailable)
+	})
+	f.TokenURL, f.SendURL = srv.URL+"/token", srv.URL
+	err := f.Send(context.Background(), Notification{Token: "t"})
+	if err == nil || errors.Is(err, ErrUnregistered) || !strings.Contains(err.Error(), "503") {
+		t.Errorf("got %v", err)
+	}
+}
+
+func Test_FromEnv(t *testing.T) {
+	if f, err := FromEnv(func(string) string { return "" }); f != nil || err != nil {
+		t.Errorf("unset: got %v, %v", f, err)
+	}
+	if _, err := FromEnv(func(string) string { return "/nonexistent/key.json" }); err == nil {
+		t.Error("missing file accepted")
+	}
+	if _, err := ParseCredentials([]byte(`{"project_id":"p"}`)); err == nil {
+		t.Error("incomplete credentials accepted")
+	}
+}
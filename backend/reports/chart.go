@@ -0,0 +1,231 @@
+/**
+ * Report Charts - Aggregated Series to Plot
+ *
+ * GenerateChart groups a period's entries by time bucket, project, tag
+ * or member, like the group tables of GenerateReport, and returns one
+ * series of hours, entries or amounts per group for the frontend to
+ * draw.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-22
+ */
+package reports
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"time"
+
+	"backend/models"
+)
+
+// Chart metrics.
+const (
+	MetricHours   = "hours"   // Tracked hours
+	MetricEntries = "entries" // Number of entries
+	MetricAmount  = "amount"  // Value at the members' rates, in cents per currency
+)
+
+// ChartGroupings lists the groupings a chart accepts; day, week and
+// month are time buckets.
+var ChartGroupings = []string{"day", "week", "month", "project", "tag", "member"}
+
+/**
+ * Series is one set of values of a chart, a value per label
+ */
+type Series struct {
+	Name   string    `json:"name"`
+	Values []float64 `json:"values"`
+}
+
+/**
+ * ChartData is a metric of a period's entries per group, ready to plot
+ */
+type ChartData struct {
+	Metric   string    `json:"metric"`
+	Group    string    `json:"group"`
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	Timezone string    `json:"timezone"`
+	Keys     []string  `json:"keys"`           // Group keys, such as 2025-03-03 or a project
+	Labels   []string  `json:"labels"`         // Group labels as in reports
+	Series   []Series  `json:"series"`         // One per currency for amount, one otherwise
+	Totals   Totals    `json:"totals"`         // Of the entries, each counted once
+	Note     string    `json:"note,omitempty"` // Caveat, as for overlapping tags
+}
+
+/**
+ * GenerateChart aggregates the entries src returns like GenerateReport
+ * does for a group table, so charts match the reports
+ *
+ * The grouping is the config's group_by (one of ChartGroupings). Time
+ * buckets cover the whole period, those without entries holding zero;
+ * other groups are ordered by descending time. Amount series are the
+ * entries' value at their rate, one series per currency, rounded to
+ * cents per group; entries without a rate are left out of them. Labels
+ * are in the locale of the config, else the request's.
+ *
+ * @param src - Entry source
+ * @param req - Period, zone and config; Template is not used
+ * @param metric - MetricHours, MetricEntries or MetricAmount
+ * @param maxBuckets - Most groups returned
+ * @return *ChartData - Chart
+ * @return error - *FieldError for an invalid request or too many groups,
+ *   or the source's error
+ */
+func GenerateChart(src Source, req Request, metric string, maxBuckets int) (*ChartData, error) {
+	if metric != MetricHours && metric != MetricEntries && metric != MetricAmount {
+		return nil, &FieldError{"metric", errors.New("must be hours, entries or amount")}
+	}
+	groupBy := req.Config.GroupBy
+	if !slices.Contains(ChartGroupings, groupBy) {
+		return nil, &FieldError{"group", errors.New("must be day, week, month, project, tag or member")}
+	}
+	if err := req.Config.ValidateFor(req.Team); err != nil {
+		return nil, &FieldError{"group", err}
+	}
+	if !req.To.After(req.From) {
+		return nil, &FieldError{"to", errors.New("must be after from")}
+	}
+	loc := req.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	weekStart := req.Config.WeekStartDay()
+	l := requestLocale(req)
+
+	// Time buckets are known before loading anything
+	var buckets []Entry
+	if groupBy == "day" || groupBy == "week" || groupBy == "month" {
+		for t := bucketStart(req.From.In(loc), groupBy, weekStart); t.Before(req.To); t = nextBucket(t, groupBy) {
+			if len(buckets) == maxBuckets {
+				return nil, tooManyBuckets(maxBuckets)
+			}
+			buckets = append(buckets, Entry{StartAt: t})
+		}
+	}
+
+	all, err := src(req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+	entries := filterEntries(all, req.Config)
+	groups := groupEntries(entries, groupBy, loc, weekStart, l)
+	if buckets != nil {
+		byKey := make(map[string]group, len(groups))
+		for _, g := range groups {
+			byKey[g.key] = g
+		}
+		groups = make([]group, len(buckets))
+		for i, b := range buckets {
+			key, label := groupKey(b, groupBy, loc, weekStart, l)
+			groups[i] = byKey[key]
+			groups[i].key, groups[i].label = key, label
+		}
+	} else if len(groups) > maxBuckets {
+		return nil, tooManyBuckets(maxBuckets)
+	}
+
+	chart := &ChartData{
+		Metric:   metric,
+		Group:    groupBy,
+		From:     req.From.In(loc),
+		To:       req.To.In(loc),
+		Timezone: loc.String(),
+		Keys:     make([]string, len(groups)),
+		Labels:   make([]string, len(groups)),
+		Series:   []Series{},
+		Totals:   sum(entries),
+	}
+	if groupBy == "tag" {
+		chart.Note = l.t("report.note.tags", nil)
+	}
+	for i, g := range groups {
+		chart.Keys[i], chart.Labels[i] = g.key, g.label
+	}
+
+	switch metric {
+	case MetricHours, MetricEntries:
+		values := make([]float64, len(groups))
+		for i, g := range groups {
+			if metric == MetricHours {
+				values[i] = g.totals.TotalSeconds / 3600
+			} else {
+				values[i] = float64(g.totals.EntryCount)
+			}
+		}
+		chart.Series = append(chart.Series, Series{Name: metric, Values: values})
+	case MetricAmount:
+		byCurrency := map[string][]float64{}
+		for i, g := range groups {
+			for currency, cents := range amounts(g.entries) {
+				if byCurrency[currency] == nil {
+					byCurrency[currency] = make([]float64, len(groups))
+				}
+				byCurrency[currency][i] = cents
+			}
+		}
+		for currency, values := range byCurrency {
+			chart.Series = append(chart.Series, Series{Name: currency, Values: values})
+		}
+		sort.Slice(chart.Series, func(i, j int) bool { return chart.Series[i].Name < chart.Series[j].Name })
+	}
+	return chart, nil
+}
+
+/**
+ * amounts values entries at their rates, in cents per currency
+ */
+func amounts(entries []Entry) map[string]float64 {
+	out := map[string]float64{}
+	for _, e := range entries {
+		if e.Currency != "" {
+			out[e.Currency] += e.Seconds * float64(e.RateCents)
+		}
+	}
+	for currency, v := range out {
+		out[currency] = math.Round(v / 3600)
+	}
+	return out
+}
+
+/**
+ * bucketStart returns the start of the day, week or month holding t
+ */
+func bucketStart(t time.Time, groupBy string, weekStart time.Weekday) time.Time {
+	y, m, d := t.Date()
+	switch groupBy {
+	case "week":
+		return models.WeekStartOf(t, weekStart)
+	case "month":
+		return time.Date(y, m, 1, 0, 0, 0, 0, t.Location())
+	default:
+		return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	}
+}
+
+/**
+ * nextBucket returns the start of the day, week or month after the one
+ * starting at t
+ */
+func nextBucket(t time.Time, groupBy string) time.Time {
+	switch groupBy {
+	case "week":
+		return t.AddDate(0, 0, 7)
+	case "month":
+		return t.AddDate(0, 1, 0)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
+
+/**
+ * tooManyBuckets is the error of a chart with more than limit groups
+ */
+func tooManyBuckets(limit int) error {
+	return &FieldError{"group", fmt.Errorf("would return more than %d groups; narrow the period or choose a coarser group", limit)}
+}
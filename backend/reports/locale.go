@@ -0,0 +1,180 @@
+/**
+ * Report Locales - Translated Labels and Localized Formats
+ *
+ * Reports are written in one of models.Locales. Translate looks strings
+ * up in the bundles of the locales directory; a locale formats numbers,
+ * amounts, dates and CSV separators as the language expects.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-22
+ */
+package reports
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"backend/locales"
+	"backend/models"
+
+	"github.com/gobuffalo/middleware/i18n"
+)
+
+// translations holds the bundles of the locales directory, loaded on
+// first use.
+var translations struct {
+	once sync.Once
+	t    *i18n.Translator
+	err  error
+}
+
+/**
+ * Translate returns the string id of the locales bundles in a language,
+ * filled in with data (a map, a struct or nil)
+ *
+ * A key missing from the language falls back to en-US, and then to the
+ * id itself, so translating never fails; so does a language outside
+ * models.Locales.
+ */
+func Translate(lang, id string, data any) string {
+	translations.once.Do(func() {
+		translations.t, translations.err = i18n.New(locales.FS(), models.DefaultLocale)
+	})
+	if translations.err != nil {
+		return id
+	}
+	var args []any
+	if data != nil {
+		args = append(args, data)
+	}
+	for _, l := range []string{lang, models.DefaultLocale} {
+		if s, err := translations.t.TranslateWithLang(l, id, args...); err == nil && s != id {
+			return s
+		}
+	}
+	return id
+}
+
+/**
+ * locale translates the strings of a report and formats its numbers and
+ * dates in one of models.Locales
+ */
+type locale struct {
+	lang     string
+	decimal  string // Decimal separator
+	date     string // Layout of dates
+	dateTime string // Layout of times
+}
+
+// localeCache holds a *locale per language.
+var localeCache sync.Map
+
+/**
+ * localeFor returns the locale of a language, en-US when it is empty or
+ * not one of models.Locales
+ */
+func localeFor(lang string) *locale {
+	if !models.ValidLocale(lang) {
+		lang = models.DefaultLocale
+	}
+	if l, ok := localeCache.Load(lang); ok {
+		return l.(*locale)
+	}
+	l := &locale{lang: lang}
+	l.decimal = l.t("report.format.decimal", nil)
+	l.date = l.t("report.format.date", nil)
+	l.dateTime = l.t("report.format.date_time", nil)
+	localeCache.Store(lang, l)
+	return l
+}
+
+/**
+ * t translates id (see Translate)
+ */
+func (l *locale) t(id string, data map[string]any) string {
+	return Translate(l.lang, id, data)
+}
+
+/**
+ * number renders v with prec decimals
+ */
+func (l *locale) number(v float64, prec int) string {
+	return strings.Replace(strconv.FormatFloat(v, 'f', prec, 64), ".", l.decimal, 1)
+}
+
+/**
+ * cents renders an amount in cents like FormatCents
+ */
+func (l *locale) cents(cents int64) string {
+	return strings.Replace(FormatCents(cents), ".", l.decimal, 1)
+}
+
+/**
+ * percent renders a share with one decimal
+ */
+func (l *locale) percent(v float64) string {
+	return l.t("report.format.percent", map[string]any{"Value": l.number(v, 1)})
+}
+
+/**
+ * duration renders seconds like FormatDuration
+ */
+func (l *locale) duration(seconds float64) string {
+	m := int(seconds / 60)
+	if m < 60 {
+		return l.t("report.format.minutes", map[string]any{"Minutes": m})
+	}
+	return l.t("report.format.hours_minutes", map[string]any{"Hours": m / 60, "Minutes": fmt.Sprintf("%02d", m%60)})
+}
+
+/**
+ * bool renders yes or no
+ */
+func (l *locale) bool(v bool) string {
+	if v {
+		return l.t("report.yes", nil)
+	}
+	return l.t("report.no", nil)
+}
+
+/**
+ * csvComma returns the CSV field separator: a semicolon where commas
+ * separate decimals, as spreadsheets of those locales expect
+ */
+func (l *locale) csvComma() rune {
+	if l.decimal == "," {
+		return ';'
+	}
+	return ','
+}
+
+/**
+ * dayLabel names a day, such as "Mon 2025-03-03"
+ */
+func (l *locale) dayLabel(t time.Time) string {
+	return l.t("report.label.day", map[string]any{
+		"Weekday": l.t("report.weekday."+strconv.Itoa(int(t.Weekday())), nil),
+		"Date":    t.Format(l.date),
+	})
+}
+
+/**
+ * weekLabel names the week starting on start, such as "Week of 2025-03-03"
+ */
+func (l *locale) weekLabel(start time.Time) string {
+	return l.t("report.label.week", map[string]any{"Date": start.Format(l.date)})
+}
+
+/**
+ * monthLabel names the month of t, such as "March 2025"
+ */
+func (l *locale) monthLabel(t time.Time) string {
+	return l.t("report.label.month", map[string]any{
+		"Month": l.t("report.month."+strconv.Itoa(int(t.Month())), nil),
+		"Year":  t.Year(),
+	})
+}
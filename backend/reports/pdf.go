@@ -0,0 +1,257 @@
+/**
+ * PDF Rendering - Printable Reports
+ *
+ * RenderPDF lays a Document out on A4 pages: a title page with the
+ * period and totals, then the sections with their tables and bar charts.
+ * The DejaVu fonts are embedded in the binary so any script a project
+ * name is typed in prints.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-22
+ */
+package reports
+
+import (
+	_ "embed"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// DejaVu Sans covers Latin, Greek and Cyrillic scripts among others, so
+// project names and notes render as typed (see fonts/LICENSE).
+var (
+	//go:embed fonts/DejaVuSans.ttf
+	fontRegular []byte
+	//go:embed fonts/DejaVuSans-Bold.ttf
+	fontBold []byte
+)
+
+const (
+	pdfFont   = "DejaVu"
+	pdfMargin = 15.0 // mm
+	pdfRowH   = 6.0  // Height of a table row or chart bar
+)
+
+// pdfColumnWidths are the widths of the fixed-width column kinds in mm;
+// text columns share the rest of the line.
+var pdfColumnWidths = map[string]float64{
+	KindTime:     28,
+	KindDuration: 22,
+	KindNumber:   16,
+	KindPercent:  18,
+	KindBool:     16,
+	KindMoney:    24,
+}
+
+/**
+ * RenderPDF writes the document as a PDF: a title page with the period
+ * and totals, then every section with its title and note. Table headers
+ * repeat after each page break; charts are horizontal bars. Reports with details
+ * are printed landscape.
+ */
+func RenderPDF(w io.Writer, d *Document) error {
+	orientation := "P"
+	for _, s := range d.Sections {
+		if s.Kind == SectionDetails {
+			orientation = "L"
+		}
+	}
+	pdf := fpdf.New(orientation, "mm", "A4", "")
+	pdf.AddUTF8FontFromBytes(pdfFont, "", fontRegular)
+	pdf.AddUTF8FontFromBytes(pdfFont, "B", fontBold)
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(false, pdfMargin)
+	pdf.SetTitle(d.Title, true)
+	pdf.SetCreator("TimeTrac", true)
+	pdf.SetCreationDate(d.GeneratedAt)
+	pdf.AliasNbPages("{nb}")
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-pdfMargin + 3)
+		pdf.SetFont(pdfFont, "", 8)
+		pdf.SetTextColor(120, 120, 120)
+		pdf.CellFormat(0, 4, fmt.Sprintf("%s · %s · %d/{nb}", d.Title, d.Period(), pdf.PageNo()), "", 0, "C", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+	})
+
+	l := d.locale()
+	pdfTitlePage(pdf, d, l)
+	for _, s := range d.Sections {
+		pdf.AddPage()
+		pdf.SetFont(pdfFont, "B", 14)
+		pdf.CellFormat(0, 10, s.Title, "", 1, "L", false, 0, "")
+		pdf.Ln(2)
+		if s.Table != nil {
+			pdfTable(pdf, s.Table, l)
+		}
+		if s.Chart != nil {
+			pdfChart(pdf, s.Chart, l)
+		}
+		if s.Note != "" {
+			pdfNote(pdf, s.Note)
+		}
+	}
+	return pdf.Output(w)
+}
+
+/**
+ * pdfTitlePage writes the title, period and totals
+ */
+func pdfTitlePage(pdf *fpdf.Fpdf, d *Document, l *locale) {
+	pdf.AddPage()
+	_, h := pdf.GetPageSize()
+	pdf.SetY(h / 3)
+	pdf.SetFont(pdfFont, "B", 24)
+	pdf.MultiCell(0, 12, d.Title, "", "C", false)
+	pdf.Ln(4)
+	pdf.SetFont(pdfFont, "", 12)
+	for _, line := range []string{
+		d.Period() + " (" + d.Timezone + ")",
+		l.t("report.pdf.generated", map[string]any{"Time": d.GeneratedAt.Format(l.dateTime)}),
+	} {
+		pdf.CellFormat(0, 7, line, "", 1, "C", false, 0, "")
+	}
+	pdf.Ln(8)
+	if d.Totals.EntryCount == 0 {
+		pdf.CellFormat(0, 7, l.t("report.text.empty", nil), "", 1, "C", false, 0, "")
+		return
+	}
+	pdf.SetFont(pdfFont, "B", 12)
+	for _, line := range []string{
+		l.t("report.pdf.total", map[string]any{"Value": l.duration(d.Totals.TotalSeconds)}),
+		l.t("report.pdf.billable", map[string]any{"Value": l.duration(d.Totals.BillableSeconds)}),
+		l.t("report.pdf.entries", map[string]any{"Value": d.Totals.EntryCount}),
+	} {
+		pdf.CellFormat(0, 7, line, "", 1, "C", false, 0, "")
+	}
+}
+
+/**
+ * pdfNote writes a section's note below its content
+ */
+func pdfNote(pdf *fpdf.Fpdf, note string) {
+	_, pageH := pdf.GetPageSize()
+	if pdf.GetY()+3*pdfRowH > pageH-pdfMargin {
+		pdf.AddPage()
+	}
+	pdf.Ln(3)
+	pdf.SetFont(pdfFont, "", 8)
+	pdf.SetTextColor(90, 90, 90)
+	pdf.MultiCell(0, 4, note, "", "L", false)
+	pdf.SetTextColor(0, 0, 0)
+}
+
+/**
+ * pdfFit shortens s with an ellipsis until it fits width w
+ */
+func pdfFit(pdf *fpdf.Fpdf, s string, w float64) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if pdf.GetStringWidth(s) <= w {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && pdf.GetStringWidth(string(runes)+"…") > w {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
+
+/**
+ * pdfTable writes a table, starting a new page and repeating the header
+ * when a row does not fit
+ */
+func pdfTable(pdf *fpdf.Fpdf, t *Table, l *locale) {
+	pageW, pageH := pdf.GetPageSize()
+	avail := pageW - 2*pdfMargin
+	widths := make([]float64, len(t.Columns))
+	fixed, shares := 0.0, 0.0
+	for i, col := range t.Columns {
+		if w, ok := pdfColumnWidths[col.Kind]; ok {
+			widths[i] = w
+			fixed += w
+		} else if col.Key == "note" {
+			shares += 2
+		} else {
+			shares++
+		}
+	}
+	for i, col := range t.Columns {
+		if widths[i] == 0 {
+			share := 1.0
+			if col.Key == "note" {
+				share = 2
+			}
+			widths[i] = (avail - fixed) * share / shares
+		}
+	}
+
+	align := func(col Column) string {
+		switch col.Kind {
+		case KindDuration, KindNumber, KindPercent, KindMoney:
+			return "R"
+		}
+		return "L"
+	}
+	header := func() {
+		pdf.SetFont(pdfFont, "B", 9)
+		pdf.SetFillColor(230, 230, 230)
+		for i, col := range t.Columns {
+			pdf.CellFormat(widths[i], pdfRowH, pdfFit(pdf, col.Title, widths[i]-2), "B", 0, align(col), true, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFont(pdfFont, "", 9)
+	}
+	row := func(cells []any, border string) {
+		if pdf.GetY()+pdfRowH > pageH-pdfMargin {
+			pdf.AddPage()
+			header()
+		}
+		for i, v := range cells {
+			text := pdfFit(pdf, textCell(t.Columns[i], v, l), widths[i]-2)
+			pdf.CellFormat(widths[i], pdfRowH, text, border, 0, align(t.Columns[i]), false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+
+	header()
+	for _, cells := range t.Rows {
+		row(cells, "")
+	}
+	if t.Total != nil {
+		pdf.SetFont(pdfFont, "B", 9)
+		row(t.Total, "T")
+	}
+}
+
+/**
+ * pdfChart draws horizontal bars scaled to the longest
+ */
+func pdfChart(pdf *fpdf.Fpdf, bars []Bar, l *locale) {
+	pageW, pageH := pdf.GetPageSize()
+	pdf.SetFont(pdfFont, "", 9)
+	labelW, top := 0.0, 0.0
+	for _, bar := range bars {
+		labelW = max(labelW, pdf.GetStringWidth(bar.Label)+2)
+		top = max(top, bar.Seconds)
+	}
+	labelW = min(labelW, 60)
+	valueW := 22.0
+	barW := pageW - 2*pdfMargin - labelW - valueW
+
+	pdf.SetFillColor(66, 133, 244)
+	for _, bar := range bars {
+		if pdf.GetY()+pdfRowH > pageH-pdfMargin {
+			pdf.AddPage()
+		}
+		y := pdf.GetY()
+		pdf.CellFormat(labelW, pdfRowH, pdfFit(pdf, bar.Label, labelW-2), "", 0, "L", false, 0, "")
+		if top > 0 && bar.Seconds > 0 {
+			pdf.Rect(pdfMargin+labelW, y+1, barW*bar.Seconds/top, pdfRowH-2, "F")
+		}
+		pdf.SetX(pdfMargin + labelW + barW)
+		pdf.CellFormat(valueW, pdfRowH, l.duration(bar.Seconds), "", 1, "R", false, 0, "")
+	}
+}
@@ -0,0 +1,334 @@
+/**
+ * Report Rendering - Writing Documents as JSON, CSV and Text
+ *
+ * This file writes a Document out in the formats other than PDF (see
+ * pdf.go):
+ * - RenderJSON for the API and stored artifacts
+ * - RenderCSV for spreadsheets, with the document's tables only
+ * - RenderText as plain text, and RenderSummary without the entries for
+ *   the body of report emails
+ *
+ * It also holds the helpers the renderers share: durations, amounts,
+ * the period line and file names.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-22
+ */
+package reports
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// textBarWidth is the width of the longest bar in a text chart.
+const textBarWidth = 30
+
+// nonSlug matches runs of characters left out of file names.
+var nonSlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+/**
+ * FormatDuration renders seconds as "1h 05m" or "12m", as en-US reports
+ * do
+ */
+func FormatDuration(seconds float64) string {
+	m := int(seconds / 60)
+	if m < 60 {
+		return fmt.Sprintf("%dm", m)
+	}
+	return fmt.Sprintf("%dh %02dm", m/60, m%60)
+}
+
+/**
+ * FormatCents renders an amount in cents as "1234.56"
+ */
+func FormatCents(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign, cents = "-", -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
+
+/**
+ * Period renders the document's period as its first and last day
+ */
+func (d *Document) Period() string {
+	l := d.locale()
+	first := d.From.Format(l.date)
+	// The end is exclusive; a period ending at midnight ends the day before
+	last := d.To.Add(-time.Nanosecond).Format(l.date)
+	if first == last {
+		return first
+	}
+	return l.t("report.period", map[string]any{"From": first, "To": last})
+}
+
+/**
+ * Filename returns a download name for the document, such as
+ * "summary-report-2025-03-03.csv"
+ */
+func Filename(d *Document, ext string) string {
+	slug := strings.Trim(nonSlug.ReplaceAllString(strings.ToLower(d.Title), "-"), "-")
+	if slug == "" {
+		slug = "report"
+	}
+	return fmt.Sprintf("%s-%s.%s", slug, d.From.Format("2006-01-02"), ext)
+}
+
+/**
+ * RenderJSON writes the document as JSON
+ */
+func RenderJSON(w io.Writer, d *Document) error {
+	return json.NewEncoder(w).Encode(d)
+}
+
+/**
+ * RenderCSV writes the document's tables as CSV
+ *
+ * A report with a single table, such as a detailed report without
+ * grouping, is written as that table alone. Otherwise every table is
+ * preceded by a row holding its title and followed by an empty row. A
+ * section's note follows its table as a row of its own. Durations are
+ * decimal hours; times are local to the document. Numbers use the
+ * document's decimal separator, and fields are separated by semicolons
+ * where that is a comma. Text that a spreadsheet would take for a
+ * formula is escaped (see csvText).
+ */
+func RenderCSV(w io.Writer, d *Document) error {
+	l := d.locale()
+	var tables []Section
+	for _, s := range d.Sections {
+		if s.Table != nil {
+			tables = append(tables, s)
+		}
+	}
+	cw := csv.NewWriter(w)
+	cw.Comma = l.csvComma()
+	for i, s := range tables {
+		if len(tables) > 1 {
+			if i > 0 {
+				cw.Write([]string{})
+			}
+			cw.Write([]string{csvText(s.Title)})
+		}
+		header := make([]string, len(s.Table.Columns))
+		for j, col := range s.Table.Columns {
+			header[j] = csvText(col.Title)
+			if col.Kind == KindDuration {
+				header[j] = csvText(l.t("report.column.hours", map[string]any{"Title": col.Title}))
+			}
+		}
+		cw.Write(header)
+		for _, row := range s.Table.Rows {
+			cw.Write(csvRow(s.Table.Columns, row, l))
+		}
+		if s.Table.Total != nil {
+			cw.Write(csvRow(s.Table.Columns, s.Table.Total, l))
+		}
+		if s.Note != "" {
+			cw.Write([]string{csvText(s.Note)})
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+/**
+ * csvText escapes text that starts like a formula (=, +, -, @, tab or
+ * carriage return) with a leading apostrophe, so a spreadsheet opening
+ * the file shows it instead of evaluating it
+ */
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+/**
+ * csvRow formats the cells of a row for CSV
+ */
+func csvRow(cols []Column, row []any, l *locale) []string {
+	out := make([]string, len(row))
+	for i, v := range row {
+		switch v := v.(type) {
+		case nil:
+		case string:
+			out[i] = csvText(v)
+		case int:
+			out[i] = strconv.Itoa(v)
+		case int64:
+			out[i] = l.cents(v)
+		case bool:
+			out[i] = strconv.FormatBool(v)
+		case time.Time:
+			out[i] = v.Format(l.dateTime)
+		case float64:
+			if cols[i].Kind == KindDuration {
+				v /= 3600
+			}
+			out[i] = l.number(v, 2)
+		default:
+			out[i] = fmt.Sprint(v)
+		}
+	}
+	return out
+}
+
+/**
+ * RenderText writes the document as plain text
+ */
+func RenderText(w io.Writer, d *Document) error {
+	return renderText(w, d, true)
+}
+
+/**
+ * RenderSummary writes the document as plain text without its details
+ * sections, for the body of an email
+ */
+func RenderSummary(w io.Writer, d *Document) error {
+	return renderText(w, d, false)
+}
+
+/**
+ * renderText writes the header, totals and sections of the document,
+ * leaving out the entries unless details is set
+ */
+func renderText(w io.Writer, d *Document, details bool) error {
+	l := d.locale()
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n%s\n\n", d.Title, l.t("report.text.period", map[string]any{"Period": d.Period(), "Timezone": d.Timezone}))
+	if d.Totals.EntryCount == 0 {
+		b.WriteString(l.t("report.text.empty", nil) + "\n")
+		_, err := io.WriteString(w, b.String())
+		return err
+	}
+	b.WriteString(l.t("report.text.totals", map[string]any{
+		"Total":    l.duration(d.Totals.TotalSeconds),
+		"Billable": l.duration(d.Totals.BillableSeconds),
+		"Entries":  d.Totals.EntryCount,
+	}) + "\n")
+
+	for _, s := range d.Sections {
+		if s.Kind == SectionDetails && !details {
+			continue
+		}
+		fmt.Fprintf(&b, "\n%s\n", s.Title)
+		if s.Table != nil {
+			textTable(&b, s.Table, l)
+		}
+		if s.Chart != nil {
+			textChart(&b, s.Chart, l)
+		}
+		if s.Note != "" {
+			b.WriteString(l.t("report.text.note", map[string]any{"Note": s.Note}) + "\n")
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+/**
+ * textCell formats a cell for text output
+ */
+func textCell(col Column, v any, l *locale) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return l.bool(v)
+	case time.Time:
+		return v.Format(l.dateTime)
+	case int64:
+		return l.cents(v)
+	case float64:
+		if col.Kind == KindPercent {
+			return l.percent(v)
+		}
+		return l.duration(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+/**
+ * textTable writes a table with aligned columns; numbers are right
+ * aligned and empty columns are left out
+ */
+func textTable(b *strings.Builder, t *Table, l *locale) {
+	rows := make([][]string, 0, len(t.Rows)+2)
+	header := make([]string, len(t.Columns))
+	for i, col := range t.Columns {
+		header[i] = col.Title
+	}
+	rows = append(rows, header)
+	for _, row := range t.Rows {
+		cells := make([]string, len(row))
+		for i, v := range row {
+			cells[i] = textCell(t.Columns[i], v, l)
+		}
+		rows = append(rows, cells)
+	}
+	if t.Total != nil {
+		cells := make([]string, len(t.Total))
+		for i, v := range t.Total {
+			cells[i] = textCell(t.Columns[i], v, l)
+		}
+		rows = append(rows, cells)
+	}
+
+	widths := make([]int, len(t.Columns))
+	used := make([]bool, len(t.Columns))
+	for r, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+			used[i] = used[i] || (r > 0 && cell != "")
+		}
+	}
+	for _, row := range rows {
+		var line strings.Builder
+		for i, cell := range row {
+			if !used[i] {
+				continue
+			}
+			pad := strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell))
+			switch t.Columns[i].Kind {
+			case KindDuration, KindNumber, KindPercent, KindMoney:
+				line.WriteString("  " + pad + cell)
+			default:
+				line.WriteString("  " + cell + pad)
+			}
+		}
+		b.WriteString(strings.TrimRight(line.String(), " ") + "\n")
+	}
+}
+
+/**
+ * textChart writes bars of # scaled to the longest
+ */
+func textChart(b *strings.Builder, bars []Bar, l *locale) {
+	width, top := 0, 0.0
+	for _, bar := range bars {
+		width = max(width, utf8.RuneCountInString(bar.Label))
+		top = max(top, bar.Seconds)
+	}
+	for _, bar := range bars {
+		n := 0
+		if top > 0 {
+			n = int(bar.Seconds / top * textBarWidth)
+		}
+		pad := strings.Repeat(" ", width-utf8.RuneCountInString(bar.Label))
+		fmt.Fprintf(b, "  %s%s  %s %s\n", bar.Label, pad, strings.Repeat("#", n), l.duration(bar.Seconds))
+	}
+}
@@ -0,0 +1,600 @@
+/**
+ * Reports - Turning Time Entries into Report Documents
+ *
+ * This package builds the reports offered by the report templates
+ * (models.ReportTemplates) and renders them:
+ * - GenerateReport reads the entries of a period from a Source and builds
+ *   a Document of sections: a table per group (day, week, month, project,
+ *   tag or member), an optional chart of the groups, the budgets of
+ *   project reports, and the entries themselves when details are included
+ * - GenerateChart aggregates the same groups into series to plot
+ * - RenderJSON, RenderCSV, RenderText and RenderPDF write a Document out
+ * - Preview cuts a Document down for display
+ *
+ * Titles, labels, dates and numbers are in the report's locale, one of
+ * models.Locales, translated with the bundles of the locales directory
+ * (see Translate).
+ *
+ * The package does not query the database; callers supply a Source that
+ * applies their visibility rules.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-22
+ */
+package reports
+
+import (
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"backend/models"
+)
+
+// Column kinds, which tell renderers how to format a cell.
+const (
+	KindText     = "text"     // string
+	KindDuration = "duration" // float64 seconds
+	KindTime     = "time"     // time.Time, or nil for a running entry's end
+	KindNumber   = "number"   // int
+	KindPercent  = "percent"  // float64 percent
+	KindBool     = "bool"     // bool
+	KindMoney    = "money"    // int64 cents
+)
+
+// Section kinds.
+const (
+	SectionGroups  = "groups"
+	SectionChart   = "chart"
+	SectionBudget  = "budget"
+	SectionDetails = "details"
+)
+
+// NoProject labels entries without a project in en-US, and selects
+// them in a config's project filter whatever the locale.
+const NoProject = "(no project)"
+
+// NoTag labels entries without tags in en-US.
+const NoTag = "(no tag)"
+
+/**
+ * Entry is a time entry as reports see it
+ */
+type Entry struct {
+	StartAt  time.Time
+	EndAt    *time.Time // Nil while running
+	Member   string     // Email of the entry's user
+	Project  string
+	Tags     []string
+	Note     string
+	Billable bool
+	Seconds  float64 // Duration net of pauses
+
+	// Member's hourly rate when the entry started, for team entries;
+	// Currency is "" when the entry is not priced
+	RateCents int
+	Currency  string
+}
+
+/**
+ * Budget is a project's budget and its consumption up to the end of a
+ * report's period, which the report's entries may only partly cover
+ */
+type Budget struct {
+	Project     string
+	Hours       float64 // Hours budgeted, 0 for none
+	AmountCents int64   // Money budgeted, 0 for none
+	Currency    string  // Of AmountCents and UsedCents
+	UsedSeconds float64 // Tracked on the project
+	UsedCents   int64   // Value of the tracked time at the members' rates
+}
+
+/**
+ * Source returns the entries that started in [from, to), ordered by
+ * start
+ */
+type Source func(from, to time.Time) ([]Entry, error)
+
+/**
+ * Request describes the report to generate
+ */
+type Request struct {
+	Template string              // One of models.ReportTemplates
+	Title    string              // Defaults to the template's title
+	Config   models.ReportConfig // Grouping, sections and filters
+	From     time.Time           // Start of the period (inclusive)
+	To       time.Time           // End of the period (exclusive)
+	Location *time.Location      // Zone of days and times; UTC when nil
+	Team     bool                // Adds the member column to details
+	Budgets  []Budget            // Budgets of the projects, for project reports
+	Locale   string              // Language when the config names none, usually the user's preference
+}
+
+/**
+ * FieldError is returned by GenerateReport for an invalid request
+ */
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string { return e.Field + " " + e.Err.Error() }
+
+func (e *FieldError) Unwrap() error { return e.Err }
+
+/**
+ * Column describes a table column
+ */
+type Column struct {
+	Key   string `json:"key"`
+	Title string `json:"title"`
+	Kind  string `json:"kind"`
+}
+
+/**
+ * Table is a section's tabular data; cells hold raw values of their
+ * column's kind
+ */
+type Table struct {
+	Columns   []Column `json:"columns"`
+	Rows      [][]any  `json:"rows"`
+	Total     []any    `json:"total,omitempty"`     // Totals row, nil when the table has none
+	Truncated bool     `json:"truncated,omitempty"` // Rows were left out (see Preview)
+}
+
+/**
+ * Bar is one bar of a chart
+ */
+type Bar struct {
+	Label   string  `json:"label"`
+	Seconds float64 `json:"seconds"`
+}
+
+/**
+ * Section is a titled part of a report holding a table or a chart
+ */
+type Section struct {
+	Kind  string `json:"kind"`
+	Title string `json:"title"`
+	Note  string `json:"note,omitempty"` // Caveat shown with the section
+	Table *Table `json:"table,omitempty"`
+	Chart []Bar  `json:"chart,omitempty"`
+}
+
+/**
+ * Totals sums up the entries of a report
+ */
+type Totals struct {
+	TotalSeconds    float64 `json:"total_seconds"`
+	BillableSeconds float64 `json:"billable_seconds"`
+	EntryCount      int     `json:"entry_count"`
+}
+
+/**
+ * Document is a generated report
+ */
+type Document struct {
+	Title       string    `json:"title"`
+	Template    string    `json:"template"`
+	GroupBy     string    `json:"group_by"`
+	From        time.Time `json:"from"`
+	To          time.Time `json:"to"`
+	Timezone    string    `json:"timezone"`
+	Locale      string    `json:"locale"`
+	GeneratedAt time.Time `json:"generated_at"`
+	Totals      Totals    `json:"totals"`
+	Sections    []Section `json:"sections"`
+	Truncated   bool      `json:"truncated,omitempty"` // Rows or sections were left out (see Preview)
+
+	loc *time.Location
+}
+
+/**
+ * Location returns the zone the document's days and times are in
+ */
+func (d *Document) Location() *time.Location {
+	if d.loc == nil {
+		return time.UTC
+	}
+	return d.loc
+}
+
+/**
+ * locale returns the locale the document is written in
+ */
+func (d *Document) locale() *locale {
+	return localeFor(d.Locale)
+}
+
+/**
+ * requestLocale returns the locale of a request: the config's, else the
+ * request's
+ */
+func requestLocale(req Request) *locale {
+	if req.Config.Locale != "" {
+		return localeFor(req.Config.Locale)
+	}
+	return localeFor(req.Locale)
+}
+
+/**
+ * GenerateReport builds a report over the entries src returns
+ *
+ * Entries are filtered by the config's projects and billable_only. Group
+ * tables are ordered by time for day, week and month (weeks start on the
+ * config's week_start, Monday by default) and by descending time for
+ * projects, tags and members. An entry with several tags is in the group
+ * of each, so tag groups overlap; their sections carry a note saying so,
+ * and the totals stay those of the entries. Grouping by member requires
+ * a team report. Project reports with budgets get a budget section after
+ * the groups. Details are included for detailed reports or with
+ * include_details, as one section per group when the report is grouped.
+ * The report is written in the config's locale, else the request's.
+ *
+ * @param src - Entry source
+ * @param req - Report to generate
+ * @return *Document - Generated report
+ * @return error - *FieldError for an invalid request, or the source's error
+ */
+func GenerateReport(src Source, req Request) (*Document, error) {
+	if !models.ValidTemplate(req.Template) {
+		return nil, &FieldError{"template", errors.New("must be summary, project or detailed")}
+	}
+	if err := req.Config.ValidateFor(req.Team); err != nil {
+		return nil, &FieldError{"config", err}
+	}
+	if !req.To.After(req.From) {
+		return nil, &FieldError{"to", errors.New("must be after from")}
+	}
+	loc := req.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	groupBy := req.Config.GroupBy
+	if groupBy == "" {
+		groupBy = models.DefaultGroupBy(req.Template)
+	}
+	l := requestLocale(req)
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		title = l.t("report.template."+req.Template, nil)
+	}
+
+	all, err := src(req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+	entries := filterEntries(all, req.Config)
+
+	doc := &Document{
+		Title:       title,
+		Template:    req.Template,
+		GroupBy:     groupBy,
+		From:        req.From.In(loc),
+		To:          req.To.In(loc),
+		Timezone:    loc.String(),
+		Locale:      l.lang,
+		GeneratedAt: time.Now().In(loc),
+		Totals:      sum(entries),
+		Sections:    []Section{},
+		loc:         loc,
+	}
+
+	var groups []group
+	if groupBy != "none" {
+		groups = groupEntries(entries, groupBy, loc, req.Config.WeekStartDay(), l)
+		note := ""
+		if groupBy == "tag" {
+			note = l.t("report.note.tags", nil)
+		}
+		section := groupSection(groupBy, groups, doc.Totals, l)
+		section.Note = note
+		doc.Sections = append(doc.Sections, section)
+		if req.Config.IncludeCharts {
+			bars := make([]Bar, len(groups))
+			for i, g := range groups {
+				bars[i] = Bar{Label: g.label, Seconds: g.totals.TotalSeconds}
+			}
+			doc.Sections = append(doc.Sections, Section{Kind: SectionChart, Title: l.t("report.chart."+groupBy, nil), Note: note, Chart: bars})
+		}
+	}
+	if req.Template == "project" && len(req.Budgets) > 0 {
+		doc.Sections = append(doc.Sections, budgetSection(req.Budgets, entries, l))
+	}
+	if req.Template == "detailed" || req.Config.IncludeDetails {
+		if groupBy == "none" {
+			doc.Sections = append(doc.Sections, detailSection(l.t("report.section.entries", nil), entries, req.Team, loc, l))
+		}
+		for _, g := range groups {
+			doc.Sections = append(doc.Sections, detailSection(g.label, g.entries, req.Team, loc, l))
+		}
+	}
+	return doc, nil
+}
+
+/**
+ * Preview returns a copy of the document cut down for display: every
+ * table keeps its first rows rows and its totals, and at most rows
+ * details sections remain. Tables that lost rows, and the document when
+ * anything was left out, are marked truncated. Charts are kept whole.
+ *
+ * @param d - Generated report
+ * @param rows - Rows per table and details sections kept (>= 1)
+ * @return *Document - Shortened copy; d is not changed
+ */
+func Preview(d *Document, rows int) *Document {
+	p := *d
+	p.Sections = make([]Section, 0, len(d.Sections))
+	details := 0
+	for _, s := range d.Sections {
+		if s.Kind == SectionDetails {
+			if details == rows {
+				p.Truncated = true
+				continue
+			}
+			details++
+		}
+		if s.Table != nil && len(s.Table.Rows) > rows {
+			t := *s.Table
+			t.Rows, t.Truncated = t.Rows[:rows], true
+			s.Table, p.Truncated = &t, true
+		}
+		p.Sections = append(p.Sections, s)
+	}
+	return &p
+}
+
+/**
+ * filterEntries returns the entries a config's projects and
+ * billable_only keep
+ */
+func filterEntries(all []Entry, config models.ReportConfig) []Entry {
+	entries := make([]Entry, 0, len(all))
+	for _, e := range all {
+		if config.BillableOnly && !e.Billable {
+			continue
+		}
+		if len(config.Projects) > 0 && !containsProject(config.Projects, e.Project) {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+/**
+ * containsProject reports whether an entry's project is in the filter;
+ * NoProject in the filter matches entries without a project
+ */
+func containsProject(filter []string, project string) bool {
+	for _, p := range filter {
+		if p == project || (project == "" && p == NoProject) {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * sum totals entries
+ */
+func sum(entries []Entry) Totals {
+	var t Totals
+	for _, e := range entries {
+		t.TotalSeconds += e.Seconds
+		if e.Billable {
+			t.BillableSeconds += e.Seconds
+		}
+		t.EntryCount++
+	}
+	return t
+}
+
+/**
+ * group is the entries sharing a group key
+ */
+type group struct {
+	key, label string
+	entries    []Entry
+	totals     Totals
+}
+
+/**
+ * groupEntries groups entries by day, week, month, project, tag or
+ * member; an entry is in the group of each of its tags
+ */
+func groupEntries(entries []Entry, groupBy string, loc *time.Location, weekStart time.Weekday, l *locale) []group {
+	byKey := map[string]*group{}
+	var order []*group
+	add := func(e Entry, key, label string) {
+		g := byKey[key]
+		if g == nil {
+			g = &group{key: key, label: label}
+			byKey[key] = g
+			order = append(order, g)
+		}
+		g.entries = append(g.entries, e)
+	}
+	for _, e := range entries {
+		if groupBy == "tag" {
+			tags := entryTags(e)
+			for _, tag := range tags {
+				add(e, tag, tag)
+			}
+			if len(tags) == 0 {
+				add(e, "", l.t("report.no_tag", nil))
+			}
+			continue
+		}
+		key, label := groupKey(e, groupBy, loc, weekStart, l)
+		add(e, key, label)
+	}
+	groups := make([]group, len(order))
+	for i, g := range order {
+		g.totals = sum(g.entries)
+		groups[i] = *g
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		byTime := groupBy == "project" || groupBy == "tag" || groupBy == "member"
+		if byTime && groups[i].totals.TotalSeconds != groups[j].totals.TotalSeconds {
+			return groups[i].totals.TotalSeconds > groups[j].totals.TotalSeconds
+		}
+		return groups[i].key < groups[j].key
+	})
+	return groups
+}
+
+/**
+ * groupKey returns the sortable key and the label of an entry's group
+ */
+func groupKey(e Entry, groupBy string, loc *time.Location, weekStart time.Weekday, l *locale) (string, string) {
+	t := e.StartAt.In(loc)
+	switch groupBy {
+	case "week":
+		start := models.WeekStartOf(t, weekStart)
+		return start.Format("2006-01-02"), l.weekLabel(start)
+	case "month":
+		return t.Format("2006-01"), l.monthLabel(t)
+	case "project":
+		if e.Project == "" {
+			return "", l.t("report.no_project", nil)
+		}
+		return e.Project, e.Project
+	case "member":
+		return e.Member, e.Member
+	default:
+		return t.Format("2006-01-02"), l.dayLabel(t)
+	}
+}
+
+/**
+ * entryTags returns the distinct non-blank tags of an entry
+ */
+func entryTags(e Entry) []string {
+	tags := make([]string, 0, len(e.Tags))
+	for _, tag := range e.Tags {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+/**
+ * groupSection tabulates groups with their share of the total
+ */
+func groupSection(groupBy string, groups []group, totals Totals, l *locale) Section {
+	table := &Table{
+		Columns: []Column{
+			{"group", l.t("report.group_column."+groupBy, nil), KindText},
+			{"total_seconds", l.t("report.column.duration", nil), KindDuration},
+			{"billable_seconds", l.t("report.column.billable", nil), KindDuration},
+			{"entry_count", l.t("report.column.entries", nil), KindNumber},
+			{"share", l.t("report.column.share", nil), KindPercent},
+		},
+		Rows:  make([][]any, 0, len(groups)),
+		Total: []any{l.t("report.total", nil), totals.TotalSeconds, totals.BillableSeconds, totals.EntryCount, 100.0},
+	}
+	for _, g := range groups {
+		share := 0.0
+		if totals.TotalSeconds > 0 {
+			share = g.totals.TotalSeconds / totals.TotalSeconds * 100
+		}
+		table.Rows = append(table.Rows, []any{g.label, g.totals.TotalSeconds, g.totals.BillableSeconds, g.totals.EntryCount, share})
+	}
+	if totals.TotalSeconds == 0 {
+		table.Total[4] = 0.0
+	}
+	return Section{Kind: SectionGroups, Title: l.t("report.group."+groupBy, nil), Table: table}
+}
+
+/**
+ * budgetSection tabulates the budgets with the hours of the report's
+ * entries on each project; money columns are only added when a budget
+ * has an amount
+ */
+func budgetSection(budgets []Budget, entries []Entry, l *locale) Section {
+	period := map[string]float64{}
+	for _, e := range entries {
+		period[e.Project] += e.Seconds
+	}
+	cols := []Column{
+		{"project", l.t("report.column.project", nil), KindText},
+		{"budget_seconds", l.t("report.column.budget", nil), KindDuration},
+		{"used_seconds", l.t("report.column.used", nil), KindDuration},
+		{"period_seconds", l.t("report.column.period", nil), KindDuration},
+		{"remaining_seconds", l.t("report.column.remaining", nil), KindDuration},
+		{"used_share", l.t("report.column.used_share", nil), KindPercent},
+	}
+	currency := ""
+	for _, b := range budgets {
+		if b.AmountCents > 0 {
+			currency = b.Currency
+			break
+		}
+	}
+	if currency != "" {
+		data := map[string]any{"Currency": currency}
+		cols = append(cols,
+			Column{"budget_cents", l.t("report.column.budget_amount", data), KindMoney},
+			Column{"used_cents", l.t("report.column.used_amount", data), KindMoney},
+			Column{"used_amount_share", l.t("report.column.used_amount_share", data), KindPercent},
+		)
+	}
+	table := &Table{Columns: cols, Rows: make([][]any, 0, len(budgets))}
+	for _, b := range budgets {
+		row := []any{b.Project, nil, b.UsedSeconds, period[b.Project], nil, nil}
+		if b.Hours > 0 {
+			limit := b.Hours * 3600
+			row[1], row[4], row[5] = limit, max(limit-b.UsedSeconds, 0), b.UsedSeconds/limit*100
+		}
+		if currency != "" {
+			row = append(row, nil, nil, nil)
+			if b.AmountCents > 0 {
+				row[6], row[7], row[8] = b.AmountCents, b.UsedCents, float64(b.UsedCents)/float64(b.AmountCents)*100
+			}
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	return Section{Kind: SectionBudget, Title: l.t("report.section.budget", nil), Table: table}
+}
+
+/**
+ * detailSection lists entries
+ */
+func detailSection(title string, entries []Entry, team bool, loc *time.Location, l *locale) Section {
+	cols := []Column{
+		{"start_at", l.t("report.column.start", nil), KindTime},
+		{"end_at", l.t("report.column.end", nil), KindTime},
+		{"seconds", l.t("report.column.duration", nil), KindDuration},
+	}
+	if team {
+		cols = append(cols, Column{"member", l.t("report.column.member", nil), KindText})
+	}
+	cols = append(cols,
+		Column{"project", l.t("report.column.project", nil), KindText},
+		Column{"tags", l.t("report.column.tags", nil), KindText},
+		Column{"note", l.t("report.column.note", nil), KindText},
+		Column{"billable", l.t("report.column.billable", nil), KindBool},
+	)
+	table := &Table{Columns: cols, Rows: make([][]any, 0, len(entries))}
+	for _, e := range entries {
+		var end any
+		if e.EndAt != nil {
+			end = e.EndAt.In(loc)
+		}
+		row := []any{e.StartAt.In(loc), end, e.Seconds}
+		if team {
+			row = append(row, e.Member)
+		}
+		row = append(row, e.Project, strings.Join(e.Tags, ", "), e.Note, e.Billable)
+		table.Rows = append(table.Rows, row)
+	}
+	table.Total = make([]any, len(cols))
+	table.Total[0], table.Total[2] = l.t("report.total", nil), sum(entries).TotalSeconds
+	return Section{Kind: SectionDetails, Title: title, Table: table}
+}
@@ -0,0 +1,527 @@
+package reports
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/models"
+)
+
+// fixedSource returns entries regardless of the period.
+func fixedSource(entries ...Entry) Source {
+	return func(from, to time.Time) ([]Entry, error) { return entries, nil }
+}
+
+func entryAt(s, project string, minutes int, billable bool) Entry {
+	start, _ := time.Parse(time.RFC3339, s)
+	end := start.Add(time.Duration(minutes) * time.Minute)
+	return Entry{StartAt: start, EndAt: &end, Project: project, Billable: billable, Seconds: float64(minutes * 60), Member: "a@example.com"}
+}
+
+func week() (time.Time, time.Time) {
+	from := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+	return from, from.AddDate(0, 0, 7)
+}
+
+func Test_GenerateReport_Summary(t *testing.T) {
+	from, to := week()
+	src := fixedSource(
+		entryAt("2025-03-03T09:00:00Z", "alpha", 60, true),
+		entryAt("2025-03-03T11:00:00Z", "beta", 30, false),
+		entryAt("2025-03-05T09:00:00Z", "alpha", 90, true),
+	)
+	doc, err := GenerateReport(src, Request{Template: "summary", Config: models.ReportConfig{IncludeCharts: true}, From: from, To: to})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Title != "Summary Report" || doc.GroupBy != "day" {
+		t.Fatalf("got title %q group_by %q", doc.Title, doc.GroupBy)
+	}
+	if doc.Totals != (Totals{TotalSeconds: 180 * 60, BillableSeconds: 150 * 60, EntryCount: 3}) {
+		t.Fatalf("got totals %+v", doc.Totals)
+	}
+	if len(doc.Sections) != 2 || doc.Sections[0].Kind != SectionGroups || doc.Sections[1].Kind != SectionChart {
+		t.Fatalf("got sections %+v", doc.Sections)
+	}
+	rows := doc.Sections[0].Table.Rows
+	if len(rows) != 2 || rows[0][0] != "Mon 2025-03-03" || rows[0][1] != 90*60.0 || rows[1][3] != 1 {
+		t.Fatalf("got rows %v", rows)
+	}
+	if share := rows[1][4].(float64); share != 50 {
+		t.Fatalf("got share %v", share)
+	}
+}
+
+func Test_GenerateReport_ProjectDetails(t *testing.T) {
+	from, to := week()
+	src := fixedSource(
+		entryAt("2025-03-03T09:00:00Z", "alpha", 60, true),
+		entryAt("2025-03-03T11:00:00Z", "", 120, false),
+		entryAt("2025-03-04T09:00:00Z", "beta", 30, true),
+	)
+	doc, err := GenerateReport(src, Request{
+		Template: "project",
+		Config:   models.ReportConfig{IncludeDetails: true, Projects: []string{"alpha", NoProject}},
+		From:     from, To: to, Team: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Biggest project first, then one details section per project
+	var titles []string
+	for _, s := range doc.Sections {
+		titles = append(titles, s.Title)
+	}
+	if strings.Join(titles, "|") != "By project|"+NoProject+"|alpha" {
+		t.Fatalf("got sections %v", titles)
+	}
+	details := doc.Sections[2].Table
+	if details.Columns[3].Key != "member" || len(details.Rows) != 1 {
+		t.Fatalf("got details %+v", details)
+	}
+}
+
+func Test_GenerateReport_Budgets(t *testing.T) {
+	from, to := week()
+	src := fixedSource(
+		entryAt("2025-03-03T09:00:00Z", "alpha", 120, true),
+		entryAt("2025-03-04T09:00:00Z", "beta", 30, true),
+	)
+	budgets := []Budget{
+		{Project: "alpha", Hours: 10, UsedSeconds: 9 * 3600},
+		{Project: "beta", AmountCents: 100000, Currency: "EUR", UsedSeconds: 3600, UsedCents: 125050},
+	}
+	doc, err := GenerateReport(src, Request{Template: "project", From: from, To: to, Team: true, Budgets: budgets})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Sections) != 2 || doc.Sections[1].Kind != SectionBudget {
+		t.Fatalf("got sections %+v", doc.Sections)
+	}
+	table := doc.Sections[1].Table
+	if len(table.Columns) != 9 || table.Columns[6].Title != "Budget EUR" {
+		t.Fatalf("got columns %+v", table.Columns)
+	}
+	alpha, beta := table.Rows[0], table.Rows[1]
+	if alpha[1] != 36000.0 || alpha[3] != 7200.0 || alpha[4] != 3600.0 || alpha[5] != 90.0 || alpha[6] != nil {
+		t.Fatalf("got alpha %v", alpha)
+	}
+	// An overrun leaves nothing remaining
+	if beta[1] != nil || beta[3] != 1800.0 || beta[6] != int64(100000) || beta[8] != 125.05 {
+		t.Fatalf("got beta %v", beta)
+	}
+
+	var buf bytes.Buffer
+	RenderText(&buf, doc)
+	if !strings.Contains(buf.String(), "1250.50") {
+		t.Fatalf("no amount in\n%s", buf.String())
+	}
+	buf.Reset()
+	RenderCSV(&buf, doc)
+	if !strings.Contains(buf.String(), "beta,,1.00,0.50,,,1000.00,1250.50,125.05") {
+		t.Fatalf("got CSV\n%s", buf.String())
+	}
+
+	// Hours budgets alone have no money columns; other templates no section
+	doc, _ = GenerateReport(src, Request{Template: "project", From: from, To: to, Budgets: budgets[:1]})
+	if cols := doc.Sections[1].Table.Columns; len(cols) != 6 {
+		t.Fatalf("got columns %+v", cols)
+	}
+	doc, _ = GenerateReport(src, Request{Template: "summary", From: from, To: to, Budgets: budgets})
+	for _, s := range doc.Sections {
+		if s.Kind == SectionBudget {
+			t.Fatal("budget section in a summary report")
+		}
+	}
+	if FormatCents(-5) != "-0.05" {
+		t.Errorf("got %s", FormatCents(-5))
+	}
+}
+
+func Test_GenerateReport_Tags(t *testing.T) {
+	from, to := week()
+	both := entryAt("2025-03-03T09:00:00Z", "alpha", 60, true)
+	both.Tags = []string{"meeting", "client", "meeting"}
+	client := entryAt("2025-03-04T09:00:00Z", "alpha", 30, false)
+	client.Tags = []string{"client"}
+	src := fixedSource(both, client, entryAt("2025-03-05T09:00:00Z", "beta", 15, false))
+	doc, err := GenerateReport(src, Request{
+		Template: "summary",
+		Config:   models.ReportConfig{GroupBy: "tag", IncludeCharts: true, IncludeDetails: true},
+		From:     from, To: to,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Entries count toward each tag; the totals count them once
+	groups := doc.Sections[0]
+	if groups.Title != "By tag" || groups.Note == "" || doc.Sections[1].Note == "" {
+		t.Fatalf("got section %+v", groups)
+	}
+	rows := groups.Table.Rows
+	if len(rows) != 3 || rows[0][0] != "client" || rows[0][1] != 90*60.0 || rows[1][0] != "meeting" || rows[2][0] != NoTag {
+		t.Fatalf("got rows %v", rows)
+	}
+	if groups.Table.Total[1] != 105*60.0 || groups.Table.Total[3] != 3 {
+		t.Fatalf("got total %v", groups.Table.Total)
+	}
+	var titles []string
+	for _, s := range doc.Sections[2:] {
+		titles = append(titles, fmt.Sprintf("%s:%d", s.Title, len(s.Table.Rows)))
+	}
+	if strings.Join(titles, "|") != "client:2|meeting:1|"+NoTag+":1" {
+		t.Fatalf("got details %v", titles)
+	}
+
+	var buf bytes.Buffer
+	if err := RenderCSV(&buf, doc); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), groups.Note) {
+		t.Errorf("CSV lacks the note:\n%s", buf.String())
+	}
+	buf.Reset()
+	if err := RenderSummary(&buf, doc); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "Note: "+groups.Note) {
+		t.Errorf("text lacks the note:\n%s", buf.String())
+	}
+	buf.Reset()
+	if err := RenderPDF(&buf, doc); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_GenerateReport_Members(t *testing.T) {
+	from, to := week()
+	other := entryAt("2025-03-04T09:00:00Z", "alpha", 120, true)
+	other.Member = "b@example.com"
+	src := fixedSource(entryAt("2025-03-03T09:00:00Z", "alpha", 60, true), other)
+	req := Request{Template: "summary", Config: models.ReportConfig{GroupBy: "member"}, From: from, To: to}
+
+	// Members only group team reports
+	var fe *FieldError
+	if _, err := GenerateReport(src, req); !errors.As(err, &fe) || fe.Field != "config" {
+		t.Fatalf("personal report: got %v", err)
+	}
+	req.Team = true
+	doc, err := GenerateReport(src, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := doc.Sections[0].Table.Rows
+	if doc.Sections[0].Title != "By member" || len(rows) != 2 || rows[0][0] != "b@example.com" || rows[1][0] != "a@example.com" {
+		t.Fatalf("got %+v", doc.Sections[0])
+	}
+	if doc.Sections[0].Note != "" {
+		t.Errorf("got note %q", doc.Sections[0].Note)
+	}
+}
+
+func Test_GenerateReport_WeekStart(t *testing.T) {
+	from, to := week()
+	src := fixedSource(entryAt("2025-03-08T09:00:00Z", "alpha", 60, true), entryAt("2025-03-09T09:00:00Z", "alpha", 30, true))
+	for weekStart, want := range map[string]string{"": "Week of 2025-03-03", "sunday": "Week of 2025-03-02|Week of 2025-03-09"} {
+		doc, err := GenerateReport(src, Request{Template: "summary", Config: models.ReportConfig{GroupBy: "week", WeekStart: weekStart}, From: from, To: to})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var labels []string
+		for _, row := range doc.Sections[0].Table.Rows {
+			labels = append(labels, row[0].(string))
+		}
+		if strings.Join(labels, "|") != want {
+			t.Errorf("week_start %q: got %v", weekStart, labels)
+		}
+	}
+}
+
+func Test_GenerateChart(t *testing.T) {
+	from, to := week()
+	priced := func(e Entry, cents int, currency string) Entry {
+		e.RateCents, e.Currency = cents, currency
+		return e
+	}
+	src := fixedSource(
+		priced(entryAt("2025-03-03T09:00:00Z", "alpha", 60, true), 6000, "EUR"),
+		priced(entryAt("2025-03-05T09:00:00Z", "beta", 30, false), 10000, "USD"),
+		entryAt("2025-03-05T11:00:00Z", "alpha", 90, true),
+	)
+
+	// Days without entries are zero and the values match the report
+	chart, err := GenerateChart(src, Request{Config: models.ReportConfig{GroupBy: "day"}, From: from, To: to}, MetricHours, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chart.Labels) != 7 || chart.Keys[0] != "2025-03-03" || chart.Labels[6] != "Sun 2025-03-09" {
+		t.Fatalf("got labels %v", chart.Labels)
+	}
+	if got := fmt.Sprint(chart.Series[0].Values); got != "[1 0 2 0 0 0 0]" {
+		t.Fatalf("got values %s", got)
+	}
+	doc, _ := GenerateReport(src, Request{Template: "summary", From: from, To: to})
+	if rows := doc.Sections[0].Table.Rows; rows[1][1].(float64)/3600 != chart.Series[0].Values[2] || chart.Totals != doc.Totals {
+		t.Fatalf("chart %v does not match report %v", chart.Series[0].Values, rows)
+	}
+
+	chart, err = GenerateChart(src, Request{Config: models.ReportConfig{GroupBy: "project"}, From: from, To: to, Team: true}, MetricAmount, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(chart.Labels, "|") != "alpha|beta" || len(chart.Series) != 2 {
+		t.Fatalf("got %+v", chart)
+	}
+	if eur, usd := chart.Series[0], chart.Series[1]; eur.Name != "EUR" || fmt.Sprint(eur.Values) != "[6000 0]" ||
+		usd.Name != "USD" || fmt.Sprint(usd.Values) != "[0 5000]" {
+		t.Fatalf("got series %+v", chart.Series)
+	}
+
+	chart, err = GenerateChart(src, Request{Config: models.ReportConfig{GroupBy: "tag"}, From: from, To: to}, MetricEntries, 10)
+	if err != nil || chart.Note == "" || fmt.Sprint(chart.Series[0].Values) != "[3]" {
+		t.Fatalf("got %+v %v", chart, err)
+	}
+
+	// Too many groups fail instead of being cut off
+	var fe *FieldError
+	_, err = GenerateChart(src, Request{Config: models.ReportConfig{GroupBy: "day"}, From: from, To: to}, MetricHours, 6)
+	if !errors.As(err, &fe) || fe.Field != "group" {
+		t.Fatalf("day buckets: got %v", err)
+	}
+	_, err = GenerateChart(src, Request{Config: models.ReportConfig{GroupBy: "project"}, From: from, To: to}, MetricHours, 1)
+	if !errors.As(err, &fe) || fe.Field != "group" {
+		t.Fatalf("projects: got %v", err)
+	}
+	_, err = GenerateChart(src, Request{Config: models.ReportConfig{GroupBy: "day"}, From: from, To: to}, "money", 10)
+	if !errors.As(err, &fe) || fe.Field != "metric" {
+		t.Fatalf("metric: got %v", err)
+	}
+}
+
+func Test_GenerateReport_Invalid(t *testing.T) {
+	from, to := week()
+	var fe *FieldError
+	for field, req := range map[string]Request{
+		"template": {Template: "pie", From: from, To: to},
+		"config":   {Template: "summary", Config: models.ReportConfig{GroupBy: "planet"}, From: from, To: to},
+		"to":       {Template: "summary", From: to, To: from},
+	} {
+		_, err := GenerateReport(fixedSource(), req)
+		if !errors.As(err, &fe) || fe.Field != field {
+			t.Errorf("%s: got %v", field, err)
+		}
+	}
+}
+
+func Test_RenderCSV(t *testing.T) {
+	from, to := week()
+	src := fixedSource(entryAt("2025-03-03T09:00:00Z", "alpha", 90, true))
+
+	// A detailed report without grouping is one flat table
+	doc, _ := GenerateReport(src, Request{Template: "detailed", From: from, To: to})
+	var buf bytes.Buffer
+	if err := RenderCSV(&buf, doc); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 || records[0][0] != "Start" || records[1][2] != "1.50" || records[2][0] != "Total" {
+		t.Fatalf("got %v", records)
+	}
+
+	// Several tables carry their titles
+	doc, _ = GenerateReport(src, Request{Template: "summary", Config: models.ReportConfig{IncludeDetails: true}, From: from, To: to})
+	buf.Reset()
+	RenderCSV(&buf, doc)
+	r := csv.NewReader(&buf)
+	r.FieldsPerRecord = -1
+	records, _ = r.ReadAll()
+	if records[0][0] != "By day" {
+		t.Fatalf("got %v", records)
+	}
+}
+
+func Test_RenderCSV_Formulas(t *testing.T) {
+	from, to := week()
+	src := fixedSource(entryAt("2025-03-03T09:00:00Z", "=HYPERLINK(\"http://x\")", 90, true))
+	doc, _ := GenerateReport(src, Request{Template: "detailed", From: from, To: to})
+	var buf bytes.Buffer
+	if err := RenderCSV(&buf, doc); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), ",=HYPERLINK") || !strings.Contains(buf.String(), "'=HYPERLINK") {
+		t.Fatalf("formula not escaped:\n%s", buf.String())
+	}
+
+	for in, want := range map[string]string{
+		"=1+1": "'=1+1", "+1": "'+1", "-1": "'-1", "@SUM(A1)": "'@SUM(A1)", "\tx": "'\tx", "\rx": "'\rx",
+		"alpha": "alpha", "a=b": "a=b", "": "",
+	} {
+		if got := csvText(in); got != want {
+			t.Errorf("csvText(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func Test_RenderText(t *testing.T) {
+	from, to := week()
+	doc, _ := GenerateReport(fixedSource(entryAt("2025-03-03T09:00:00Z", "alpha", 90, true)),
+		Request{Template: "project", Title: "Weekly", Config: models.ReportConfig{IncludeCharts: true}, From: from, To: to})
+	var buf bytes.Buffer
+	if err := RenderText(&buf, doc); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"Weekly", "Period: 2025-03-03 to 2025-03-09 (UTC)", "alpha", "1h 30m", "100.0%", "##########"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+	if got := Filename(doc, "csv"); got != "weekly-2025-03-03.csv" {
+		t.Errorf("got filename %q", got)
+	}
+}
+
+func Test_RenderPDF(t *testing.T) {
+	from, to := week()
+	var entries []Entry
+	for i := 0; i < 120; i++ {
+		e := entryAt("2025-03-03T09:00:00Z", "Überprüfung", 30, true)
+		e.Note = "Réunion — встреча " + strings.Repeat("long note ", 20)
+		entries = append(entries, e)
+	}
+	doc, _ := GenerateReport(fixedSource(entries...), Request{
+		Template: "detailed", Title: "Käse", Config: models.ReportConfig{GroupBy: "project", IncludeCharts: true}, From: from, To: to, Team: true,
+	})
+	var buf bytes.Buffer
+	if err := RenderPDF(&buf, doc); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")) {
+		t.Fatalf("not a PDF: %q", buf.Bytes()[:16])
+	}
+	// The title page, groups, chart and two pages of entries
+	if n := bytes.Count(buf.Bytes(), []byte("/Type /Page\n")); n < 5 {
+		t.Fatalf("got %d pages", n)
+	}
+}
+
+func Test_RenderSummary(t *testing.T) {
+	from, to := week()
+	e := entryAt("2025-03-03T09:00:00Z", "alpha", 90, true)
+	e.Note = "kickoff"
+	doc, _ := GenerateReport(fixedSource(e), Request{Template: "project", Config: models.ReportConfig{IncludeDetails: true}, From: from, To: to})
+	var full, summary bytes.Buffer
+	RenderText(&full, doc)
+	RenderSummary(&summary, doc)
+	if !strings.Contains(full.String(), "kickoff") {
+		t.Fatalf("details missing from text:\n%s", full.String())
+	}
+	if out := summary.String(); strings.Contains(out, "kickoff") || !strings.Contains(out, "By project") {
+		t.Fatalf("got summary:\n%s", out)
+	}
+}
+
+func Test_GenerateReport_Locale(t *testing.T) {
+	from, to := week()
+	src := fixedSource(entryAt("2025-03-03T09:00:00Z", "alpha", 90, true), entryAt("2025-03-04T09:00:00Z", "", 20, false))
+
+	// The config's locale wins over the request's
+	doc, err := GenerateReport(src, Request{
+		Template: "summary", Config: models.ReportConfig{IncludeDetails: true, Locale: "de-DE"}, From: from, To: to, Locale: "en-US",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Locale != "de-DE" || doc.Title != "Zusammenfassung" || doc.Sections[0].Title != "Nach Tag" {
+		t.Fatalf("got %s %q %q", doc.Locale, doc.Title, doc.Sections[0].Title)
+	}
+	if label := doc.Sections[0].Table.Rows[0][0]; label != "Mo. 03.03.2025" {
+		t.Fatalf("got day label %v", label)
+	}
+	if doc.Period() != "03.03.2025 bis 09.03.2025" {
+		t.Fatalf("got period %q", doc.Period())
+	}
+
+	var text bytes.Buffer
+	RenderText(&text, doc)
+	for _, want := range []string{"Zeitraum: 03.03.2025 bis 09.03.2025 (UTC)", "1 Std. 50 Min.", "81,8 %", "ja", "nein", "Gesamt"} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("missing %q in:\n%s", want, text.String())
+		}
+	}
+
+	// Comma decimals come with semicolon separated fields
+	var buf bytes.Buffer
+	RenderCSV(&buf, doc)
+	r := csv.NewReader(&buf)
+	r.Comma, r.FieldsPerRecord = ';', -1
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if records[1][1] != "Dauer (Std.)" || records[2][1] != "1,50" || records[2][4] != "81,82" {
+		t.Fatalf("got %v", records)
+	}
+
+	// Months are named in the locale; the request's locale applies without one in the config
+	doc, _ = GenerateReport(src, Request{Template: "project", Config: models.ReportConfig{GroupBy: "month"}, From: from, To: to, Locale: "de-DE"})
+	if label := doc.Sections[0].Table.Rows[0][0]; label != "März 2025" {
+		t.Fatalf("got month label %v", label)
+	}
+}
+
+func Test_Translate(t *testing.T) {
+	if got := Translate("de-DE", "report.label.week", map[string]any{"Date": "03.03.2025"}); got != "Woche ab 03.03.2025" {
+		t.Fatalf("got %q", got)
+	}
+	// Keys missing from a language, and unknown languages, fall back to en-US
+	if got := Translate("de-DE", "welcome_greeting", nil); got != "Welcome to Buffalo (EN)" {
+		t.Fatalf("got %q", got)
+	}
+	if got := Translate("fr-FR", "report.total", nil); got != "Total" {
+		t.Fatalf("got %q", got)
+	}
+	// A key no bundle has is returned as is
+	if got := Translate("de-DE", "report.missing", nil); got != "report.missing" {
+		t.Fatalf("got %q", got)
+	}
+	// Unknown languages format as en-US
+	if got := localeFor("xx").duration(3900); got != FormatDuration(3900) {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func Test_Preview(t *testing.T) {
+	from, to := week()
+	var entries []Entry
+	for i, project := range []string{"alpha", "beta", "gamma", "alpha"} {
+		entries = append(entries, entryAt(fmt.Sprintf("2025-03-0%dT09:00:00Z", 3+i), project, 30, true))
+	}
+	doc, _ := GenerateReport(fixedSource(entries...), Request{Template: "detailed", Config: models.ReportConfig{GroupBy: "project"}, From: from, To: to})
+	p := Preview(doc, 2)
+	if !p.Truncated || len(p.Sections) != 3 {
+		t.Fatalf("got %d sections, truncated %v", len(p.Sections), p.Truncated)
+	}
+	groups := p.Sections[0].Table
+	if len(groups.Rows) != 2 || !groups.Truncated || groups.Total[3] != 4 {
+		t.Fatalf("got groups %+v", groups)
+	}
+	// alpha's two entries fit
+	if p.Sections[1].Table.Truncated || len(p.Sections[1].Table.Rows) != 2 {
+		t.Fatalf("got details %+v", p.Sections[1].Table)
+	}
+	if len(doc.Sections) != 4 || len(doc.Sections[0].Table.Rows) != 3 {
+		t.Fatal("preview changed the document")
+	}
+	if Preview(doc, 10).Truncated {
+		t.Fatal("a short document was truncated")
+	}
+}
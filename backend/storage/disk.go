@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+/**
+ * Disk stores objects as files below a root directory
+ */
+type Disk struct {
+	root string
+}
+
+/**
+ * NewDisk returns a Store writing below root
+ */
+func NewDisk(root string) *Disk {
+	return &Disk{root: root}
+}
+
+/**
+ * path maps a key to a file path, rejecting keys that escape the root
+ */
+func (d *Disk) path(key string) (string, error) {
+	clean := path.Clean("/" + key)
+	if clean == "/" || strings.Contains(key, "..") {
+		return "", errors.New("storage: invalid key " + key)
+	}
+	return filepath.Join(d.root, filepath.FromSlash(clean)), nil
+}
+
+func (d *Disk) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	p, err := d.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+
+	// Write to a temp file first so readers never see a partial object
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".upload-*")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), p)
+}
+
+func (d *Disk) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	p, err := d.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+func (d *Disk) Delete(ctx context.Context, key string) error {
+	p, err := d.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// SignedURL is not supported on disk; objects are streamed through the API.
+func (d *Disk) SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	return "", nil
+}
@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func Test_Disk_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	d := NewDisk(t.TempDir())
+
+	if err := d.Put(ctx, "tracks/a/b.jpg", strings.NewReader("img"), 3, "image/jpeg"); err != nil {
+		t.Fatal(err)
+	}
+	rc, err := d.Get(ctx, "tracks/a/b.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := io.ReadAll(rc)
+	rc.Close()
+	if string(b) != "img" {
+		t.Fatalf("got %q", b)
+	}
+
+	if err := d.Delete(ctx, "tracks/a/b.jpg"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.Get(ctx, "tracks/a/b.jpg"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func Test_Disk_RejectsEscapingKeys(t *testing.T) {
+	d := NewDisk(t.TempDir())
+	for _, key := range []string{"../x.jpg", "tracks/../../x.jpg", ""} {
+		if err := d.Put(context.Background(), key, strings.NewReader("x"), 1, "image/jpeg"); err == nil {
+			t.Errorf("key %q accepted", key)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+/**
+ * Storage - Pluggable Blob Storage for Uploaded Files
+ *
+ * This package defines the Store interface used for photo attachments and
+ * its implementations:
+ * - Disk: files under a local directory
+ * - S3: any S3-compatible object store (AWS, MinIO, R2, ...)
+ *
+ * The backend is selected with the PHOTO_STORAGE environment variable.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-20
+ */
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"time"
+)
+
+/**
+ * ErrNotFound is returned by Get and Delete when the key does not exist
+ */
+var ErrNotFound = errors.New("storage: object not found")
+
+/**
+ * Store is a minimal blob store keyed by slash-separated paths such as
+ * `tracks/{id}/{uuid}.jpg`.
+ */
+type Store interface {
+	// Put stores size bytes from r under key, replacing any existing object.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get opens the object stored under key.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the object stored under key.
+	Delete(ctx context.Context, key string) error
+	// SignedURL returns a time-limited download URL, or "" if the store
+	// cannot serve objects directly and callers must stream via Get.
+	SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error)
+}
+
+/**
+ * FromEnv builds the Store configured by the environment
+ *
+ * PHOTO_STORAGE selects the backend:
+ * - "disk" (default): PHOTO_STORAGE_DIR, default "uploads"
+ * - "s3": S3_ENDPOINT, S3_BUCKET, S3_ACCESS_KEY, S3_SECRET_KEY,
+ *   S3_REGION (optional) and S3_USE_SSL (default "true")
+ *
+ * @return Store - Configured store
+ */
+func FromEnv() (Store, error) {
+	switch os.Getenv("PHOTO_STORAGE") {
+	case "", "disk":
+		dir := os.Getenv("PHOTO_STORAGE_DIR")
+		if dir == "" {
+			dir = "uploads"
+		}
+		return NewDisk(dir), nil
+	case "s3":
+		return NewS3(S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			Region:    os.Getenv("S3_REGION"),
+			UseSSL:    os.Getenv("S3_USE_SSL") != "false",
+		})
+	default:
+		return nil, errors.New("storage: unknown PHOTO_STORAGE " + os.Getenv("PHOTO_STORAGE"))
+	}
+}
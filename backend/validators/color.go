@@ -0,0 +1,46 @@
+/**
+ * Validators - Shared Input Validation Rules
+ *
+ * This package holds validation and normalization rules used by several
+ * handlers, so every endpoint applies the same rule to the same field.
+ *
+ * @author Abud Developer
+ * @version 1.0.0
+ * @since 2025-09-20
+ */
+package validators
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+/**
+ * ErrInvalidColor is returned for values that are not #RGB or #RRGGBB
+ */
+var ErrInvalidColor = errors.New("must be a hex color in #RGB or #RRGGBB form")
+
+var hexColor = regexp.MustCompile(`^#([0-9a-f]{3}|[0-9a-f]{6})$`)
+
+/**
+ * NormalizeColor validates a hex color and returns it in lowercase
+ * 7-character form
+ *
+ * Surrounding whitespace is ignored and matching is case-insensitive;
+ * `#ABC` becomes `#aabbcc`.
+ *
+ * @param s - Client-supplied color
+ * @return string - Normalized color, e.g. "#3b82f6"
+ * @return error - ErrInvalidColor if s is not a hex color
+ */
+func NormalizeColor(s string) (string, error) {
+	c := strings.ToLower(strings.TrimSpace(s))
+	if !hexColor.MatchString(c) {
+		return "", ErrInvalidColor
+	}
+	if len(c) == 4 {
+		c = string([]byte{'#', c[1], c[1], c[2], c[2], c[3], c[3]})
+	}
+	return c, nil
+}
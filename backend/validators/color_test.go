@@ -0,0 +1,29 @@
+package validators
+
+import "testing"
+
+func Test_NormalizeColor(t *testing.T) {
+	valid := map[string]string{
+		"#3b82f6":   "#3b82f6",
+		"#3B82F6":   "#3b82f6",
+		"#abc":      "#aabbcc",
+		"#F0a":      "#ff00aa",
+		" #123456 ": "#123456",
+	}
+	for in, want := range valid {
+		got, err := NormalizeColor(in)
+		if err != nil || got != want {
+			t.Errorf("NormalizeColor(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+
+	invalid := []string{
+		"", "#", "3b82f6", "#3b82f", "#3b82f6ff", "#ggg", "blue",
+		"javascript:alert(1)", "#abc;background:url(x)", "url(#abc)",
+	}
+	for _, in := range invalid {
+		if _, err := NormalizeColor(in); err == nil {
+			t.Errorf("NormalizeColor(%q) accepted", in)
+		}
+	}
+}
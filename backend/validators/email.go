@@ -0,0 +1,48 @@
+package validators
+
+import (
+	"errors"
+	"net/mail"
+	"strings"
+)
+
+/**
+ * MaxEmailLength is the longest address accepted (RFC 5321 path limit)
+ */
+const MaxEmailLength = 254
+
+/**
+ * ErrInvalidEmail is returned for values that are not a plain email
+ * address
+ */
+var ErrInvalidEmail = errors.New("must be a valid email address")
+
+/**
+ * NormalizeEmail validates an account email address and returns it in
+ * lowercase
+ *
+ * The address is parsed with net/mail (RFC 5322). Display names
+ * ("Ann <ann@example.com>"), comments and domains without a dot are
+ * rejected, since an account address is a bare mailbox on the internet.
+ *
+ * @param s - Client-supplied address
+ * @return string - Trimmed, lowercased address
+ * @return error - ErrInvalidEmail
+ */
+func NormalizeEmail(s string) (string, error) {
+	email := strings.ToLower(strings.TrimSpace(s))
+	if email == "" || len(email) > MaxEmailLength {
+		return "", ErrInvalidEmail
+	}
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Name != "" || addr.Address != email {
+		return "", ErrInvalidEmail
+	}
+	at := strings.LastIndexByte(email, '@')
+	domain := email[at+1:]
+	if !strings.Contains(domain, ".") || strings.HasPrefix(domain, "[") ||
+		strings.HasPrefix(domain, ".") || strings.HasSuffix(domain, ".") {
+		return "", ErrInvalidEmail
+	}
+	return email, nil
+}
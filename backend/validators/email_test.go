@@ -0,0 +1,37 @@
+package validators
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_NormalizeEmail(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{"ann@example.com", "ann@example.com", true},
+		{"  Ann.Lee+work@Example.COM ", "ann.lee+work@example.com", true},
+		{"o'brien@mail.example.org", "o'brien@mail.example.org", true},
+		{"", "", false},
+		{"ann", "", false},
+		{"ann@", "", false},
+		{"@example.com", "", false},
+		{"ann@localhost", "", false},
+		{"ann@example.com.", "", false},
+		{"ann@[127.0.0.1]", "", false},
+		{"Ann <ann@example.com>", "", false},
+		{"ann@example.com (work)", "", false},
+		{"ann@@example.com", "", false},
+		{"ann smith@example.com", "", false},
+		{"ann@example.com, bob@example.com", "", false},
+		{strings.Repeat("a", 250) + "@example.com", "", false},
+	}
+	for _, tc := range cases {
+		got, err := NormalizeEmail(tc.in)
+		if (err == nil) != tc.ok || got != tc.want {
+			t.Errorf("NormalizeEmail(%q) = %q, %v; want %q, ok=%v", tc.in, got, err, tc.want, tc.ok)
+		}
+	}
+}
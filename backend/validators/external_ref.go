@@ -0,0 +1,43 @@
+package validators
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+/**
+ * MaxExternalRefLength bounds issue references stored on entries
+ */
+const MaxExternalRefLength = 100
+
+/**
+ * ErrInvalidExternalRef is returned for references that are too long or
+ * contain characters outside the allowed set
+ */
+var ErrInvalidExternalRef = errors.New("must be at most 100 characters of letters, digits and . _ - / # :")
+
+var externalRefChars = regexp.MustCompile(`^[A-Za-z0-9._\-/#:]+$`)
+
+/**
+ * NormalizeExternalRef validates an issue reference such as "ACME-123"
+ * or "org/repo#45"
+ *
+ * No tracker-specific format is enforced; only length and characters are
+ * checked. Surrounding whitespace is ignored and an empty result means
+ * "no reference".
+ *
+ * @param s - Client-supplied reference
+ * @return string - Trimmed reference, "" to clear it
+ * @return error - ErrInvalidExternalRef
+ */
+func NormalizeExternalRef(s string) (string, error) {
+	ref := strings.TrimSpace(s)
+	if ref == "" {
+		return "", nil
+	}
+	if len(ref) > MaxExternalRefLength || !externalRefChars.MatchString(ref) {
+		return "", ErrInvalidExternalRef
+	}
+	return ref, nil
+}
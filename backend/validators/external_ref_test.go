@@ -0,0 +1,33 @@
+package validators
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_NormalizeExternalRef(t *testing.T) {
+	valid := map[string]string{
+		"ACME-123":           "ACME-123",
+		" org/repo#45 ":      "org/repo#45",
+		"gitlab:group/p#7":   "gitlab:group/p#7",
+		"":                   "",
+		"   ":                "",
+		"my.org/repo_name#1": "my.org/repo_name#1",
+	}
+	for in, want := range valid {
+		got, err := NormalizeExternalRef(in)
+		if err != nil || got != want {
+			t.Errorf("NormalizeExternalRef(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+
+	invalid := []string{
+		"ACME 123", "<script>", "a;b", "ticket?id=1",
+		strings.Repeat("A", MaxExternalRefLength+1),
+	}
+	for _, in := range invalid {
+		if _, err := NormalizeExternalRef(in); err == nil {
+			t.Errorf("NormalizeExternalRef(%q) accepted", in)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package validators
+
+import (
+	"strings"
+)
+
+/**
+ * MinPasswordLength is the shortest password accepted for an account
+ */
+const MinPasswordLength = 8
+
+/**
+ * MaxPasswordLength is the longest password accepted, in bytes; bcrypt
+ * ignores everything after the 72nd byte
+ */
+const MaxPasswordLength = 72
+
+// Password rule violations, as reported to clients.
+const (
+	PasswordTooShort  = "must be at least 8 characters"
+	PasswordTooLong   = "must be at most 72 bytes"
+	PasswordTooCommon = "too common"
+)
+
+/**
+ * commonPasswords are among the most frequent passwords in public breach
+ * corpora, lowercased. Only entries long enough to pass the length rule
+ * are listed.
+ */
+var commonPasswords = map[string]bool{}
+
+func init() {
+	for _, pw := range strings.Fields(`
+		12345678 123456789 1234567890 12345678910 123123123 11111111
+		87654321 11223344 12341234 88888888 99999999 987654321 147258369
+		password password1 password12 password123 passw0rd p@ssw0rd p@ssword
+		password! qwertyuiop qwerty123 qwertyui qwerty12 1q2w3e4r 1q2w3e4r5t
+		1qaz2wsx zaq12wsx qazwsxedc asdfghjkl asdfasdf zxcvbnm1 iloveyou
+		iloveyou1 sunshine princess football baseball basketball superman
+		batman123 starwars whatever trustno1 letmein1 welcome1 welcome123
+		computer internet michelle jennifer jordan23 liverpool chelsea1
+		abcd1234 abc12345 abcdefgh aa123456 a1234567 a12345678 qwe12345
+		1234qwer q1w2e3r4 q1w2e3r4t5 changeme changeme1 administrator
+		admin123 admin1234 master123 monkey123 dragon123 shadow123
+		football1 baseball1 princess1 sunshine1 charlie1 freedom1
+		passport secret12 letmein123 hello123 test1234 testtest
+		00000000 qwertyqwerty 1111111111 0987654321 mypassword
+		picture1 senha123 lovely123 password1234 qwerty1234
+	`) {
+		commonPasswords[pw] = true
+	}
+}
+
+/**
+ * PasswordProblems checks a new password against the strength rules
+ *
+ * Used for registration, password changes and resets so every flow
+ * enforces the same rules.
+ *
+ * @param pw - Plain text password
+ * @return []string - Rule violations (PasswordTooShort, ...), empty if
+ *   the password is acceptable
+ */
+func PasswordProblems(pw string) []string {
+	problems := []string{}
+	if len([]rune(pw)) < MinPasswordLength {
+		problems = append(problems, PasswordTooShort)
+	}
+	if len(pw) > MaxPasswordLength {
+		problems = append(problems, PasswordTooLong)
+	}
+	if commonPasswords[strings.ToLower(pw)] {
+		problems = append(problems, PasswordTooCommon)
+	}
+	return problems
+}
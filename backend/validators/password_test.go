@@ -0,0 +1,32 @@
+package validators
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func Test_PasswordProblems(t *testing.T) {
+	cases := []struct {
+		pw   string
+		want []string
+	}{
+		{"correct horse", []string{}},
+		{"secret123", []string{}},
+		{"Zq8!vN2#", []string{}},
+		{"äöüßäöüß", []string{}},
+		{"", []string{PasswordTooShort}},
+		{"abc", []string{PasswordTooShort}},
+		{"1234567", []string{PasswordTooShort}},
+		{"password", []string{PasswordTooCommon}},
+		{"Password1", []string{PasswordTooCommon}},
+		{"QWERTYUIOP", []string{PasswordTooCommon}},
+		{strings.Repeat("x", 72), []string{}},
+		{strings.Repeat("x", 73), []string{PasswordTooLong}},
+	}
+	for _, tc := range cases {
+		if got := PasswordProblems(tc.pw); !slices.Equal(got, tc.want) {
+			t.Errorf("PasswordProblems(%q) = %v; want %v", tc.pw, got, tc.want)
+		}
+	}
+}